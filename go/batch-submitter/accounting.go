@@ -0,0 +1,337 @@
+package batchsubmitter
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// adminSpendPath is the path at which the spend of confirmed batches
+	// is queried by the admin server.
+	adminSpendPath = "/admin/spend"
+
+	// defaultAdminSpendBatches is the number of confirmed batches whose
+	// cost is returned by the admin API if no limit is given.
+	defaultAdminSpendBatches = 20
+)
+
+// spendWindows are the trailing windows over which the spend of confirmed
+// batches is accumulated.
+var spendWindows = []struct {
+	name     string
+	duration time.Duration
+}{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+}
+
+// spendBudgetWindow is the trailing window over which spend is held to the
+// configured SpendBudget.
+const spendBudgetWindow = 24 * time.Hour
+
+// BatchCost is the cost of a confirmed batch tx.
+type BatchCost struct {
+	TxHash common.Hash `json:"tx_hash"`
+
+	// Start and End bound the L2 blocks of the batch, [Start, End). Each
+	// L2 block holds exactly one tx.
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	GasUsed           uint64   `json:"gas_used"`
+	EffectiveGasPrice *big.Int `json:"effective_gas_price"`
+
+	// Cost is GasUsed times EffectiveGasPrice, in wei.
+	Cost *big.Int `json:"cost"`
+
+	// CostPerL2Tx is Cost divided by the number of L2 txs in the batch,
+	// in wei.
+	CostPerL2Tx *big.Int `json:"cost_per_l2_tx"`
+
+	ConfirmedAt time.Time `json:"confirmed_at"`
+}
+
+// WindowSpend is the spend of the batches confirmed within a trailing window.
+type WindowSpend struct {
+	Window     string `json:"window"`
+	NumBatches uint64 `json:"num_batches"`
+	NumL2Txs   uint64 `json:"num_l2_txs"`
+
+	// Spend is the total cost of the batches, in wei.
+	Spend *big.Int `json:"spend"`
+
+	// SpendPerL2Tx is Spend divided by NumL2Txs, in wei, or zero if no L2
+	// txs were batched.
+	SpendPerL2Tx *big.Int `json:"spend_per_l2_tx"`
+}
+
+// SpendReport summarizes the spend of the batches confirmed since startup, as
+// returned by the admin API.
+type SpendReport struct {
+	// Total is the spend since startup.
+	Total WindowSpend `json:"total"`
+
+	// Windows are the spends over each trailing window.
+	Windows []WindowSpend `json:"windows"`
+
+	// Recent are the costs of the most recently confirmed batches, most
+	// recent first.
+	Recent []BatchCost `json:"recent"`
+}
+
+// spendTracker accumulates the costs of confirmed batches, retaining those
+// within the longest spend window.
+//
+// NOTE: spendTracker is safe for concurrent use.
+type spendTracker struct {
+	mu    sync.Mutex
+	costs []BatchCost
+	total WindowSpend
+}
+
+// newSpendTracker initializes a spendTracker with no recorded spend.
+func newSpendTracker() *spendTracker {
+	return &spendTracker{
+		total: WindowSpend{
+			Window: "total",
+			Spend:  new(big.Int),
+		},
+	}
+}
+
+// Record adds the cost of a confirmed batch.
+func (t *spendTracker) Record(cost BatchCost) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.costs = append(t.costs, cost)
+	t.total.NumBatches++
+	t.total.NumL2Txs += cost.End - cost.Start
+	t.total.Spend.Add(t.total.Spend, cost.Cost)
+
+	t.pruneLocked(cost.ConfirmedAt)
+}
+
+// Report summarizes the spend as of now, including the costs of up to limit of
+// the most recently confirmed batches.
+func (t *spendTracker) Report(now time.Time, limit int) SpendReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+
+	total := t.total
+	total.Spend = new(big.Int).Set(t.total.Spend)
+	total.SpendPerL2Tx = spendPerL2Tx(total.Spend, total.NumL2Txs)
+
+	report := SpendReport{
+		Total:   total,
+		Windows: make([]WindowSpend, 0, len(spendWindows)),
+	}
+	for _, window := range spendWindows {
+		spend := WindowSpend{
+			Window: window.name,
+			Spend:  new(big.Int),
+		}
+		cutoff := now.Add(-window.duration)
+		for _, cost := range t.costs {
+			if !cost.ConfirmedAt.After(cutoff) {
+				continue
+			}
+			spend.NumBatches++
+			spend.NumL2Txs += cost.End - cost.Start
+			spend.Spend.Add(spend.Spend, cost.Cost)
+		}
+		spend.SpendPerL2Tx = spendPerL2Tx(spend.Spend, spend.NumL2Txs)
+		report.Windows = append(report.Windows, spend)
+	}
+
+	for i := len(t.costs) - 1; i >= 0 && len(report.Recent) < limit; i-- {
+		report.Recent = append(report.Recent, t.costs[i])
+	}
+
+	return report
+}
+
+// Spent returns the spend of the batches confirmed within window as of now.
+func (t *spendTracker) Spent(now time.Time, window time.Duration) *big.Int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+
+	spent := new(big.Int)
+	cutoff := now.Add(-window)
+	for _, cost := range t.costs {
+		if cost.ConfirmedAt.After(cutoff) {
+			spent.Add(spent, cost.Cost)
+		}
+	}
+
+	return spent
+}
+
+// batchUsage is the gas used and L2 txs batched by a set of confirmed batches.
+type batchUsage struct {
+	NumBatches uint64
+	NumL2Txs   uint64
+	GasUsed    uint64
+}
+
+// Usage returns the gas used and L2 txs batched by the batches confirmed within
+// the longest spend window as of now.
+func (t *spendTracker) Usage(now time.Time) batchUsage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pruneLocked(now)
+
+	var usage batchUsage
+	for _, cost := range t.costs {
+		usage.NumBatches++
+		usage.NumL2Txs += cost.End - cost.Start
+		usage.GasUsed += cost.GasUsed
+	}
+
+	return usage
+}
+
+// pruneLocked drops the costs that have left the longest spend window as of
+// now.
+//
+// NOTE: This method MUST be called while holding t.mu.
+func (t *spendTracker) pruneLocked(now time.Time) {
+	cutoff := now.Add(-spendWindows[len(spendWindows)-1].duration)
+	i := 0
+	for i < len(t.costs) && !t.costs[i].ConfirmedAt.After(cutoff) {
+		i++
+	}
+	t.costs = t.costs[i:]
+}
+
+// spendPerL2Tx divides spend by numL2Txs, returning zero if no L2 txs were
+// batched.
+func spendPerL2Tx(spend *big.Int, numL2Txs uint64) *big.Int {
+	if numL2Txs == 0 {
+		return new(big.Int)
+	}
+
+	return new(big.Int).Div(spend, new(big.Int).SetUint64(numL2Txs))
+}
+
+// effectiveGasPrice returns the price per gas paid by tx once included in a
+// block with the given base fee, which is nil before London.
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil || tx.Type() == types.LegacyTxType {
+		return tx.GasPrice()
+	}
+
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+
+	return price
+}
+
+// recordBatchCost records the cost of the batch tx of sub, confirmed by receipt
+// in the block with the given header. Failures are logged rather than
+// returned, as the batch is confirmed regardless.
+func (s *Service) recordBatchCost(
+	ctx context.Context,
+	sub *batchSubmission,
+	receipt *types.Receipt,
+	header *types.Header,
+	confirmedAt time.Time,
+) {
+
+	name := s.cfg.Driver.Name()
+
+	tx, _, err := s.cfg.L1Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		log.Warn(name+" unable to get batch tx for cost accounting",
+			"tx_hash", receipt.TxHash, "err", err)
+		return
+	}
+
+	price := effectiveGasPrice(tx, header.BaseFee)
+	cost := BatchCost{
+		TxHash:            receipt.TxHash,
+		Start:             sub.start.Uint64(),
+		End:               sub.end.Uint64(),
+		GasUsed:           receipt.GasUsed,
+		EffectiveGasPrice: price,
+		Cost: new(big.Int).Mul(
+			price, new(big.Int).SetUint64(receipt.GasUsed),
+		),
+		ConfirmedAt: confirmedAt,
+	}
+	cost.CostPerL2Tx = spendPerL2Tx(cost.Cost, cost.End-cost.Start)
+	s.spend.Record(cost)
+	if s.catchUp != nil {
+		s.catchUp.Record(cost.Cost)
+	}
+
+	log.Info(name+" batch cost recorded", "tx_hash", receipt.TxHash,
+		"cost_eth", weiToEth64(cost.Cost), "cost_per_l2_tx_eth",
+		weiToEth64(cost.CostPerL2Tx))
+
+	s.metrics.BatchCost.Set(weiToEth64(cost.Cost))
+	s.metrics.BatchCostPerL2Tx.Set(weiToEth64(cost.CostPerL2Tx))
+	s.metrics.TotalSpend.Add(weiToEth64(cost.Cost))
+	s.updateSpendMetrics(confirmedAt)
+}
+
+// updateSpendMetrics sets the spend of each trailing window as of now, such
+// that batches leaving a window are reflected even while none confirm.
+func (s *Service) updateSpendMetrics(now time.Time) {
+	for _, spend := range s.spend.Report(now, 0).Windows {
+		s.metrics.WindowSpend.WithLabelValues(spend.Window).Set(
+			weiToEth64(spend.Spend),
+		)
+	}
+}
+
+// spendBudgetExhausted returns true if a SpendBudget is configured and the
+// batches confirmed within spendBudgetWindow as of now spent at least as much.
+func (s *Service) spendBudgetExhausted(now time.Time) bool {
+	if !isPositive(s.cfg.SpendBudget) {
+		return false
+	}
+
+	spent := s.spend.Spent(now, spendBudgetWindow)
+	if spent.Cmp(s.cfg.SpendBudget) < 0 {
+		return false
+	}
+
+	log.Warn(s.cfg.Driver.Name()+" spend budget exhausted, skipping "+
+		"cycle", "spent_eth", weiToEth64(spent),
+		"budget_eth", weiToEth64(s.cfg.SpendBudget),
+		"window", spendBudgetWindow)
+
+	return true
+}
+
+// serveAdminSpend returns the spend report of a service, including the costs
+// of up to limit of the most recently confirmed batches.
+func serveAdminSpend(s *Service, req *http.Request) (interface{}, error) {
+	limit := defaultAdminSpendBatches
+	if v := req.FormValue("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, ErrInvalidAdminParam
+		}
+		limit = n
+	}
+
+	return s.spend.Report(time.Now(), limit), nil
+}
@@ -0,0 +1,143 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEffectiveGasPrice asserts that dynamic fee txs pay the base fee plus
+// their tip, capped at their fee cap, while legacy txs pay their gas price.
+func TestEffectiveGasPrice(t *testing.T) {
+	t.Parallel()
+
+	legacy := types.NewTx(&types.LegacyTx{GasPrice: big.NewInt(30)})
+	require.Equal(t, big.NewInt(30), effectiveGasPrice(legacy, big.NewInt(10)))
+
+	dynamic := types.NewTx(&types.DynamicFeeTx{
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(20),
+	})
+	require.Equal(t, big.NewInt(12), effectiveGasPrice(dynamic, big.NewInt(10)))
+	require.Equal(t, big.NewInt(20), effectiveGasPrice(dynamic, big.NewInt(19)))
+}
+
+// TestSpendTracker asserts that spend is accumulated over each trailing window,
+// and in total since startup.
+func TestSpendTracker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	tracker := newSpendTracker()
+	tracker.Record(BatchCost{
+		Start:       0,
+		End:         10,
+		Cost:        big.NewInt(1000),
+		ConfirmedAt: now.Add(-8 * 24 * time.Hour),
+	})
+	tracker.Record(BatchCost{
+		Start:       10,
+		End:         15,
+		Cost:        big.NewInt(500),
+		ConfirmedAt: now.Add(-2 * time.Hour),
+	})
+	tracker.Record(BatchCost{
+		Start:       15,
+		End:         16,
+		Cost:        big.NewInt(300),
+		ConfirmedAt: now.Add(-time.Minute),
+	})
+
+	report := tracker.Report(now, 2)
+	require.Equal(t, uint64(3), report.Total.NumBatches)
+	require.Equal(t, uint64(16), report.Total.NumL2Txs)
+	require.Equal(t, big.NewInt(1800), report.Total.Spend)
+	require.Equal(t, big.NewInt(112), report.Total.SpendPerL2Tx)
+
+	require.Len(t, report.Windows, 3)
+	require.Equal(t, "1h", report.Windows[0].Window)
+	require.Equal(t, big.NewInt(300), report.Windows[0].Spend)
+	require.Equal(t, big.NewInt(800), report.Windows[1].Spend)
+	require.Equal(t, uint64(6), report.Windows[1].NumL2Txs)
+	require.Equal(t, big.NewInt(800), report.Windows[2].Spend)
+
+	require.Len(t, report.Recent, 2)
+	require.Equal(t, uint64(15), report.Recent[0].Start)
+	require.Equal(t, uint64(10), report.Recent[1].Start)
+}
+
+// TestSpendBudget asserts that cycles are skipped once the batches confirmed
+// within the trailing day spent the configured budget, and resume once the
+// spend leaves the window.
+func TestSpendBudget(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver:      namedDriver{name: "TestSpendBudget"},
+			SpendBudget: big.NewInt(1000),
+		},
+		spend: newSpendTracker(),
+	}
+	s.spend.Record(BatchCost{
+		Start:       0,
+		End:         10,
+		Cost:        big.NewInt(600),
+		ConfirmedAt: now.Add(-23 * time.Hour),
+	})
+	require.False(t, s.spendBudgetExhausted(now))
+
+	s.spend.Record(BatchCost{
+		Start:       10,
+		End:         15,
+		Cost:        big.NewInt(400),
+		ConfirmedAt: now,
+	})
+	require.Equal(t, big.NewInt(1000), s.spend.Spent(now, spendBudgetWindow))
+	require.True(t, s.spendBudgetExhausted(now))
+	require.False(t, s.spendBudgetExhausted(now.Add(2*time.Hour)))
+
+	s.cfg.SpendBudget = nil
+	require.False(t, s.spendBudgetExhausted(now))
+}
+
+// TestAdminSpend asserts that the admin API returns the spend report of the
+// named service.
+func TestAdminSpend(t *testing.T) {
+	t.Parallel()
+
+	server, services := newAdminTestServer(
+		t, namedDriver{name: "TestAdminSpend"},
+	)
+	services[0].spend = newSpendTracker()
+	services[0].spend.Record(BatchCost{
+		Start:       0,
+		End:         4,
+		Cost:        big.NewInt(400),
+		ConfirmedAt: time.Now(),
+	})
+
+	resp, err := http.Get(server.URL + adminSpendPath +
+		"?service=TestAdminSpend")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var report SpendReport
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&report))
+	require.Equal(t, big.NewInt(400), report.Total.Spend)
+	require.Equal(t, big.NewInt(100), report.Total.SpendPerL2Tx)
+	require.Len(t, report.Recent, 1)
+
+	resp, err = http.Get(server.URL + adminSpendPath +
+		"?service=TestAdminSpend&limit=-1")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
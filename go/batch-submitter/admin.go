@@ -0,0 +1,305 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// adminPausePath is the path at which submission is paused by the
+	// metrics server.
+	adminPausePath = "/admin/pause"
+
+	// adminResumePath is the path at which paused submission is resumed
+	// by the admin server.
+	adminResumePath = "/admin/resume"
+
+	// adminCyclePath is the path at which an immediate cycle is triggered
+	// by the admin server.
+	adminCyclePath = "/admin/cycle"
+
+	// adminConfigPath is the path at which the runtime settings are
+	// adjusted by the admin server.
+	adminConfigPath = "/admin/config"
+
+	// adminPendingPath is the path at which the pending tx state is
+	// dumped by the admin server.
+	adminPendingPath = "/admin/pending"
+
+	// adminPendingReplacePath is the path at which the lowest pending tx
+	// is replaced by the admin server.
+	adminPendingReplacePath = "/admin/pending/replace"
+
+	// adminSubmissionsPath is the path at which the recorded submissions
+	// are queried by the admin server.
+	adminSubmissionsPath = "/admin/submissions"
+
+	// defaultAdminSubmissions is the number of recorded submissions
+	// returned by the admin API if no limit is given.
+	defaultAdminSubmissions = 50
+)
+
+// ErrInvalidAdminParam signals an admin request with a missing or malformed
+// parameter.
+var ErrInvalidAdminParam = errors.New("invalid admin parameter")
+
+// ErrMaxTxSizeUnsupported signals an attempt to adjust the max tx size of a
+// service whose Driver does not implement TxSizeLimiter.
+var ErrMaxTxSizeUnsupported = errors.New("driver does not support " +
+	"adjusting max tx size")
+
+// ErrNoStateStore signals a query for recorded submissions of a service
+// without a StateStore.
+var ErrNoStateStore = errors.New("submission state is not recorded")
+
+// RuntimeSettings are the settings of a service that may be adjusted at
+// runtime through the admin API.
+type RuntimeSettings struct {
+	// Paused is true while submission is paused by an operator.
+	Paused bool `json:"paused"`
+
+	// PollInterval is the delay between cycles.
+	PollInterval string `json:"poll_interval"`
+
+	// MaxTxSize is the max size of a batch tx, or zero if the Driver does
+	// not support adjusting it.
+	MaxTxSize uint64 `json:"max_tx_size,omitempty"`
+}
+
+// PendingBatch is a batch tx awaiting confirmation in pipelined mode.
+type PendingBatch struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+	Nonce uint64 `json:"nonce"`
+}
+
+// PendingTxState is the state of the txs published by a service that have yet
+// to confirm, as dumped by the admin API.
+type PendingTxState struct {
+	// LatestNonce and PendingNonce are the nonces of the service's wallet
+	// as reported by the L1 backend. Any nonces in between are held by
+	// txs pending in the L1 tx pool.
+	LatestNonce  uint64 `json:"latest_nonce"`
+	PendingNonce uint64 `json:"pending_nonce"`
+
+	// InFlight are the batch txs awaiting confirmation in pipelined mode.
+	InFlight []PendingBatch `json:"in_flight"`
+
+	// Submissions are the pending records of the configured StateStore,
+	// if any.
+	Submissions []*queue.SubmissionRecord `json:"submissions,omitempty"`
+}
+
+// adminFunc performs an admin operation on s, returning the JSON response.
+type adminFunc func(s *Service, req *http.Request) (interface{}, error)
+
+// adminHandler serves a single admin operation on the services of a registry,
+// the service being named by the service form value.
+type adminHandler struct {
+	registry *statusRegistry
+	method   string
+	serve    adminFunc
+}
+
+// ServeHTTP performs the handler's operation on the named service, returning
+// its result as JSON.
+func (h adminHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != h.method {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s, ok := h.registry.lookup(req.FormValue("service"))
+	if !ok {
+		http.Error(w, "unknown service", http.StatusNotFound)
+		return
+	}
+
+	resp, err := h.serve(s, req)
+	switch {
+	case errors.Is(err, ErrInvalidAdminParam),
+		errors.Is(err, ErrMaxTxSizeUnsupported),
+		errors.Is(err, ErrUnknownCatchUpAction):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, ErrNoStateStore),
+		errors.Is(err, ErrNoCostHistory),
+		errors.Is(err, ErrNoCatchUpPlan),
+		errors.Is(err, ErrNoPendingTxs):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("Unable to write admin response", "err", err)
+	}
+}
+
+// adminHandlers returns the handlers of the admin API for the services of
+// registry, keyed by path. Mutations are guarded by auth, if non-nil.
+func adminHandlers(
+	registry *statusRegistry, auth *operatorAuth) map[string]http.Handler {
+
+	handler := func(method string, serve adminFunc) http.Handler {
+		return adminHandler{
+			registry: registry,
+			method:   method,
+			serve:    serve,
+		}
+	}
+
+	handlers := map[string]http.Handler{
+		adminQuarantinePath: quarantineHandler{
+			registry: registry,
+		},
+		adminLogPath: logLevelsHandler{
+			filter: defaultLogFilter,
+		},
+		adminPausePath: handler(http.MethodPost, func(
+			s *Service, _ *http.Request) (interface{}, error) {
+
+			s.Pause()
+			return s.RuntimeSettings(), nil
+		}),
+		adminResumePath: handler(http.MethodPost, func(
+			s *Service, _ *http.Request) (interface{}, error) {
+
+			s.Resume()
+			return s.RuntimeSettings(), nil
+		}),
+		adminCyclePath: handler(http.MethodPost, func(
+			s *Service, _ *http.Request) (interface{}, error) {
+
+			s.TriggerCycle()
+			return s.RuntimeSettings(), nil
+		}),
+		adminConfigPath: handler(http.MethodPost, serveAdminConfig),
+		adminPendingPath: handler(http.MethodGet, func(
+			s *Service, req *http.Request) (interface{}, error) {
+
+			return s.PendingTxState(req.Context())
+		}),
+		adminPendingReplacePath: handler(http.MethodPost,
+			serveAdminPendingReplace),
+		adminSubmissionsPath: handler(http.MethodGet,
+			serveAdminSubmissions),
+		adminSpendPath: handler(http.MethodGet, serveAdminSpend),
+		adminForecastPath: handler(http.MethodGet,
+			serveAdminForecast),
+		adminCatchUpPath: handler(http.MethodPost, serveAdminCatchUp),
+	}
+	for path, h := range handlers {
+		handlers[path] = auth.protect(h)
+	}
+
+	return handlers
+}
+
+// serveAdminConfig adjusts the settings given by the poll_interval and
+// max_tx_size form values, leaving any that are absent unchanged.
+func serveAdminConfig(s *Service, req *http.Request) (interface{}, error) {
+	var (
+		pollInterval time.Duration
+		maxTxSize    uint64
+		err          error
+	)
+	if v := req.FormValue("poll_interval"); v != "" {
+		pollInterval, err = time.ParseDuration(v)
+		if err != nil || pollInterval <= 0 {
+			return nil, ErrInvalidAdminParam
+		}
+	}
+	if v := req.FormValue("max_tx_size"); v != "" {
+		maxTxSize, err = strconv.ParseUint(v, 10, 64)
+		if err != nil || maxTxSize == 0 {
+			return nil, ErrInvalidAdminParam
+		}
+		if err := validateMaxTxSize(s, maxTxSize); err != nil {
+			return nil, err
+		}
+	}
+
+	// Every setting was validated above before applying any, such that a
+	// rejected request leaves the settings unchanged.
+	if maxTxSize != 0 {
+		if err := s.SetMaxTxSize(maxTxSize); err != nil {
+			return nil, err
+		}
+	}
+	if pollInterval != 0 {
+		if err := s.SetPollInterval(pollInterval); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.RuntimeSettings(), nil
+}
+
+// serveAdminPendingReplace replaces the lowest pending tx using the strategy
+// given by the strategy form value, or the configured pending tx strategy if
+// absent. The request blocks until the replacement or the original tx confirms.
+func serveAdminPendingReplace(
+	s *Service, req *http.Request) (interface{}, error) {
+
+	strategy := req.FormValue("strategy")
+	switch strategy {
+	case "":
+		strategy = s.cfg.PendingTxStrategy
+	case PendingTxStrategyCancel, PendingTxStrategyReplace:
+	default:
+		return nil, ErrInvalidAdminParam
+	}
+
+	return s.ReplacePendingTx(req.Context(), strategy)
+}
+
+// serveAdminSubmissions returns up to limit of the most recently recorded
+// submissions, most recent nonce first.
+func serveAdminSubmissions(
+	s *Service, req *http.Request) (interface{}, error) {
+
+	limit := defaultAdminSubmissions
+	if v := req.FormValue("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, ErrInvalidAdminParam
+		}
+		limit = n
+	}
+
+	if s.cfg.StateStore == nil {
+		return nil, ErrNoStateStore
+	}
+
+	return s.cfg.StateStore.Recent(limit)
+}
+
+// runAdminServer spins up the admin API server at the provided hostname and
+// port, guarded by auth. The admin server is kept apart from the metrics
+// server, such that the admin API is only exposed where explicitly enabled.
+//
+// NOTE: This method MUST be run as a goroutine.
+func runAdminServer(hostname string, port uint64, auth *operatorAuth) {
+	adminAddr := fmt.Sprintf("%s:%s", hostname,
+		strconv.FormatUint(port, 10))
+
+	mux := http.NewServeMux()
+	for path, handler := range adminHandlers(defaultStatusRegistry, auth) {
+		mux.Handle(path, handler)
+	}
+
+	log.Info("Starting admin server", "addr", adminAddr)
+	err := http.ListenAndServe(adminAddr, mux)
+	log.Error("Admin server stopped", "err", err)
+}
@@ -0,0 +1,564 @@
+package batchsubmitter
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/urfave/cli"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/flags"
+)
+
+const (
+	// adminSignatureParam is the form value carrying an operator's
+	// signature of an admin action.
+	adminSignatureParam = "signature"
+
+	// adminTimestampParam is the form value carrying the unix time at
+	// which an admin action was signed.
+	adminTimestampParam = "timestamp"
+
+	// adminSignatureMaxAge bounds how far the timestamp of a signed admin
+	// action may drift from the local clock, bounding the window in which
+	// its signature must be remembered to reject replays.
+	adminSignatureMaxAge = 5 * time.Minute
+
+	// adminActionDomain prefixes every signed admin action, such that an
+	// operator's signature can never be mistaken for one over another
+	// kind of message.
+	adminActionDomain = "batch-submitter admin action"
+)
+
+var (
+	// ErrAdminSignatureRequired signals an admin mutation without an
+	// operator signature or timestamp.
+	ErrAdminSignatureRequired = errors.New("admin action must be signed " +
+		"by an operator")
+
+	// ErrInvalidAdminSignature signals an admin mutation whose signature
+	// is malformed or does not recover to a signer.
+	ErrInvalidAdminSignature = errors.New("invalid admin signature")
+
+	// ErrAdminSignatureExpired signals an admin mutation signed outside of
+	// the accepted time window.
+	ErrAdminSignatureExpired = errors.New("admin signature expired")
+
+	// ErrAdminSignatureReplayed signals an admin mutation whose signature
+	// has already been accepted.
+	ErrAdminSignatureReplayed = errors.New("admin signature already used")
+
+	// ErrUnknownOperator signals an admin mutation signed by a key that is
+	// not a configured operator.
+	ErrUnknownOperator = errors.New("signer is not a configured operator")
+
+	// ErrAdminMutationsDisabled signals an admin mutation while no
+	// operators are configured to sign it.
+	ErrAdminMutationsDisabled = errors.New("admin mutations are disabled " +
+		"without configured operators")
+
+	// ErrOperatorOutOfScope signals an admin mutation signed by an
+	// operator of one tenant that targets a service of another tenant, or
+	// the process as a whole.
+	ErrOperatorOutOfScope = errors.New("operator is not authorized for " +
+		"this tenant")
+)
+
+// AdminActionMessage returns the message signed by an operator to perform the
+// admin action at path with the given form values, which must include the
+// timestamp. Any signature form value is excluded, and the remaining values
+// are sorted by key such that the message can be rebuilt from an audit entry.
+func AdminActionMessage(path string, params url.Values) []byte {
+	signed := make(url.Values, len(params))
+	for key, values := range params {
+		if key != adminSignatureParam {
+			signed[key] = values
+		}
+	}
+
+	return []byte(fmt.Sprintf("%s\npath: %s\nparams: %s",
+		adminActionDomain, path, signed.Encode()))
+}
+
+// SignAdminAction signs the admin action at path with the given form values
+// as an EIP-191 personal message, returning the form values to post along with
+// the timestamp and signature. The timestamp is set to the current time unless
+// already present.
+func SignAdminAction(
+	key *ecdsa.PrivateKey,
+	path string,
+	params url.Values,
+) (url.Values, error) {
+
+	signed := make(url.Values, len(params)+2)
+	for key, values := range params {
+		signed[key] = values
+	}
+	signed.Del(adminSignatureParam)
+	if signed.Get(adminTimestampParam) == "" {
+		signed.Set(adminTimestampParam,
+			strconv.FormatInt(time.Now().Unix(), 10))
+	}
+
+	sig, err := crypto.Sign(adminActionHash(path, signed), key)
+	if err != nil {
+		return nil, err
+	}
+	sig[crypto.RecoveryIDOffset] += 27
+	signed.Set(adminSignatureParam, hexutil.Encode(sig))
+
+	return signed, nil
+}
+
+// adminActionHash returns the EIP-191 digest signed by an operator to perform
+// the admin action at path with the given form values.
+func adminActionHash(path string, params url.Values) []byte {
+	return accounts.TextHash(AdminActionMessage(path, params))
+}
+
+// recoverAdminSigner returns the address whose key produced sig over the admin
+// action at path with the given form values. Signatures with a recovery id of
+// either 0/1 or 27/28 are accepted, but only in their canonical low-s form,
+// such that a signature cannot be malleated into another valid one.
+func recoverAdminSigner(
+	path string,
+	params url.Values,
+	sig []byte,
+) (common.Address, error) {
+
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, ErrInvalidAdminSignature
+	}
+	sig = common.CopyBytes(sig)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:64])
+	if !crypto.ValidateSignatureValues(
+		sig[crypto.RecoveryIDOffset], r, s, true,
+	) {
+		return common.Address{}, ErrInvalidAdminSignature
+	}
+
+	pub, err := crypto.SigToPub(adminActionHash(path, params), sig)
+	if err != nil {
+		return common.Address{}, ErrInvalidAdminSignature
+	}
+
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// AuditEntry records an admin mutation, whether performed or rejected. The
+// signature of a performed action can be verified against the message rebuilt
+// by AdminActionMessage from Path and Params.
+type AuditEntry struct {
+	// Time is the time at which the action was received.
+	Time time.Time `json:"time"`
+
+	// Operator is the address of the operator that signed the action, if
+	// it carried a valid signature.
+	Operator *common.Address `json:"operator,omitempty"`
+
+	// Path is the admin path of the action.
+	Path string `json:"path"`
+
+	// Params are the URL-encoded form values of the action, excluding
+	// the signature.
+	Params string `json:"params"`
+
+	// Signature is the operator's signature of the action, if any.
+	Signature string `json:"signature,omitempty"`
+
+	// Status is the HTTP status with which the action was answered.
+	Status int `json:"status"`
+
+	// Error is the reason the action was rejected, if it was.
+	Error string `json:"error,omitempty"`
+}
+
+// auditTrail appends AuditEntries to a file as JSON lines, syncing each entry
+// to disk before the action it records is answered.
+//
+// NOTE: auditTrail is safe for concurrent use.
+type auditTrail struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// openAuditTrail opens the audit trail at path, creating it if it does not
+// exist and appending to it otherwise.
+func openAuditTrail(path string) (*auditTrail, error) {
+	file, err := os.OpenFile(
+		path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auditTrail{
+		file: file,
+	}, nil
+}
+
+// record appends entry to the audit trail.
+func (t *auditTrail) record(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, err := t.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return t.file.Sync()
+}
+
+// operatorAuth requires every admin mutation to be signed by one of the
+// configured operators, rejecting every mutation if none are, and records each
+// mutation in the audit trail, if configured. Read-only requests are served
+// unchanged.
+//
+// Operators may also be scoped to a single tenant, in which case they may only
+// mutate the services of that tenant.
+type operatorAuth struct {
+	operators map[common.Address]struct{}
+	trail     *auditTrail
+	now       func() time.Time
+
+	mu sync.Mutex
+
+	// tenants maps each tenant-scoped operator to its tenant.
+	tenants map[common.Address]string
+
+	// seen holds the digest of each accepted action until its timestamp
+	// leaves the accepted window, keyed such that no other signature over
+	// the same action can be replayed.
+	seen map[common.Hash]time.Time
+}
+
+// newOperatorAuth initializes an operatorAuth accepting mutations signed by
+// operators, or no mutations if operators is empty. A nil trail records
+// nothing.
+func newOperatorAuth(
+	operators []common.Address, trail *auditTrail) *operatorAuth {
+
+	set := make(map[common.Address]struct{}, len(operators))
+	for _, operator := range operators {
+		set[operator] = struct{}{}
+	}
+
+	return &operatorAuth{
+		operators: set,
+		trail:     trail,
+		now:       time.Now,
+		tenants:   make(map[common.Address]string),
+		seen:      make(map[common.Hash]time.Time),
+	}
+}
+
+// scopeTenant accepts mutations signed by operators for the services of tenant
+// only, i.e. those whose names carry the tenant's prefix.
+func (a *operatorAuth) scopeTenant(tenant string, operators []common.Address) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, operator := range operators {
+		a.tenants[operator] = tenant
+	}
+	log.Info("Guarding tenant admin API", "tenant", tenant,
+		"operators", len(operators))
+}
+
+// serviceScoped is implemented by the admin handlers acting on the single
+// service named by the service form value, as resolved through
+// statusRegistry.lookup. Tenant-scoped operators may only use those handlers,
+// every other path acting on the process as a whole.
+type serviceScoped interface {
+	serviceScoped()
+}
+
+func (adminHandler) serviceScoped()      {}
+func (quarantineHandler) serviceScoped() {}
+
+// authorize returns nil if signer may perform the admin action of req, either
+// as an operator of every tenant or, if scoped is true, as an operator of the
+// tenant of the named service. Actions that are not scoped to a service are
+// reserved to operators of every tenant, whatever the form holds.
+func (a *operatorAuth) authorize(
+	signer common.Address, req *http.Request, scoped bool) error {
+
+	if _, ok := a.operators[signer]; ok {
+		return nil
+	}
+
+	a.mu.Lock()
+	tenant, ok := a.tenants[signer]
+	a.mu.Unlock()
+	if !ok {
+		return ErrUnknownOperator
+	}
+	if !scoped {
+		return ErrOperatorOutOfScope
+	}
+
+	// Service names are prefixed by their tenant's name, see
+	// tenantPrefix.
+	if !strings.HasPrefix(req.Form.Get("service"), tenant+"_") {
+		return ErrOperatorOutOfScope
+	}
+
+	return nil
+}
+
+// newAdminAuth initializes the operatorAuth guarding the admin API from the
+// configured operators and audit trail. Without operators, every mutation is
+// rejected.
+func newAdminAuth(cfg Config) (*operatorAuth, error) {
+	operators, err := ParseAdminOperators(cfg.AdminOperators)
+	if err != nil {
+		return nil, err
+	}
+
+	var trail *auditTrail
+	if cfg.AdminAuditLog != "" {
+		trail, err = openAuditTrail(cfg.AdminAuditLog)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	log.Info("Guarding admin API", "operators", len(operators),
+		"audit_log", cfg.AdminAuditLog)
+
+	return newOperatorAuth(operators, trail), nil
+}
+
+// ParseAdminOperators parses a comma-separated list of operator addresses.
+func ParseAdminOperators(addresses string) ([]common.Address, error) {
+	var operators []common.Address
+	for _, address := range strings.Split(addresses, ",") {
+		address = strings.TrimSpace(address)
+		if address == "" {
+			continue
+		}
+		operator, err := ParseAddress(address)
+		if err != nil {
+			return nil, err
+		}
+		operators = append(operators, operator)
+	}
+
+	return operators, nil
+}
+
+// verify returns the operator that signed the admin action of req, which acts
+// on a single service if scoped is true. The form of req MUST already be
+// parsed.
+func (a *operatorAuth) verify(
+	req *http.Request, scoped bool) (*common.Address, error) {
+
+	a.mu.Lock()
+	numOperators := len(a.operators) + len(a.tenants)
+	a.mu.Unlock()
+	if numOperators == 0 {
+		return nil, ErrAdminMutationsDisabled
+	}
+
+	sigHex := req.Form.Get(adminSignatureParam)
+	timestamp := req.Form.Get(adminTimestampParam)
+	if sigHex == "" || timestamp == "" {
+		return nil, ErrAdminSignatureRequired
+	}
+
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAdminParam, err)
+	}
+	now := a.now()
+	age := now.Sub(time.Unix(signedAt, 0))
+	if age > adminSignatureMaxAge || age < -adminSignatureMaxAge {
+		return nil, ErrAdminSignatureExpired
+	}
+
+	sig, err := hexutil.Decode(sigHex)
+	if err != nil {
+		return nil, ErrInvalidAdminSignature
+	}
+	signer, err := recoverAdminSigner(req.URL.Path, req.Form, sig)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.authorize(signer, req, scoped); err != nil {
+		return &signer, err
+	}
+
+	// Remember the signed action until its timestamp leaves the accepted
+	// window, after which a replay is rejected as expired instead. Keying
+	// on the signed digest rather than the signature rejects a replay
+	// under any other encoding of the signature.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for seenHash, expiry := range a.seen {
+		if now.After(expiry) {
+			delete(a.seen, seenHash)
+		}
+	}
+	key := common.BytesToHash(adminActionHash(req.URL.Path, req.Form))
+	if _, ok := a.seen[key]; ok {
+		return &signer, ErrAdminSignatureReplayed
+	}
+	a.seen[key] = time.Unix(signedAt, 0).Add(adminSignatureMaxAge)
+
+	return &signer, nil
+}
+
+// protect wraps the handler of an admin path, verifying and recording every
+// request other than a GET before it is served. Tenant-scoped operators are
+// only accepted if next implements serviceScoped. A nil operatorAuth rejects
+// every mutation, as if no operators were configured.
+func (a *operatorAuth) protect(next http.Handler) http.Handler {
+	if a == nil {
+		a = newOperatorAuth(nil, nil)
+	}
+
+	_, scoped := next.(serviceScoped)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodGet {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		entry := AuditEntry{
+			Time:      a.now(),
+			Path:      req.URL.Path,
+			Params:    adminAuditParams(req.Form),
+			Signature: req.Form.Get(adminSignatureParam),
+		}
+
+		operator, err := a.verify(req, scoped)
+		entry.Operator = operator
+		if err != nil {
+			status := http.StatusForbidden
+			switch {
+			case errors.Is(err, ErrInvalidAdminParam):
+				status = http.StatusBadRequest
+			case errors.Is(err, ErrAdminSignatureRequired):
+				status = http.StatusUnauthorized
+			}
+			entry.Status = status
+			entry.Error = err.Error()
+			a.record(entry)
+
+			log.Warn("Rejected admin action", "path", entry.Path,
+				"operator", operator, "err", err)
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, req)
+
+		entry.Status = rec.status
+		a.record(entry)
+		log.Info("Performed admin action", "path", entry.Path,
+			"operator", operator, "params", entry.Params,
+			"status", rec.status)
+	})
+}
+
+// record appends entry to the audit trail, if configured.
+func (a *operatorAuth) record(entry AuditEntry) {
+	if a.trail == nil {
+		return
+	}
+	if err := a.trail.record(entry); err != nil {
+		log.Error("Unable to record admin action", "path", entry.Path,
+			"err", err)
+	}
+}
+
+// adminAuditParams encodes the form values of an admin action as signed,
+// excluding the signature.
+func adminAuditParams(form url.Values) string {
+	params := make(url.Values, len(form))
+	for key, values := range form {
+		if key != adminSignatureParam {
+			params[key] = values
+		}
+	}
+
+	return params.Encode()
+}
+
+// statusRecorder is an http.ResponseWriter recording the status written by a
+// handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader records status before writing it.
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// SignAdmin signs the admin action given by its path and key=value form
+// arguments with the operator key, printing the URL-encoded form to post to
+// the admin API.
+func SignAdmin(ctx *cli.Context) error {
+	if ctx.NArg() < 1 {
+		return fmt.Errorf("%w: admin path", ErrInvalidAdminParam)
+	}
+	path := ctx.Args().First()
+
+	params := make(url.Values)
+	for _, arg := range ctx.Args().Tail() {
+		parts := strings.SplitN(arg, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%w: %q", ErrInvalidAdminParam, arg)
+		}
+		params.Add(parts[0], parts[1])
+	}
+
+	key, err := ParsePrivateKeyStr(
+		ctx.String(flags.OperatorPrivateKeyFlag.Name),
+	)
+	if err != nil {
+		return err
+	}
+
+	signed, err := SignAdminAction(key, path, params)
+	if err != nil {
+		return err
+	}
+	fmt.Println(signed.Encode())
+
+	return nil
+}
@@ -0,0 +1,322 @@
+package batchsubmitter
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// readAuditTrail returns the entries appended to the audit trail at path.
+func readAuditTrail(t *testing.T, path string) []AuditEntry {
+	file, err := os.Open(path)
+	require.Nil(t, err)
+	defer file.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		require.Nil(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	require.Nil(t, scanner.Err())
+
+	return entries
+}
+
+// TestAdminRequiresOperatorSignature asserts that admin mutations are only
+// performed if freshly signed by a configured operator, and that every
+// mutation is recorded in the audit trail along with a verifiable signature.
+func TestAdminRequiresOperatorSignature(t *testing.T) {
+	t.Parallel()
+
+	operatorKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	otherKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	operator := crypto.PubkeyToAddress(operatorKey.PublicKey)
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	trail, err := openAuditTrail(auditPath)
+	require.Nil(t, err)
+	auth := newOperatorAuth(nil, trail)
+	auth.operators[operator] = struct{}{}
+
+	name := "TestAdminRequiresOperatorSignature"
+	server, services := newGuardedAdminTestServer(
+		t, auth, namedDriver{name: name},
+	)
+	s := services[0]
+	form := url.Values{"service": {name}}
+
+	code, _ := postAdminRaw(t, server, adminPausePath, form)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.False(t, s.Paused())
+
+	signed, err := SignAdminAction(otherKey, adminPausePath, form)
+	require.Nil(t, err)
+	code, _ = postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusForbidden, code)
+	require.False(t, s.Paused())
+
+	// A signature over another action is rejected.
+	signed, err = SignAdminAction(operatorKey, adminResumePath, form)
+	require.Nil(t, err)
+	code, _ = postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusForbidden, code)
+
+	signedAt := time.Now().Add(-time.Hour).Unix()
+	stale := url.Values{
+		"service":   {name},
+		"timestamp": {strconv.FormatInt(signedAt, 10)},
+	}
+	signed, err = SignAdminAction(operatorKey, adminPausePath, stale)
+	require.Nil(t, err)
+	code, _ = postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusForbidden, code)
+
+	signed, err = SignAdminAction(operatorKey, adminPausePath, form)
+	require.Nil(t, err)
+	code, settings := postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, settings.Paused)
+	require.True(t, s.Paused())
+
+	// The same signed action cannot be replayed.
+	code, _ = postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusForbidden, code)
+
+	entries := readAuditTrail(t, auditPath)
+	require.Len(t, entries, 6)
+
+	var statuses []int
+	for _, entry := range entries {
+		statuses = append(statuses, entry.Status)
+	}
+	require.Equal(t, []int{
+		http.StatusUnauthorized, http.StatusForbidden,
+		http.StatusForbidden, http.StatusForbidden,
+		http.StatusOK, http.StatusForbidden,
+	}, statuses)
+
+	performed := entries[4]
+	require.Equal(t, &operator, performed.Operator)
+	require.Equal(t, adminPausePath, performed.Path)
+	require.Empty(t, performed.Error)
+
+	params, err := url.ParseQuery(performed.Params)
+	require.Nil(t, err)
+	require.Equal(t, name, params.Get("service"))
+	sig, err := hexutil.Decode(performed.Signature)
+	require.Nil(t, err)
+	signer, err := recoverAdminSigner(performed.Path, params, sig)
+	require.Nil(t, err)
+	require.Equal(t, operator, signer)
+}
+
+// TestAdminRejectsMalleatedSignature asserts that a signature is only accepted
+// in its canonical low-s form, and that an accepted action cannot be replayed
+// under another encoding of its signature.
+func TestAdminRejectsMalleatedSignature(t *testing.T) {
+	t.Parallel()
+
+	name := "TestAdminRejectsMalleatedSignature"
+	server, services := newAdminTestServer(t, namedDriver{name: name})
+	s := services[0]
+
+	signed := signAdmin(t, adminPausePath, url.Values{"service": {name}})
+	sig, err := hexutil.Decode(signed.Get(adminSignatureParam))
+	require.Nil(t, err)
+
+	malleate := func(sig []byte) url.Values {
+		malleated := make(url.Values, len(signed))
+		for key, values := range signed {
+			malleated[key] = values
+		}
+		malleated.Set(adminSignatureParam, hexutil.Encode(sig))
+		return malleated
+	}
+
+	// The high-s counterpart of the signature recovers to the same signer,
+	// but is rejected.
+	highS := common.CopyBytes(sig)
+	copy(highS[32:64], common.LeftPadBytes(new(big.Int).Sub(
+		crypto.S256().Params().N, new(big.Int).SetBytes(sig[32:64]),
+	).Bytes(), 32))
+	highS[crypto.RecoveryIDOffset] ^= 1
+	code, _ := postAdminRaw(t, server, adminPausePath, malleate(highS))
+	require.Equal(t, http.StatusForbidden, code)
+	require.False(t, s.Paused())
+
+	code, _ = postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, s.Paused())
+	s.Resume()
+
+	// Re-encoding the recovery id does not evade replay protection.
+	reencoded := common.CopyBytes(sig)
+	reencoded[crypto.RecoveryIDOffset] -= 27
+	code, _ = postAdminRaw(t, server, adminPausePath, malleate(reencoded))
+	require.Equal(t, http.StatusForbidden, code)
+	require.False(t, s.Paused())
+}
+
+// TestAdminMutationsDisabledWithoutOperators asserts that mutations are
+// rejected when no operators are configured, whether signed or not, while
+// still being recorded in the audit trail.
+func TestAdminMutationsDisabledWithoutOperators(t *testing.T) {
+	t.Parallel()
+
+	auditPath := filepath.Join(t.TempDir(), "audit.log")
+	trail, err := openAuditTrail(auditPath)
+	require.Nil(t, err)
+
+	name := "TestAdminMutationsDisabledWithoutOperators"
+	server, services := newGuardedAdminTestServer(
+		t, newOperatorAuth(nil, trail), namedDriver{name: name},
+	)
+	form := url.Values{"service": {name}}
+
+	code, _ := postAdminRaw(t, server, adminPausePath, form)
+	require.Equal(t, http.StatusForbidden, code)
+	code, _ = postAdmin(t, server, adminPausePath, form)
+	require.Equal(t, http.StatusForbidden, code)
+	require.False(t, services[0].Paused())
+
+	entries := readAuditTrail(t, auditPath)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		require.Equal(t, http.StatusForbidden, entry.Status)
+		require.Equal(t, ErrAdminMutationsDisabled.Error(), entry.Error)
+	}
+
+	// A nil operatorAuth likewise rejects every mutation.
+	server, services = newGuardedAdminTestServer(
+		t, nil, namedDriver{name: name},
+	)
+	code, _ = postAdmin(t, server, adminPausePath, form)
+	require.Equal(t, http.StatusForbidden, code)
+	require.False(t, services[0].Paused())
+}
+
+// TestAdminTenantOperatorScope asserts that an operator scoped to a tenant may
+// only mutate the services of that tenant, while operators of every tenant may
+// mutate any service.
+func TestAdminTenantOperatorScope(t *testing.T) {
+	t.Parallel()
+
+	globalKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	auth := newOperatorAuth([]common.Address{
+		crypto.PubkeyToAddress(globalKey.PublicKey),
+	}, nil)
+	auth.scopeTenant("alpha", []common.Address{
+		crypto.PubkeyToAddress(adminTestKey.PublicKey),
+	})
+
+	server, services := newGuardedAdminTestServer(
+		t, auth,
+		namedDriver{name: "alpha_Sequencer"},
+		namedDriver{name: "alphabet_Sequencer"},
+		namedDriver{name: "beta_Sequencer"},
+	)
+
+	code, _ := postAdmin(t, server, adminPausePath, url.Values{
+		"service": {"alpha_Sequencer"},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, services[0].Paused())
+
+	for i, name := range []string{"alphabet_Sequencer", "beta_Sequencer"} {
+		code, _ = postAdmin(t, server, adminPausePath, url.Values{
+			"service": {name},
+		})
+		require.Equal(t, http.StatusForbidden, code)
+		require.False(t, services[i+1].Paused())
+	}
+
+	// Process-wide actions are reserved to operators of every tenant.
+	code, _ = postAdmin(t, server, adminLogPath, url.Values{
+		"module": {"txmgr"},
+		"level":  {"debug"},
+	})
+	require.Equal(t, http.StatusForbidden, code)
+
+	// Naming a service of the tenant does not scope a process-wide action.
+	levels := defaultLogFilter.Levels()
+	code, _ = postAdmin(t, server, adminLogPath, url.Values{
+		"service": {"alpha_Sequencer"},
+		"level":   {"trace"},
+	})
+	require.Equal(t, http.StatusForbidden, code)
+	require.Equal(t, levels, defaultLogFilter.Levels())
+
+	form := url.Values{
+		"service": {"beta_Sequencer"},
+		adminTimestampParam: {
+			strconv.FormatInt(time.Now().Unix(), 10),
+		},
+	}
+	signed, err := SignAdminAction(globalKey, adminPausePath, form)
+	require.Nil(t, err)
+	code, _ = postAdminRaw(t, server, adminPausePath, signed)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, services[2].Paused())
+}
+
+// TestAdminQuarantineRequiresOperatorSignature asserts that a quarantined range
+// is only released if signed by a configured operator, and never released while
+// no operators are configured.
+func TestAdminQuarantineRequiresOperatorSignature(t *testing.T) {
+	t.Parallel()
+
+	name := "TestAdminQuarantineRequiresOperatorSignature"
+	m := metrics.NewMetrics(name)
+	quarantine := func(s *Service) {
+		s.metrics = m
+		s.retries = newRetryBudget(1)
+		require.NotNil(t, s.retries.Fail(10, 20, errors.New("failed")))
+	}
+	form := url.Values{
+		"service": {name},
+		"action":  {string(QuarantineForce)},
+	}
+
+	server, services := newAdminTestServer(t, namedDriver{name: name})
+	s := services[0]
+	quarantine(s)
+
+	code, _ := postAdminRaw(t, server, adminQuarantinePath, form)
+	require.Equal(t, http.StatusUnauthorized, code)
+	require.NotNil(t, s.retries.Quarantined())
+
+	code, _ = postAdmin(t, server, adminQuarantinePath, form)
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, s.retries.Quarantined())
+
+	// Without operators, the quarantine cannot be released even if signed.
+	server, services = newGuardedAdminTestServer(
+		t, newOperatorAuth(nil, nil), namedDriver{name: name},
+	)
+	s = services[0]
+	quarantine(s)
+
+	code, _ = postAdmin(t, server, adminQuarantinePath, form)
+	require.Equal(t, http.StatusForbidden, code)
+	require.NotNil(t, s.retries.Quarantined())
+}
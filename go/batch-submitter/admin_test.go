@@ -0,0 +1,258 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// limitedDriver is a Driver that only implements Name and TxSizeLimiter.
+type limitedDriver struct {
+	namedDriver
+	maxTxSize uint64
+}
+
+func (d *limitedDriver) MaxTxSize() uint64 {
+	return d.maxTxSize
+}
+
+func (d *limitedDriver) SetMaxTxSize(size uint64) {
+	d.maxTxSize = size
+}
+
+// adminTestKey is the key of the operator signing the admin actions posted by
+// postAdmin.
+var adminTestKey, _ = crypto.HexToECDSA(
+	"4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318",
+)
+
+// newAdminTestServer serves the admin API for the given drivers, each run by a
+// service polling once per minute, accepting mutations signed by adminTestKey.
+func newAdminTestServer(
+	t *testing.T, drivers ...Driver) (*httptest.Server, []*Service) {
+
+	auth := newOperatorAuth([]common.Address{
+		crypto.PubkeyToAddress(adminTestKey.PublicKey),
+	}, nil)
+
+	return newGuardedAdminTestServer(t, auth, drivers...)
+}
+
+// adminTestActions counts the actions signed by signAdmin, each of which is
+// timestamped a second earlier than the last, such that repeating an action is
+// not rejected as a replay.
+var adminTestActions int64
+
+// signAdmin signs the admin action at path with the given form values by
+// adminTestKey.
+func signAdmin(t *testing.T, path string, form url.Values) url.Values {
+	offset := time.Duration(atomic.AddInt64(&adminTestActions, 1))
+	timestamped := make(url.Values, len(form)+1)
+	for key, values := range form {
+		timestamped[key] = values
+	}
+	timestamped.Set(adminTimestampParam, strconv.FormatInt(
+		time.Now().Add(-offset*time.Second).Unix(), 10,
+	))
+
+	signed, err := SignAdminAction(adminTestKey, path, timestamped)
+	require.Nil(t, err)
+
+	return signed
+}
+
+// newGuardedAdminTestServer serves the admin API for the given drivers like
+// newAdminTestServer, guarding mutations by auth.
+func newGuardedAdminTestServer(
+	t *testing.T,
+	auth *operatorAuth,
+	drivers ...Driver,
+) (*httptest.Server, []*Service) {
+
+	registry := &statusRegistry{
+		services: make(map[string]*Service),
+	}
+
+	var services []*Service
+	mux := http.NewServeMux()
+	for _, driver := range drivers {
+		s := &Service{
+			cfg: ServiceConfig{
+				Driver:       driver,
+				PollInterval: time.Minute,
+			},
+			pollInterval: int64(time.Minute),
+			trigger:      make(chan struct{}, 1),
+		}
+		registry.register(s)
+		services = append(services, s)
+	}
+	for path, handler := range adminHandlers(registry, auth) {
+		mux.Handle(path, handler)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, services
+}
+
+// postAdmin posts form to the admin API at path signed by adminTestKey,
+// decoding any settings returned.
+func postAdmin(
+	t *testing.T,
+	server *httptest.Server,
+	path string,
+	form url.Values,
+) (int, RuntimeSettings) {
+
+	return postAdminRaw(t, server, path, signAdmin(t, path, form))
+}
+
+// postAdminRaw posts form to the admin API at path as is, decoding any
+// settings returned.
+func postAdminRaw(
+	t *testing.T,
+	server *httptest.Server,
+	path string,
+	form url.Values,
+) (int, RuntimeSettings) {
+
+	resp, err := http.Post(
+		server.URL+path, "application/x-www-form-urlencoded",
+		strings.NewReader(form.Encode()),
+	)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	var settings RuntimeSettings
+	if resp.StatusCode == http.StatusOK {
+		require.Nil(t, json.NewDecoder(resp.Body).Decode(&settings))
+	}
+
+	return resp.StatusCode, settings
+}
+
+// TestAdminPauseResume asserts that the admin API pauses and resumes the named
+// service, that a paused service skips its cycles, and that triggered cycles
+// are coalesced.
+func TestAdminPauseResume(t *testing.T) {
+	t.Parallel()
+
+	server, services := newAdminTestServer(
+		t, namedDriver{name: "TestAdminPauseResume"},
+	)
+	s := services[0]
+	form := url.Values{"service": {"TestAdminPauseResume"}}
+
+	code, _ := postAdmin(t, server, adminPausePath, url.Values{
+		"service": {"unknown"},
+	})
+	require.Equal(t, http.StatusNotFound, code)
+
+	code, settings := postAdmin(t, server, adminPausePath, form)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, settings.Paused)
+	require.True(t, s.Paused())
+
+	trace := newCycleTrace()
+	s.runCycle(trace)
+	require.Equal(t, CycleSkipped, trace.Outcome)
+
+	code, _ = postAdmin(t, server, adminCyclePath, form)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = postAdmin(t, server, adminCyclePath, form)
+	require.Equal(t, http.StatusOK, code)
+	require.Len(t, s.trigger, 1)
+
+	code, settings = postAdmin(t, server, adminResumePath, form)
+	require.Equal(t, http.StatusOK, code)
+	require.False(t, settings.Paused)
+	require.False(t, s.Paused())
+}
+
+// TestAdminConfig asserts that the admin API adjusts runtime settings, and that
+// a rejected request leaves every setting unchanged.
+func TestAdminConfig(t *testing.T) {
+	t.Parallel()
+
+	limited := &limitedDriver{
+		namedDriver: namedDriver{name: "TestAdminConfigLimited"},
+		maxTxSize:   1000,
+	}
+	server, services := newAdminTestServer(
+		t, limited, namedDriver{name: "TestAdminConfigNamed"},
+	)
+
+	code, settings := postAdmin(t, server, adminConfigPath, url.Values{
+		"service":       {"TestAdminConfigLimited"},
+		"poll_interval": {"5s"},
+		"max_tx_size":   {"2000"},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "5s", settings.PollInterval)
+	require.Equal(t, uint64(2000), settings.MaxTxSize)
+	require.Equal(t, 5*time.Second, services[0].PollInterval())
+	require.Equal(t, uint64(2000), limited.MaxTxSize())
+
+	code, _ = postAdmin(t, server, adminConfigPath, url.Values{
+		"service":       {"TestAdminConfigLimited"},
+		"poll_interval": {"-1s"},
+	})
+	require.Equal(t, http.StatusBadRequest, code)
+
+	// The max tx size of a driver that does not support adjusting it is
+	// rejected before the poll interval is applied.
+	code, _ = postAdmin(t, server, adminConfigPath, url.Values{
+		"service":       {"TestAdminConfigNamed"},
+		"poll_interval": {"5s"},
+		"max_tx_size":   {"2000"},
+	})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, time.Minute, services[1].PollInterval())
+
+	// A max tx size above the L1 tx pool's limit is rejected before the
+	// poll interval is applied.
+	code, _ = postAdmin(t, server, adminConfigPath, url.Values{
+		"service":       {"TestAdminConfigLimited"},
+		"poll_interval": {"10s"},
+		"max_tx_size": {
+			strconv.FormatUint(MaxL1TxPoolTxSize+1, 10),
+		},
+	})
+	require.Equal(t, http.StatusBadRequest, code)
+	require.Equal(t, 5*time.Second, services[0].PollInterval())
+	require.Equal(t, uint64(2000), limited.MaxTxSize())
+}
+
+// TestAdminSubmissionsRequireStateStore asserts that recorded submissions can
+// only be queried for services with a StateStore.
+func TestAdminSubmissionsRequireStateStore(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newAdminTestServer(
+		t, namedDriver{name: "TestAdminSubmissionsRequireStateStore"},
+	)
+
+	resp, err := http.Get(server.URL + adminSubmissionsPath +
+		"?service=TestAdminSubmissionsRequireStateStore")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + adminSubmissionsPath +
+		"?service=TestAdminSubmissionsRequireStateStore&limit=0")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
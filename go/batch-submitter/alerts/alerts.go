@@ -0,0 +1,219 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// notifyTimeout bounds each notification request.
+const notifyTimeout = 10 * time.Second
+
+// Severity is the urgency of an alert.
+type Severity string
+
+const (
+	// SeverityResolved marks a condition that has cleared.
+	SeverityResolved Severity = "resolved"
+
+	// SeverityWarning marks a condition that requires attention soon.
+	SeverityWarning Severity = "warning"
+
+	// SeverityCritical marks a condition that requires immediate
+	// attention.
+	SeverityCritical Severity = "critical"
+)
+
+// Alert describes a change in a monitored condition of a service.
+type Alert struct {
+	// Key identifies the condition, such that a resolving alert clears
+	// the alerts previously raised with the same key.
+	Key string `json:"key"`
+
+	// Service is the name of the service raising the alert.
+	Service string `json:"service"`
+
+	// Severity is the urgency of the alert.
+	Severity Severity `json:"severity"`
+
+	// Summary is a human readable description of the alert.
+	Summary string `json:"summary"`
+
+	// Details are additional machine readable fields.
+	Details map[string]string `json:"details,omitempty"`
+
+	// Time is the time at which the alert was raised.
+	Time time.Time `json:"time"`
+}
+
+// Notifier delivers alerts to an external system.
+type Notifier interface {
+	// Name identifies the notifier in logs.
+	Name() string
+
+	// Notify delivers alert.
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// postJSON posts body encoded as JSON to url, returning an error if the
+// request fails or is not accepted.
+func postJSON(
+	ctx context.Context,
+	client *http.Client,
+	url string,
+	body interface{},
+) error {
+
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, url, bytes.NewReader(data),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification rejected with status %d",
+			resp.StatusCode)
+	}
+
+	return nil
+}
+
+// WebhookNotifier posts each alert as JSON to a generic webhook.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier initializes a WebhookNotifier posting to url.
+func NewWebhookNotifier(url string) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:    url,
+		client: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// Name identifies the notifier in logs.
+func (n *WebhookNotifier) Name() string {
+	return "webhook"
+}
+
+// Notify posts alert to the webhook.
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	return postJSON(ctx, n.client, n.url, alert)
+}
+
+// SlackNotifier posts each alert as a message to a Slack incoming webhook.
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewSlackNotifier initializes a SlackNotifier posting to the incoming
+// webhook at url.
+func NewSlackNotifier(url string) *SlackNotifier {
+	return &SlackNotifier{
+		url:    url,
+		client: &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// Name identifies the notifier in logs.
+func (n *SlackNotifier) Name() string {
+	return "slack"
+}
+
+// Notify posts alert to the Slack channel of the webhook.
+func (n *SlackNotifier) Notify(ctx context.Context, alert Alert) error {
+	text := fmt.Sprintf("[%s] %s: %s", alert.Severity, alert.Service,
+		alert.Summary)
+
+	return postJSON(ctx, n.client, n.url, map[string]string{
+		"text": text,
+	})
+}
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyNotifier triggers and resolves PagerDuty incidents using the
+// Events API v2. Alerts sharing a key are deduplicated into one incident.
+type PagerDutyNotifier struct {
+	url        string
+	routingKey string
+	client     *http.Client
+}
+
+// NewPagerDutyNotifier initializes a PagerDutyNotifier raising incidents on
+// the service integration identified by routingKey, using the Events API at
+// url, typically DefaultPagerDutyURL.
+func NewPagerDutyNotifier(url, routingKey string) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		url:        url,
+		routingKey: routingKey,
+		client:     &http.Client{Timeout: notifyTimeout},
+	}
+}
+
+// Name identifies the notifier in logs.
+func (n *PagerDutyNotifier) Name() string {
+	return "pagerduty"
+}
+
+// pagerDutyEvent is an Events API v2 event.
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes a triggered PagerDuty event.
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Notify triggers an incident for alert, or resolves the incident of its key
+// if the alert is resolved.
+func (n *PagerDutyNotifier) Notify(ctx context.Context, alert Alert) error {
+	event := pagerDutyEvent{
+		RoutingKey: n.routingKey,
+		DedupKey:   alert.Key,
+	}
+	if alert.Severity == SeverityResolved {
+		event.EventAction = "resolve"
+	} else {
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{
+			Summary:       alert.Service + ": " + alert.Summary,
+			Source:        alert.Service,
+			Severity:      string(alert.Severity),
+			Timestamp:     alert.Time.UTC().Format(time.RFC3339),
+			CustomDetails: alert.Details,
+		}
+	}
+
+	return postJSON(ctx, n.client, n.url, event)
+}
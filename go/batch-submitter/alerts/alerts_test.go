@@ -0,0 +1,106 @@
+package alerts_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a server that forwards each decoded request body to
+// the returned channel, responding with status.
+func newTestServer(
+	t *testing.T,
+	status int,
+) (*httptest.Server, chan map[string]interface{}) {
+
+	bodies := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			var body map[string]interface{}
+			require.Nil(t, json.NewDecoder(req.Body).Decode(&body))
+			bodies <- body
+			w.WriteHeader(status)
+		},
+	))
+	t.Cleanup(server.Close)
+
+	return server, bodies
+}
+
+var testAlert = alerts.Alert{
+	Key:      "sequencer-balance",
+	Service:  "sequencer",
+	Severity: alerts.SeverityCritical,
+	Summary:  "wallet balance is low",
+	Details:  map[string]string{"balance_eth": "0.1"},
+	Time:     time.Unix(1600000000, 0),
+}
+
+// TestWebhookNotifier asserts that alerts are posted as JSON.
+func TestWebhookNotifier(t *testing.T) {
+	t.Parallel()
+
+	server, bodies := newTestServer(t, http.StatusOK)
+	notifier := alerts.NewWebhookNotifier(server.URL)
+	require.Nil(t, notifier.Notify(context.Background(), testAlert))
+
+	body := <-bodies
+	require.Equal(t, "sequencer-balance", body["key"])
+	require.Equal(t, "critical", body["severity"])
+	require.Equal(t, "wallet balance is low", body["summary"])
+}
+
+// TestSlackNotifier asserts that alerts are posted as Slack messages.
+func TestSlackNotifier(t *testing.T) {
+	t.Parallel()
+
+	server, bodies := newTestServer(t, http.StatusOK)
+	notifier := alerts.NewSlackNotifier(server.URL)
+	require.Nil(t, notifier.Notify(context.Background(), testAlert))
+
+	body := <-bodies
+	require.Equal(t, "[critical] sequencer: wallet balance is low",
+		body["text"])
+}
+
+// TestPagerDutyNotifier asserts that alerts trigger incidents, and that
+// resolved alerts resolve the incident of the same key.
+func TestPagerDutyNotifier(t *testing.T) {
+	t.Parallel()
+
+	server, bodies := newTestServer(t, http.StatusAccepted)
+	notifier := alerts.NewPagerDutyNotifier(server.URL, "routing-key")
+	require.Nil(t, notifier.Notify(context.Background(), testAlert))
+
+	body := <-bodies
+	require.Equal(t, "routing-key", body["routing_key"])
+	require.Equal(t, "trigger", body["event_action"])
+	require.Equal(t, "sequencer-balance", body["dedup_key"])
+	payload := body["payload"].(map[string]interface{})
+	require.Equal(t, "critical", payload["severity"])
+	require.Equal(t, "sequencer", payload["source"])
+
+	resolved := testAlert
+	resolved.Severity = alerts.SeverityResolved
+	require.Nil(t, notifier.Notify(context.Background(), resolved))
+
+	body = <-bodies
+	require.Equal(t, "resolve", body["event_action"])
+	require.Equal(t, "sequencer-balance", body["dedup_key"])
+	require.Nil(t, body["payload"])
+}
+
+// TestNotifierRejected asserts that a rejected notification is reported.
+func TestNotifierRejected(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTestServer(t, http.StatusInternalServerError)
+	notifier := alerts.NewWebhookNotifier(server.URL)
+	require.Error(t, notifier.Notify(context.Background(), testAlert))
+}
@@ -0,0 +1,83 @@
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Record is a sealed batch payload as archived, alongside the manifest
+// required to verify and open it.
+type Record struct {
+	Manifest *Manifest     `json:"manifest"`
+	Sealed   hexutil.Bytes `json:"sealed"`
+}
+
+// Archiver seals the payloads of confirmed batches and stores them in a
+// queue.Backend, one record per batch keyed by its start height.
+//
+// NOTE: Archiver is safe for concurrent use if its backend is.
+type Archiver struct {
+	sealer  *Sealer
+	backend queue.Backend
+}
+
+// NewArchiver initializes an Archiver sealing payloads with sealer into
+// backend.
+func NewArchiver(sealer *Sealer, backend queue.Backend) *Archiver {
+	return &Archiver{
+		sealer:  sealer,
+		backend: backend,
+	}
+}
+
+// Archive seals the calldata of batch and stores it, replacing any record of a
+// batch with the same start height. The manifest of the record is returned.
+func (a *Archiver) Archive(batch *queue.Batch) (*Manifest, error) {
+	sealed, manifest, err := a.sealer.Seal(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(&Record{
+		Manifest: manifest,
+		Sealed:   sealed,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := a.backend.Put(recordKey(batch.Start), data); err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+// Get returns the archived record of the batch starting at start, or
+// queue.ErrNotFound if none is stored.
+func (a *Archiver) Get(start uint64) (*Record, error) {
+	data, err := a.backend.Get(recordKey(start))
+	if err != nil {
+		return nil, err
+	}
+
+	var record Record
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// Close releases the backend.
+func (a *Archiver) Close() error {
+	return a.backend.Close()
+}
+
+// recordKey returns the key of the record of the batch starting at start.
+// Heights are zero-padded, such that keys sort by height.
+func recordKey(start uint64) string {
+	return fmt.Sprintf("%020d", start)
+}
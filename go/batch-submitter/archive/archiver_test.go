@@ -0,0 +1,44 @@
+package archive_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// TestArchiverRoundTrip asserts that an archived batch is stored sealed and is
+// opened from its record to the original calldata.
+func TestArchiverRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	backend, err := queue.NewDirBackend(t.TempDir(), time.Minute)
+	require.Nil(t, err)
+
+	sealer := newTestSealer(t, "key-1", 0x01)
+	archiver := archive.NewArchiver(sealer, backend)
+	defer archiver.Close()
+
+	batch := &queue.Batch{
+		Start:    10,
+		End:      20,
+		CallData: []byte("appendSequencerBatch calldata"),
+	}
+	manifest, err := archiver.Archive(batch)
+	require.Nil(t, err)
+
+	record, err := archiver.Get(10)
+	require.Nil(t, err)
+	require.Equal(t, manifest, record.Manifest)
+	require.Nil(t, archive.VerifySealed(record.Sealed, record.Manifest))
+
+	callData, err := sealer.Open(record.Sealed, record.Manifest)
+	require.Nil(t, err)
+	require.Equal(t, []byte(batch.CallData), callData)
+
+	_, err = archiver.Get(20)
+	require.True(t, errors.Is(err, queue.ErrNotFound))
+}
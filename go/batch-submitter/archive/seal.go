@@ -0,0 +1,166 @@
+// Package archive seals batch payloads for archival outside of L1, such that
+// archived data remains confidential at rest while being verifiable against the
+// calldata of the batch tx.
+package archive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// KeySize is the size in bytes of an archive encryption key.
+const KeySize = 32
+
+// ErrInvalidKey signals that an archive encryption key is not KeySize bytes.
+var ErrInvalidKey = fmt.Errorf("archive key must be %d bytes", KeySize)
+
+// ErrKeyMismatch signals an attempt to open a payload sealed under another key.
+var ErrKeyMismatch = errors.New("payload sealed with a different key")
+
+// ErrManifestMismatch signals that a sealed payload does not match the hashes
+// recorded in its manifest.
+var ErrManifestMismatch = errors.New("sealed payload does not match manifest")
+
+// Manifest records how a batch payload was sealed, and the hashes required to
+// verify it once opened.
+type Manifest struct {
+	// Start and End bound the L2 blocks covered by the batch, [Start, End).
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	// KeyID identifies the operator-managed key the payload was sealed with.
+	KeyID string `json:"key_id"`
+
+	// Nonce is the AES-GCM nonce used to seal the payload.
+	Nonce hexutil.Bytes `json:"nonce"`
+
+	// CallDataHash is the keccak256 hash of the batch calldata, which can be
+	// checked against the input of the batch tx on L1.
+	CallDataHash common.Hash `json:"call_data_hash"`
+
+	// SealedHash is the sha256 hash of the sealed payload, allowing
+	// archived objects to be verified without the key.
+	SealedHash common.Hash `json:"sealed_hash"`
+}
+
+// Sealer encrypts batch payloads with AES-256-GCM under an operator-managed
+// key.
+type Sealer struct {
+	keyID string
+	aead  cipher.AEAD
+}
+
+// NewSealer initializes a Sealer with the given key, identified by keyID in
+// the manifests it produces.
+func NewSealer(keyID string, key []byte) (*Sealer, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealer{
+		keyID: keyID,
+		aead:  aead,
+	}, nil
+}
+
+// ParseKey decodes a hex-encoded archive encryption key.
+func ParseKey(keyStr string) ([]byte, error) {
+	key, err := hexutil.Decode(strings.TrimSpace(keyStr))
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	return key, nil
+}
+
+// Seal encrypts the calldata of batch, returning the sealed payload alongside
+// its manifest. The block range is authenticated, such that a payload cannot
+// be passed off as that of another range.
+func (s *Sealer) Seal(batch *queue.Batch) ([]byte, *Manifest, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, nil, err
+	}
+
+	manifest := &Manifest{
+		Start:        batch.Start,
+		End:          batch.End,
+		KeyID:        s.keyID,
+		Nonce:        nonce,
+		CallDataHash: crypto.Keccak256Hash(batch.CallData),
+	}
+
+	sealed := s.aead.Seal(
+		nil, nonce, batch.CallData, manifest.additionalData(),
+	)
+	manifest.SealedHash = sha256.Sum256(sealed)
+
+	return sealed, manifest, nil
+}
+
+// Open verifies sealed against manifest and returns the decrypted calldata.
+func (s *Sealer) Open(sealed []byte, manifest *Manifest) ([]byte, error) {
+	if manifest.KeyID != s.keyID {
+		return nil, ErrKeyMismatch
+	}
+	if err := VerifySealed(sealed, manifest); err != nil {
+		return nil, err
+	}
+
+	callData, err := s.aead.Open(
+		nil, manifest.Nonce, sealed, manifest.additionalData(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrManifestMismatch, err)
+	}
+	if crypto.Keccak256Hash(callData) != manifest.CallDataHash {
+		return nil, ErrManifestMismatch
+	}
+
+	return callData, nil
+}
+
+// VerifySealed checks the integrity of a sealed payload against its manifest
+// without decrypting it.
+func VerifySealed(sealed []byte, manifest *Manifest) error {
+	if sha256.Sum256(sealed) != manifest.SealedHash {
+		return ErrManifestMismatch
+	}
+
+	return nil
+}
+
+// additionalData returns the data authenticated alongside the sealed payload,
+// binding it to the manifest's block range and calldata hash.
+func (m *Manifest) additionalData() []byte {
+	data := make([]byte, 16, 16+common.HashLength)
+	binary.BigEndian.PutUint64(data[:8], m.Start)
+	binary.BigEndian.PutUint64(data[8:], m.End)
+
+	return append(data, m.CallDataHash.Bytes()...)
+}
@@ -0,0 +1,94 @@
+package archive_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSealer initializes a Sealer whose key consists of the fill byte.
+func newTestSealer(t *testing.T, keyID string, fill byte) *archive.Sealer {
+	sealer, err := archive.NewSealer(
+		keyID, bytes.Repeat([]byte{fill}, archive.KeySize),
+	)
+	require.Nil(t, err)
+
+	return sealer
+}
+
+// TestSealOpen asserts that a sealed batch payload is opened to its original
+// calldata, and that the payload is not stored in the clear.
+func TestSealOpen(t *testing.T) {
+	t.Parallel()
+
+	sealer := newTestSealer(t, "key-1", 0x01)
+	batch := &queue.Batch{
+		Start:     10,
+		End:       20,
+		CallData:  []byte("appendSequencerBatch calldata"),
+		CreatedAt: time.Now(),
+	}
+
+	sealed, manifest, err := sealer.Seal(batch)
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), manifest.Start)
+	require.Equal(t, uint64(20), manifest.End)
+	require.Equal(t, "key-1", manifest.KeyID)
+	require.False(t, bytes.Contains(sealed, batch.CallData))
+	require.Nil(t, archive.VerifySealed(sealed, manifest))
+
+	callData, err := sealer.Open(sealed, manifest)
+	require.Nil(t, err)
+	require.Equal(t, []byte(batch.CallData), callData)
+}
+
+// TestOpenRejectsTampering asserts that a sealed payload is rejected if it, or
+// the range recorded in its manifest, was altered, or if the wrong key is used.
+func TestOpenRejectsTampering(t *testing.T) {
+	t.Parallel()
+
+	sealer := newTestSealer(t, "key-1", 0x01)
+	sealed, manifest, err := sealer.Seal(&queue.Batch{
+		Start:    10,
+		End:      20,
+		CallData: []byte("calldata"),
+	})
+	require.Nil(t, err)
+
+	tampered := append([]byte{}, sealed...)
+	tampered[0] ^= 0xff
+	require.Equal(t, archive.ErrManifestMismatch,
+		archive.VerifySealed(tampered, manifest))
+	_, err = sealer.Open(tampered, manifest)
+	require.Equal(t, archive.ErrManifestMismatch, err)
+
+	shifted := *manifest
+	shifted.Start = 11
+	_, err = sealer.Open(sealed, &shifted)
+	require.ErrorIs(t, err, archive.ErrManifestMismatch)
+
+	_, err = newTestSealer(t, "key-2", 0x02).Open(sealed, manifest)
+	require.Equal(t, archive.ErrKeyMismatch, err)
+}
+
+// TestParseKey asserts that only hex-encoded keys of KeySize bytes are
+// accepted.
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := archive.ParseKey(
+		"0x" + string(bytes.Repeat([]byte("ab"), archive.KeySize)),
+	)
+	require.Nil(t, err)
+	require.Len(t, key, archive.KeySize)
+
+	_, err = archive.ParseKey("0xabcd")
+	require.Equal(t, archive.ErrInvalidKey, err)
+
+	_, err = archive.ParseKey("not hex")
+	require.Error(t, err)
+}
@@ -0,0 +1,147 @@
+package batchsubmitter
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// balanceNotifyTimeout bounds the delivery of each balance alert.
+const balanceNotifyTimeout = 30 * time.Second
+
+// balanceLevel classifies a wallet balance against the configured
+// thresholds. Levels are ordered by severity.
+type balanceLevel int
+
+const (
+	// balanceOK is a balance at or above every threshold.
+	balanceOK balanceLevel = iota
+
+	// balanceWarning is a balance below MinBalance.
+	balanceWarning
+
+	// balanceCritical is a balance below CriticalBalance, at which
+	// submission halts.
+	balanceCritical
+)
+
+// String returns the name of the level.
+func (l balanceLevel) String() string {
+	switch l {
+	case balanceWarning:
+		return "warning"
+	case balanceCritical:
+		return "critical"
+	default:
+		return "ok"
+	}
+}
+
+// belowThreshold returns true if threshold is positive and balance is below
+// it.
+func belowThreshold(balance, threshold *big.Int) bool {
+	return threshold != nil && threshold.Sign() > 0 &&
+		balance.Cmp(threshold) < 0
+}
+
+// checkBalance classifies balance against the warning and critical
+// thresholds, logging while it is below either. The configured notifiers are
+// alerted whenever the level changes, rather than on every cycle, and once
+// more when the balance recovers.
+//
+// NOTE: This method MUST only be called from the event loop.
+func (s *Service) checkBalance(balance *big.Int) balanceLevel {
+	name := s.cfg.Driver.Name()
+	wallet := s.cfg.AddressBook.Format(s.cfg.Driver.WalletAddr())
+
+	level := balanceOK
+	threshold := s.cfg.MinBalance
+	switch {
+	case belowThreshold(balance, s.cfg.CriticalBalance):
+		level = balanceCritical
+		threshold = s.cfg.CriticalBalance
+		log.Error(name+" balance below critical minimum, halting "+
+			"submission", "wallet", wallet, "balance", balance,
+			"critical_balance", threshold)
+
+	case belowThreshold(balance, s.cfg.MinBalance):
+		level = balanceWarning
+		log.Error(name+" balance below safe minimum", "wallet",
+			wallet, "balance", balance, "min_balance", threshold)
+	}
+	s.metrics.BalanceAlertLevel.Set(float64(level))
+
+	if level != s.balanceLevel {
+		s.notifyBalance(level, balance, threshold)
+		s.balanceLevel = level
+	}
+
+	return level
+}
+
+// notifyBalance alerts the configured notifiers that the balance has reached
+// level, in the background.
+func (s *Service) notifyBalance(
+	level balanceLevel,
+	balance, threshold *big.Int,
+) {
+
+	name := s.cfg.Driver.Name()
+	alert := alerts.Alert{
+		Key:     name + "-balance",
+		Service: name,
+		Details: map[string]string{
+			"wallet":      s.cfg.Driver.WalletAddr().Hex(),
+			"balance_eth": fmt.Sprintf("%f", weiToEth64(balance)),
+		},
+		Time: time.Now(),
+	}
+	switch level {
+	case balanceCritical:
+		alert.Severity = alerts.SeverityCritical
+		alert.Summary = fmt.Sprintf("wallet balance %f ETH is below "+
+			"the critical minimum of %f ETH, submission halted",
+			weiToEth64(balance), weiToEth64(threshold))
+	case balanceWarning:
+		alert.Severity = alerts.SeverityWarning
+		alert.Summary = fmt.Sprintf("wallet balance %f ETH is below "+
+			"the safe minimum of %f ETH", weiToEth64(balance),
+			weiToEth64(threshold))
+	default:
+		alert.Severity = alerts.SeverityResolved
+		alert.Summary = fmt.Sprintf("wallet balance %f ETH recovered",
+			weiToEth64(balance))
+	}
+
+	s.notify(alert)
+}
+
+// notify delivers alert to each of the configured notifiers in the
+// background.
+func (s *Service) notify(alert alerts.Alert) {
+	name := s.cfg.Driver.Name()
+	for _, notifier := range s.cfg.BalanceNotifiers {
+		notifier := notifier
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+
+			ctx, cancel := context.WithTimeout(
+				context.Background(), balanceNotifyTimeout,
+			)
+			defer cancel()
+
+			if err := notifier.Notify(ctx, alert); err != nil {
+				log.Error(name+" unable to deliver alert",
+					"key", alert.Key,
+					"notifier", notifier.Name(),
+					"severity", alert.Severity, "err", err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,89 @@
+package batchsubmitter
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// walletDriver is a namedDriver with a zero wallet address.
+type walletDriver struct {
+	namedDriver
+}
+
+func (d walletDriver) WalletAddr() common.Address {
+	return common.Address{}
+}
+
+// recordingNotifier records the severity of each alert it is notified of.
+type recordingNotifier struct {
+	mu         sync.Mutex
+	severities []alerts.Severity
+	details    []map[string]string
+}
+
+func (n *recordingNotifier) Name() string {
+	return "recording"
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, alert alerts.Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.severities = append(n.severities, alert.Severity)
+	n.details = append(n.details, alert.Details)
+	return nil
+}
+
+// TestServiceCheckBalance asserts that balances are classified against both
+// thresholds, and that notifiers are only alerted when the level changes.
+func TestServiceCheckBalance(t *testing.T) {
+	t.Parallel()
+
+	notifier := &recordingNotifier{}
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver: walletDriver{
+				namedDriver{name: "TestServiceCheckBalance"},
+			},
+			AddressBook:      NewAddressBook(nil),
+			MinBalance:       big.NewInt(100),
+			CriticalBalance:  big.NewInt(10),
+			BalanceNotifiers: []alerts.Notifier{notifier},
+		},
+		metrics: metrics.NewMetrics("TestServiceCheckBalance"),
+	}
+
+	for _, test := range []struct {
+		balance  int64
+		expLevel balanceLevel
+	}{
+		{100, balanceOK},
+		{99, balanceWarning},
+		{50, balanceWarning},
+		{9, balanceCritical},
+		{5, balanceCritical},
+		{10, balanceWarning},
+		{1000, balanceOK},
+	} {
+		level := s.checkBalance(big.NewInt(test.balance))
+		require.Equal(t, test.expLevel, level, "balance %d",
+			test.balance)
+	}
+	s.wg.Wait()
+
+	// Notifications are delivered concurrently, and may therefore arrive
+	// in any order.
+	require.ElementsMatch(t, []alerts.Severity{
+		alerts.SeverityWarning,
+		alerts.SeverityCritical,
+		alerts.SeverityWarning,
+		alerts.SeverityResolved,
+	}, notifier.severities)
+}
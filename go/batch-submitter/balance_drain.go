@@ -0,0 +1,174 @@
+package batchsubmitter
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// drainBaselineWeight is the weight given to each completed window
+	// when folding its drain rate into the baseline, such that the
+	// baseline reflects roughly the last ten windows.
+	drainBaselineWeight = 0.1
+
+	// drainMinBaselineWindows is the number of windows that must complete
+	// before the baseline is trusted, and drain alerts may be raised.
+	drainMinBaselineWindows = 3
+)
+
+// drainSample is the amount drained from a wallet between two consecutive
+// balance observations.
+type drainSample struct {
+	at      time.Time
+	drained *big.Int
+}
+
+// drainTracker measures the rate at which a wallet's balance drains over a
+// trailing window, along with a baseline rate averaged over past windows.
+// Only decreases are counted, such that refunding the wallet does not mask a
+// drain.
+type drainTracker struct {
+	window time.Duration
+
+	lastBalance *big.Int
+	samples     []drainSample
+
+	// windowStart and windowDrained accumulate the drain of the current
+	// window, which is folded into the baseline once it completes.
+	windowStart   time.Time
+	windowDrained *big.Int
+
+	baseline   float64
+	numWindows int
+}
+
+// newDrainTracker initializes a drainTracker measuring the drain rate over
+// window.
+func newDrainTracker(window time.Duration) *drainTracker {
+	return &drainTracker{
+		window:        window,
+		windowDrained: new(big.Int),
+	}
+}
+
+// Observe records balance as observed at now, returning the drain rate over
+// the trailing window and the baseline rate, both in ETH per hour. The
+// baseline is not updated by windows for which abnormal returns true, such
+// that a sustained drain keeps alerting rather than becoming the norm. The
+// final return value is false until enough windows have completed for the
+// baseline to be trusted.
+func (t *drainTracker) Observe(
+	now time.Time,
+	balance *big.Int,
+	abnormal func(rate, baseline float64) bool,
+) (float64, float64, bool) {
+
+	if t.lastBalance == nil {
+		t.lastBalance = new(big.Int).Set(balance)
+		t.windowStart = now
+		return 0, 0, false
+	}
+
+	drained := new(big.Int).Sub(t.lastBalance, balance)
+	t.lastBalance.Set(balance)
+	if drained.Sign() > 0 {
+		t.samples = append(t.samples, drainSample{now, drained})
+		t.windowDrained.Add(t.windowDrained, drained)
+	}
+
+	// Drop the samples that have left the trailing window.
+	cutoff := now.Add(-t.window)
+	i := 0
+	for i < len(t.samples) && !t.samples[i].at.After(cutoff) {
+		i++
+	}
+	t.samples = t.samples[i:]
+
+	total := new(big.Int)
+	for _, sample := range t.samples {
+		total.Add(total, sample.drained)
+	}
+	rate := weiToEth64(total) / t.window.Hours()
+
+	if elapsed := now.Sub(t.windowStart); elapsed >= t.window {
+		windowRate := weiToEth64(t.windowDrained) / elapsed.Hours()
+		switch {
+		case t.numWindows == 0:
+			t.baseline = windowRate
+		case !abnormal(windowRate, t.baseline):
+			t.baseline = (1-drainBaselineWeight)*t.baseline +
+				drainBaselineWeight*windowRate
+		}
+		t.numWindows++
+		t.windowStart = now
+		t.windowDrained.SetUint64(0)
+	}
+
+	return rate, t.baseline, t.numWindows >= drainMinBaselineWindows
+}
+
+// drainAbnormal returns true if rate exceeds both the configured minimum drain
+// rate and BalanceDrainFactor times baseline.
+func (s *Service) drainAbnormal(rate, baseline float64) bool {
+	return rate > s.cfg.MinBalanceDrainRate &&
+		rate > s.cfg.BalanceDrainFactor*baseline
+}
+
+// checkBalanceDrain records balance as observed at now with the service's
+// drain tracker, alerting the configured notifiers when the drain rate becomes
+// abnormal compared to its baseline, and again once it returns to normal. This
+// catches fee strategy misconfigurations or gas market anomalies well before
+// the balance crosses an absolute threshold.
+//
+// NOTE: This method MUST only be called from the event loop.
+func (s *Service) checkBalanceDrain(now time.Time, balance *big.Int) {
+	if s.drain == nil {
+		return
+	}
+	name := s.cfg.Driver.Name()
+
+	rate, baseline, ready := s.drain.Observe(now, balance, s.drainAbnormal)
+	s.metrics.BalanceDrainRate.Set(rate)
+	s.metrics.BalanceDrainBaseline.Set(baseline)
+	if !ready {
+		return
+	}
+
+	abnormal := s.drainAbnormal(rate, baseline)
+	if abnormal {
+		log.Warn(name+" abnormal balance drain rate", "wallet",
+			s.cfg.AddressBook.Format(s.cfg.Driver.WalletAddr()),
+			"eth_per_hour", rate, "baseline_eth_per_hour", baseline)
+	}
+	if abnormal == s.drainAlerting {
+		return
+	}
+	s.drainAlerting = abnormal
+
+	alert := alerts.Alert{
+		Key:     name + "-balance-drain",
+		Service: name,
+		Details: map[string]string{
+			"wallet":                s.cfg.Driver.WalletAddr().Hex(),
+			"balance_eth":           fmt.Sprintf("%f", weiToEth64(balance)),
+			"eth_per_hour":          fmt.Sprintf("%f", rate),
+			"baseline_eth_per_hour": fmt.Sprintf("%f", baseline),
+		},
+		Time: now,
+	}
+	if abnormal {
+		alert.Severity = alerts.SeverityWarning
+		alert.Summary = fmt.Sprintf("wallet draining at %f ETH/hour, "+
+			"above %.1fx the baseline of %f ETH/hour", rate,
+			s.cfg.BalanceDrainFactor, baseline)
+	} else {
+		alert.Severity = alerts.SeverityResolved
+		alert.Summary = fmt.Sprintf("wallet drain rate %f ETH/hour "+
+			"returned to normal", rate)
+	}
+	s.notify(alert)
+}
@@ -0,0 +1,93 @@
+package batchsubmitter
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDrainTrackerIgnoresRefunds asserts that only decreases of the balance
+// are counted towards the drain rate.
+func TestDrainTrackerIgnoresRefunds(t *testing.T) {
+	t.Parallel()
+
+	tracker := newDrainTracker(time.Hour)
+	never := func(float64, float64) bool { return false }
+
+	now := time.Unix(0, 0)
+	tracker.Observe(now, etherToWei(10), never)
+	tracker.Observe(now.Add(10*time.Minute), etherToWei(9), never)
+	tracker.Observe(now.Add(20*time.Minute), etherToWei(20), never)
+	rate, _, ready := tracker.Observe(
+		now.Add(30*time.Minute), etherToWei(19), never,
+	)
+	require.Equal(t, 2.0, rate)
+	require.False(t, ready)
+
+	// The first drain leaves the trailing window.
+	rate, _, _ = tracker.Observe(
+		now.Add(70*time.Minute), etherToWei(19), never,
+	)
+	require.Equal(t, 1.0, rate)
+}
+
+// TestServiceCheckBalanceDrain asserts that an alert is raised once the drain
+// rate exceeds the baseline by BalanceDrainFactor, and resolved once it
+// returns to normal.
+func TestServiceCheckBalanceDrain(t *testing.T) {
+	t.Parallel()
+
+	notifier := &recordingNotifier{}
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver: walletDriver{
+				namedDriver{name: "TestServiceCheckBalanceDrain"},
+			},
+			AddressBook:         NewAddressBook(nil),
+			BalanceNotifiers:    []alerts.Notifier{notifier},
+			BalanceDrainFactor:  3,
+			MinBalanceDrainRate: 0.05,
+		},
+		metrics: metrics.NewMetrics("TestServiceCheckBalanceDrain"),
+		drain:   newDrainTracker(time.Hour),
+	}
+
+	now := time.Unix(0, 0)
+	balance := etherToWei(100)
+	observe := func(minutes int, drainPerStep *big.Int) {
+		for i := 0; i < minutes/10; i++ {
+			now = now.Add(10 * time.Minute)
+			balance = new(big.Int).Sub(balance, drainPerStep)
+			s.checkBalanceDrain(now, balance)
+		}
+	}
+
+	// Establish a baseline of 0.06 ETH per hour.
+	normal := floatEtherToWei(0.01)
+	s.checkBalanceDrain(now, balance)
+	observe(240, normal)
+	require.False(t, s.drainAlerting)
+
+	// Drain ten times faster than the baseline.
+	observe(60, floatEtherToWei(0.1))
+	require.True(t, s.drainAlerting)
+
+	// The sustained drain is not folded into the baseline.
+	observe(120, floatEtherToWei(0.1))
+	require.True(t, s.drainAlerting)
+
+	observe(60, normal)
+	require.False(t, s.drainAlerting)
+	s.wg.Wait()
+
+	// Notifications are delivered concurrently, and may therefore arrive
+	// in any order.
+	require.ElementsMatch(t, []alerts.Severity{
+		alerts.SeverityWarning,
+		alerts.SeverityResolved,
+	}, notifier.severities)
+}
@@ -3,26 +3,67 @@ package batchsubmitter
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"math/big"
 	"net/http"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/proposer"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/failover"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/leader"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/quota"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/rpcmetrics"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
 	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
+	l2rpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/getsentry/sentry-go"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/urfave/cli"
 )
 
+var (
+	// ErrNoTenantsStarted signals that none of the configured tenants could
+	// be started.
+	ErrNoTenantsStarted = errors.New("unable to start any tenants")
+
+	// metricsServerOnce ensures that the metrics server is only started
+	// once, even when running multiple tenants within the same process.
+	metricsServerOnce sync.Once
+
+	// debugServerOnce ensures that the debug server is only started once,
+	// even when running multiple tenants within the same process.
+	debugServerOnce sync.Once
+
+	// adminServerOnce ensures that the admin server is only started once,
+	// even when running multiple tenants within the same process.
+	adminServerOnce sync.Once
+
+	// adminAuth guards the admin server shared by all tenants, once
+	// started.
+	adminAuth *operatorAuth
+)
+
 const (
 	// defaultDialTimeout is default duration the service will wait on
 	// startup to make a connection to either the L1 or L2 backends.
@@ -46,29 +87,123 @@ func Main(gitVersion string) func(ctx *cli.Context) error {
 			defer sentry.Flush(2 * time.Second)
 		}
 
-		log.Info("Initializing batch submitter")
+		// Metrics are shared by all tenants, and must be identified
+		// consistently before any service registers them.
+		metrics.UseServiceLabel(cfg.MetricsServiceLabel)
 
-		batchSubmitter, err := NewBatchSubmitter(cfg, gitVersion)
-		if err != nil {
-			log.Error("Unable to create batch submitter", "error", err)
-			return err
+		tenantCfgs := []Config{cfg}
+		if cfg.TenantsFile != "" {
+			tenantCfgs, err = LoadTenantConfigs(cfg, cfg.TenantsFile)
+			if err != nil {
+				log.Error("Unable to load tenants file", "error", err)
+				return err
+			}
+		}
+
+		var batchSubmitters []*BatchSubmitter
+		for _, tenantCfg := range tenantCfgs {
+			batchSubmitter, err := startBatchSubmitter(tenantCfg, gitVersion)
+			if err != nil {
+				// In single-tenant mode the error is fatal.
+				// Otherwise, a misconfigured tenant should
+				// not prevent the others from running.
+				if len(tenantCfgs) == 1 {
+					return err
+				}
+				log.Error("Unable to start tenant", "tenant",
+					tenantCfg.TenantName, "error", err)
+				continue
+			}
+			defer batchSubmitter.Stop()
+			batchSubmitters = append(batchSubmitters, batchSubmitter)
 		}
 
-		log.Info("Starting batch submitter")
+		if len(batchSubmitters) == 0 {
+			return ErrNoTenantsStarted
+		}
 
-		if err := batchSubmitter.Start(); err != nil {
+		// Metrics are shared by all tenants within the process, and are
+		// therefore pushed by a single set of exporters.
+		pusher, err := startMetricsExporters(cfg)
+		if err != nil {
+			log.Error("Unable to start metrics exporters", "error", err)
 			return err
 		}
-		defer batchSubmitter.Stop()
+		if pusher != nil {
+			defer pusher.Stop()
+		}
+
+		log.Info("Batch submitter started", "num_tenants",
+			len(batchSubmitters))
 
-		log.Info("Batch submitter started")
+		sigs := make(chan os.Signal, 1)
+		signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+		sig := <-sigs
+		log.Info("Batch submitter shutting down", "signal", sig)
 
-		<-(chan struct{})(nil)
+		drainBatchSubmitters(batchSubmitters, cfg.ShutdownGracePeriod, sigs)
 
 		return nil
 	}
 }
 
+// drainBatchSubmitters drains every batch submitter for up to gracePeriod, or
+// until a further signal is received on sigs, forcing shutdown.
+func drainBatchSubmitters(
+	batchSubmitters []*BatchSubmitter,
+	gracePeriod time.Duration,
+	sigs <-chan os.Signal,
+) {
+
+	if gracePeriod == 0 {
+		return
+	}
+
+	log.Info("Draining batch txs in flight, signal again to force "+
+		"shutdown", "grace_period", gracePeriod)
+
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-sigs:
+			log.Warn("Forcing shutdown", "signal", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for _, batchSubmitter := range batchSubmitters {
+		wg.Add(1)
+		go func(batchSubmitter *BatchSubmitter) {
+			defer wg.Done()
+			batchSubmitter.Drain(ctx)
+		}(batchSubmitter)
+	}
+	wg.Wait()
+}
+
+// startBatchSubmitter initializes and starts a BatchSubmitter for the given
+// configuration.
+func startBatchSubmitter(cfg Config, gitVersion string) (*BatchSubmitter, error) {
+	log.Info("Initializing batch submitter", "tenant", cfg.TenantName)
+
+	batchSubmitter, err := NewBatchSubmitter(cfg, gitVersion)
+	if err != nil {
+		log.Error("Unable to create batch submitter", "error", err)
+		return nil, err
+	}
+
+	log.Info("Starting batch submitter", "tenant", cfg.TenantName)
+
+	if err := batchSubmitter.Start(); err != nil {
+		return nil, err
+	}
+
+	return batchSubmitter, nil
+}
+
 // BatchSubmitter is a service that configures the necessary resources for
 // running the TxBatchSubmitter and StateBatchSubmitter sub-services.
 type BatchSubmitter struct {
@@ -83,18 +218,42 @@ type BatchSubmitter struct {
 
 	batchTxService    *Service
 	batchStateService *Service
+
+	// submissionQueue is closed once the services have stopped, releasing
+	// its lock for the next instance.
+	submissionQueue *queue.SubmissionQueue
+
+	// stateStores are closed once the services have stopped, releasing
+	// their locks for the next instance.
+	stateStores []*queue.StateStore
+
+	// archiver is closed once the services have stopped, releasing its
+	// backend.
+	archiver *archive.Archiver
+
+	// rpcTransports fail over between the configured providers, and stop
+	// health checking them once the services have stopped.
+	rpcTransports []*failover.Transport
+
+	// elector campaigns for leadership in high availability mode, and
+	// releases the leaderLock once the services have stopped.
+	elector    *leader.Elector
+	leaderLock *leader.RedisLock
 }
 
 // NewBatchSubmitter initializes the BatchSubmitter, gathering any resources
 // that will be needed by the TxBatchSubmitter and StateBatchSubmitter
 // sub-services.
-func NewBatchSubmitter(cfg Config, gitVersion string) (*BatchSubmitter, error) {
+func NewBatchSubmitter(
+	cfg Config,
+	gitVersion string,
+) (_ *BatchSubmitter, err error) {
+
 	ctx := context.Background()
 
 	// Set up our logging. If Sentry is enabled, we will use our custom
 	// log handler that logs to stdout and forwards any error messages to
 	// Sentry for collection. Otherwise, logs will only be posted to stdout.
-	var logHandler log.Handler
 	if cfg.SentryEnable {
 		err := sentry.Init(sentry.ClientOptions{
 			Dsn:              cfg.SentryDsn,
@@ -106,23 +265,39 @@ func NewBatchSubmitter(cfg Config, gitVersion string) (*BatchSubmitter, error) {
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		logHandler = SentryStreamHandler(os.Stdout, log.TerminalFormat(true))
-	} else {
-		logHandler = log.StreamHandler(os.Stdout, log.TerminalFormat(true))
+	logHandler, err := newLogHandler(
+		os.Stdout, cfg.LogFormat, cfg.SentryEnable,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	logLevel, err := log.LvlFromString(cfg.LogLevel)
+	err = defaultLogFilter.SetLevels(LogLevels{
+		Level:   cfg.LogLevel,
+		Modules: cfg.LogModules,
+	})
 	if err != nil {
 		return nil, err
 	}
+	defaultLogFilter.SetHandler(logHandler)
 
-	log.Root().SetHandler(log.LvlFilterHandler(logLevel, logHandler))
+	log.Root().SetHandler(defaultLogFilter)
+
+	// Label the contracts and wallets in logs and the status API. Any
+	// configured labels take precedence over the built-in ones.
+	addressLabels, err := ParseAddressLabels(cfg.AddressLabels)
+	if err != nil {
+		return nil, err
+	}
+	addressBook := NewAddressBook(addressLabels)
 
 	// Parse sequencer private key and CTC contract address.
 	sequencerPrivKey, ctcAddress, err := parseWalletPrivKeyAndContractAddr(
-		"Sequencer", cfg.Mnemonic, cfg.SequencerHDPath,
-		cfg.SequencerPrivateKey, cfg.CTCAddress,
+		tenantPrefix(cfg)+"Sequencer", tenantPrefix(cfg)+"CTC",
+		cfg.Mnemonic, cfg.SequencerHDPath, cfg.SequencerPrivateKey,
+		cfg.CTCAddress, addressBook,
 	)
 	if err != nil {
 		return nil, err
@@ -130,90 +305,523 @@ func NewBatchSubmitter(cfg Config, gitVersion string) (*BatchSubmitter, error) {
 
 	// Parse proposer private key and SCC contract address.
 	proposerPrivKey, sccAddress, err := parseWalletPrivKeyAndContractAddr(
-		"Proposer", cfg.Mnemonic, cfg.ProposerHDPath,
-		cfg.ProposerPrivateKey, cfg.SCCAddress,
+		tenantPrefix(cfg)+"Proposer", tenantPrefix(cfg)+"SCC",
+		cfg.Mnemonic, cfg.ProposerHDPath, cfg.ProposerPrivateKey,
+		cfg.SCCAddress, addressBook,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse the private keys of the wallets each sub-service may rotate
+	// submission to.
+	sequencerRotationKeys, err := parseRotationPrivKeys(
+		tenantPrefix(cfg)+"Sequencer",
+		cfg.SequencerRotationPrivateKeys, addressBook,
+	)
+	if err != nil {
+		return nil, err
+	}
+	proposerRotationKeys, err := parseRotationPrivKeys(
+		tenantPrefix(cfg)+"Proposer",
+		cfg.ProposerRotationPrivateKeys, addressBook,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// The stores, locks and transports opened below are released if the
+	// submitter fails to initialize, as the caller never stops it.
+	var (
+		rpcTransports   []*failover.Transport
+		elector         *leader.Elector
+		leaderLock      *leader.RedisLock
+		submissionQueue *queue.SubmissionQueue
+		stateStores     []*queue.StateStore
+		archiver        *archive.Archiver
+	)
+	defer func() {
+		if err == nil {
+			return
+		}
+		(&BatchSubmitter{
+			submissionQueue: submissionQueue,
+			stateStores:     stateStores,
+			archiver:        archiver,
+			rpcTransports:   rpcTransports,
+			leaderLock:      leaderLock,
+		}).release()
+	}()
+
 	// Connect to L1 and L2 providers. Perform these last since they are the
 	// most expensive.
-	l1Client, err := dialL1EthClientWithTimeout(ctx, cfg.L1EthRpc)
+	l1RPCClient, l1Transport, err := dialL1RPCClientWithTimeout(
+		ctx, cfg, "l1", cfg.L1EthRpc,
+	)
 	if err != nil {
 		return nil, err
 	}
+	if l1Transport != nil {
+		rpcTransports = append(rpcTransports, l1Transport)
+	}
+	l1Client := ethclient.NewClient(l1RPCClient)
 
-	l2Client, err := dialL2EthClientWithTimeout(ctx, cfg.L2EthRpc)
+	l2RPCClient, l2Transport, err := dialL2RPCClientWithTimeout(
+		ctx, cfg, "l2", cfg.L2EthRpc,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if l2Transport != nil {
+		rpcTransports = append(rpcTransports, l2Transport)
+	}
+	l2Client := l2ethclient.NewClient(l2RPCClient)
+	l2BlockClient, err := newL2BlockClient(ctx, cfg, "l2", l2RPCClient)
 	if err != nil {
 		return nil, err
 	}
 
+	var secondaryL2Client l2client.Client
+	if cfg.SecondaryL2EthRpc != "" {
+		rpcClient, transport, err := dialL2RPCClientWithTimeout(
+			ctx, cfg, "secondary_l2", cfg.SecondaryL2EthRpc,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if transport != nil {
+			rpcTransports = append(rpcTransports, transport)
+		}
+		secondaryL2Client, err = newL2BlockClient(
+			ctx, cfg, "secondary_l2", rpcClient,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	if cfg.MetricsServerEnable {
-		go runMetricsServer(cfg.MetricsHostname, cfg.MetricsPort)
+		metricsServerOnce.Do(func() {
+			var gatherer prometheus.Gatherer
+			if hasMetricsExporter(cfg, MetricsExporterPrometheus) {
+				gatherer = metricsGatherer(cfg)
+			}
+			go runMetricsServer(
+				cfg.MetricsHostname, cfg.MetricsPort, gatherer,
+			)
+		})
+	}
+
+	if cfg.AdminServerEnable {
+		var adminAuthErr error
+		adminServerOnce.Do(func() {
+			adminAuth, adminAuthErr = newAdminAuth(cfg)
+			if adminAuthErr != nil {
+				return
+			}
+			go runAdminServer(cfg.AdminHostname, cfg.AdminPort, adminAuth)
+		})
+		if adminAuthErr != nil {
+			return nil, adminAuthErr
+		}
+
+		// The admin server is shared by all tenants, each of which
+		// may add operators scoped to its own services.
+		tenantOperators, err := ParseAdminOperators(
+			cfg.TenantAdminOperators,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if adminAuth != nil && len(tenantOperators) > 0 {
+			adminAuth.scopeTenant(cfg.TenantName, tenantOperators)
+		}
+	}
+
+	if cfg.DebugServerEnable {
+		debugServerOnce.Do(func() {
+			go runDebugServer(cfg.DebugHostname, cfg.DebugPort)
+		})
 	}
 
 	chainID, err := l1Client.ChainID(ctx)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.L1ChainID != 0 && chainID.Uint64() != cfg.L1ChainID {
+		return nil, fmt.Errorf("%w: want=%d got=%v",
+			ErrL1ChainIDMismatch, cfg.L1ChainID, chainID)
+	}
 
-	txManagerConfig := txmgr.Config{
-		MinGasPrice:          gasPriceFromGwei(1),
-		MaxGasPrice:          gasPriceFromGwei(cfg.MaxGasPriceInGwei),
-		GasRetryIncrement:    gasPriceFromGwei(cfg.GasRetryIncrement),
-		ResubmissionTimeout:  cfg.ResubmissionTimeout,
-		ReceiptQueryInterval: time.Second,
+	// Sign a self-check tx with each wallet to ensure that batch txs will
+	// be accepted by the settlement chain.
+	if err := VerifySigner(sequencerPrivKey, chainID); err != nil {
+		return nil, fmt.Errorf("sequencer signer self-check failed: %w",
+			err)
+	}
+	if err := VerifySigner(proposerPrivKey, chainID); err != nil {
+		return nil, fmt.Errorf("proposer signer self-check failed: %w",
+			err)
+	}
+	log.Info("Signer self-check passed", "tenant", cfg.TenantName,
+		"chain_id", chainID)
+
+	txManagerConfig := newTxManagerConfig(cfg)
+
+	txPublisher, err := newTxPublisher(cfg, l1Client)
+	if err != nil {
+		return nil, err
+	}
+	if rebroadcaster, ok := txPublisher.(txmgr.Rebroadcaster); ok {
+		txManagerConfig.Rebroadcaster = rebroadcaster
+	}
+
+	minBalance := etherToWei(cfg.SafeMinimumEtherBalance)
+	criticalBalance := floatEtherToWei(cfg.CriticalEtherBalance)
+	catchUpBudget := CatchUpBudget{
+		MaxSpend:          floatEtherToWei(cfg.CatchUpMaxEther),
+		MaxDuration:       cfg.CatchUpMaxDuration,
+		ApprovalThreshold: floatEtherToWei(cfg.CatchUpApprovalEther),
+	}
+	walletRotationBalance := floatEtherToWei(cfg.WalletRotationEtherBalance)
+	balanceNotifiers := newBalanceNotifiers(cfg)
+	rpcRetryPolicy := RetryPolicy{
+		MaxRetries:       cfg.RPCMaxRetries,
+		InitialBackoff:   cfg.RPCRetryInitialBackoff,
+		MaxBackoff:       cfg.RPCRetryMaxBackoff,
+		BreakerThreshold: cfg.RPCCircuitBreakerThreshold,
 	}
 
-	var batchTxService *Service
+	var (
+		batchTxService    *Service
+		batchStateService *Service
+	)
+
+	// In high availability mode, both services only submit while this
+	// replica holds leadership, and run a cycle as soon as it changes.
+	// The elector is only started once both services are initialized.
+	elector, leaderLock, err = newElector(cfg, func() {
+		if batchTxService != nil {
+			batchTxService.TriggerCycle()
+		}
+		if batchStateService != nil {
+			batchStateService.TriggerCycle()
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	var serviceElector LeaderElector
+	if elector != nil {
+		serviceElector = elector
+	}
+	buildInfo := newBuildInfo(cfg, gitVersion)
 	if cfg.RunTxBatchSubmitter {
+		gasPriceOracle, err := newGasPriceOracle(
+			ctx, cfg, cfg.SequencerGasPriceOracle, l1Client,
+			l1RPCClient,
+		)
+		if err != nil {
+			return nil, err
+		}
+		batchTxManagerConfig := txManagerConfig
+		batchTxManagerConfig.GasPriceOracle = gasPriceOracle
+
+		batchVersion, err := sequencer.ParseBatchVersion(cfg.BatchEncoding)
+		if err != nil {
+			return nil, err
+		}
+		batchBoundary, err := sequencer.ParseBatchBoundary(cfg.BatchBoundary)
+		if err != nil {
+			return nil, err
+		}
+		systemTxPolicy, err := sequencer.ParseSystemTxPolicy(cfg.SystemTxPolicy)
+		if err != nil {
+			return nil, err
+		}
+		systemTxSenders, err := sequencer.ParseSystemTxSenders(
+			cfg.SystemTxSenders,
+		)
+		if err != nil {
+			return nil, err
+		}
+		malformedBlockPolicy, err := sequencer.ParseMalformedBlockPolicy(
+			cfg.MalformedBlockPolicy,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		// Quarantined blocks are recorded alongside the submissions of
+		// the driver, such that they survive restarts.
+		batchTxDriverName := tenantPrefix(cfg) + "Sequencer"
+		stateStore, err := openStateStore(cfg, batchTxDriverName)
+		if err != nil {
+			return nil, err
+		}
+		var onMalformedBlock sequencer.MalformedBlockHook
+		if stateStore != nil {
+			stateStores = append(stateStores, stateStore)
+			onMalformedBlock = stateStore.PutQuarantinedBlock
+		}
+
+		rpcQuota := quota.NewRPC(
+			cfg.SequencerRPCQuota, cfg.SequencerRPCBurst,
+		)
+
 		batchTxDriver, err := sequencer.NewDriver(sequencer.Config{
-			Name:        "Sequencer",
-			L1Client:    l1Client,
-			L2Client:    l2Client,
-			BlockOffset: cfg.BlockOffset,
-			MaxTxSize:   cfg.MaxL1TxSize,
-			CTCAddr:     ctcAddress,
-			ChainID:     chainID,
-			PrivKey:     sequencerPrivKey,
+			Name:           batchTxDriverName,
+			L1Client:       l1Client,
+			L2Client:       l2BlockClient,
+			BlockOffset:    cfg.BlockOffset,
+			MaxTxSize:      cfg.MaxL1TxSize,
+			BlockCacheSize: int(cfg.BlockCacheSize),
+			CTCAddr:        ctcAddress,
+			ChainID:        chainID,
+			PrivKey:        sequencerPrivKey,
+			RotationKeys:   sequencerRotationKeys,
+
+			NumFetchWorkers:        int(cfg.NumFetchWorkers),
+			FetchTargetLatency:     cfg.FetchTargetLatency,
+			GasLimitMultiplier:     cfg.GasLimitMultiplier,
+			GasLimitBuffer:         cfg.GasLimitBuffer,
+			MaxGasLimit:            cfg.MaxGasLimit,
+			DryRun:                 cfg.DryRun,
+			Publisher:              txPublisher,
+			MaxContextDrift:        cfg.MaxContextDrift,
+			ExpectedInclusionDelay: cfg.ExpectedInclusionDelay,
+			SecondaryL2Client:      secondaryL2Client,
+			MinL2TxCount:           cfg.MinL2TxCount,
+			MinBatchBytes:          cfg.MinBatchBytes,
+			MaxBatchSubmissionTime: cfg.MaxBatchSubmissionTime,
+			MaxFeePerL2Tx:          gasPriceFromGwei(cfg.MaxFeePerL2TxInGwei),
+			MemoryQuota:            quota.NewMemory(cfg.SequencerMemoryQuota),
+			RPCQuota:               rpcQuota,
+			RPCTimeout:             cfg.RPCCallTimeout,
+			BatchVersion:           batchVersion,
+			BatchBoundary:          batchBoundary,
+			SystemTxPolicy:         systemTxPolicy,
+			BatchLimits: sequencer.BatchLimits{
+				MaxTxsPerContext:    cfg.MaxTxsPerContext,
+				MaxContextsPerBatch: cfg.MaxContextsPerBatch,
+			},
+			SystemTxs: sequencer.SystemTxFilter{
+				ZeroGasPrice: cfg.SystemTxZeroGasPrice,
+				Senders:      systemTxSenders,
+			},
+			MalformedBlockPolicy: malformedBlockPolicy,
+			MalformedBlockBudget: cfg.MalformedBlockBudget,
+			OnMalformedBlock:     onMalformedBlock,
 		})
 		if err != nil {
 			return nil, err
 		}
 
+		// Submission begins from the rotation wallet the CTC accepts
+		// sequencer batches from, if any are configured.
+		if err := UseAuthorizedWallet(ctx, batchTxDriver); err != nil {
+			return nil, err
+		}
+
+		// Each driver persists its queue under a directory named
+		// after the driver, so that tenants never share a queue.
+		if cfg.SubmissionQueueDir != "" {
+			submissionQueue, err = queue.NewSubmissionQueue(
+				filepath.Join(
+					cfg.SubmissionQueueDir,
+					batchTxDriver.Name(),
+				),
+				cfg.SubmissionQueueStaleLockTimeout,
+			)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		archiver, err = openArchiver(cfg, batchTxDriver.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		sendSelfTx := noncemgr.NewRotatingSelfTxSender(
+			l1Client, batchTxDriver.Wallets().Key, chainID,
+		)
+		var nonceGapFiller noncemgr.GapFiller
+		if cfg.FillNonceGaps {
+			nonceGapFiller = noncemgr.NewSelfTxGapFiller(
+				l1Client, sendSelfTx, txManagerConfig.MaxGasPrice,
+			)
+		}
+
+		// Sequencer batches also record the encoding they were
+		// built with, which may be changed between restarts.
+		batchBuildInfo := buildInfo
+		batchBuildInfo.Encoding = batchVersion.String()
+		batchBuildInfo.EncoderVersion = sequencer.EncoderVersion
+
+		var priorityMinGasPrice *big.Int
+		if cfg.PriorityLaneMinGasPriceInGwei != 0 {
+			priorityMinGasPrice = gasPriceFromGwei(
+				cfg.PriorityLaneMinGasPriceInGwei,
+			)
+		}
+
 		batchTxService = NewService(ServiceConfig{
 			Context:         ctx,
 			Driver:          batchTxDriver,
 			PollInterval:    cfg.PollInterval,
 			L1Client:        l1Client,
-			TxManagerConfig: txManagerConfig,
+			TxManagerConfig: batchTxManagerConfig,
+			SubmissionQueue: submissionQueue,
+			StateStore:      stateStore,
+			Archiver:        archiver,
+			DryRun:          cfg.DryRun,
+			VerifyBatches:   cfg.VerifyBatches,
+
+			DeferAboveMaxGasPrice: cfg.DeferAboveMaxGasPrice,
+			NonceGapFiller:        nonceGapFiller,
+			SelfTxSender:          sendSelfTx,
+			ClearPendingTxs:       cfg.ClearPendingTxs,
+			PendingTxStrategy:     cfg.PendingTxStrategy,
+			MaxInFlightBatches:    cfg.MaxInFlightBatches,
+			CatchUpChunkSize:      cfg.CatchUpChunkSize,
+			CatchUpBudget:         catchUpBudget,
+			SpendBudget:           floatEtherToWei(cfg.SpendBudgetEther),
+			PriorityLaneAge:       cfg.PriorityLaneAge,
+			PriorityMinGasPrice:   priorityMinGasPrice,
+			AddressBook:           addressBook,
+			MaxSubmissionAttempts: cfg.MaxSubmissionAttempts,
+			SelfTestMode:          cfg.SelfTestMode,
+			MinBalance:            minBalance,
+			CriticalBalance:       criticalBalance,
+			BalanceNotifiers:      balanceNotifiers,
+			BalanceDrainWindow:    cfg.BalanceDrainWindow,
+			BalanceDrainFactor:    cfg.BalanceDrainFactor,
+			MinBalanceDrainRate:   cfg.MinBalanceDrainRate,
+			StartupGracePeriod:    cfg.StartupGracePeriod,
+			MaxCycleAge:           cfg.HealthMaxCycleAge,
+			MaxConfirmationAge:    cfg.HealthMaxConfirmationAge,
+			RPCRetryPolicy:        rpcRetryPolicy,
+			RPCCallTimeout:        cfg.RPCCallTimeout,
+			MaxCycleDuration:      cfg.MaxCycleDuration,
+			Elector:               serviceElector,
+			Build:                 &batchBuildInfo,
+			WalletRotationBalance: walletRotationBalance,
+			WalletWedgedAfter:     cfg.WalletWedgedAfter,
 		})
 	}
 
-	var batchStateService *Service
 	if cfg.RunStateBatchSubmitter {
-		batchStateDriver, err := proposer.NewDriver(proposer.Config{
-			Name:        "Proposer",
-			L1Client:    l1Client,
-			L2Client:    l2Client,
-			BlockOffset: cfg.BlockOffset,
-			MaxTxSize:   cfg.MaxL1TxSize,
-			SCCAddr:     sccAddress,
-			CTCAddr:     ctcAddress,
-			ChainID:     chainID,
-			PrivKey:     proposerPrivKey,
-		})
+		gasPriceOracle, err := newGasPriceOracle(
+			ctx, cfg, cfg.ProposerGasPriceOracle, l1Client,
+			l1RPCClient,
+		)
+		if err != nil {
+			return nil, err
+		}
+		batchStateManagerConfig := txManagerConfig
+		batchStateManagerConfig.GasPriceOracle = gasPriceOracle
+
+		rpcQuota := quota.NewRPC(
+			cfg.ProposerRPCQuota, cfg.ProposerRPCBurst,
+		)
+
+		proposerCfg := proposer.Config{
+			Name:             tenantPrefix(cfg) + "Proposer",
+			L1Client:         l1Client,
+			L2Client:         l2Client,
+			BlockOffset:      cfg.BlockOffset,
+			MaxTxSize:        cfg.MaxL1TxSize,
+			SCCAddr:          sccAddress,
+			CTCAddr:          ctcAddress,
+			ChainID:          chainID,
+			PrivKey:          proposerPrivKey,
+			RotationKeys:     proposerRotationKeys,
+			DryRun:           cfg.DryRun,
+			Publisher:        txPublisher,
+			NumConfirmations: cfg.NumConfirmations,
+			MemoryQuota:      quota.NewMemory(cfg.ProposerMemoryQuota),
+			RPCQuota:         rpcQuota,
+			RPCTimeout:       cfg.RPCCallTimeout,
+		}
+
+		// When co-located with the sequencer, hold state batches behind
+		// the sequencer's confirmed batches.
+		if batchTxService != nil {
+			proposerCfg.SequencerHeight = batchTxService
+		}
+
+		batchStateDriver, err := proposer.NewDriver(proposerCfg)
 		if err != nil {
 			return nil, err
 		}
 
+		// Submission begins from the rotation wallet the SCC accepts
+		// state batches from, if any are configured.
+		if err := UseAuthorizedWallet(ctx, batchStateDriver); err != nil {
+			return nil, err
+		}
+
+		stateStore, err := openStateStore(cfg, batchStateDriver.Name())
+		if err != nil {
+			return nil, err
+		}
+		if stateStore != nil {
+			stateStores = append(stateStores, stateStore)
+		}
+
+		proofStore, err := openProofStore(cfg, batchStateDriver.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		sendSelfTx := noncemgr.NewRotatingSelfTxSender(
+			l1Client, batchStateDriver.Wallets().Key, chainID,
+		)
+		var nonceGapFiller noncemgr.GapFiller
+		if cfg.FillNonceGaps {
+			nonceGapFiller = noncemgr.NewSelfTxGapFiller(
+				l1Client, sendSelfTx, txManagerConfig.MaxGasPrice,
+			)
+		}
+
 		batchStateService = NewService(ServiceConfig{
 			Context:         ctx,
 			Driver:          batchStateDriver,
 			PollInterval:    cfg.PollInterval,
 			L1Client:        l1Client,
-			TxManagerConfig: txManagerConfig,
+			TxManagerConfig: batchStateManagerConfig,
+			StateStore:      stateStore,
+			ProofStore:      proofStore,
+			DryRun:          cfg.DryRun,
+
+			DeferAboveMaxGasPrice: cfg.DeferAboveMaxGasPrice,
+			NonceGapFiller:        nonceGapFiller,
+			SelfTxSender:          sendSelfTx,
+			ClearPendingTxs:       cfg.ClearPendingTxs,
+			PendingTxStrategy:     cfg.PendingTxStrategy,
+			CatchUpChunkSize:      cfg.CatchUpChunkSize,
+			CatchUpBudget:         catchUpBudget,
+			SpendBudget:           floatEtherToWei(cfg.SpendBudgetEther),
+			AddressBook:           addressBook,
+			MaxSubmissionAttempts: cfg.MaxSubmissionAttempts,
+			SelfTestMode:          cfg.SelfTestMode,
+			MinBalance:            minBalance,
+			CriticalBalance:       criticalBalance,
+			BalanceNotifiers:      balanceNotifiers,
+			BalanceDrainWindow:    cfg.BalanceDrainWindow,
+			BalanceDrainFactor:    cfg.BalanceDrainFactor,
+			MinBalanceDrainRate:   cfg.MinBalanceDrainRate,
+			StartupGracePeriod:    cfg.StartupGracePeriod,
+			MaxCycleAge:           cfg.HealthMaxCycleAge,
+			MaxConfirmationAge:    cfg.HealthMaxConfirmationAge,
+			RPCRetryPolicy:        rpcRetryPolicy,
+			RPCCallTimeout:        cfg.RPCCallTimeout,
+			MaxCycleDuration:      cfg.MaxCycleDuration,
+			Elector:               serviceElector,
+			Build:                 &buildInfo,
+			WalletRotationBalance: walletRotationBalance,
+			WalletWedgedAfter:     cfg.WalletWedgedAfter,
 		})
 	}
 
@@ -228,23 +836,69 @@ func NewBatchSubmitter(cfg Config, gitVersion string) (*BatchSubmitter, error) {
 		sccAddress:        sccAddress,
 		batchTxService:    batchTxService,
 		batchStateService: batchStateService,
+		submissionQueue:   submissionQueue,
+		stateStores:       stateStores,
+		archiver:          archiver,
+		rpcTransports:     rpcTransports,
+		elector:           elector,
+		leaderLock:        leaderLock,
 	}, nil
 }
 
+// Start starts the elector, if any, and both services. If any fails to start,
+// whatever was already started is stopped and every resource of the submitter
+// released, such that a submitter that failed to start never submits and need
+// not be stopped.
 func (b *BatchSubmitter) Start() error {
+	if b.elector != nil {
+		b.elector.Start()
+	}
+
+	var services []*Service
 	if b.cfg.RunTxBatchSubmitter {
-		if err := b.batchTxService.Start(); err != nil {
-			return err
-		}
+		services = append(services, b.batchTxService)
 	}
 	if b.cfg.RunStateBatchSubmitter {
-		if err := b.batchStateService.Start(); err != nil {
+		services = append(services, b.batchStateService)
+	}
+	for i, service := range services {
+		if err := service.Start(); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				_ = services[j].Stop()
+			}
+			if b.elector != nil {
+				b.elector.Stop()
+			}
+			b.release()
 			return err
 		}
 	}
+
 	return nil
 }
 
+// Drain drains both services concurrently, see Service.Drain, returning once
+// both are drained or ctx expires.
+func (b *BatchSubmitter) Drain(ctx context.Context) {
+	var services []*Service
+	if b.cfg.RunTxBatchSubmitter {
+		services = append(services, b.batchTxService)
+	}
+	if b.cfg.RunStateBatchSubmitter {
+		services = append(services, b.batchStateService)
+	}
+
+	var wg sync.WaitGroup
+	for _, service := range services {
+		wg.Add(1)
+		go func(service *Service) {
+			defer wg.Done()
+			_ = service.Drain(ctx)
+		}(service)
+	}
+	wg.Wait()
+}
+
 func (b *BatchSubmitter) Stop() {
 	if b.cfg.RunTxBatchSubmitter {
 		_ = b.batchTxService.Stop()
@@ -252,17 +906,222 @@ func (b *BatchSubmitter) Stop() {
 	if b.cfg.RunStateBatchSubmitter {
 		_ = b.batchStateService.Stop()
 	}
+	if b.elector != nil {
+		b.elector.Stop()
+	}
+	b.release()
+}
+
+// release closes the stores, locks and transports of the submitter, once its
+// services and elector have stopped or were never started.
+func (b *BatchSubmitter) release() {
+	if b.leaderLock != nil {
+		_ = b.leaderLock.Close()
+	}
+	if b.submissionQueue != nil {
+		if err := b.submissionQueue.Close(); err != nil {
+			log.Error("Unable to close submission queue", "err", err)
+		}
+	}
+	for _, stateStore := range b.stateStores {
+		if err := stateStore.Close(); err != nil {
+			log.Error("Unable to close submission state store",
+				"err", err)
+		}
+	}
+	if b.archiver != nil {
+		if err := b.archiver.Close(); err != nil {
+			log.Error("Unable to close batch archive", "err", err)
+		}
+	}
+	for _, transport := range b.rpcTransports {
+		transport.Close()
+	}
+}
+
+// newTxManagerConfig returns the tx manager config shared by both services,
+// without a gas price oracle.
+func newTxManagerConfig(cfg Config) txmgr.Config {
+	return txmgr.Config{
+		MinGasPrice:          gasPriceFromGwei(1),
+		MaxGasPrice:          gasPriceFromGwei(cfg.MaxGasPriceInGwei),
+		GasRetryIncrement:    gasPriceFromGwei(cfg.GasRetryIncrement),
+		ResubmissionTimeout:  cfg.ResubmissionTimeout,
+		ReceiptQueryInterval: time.Second,
+		NumConfirmations:     cfg.NumConfirmations,
+		FeeBump: txmgr.FeeBumpPolicy{
+			GasPricePercent: cfg.FeeBumpPercent,
+			TipCapPercent:   cfg.FeeBumpTipCapPercent,
+			FeeCapPercent:   cfg.FeeBumpFeeCapPercent,
+			MaxBumps:        cfg.MaxFeeBumps,
+			AbortAfter:      cfg.TxAbortAfter,
+		},
+	}
+}
+
+// newTxPublisher returns the publisher of batch txs for the configured
+// submission mode, which is l1Client itself in the public mode.
+func newTxPublisher(
+	cfg Config,
+	l1Client *ethclient.Client,
+) (txmgr.Publisher, error) {
+
+	mode, err := txmgr.ParseSubmissionMode(cfg.TxSubmissionMode)
+	if err != nil {
+		return nil, err
+	}
+	if mode == txmgr.SubmissionModePublic {
+		return l1Client, nil
+	}
+
+	var signingKey *ecdsa.PrivateKey
+	if cfg.TxRelaySigningKey != "" {
+		signingKey, err = ParsePrivateKeyStr(cfg.TxRelaySigningKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	publisher, err := txmgr.NewRelayPublisher(txmgr.RelayConfig{
+		Mode:       mode,
+		URL:        cfg.TxRelayURL,
+		SigningKey: signingKey,
+		MaxBlocks:  cfg.PrivateTxMaxBlocks,
+		Timeout:    cfg.RPCRequestTimeout,
+	}, l1Client)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("Submitting batch txs to private relay", "tenant",
+		cfg.TenantName, "mode", mode)
+
+	return publisher, nil
+}
+
+// newElector returns the elector campaigning for leadership in high
+// availability mode along with its lock, or nil if HALockURL is not set. The
+// onChange callback is invoked whenever leadership is gained or lost.
+func newElector(
+	cfg Config,
+	onChange func(),
+) (*leader.Elector, *leader.RedisLock, error) {
+
+	if cfg.HALockURL == "" {
+		return nil, nil, nil
+	}
+
+	key := cfg.HALockKey
+	if cfg.TenantName != "" {
+		key += ":" + cfg.TenantName
+	}
+	lock, err := leader.NewRedisLock(cfg.HALockURL, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	id := cfg.HAReplicaID
+	if id == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, nil, err
+		}
+		id = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	elector, err := leader.NewElector(leader.Config{
+		Lock:          lock,
+		ID:            id,
+		LeaseDuration: cfg.HALeaseDuration,
+		OnChange: func(bool) {
+			onChange()
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Info("High availability mode enabled", "tenant", cfg.TenantName,
+		"replica_id", id, "lock_key", key)
+
+	return elector, lock, nil
+}
+
+// openStateStore opens the submission state store of the driver with the given
+// name, or returns nil if neither a SubmissionStateURL nor a SubmissionStateDir
+// is configured. Each driver records its state under a namespace named after
+// the driver, so that drivers and tenants never share a store.
+func openStateStore(cfg Config, name string) (*queue.StateStore, error) {
+	if cfg.SubmissionStateURL != "" {
+		backend, err := queue.OpenBackend(
+			cfg.SubmissionStateURL, name,
+			cfg.SubmissionQueueStaleLockTimeout,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return queue.OpenStateStore(backend), nil
+	}
+	if cfg.SubmissionStateDir == "" {
+		return nil, nil
+	}
+
+	return queue.NewStateStore(
+		filepath.Join(cfg.SubmissionStateDir, name),
+		cfg.SubmissionQueueStaleLockTimeout,
+	)
+}
+
+// openArchiver opens the batch archive of the driver with the given name, or
+// returns nil if no ArchiveURL is configured. As with state stores, each driver
+// archives its batches under a namespace named after it.
+func openArchiver(cfg Config, name string) (*archive.Archiver, error) {
+	if cfg.ArchiveURL == "" {
+		return nil, nil
+	}
+
+	key, err := archive.ParseKey(cfg.ArchiveKey)
+	if err != nil {
+		return nil, err
+	}
+	sealer, err := archive.NewSealer(cfg.ArchiveKeyID, key)
+	if err != nil {
+		return nil, err
+	}
+
+	backend, err := queue.OpenBackend(
+		cfg.ArchiveURL, name, cfg.SubmissionQueueStaleLockTimeout,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return archive.NewArchiver(sealer, backend), nil
+}
+
+// openProofStore opens the inclusion proof store of the driver with the given
+// name, or returns nil if no InclusionProofDir is configured. As with state
+// stores, each driver stores its proofs under a directory named after it.
+func openProofStore(cfg Config, name string) (*queue.ProofStore, error) {
+	if cfg.InclusionProofDir == "" {
+		return nil, nil
+	}
+
+	return queue.NewProofStore(filepath.Join(cfg.InclusionProofDir, name))
 }
 
 // parseWalletPrivKeyAndContractAddr returns the wallet private key to use for
 // sending transactions as well as the contract address to send to for a
-// particular sub-service.
+// particular sub-service. The wallet and contract are labeled in addressBook
+// after name and contractName respectively.
 func parseWalletPrivKeyAndContractAddr(
 	name string,
+	contractName string,
 	mnemonic string,
 	hdPath string,
 	privKeyStr string,
 	contractAddrStr string,
+	addressBook *AddressBook,
 ) (*ecdsa.PrivateKey, common.Address, error) {
 
 	// Parse wallet private key from either privkey string or BIP39 mnemonic
@@ -281,46 +1140,261 @@ func parseWalletPrivKeyAndContractAddr(
 	// Log wallet address rather than private key...
 	walletAddress := crypto.PubkeyToAddress(privKey.PublicKey)
 
+	addressBook.SetDefault(walletAddress, name+" wallet")
+	addressBook.SetDefault(contractAddress, contractName)
+
 	log.Info(name+" wallet params parsed successfully", "wallet_address",
-		walletAddress, "contract_address", contractAddress)
+		addressBook.Format(walletAddress), "contract_address",
+		addressBook.Format(contractAddress))
 
 	return privKey, contractAddress, nil
 }
 
+// parseRotationPrivKeys returns the private keys of the wallets to which a
+// particular sub-service may rotate submission, labeling each in addressBook
+// after name and its position following the primary wallet.
+func parseRotationPrivKeys(
+	name string,
+	privKeysStr string,
+	addressBook *AddressBook,
+) ([]*ecdsa.PrivateKey, error) {
+
+	privKeys, err := ParsePrivateKeysStr(privKeysStr)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, privKey := range privKeys {
+		walletAddress := crypto.PubkeyToAddress(privKey.PublicKey)
+		addressBook.SetDefault(
+			walletAddress, fmt.Sprintf("%s wallet %d", name, i+2),
+		)
+	}
+	if len(privKeys) > 0 {
+		log.Info(name+" rotation wallets parsed successfully",
+			"count", len(privKeys))
+	}
+
+	return privKeys, nil
+}
+
 // runMetricsServer spins up a prometheus metrics server at the provided
-// hostname and port.
+// hostname and port. The metrics of gatherer are only served for scraping if
+// gatherer is non-nil, while the status, health and live status endpoints are
+// always served. The admin API is served by the admin server instead. A
+// dedicated mux is used rather than http.DefaultServeMux, on which
+// net/http/pprof registers its profiles.
 //
 // NOTE: This method MUST be run as a goroutine.
-func runMetricsServer(hostname string, port uint64) {
+func runMetricsServer(
+	hostname string,
+	port uint64,
+	gatherer prometheus.Gatherer,
+) {
+
 	metricsPortStr := strconv.FormatUint(port, 10)
 	metricsAddr := fmt.Sprintf("%s:%s", hostname, metricsPortStr)
 
-	http.Handle("/metrics", promhttp.Handler())
-	_ = http.ListenAndServe(metricsAddr, nil)
+	mux := http.NewServeMux()
+	if gatherer != nil {
+		mux.Handle("/metrics", promhttp.InstrumentMetricHandler(
+			prometheus.DefaultRegisterer,
+			promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}),
+		))
+	}
+	mux.Handle(statusPath, defaultStatusRegistry)
+	mux.Handle(healthzPath, healthHandler{
+		registry: defaultStatusRegistry,
+	})
+	mux.Handle(readyzPath, healthHandler{
+		registry: defaultStatusRegistry,
+		ready:    true,
+	})
+	for path, handler := range liveHandlers(defaultStatusRegistry) {
+		mux.Handle(path, handler)
+	}
+	_ = http.ListenAndServe(metricsAddr, mux)
 }
 
-// dialL1EthClientWithTimeout attempts to dial the L1 provider using the
-// provided URL. If the dial doesn't complete within defaultDialTimeout seconds,
-// this method will return an error.
-func dialL1EthClientWithTimeout(ctx context.Context, url string) (
-	*ethclient.Client, error) {
+// splitEndpoints parses a comma-separated list of provider URLs.
+func splitEndpoints(urls string) []string {
+	var endpoints []string
+	for _, url := range strings.Split(urls, ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			endpoints = append(endpoints, url)
+		}
+	}
+	return endpoints
+}
 
-	ctxt, cancel := context.WithTimeout(ctx, defaultDialTimeout)
-	defer cancel()
+// newFailoverClient returns an HTTP client failing over between endpoints,
+// along with the transport doing so. The backend labels the transport's logs
+// and metrics, and is prefixed by the tenant's name in multi-tenant mode.
+func newFailoverClient(
+	cfg Config,
+	backend string,
+	endpoints []string,
+) (*http.Client, *failover.Transport, error) {
+
+	transport, err := failover.NewTransport(failover.Config{
+		Backend:             tenantPrefix(cfg) + backend,
+		Endpoints:           endpoints,
+		HealthCheckInterval: cfg.RPCHealthCheckInterval,
+		RequestTimeout:      cfg.RPCRequestTimeout,
+		LoadBalanceReads:    cfg.RPCLoadBalanceReads,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return newInstrumentedClient(cfg, backend, transport), transport, nil
+}
+
+// newInstrumentedClient returns an HTTP client recording per-method metrics of
+// each RPC made using base, labeled by the backend prefixed by the tenant's
+// name.
+func newInstrumentedClient(
+	cfg Config,
+	backend string,
+	base http.RoundTripper,
+) *http.Client {
+
+	return &http.Client{
+		Transport: rpcmetrics.NewTransport(tenantPrefix(cfg)+backend, base),
+	}
+}
+
+// isHTTPEndpoint returns true if url is dialed over HTTP, rather than
+// WebSocket or IPC.
+func isHTTPEndpoint(url string) bool {
+	url = strings.ToLower(strings.TrimSpace(url))
+	return strings.HasPrefix(url, "http://") ||
+		strings.HasPrefix(url, "https://")
+}
 
-	return ethclient.DialContext(ctxt, url)
+// dialL1RPCClientWithTimeout attempts to dial the L1 provider using the
+// provided comma-separated list of URLs. A single URL is dialed directly,
+// while multiple URLs are dialed over HTTP, failing over between them using
+// the returned transport. RPCs made over HTTP are instrumented per method. If
+// the dial doesn't complete within defaultDialTimeout seconds, this method
+// will return an error.
+func dialL1RPCClientWithTimeout(
+	ctx context.Context,
+	cfg Config,
+	backend, urls string,
+) (*rpc.Client, *failover.Transport, error) {
+
+	endpoints := splitEndpoints(urls)
+	if len(endpoints) <= 1 {
+		ctxt, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+		defer cancel()
+
+		if isHTTPEndpoint(urls) {
+			client, err := rpc.DialHTTPWithClient(
+				strings.TrimSpace(urls),
+				newInstrumentedClient(cfg, backend, nil),
+			)
+			return client, nil, err
+		}
+
+		client, err := rpc.DialContext(ctxt, urls)
+		return client, nil, err
+	}
+
+	httpClient, transport, err := newFailoverClient(cfg, backend, endpoints)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := rpc.DialHTTPWithClient(endpoints[0], httpClient)
+	if err != nil {
+		transport.Close()
+		return nil, nil, err
+	}
+
+	return client, transport, nil
+}
+
+// dialL2RPCClientWithTimeout attempts to dial the L2 provider using the
+// provided comma-separated list of URLs, failing over between multiple URLs
+// as dialL1RPCClientWithTimeout does. If the dial doesn't complete within
+// defaultDialTimeout seconds, this method will return an error.
+func dialL2RPCClientWithTimeout(
+	ctx context.Context,
+	cfg Config,
+	backend, urls string,
+) (*l2rpc.Client, *failover.Transport, error) {
+
+	endpoints := splitEndpoints(urls)
+	if len(endpoints) <= 1 {
+		ctxt, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+		defer cancel()
+
+		if isHTTPEndpoint(urls) {
+			client, err := l2rpc.DialHTTPWithClient(
+				strings.TrimSpace(urls),
+				newInstrumentedClient(cfg, backend, nil),
+			)
+			if err != nil {
+				return nil, nil, err
+			}
+			return client, nil, nil
+		}
+
+		client, err := l2rpc.DialContext(ctxt, urls)
+		return client, nil, err
+	}
+
+	httpClient, transport, err := newFailoverClient(cfg, backend, endpoints)
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := l2rpc.DialHTTPWithClient(endpoints[0], httpClient)
+	if err != nil {
+		transport.Close()
+		return nil, nil, err
+	}
+
+	return client, transport, nil
 }
 
-// dialL2EthClientWithTimeout attempts to dial the L2 provider using the
-// provided URL. If the dial doesn't complete within defaultDialTimeout seconds,
-// this method will return an error.
-func dialL2EthClientWithTimeout(ctx context.Context, url string) (
-	*l2ethclient.Client, error) {
+// newL2BlockClient returns a client reading the L2 blocks to be batched from
+// rpcClient in the flavor of RPC configured for the backend, detecting the
+// flavor served by the node if configured to. If the detection doesn't
+// complete within defaultDialTimeout seconds, this method will return an
+// error.
+func newL2BlockClient(
+	ctx context.Context,
+	cfg Config,
+	backend string,
+	rpcClient *l2rpc.Client,
+) (l2client.Client, error) {
+
+	flavor, err := l2client.ParseFlavor(cfg.L2RPCFlavor)
+	if err != nil {
+		return nil, err
+	}
 
 	ctxt, cancel := context.WithTimeout(ctx, defaultDialTimeout)
 	defer cancel()
 
-	return l2ethclient.DialContext(ctxt, url)
+	client, flavor, err := l2client.New(ctxt, rpcClient, flavor)
+	if err != nil {
+		return nil, fmt.Errorf("unable to detect %s rpc flavor: %w",
+			backend, err)
+	}
+	log.Info("Reading L2 blocks", "backend", backend, "flavor", flavor)
+
+	return client, nil
+}
+
+// tenantPrefix returns the prefix applied to service names, and consequently
+// log messages and metric subsystems, for the tenant described by cfg. The
+// prefix is empty in single-tenant mode.
+func tenantPrefix(cfg Config) string {
+	if cfg.TenantName == "" {
+		return ""
+	}
+	return cfg.TenantName + "_"
 }
 
 // traceRateToFloat64 converts a time.Duration into a valid float64 for the
@@ -334,6 +1408,41 @@ func traceRateToFloat64(rate time.Duration) float64 {
 	return rate64
 }
 
+// newBalanceNotifiers initializes a notifier for each configured balance
+// alert destination.
+func newBalanceNotifiers(cfg Config) []alerts.Notifier {
+	var notifiers []alerts.Notifier
+	if cfg.AlertWebhookURL != "" {
+		notifiers = append(notifiers,
+			alerts.NewWebhookNotifier(cfg.AlertWebhookURL))
+	}
+	if cfg.AlertSlackWebhookURL != "" {
+		notifiers = append(notifiers,
+			alerts.NewSlackNotifier(cfg.AlertSlackWebhookURL))
+	}
+	if cfg.AlertPagerDutyRoutingKey != "" {
+		notifiers = append(notifiers, alerts.NewPagerDutyNotifier(
+			alerts.DefaultPagerDutyURL, cfg.AlertPagerDutyRoutingKey,
+		))
+	}
+
+	return notifiers
+}
+
 func gasPriceFromGwei(gasPriceInGwei uint64) *big.Int {
 	return new(big.Int).SetUint64(gasPriceInGwei * 1e9)
 }
+
+// etherToWei converts an amount of ether to wei.
+func etherToWei(ether uint64) *big.Int {
+	wei := new(big.Int).SetUint64(ether)
+	return wei.Mul(wei, big.NewInt(1e18))
+}
+
+// floatEtherToWei converts a fractional amount of ether to wei.
+func floatEtherToWei(ether float64) *big.Int {
+	wei, _ := new(big.Float).Mul(
+		big.NewFloat(ether), big.NewFloat(1e18),
+	).Int(nil)
+	return wei
+}
@@ -0,0 +1,74 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// newStartTestService returns a service whose self-test returns driverErr in
+// strict mode, and which never runs a cycle once started.
+func newStartTestService(name string, driverErr error) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Service{
+		cfg: ServiceConfig{
+			Driver: selfTestDriver{
+				namedDriver: namedDriver{name: name},
+				err:         driverErr,
+			},
+			Elector:      &fakeElector{},
+			SelfTestMode: SelfTestStrict,
+		},
+		ctx:          ctx,
+		cancel:       cancel,
+		metrics:      metrics.NewMetrics(name),
+		pollInterval: int64(time.Hour),
+	}
+}
+
+// TestBatchSubmitterStartFailure asserts that a service failing to start stops
+// the services started before it, and releases the resources of the submitter,
+// such that a submitter that failed to start never submits.
+func TestBatchSubmitterStartFailure(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stateStore, err := queue.NewStateStore(dir, time.Hour)
+	require.Nil(t, err)
+
+	driverErr := errors.New("unable to reach L1")
+	batchTxService := newStartTestService(
+		"TestBatchSubmitterStartFailureTx", nil,
+	)
+	batchStateService := newStartTestService(
+		"TestBatchSubmitterStartFailureState", driverErr,
+	)
+
+	b := &BatchSubmitter{
+		cfg: Config{
+			RunTxBatchSubmitter:    true,
+			RunStateBatchSubmitter: true,
+		},
+		batchTxService:    batchTxService,
+		batchStateService: batchStateService,
+		stateStores:       []*queue.StateStore{stateStore},
+	}
+
+	err = b.Start()
+	require.True(t, errors.Is(err, ErrSelfTestFailed))
+
+	require.NotNil(t, batchTxService.ctx.Err())
+	_, ok := defaultStatusRegistry.lookup("TestBatchSubmitterStartFailureTx")
+	require.False(t, ok)
+
+	// The state store was closed, releasing its lock.
+	stateStore, err = queue.NewStateStore(dir, time.Hour)
+	require.Nil(t, err)
+	require.Nil(t, stateStore.Close())
+}
@@ -0,0 +1,202 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrL2Reorged signals that the L2 chain no longer contains the block at the
+// end of the last confirmed batch, i.e. that an L2 reorg reached beneath the
+// batches already committed to L1.
+var ErrL2Reorged = errors.New("L2 block at end of confirmed batch was " +
+	"reorged out")
+
+// BlockHasher is an optional interface that may be implemented by a Driver to
+// pin the L2 block at the end of each confirmed batch. The pinned block is
+// verified at the start of every cycle, such that an L2 reorg beneath the
+// confirmed batches halts submission rather than being built on top of.
+type BlockHasher interface {
+	// L2BlockHash returns the hash of the L2 block at number, bypassing
+	// any cache.
+	L2BlockHash(ctx context.Context, number *big.Int) (common.Hash, error)
+}
+
+// BlockPin is the L2 block at the end of the highest confirmed batch.
+type BlockPin struct {
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+}
+
+// blockPinState holds the highest BlockPin, updated as batches confirm.
+//
+// NOTE: blockPinState is safe for concurrent use, as pipelined batches are
+// confirmed outside of the event loop.
+type blockPinState struct {
+	mu  sync.Mutex
+	pin *BlockPin
+}
+
+// Advance pins the block at number with hash, unless a higher block is already
+// pinned.
+func (p *blockPinState) Advance(number uint64, hash common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pin != nil && p.pin.Number > number {
+		return
+	}
+	p.pin = &BlockPin{
+		Number: number,
+		Hash:   hash,
+	}
+}
+
+// Get returns the pinned block, or nil if none is pinned.
+func (p *blockPinState) Get() *BlockPin {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pin == nil {
+		return nil
+	}
+	pin := *p.pin
+	return &pin
+}
+
+// endBlockHash returns the hash of the L2 block at the end of sub, taken from
+// its prebuilt batch if any, and otherwise queried from a Driver implementing
+// BlockHasher right after the batch tx is published. The zero hash is returned
+// if the hash is unknown, in which case the batch is never pinned.
+func (s *Service) endBlockHash(
+	ctx context.Context,
+	sub *batchSubmission,
+) common.Hash {
+
+	if sub.batch != nil && sub.batch.EndBlockHash != (common.Hash{}) {
+		return sub.batch.EndBlockHash
+	}
+
+	hasher, ok := s.cfg.Driver.(BlockHasher)
+	if !ok || sub.end.Sign() <= 0 {
+		return common.Hash{}
+	}
+
+	var hash common.Hash
+	number := new(big.Int).Sub(sub.end, big.NewInt(1))
+	err := s.callWithTimeout(ctx, func(ctx context.Context) error {
+		var err error
+		hash, err = hasher.L2BlockHash(ctx, number)
+		return err
+	})
+	if err != nil {
+		log.Warn(s.cfg.Driver.Name()+" unable to get end block hash, "+
+			"batch will not be pinned", "number", number, "err", err)
+		return common.Hash{}
+	}
+
+	return hash
+}
+
+// pinEndBlock pins the L2 block at the end of a confirmed batch ending at end,
+// exclusive, if its hash is known and the batch tx succeeded.
+func (s *Service) pinEndBlock(
+	end uint64,
+	hash common.Hash,
+	receipt *types.Receipt,
+) {
+
+	if end == 0 || hash == (common.Hash{}) ||
+		receipt.Status != types.ReceiptStatusSuccessful {
+
+		return
+	}
+
+	s.endBlockPin.Advance(end-1, hash)
+}
+
+// loadEndBlockPin pins the end block of the most recently confirmed batch in the
+// configured StateStore, such that a restarted service verifies the chain it
+// left off on.
+func (s *Service) loadEndBlockPin() {
+	if s.cfg.StateStore == nil {
+		return
+	}
+
+	recent, err := s.cfg.StateStore.Recent(defaultRecentSubmissions)
+	if err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to load pinned end "+
+			"block", "err", err)
+		return
+	}
+	for _, record := range recent {
+		if record.Status != queue.SubmissionConfirmed ||
+			record.Receipt == nil ||
+			record.Receipt.Status != types.ReceiptStatusSuccessful ||
+			record.EndBlockHash == (common.Hash{}) || record.End == 0 {
+
+			continue
+		}
+
+		s.endBlockPin.Advance(record.End-1, record.EndBlockHash)
+		return
+	}
+}
+
+// checkEndBlockPin verifies that the L2 chain still contains the pinned block,
+// if any, recording the outcome in trace. If the block was reorged out, or
+// cannot be verified, false is returned and the cycle must be abandoned.
+func (s *Service) checkEndBlockPin(
+	ctx context.Context,
+	trace *CycleTrace,
+) bool {
+
+	pin := s.endBlockPin.Get()
+	if pin == nil {
+		return true
+	}
+	hasher, ok := s.cfg.Driver.(BlockHasher)
+	if !ok {
+		return true
+	}
+
+	name := s.cfg.Driver.Name()
+	number := new(big.Int).SetUint64(pin.Number)
+
+	var hash common.Hash
+	err := s.queryWithRetry(ctx, "end_block_pin", func(
+		ctx context.Context) error {
+
+		var err error
+		hash, err = hasher.L2BlockHash(ctx, number)
+		return err
+	})
+	if err != nil {
+		log.Error(name+" unable to verify pinned end block",
+			"number", pin.Number, "err", err)
+		trace.Failed("unable to verify pinned end block", err)
+		return false
+	}
+
+	if hash != pin.Hash {
+		s.metrics.L2ReorgsDetected.Inc()
+		log.Error(name+" L2 reorg beneath confirmed batches, halting "+
+			"submission", "number", pin.Number,
+			"pinned_hash", pin.Hash, "hash", hash)
+		trace.Failed("L2 reorg beneath confirmed batches",
+			fmt.Errorf("%w: block=%d pinned_hash=%s hash=%s",
+				ErrL2Reorged, pin.Number, pin.Hash.Hex(),
+				hash.Hex()))
+		return false
+	}
+	trace.Step("end_block_pin", "number=%d hash=%s", pin.Number, hash)
+
+	return true
+}
@@ -0,0 +1,85 @@
+package batchsubmitter
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// hashingDriver is a driver returning the L2 block hashes of its chain.
+type hashingDriver struct {
+	namedDriver
+	chain map[uint64]common.Hash
+}
+
+func (d hashingDriver) L2BlockHash(
+	_ context.Context, number *big.Int) (common.Hash, error) {
+
+	return d.chain[number.Uint64()], nil
+}
+
+// TestBlockPinStateAdvance asserts that the pin only ever advances, e.g. if
+// pipelined batches confirm out of order.
+func TestBlockPinStateAdvance(t *testing.T) {
+	t.Parallel()
+
+	var p blockPinState
+	require.Nil(t, p.Get())
+
+	p.Advance(20, common.Hash{0x20})
+	p.Advance(15, common.Hash{0x15})
+	require.Equal(t, &BlockPin{Number: 20, Hash: common.Hash{0x20}}, p.Get())
+}
+
+// TestServicePinEndBlock asserts that only successful batches with a known end
+// block hash are pinned, at the last block of their range.
+func TestServicePinEndBlock(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{}
+	success := &types.Receipt{Status: types.ReceiptStatusSuccessful}
+	failure := &types.Receipt{Status: types.ReceiptStatusFailed}
+
+	s.pinEndBlock(10, common.Hash{}, success)
+	s.pinEndBlock(10, common.Hash{0x09}, failure)
+	require.Nil(t, s.endBlockPin.Get())
+
+	s.pinEndBlock(10, common.Hash{0x09}, success)
+	require.Equal(t, &BlockPin{Number: 9, Hash: common.Hash{0x09}},
+		s.endBlockPin.Get())
+}
+
+// TestServiceCheckEndBlockPin asserts that a cycle proceeds while the chain
+// contains the pinned block, and is abandoned once the block is reorged out.
+func TestServiceCheckEndBlockPin(t *testing.T) {
+	t.Parallel()
+
+	driver := hashingDriver{
+		namedDriver: namedDriver{name: "TestServiceCheckEndBlockPin"},
+		chain:       map[uint64]common.Hash{9: {0x09}},
+	}
+	s := newRetryTestService(context.Background(), driver.name,
+		RetryPolicy{})
+	s.cfg.Driver = driver
+
+	// Nothing is pinned yet.
+	require.True(t, s.checkEndBlockPin(s.ctx, newCycleTrace()))
+
+	s.endBlockPin.Advance(9, common.Hash{0x09})
+	trace := newCycleTrace()
+	require.True(t, s.checkEndBlockPin(s.ctx, trace))
+	require.Equal(t, "end_block_pin", trace.Steps[0].Name)
+
+	driver.chain[9] = common.Hash{0xff}
+	trace = newCycleTrace()
+	require.False(t, s.checkEndBlockPin(s.ctx, trace))
+	require.Equal(t, CycleFailed, trace.Outcome)
+	require.True(t, strings.HasPrefix(trace.Error, ErrL2Reorged.Error()))
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.L2ReorgsDetected))
+}
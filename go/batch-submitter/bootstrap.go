@@ -0,0 +1,76 @@
+package batchsubmitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/failover"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/flags"
+	l2rpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+)
+
+// Bootstrap runs the bootstrap subcommand, deriving the submitted L2 height
+// from the SequencerBatchAppended events of the CTC alone, such that a
+// sequencer can be cold-started without an archive of the submitted L2 blocks.
+// The height is validated against the blocks available from the L2 node, and
+// the report is printed as JSON. An error is returned describing any blocks
+// pending submission that the L2 node is unable to serve, rather than the
+// generic range error the sequencer would otherwise fail with.
+func Bootstrap(ctx *cli.Context) error {
+	cfg, err := NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+	ctcAddr, err := ParseAddress(cfg.CTCAddress)
+	if err != nil {
+		return err
+	}
+
+	l1Endpoints := splitEndpoints(cfg.L1EthRpc)
+	l2Endpoints := splitEndpoints(cfg.L2EthRpc)
+	if len(l1Endpoints) == 0 || len(l2Endpoints) == 0 {
+		return failover.ErrNoEndpoints
+	}
+	c := context.Background()
+	l1Client, err := ethclient.DialContext(c, l1Endpoints[0])
+	if err != nil {
+		return err
+	}
+	defer l1Client.Close()
+	l2RPCClient, err := l2rpc.DialContext(c, l2Endpoints[0])
+	if err != nil {
+		return err
+	}
+	defer l2RPCClient.Close()
+	l2Client, err := newL2BlockClient(c, cfg, "l2", l2RPCClient)
+	if err != nil {
+		return err
+	}
+
+	report, err := sequencer.Bootstrap(c, sequencer.BootstrapConfig{
+		L1Client:     l1Client,
+		L2Client:     l2Client,
+		CTCAddr:      ctcAddr,
+		BlockOffset:  cfg.BlockOffset,
+		FromL1Block:  ctx.Uint64(flags.BootstrapFromL1BlockFlag.Name),
+		L1BlockRange: ctx.Uint64(flags.BootstrapL1BlockRangeFlag.Name),
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(os.Stdout, string(out)); err != nil {
+		return err
+	}
+
+	return report.Err()
+}
@@ -0,0 +1,48 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ConfigHash returns the keccak256 hash of the JSON encoding of cfg, with its
+// secrets cleared. Endpoints are cleared as well, since their URLs may embed
+// credentials.
+func ConfigHash(cfg Config) common.Hash {
+	for _, secret := range []*string{
+		&cfg.L1EthRpc,
+		&cfg.L2EthRpc,
+		&cfg.SecondaryL2EthRpc,
+		&cfg.SentryDsn,
+		&cfg.TxRelayURL,
+		&cfg.TxRelaySigningKey,
+		&cfg.AlertWebhookURL,
+		&cfg.AlertSlackWebhookURL,
+		&cfg.AlertPagerDutyRoutingKey,
+		&cfg.GasPriceOracleURL,
+		&cfg.SequencerPrivateKey,
+		&cfg.ProposerPrivateKey,
+		&cfg.Mnemonic,
+		&cfg.PushgatewayURL,
+		&cfg.SubmissionStateURL,
+		&cfg.HALockURL,
+	} {
+		*secret = ""
+	}
+
+	// Config only holds plain values, which always encode.
+	encoded, _ := json.Marshal(cfg)
+	return crypto.Keccak256Hash(encoded)
+}
+
+// newBuildInfo returns the BuildInfo recorded alongside the submissions of a
+// submitter running the given version with cfg.
+func newBuildInfo(cfg Config, gitVersion string) queue.BuildInfo {
+	return queue.BuildInfo{
+		Version:    gitVersion,
+		ConfigHash: ConfigHash(cfg),
+	}
+}
@@ -0,0 +1,33 @@
+package batchsubmitter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigHash asserts that the config hash identifies the settings of a
+// submitter, but not its secrets or endpoints.
+func TestConfigHash(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{
+		BatchEncoding:       "v2",
+		MaxL1TxSize:         90000,
+		SequencerPrivateKey: "sequencer-privkey",
+		L1EthRpc:            "https://l1.example/api-key",
+	}
+	hash := ConfigHash(cfg)
+
+	rotated := cfg
+	rotated.SequencerPrivateKey = "rotated-privkey"
+	rotated.L1EthRpc = "https://l1.example/other-api-key"
+	require.Equal(t, hash, ConfigHash(rotated))
+
+	changed := cfg
+	changed.MaxL1TxSize = 100000
+	require.NotEqual(t, hash, ConfigHash(changed))
+
+	// The passed config is not mutated.
+	require.Equal(t, "sequencer-privkey", cfg.SequencerPrivateKey)
+}
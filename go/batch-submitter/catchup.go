@@ -0,0 +1,483 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// adminCatchUpPath is the path at which catch-up plans are approved, cancelled
+// or replanned by the admin server.
+const adminCatchUpPath = "/admin/catchup"
+
+// ErrNoCatchUpPlan signals an attempt to act on a catch-up plan when none is
+// awaiting approval or executing.
+var ErrNoCatchUpPlan = errors.New("no catch-up plan is pending")
+
+// ErrUnknownCatchUpAction signals an attempt to act on a catch-up plan with an
+// unsupported action.
+var ErrUnknownCatchUpAction = errors.New("catch-up action must be " +
+	"approve, cancel or replan")
+
+// CatchUpAction is an operator's decision on a catch-up plan.
+type CatchUpAction string
+
+const (
+	// CatchUpApprove executes a plan awaiting approval.
+	CatchUpApprove CatchUpAction = "approve"
+
+	// CatchUpCancel stops a plan awaiting approval or executing, such that
+	// the remaining backlog is submitted a chunk per poll interval.
+	CatchUpCancel CatchUpAction = "cancel"
+
+	// CatchUpReplan discards the current plan, such that the next cycle
+	// plans the catch-up of the remaining backlog afresh.
+	CatchUpReplan CatchUpAction = "replan"
+)
+
+// CatchUpState is the progress of a catch-up plan.
+type CatchUpState string
+
+const (
+	// CatchUpAwaitingApproval is the state of a plan whose estimated cost
+	// exceeds the approval threshold, or is unknown. Until approved, the
+	// backlog is submitted a chunk per poll interval.
+	CatchUpAwaitingApproval CatchUpState = "awaiting_approval"
+
+	// CatchUpExecuting is the state of a plan whose chunks are submitted
+	// back to back.
+	CatchUpExecuting CatchUpState = "executing"
+
+	// CatchUpCompleted is the state of a plan whose blocks were all
+	// submitted, or whose backlog was cleared.
+	CatchUpCompleted CatchUpState = "completed"
+
+	// CatchUpBudgetExhausted is the state of a plan stopped upon spending
+	// its max spend, or exceeding its max duration.
+	CatchUpBudgetExhausted CatchUpState = "budget_exhausted"
+
+	// CatchUpCancelled is the state of a plan cancelled by an operator.
+	CatchUpCancelled CatchUpState = "cancelled"
+)
+
+// CatchUpBudget bounds the catch-up on a backlog larger than CatchUpChunkSize.
+type CatchUpBudget struct {
+	// MaxSpend, if positive, is the max spend in wei of the batch txs
+	// submitted back to back.
+	MaxSpend *big.Int
+
+	// MaxDuration, if non-zero, is the max duration for which chunks are
+	// submitted back to back.
+	MaxDuration time.Duration
+
+	// ApprovalThreshold, if positive, is the estimated cost in wei above
+	// which a plan awaits approval by an operator before executing.
+	ApprovalThreshold *big.Int
+}
+
+// enabled returns true if any bound of the budget is set.
+func (b CatchUpBudget) enabled() bool {
+	return isPositive(b.MaxSpend) || b.MaxDuration > 0 ||
+		isPositive(b.ApprovalThreshold)
+}
+
+// isPositive returns true if x is set and greater than zero.
+func isPositive(x *big.Int) bool {
+	return x != nil && x.Sign() > 0
+}
+
+// CatchUpPlan is the schedule of a catch-up within its budget, as reported by
+// the status API.
+type CatchUpPlan struct {
+	State CatchUpState `json:"state"`
+
+	// Start and End bound the backlog when the plan was made, [Start, End).
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	// PlannedEnd is the exclusive end of the blocks submitted back to back
+	// within the budget. Any blocks beyond it are submitted a chunk per
+	// poll interval.
+	PlannedEnd uint64 `json:"planned_end"`
+
+	// ChunkSize and NumChunks are the size and number of the chunks
+	// covering [Start, PlannedEnd).
+	ChunkSize uint64 `json:"chunk_size"`
+	NumChunks uint64 `json:"num_chunks"`
+
+	// GasPrice is the gas price at which each batch tx is expected to
+	// confirm under the active fee strategy, in wei.
+	GasPrice *big.Int `json:"gas_price,omitempty"`
+
+	// EstimatedCost is the expected cost of the planned chunks in wei, or
+	// nil if no batch has confirmed to estimate it from.
+	EstimatedCost *big.Int `json:"estimated_cost,omitempty"`
+
+	// EstimatedDuration is the expected duration until the planned chunks
+	// are confirmed, or empty if unknown.
+	EstimatedDuration string `json:"estimated_duration,omitempty"`
+
+	// MaxSpend and MaxDuration are the bounds of the budget, if set.
+	MaxSpend    *big.Int `json:"max_spend,omitempty"`
+	MaxDuration string   `json:"max_duration,omitempty"`
+
+	// RequiresApproval is true if the plan awaited approval by an operator
+	// before executing.
+	RequiresApproval bool `json:"requires_approval"`
+
+	// Spent is the cost in wei of the batches confirmed while executing.
+	// Batches in flight when the budget is exhausted may overshoot it.
+	Spent *big.Int `json:"spent"`
+
+	// PlannedAt is the time at which the plan was made, and StartedAt the
+	// time at which its first chunk was submitted.
+	PlannedAt time.Time  `json:"planned_at"`
+	StartedAt *time.Time `json:"started_at,omitempty"`
+
+	// Deadline is the time after which no further chunks are submitted
+	// back to back, if MaxDuration is set.
+	Deadline *time.Time `json:"deadline,omitempty"`
+}
+
+// planCatchUp plans the catch-up of the backlog [params.start, params.end) in
+// chunks of chunkSize submitted back to back, under the active fee strategy.
+// The planned chunks are the first that fit within the budget, as estimated
+// from the gas used per L2 tx of recently confirmed batches, if any.
+func planCatchUp(
+	params forecastParams,
+	chunkSize uint64,
+	budget CatchUpBudget,
+	now time.Time,
+) *CatchUpPlan {
+
+	backlog := params.end - params.start
+	numChunks := (backlog + chunkSize - 1) / chunkSize
+
+	plan := &CatchUpPlan{
+		State:     CatchUpExecuting,
+		Start:     params.start,
+		End:       params.end,
+		ChunkSize: chunkSize,
+		Spent:     new(big.Int),
+		PlannedAt: now,
+	}
+	if isPositive(budget.MaxSpend) {
+		plan.MaxSpend = budget.MaxSpend
+	}
+	if budget.MaxDuration > 0 {
+		plan.MaxDuration = budget.MaxDuration.String()
+	}
+
+	// Chunks are submitted back to back, without waiting the poll
+	// interval.
+	params.pollInterval = 0
+
+	if params.usage.NumL2Txs > 0 {
+		gasPerL2Tx := params.usage.GasUsed / params.usage.NumL2Txs
+		gasPrice, numBumps := bumpedGasPrice(
+			params, params.initialGasPrice, true,
+		)
+		plan.GasPrice = gasPrice
+
+		chunkCost := new(big.Int).Mul(
+			gasPrice, new(big.Int).SetUint64(gasPerL2Tx*chunkSize),
+		)
+		if plan.MaxSpend != nil && chunkCost.Sign() > 0 {
+			affordable := new(big.Int).Div(plan.MaxSpend, chunkCost)
+			if affordable.Cmp(new(big.Int).SetUint64(numChunks)) < 0 {
+				numChunks = affordable.Uint64()
+			}
+		}
+
+		// The duration is only known if the active strategy reaches the
+		// market gas price.
+		if gasPrice.Cmp(params.marketGasPrice) >= 0 {
+			perRound := forecastLatency(params, 1, numBumps)
+			if budget.MaxDuration > 0 && perRound > 0 {
+				maxInFlight := params.maxInFlight
+				if maxInFlight == 0 {
+					maxInFlight = 1
+				}
+				rounds := uint64(budget.MaxDuration / perRound)
+				if rounds*maxInFlight < numChunks {
+					numChunks = rounds * maxInFlight
+				}
+			}
+			plan.EstimatedDuration = forecastLatency(
+				params, numChunks, numBumps,
+			).String()
+		}
+
+		plannedBlocks := numChunks * chunkSize
+		if plannedBlocks > backlog {
+			plannedBlocks = backlog
+		}
+		plan.EstimatedCost = new(big.Int).Mul(
+			gasPrice, new(big.Int).SetUint64(gasPerL2Tx*plannedBlocks),
+		)
+	}
+
+	plan.NumChunks = numChunks
+	plan.PlannedEnd = params.start + numChunks*chunkSize
+	if plan.PlannedEnd > params.end {
+		plan.PlannedEnd = params.end
+	}
+
+	switch {
+	case numChunks == 0:
+		plan.State = CatchUpBudgetExhausted
+
+	case isPositive(budget.ApprovalThreshold) &&
+		(plan.EstimatedCost == nil ||
+			plan.EstimatedCost.Cmp(budget.ApprovalThreshold) > 0):
+
+		plan.RequiresApproval = true
+		plan.State = CatchUpAwaitingApproval
+	}
+
+	return plan
+}
+
+// catchUpTracker holds the catch-up plan of a service and tracks its progress.
+// A plan is made when a catch-up begins, and holds until the backlog is cleared
+// or an operator replans, such that a stopped plan is not replanned with a
+// fresh budget while the backlog remains.
+//
+// NOTE: catchUpTracker is safe for concurrent use.
+type catchUpTracker struct {
+	mu     sync.Mutex
+	budget CatchUpBudget
+	plan   *CatchUpPlan
+
+	// stale is set once the plan no longer holds, such that the next
+	// catch-up is planned afresh.
+	stale bool
+}
+
+// newCatchUpTracker initializes a catchUpTracker planning within budget.
+func newCatchUpTracker(budget CatchUpBudget) *catchUpTracker {
+	return &catchUpTracker{
+		budget: budget,
+		stale:  true,
+	}
+}
+
+// NeedsPlan returns true if the next catch-up must be planned.
+func (t *catchUpTracker) NeedsPlan() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.stale
+}
+
+// Begin adopts plan as the plan in effect.
+func (t *catchUpTracker) Begin(plan *CatchUpPlan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.plan = plan
+	t.stale = false
+}
+
+// Advance reports whether the chunk beginning at next may be followed by the
+// next chunk immediately, stopping the plan once its blocks are submitted or
+// its budget is exhausted.
+func (t *catchUpTracker) Advance(next uint64, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	plan := t.plan
+	if t.stale || plan == nil || plan.State != CatchUpExecuting {
+		return false
+	}
+
+	if plan.StartedAt == nil {
+		startedAt := now
+		plan.StartedAt = &startedAt
+		if t.budget.MaxDuration > 0 {
+			deadline := now.Add(t.budget.MaxDuration)
+			plan.Deadline = &deadline
+		}
+	}
+
+	switch {
+	case next >= plan.PlannedEnd:
+		plan.State = CatchUpCompleted
+	case plan.MaxSpend != nil && plan.Spent.Cmp(plan.MaxSpend) >= 0:
+		plan.State = CatchUpBudgetExhausted
+	case plan.Deadline != nil && now.After(*plan.Deadline):
+		plan.State = CatchUpBudgetExhausted
+	}
+
+	return plan.State == CatchUpExecuting
+}
+
+// Record adds cost to the spend of the plan, if it is executing.
+func (t *catchUpTracker) Record(cost *big.Int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.plan != nil && t.plan.State == CatchUpExecuting {
+		t.plan.Spent = new(big.Int).Add(t.plan.Spent, cost)
+	}
+}
+
+// Finish concludes the plan once the backlog is cleared, such that the next
+// catch-up is planned afresh.
+func (t *catchUpTracker) Finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.plan != nil && !t.stale {
+		switch t.plan.State {
+		case CatchUpAwaitingApproval, CatchUpExecuting:
+			t.plan.State = CatchUpCompleted
+		}
+	}
+	t.stale = true
+}
+
+// Act applies an operator's action to the plan, returning the resulting plan.
+func (t *catchUpTracker) Act(action CatchUpAction) (*CatchUpPlan, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	switch action {
+	case CatchUpApprove, CatchUpCancel, CatchUpReplan:
+	default:
+		return nil, ErrUnknownCatchUpAction
+	}
+
+	plan := t.plan
+	if plan == nil || t.stale {
+		return nil, ErrNoCatchUpPlan
+	}
+
+	switch action {
+	case CatchUpApprove:
+		if plan.State != CatchUpAwaitingApproval {
+			return nil, fmt.Errorf("%w: plan is %s", ErrNoCatchUpPlan,
+				plan.State)
+		}
+		plan.State = CatchUpExecuting
+
+	case CatchUpCancel:
+		if plan.State != CatchUpAwaitingApproval &&
+			plan.State != CatchUpExecuting {
+
+			return nil, fmt.Errorf("%w: plan is %s", ErrNoCatchUpPlan,
+				plan.State)
+		}
+		plan.State = CatchUpCancelled
+
+	case CatchUpReplan:
+		t.stale = true
+	}
+
+	acted := *plan
+	return &acted, nil
+}
+
+// Plan returns the plan in effect, or last concluded, or nil if none was made.
+func (t *catchUpTracker) Plan() *CatchUpPlan {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.plan == nil {
+		return nil
+	}
+	plan := *t.plan
+
+	return &plan
+}
+
+// continueCatchUp reports whether the chunk of the backlog [next, end) about to
+// be submitted may be followed by the next chunk immediately, planning the
+// catch-up within the CatchUpBudget if no plan is in effect. Without a budget,
+// a backlog is always caught up back to back.
+func (s *Service) continueCatchUp(
+	ctx context.Context,
+	trace *CycleTrace,
+	next, end uint64,
+) bool {
+
+	if s.catchUp == nil {
+		return true
+	}
+	name := s.cfg.Driver.Name()
+
+	if s.catchUp.NeedsPlan() {
+		params, err := s.forecastParams(ctx, next, end)
+		if err != nil {
+			log.Error(name+" unable to plan catch-up", "err", err)
+			trace.Step("catch_up_plan", "failed: %v", err)
+			return false
+		}
+
+		plan := planCatchUp(
+			params, s.cfg.CatchUpChunkSize, s.cfg.CatchUpBudget,
+			time.Now(),
+		)
+		s.catchUp.Begin(plan)
+
+		log.Info(name+" planned catch-up", "start", plan.Start,
+			"end", plan.End, "planned_end", plan.PlannedEnd,
+			"num_chunks", plan.NumChunks, "estimated_cost",
+			plan.EstimatedCost, "estimated_duration",
+			plan.EstimatedDuration, "state", plan.State)
+		if plan.RequiresApproval {
+			log.Warn(name+" catch-up plan awaiting approval",
+				"estimated_cost", plan.EstimatedCost)
+		}
+	}
+
+	proceed := s.catchUp.Advance(next, time.Now())
+	plan := s.catchUp.Plan()
+	trace.Step("catch_up_plan", "state=%s planned_end=%d spent=%v",
+		plan.State, plan.PlannedEnd, plan.Spent)
+
+	return proceed
+}
+
+// ActOnCatchUp applies an operator's action to the catch-up plan in effect,
+// returning the resulting plan.
+func (s *Service) ActOnCatchUp(action CatchUpAction) (*CatchUpPlan, error) {
+	if s.catchUp == nil {
+		return nil, ErrNoCatchUpPlan
+	}
+
+	plan, err := s.catchUp.Act(action)
+	if err != nil {
+		return nil, err
+	}
+	log.Info(s.cfg.Driver.Name()+" catch-up plan updated by operator",
+		"action", action, "state", plan.State)
+
+	if action == CatchUpApprove {
+		s.TriggerCycle()
+	}
+
+	return plan, nil
+}
+
+// CatchUpPlan returns the catch-up plan in effect, or last concluded, or nil if
+// none was made.
+func (s *Service) CatchUpPlan() *CatchUpPlan {
+	if s.catchUp == nil {
+		return nil
+	}
+
+	return s.catchUp.Plan()
+}
+
+// serveAdminCatchUp applies the action form value to the catch-up plan of a
+// service.
+func serveAdminCatchUp(s *Service, req *http.Request) (interface{}, error) {
+	return s.ActOnCatchUp(CatchUpAction(req.FormValue("action")))
+}
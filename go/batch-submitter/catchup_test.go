@@ -0,0 +1,221 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// catchUpTestParams returns the inputs of a plan of the backlog [100, 350),
+// whose batch txs confirm at 40 wei per gas after three bumps, using 10000 gas
+// per L2 tx.
+func catchUpTestParams() forecastParams {
+	return forecastParams{
+		start: 100,
+		end:   350,
+		usage: batchUsage{
+			NumBatches: 2,
+			NumL2Txs:   200,
+			GasUsed:    2000000,
+		},
+		marketGasPrice:  big.NewInt(35),
+		initialGasPrice: big.NewInt(10),
+		l1BlockTime:     12 * time.Second,
+		pollInterval:    time.Minute,
+		maxInFlight:     1,
+		txMgr: txmgr.Config{
+			MaxGasPrice:         big.NewInt(100),
+			GasRetryIncrement:   big.NewInt(10),
+			ResubmissionTimeout: 2 * time.Minute,
+			NumConfirmations:    2,
+		},
+	}
+}
+
+// TestPlanCatchUp asserts that a catch-up is planned in as many chunks as fit
+// within its max spend and max duration, awaiting approval if its estimated
+// cost exceeds the approval threshold.
+func TestPlanCatchUp(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	budget := CatchUpBudget{
+		MaxSpend:          big.NewInt(70000000),
+		ApprovalThreshold: big.NewInt(30000000),
+	}
+
+	// Each chunk of 50 blocks costs 20000000 wei, of which three fit
+	// within the max spend.
+	plan := planCatchUp(catchUpTestParams(), 50, budget, now)
+	require.Equal(t, CatchUpAwaitingApproval, plan.State)
+	require.True(t, plan.RequiresApproval)
+	require.Equal(t, uint64(3), plan.NumChunks)
+	require.Equal(t, uint64(250), plan.PlannedEnd)
+	require.Equal(t, uint64(350), plan.End)
+	require.Equal(t, big.NewInt(40), plan.GasPrice)
+	require.Equal(t, big.NewInt(60000000), plan.EstimatedCost)
+	require.Equal(t, "19m12s", plan.EstimatedDuration)
+
+	// Each chunk confirms 6m24s after the last, of which two fit within
+	// the max duration.
+	budget.MaxDuration = 15 * time.Minute
+	plan = planCatchUp(catchUpTestParams(), 50, budget, now)
+	require.Equal(t, uint64(2), plan.NumChunks)
+	require.Equal(t, uint64(200), plan.PlannedEnd)
+	require.Equal(t, big.NewInt(40000000), plan.EstimatedCost)
+	require.Equal(t, "12m48s", plan.EstimatedDuration)
+	require.Equal(t, "15m0s", plan.MaxDuration)
+
+	// A cheaper plan executes without approval.
+	budget.ApprovalThreshold = big.NewInt(40000000)
+	plan = planCatchUp(catchUpTestParams(), 50, budget, now)
+	require.Equal(t, CatchUpExecuting, plan.State)
+	require.False(t, plan.RequiresApproval)
+
+	// A plan whose cost is unknown covers the entire backlog, and awaits
+	// approval.
+	params := catchUpTestParams()
+	params.usage = batchUsage{}
+	plan = planCatchUp(params, 50, budget, now)
+	require.Equal(t, CatchUpAwaitingApproval, plan.State)
+	require.Equal(t, uint64(5), plan.NumChunks)
+	require.Equal(t, uint64(350), plan.PlannedEnd)
+	require.Nil(t, plan.EstimatedCost)
+
+	// A budget affording no chunk is exhausted from the start.
+	budget.MaxSpend = big.NewInt(10000000)
+	plan = planCatchUp(catchUpTestParams(), 50, budget, now)
+	require.Equal(t, CatchUpBudgetExhausted, plan.State)
+	require.Equal(t, uint64(100), plan.PlannedEnd)
+}
+
+// TestCatchUpTracker asserts that a plan executes once approved, stops once its
+// budget is exhausted, and is only replanned once the backlog is cleared.
+func TestCatchUpTracker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	budget := CatchUpBudget{
+		MaxSpend:          big.NewInt(70000000),
+		MaxDuration:       time.Hour,
+		ApprovalThreshold: big.NewInt(30000000),
+	}
+	tracker := newCatchUpTracker(budget)
+	require.True(t, tracker.NeedsPlan())
+
+	_, err := tracker.Act(CatchUpApprove)
+	require.True(t, errors.Is(err, ErrNoCatchUpPlan))
+
+	tracker.Begin(planCatchUp(catchUpTestParams(), 50, budget, now))
+	require.False(t, tracker.NeedsPlan())
+	require.False(t, tracker.Advance(100, now))
+
+	_, err = tracker.Act("skip")
+	require.True(t, errors.Is(err, ErrUnknownCatchUpAction))
+
+	plan, err := tracker.Act(CatchUpApprove)
+	require.Nil(t, err)
+	require.Equal(t, CatchUpExecuting, plan.State)
+	require.True(t, tracker.Advance(100, now))
+	require.Equal(t, now.Add(time.Hour), *tracker.Plan().Deadline)
+
+	// The spend of batches confirmed while executing is recorded, until
+	// it exhausts the max spend.
+	tracker.Record(big.NewInt(40000000))
+	require.True(t, tracker.Advance(150, now))
+	tracker.Record(big.NewInt(40000000))
+	require.False(t, tracker.Advance(200, now))
+
+	plan = tracker.Plan()
+	require.Equal(t, CatchUpBudgetExhausted, plan.State)
+	require.Equal(t, big.NewInt(80000000), plan.Spent)
+	require.False(t, tracker.NeedsPlan())
+
+	_, err = tracker.Act(CatchUpCancel)
+	require.True(t, errors.Is(err, ErrNoCatchUpPlan))
+
+	// Clearing the backlog concludes the plan, such that the next
+	// catch-up is replanned.
+	tracker.Finish()
+	require.True(t, tracker.NeedsPlan())
+	require.Equal(t, CatchUpBudgetExhausted, tracker.Plan().State)
+
+	// A plan executing past its deadline is stopped.
+	budget.ApprovalThreshold = nil
+	tracker.Begin(planCatchUp(catchUpTestParams(), 50, budget, now))
+	require.True(t, tracker.Advance(100, now))
+	require.False(t, tracker.Advance(150, now.Add(2*time.Hour)))
+	require.Equal(t, CatchUpBudgetExhausted, tracker.Plan().State)
+
+	// An operator may replan a stopped plan.
+	_, err = tracker.Act(CatchUpReplan)
+	require.Nil(t, err)
+	require.True(t, tracker.NeedsPlan())
+}
+
+// TestAdminCatchUp asserts that the admin API approves and cancels the
+// catch-up plan of the named service, refusing actions on plans that are not
+// pending.
+func TestAdminCatchUp(t *testing.T) {
+	t.Parallel()
+
+	server, services := newAdminTestServer(
+		t, namedDriver{name: "TestAdminCatchUp"},
+	)
+	s := services[0]
+
+	post := func(action CatchUpAction) (int, *CatchUpPlan) {
+		resp, err := http.Post(
+			server.URL+adminCatchUpPath,
+			"application/x-www-form-urlencoded",
+			strings.NewReader(signAdmin(t, adminCatchUpPath,
+				url.Values{
+					"service": {"TestAdminCatchUp"},
+					"action":  {string(action)},
+				},
+			).Encode()),
+		)
+		require.Nil(t, err)
+		defer resp.Body.Close()
+
+		var plan *CatchUpPlan
+		if resp.StatusCode == http.StatusOK {
+			require.Nil(t, json.NewDecoder(resp.Body).Decode(&plan))
+		}
+		return resp.StatusCode, plan
+	}
+
+	// Without a catch-up budget, there is no plan to act on.
+	code, _ := post(CatchUpApprove)
+	require.Equal(t, http.StatusConflict, code)
+
+	budget := CatchUpBudget{ApprovalThreshold: big.NewInt(1)}
+	s.catchUp = newCatchUpTracker(budget)
+	s.catchUp.Begin(
+		planCatchUp(catchUpTestParams(), 50, budget, time.Now()),
+	)
+	require.Equal(t, CatchUpAwaitingApproval, s.CatchUpPlan().State)
+
+	code, _ = post("skip")
+	require.Equal(t, http.StatusBadRequest, code)
+
+	code, plan := post(CatchUpApprove)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, CatchUpExecuting, plan.State)
+	require.Len(t, s.trigger, 1)
+
+	code, _ = post(CatchUpApprove)
+	require.Equal(t, http.StatusConflict, code)
+
+	code, plan = post(CatchUpCancel)
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, CatchUpCancelled, plan.State)
+}
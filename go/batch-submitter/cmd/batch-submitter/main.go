@@ -37,6 +37,44 @@ func main() {
 		"that synchronize L2 state to L1 contracts"
 
 	app.Action = batchsubmitter.Main(GitVersion)
+	app.Commands = []cli.Command{
+		{
+			Name: "doctor",
+			Usage: "Check the configured deployment and print a " +
+				"pass/fail report, without submitting any batches",
+			Action: batchsubmitter.Doctor,
+		},
+		{
+			Name: "replay",
+			Usage: "Replay the fee escalation of a recorded submission " +
+				"against historical base fees",
+			Flags:  flags.ReplayFlags,
+			Action: batchsubmitter.Replay,
+		},
+		{
+			Name: "inspect",
+			Usage: "Decode the sequencer batch of an L1 tx and print " +
+				"it as JSON",
+			ArgsUsage: "<txhash>",
+			Action:    batchsubmitter.Inspect,
+		},
+		{
+			Name: "bootstrap",
+			Usage: "Derive the submitted L2 height from the CTC events " +
+				"on L1 and report any blocks pending submission " +
+				"that the L2 node is unable to serve",
+			Flags:  flags.BootstrapFlags,
+			Action: batchsubmitter.Bootstrap,
+		},
+		{
+			Name: "sign-admin",
+			Usage: "Sign an admin action with an operator key and " +
+				"print the form to post to the admin API",
+			ArgsUsage: "<path> [key=value...]",
+			Flags:     flags.SignAdminFlags,
+			Action:    batchsubmitter.SignAdmin,
+		},
+	}
 	err := app.Run(os.Args)
 	if err != nil {
 		log.Crit("Application failed", "message", err)
@@ -1,13 +1,23 @@
 package batchsubmitter
 
 import (
+	"crypto/ecdsa"
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/urfave/cli"
 
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/flags"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/leader"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
 )
 
 var (
@@ -37,8 +47,190 @@ var (
 	// with which to configure Sentry logging.
 	ErrSentryDSNNotSet = errors.New("sentry-dsn must be set if use-sentry " +
 		"is true")
+
+	// ErrInvalidGasLimitMultiplier signals that the configured gas limit
+	// multiplier would reduce the node's gas estimate.
+	ErrInvalidGasLimitMultiplier = errors.New("gas-limit-multiplier must " +
+		"be at least 1.0")
+
+	// ErrInvalidPriorityLaneGasPrice signals that the priority lane was
+	// configured to begin above the max gas price.
+	ErrInvalidPriorityLaneGasPrice = errors.New("priority-lane-min-gas-" +
+		"price-in-gwei must not exceed max-gas-price-in-gwei")
+
+	// ErrInvalidDynamicFeeBump signals that dynamic fee txs were
+	// configured to be bumped by less than nodes accept as a replacement.
+	ErrInvalidDynamicFeeBump = errors.New("fee-bump-tip-cap-percent and " +
+		"fee-bump-fee-cap-percent must be zero or at least 10")
+
+	// ErrBatchEncodingIncompatible signals that sequencer batches were
+	// configured with an encoding the legacy CTC does not accept, without
+	// opting in to a CTC that does.
+	ErrBatchEncodingIncompatible = errors.New("batch-encoding other than " +
+		"v0 requires encoded-batch-ctc")
+
+	// ErrInvalidMaxTxsPerContext signals that sequencer batch contexts were
+	// configured to hold more txs than their encoding allows.
+	ErrInvalidMaxTxsPerContext = fmt.Errorf("max-txs-per-context must "+
+		"not exceed %d", sequencer.MaxContextTxs)
+
+	// ErrInvalidMaxContextsPerBatch signals that sequencer batches were
+	// configured to hold more contexts than their encoding allows.
+	ErrInvalidMaxContextsPerBatch = fmt.Errorf("max-contexts-per-batch "+
+		"must not exceed %d", sequencer.MaxBatchContexts)
+
+	// ErrUnknownGasPriceOracle signals that a service was configured with a
+	// gas price oracle type that is not supported.
+	ErrUnknownGasPriceOracle = errors.New("gas price oracle must be one " +
+		"of node, fee-history or http")
+
+	// ErrUnknownPendingTxStrategy signals that pending txs were configured
+	// to be cleared using an unsupported strategy.
+	ErrUnknownPendingTxStrategy = errors.New("pending-tx-strategy must be " +
+		"one of cancel or replace")
+
+	// ErrUnknownSelfTestMode signals that the startup self-test was
+	// configured with an unsupported mode.
+	ErrUnknownSelfTestMode = errors.New("self-test-mode must be one of " +
+		"off, strict or degraded")
+
+	// ErrInvalidCriticalEtherBalance signals that the critical balance was
+	// configured at or above the safe minimum balance, such that no
+	// warning would be raised before submission halts.
+	ErrInvalidCriticalEtherBalance = errors.New("critical-ether-balance " +
+		"must be non-negative and below safe-minimum-ether-balance")
+
+	// ErrInvalidCatchUpBudget signals that a catch-up budget was
+	// configured with a negative bound, or without chunking the backlog
+	// that it bounds.
+	ErrInvalidCatchUpBudget = errors.New("catch-up-max-ether, " +
+		"catch-up-max-duration and catch-up-approval-ether must be " +
+		"non-negative and require catch-up-chunk-size")
+
+	// ErrInvalidBalanceDrainFactor signals that balance drain alerts were
+	// configured with a factor that would alert at the baseline rate.
+	ErrInvalidBalanceDrainFactor = errors.New("balance-drain-factor " +
+		"must be greater than one")
+
+	// ErrInvalidWalletRotation signals that wallet rotation was configured
+	// with a negative balance threshold or wedge duration.
+	ErrInvalidWalletRotation = errors.New("wallet-rotation-ether-balance " +
+		"and wallet-wedged-after must be non-negative")
+
+	// ErrInvalidRotationKey signals that a rotation private key could not
+	// be parsed.
+	ErrInvalidRotationKey = errors.New("sequencer-rotation-private-keys " +
+		"and proposer-rotation-private-keys must be comma-separated " +
+		"private keys")
+
+	// ErrSharedRotationKey signals that a wallet was configured more than
+	// once across the sequencer and proposer, which would lead to the two
+	// sharing its nonces.
+	ErrSharedRotationKey = errors.New("sequencer and proposer wallets, " +
+		"including rotation wallets, must be distinct")
+
+	// ErrInvalidRPCRetryBackoff signals that RPC retries were configured
+	// with an initial backoff exceeding the max backoff.
+	ErrInvalidRPCRetryBackoff = errors.New("rpc-retry-initial-backoff " +
+		"must not exceed rpc-retry-max-backoff")
+
+	// ErrPipelineRequiresMaxGasLimit signals that pipelined batches were
+	// configured without a gas limit to submit them at. Batches building
+	// on unconfirmed batches cannot be gas estimated.
+	ErrPipelineRequiresMaxGasLimit = errors.New("max-gas-limit must be " +
+		"set when max-in-flight-batches is greater than one")
+
+	// ErrGasPriceOracleURLNotSet signals that the http gas price oracle was
+	// selected without providing a URL to query.
+	ErrGasPriceOracleURLNotSet = errors.New("gas-price-oracle-url must be " +
+		"set when using the http gas price oracle")
+
+	// ErrUnknownMetricsExporter signals that metrics were configured to be
+	// exported to an unsupported backend.
+	ErrUnknownMetricsExporter = errors.New("metrics-exporters must be " +
+		"a list of prometheus, statsd, otlp or pushgateway")
+
+	// ErrStatsDAddressNotSet signals that the statsd metrics exporter was
+	// selected without providing an agent to push to.
+	ErrStatsDAddressNotSet = errors.New("statsd-address must be set " +
+		"when using the statsd metrics exporter")
+
+	// ErrOTLPEndpointNotSet signals that the otlp metrics exporter was
+	// selected without providing a collector to push to.
+	ErrOTLPEndpointNotSet = errors.New("otlp-endpoint must be set " +
+		"when using the otlp metrics exporter")
+
+	// ErrPushgatewayURLNotSet signals that the pushgateway metrics exporter
+	// was selected without providing a Pushgateway to push to.
+	ErrPushgatewayURLNotSet = errors.New("pushgateway-url must be set " +
+		"when using the pushgateway metrics exporter")
+
+	// ErrInvalidMetricsNamespace signals a metrics namespace or service
+	// label that is not a valid Prometheus name.
+	ErrInvalidMetricsNamespace = errors.New("metrics-namespace and " +
+		"metrics-service-label must be valid prometheus names")
+
+	// ErrInvalidMetricsLabels signals constant metrics labels that are not
+	// a comma-separated list of name=value pairs with valid names.
+	ErrInvalidMetricsLabels = errors.New("metrics-labels must be a " +
+		"comma-separated list of name=value pairs")
+
+	// ErrInvalidRPCQuota signals that an RPC quota was configured with a
+	// negative rate.
+	ErrInvalidRPCQuota = errors.New("sequencer-rpc-quota and " +
+		"proposer-rpc-quota must be non-negative")
+
+	// ErrInvalidSpendBudget signals that a spend budget was configured with
+	// a negative amount.
+	ErrInvalidSpendBudget = errors.New("spend-budget-ether must be " +
+		"non-negative")
+
+	// ErrInvalidHALeaseDuration signals that high availability mode was
+	// configured with a negative lease duration.
+	ErrInvalidHALeaseDuration = errors.New("ha-lease-duration must be " +
+		"non-negative")
+
+	// ErrTxRelayURLNotSet signals that private tx submission was selected
+	// without providing a relay to submit to.
+	ErrTxRelayURLNotSet = errors.New("tx-relay-url must be set when " +
+		"using the private or bundle tx submission modes")
+
+	// ErrDebugPortConflict signals that the debug server was configured to
+	// listen on the same port as the metrics server.
+	ErrDebugPortConflict = errors.New("debug-port must differ from " +
+		"metrics-port when both servers are enabled")
+
+	// ErrAdminPortConflict signals that the admin server was configured to
+	// listen on the same port as the metrics or debug server.
+	ErrAdminPortConflict = errors.New("admin-port must differ from " +
+		"metrics-port and debug-port when the servers are enabled")
+
+	// ErrMaxL1TxSizeTooLarge signals a max L1 tx size above the size of the
+	// largest tx accepted by the L1 tx pool.
+	ErrMaxL1TxSizeTooLarge = errors.New("max-l1-tx-size exceeds the L1 " +
+		"tx pool's max tx size")
+
+	// ErrInvalidSubmissionStateURL signals a submission state URL that does
+	// not select a supported backend.
+	ErrInvalidSubmissionStateURL = errors.New("submission-state-url must " +
+		"be a path or a file, bolt, sqlite, postgres or s3 URL")
+
+	// ErrInvalidArchiveURL signals an archive URL that does not select a
+	// supported backend.
+	ErrInvalidArchiveURL = errors.New("archive-url must be a path or a " +
+		"file, bolt, sqlite, postgres or s3 URL")
+
+	// ErrInvalidArchiveKey signals that archiving was enabled without a
+	// valid archive key.
+	ErrInvalidArchiveKey = errors.New("archive-key must be a hex-encoded " +
+		"32 byte key when archive-url is set")
 )
 
+// MaxL1TxPoolTxSize is the size in bytes of the largest tx accepted by the L1
+// tx pool, above which batch txs are rejected before ever reaching the CTC or
+// SCC.
+const MaxL1TxPoolTxSize = 4 * 32 * 1024
+
 type Config struct {
 	/* Required Params */
 
@@ -49,12 +241,26 @@ type Config struct {
 	// EthNetworkName identifies the intended Ethereum network.
 	EthNetworkName string
 
-	// L1EthRpc is the HTTP provider URL for L1.
+	// L1EthRpc is the HTTP or WebSocket provider URL for L1, or a
+	// comma-separated list of HTTP provider URLs in order of priority to
+	// fail over between. Over WebSocket, confirmations are tracked by
+	// subscribing to new heads rather than polling.
 	L1EthRpc string
 
-	// L2EthRpc is the HTTP provider URL for L1.
+	// L2EthRpc is the HTTP provider URL for L2, or a comma-separated list
+	// of HTTP provider URLs in order of priority to fail over between.
 	L2EthRpc string
 
+	// SecondaryL2EthRpc, if set, is the HTTP provider URL for L2 from which
+	// blocks are refetched when those returned by L2EthRpc do not form a
+	// single chain.
+	SecondaryL2EthRpc string
+
+	// L2RPCFlavor is the flavor of JSON-RPC served by the L2 providers,
+	// either l2geth, op-geth or auto to detect it at startup, defaulting
+	// to l2geth.
+	L2RPCFlavor string
+
 	// CTCAddress is the CTC contract address.
 	CTCAddress string
 
@@ -77,8 +283,20 @@ type Config struct {
 	// be in a batch.
 	MaxStateBatchCount uint64
 
+	// MinL2TxCount is the minimum number of L2 transactions a pending range
+	// must hold before a sequencer batch is submitted, unless the range is
+	// older than MaxBatchSubmissionTime. If zero, it is not enforced.
+	MinL2TxCount uint64
+
+	// MinBatchBytes is the minimum size in bytes of the sequencer
+	// transactions of a pending range before a sequencer batch is
+	// submitted, unless the range is older than MaxBatchSubmissionTime. If
+	// zero, it is not enforced.
+	MinBatchBytes uint64
+
 	// MaxBatchSubmissionTime is the maximum amount of time that we will
-	// wait before submitting an under-sized batch.
+	// wait before submitting an under-sized batch. If zero,
+	// sequencer.DefaultMaxBatchSubmissionTime is used.
 	MaxBatchSubmissionTime time.Duration
 
 	// PollInterval is the delay between querying L2 for more transaction
@@ -105,8 +323,9 @@ type Config struct {
 	// submitter.
 	RunStateBatchSubmitter bool
 
-	//SafeMinimumEtherBalance is the safe minimum amount of ether the batch
-	//submitter key should hold before it starts to log errors.
+	// SafeMinimumEtherBalance is the safe minimum amount of ether the batch
+	// submitter key should hold before it starts to log errors and fails
+	// the readiness probe.
 	SafeMinimumEtherBalance uint64
 
 	// ClearPendingTxs is a boolean to clear the pending transactions in the
@@ -118,6 +337,15 @@ type Config struct {
 	// LogLevel is the lowest log level that will be output.
 	LogLevel string
 
+	// LogFormat is the format in which logs are written, one of terminal
+	// or json. Defaults to terminal.
+	LogFormat string
+
+	// LogModules is a comma-separated list of pattern=level, logging the
+	// packages or files matched by each pattern down to its level, e.g.
+	// txmgr=debug.
+	LogModules string
+
 	// SentryEnable if true, logs any error messages to sentry. SentryDsn
 	// must also be set if SentryEnable is true.
 	SentryEnable bool
@@ -133,14 +361,328 @@ type Config struct {
 	// blocks.
 	BlockOffset uint64
 
+	// L1ChainID is the expected chain ID of the L1 settlement chain. If
+	// non-zero, startup fails if the L1 provider reports a different one.
+	L1ChainID uint64
+
+	// AddressLabels is a comma-separated list of address=label pairs used
+	// to identify addresses by name in logs and the status API. The CTC,
+	// SCC and submitter wallets are labeled by default.
+	AddressLabels string
+
+	// BlockCacheSize is the maximum number of L2 blocks the sequencer
+	// driver will cache between submission cycles.
+	BlockCacheSize uint64
+
+	// NumFetchWorkers is the maximum number of L2 blocks the sequencer
+	// driver will fetch concurrently while building a batch.
+	NumFetchWorkers uint64
+
+	// FetchTargetLatency is the average L2 request latency above which the
+	// block fetch concurrency is reduced. If zero, the concurrency is fixed
+	// to NumFetchWorkers, otherwise it is tuned up to NumFetchWorkers.
+	FetchTargetLatency time.Duration
+
+	// GasLimitMultiplier scales the node's gas estimate for each batch tx
+	// to provide a safety margin.
+	GasLimitMultiplier float64
+
+	// GasLimitBuffer is a fixed amount of gas added to each scaled gas
+	// estimate.
+	GasLimitBuffer uint64
+
+	// MaxGasLimit is a hard cap on the gas limit of any batch tx. A value of
+	// zero disables the cap.
+	MaxGasLimit uint64
+
+	// MaxContextDrift is the maximum age a batch context's timestamp may
+	// have relative to the L1 timestamp at inclusion. A value of zero
+	// disables the check.
+	MaxContextDrift time.Duration
+
+	// ExpectedInclusionDelay is the expected time a batch tx waits in the
+	// mempool, used when validating MaxContextDrift.
+	ExpectedInclusionDelay time.Duration
+
 	// MaxGasPriceInGwei is the maximum gas price in gwei we will allow in order
 	// to confirm a transaction.
 	MaxGasPriceInGwei uint64
 
+	// MaxFeePerL2TxInGwei is the maximum fee in gwei worth paying per L2
+	// transaction in a sequencer batch. Batches whose fee would exceed this
+	// ceiling are deferred until a larger batch can be built. If zero, no
+	// ceiling applies.
+	MaxFeePerL2TxInGwei uint64
+
+	// SpendBudgetEther, if non-zero, is the max amount of ether spent on
+	// the batch txs confirmed within a trailing day, after which
+	// submission is skipped until earlier spend leaves the window.
+	SpendBudgetEther float64
+
+	// PriorityLaneAge, if non-zero, is the age of the oldest L1 queue
+	// element yet to be appended to the CTC at which the sequencer submits
+	// the pending range immediately, regardless of its size or the L1 gas
+	// price. It should be set below the force-inclusion period.
+	PriorityLaneAge time.Duration
+
+	// PriorityLaneMinGasPriceInGwei, if non-zero, is the gas price in gwei
+	// at which batch txs submitted by the priority lane are first
+	// published.
+	PriorityLaneMinGasPriceInGwei uint64
+
+	// BatchEncoding is the version of the encoding used for the contexts
+	// of sequencer batches, either v0, v1 or v2. Only v0 is accepted by
+	// the legacy CTC, such that other versions require EncodedBatchCTC.
+	BatchEncoding string
+
+	// EncodedBatchCTC, if true, asserts that the CTC accepts sequencer
+	// batches encoded with versions other than v0.
+	EncodedBatchCTC bool
+
+	// BatchBoundary determines where a sequencer batch cut short of its
+	// block range may end, either none, context or epoch, the latter two
+	// keeping each context or L1 epoch whole within a single batch.
+	BatchBoundary string
+
+	// MaxTxsPerContext is the maximum number of txs of a single context of
+	// a sequencer batch. Runs of sequencer txs sharing a context are split
+	// across as many contexts as needed. If zero, the maximum allowed by
+	// the encoding is used.
+	MaxTxsPerContext uint64
+
+	// MaxContextsPerBatch is the maximum number of contexts of a sequencer
+	// batch, beyond which the batch is cut short. If zero, the maximum
+	// allowed by the encoding is used.
+	MaxContextsPerBatch uint64
+
+	// SystemTxPolicy determines how sequencer txs identified as system txs
+	// are handled, either include, exclude or error. Excluded system txs
+	// are still batched, but do not count towards the minimum batch size
+	// or fee ceiling. Defaults to include.
+	SystemTxPolicy string
+
+	// SystemTxSenders is a comma-separated list of the L2 accounts whose
+	// txs are system txs.
+	SystemTxSenders string
+
+	// SystemTxZeroGasPrice, if true, identifies every L2 tx with a zero gas
+	// price as a system tx.
+	SystemTxZeroGasPrice bool
+
+	// MalformedBlockPolicy determines how L2 blocks that cannot be batched,
+	// e.g. as they hold an unexpected tx type, are handled, either error
+	// or quarantine. Quarantined blocks are recorded in the state store,
+	// if any, for manual review. Defaults to error.
+	MalformedBlockPolicy string
+
+	// MalformedBlockBudget is the number of consecutive batches that may
+	// encounter a quarantined block before the sequencer halts. If zero,
+	// the sequencer never halts.
+	MalformedBlockBudget uint64
+
+	// DeferAboveMaxGasPrice, if true, skips submission while the L1 gas
+	// price exceeds MaxGasPriceInGwei, resuming once it falls.
+	DeferAboveMaxGasPrice bool
+
+	// TxSubmissionMode determines how batch txs are published, either
+	// public to broadcast them to the L1 mempool, or private or bundle to
+	// submit them to TxRelayURL via eth_sendPrivateTransaction or as
+	// Flashbots bundles respectively.
+	TxSubmissionMode string
+
+	// TxRelayURL is the JSON-RPC endpoint of the relay used in the private
+	// and bundle submission modes.
+	TxRelayURL string
+
+	// TxRelaySigningKey is the private key used to sign requests to the
+	// relay, identifying the submitter. It should not hold any funds, and
+	// is required in the bundle submission mode.
+	TxRelaySigningKey string
+
+	// PrivateTxMaxBlocks is the number of blocks within which the relay may
+	// include a private tx before it must be resubmitted.
+	PrivateTxMaxBlocks uint64
+
+	// PendingTxStrategy determines how pending txs are replaced when
+	// ClearPendingTxs is set, either cancel or replace.
+	PendingTxStrategy string
+
+	// SelfTestMode determines how the startup self-test is run, either
+	// off, strict or degraded.
+	SelfTestMode string
+
+	// FillNonceGaps, if true, repairs gaps left by txs dropped from the L1
+	// tx pool by publishing zero-value self-transactions at the missing
+	// nonces. Otherwise, the missing nonces are reused for new batches.
+	FillNonceGaps bool
+
+	// MaxInFlightBatches is the maximum number of sequencer batch txs at
+	// consecutive nonces that may await confirmation at once. Values of
+	// zero and one submit each batch only once the previous batch has
+	// confirmed.
+	MaxInFlightBatches uint64
+
+	// CatchUpChunkSize, if non-zero, is the max number of L2 blocks
+	// covered by each cycle. A larger backlog is submitted in chunks of
+	// this size, back to back without waiting the poll interval, rather
+	// than built in a single pass.
+	CatchUpChunkSize uint64
+
+	// CatchUpMaxEther, if non-zero, is the max amount of ether spent on
+	// the chunks of a backlog submitted back to back, after which the
+	// remaining chunks are submitted a poll interval apart.
+	CatchUpMaxEther float64
+
+	// CatchUpMaxDuration, if non-zero, is the max duration for which the
+	// chunks of a backlog are submitted back to back.
+	CatchUpMaxDuration time.Duration
+
+	// CatchUpApprovalEther, if non-zero, is the estimated cost in ether
+	// above which a catch-up plan awaits approval via the admin API before
+	// its chunks are submitted back to back.
+	CatchUpApprovalEther float64
+
+	// MaxSubmissionAttempts is the number of consecutive failed submissions
+	// of an L2 range after which the range is quarantined until released
+	// via the admin API. If zero, ranges are retried indefinitely.
+	MaxSubmissionAttempts uint64
+
+	// HealthMaxCycleAge is the duration after which a service that has not
+	// completed a cycle fails the liveness probe. If zero, liveness only
+	// requires the metrics server to respond.
+	HealthMaxCycleAge time.Duration
+
+	// HealthMaxConfirmationAge is the duration after which a service that
+	// has not confirmed a batch tx fails the readiness probe. If zero, the
+	// age of the last confirmation is not checked.
+	HealthMaxConfirmationAge time.Duration
+
+	// RPCHealthCheckInterval is the interval at which each of multiple
+	// provider URLs is health checked, allowing failed providers to
+	// recover.
+	RPCHealthCheckInterval time.Duration
+
+	// RPCRequestTimeout, if non-zero, is the duration after which a
+	// request to one of multiple provider URLs fails over to the next.
+	RPCRequestTimeout time.Duration
+
+	// RPCLoadBalanceReads, if true, spreads requests that do not publish
+	// txs across every healthy provider URL.
+	RPCLoadBalanceReads bool
+
+	// RPCMaxRetries is the number of times a failed balance, nonce or
+	// block range query is retried within a cycle before the cycle is
+	// skipped.
+	RPCMaxRetries uint64
+
+	// RPCRetryInitialBackoff is the delay before the first retry of a
+	// failed query, doubling with each retry up to RPCRetryMaxBackoff.
+	RPCRetryInitialBackoff time.Duration
+
+	// RPCRetryMaxBackoff caps the delay between retries of a failed query.
+	RPCRetryMaxBackoff time.Duration
+
+	// RPCCallTimeout, if non-zero, bounds each RPC call made by a cycle,
+	// such that a hung call fails rather than stalling the cycle.
+	RPCCallTimeout time.Duration
+
+	// MaxCycleDuration, if non-zero, bounds the queries and batch building
+	// of each cycle, after which the cycle is aborted.
+	MaxCycleDuration time.Duration
+
+	// RPCCircuitBreakerThreshold, if non-zero, is the number of
+	// consecutive queries failing after exhausting their retries at which
+	// a service reports not ready, until a query succeeds.
+	RPCCircuitBreakerThreshold uint64
+
+	// StartupGracePeriod is the duration after startup during which the
+	// services only observe chain state and pending txs before their first
+	// submission.
+	StartupGracePeriod time.Duration
+
+	// ShutdownGracePeriod is the duration for which the services drain on
+	// shutdown, awaiting the batch txs in flight without starting new
+	// cycles, before they are abandoned. If zero, the services stop
+	// immediately.
+	ShutdownGracePeriod time.Duration
+
+	// CriticalEtherBalance is the amount of ether below which the batch
+	// submitter key halts submission until refunded. If zero, submission
+	// never halts on a low balance.
+	CriticalEtherBalance float64
+
+	// BalanceDrainWindow, if non-zero, is the trailing window over which
+	// the drain rate of the batch submitter key is measured, alerting when
+	// it exceeds BalanceDrainFactor times its baseline.
+	BalanceDrainWindow time.Duration
+
+	// BalanceDrainFactor is the multiple of the baseline drain rate above
+	// which an alert is raised.
+	BalanceDrainFactor float64
+
+	// MinBalanceDrainRate is the drain rate, in ETH per hour, below which
+	// no drain alert is raised, no matter the baseline.
+	MinBalanceDrainRate float64
+
+	// AlertWebhookURL, if set, is a URL to which balance alerts are posted
+	// as JSON.
+	AlertWebhookURL string
+
+	// AlertSlackWebhookURL, if set, is a Slack incoming webhook to which
+	// balance alerts are posted.
+	AlertSlackWebhookURL string
+
+	// AlertPagerDutyRoutingKey, if set, is the routing key of the
+	// PagerDuty integration on which balance incidents are raised.
+	AlertPagerDutyRoutingKey string
+
+	// SequencerGasPriceOracle selects the source of the initial gas price
+	// of sequencer txs, one of node, fee-history or http. If empty, the
+	// initial gas price is the minimum gas price.
+	SequencerGasPriceOracle string
+
+	// ProposerGasPriceOracle selects the source of the initial gas price of
+	// proposer txs. It accepts the same values as SequencerGasPriceOracle.
+	ProposerGasPriceOracle string
+
+	// GasPriceOracleURL is the endpoint queried by the http gas price
+	// oracle.
+	GasPriceOracleURL string
+
+	// GasPriceOracleField is the field of the http gas price oracle's JSON
+	// response holding the gas price in gwei.
+	GasPriceOracleField string
+
+	// FeeHistoryBlockCount is the number of recent blocks sampled by the
+	// fee-history gas price oracle.
+	FeeHistoryBlockCount uint64
+
+	// FeeHistoryPercentile is the priority fee percentile sampled from each
+	// block by the fee-history gas price oracle.
+	FeeHistoryPercentile float64
+
 	// GasRetryIncrement is the step size (in gwei) by which we will ratchet the
 	// gas price in order to get a transaction confirmed.
 	GasRetryIncrement uint64
 
+	// FeeBumpPercent is the percentage by which the gas price of a batch tx
+	// is bumped on each replacement, before adding GasRetryIncrement.
+	FeeBumpPercent uint64
+
+	// FeeBumpTipCapPercent and FeeBumpFeeCapPercent are the percentages by
+	// which the tip cap and fee cap of a dynamic fee tx are bumped on each
+	// replacement. If zero, the minimum of 10% accepted by nodes is used.
+	FeeBumpTipCapPercent uint64
+	FeeBumpFeeCapPercent uint64
+
+	// MaxFeeBumps, if non-zero, is the max number of replacements of a
+	// batch tx, after which it is abandoned.
+	MaxFeeBumps uint64
+
+	// TxAbortAfter, if non-zero, is the duration after which a batch tx
+	// that has not been mined is abandoned, regardless of its fees.
+	TxAbortAfter time.Duration
+
 	// SequencerPrivateKey the private key of the wallet used to submit
 	// transactions to the CTC contract.
 	SequencerPrivateKey string
@@ -149,6 +691,28 @@ type Config struct {
 	// transaction to the SCC contract.
 	ProposerPrivateKey string
 
+	// SequencerRotationPrivateKeys is a comma-separated list of the private
+	// keys of the wallets to which submission to the CTC contract rotates,
+	// once its AddressManager resolves one of them as the sequencer.
+	SequencerRotationPrivateKeys string
+
+	// ProposerRotationPrivateKeys is a comma-separated list of the private
+	// keys of the wallets to which submission to the SCC contract rotates.
+	ProposerRotationPrivateKeys string
+
+	// WalletRotationEtherBalance is the amount of ether below which the
+	// active wallet is alerted on as needing the authorized sender updated
+	// away from it, if rotation wallets are configured, and which a rotation
+	// wallet must hold to take over submission. If zero, the balance is not
+	// checked.
+	WalletRotationEtherBalance float64
+
+	// WalletWedgedAfter, if non-zero, is the duration for which txs may
+	// remain pending behind an unchanged nonce of the active wallet before
+	// it is alerted on as needing the authorized sender updated away from
+	// it.
+	WalletWedgedAfter time.Duration
+
 	// Mnemonic is the HD seed used to derive the wallet private keys for both
 	// the sequence and proposer. Must be used in conjunction with
 	// SequencerHDPath and ProposerHDPath.
@@ -171,20 +735,204 @@ type Config struct {
 
 	// MetricsPort is the port at which the metrics server is running.
 	MetricsPort uint64
+
+	// MetricsExporters is a comma-separated list of the backends to which
+	// metrics are exported: prometheus, statsd, otlp and/or pushgateway.
+	MetricsExporters string
+
+	// MetricsNamespace, if set, prefixes the name of every exported metric,
+	// e.g. to distinguish the submitter's metrics from those of other
+	// services sharing a Prometheus.
+	MetricsNamespace string
+
+	// MetricsLabels is a comma-separated list of name=value pairs attached
+	// to every exported series, e.g. network=mainnet,chain_id=10, such that
+	// dashboards can span several networks.
+	MetricsLabels string
+
+	// MetricsServiceLabel, if set, is the label identifying the service of
+	// each metric, e.g. "service", in place of prefixing each metric's
+	// name with the service's name.
+	MetricsServiceLabel string
+
+	// StatsDAddress is the address of the StatsD or Datadog agent to which
+	// metrics are pushed by the statsd exporter.
+	StatsDAddress string
+
+	// OTLPEndpoint is the OTLP/HTTP metrics endpoint of the OpenTelemetry
+	// collector to which metrics are pushed by the otlp exporter.
+	OTLPEndpoint string
+
+	// PushgatewayURL is the URL of the Prometheus Pushgateway to which
+	// metrics are pushed by the pushgateway exporter.
+	PushgatewayURL string
+
+	// PushgatewayJob is the job under which metrics are pushed to the
+	// Pushgateway, grouped further by the MetricsLabels.
+	PushgatewayJob string
+
+	// MetricsExportInterval is the interval at which metrics are pushed by
+	// the statsd, otlp and pushgateway exporters.
+	MetricsExportInterval time.Duration
+
+	// DebugServerEnable, if true, runs a debug server serving pprof
+	// profiles, goroutine dumps and a snapshot of internal state.
+	DebugServerEnable bool
+
+	// DebugHostname is the hostname at which the debug server is running.
+	DebugHostname string
+
+	// DebugPort is the port at which the debug server is running.
+	DebugPort uint64
+
+	// AdminServerEnable, if true, runs the admin API server.
+	AdminServerEnable bool
+
+	// AdminHostname is the hostname at which the admin API server is
+	// running.
+	AdminHostname string
+
+	// AdminPort is the port at which the admin API server is running.
+	AdminPort uint64
+
+	// AdminOperators is a comma-separated list of the addresses whose
+	// signatures authorize mutations through the admin API. If empty,
+	// every mutation is rejected.
+	AdminOperators string
+
+	// AdminAuditLog is the file to which every admin mutation is appended
+	// as a JSON line. If empty, no audit trail is kept.
+	AdminAuditLog string
+
+	// DryRun, if true, builds, simulates and signs batch txs without
+	// publishing them.
+	DryRun bool
+
+	// SubmissionQueueDir is the directory in which built batches are
+	// persisted until confirmed. If empty, batches are rebuilt on every
+	// attempt and not persisted.
+	SubmissionQueueDir string
+
+	// SubmissionQueueStaleLockTimeout is the duration after which the lock
+	// over a SubmissionQueueDir that is no longer refreshed is broken.
+	SubmissionQueueStaleLockTimeout time.Duration
+
+	// SubmissionStateDir is the directory in which the published batch
+	// txs are recorded, such that a restarted submitter resumes tracking
+	// any left pending. If empty, no submission state is persisted.
+	SubmissionStateDir string
+
+	// SubmissionStateURL selects the backend in which the published batch
+	// txs are recorded, as accepted by queue.OpenBackend, e.g. a bolt or
+	// SQLite file for single-node deployments or a Postgres database
+	// shared by the replicas of a high availability deployment. If set, it
+	// takes precedence over SubmissionStateDir.
+	SubmissionStateURL string
+
+	// HALockURL is the URL of the Redis server through which replicas
+	// sharing the same wallets elect a leader in high availability mode,
+	// such that only the leader submits. If empty, high availability mode
+	// is disabled.
+	HALockURL string
+
+	// HALockKey is the key under which the leader lock is stored, suffixed
+	// with the tenant name in multi-tenant mode.
+	HALockKey string
+
+	// HAReplicaID identifies this replica to the others, and defaults to
+	// the hostname and PID of the process.
+	HAReplicaID string
+
+	// HALeaseDuration is the duration for which leadership is leased, and
+	// the longest a standby waits to take over from a failed leader.
+	HALeaseDuration time.Duration
+
+	// InclusionProofDir is the directory in which an inclusion proof of
+	// each confirmed state batch is stored for downstream verifiers. If
+	// empty, no proofs are generated.
+	InclusionProofDir string
+
+	// ArchiveURL selects the backend in which the payload of each
+	// confirmed sequencer batch is archived, sealed under ArchiveKey, as
+	// accepted by queue.OpenBackend. If empty, batches are not archived.
+	ArchiveURL string
+
+	// ArchiveKey is the hex-encoded key with which archived batch
+	// payloads are sealed. It is required if ArchiveURL is set.
+	ArchiveKey string
+
+	// ArchiveKeyID identifies ArchiveKey in the manifest of each archived
+	// batch, such that payloads can be opened after a key rotation.
+	ArchiveKeyID string
+
+	// VerifyBatches, if true, re-derives each confirmed sequencer batch
+	// from its L1 calldata and compares it against the L2 blocks it
+	// covers, raising a critical alert on any mismatch.
+	VerifyBatches bool
+
+	// SequencerMemoryQuota and ProposerMemoryQuota bound the bytes of L2
+	// data each driver holds across the batches it builds at once. A
+	// batch is submitted early once its driver's quota is exhausted. If
+	// zero, memory is unbounded.
+	SequencerMemoryQuota uint64
+	ProposerMemoryQuota  uint64
+
+	// SequencerRPCQuota and ProposerRPCQuota bound the rate of L2 requests
+	// made by each driver, in requests per second. If zero, requests are
+	// unbounded.
+	SequencerRPCQuota float64
+	ProposerRPCQuota  float64
+
+	// SequencerRPCBurst and ProposerRPCBurst are the number of L2 requests
+	// each driver may make at once before being held to its RPC quota. If
+	// zero, bursts of one second's worth of requests are admitted.
+	SequencerRPCBurst uint64
+	ProposerRPCBurst  uint64
+
+	// TenantsFile is the path to a JSON file defining multiple rollup
+	// tenants. If set, one isolated batch submitter is run per tenant.
+	TenantsFile string
+
+	// TenantName identifies the tenant this configuration was derived for,
+	// and is used to namespace logs and metrics. This is populated from
+	// the TenantsFile and is empty in single-tenant mode.
+	TenantName string
+
+	// TenantAdminOperators is a comma-separated list of the addresses
+	// whose signatures authorize admin API mutations of the services of
+	// TenantName only. This is populated from the TenantsFile.
+	TenantAdminOperators string
 }
 
 // NewConfig parses the Config from the provided flags or environment variables.
 // This method fails if ValidateConfig deems the configuration to be malformed.
 func NewConfig(ctx *cli.Context) (Config, error) {
-	cfg := Config{
+	cfg := configFromContext(ctx)
+
+	err := ValidateConfig(&cfg)
+	if err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// configFromContext reads the Config from the provided flags or environment
+// variables, without validating it.
+func configFromContext(ctx *cli.Context) Config {
+	return Config{
 		/* Required Flags */
 		BuildEnv:                ctx.GlobalString(flags.BuildEnvFlag.Name),
 		EthNetworkName:          ctx.GlobalString(flags.EthNetworkNameFlag.Name),
 		L1EthRpc:                ctx.GlobalString(flags.L1EthRpcFlag.Name),
 		L2EthRpc:                ctx.GlobalString(flags.L2EthRpcFlag.Name),
+		SecondaryL2EthRpc:       ctx.GlobalString(flags.SecondaryL2EthRpcFlag.Name),
+		L2RPCFlavor:             ctx.GlobalString(flags.L2RPCFlavorFlag.Name),
 		CTCAddress:              ctx.GlobalString(flags.CTCAddressFlag.Name),
 		SCCAddress:              ctx.GlobalString(flags.SCCAddressFlag.Name),
 		MaxL1TxSize:             ctx.GlobalUint64(flags.MaxL1TxSizeFlag.Name),
+		MinL2TxCount:            ctx.GlobalUint64(flags.MinL2TxCountFlag.Name),
+		MinBatchBytes:           ctx.GlobalUint64(flags.MinBatchBytesFlag.Name),
 		MaxBatchSubmissionTime:  ctx.GlobalDuration(flags.MaxBatchSubmissionTimeFlag.Name),
 		PollInterval:            ctx.GlobalDuration(flags.PollIntervalFlag.Name),
 		NumConfirmations:        ctx.GlobalUint64(flags.NumConfirmationsFlag.Name),
@@ -195,28 +943,135 @@ func NewConfig(ctx *cli.Context) (Config, error) {
 		SafeMinimumEtherBalance: ctx.GlobalUint64(flags.SafeMinimumEtherBalanceFlag.Name),
 		ClearPendingTxs:         ctx.GlobalBool(flags.ClearPendingTxsFlag.Name),
 		/* Optional Flags */
-		SentryEnable:        ctx.GlobalBool(flags.SentryEnableFlag.Name),
-		SentryDsn:           ctx.GlobalString(flags.SentryDsnFlag.Name),
-		SentryTraceRate:     ctx.GlobalDuration(flags.SentryTraceRateFlag.Name),
-		BlockOffset:         ctx.GlobalUint64(flags.BlockOffsetFlag.Name),
-		MaxGasPriceInGwei:   ctx.GlobalUint64(flags.MaxGasPriceInGweiFlag.Name),
-		GasRetryIncrement:   ctx.GlobalUint64(flags.GasRetryIncrementFlag.Name),
-		SequencerPrivateKey: ctx.GlobalString(flags.SequencerPrivateKeyFlag.Name),
-		ProposerPrivateKey:  ctx.GlobalString(flags.ProposerPrivateKeyFlag.Name),
-		Mnemonic:            ctx.GlobalString(flags.MnemonicFlag.Name),
-		SequencerHDPath:     ctx.GlobalString(flags.SequencerHDPathFlag.Name),
-		ProposerHDPath:      ctx.GlobalString(flags.ProposerHDPathFlag.Name),
-		MetricsServerEnable: ctx.GlobalBool(flags.MetricsServerEnableFlag.Name),
-		MetricsHostname:     ctx.GlobalString(flags.MetricsHostnameFlag.Name),
-		MetricsPort:         ctx.GlobalUint64(flags.MetricsPortFlag.Name),
-	}
-
-	err := ValidateConfig(&cfg)
-	if err != nil {
-		return Config{}, err
+		LogLevel:                        ctx.GlobalString(flags.LogLevelFlag.Name),
+		LogFormat:                       ctx.GlobalString(flags.LogFormatFlag.Name),
+		LogModules:                      ctx.GlobalString(flags.LogModulesFlag.Name),
+		SentryEnable:                    ctx.GlobalBool(flags.SentryEnableFlag.Name),
+		SentryDsn:                       ctx.GlobalString(flags.SentryDsnFlag.Name),
+		SentryTraceRate:                 ctx.GlobalDuration(flags.SentryTraceRateFlag.Name),
+		BlockOffset:                     ctx.GlobalUint64(flags.BlockOffsetFlag.Name),
+		L1ChainID:                       ctx.GlobalUint64(flags.L1ChainIDFlag.Name),
+		AddressLabels:                   ctx.GlobalString(flags.AddressLabelsFlag.Name),
+		BlockCacheSize:                  ctx.GlobalUint64(flags.BlockCacheSizeFlag.Name),
+		NumFetchWorkers:                 ctx.GlobalUint64(flags.NumFetchWorkersFlag.Name),
+		FetchTargetLatency:              ctx.GlobalDuration(flags.FetchTargetLatencyFlag.Name),
+		GasLimitMultiplier:              ctx.GlobalFloat64(flags.GasLimitMultiplierFlag.Name),
+		GasLimitBuffer:                  ctx.GlobalUint64(flags.GasLimitBufferFlag.Name),
+		MaxGasLimit:                     ctx.GlobalUint64(flags.MaxGasLimitFlag.Name),
+		MaxContextDrift:                 ctx.GlobalDuration(flags.MaxContextDriftFlag.Name),
+		ExpectedInclusionDelay:          ctx.GlobalDuration(flags.ExpectedInclusionDelayFlag.Name),
+		MaxGasPriceInGwei:               ctx.GlobalUint64(flags.MaxGasPriceInGweiFlag.Name),
+		MaxFeePerL2TxInGwei:             ctx.GlobalUint64(flags.MaxFeePerL2TxInGweiFlag.Name),
+		SpendBudgetEther:                ctx.GlobalFloat64(flags.SpendBudgetEtherFlag.Name),
+		PriorityLaneAge:                 ctx.GlobalDuration(flags.PriorityLaneAgeFlag.Name),
+		PriorityLaneMinGasPriceInGwei:   ctx.GlobalUint64(flags.PriorityLaneMinGasPriceInGweiFlag.Name),
+		BatchEncoding:                   ctx.GlobalString(flags.BatchEncodingFlag.Name),
+		EncodedBatchCTC:                 ctx.GlobalBool(flags.EncodedBatchCTCFlag.Name),
+		BatchBoundary:                   ctx.GlobalString(flags.BatchBoundaryFlag.Name),
+		MaxTxsPerContext:                ctx.GlobalUint64(flags.MaxTxsPerContextFlag.Name),
+		MaxContextsPerBatch:             ctx.GlobalUint64(flags.MaxContextsPerBatchFlag.Name),
+		SystemTxPolicy:                  ctx.GlobalString(flags.SystemTxPolicyFlag.Name),
+		SystemTxSenders:                 ctx.GlobalString(flags.SystemTxSendersFlag.Name),
+		SystemTxZeroGasPrice:            ctx.GlobalBool(flags.SystemTxZeroGasPriceFlag.Name),
+		MalformedBlockPolicy:            ctx.GlobalString(flags.MalformedBlockPolicyFlag.Name),
+		MalformedBlockBudget:            ctx.GlobalUint64(flags.MalformedBlockBudgetFlag.Name),
+		DeferAboveMaxGasPrice:           ctx.GlobalBool(flags.DeferAboveMaxGasPriceFlag.Name),
+		TxSubmissionMode:                ctx.GlobalString(flags.TxSubmissionModeFlag.Name),
+		TxRelayURL:                      ctx.GlobalString(flags.TxRelayURLFlag.Name),
+		TxRelaySigningKey:               ctx.GlobalString(flags.TxRelaySigningKeyFlag.Name),
+		PrivateTxMaxBlocks:              ctx.GlobalUint64(flags.PrivateTxMaxBlocksFlag.Name),
+		PendingTxStrategy:               ctx.GlobalString(flags.PendingTxStrategyFlag.Name),
+		SelfTestMode:                    ctx.GlobalString(flags.SelfTestModeFlag.Name),
+		FillNonceGaps:                   ctx.GlobalBool(flags.FillNonceGapsFlag.Name),
+		MaxInFlightBatches:              ctx.GlobalUint64(flags.MaxInFlightBatchesFlag.Name),
+		CatchUpChunkSize:                ctx.GlobalUint64(flags.CatchUpChunkSizeFlag.Name),
+		CatchUpMaxEther:                 ctx.GlobalFloat64(flags.CatchUpMaxEtherFlag.Name),
+		CatchUpMaxDuration:              ctx.GlobalDuration(flags.CatchUpMaxDurationFlag.Name),
+		CatchUpApprovalEther:            ctx.GlobalFloat64(flags.CatchUpApprovalEtherFlag.Name),
+		MaxSubmissionAttempts:           ctx.GlobalUint64(flags.MaxSubmissionAttemptsFlag.Name),
+		HealthMaxCycleAge:               ctx.GlobalDuration(flags.HealthMaxCycleAgeFlag.Name),
+		HealthMaxConfirmationAge:        ctx.GlobalDuration(flags.HealthMaxConfirmationAgeFlag.Name),
+		RPCHealthCheckInterval:          ctx.GlobalDuration(flags.RPCHealthCheckIntervalFlag.Name),
+		RPCRequestTimeout:               ctx.GlobalDuration(flags.RPCRequestTimeoutFlag.Name),
+		RPCLoadBalanceReads:             ctx.GlobalBool(flags.RPCLoadBalanceReadsFlag.Name),
+		RPCMaxRetries:                   ctx.GlobalUint64(flags.RPCMaxRetriesFlag.Name),
+		RPCRetryInitialBackoff:          ctx.GlobalDuration(flags.RPCRetryInitialBackoffFlag.Name),
+		RPCRetryMaxBackoff:              ctx.GlobalDuration(flags.RPCRetryMaxBackoffFlag.Name),
+		RPCCallTimeout:                  ctx.GlobalDuration(flags.RPCCallTimeoutFlag.Name),
+		MaxCycleDuration:                ctx.GlobalDuration(flags.MaxCycleDurationFlag.Name),
+		RPCCircuitBreakerThreshold:      ctx.GlobalUint64(flags.RPCCircuitBreakerThresholdFlag.Name),
+		StartupGracePeriod:              ctx.GlobalDuration(flags.StartupGracePeriodFlag.Name),
+		ShutdownGracePeriod:             ctx.GlobalDuration(flags.ShutdownGracePeriodFlag.Name),
+		CriticalEtherBalance:            ctx.GlobalFloat64(flags.CriticalEtherBalanceFlag.Name),
+		BalanceDrainWindow:              ctx.GlobalDuration(flags.BalanceDrainWindowFlag.Name),
+		BalanceDrainFactor:              ctx.GlobalFloat64(flags.BalanceDrainFactorFlag.Name),
+		MinBalanceDrainRate:             ctx.GlobalFloat64(flags.MinBalanceDrainRateFlag.Name),
+		AlertWebhookURL:                 ctx.GlobalString(flags.AlertWebhookURLFlag.Name),
+		AlertSlackWebhookURL:            ctx.GlobalString(flags.AlertSlackWebhookURLFlag.Name),
+		AlertPagerDutyRoutingKey:        ctx.GlobalString(flags.AlertPagerDutyRoutingKeyFlag.Name),
+		SequencerGasPriceOracle:         ctx.GlobalString(flags.SequencerGasPriceOracleFlag.Name),
+		ProposerGasPriceOracle:          ctx.GlobalString(flags.ProposerGasPriceOracleFlag.Name),
+		GasPriceOracleURL:               ctx.GlobalString(flags.GasPriceOracleURLFlag.Name),
+		GasPriceOracleField:             ctx.GlobalString(flags.GasPriceOracleFieldFlag.Name),
+		FeeHistoryBlockCount:            ctx.GlobalUint64(flags.FeeHistoryBlockCountFlag.Name),
+		FeeHistoryPercentile:            ctx.GlobalFloat64(flags.FeeHistoryPercentileFlag.Name),
+		GasRetryIncrement:               ctx.GlobalUint64(flags.GasRetryIncrementFlag.Name),
+		FeeBumpPercent:                  ctx.GlobalUint64(flags.FeeBumpPercentFlag.Name),
+		FeeBumpTipCapPercent:            ctx.GlobalUint64(flags.FeeBumpTipCapPercentFlag.Name),
+		FeeBumpFeeCapPercent:            ctx.GlobalUint64(flags.FeeBumpFeeCapPercentFlag.Name),
+		MaxFeeBumps:                     ctx.GlobalUint64(flags.MaxFeeBumpsFlag.Name),
+		TxAbortAfter:                    ctx.GlobalDuration(flags.TxAbortAfterFlag.Name),
+		SequencerPrivateKey:             ctx.GlobalString(flags.SequencerPrivateKeyFlag.Name),
+		ProposerPrivateKey:              ctx.GlobalString(flags.ProposerPrivateKeyFlag.Name),
+		SequencerRotationPrivateKeys:    ctx.GlobalString(flags.SequencerRotationPrivateKeysFlag.Name),
+		ProposerRotationPrivateKeys:     ctx.GlobalString(flags.ProposerRotationPrivateKeysFlag.Name),
+		WalletRotationEtherBalance:      ctx.GlobalFloat64(flags.WalletRotationEtherBalanceFlag.Name),
+		WalletWedgedAfter:               ctx.GlobalDuration(flags.WalletWedgedAfterFlag.Name),
+		Mnemonic:                        ctx.GlobalString(flags.MnemonicFlag.Name),
+		SequencerHDPath:                 ctx.GlobalString(flags.SequencerHDPathFlag.Name),
+		ProposerHDPath:                  ctx.GlobalString(flags.ProposerHDPathFlag.Name),
+		MetricsServerEnable:             ctx.GlobalBool(flags.MetricsServerEnableFlag.Name),
+		MetricsHostname:                 ctx.GlobalString(flags.MetricsHostnameFlag.Name),
+		MetricsPort:                     ctx.GlobalUint64(flags.MetricsPortFlag.Name),
+		MetricsExporters:                ctx.GlobalString(flags.MetricsExportersFlag.Name),
+		StatsDAddress:                   ctx.GlobalString(flags.StatsDAddressFlag.Name),
+		OTLPEndpoint:                    ctx.GlobalString(flags.OTLPEndpointFlag.Name),
+		PushgatewayURL:                  ctx.GlobalString(flags.PushgatewayURLFlag.Name),
+		PushgatewayJob:                  ctx.GlobalString(flags.PushgatewayJobFlag.Name),
+		MetricsNamespace:                ctx.GlobalString(flags.MetricsNamespaceFlag.Name),
+		MetricsLabels:                   ctx.GlobalString(flags.MetricsLabelsFlag.Name),
+		MetricsServiceLabel:             ctx.GlobalString(flags.MetricsServiceLabelFlag.Name),
+		MetricsExportInterval:           ctx.GlobalDuration(flags.MetricsExportIntervalFlag.Name),
+		AdminServerEnable:               ctx.GlobalBool(flags.AdminServerEnableFlag.Name),
+		AdminHostname:                   ctx.GlobalString(flags.AdminHostnameFlag.Name),
+		AdminPort:                       ctx.GlobalUint64(flags.AdminPortFlag.Name),
+		AdminOperators:                  ctx.GlobalString(flags.AdminOperatorsFlag.Name),
+		AdminAuditLog:                   ctx.GlobalString(flags.AdminAuditLogFlag.Name),
+		DebugServerEnable:               ctx.GlobalBool(flags.DebugServerEnableFlag.Name),
+		DebugHostname:                   ctx.GlobalString(flags.DebugHostnameFlag.Name),
+		DebugPort:                       ctx.GlobalUint64(flags.DebugPortFlag.Name),
+		DryRun:                          ctx.GlobalBool(flags.DryRunFlag.Name),
+		SubmissionQueueDir:              ctx.GlobalString(flags.SubmissionQueueDirFlag.Name),
+		SubmissionQueueStaleLockTimeout: ctx.GlobalDuration(flags.SubmissionQueueStaleLockTimeoutFlag.Name),
+		SubmissionStateDir:              ctx.GlobalString(flags.SubmissionStateDirFlag.Name),
+		SubmissionStateURL:              ctx.GlobalString(flags.SubmissionStateURLFlag.Name),
+		HALockURL:                       ctx.GlobalString(flags.HALockURLFlag.Name),
+		HALockKey:                       ctx.GlobalString(flags.HALockKeyFlag.Name),
+		HAReplicaID:                     ctx.GlobalString(flags.HAReplicaIDFlag.Name),
+		HALeaseDuration:                 ctx.GlobalDuration(flags.HALeaseDurationFlag.Name),
+		InclusionProofDir:               ctx.GlobalString(flags.InclusionProofDirFlag.Name),
+		ArchiveURL:                      ctx.GlobalString(flags.ArchiveURLFlag.Name),
+		ArchiveKey:                      ctx.GlobalString(flags.ArchiveKeyFlag.Name),
+		ArchiveKeyID:                    ctx.GlobalString(flags.ArchiveKeyIDFlag.Name),
+		VerifyBatches:                   ctx.GlobalBool(flags.VerifyBatchesFlag.Name),
+		SequencerMemoryQuota:            ctx.GlobalUint64(flags.SequencerMemoryQuotaFlag.Name),
+		ProposerMemoryQuota:             ctx.GlobalUint64(flags.ProposerMemoryQuotaFlag.Name),
+		SequencerRPCQuota:               ctx.GlobalFloat64(flags.SequencerRPCQuotaFlag.Name),
+		ProposerRPCQuota:                ctx.GlobalFloat64(flags.ProposerRPCQuotaFlag.Name),
+		SequencerRPCBurst:               ctx.GlobalUint64(flags.SequencerRPCBurstFlag.Name),
+		ProposerRPCBurst:                ctx.GlobalUint64(flags.ProposerRPCBurstFlag.Name),
+		TenantsFile:                     ctx.GlobalString(flags.TenantsFileFlag.Name),
 	}
-
-	return cfg, nil
 }
 
 // ValidateConfig ensures additional constraints on the parsed configuration to
@@ -232,6 +1087,291 @@ func ValidateConfig(cfg *Config) error {
 		return err
 	}
 
+	// Sanity check log format and module levels.
+	switch cfg.LogFormat {
+	case "":
+		cfg.LogFormat = LogFormatTerminal
+	case LogFormatTerminal, LogFormatJSON:
+	default:
+		return ErrUnknownLogFormat
+	}
+
+	if _, err := parseLogModules(cfg.LogModules); err != nil {
+		return err
+	}
+
+	// In multi-tenant mode, wallets are validated for each tenant once the
+	// tenants file has been loaded.
+	if cfg.TenantsFile == "" {
+		if err := validateWallets(cfg); err != nil {
+			return err
+		}
+	}
+
+	// Ensure the Sentry Data Source Name is set when using Sentry.
+	if cfg.SentryEnable && cfg.SentryDsn == "" {
+		return ErrSentryDSNNotSet
+	}
+
+	// Ensure the gas limit multiplier only ever pads the gas estimate. A
+	// value of zero is left for the driver to treat as no multiplier.
+	if cfg.GasLimitMultiplier != 0 && cfg.GasLimitMultiplier < 1 {
+		return ErrInvalidGasLimitMultiplier
+	}
+
+	// Ensure batch txs are accepted by the L1 tx pool.
+	if cfg.MaxL1TxSize > MaxL1TxPoolTxSize {
+		return ErrMaxL1TxSizeTooLarge
+	}
+
+	// Ensure replacement dynamic fee txs are accepted by nodes.
+	if (cfg.FeeBumpTipCapPercent != 0 && cfg.FeeBumpTipCapPercent < 10) ||
+		(cfg.FeeBumpFeeCapPercent != 0 && cfg.FeeBumpFeeCapPercent < 10) {
+
+		return ErrInvalidDynamicFeeBump
+	}
+
+	// Ensure the priority lane's elevated fee tier remains within the max
+	// gas price.
+	if cfg.PriorityLaneMinGasPriceInGwei > cfg.MaxGasPriceInGwei {
+		return ErrInvalidPriorityLaneGasPrice
+	}
+
+	// Ensure the address labels are well-formed.
+	if _, err := ParseAddressLabels(cfg.AddressLabels); err != nil {
+		return err
+	}
+
+	// Ensure batches building on unconfirmed batches can be assigned a gas
+	// limit.
+	if cfg.MaxInFlightBatches > 1 && cfg.MaxGasLimit == 0 {
+		return ErrPipelineRequiresMaxGasLimit
+	}
+
+	// Ensure pending txs are cleared using a supported strategy,
+	// defaulting to cancellation.
+	if cfg.PendingTxStrategy == "" {
+		cfg.PendingTxStrategy = PendingTxStrategyCancel
+	}
+	switch cfg.PendingTxStrategy {
+	case PendingTxStrategyCancel, PendingTxStrategyReplace:
+	default:
+		return ErrUnknownPendingTxStrategy
+	}
+
+	// Ensure a warning is raised before submission halts on a low balance.
+	if cfg.CriticalEtherBalance < 0 ||
+		(cfg.CriticalEtherBalance > 0 && cfg.SafeMinimumEtherBalance > 0 &&
+			cfg.CriticalEtherBalance >= float64(cfg.SafeMinimumEtherBalance)) {
+
+		return ErrInvalidCriticalEtherBalance
+	}
+
+	// Ensure a catch-up budget bounds a chunked backlog.
+	if cfg.CatchUpMaxEther < 0 || cfg.CatchUpMaxDuration < 0 ||
+		cfg.CatchUpApprovalEther < 0 {
+
+		return ErrInvalidCatchUpBudget
+	}
+	if cfg.CatchUpChunkSize == 0 && (cfg.CatchUpMaxEther > 0 ||
+		cfg.CatchUpMaxDuration > 0 || cfg.CatchUpApprovalEther > 0) {
+
+		return ErrInvalidCatchUpBudget
+	}
+
+	// Ensure drain alerts are only raised above the baseline rate.
+	if cfg.BalanceDrainWindow > 0 && cfg.BalanceDrainFactor <= 1 {
+		return ErrInvalidBalanceDrainFactor
+	}
+
+	// Ensure retries back off no further than the max backoff.
+	if cfg.RPCMaxRetries > 0 &&
+		cfg.RPCRetryInitialBackoff > cfg.RPCRetryMaxBackoff {
+
+		return ErrInvalidRPCRetryBackoff
+	}
+
+	// Ensure L2 blocks are read in a supported flavor of RPC.
+	if _, err := l2client.ParseFlavor(cfg.L2RPCFlavor); err != nil {
+		return err
+	}
+
+	// Ensure sequencer batches use a supported encoding, defaulting to v0,
+	// and that the delta encoded v1 and the self-describing v2 are only
+	// used against a CTC accepting them.
+	if cfg.BatchEncoding == "" {
+		cfg.BatchEncoding = sequencer.BatchVersionV0.String()
+	}
+	batchVersion, err := sequencer.ParseBatchVersion(cfg.BatchEncoding)
+	if err != nil {
+		return err
+	}
+	if batchVersion != sequencer.BatchVersionV0 && !cfg.EncodedBatchCTC {
+		return fmt.Errorf("%w: %s", ErrBatchEncodingIncompatible,
+			cfg.BatchEncoding)
+	}
+
+	// Ensure sequencer batches align with a supported boundary.
+	if _, err := sequencer.ParseBatchBoundary(cfg.BatchBoundary); err != nil {
+		return err
+	}
+
+	// Ensure sequencer batches are limited to what their encoding allows.
+	if cfg.MaxTxsPerContext > sequencer.MaxContextTxs {
+		return ErrInvalidMaxTxsPerContext
+	}
+	if cfg.MaxContextsPerBatch > sequencer.MaxBatchContexts {
+		return ErrInvalidMaxContextsPerBatch
+	}
+
+	// Ensure system txs are identified and handled in a supported way.
+	if _, err := sequencer.ParseSystemTxPolicy(cfg.SystemTxPolicy); err != nil {
+		return err
+	}
+	if _, err := sequencer.ParseSystemTxSenders(cfg.SystemTxSenders); err != nil {
+		return err
+	}
+
+	// Ensure malformed blocks are handled in a supported way.
+	_, err = sequencer.ParseMalformedBlockPolicy(cfg.MalformedBlockPolicy)
+	if err != nil {
+		return err
+	}
+
+	// Ensure batch txs are submitted in a supported mode, defaulting to
+	// the public mempool, and that private modes have a relay to submit
+	// to.
+	submissionMode, err := txmgr.ParseSubmissionMode(cfg.TxSubmissionMode)
+	if err != nil {
+		return err
+	}
+	if submissionMode != txmgr.SubmissionModePublic && cfg.TxRelayURL == "" {
+		return ErrTxRelayURLNotSet
+	}
+	if submissionMode == txmgr.SubmissionModeBundle &&
+		cfg.TxRelaySigningKey == "" {
+
+		return txmgr.ErrRelaySigningKeyRequired
+	}
+
+	// Ensure replicas in high availability mode elect a leader through a
+	// supported lock backend.
+	if cfg.HALockURL != "" {
+		_, err := leader.NewRedisLock(cfg.HALockURL, cfg.HALockKey)
+		if err != nil {
+			return err
+		}
+		if cfg.HALeaseDuration < 0 {
+			return ErrInvalidHALeaseDuration
+		}
+	}
+
+	// Ensure admin mutations can only be authorized by valid operator
+	// addresses.
+	if _, err := ParseAdminOperators(cfg.AdminOperators); err != nil {
+		return err
+	}
+	if _, err := ParseAdminOperators(cfg.TenantAdminOperators); err != nil {
+		return err
+	}
+
+	// Ensure RPC quotas are non-negative, zero disabling them.
+	if cfg.SequencerRPCQuota < 0 || cfg.ProposerRPCQuota < 0 {
+		return ErrInvalidRPCQuota
+	}
+
+	// Ensure the spend budget is non-negative, zero disabling it.
+	if cfg.SpendBudgetEther < 0 {
+		return ErrInvalidSpendBudget
+	}
+
+	// Ensure the startup self-test uses a supported mode, defaulting to
+	// starting degraded on failure.
+	if cfg.SelfTestMode == "" {
+		cfg.SelfTestMode = SelfTestDegraded
+	}
+	switch cfg.SelfTestMode {
+	case SelfTestOff, SelfTestStrict, SelfTestDegraded:
+	default:
+		return ErrUnknownSelfTestMode
+	}
+
+	// Ensure each metrics exporter is supported and fully configured.
+	if err := validateMetricsExporters(cfg); err != nil {
+		return err
+	}
+
+	// Ensure the debug server does not compete with the metrics server
+	// for its port.
+	if cfg.DebugServerEnable && cfg.MetricsServerEnable &&
+		cfg.DebugPort == cfg.MetricsPort {
+
+		return ErrDebugPortConflict
+	}
+
+	// Ensure the admin server does not compete with the metrics or debug
+	// servers for its port.
+	if cfg.AdminServerEnable &&
+		((cfg.MetricsServerEnable && cfg.AdminPort == cfg.MetricsPort) ||
+			(cfg.DebugServerEnable && cfg.AdminPort == cfg.DebugPort)) {
+
+		return ErrAdminPortConflict
+	}
+
+	// Ensure the submission state backend is supported.
+	if cfg.SubmissionStateURL != "" {
+		_, _, err := queue.ParseBackendURL(cfg.SubmissionStateURL)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidSubmissionStateURL,
+				err)
+		}
+	}
+
+	// Ensure the archive backend is supported, and that batches are
+	// never archived unencrypted.
+	if cfg.ArchiveURL != "" {
+		if _, _, err := queue.ParseBackendURL(cfg.ArchiveURL); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchiveURL, err)
+		}
+		if _, err := archive.ParseKey(cfg.ArchiveKey); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArchiveKey, err)
+		}
+	}
+
+	// Ensure each service's gas price oracle is supported and fully
+	// configured.
+	for _, oracle := range []string{
+		cfg.SequencerGasPriceOracle, cfg.ProposerGasPriceOracle,
+	} {
+		if err := validateGasPriceOracle(cfg, oracle); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// validateGasPriceOracle ensures that oracle names a supported gas price oracle
+// type, and that any parameters it requires are set.
+func validateGasPriceOracle(cfg *Config, oracle string) error {
+	switch oracle {
+	case "", GasPriceOracleNode, GasPriceOracleFeeHistory:
+		return nil
+
+	case GasPriceOracleHTTP:
+		if cfg.GasPriceOracleURL == "" {
+			return ErrGasPriceOracleURLNotSet
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownGasPriceOracle, oracle)
+	}
+}
+
+// validateWallets ensures that the sequencer and proposer wallets are each
+// configured using exactly one derivation method, and that they are distinct.
+func validateWallets(cfg *Config) error {
 	// Enforce that either sequencer-private-key or mnemonic + sequencer-hd-path
 	// is enabled, but not both or neither.
 	usingSequencerPrivateKey := cfg.SequencerPrivateKey != ""
@@ -262,9 +1402,53 @@ func ValidateConfig(cfg *Config) error {
 		return ErrSameSequencerAndProposerPrivKey
 	}
 
-	// Ensure the Sentry Data Source Name is set when using Sentry.
-	if cfg.SentryEnable && cfg.SentryDsn == "" {
-		return ErrSentryDSNNotSet
+	if cfg.WalletRotationEtherBalance < 0 || cfg.WalletWedgedAfter < 0 {
+		return ErrInvalidWalletRotation
+	}
+
+	return validateRotationKeys(cfg)
+}
+
+// validateRotationKeys ensures that the rotation private keys parse, and that
+// no wallet is configured more than once across the sequencer and proposer.
+func validateRotationKeys(cfg *Config) error {
+	keyStrs := []string{
+		cfg.SequencerRotationPrivateKeys,
+		cfg.ProposerRotationPrivateKeys,
+	}
+
+	var privKeys []*ecdsa.PrivateKey
+	for _, keyStr := range keyStrs {
+		keys, err := ParsePrivateKeysStr(keyStr)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidRotationKey, err)
+		}
+		privKeys = append(privKeys, keys...)
+	}
+	if len(privKeys) == 0 {
+		return nil
+	}
+
+	// Wallets derived from the mnemonic are only known once it is parsed,
+	// so only the primary private keys are checked against.
+	for _, keyStr := range []string{
+		cfg.SequencerPrivateKey, cfg.ProposerPrivateKey,
+	} {
+		if keyStr == "" {
+			continue
+		}
+		if key, err := ParsePrivateKeyStr(keyStr); err == nil {
+			privKeys = append(privKeys, key)
+		}
+	}
+
+	seen := make(map[common.Address]struct{})
+	for _, privKey := range privKeys {
+		addr := crypto.PubkeyToAddress(privKey.PublicKey)
+		if _, ok := seen[addr]; ok {
+			return ErrSharedRotationKey
+		}
+		seen[addr] = struct{}{}
 	}
 
 	return nil
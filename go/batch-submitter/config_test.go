@@ -3,11 +3,27 @@ package batchsubmitter_test
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/leader"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/stretchr/testify/require"
 )
 
+// rotationPrivKey is a valid private key configured as a rotation wallet.
+const rotationPrivKey = "4c0883a69102937d6231471b5dbb6204" +
+	"fe5129617082792ae468d01a3f362318"
+
+// validArchiveKey is a valid hex-encoded archive key.
+const validArchiveKey = "0x0101010101010101010101010101010101010101010101" +
+	"010101010101010101"
+
 var validateConfigTests = []struct {
 	name   string
 	cfg    batchsubmitter.Config
@@ -20,6 +36,22 @@ var validateConfigTests = []struct {
 		},
 		expErr: fmt.Errorf("unknown level: unknown"),
 	},
+	{
+		name: "bad log format",
+		cfg: batchsubmitter.Config{
+			LogLevel:  "info",
+			LogFormat: "xml",
+		},
+		expErr: batchsubmitter.ErrUnknownLogFormat,
+	},
+	{
+		name: "bad log modules",
+		cfg: batchsubmitter.Config{
+			LogLevel:   "info",
+			LogModules: "txmgr=verbose",
+		},
+		expErr: batchsubmitter.ErrInvalidLogModules,
+	},
 	{
 		name: "sequencer priv key or mnemonic none set",
 		cfg: batchsubmitter.Config{
@@ -133,6 +165,29 @@ var validateConfigTests = []struct {
 		},
 		expErr: batchsubmitter.ErrSameSequencerAndProposerPrivKey,
 	},
+	{
+		name: "negative wallet rotation balance",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			WalletRotationEtherBalance: -1,
+		},
+		expErr: batchsubmitter.ErrInvalidWalletRotation,
+	},
+	{
+		name: "rotation key shared by sequencer and proposer",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SequencerRotationPrivateKeys: rotationPrivKey,
+			ProposerRotationPrivateKeys:  "0x" + rotationPrivKey,
+		},
+		expErr: batchsubmitter.ErrSharedRotationKey,
+	},
 	{
 		name: "sentry-dsn not set when sentry-enable is true",
 		cfg: batchsubmitter.Config{
@@ -145,6 +200,500 @@ var validateConfigTests = []struct {
 		},
 		expErr: batchsubmitter.ErrSentryDSNNotSet,
 	},
+	{
+		name: "gas limit multiplier below one",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			GasLimitMultiplier: 0.9,
+		},
+		expErr: batchsubmitter.ErrInvalidGasLimitMultiplier,
+	},
+	{
+		name: "http gas price oracle without url",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			ProposerGasPriceOracle: batchsubmitter.GasPriceOracleHTTP,
+		},
+		expErr: batchsubmitter.ErrGasPriceOracleURLNotSet,
+	},
+	{
+		name: "statsd metrics exporter without address",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsExporters: "prometheus,statsd",
+		},
+		expErr: batchsubmitter.ErrStatsDAddressNotSet,
+	},
+	{
+		name: "pushgateway metrics exporter without url",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsExporters: "pushgateway",
+		},
+		expErr: batchsubmitter.ErrPushgatewayURLNotSet,
+	},
+	{
+		name: "invalid metrics namespace",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsNamespace: "op-mainnet",
+		},
+		expErr: fmt.Errorf("%w: op-mainnet",
+			batchsubmitter.ErrInvalidMetricsNamespace),
+	},
+	{
+		name: "malformed metrics labels",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsLabels: "network=mainnet,chain-id=10",
+		},
+		expErr: fmt.Errorf("%w: chain-id=10",
+			batchsubmitter.ErrInvalidMetricsLabels),
+	},
+	{
+		name: "otlp metrics exporter without endpoint",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsExporters: "otlp",
+		},
+		expErr: batchsubmitter.ErrOTLPEndpointNotSet,
+	},
+	{
+		name: "unknown pending tx strategy",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			PendingTxStrategy: "rebroadcast",
+		},
+		expErr: batchsubmitter.ErrUnknownPendingTxStrategy,
+	},
+	{
+		name: "negative spend budget",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SpendBudgetEther: -1,
+		},
+		expErr: batchsubmitter.ErrInvalidSpendBudget,
+	},
+	{
+		name: "unknown self-test mode",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SelfTestMode: "lenient",
+		},
+		expErr: batchsubmitter.ErrUnknownSelfTestMode,
+	},
+	{
+		name: "critical balance above safe minimum",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SafeMinimumEtherBalance: 1,
+			CriticalEtherBalance:    1.5,
+		},
+		expErr: batchsubmitter.ErrInvalidCriticalEtherBalance,
+	},
+	{
+		name: "balance drain factor at baseline",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			BalanceDrainWindow: time.Hour,
+			BalanceDrainFactor: 1,
+		},
+		expErr: batchsubmitter.ErrInvalidBalanceDrainFactor,
+	},
+	{
+		name: "rpc retry initial backoff above max backoff",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			RPCMaxRetries:          3,
+			RPCRetryInitialBackoff: time.Minute,
+			RPCRetryMaxBackoff:     time.Second,
+		},
+		expErr: batchsubmitter.ErrInvalidRPCRetryBackoff,
+	},
+	{
+		name: "pipelined batches without max gas limit",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MaxInFlightBatches: 2,
+		},
+		expErr: batchsubmitter.ErrPipelineRequiresMaxGasLimit,
+	},
+	{
+		name: "priority lane gas price above max gas price",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MaxGasPriceInGwei:             100,
+			PriorityLaneMinGasPriceInGwei: 200,
+		},
+		expErr: batchsubmitter.ErrInvalidPriorityLaneGasPrice,
+	},
+	{
+		name: "dynamic fee bump below replacement minimum",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			FeeBumpTipCapPercent: 5,
+		},
+		expErr: batchsubmitter.ErrInvalidDynamicFeeBump,
+	},
+	{
+		name: "negative rpc quota",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			ProposerRPCQuota: -1,
+		},
+		expErr: batchsubmitter.ErrInvalidRPCQuota,
+	},
+	{
+		name: "debug server on metrics port",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsServerEnable: true,
+			MetricsPort:         7300,
+			DebugServerEnable:   true,
+			DebugPort:           7300,
+		},
+		expErr: batchsubmitter.ErrDebugPortConflict,
+	},
+	{
+		name: "admin server on metrics port",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MetricsServerEnable: true,
+			MetricsPort:         7300,
+			AdminServerEnable:   true,
+			AdminPort:           7300,
+		},
+		expErr: batchsubmitter.ErrAdminPortConflict,
+	},
+	{
+		name: "admin server on debug port",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			DebugServerEnable: true,
+			DebugPort:         6060,
+			AdminServerEnable: true,
+			AdminPort:         6060,
+		},
+		expErr: batchsubmitter.ErrAdminPortConflict,
+	},
+	{
+		name: "max l1 tx size above tx pool limit",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+			MaxL1TxSize:         batchsubmitter.MaxL1TxPoolTxSize + 1,
+		},
+		expErr: batchsubmitter.ErrMaxL1TxSizeTooLarge,
+	},
+	{
+		name: "unknown submission state backend",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SubmissionStateURL: "redis://localhost:6379",
+		},
+		expErr: fmt.Errorf("%w: %v",
+			batchsubmitter.ErrInvalidSubmissionStateURL,
+			fmt.Errorf("%w: redis", queue.ErrUnknownBackend)),
+	},
+	{
+		name: "unknown archive backend",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			ArchiveURL: "ipfs://localhost:5001",
+			ArchiveKey: validArchiveKey,
+		},
+		expErr: fmt.Errorf("%w: %v",
+			batchsubmitter.ErrInvalidArchiveURL,
+			fmt.Errorf("%w: ipfs", queue.ErrUnknownBackend)),
+	},
+	{
+		name: "archive without key",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			ArchiveURL: "/var/lib/batch-archive",
+		},
+		expErr: fmt.Errorf("%w: %v",
+			batchsubmitter.ErrInvalidArchiveKey, hexutil.ErrEmptyString),
+	},
+	{
+		name: "archive key too short",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			ArchiveURL: "/var/lib/batch-archive",
+			ArchiveKey: "0x0102",
+		},
+		expErr: fmt.Errorf("%w: %v",
+			batchsubmitter.ErrInvalidArchiveKey, archive.ErrInvalidKey),
+	},
+	{
+		name: "unknown batch encoding",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			BatchEncoding: "v3",
+		},
+		expErr: fmt.Errorf("%w: v3", sequencer.ErrUnknownBatchVersion),
+	},
+	{
+		name: "v1 batch encoding without encoded batch ctc",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			BatchEncoding: "v1",
+		},
+		expErr: fmt.Errorf("%w: v1",
+			batchsubmitter.ErrBatchEncodingIncompatible),
+	},
+	{
+		name: "v2 batch encoding without encoded batch ctc",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			BatchEncoding: "v2",
+		},
+		expErr: fmt.Errorf("%w: v2",
+			batchsubmitter.ErrBatchEncodingIncompatible),
+	},
+	{
+		name: "max txs per context beyond encoding",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MaxTxsPerContext: sequencer.MaxContextTxs + 1,
+		},
+		expErr: batchsubmitter.ErrInvalidMaxTxsPerContext,
+	},
+	{
+		name: "max contexts per batch beyond encoding",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MaxContextsPerBatch: sequencer.MaxBatchContexts + 1,
+		},
+		expErr: batchsubmitter.ErrInvalidMaxContextsPerBatch,
+	},
+	{
+		name: "unknown l2 rpc flavor",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			L2RPCFlavor: "erigon",
+		},
+		expErr: fmt.Errorf("%w: erigon", l2client.ErrUnknownFlavor),
+	},
+	{
+		name: "unknown batch boundary",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			BatchBoundary: "block",
+		},
+		expErr: fmt.Errorf("%w: block",
+			sequencer.ErrUnknownBatchBoundary),
+	},
+	{
+		name: "unknown system tx policy",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SystemTxPolicy: "skip",
+		},
+		expErr: fmt.Errorf("%w: skip",
+			sequencer.ErrUnknownSystemTxPolicy),
+	},
+	{
+		name: "invalid system tx sender",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			SystemTxSenders: "0x4200000000000000000000000000000000000000,oracle",
+		},
+		expErr: fmt.Errorf("%w: oracle",
+			sequencer.ErrInvalidSystemTxSender),
+	},
+	{
+		name: "unknown malformed block policy",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			MalformedBlockPolicy: "skip",
+		},
+		expErr: fmt.Errorf("%w: skip",
+			sequencer.ErrUnknownMalformedBlockPolicy),
+	},
+	{
+		name: "catch-up budget without chunk size",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			CatchUpMaxEther: 1,
+		},
+		expErr: batchsubmitter.ErrInvalidCatchUpBudget,
+	},
+	{
+		name: "private tx submission without relay",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			TxSubmissionMode: "private",
+		},
+		expErr: batchsubmitter.ErrTxRelayURLNotSet,
+	},
+	{
+		name: "bundle submission without signing key",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			TxSubmissionMode: "bundle",
+			TxRelayURL:       "https://relay.flashbots.net",
+		},
+		expErr: txmgr.ErrRelaySigningKeyRequired,
+	},
+	{
+		name: "unsupported ha lock backend",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			HALockURL: "etcd://localhost:2379",
+		},
+		expErr: fmt.Errorf("%w: etcd", leader.ErrUnsupportedLockURL),
+	},
+	{
+		name: "negative ha lease duration",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			HALockURL:       "redis://localhost:6379",
+			HALeaseDuration: -time.Second,
+		},
+		expErr: batchsubmitter.ErrInvalidHALeaseDuration,
+	},
+	{
+		name: "invalid admin operator",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			AdminOperators: "0x000000000000000000000000000000000000dEaD,0xbad",
+		},
+		expErr: fmt.Errorf("invalid address: 0xbad"),
+	},
+	{
+		name: "invalid address labels",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+
+			AddressLabels: "mainnet-ctc",
+		},
+		expErr: fmt.Errorf("%w: %q", batchsubmitter.ErrInvalidAddressLabel,
+			"mainnet-ctc"),
+	},
 	// Valid configs
 	{
 		name: "valid config with privkeys and no sentry",
@@ -157,6 +706,39 @@ var validateConfigTests = []struct {
 		},
 		expErr: nil,
 	},
+	{
+		name: "valid config with archive",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+			ArchiveURL:          "s3://batch-archive",
+			ArchiveKey:          validArchiveKey,
+		},
+		expErr: nil,
+	},
+	{
+		name: "valid config with v1 batch encoding",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+			BatchEncoding:       "v1",
+			EncodedBatchCTC:     true,
+		},
+		expErr: nil,
+	},
+	{
+		name: "valid config with v2 batch encoding",
+		cfg: batchsubmitter.Config{
+			LogLevel:            "info",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+			BatchEncoding:       "v2",
+			EncodedBatchCTC:     true,
+		},
+		expErr: nil,
+	},
 	{
 		name: "valid config with mnemonic and no sentry",
 		cfg: batchsubmitter.Config{
@@ -106,3 +106,22 @@ func ParsePrivateKeyStr(privKeyStr string) (*ecdsa.PrivateKey, error) {
 	hex := strings.TrimPrefix(privKeyStr, "0x")
 	return crypto.HexToECDSA(hex)
 }
+
+// ParsePrivateKeysStr parses a comma-separated list of hexidecimal encoded
+// private keys, each of which may optionally have an "0x" prefix.
+func ParsePrivateKeysStr(privKeysStr string) ([]*ecdsa.PrivateKey, error) {
+	var privKeys []*ecdsa.PrivateKey
+	for _, privKeyStr := range strings.Split(privKeysStr, ",") {
+		privKeyStr = strings.TrimSpace(privKeyStr)
+		if privKeyStr == "" {
+			continue
+		}
+		privKey, err := ParsePrivateKeyStr(privKeyStr)
+		if err != nil {
+			return nil, err
+		}
+		privKeys = append(privKeys, privKey)
+	}
+
+	return privKeys, nil
+}
@@ -0,0 +1,208 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strconv"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// debugPprofPath is the path under which the pprof profiles are served
+	// by the debug server.
+	debugPprofPath = "/debug/pprof/"
+
+	// debugGoroutinesPath is the path at which the stacks of every
+	// goroutine are dumped by the debug server.
+	debugGoroutinesPath = "/debug/goroutines"
+
+	// debugStatePath is the path at which a snapshot of the internal state
+	// of the registered services is served by the debug server.
+	debugStatePath = "/debug/state"
+)
+
+// DebugStater is an optional interface that may be implemented by a Driver to
+// report its internal state to the debug server.
+type DebugStater interface {
+	// DebugState returns a snapshot of the driver's internal state, e.g.
+	// its cache sizes, encodable as JSON.
+	DebugState() interface{}
+}
+
+// RuntimeDebugState is a snapshot of the Go runtime of the process.
+type RuntimeDebugState struct {
+	NumGoroutines int    `json:"num_goroutines"`
+	HeapAlloc     uint64 `json:"heap_alloc"`
+	HeapInuse     uint64 `json:"heap_inuse"`
+	Sys           uint64 `json:"sys"`
+	NumGC         uint32 `json:"num_gc"`
+}
+
+// ServiceDebugState is a snapshot of the internal state of a single service.
+type ServiceDebugState struct {
+	Name string `json:"name"`
+
+	// BlockRange is the last observed range of L2 blocks awaiting
+	// submission, if any.
+	BlockRange *BlockRangeLag `json:"block_range,omitempty"`
+
+	// CurrentCycle is the trace of the cycle in progress, if any.
+	CurrentCycle *CycleTrace `json:"current_cycle,omitempty"`
+
+	// PinnedBlock is the L2 block at the end of the highest confirmed
+	// batch, verified before building on top of it, if any.
+	PinnedBlock *BlockPin `json:"pinned_block,omitempty"`
+
+	// InFlight are the batch txs awaiting confirmation in pipelined mode.
+	InFlight []PendingBatch `json:"in_flight"`
+
+	// PendingSubmissions are the pending records of the configured
+	// StateStore, if any.
+	PendingSubmissions []*queue.SubmissionRecord `json:"pending_submissions,omitempty"`
+
+	// NumQueuedBatches is the number of built batches held by the
+	// configured SubmissionQueue, if any.
+	NumQueuedBatches int `json:"num_queued_batches"`
+
+	// Driver is the internal state reported by a Driver implementing
+	// DebugStater, if any.
+	Driver interface{} `json:"driver,omitempty"`
+
+	// Errors describe the parts of the state that could not be read.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// DebugStateResponse is the body returned by the debug state endpoint.
+type DebugStateResponse struct {
+	Runtime  RuntimeDebugState   `json:"runtime"`
+	Services []ServiceDebugState `json:"services"`
+}
+
+// DebugState returns a snapshot of the internal state of the service. Unlike
+// PendingTxState, no RPC queries are made, such that the snapshot is served
+// even while the backends are unresponsive.
+func (s *Service) DebugState() ServiceDebugState {
+	state := ServiceDebugState{
+		Name:         s.cfg.Driver.Name(),
+		BlockRange:   s.health.lag(),
+		CurrentCycle: s.traces.Current(),
+		PinnedBlock:  s.endBlockPin.Get(),
+		InFlight:     []PendingBatch{},
+	}
+	if s.pipeline != nil {
+		for _, batch := range s.pipeline.Batches() {
+			state.InFlight = append(state.InFlight, PendingBatch{
+				Start: batch.start,
+				End:   batch.end,
+				Nonce: batch.nonce,
+			})
+		}
+	}
+	if s.cfg.StateStore != nil {
+		pending, err := s.cfg.StateStore.Pending()
+		if err != nil {
+			state.Errors = append(state.Errors,
+				fmt.Sprintf("pending submissions: %v", err))
+		}
+		state.PendingSubmissions = pending
+	}
+	if s.cfg.SubmissionQueue != nil {
+		n, err := s.cfg.SubmissionQueue.Len()
+		if err != nil {
+			state.Errors = append(state.Errors,
+				fmt.Sprintf("queued batches: %v", err))
+		}
+		state.NumQueuedBatches = n
+	}
+	if stater, ok := s.cfg.Driver.(DebugStater); ok {
+		state.Driver = stater.DebugState()
+	}
+
+	return state
+}
+
+// runtimeDebugState returns a snapshot of the Go runtime.
+func runtimeDebugState() RuntimeDebugState {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	return RuntimeDebugState{
+		NumGoroutines: runtime.NumGoroutine(),
+		HeapAlloc:     memStats.HeapAlloc,
+		HeapInuse:     memStats.HeapInuse,
+		Sys:           memStats.Sys,
+		NumGC:         memStats.NumGC,
+	}
+}
+
+// debugStateHandler serves the internal state of the services of a registry.
+type debugStateHandler struct {
+	registry *statusRegistry
+}
+
+// ServeHTTP returns a snapshot of the runtime and of every registered service,
+// ordered by name, as JSON.
+func (h debugStateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	resp := DebugStateResponse{
+		Runtime:  runtimeDebugState(),
+		Services: []ServiceDebugState{},
+	}
+	for _, s := range h.registry.sorted() {
+		resp.Services = append(resp.Services, s.DebugState())
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("Unable to write debug state response", "err", err)
+	}
+}
+
+// serveGoroutines dumps the stacks of every goroutine as text.
+func serveGoroutines(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if err := rpprof.Lookup("goroutine").WriteTo(w, 2); err != nil {
+		log.Error("Unable to write goroutine dump", "err", err)
+	}
+}
+
+// debugHandlers returns a mux serving the pprof profiles, goroutine dumps and
+// internal state of the services of registry.
+func debugHandlers(registry *statusRegistry) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc(debugPprofPath, pprof.Index)
+	mux.HandleFunc(debugPprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(debugPprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(debugPprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(debugPprofPath+"trace", pprof.Trace)
+	mux.HandleFunc(debugGoroutinesPath, serveGoroutines)
+	mux.Handle(debugStatePath, debugStateHandler{
+		registry: registry,
+	})
+
+	return mux
+}
+
+// runDebugServer spins up the debug server at the provided hostname and port.
+// The debug server is kept apart from the metrics server, such that profiling
+// is only exposed where explicitly enabled.
+//
+// NOTE: This method MUST be run as a goroutine.
+func runDebugServer(hostname string, port uint64) {
+	debugAddr := fmt.Sprintf("%s:%s", hostname,
+		strconv.FormatUint(port, 10))
+
+	log.Info("Starting debug server", "addr", debugAddr)
+	err := http.ListenAndServe(debugAddr, debugHandlers(defaultStatusRegistry))
+	log.Error("Debug server stopped", "err", err)
+}
@@ -0,0 +1,74 @@
+package batchsubmitter
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestDebugHandlers asserts that the debug server serves the pprof index, a
+// goroutine dump and a snapshot of every registered service.
+func TestDebugHandlers(t *testing.T) {
+	t.Parallel()
+
+	registry := &statusRegistry{
+		services: make(map[string]*Service),
+	}
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver: namedDriver{name: "TestDebugHandlers"},
+		},
+		traces:   newTraceHistory(1),
+		health:   newHealthState(),
+		pipeline: newPipeline(context.Background(), 2),
+	}
+	s.health.SetBlockRange(big.NewInt(10), big.NewInt(15))
+	s.pipeline.Add(&inFlightBatch{
+		start: 10,
+		end:   12,
+		nonce: 3,
+	})
+	registry.register(s)
+
+	server := httptest.NewServer(debugHandlers(registry))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + debugPprofPath)
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get(server.URL + debugGoroutinesPath)
+	require.Nil(t, err)
+	dump, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.Nil(t, err)
+	require.True(t, strings.Contains(string(dump), "goroutine "))
+
+	resp, err = http.Get(server.URL + debugStatePath)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var state DebugStateResponse
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&state))
+	require.Greater(t, state.Runtime.NumGoroutines, 0)
+	require.Len(t, state.Services, 1)
+
+	service := state.Services[0]
+	require.Equal(t, "TestDebugHandlers", service.Name)
+	require.Equal(t, uint64(5), service.BlockRange.Blocks)
+	require.WithinDuration(t, time.Now(), service.BlockRange.ObservedAt,
+		time.Minute)
+	require.Equal(t, []PendingBatch{{Start: 10, End: 12, Nonce: 3}},
+		service.InFlight)
+	require.Nil(t, service.Driver)
+}
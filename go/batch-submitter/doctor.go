@@ -0,0 +1,493 @@
+package batchsubmitter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+)
+
+const (
+	// doctorTimeout bounds each check made by the doctor.
+	doctorTimeout = 10 * time.Second
+
+	// doctorMaxClockSkew is the max duration by which the latest L1 block
+	// may be timestamped ahead of the local clock.
+	doctorMaxClockSkew = 30 * time.Second
+
+	// doctorMaxHeadAge is the max age of the latest L1 block, beyond which
+	// either the provider is lagging or the local clock is ahead.
+	doctorMaxHeadAge = 2 * time.Minute
+
+	// doctorBatchGasLimit is the gas limit at which the runway is estimated
+	// if no MaxGasLimit is configured.
+	doctorBatchGasLimit = 2_000_000
+)
+
+// ErrDoctorFailed signals that at least one check made by the doctor failed.
+var ErrDoctorFailed = errors.New("deployment checks failed")
+
+// doctorStatus is the outcome of a single check.
+type doctorStatus string
+
+const (
+	doctorPass doctorStatus = "PASS"
+	doctorWarn doctorStatus = "WARN"
+	doctorFail doctorStatus = "FAIL"
+)
+
+// doctorResult is the result of a single check against a single target.
+type doctorResult struct {
+	check  string
+	target string
+	status doctorStatus
+	detail string
+}
+
+// doctorReport accumulates the results of every check.
+type doctorReport struct {
+	results []doctorResult
+}
+
+// add records the result of check against target.
+func (r *doctorReport) add(
+	check, target string,
+	status doctorStatus,
+	format string,
+	args ...interface{},
+) {
+
+	r.results = append(r.results, doctorResult{
+		check:  check,
+		target: target,
+		status: status,
+		detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// failed returns true if any check failed.
+func (r *doctorReport) failed() bool {
+	for _, result := range r.results {
+		if result.status == doctorFail {
+			return true
+		}
+	}
+	return false
+}
+
+// print writes the report to w as a table.
+func (r *doctorReport) print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tCHECK\tTARGET\tDETAIL")
+	for _, result := range r.results {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.status,
+			result.check, result.target, result.detail)
+	}
+	tw.Flush()
+}
+
+// Doctor runs the doctor subcommand, checking that the deployment described
+// by the configured flags is able to run, and printing a pass/fail report. No
+// txs are published. An error is returned if any check fails.
+func Doctor(ctx *cli.Context) error {
+	cfg, err := NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+
+	tenantCfgs := []Config{cfg}
+	if cfg.TenantsFile != "" {
+		tenantCfgs, err = LoadTenantConfigs(cfg, cfg.TenantsFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	report := &doctorReport{}
+	for _, tenantCfg := range tenantCfgs {
+		diagnose(context.Background(), tenantCfg, report)
+	}
+	report.print(os.Stdout)
+
+	if report.failed() {
+		return ErrDoctorFailed
+	}
+	return nil
+}
+
+// diagnose checks the deployment described by cfg, recording each result in
+// report. Checks that depend on a failed check are skipped.
+func diagnose(ctx context.Context, cfg Config, report *doctorReport) {
+	prefix := tenantPrefix(cfg)
+
+	l1Client, head := diagnoseL1(ctx, cfg, report)
+	diagnoseL2(ctx, cfg, report)
+
+	if l1Client != nil {
+		diagnoseContract(ctx, l1Client, prefix+"CTC", cfg.CTCAddress,
+			report)
+		diagnoseContract(ctx, l1Client, prefix+"SCC", cfg.SCCAddress,
+			report)
+		diagnoseClockSkew(head, time.Now(), report)
+	}
+
+	type signer struct {
+		name       string
+		run        bool
+		hdPath     string
+		privKeyStr string
+	}
+	for _, s := range []signer{
+		{prefix + "Sequencer", cfg.RunTxBatchSubmitter,
+			cfg.SequencerHDPath, cfg.SequencerPrivateKey},
+		{prefix + "Proposer", cfg.RunStateBatchSubmitter,
+			cfg.ProposerHDPath, cfg.ProposerPrivateKey},
+	} {
+		if !s.run {
+			continue
+		}
+		walletAddr, ok := diagnoseSigner(
+			s.name, cfg.Mnemonic, s.hdPath, s.privKeyStr, report,
+		)
+		if ok && l1Client != nil {
+			diagnoseBalance(ctx, cfg, l1Client, s.name, walletAddr,
+				report)
+		}
+	}
+
+	if cfg.MetricsServerEnable {
+		diagnoseMetricsPort(cfg.MetricsHostname, cfg.MetricsPort, report)
+	}
+
+	for _, dir := range []struct {
+		name string
+		path string
+	}{
+		{"submission_queue_dir", cfg.SubmissionQueueDir},
+		{"submission_state_dir", submissionStateDir(cfg)},
+		{"inclusion_proof_dir", cfg.InclusionProofDir},
+	} {
+		if dir.path != "" {
+			diagnoseDirectory(dir.name, dir.path, report)
+		}
+	}
+}
+
+// diagnoseL1 checks that each L1 provider is reachable and on the same chain,
+// returning a client of the first reachable provider along with its latest
+// header.
+func diagnoseL1(
+	ctx context.Context,
+	cfg Config,
+	report *doctorReport,
+) (*ethclient.Client, *types.Header) {
+
+	var (
+		client  *ethclient.Client
+		head    *types.Header
+		chainID *big.Int
+	)
+	for _, endpoint := range splitEndpoints(cfg.L1EthRpc) {
+		target := redactURL(endpoint)
+
+		ctxt, cancel := context.WithTimeout(ctx, doctorTimeout)
+		start := time.Now()
+		c, err := ethclient.DialContext(ctxt, endpoint)
+		if err != nil {
+			cancel()
+			report.add("l1_rpc", target, doctorFail, "%v", err)
+			continue
+		}
+		id, err := c.ChainID(ctxt)
+		if err != nil {
+			cancel()
+			report.add("l1_rpc", target, doctorFail, "%v", err)
+			continue
+		}
+		header, err := c.HeaderByNumber(ctxt, nil)
+		cancel()
+		if err != nil {
+			report.add("l1_rpc", target, doctorFail, "%v", err)
+			continue
+		}
+		latency := time.Since(start).Truncate(time.Millisecond)
+
+		if chainID != nil && id.Cmp(chainID) != 0 {
+			report.add("l1_rpc", target, doctorFail,
+				"chain_id=%v differs from chain_id=%v of the "+
+					"first provider", id, chainID)
+			continue
+		}
+		report.add("l1_rpc", target, doctorPass,
+			"chain_id=%v block=%v latency=%v", id, header.Number,
+			latency)
+
+		if client == nil {
+			client, head, chainID = c, header, id
+		}
+	}
+
+	return client, head
+}
+
+// diagnoseL2 checks that each L2 provider is reachable.
+func diagnoseL2(ctx context.Context, cfg Config, report *doctorReport) {
+	urls := splitEndpoints(cfg.L2EthRpc)
+	urls = append(urls, splitEndpoints(cfg.SecondaryL2EthRpc)...)
+
+	for _, endpoint := range urls {
+		target := redactURL(endpoint)
+
+		ctxt, cancel := context.WithTimeout(ctx, doctorTimeout)
+		start := time.Now()
+		client, err := l2ethclient.DialContext(ctxt, endpoint)
+		if err != nil {
+			cancel()
+			report.add("l2_rpc", target, doctorFail, "%v", err)
+			continue
+		}
+		header, err := client.HeaderByNumber(ctxt, nil)
+		cancel()
+		if err != nil {
+			report.add("l2_rpc", target, doctorFail, "%v", err)
+			continue
+		}
+
+		report.add("l2_rpc", target, doctorPass, "block=%v latency=%v",
+			header.Number, time.Since(start).Truncate(time.Millisecond))
+	}
+}
+
+// diagnoseContract checks that a contract is deployed at addrStr.
+func diagnoseContract(
+	ctx context.Context,
+	client *ethclient.Client,
+	name, addrStr string,
+	report *doctorReport,
+) {
+
+	addr, err := ParseAddress(addrStr)
+	if err != nil {
+		report.add("contract", name, doctorFail, "%v", err)
+		return
+	}
+
+	ctxt, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	code, err := client.CodeAt(ctxt, addr, nil)
+	switch {
+	case err != nil:
+		report.add("contract", name, doctorFail, "%v", err)
+	case len(code) == 0:
+		report.add("contract", name, doctorFail, "no code at %s",
+			addr.Hex())
+	default:
+		report.add("contract", name, doctorPass, "%s code_size=%d",
+			addr.Hex(), len(code))
+	}
+}
+
+// diagnoseSigner checks that a signer's key can be derived and produces
+// signatures recovering to its address, returning the address.
+func diagnoseSigner(
+	name, mnemonic, hdPath, privKeyStr string,
+	report *doctorReport,
+) (common.Address, bool) {
+
+	privKey, err := GetConfiguredPrivateKey(mnemonic, hdPath, privKeyStr)
+	if err != nil {
+		report.add("signer", name, doctorFail, "%v", err)
+		return common.Address{}, false
+	}
+	walletAddr := crypto.PubkeyToAddress(privKey.PublicKey)
+
+	if err := checkSigner(privKey); err != nil {
+		report.add("signer", name, doctorFail, "%s: %v",
+			walletAddr.Hex(), err)
+		return common.Address{}, false
+	}
+	report.add("signer", name, doctorPass, "%s", walletAddr.Hex())
+
+	return walletAddr, true
+}
+
+// checkSigner signs a digest using privKey, ensuring that the signature
+// recovers to its public key.
+func checkSigner(privKey *ecdsa.PrivateKey) error {
+	digest := crypto.Keccak256([]byte("batch-submitter doctor"))
+	sig, err := crypto.Sign(digest, privKey)
+	if err != nil {
+		return err
+	}
+
+	pubKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return err
+	}
+	if crypto.PubkeyToAddress(*pubKey) !=
+		crypto.PubkeyToAddress(privKey.PublicKey) {
+
+		return errors.New("signature does not recover to signer")
+	}
+
+	return nil
+}
+
+// diagnoseBalance checks a wallet's balance against the configured minimums,
+// and estimates the number of batch txs it can fund at the current gas
+// price.
+func diagnoseBalance(
+	ctx context.Context,
+	cfg Config,
+	client *ethclient.Client,
+	name string,
+	walletAddr common.Address,
+	report *doctorReport,
+) {
+
+	ctxt, cancel := context.WithTimeout(ctx, doctorTimeout)
+	defer cancel()
+
+	balance, err := client.BalanceAt(ctxt, walletAddr, nil)
+	if err != nil {
+		report.add("balance", name, doctorFail, "%v", err)
+		return
+	}
+	gasPrice, err := client.SuggestGasPrice(ctxt)
+	if err != nil {
+		report.add("balance", name, doctorFail, "%v", err)
+		return
+	}
+
+	gasLimit := cfg.MaxGasLimit
+	if gasLimit == 0 {
+		gasLimit = doctorBatchGasLimit
+	}
+	runway := balanceRunway(balance, gasPrice, gasLimit)
+
+	status := doctorPass
+	switch {
+	case belowThreshold(balance, floatEtherToWei(cfg.CriticalEtherBalance)):
+		status = doctorFail
+	case belowThreshold(balance, etherToWei(cfg.SafeMinimumEtherBalance)):
+		status = doctorWarn
+	}
+
+	report.add("balance", name, status,
+		"balance=%f ETH safe_minimum=%d ETH runway=~%d batch txs at "+
+			"%.2f gwei and gas_limit=%d", weiToEth64(balance),
+		cfg.SafeMinimumEtherBalance, runway, weiToGwei64(gasPrice),
+		gasLimit)
+}
+
+// balanceRunway returns the number of batch txs with the given gas limit that
+// balance can fund at gasPrice.
+func balanceRunway(balance, gasPrice *big.Int, gasLimit uint64) uint64 {
+	cost := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	if cost.Sign() == 0 {
+		return 0
+	}
+
+	return new(big.Int).Div(balance, cost).Uint64()
+}
+
+// diagnoseMetricsPort checks that the metrics server is able to listen on
+// its configured address.
+func diagnoseMetricsPort(hostname string, port uint64, report *doctorReport) {
+	addr := net.JoinHostPort(hostname, strconv.FormatUint(port, 10))
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		report.add("metrics_port", addr, doctorFail, "%v", err)
+		return
+	}
+	listener.Close()
+
+	report.add("metrics_port", addr, doctorPass, "available")
+}
+
+// submissionStateDir returns the directory in which the submission state is
+// recorded, or an empty string if it is recorded in a database or bucket.
+func submissionStateDir(cfg Config) string {
+	if cfg.SubmissionStateURL == "" {
+		return cfg.SubmissionStateDir
+	}
+
+	backend, u, err := queue.ParseBackendURL(cfg.SubmissionStateURL)
+	if err != nil || backend != queue.BackendFile {
+		return ""
+	}
+	return u.Path
+}
+
+// diagnoseDirectory checks that a persistence directory exists, or can be
+// created, and is writable.
+func diagnoseDirectory(name, dir string, report *doctorReport) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		report.add("directory", name, doctorFail, "%v", err)
+		return
+	}
+
+	probe, err := ioutil.TempFile(dir, "doctor-")
+	if err != nil {
+		report.add("directory", name, doctorFail, "%v", err)
+		return
+	}
+	probe.Close()
+	if err := os.Remove(probe.Name()); err != nil {
+		report.add("directory", name, doctorFail, "%v", err)
+		return
+	}
+
+	report.add("directory", name, doctorPass, "%s writable", dir)
+}
+
+// diagnoseClockSkew compares the local clock at now with the timestamp of the
+// latest L1 block. A block from the future means the local clock is behind,
+// while a stale block means either the provider is lagging or the local clock
+// is ahead.
+func diagnoseClockSkew(head *types.Header, now time.Time, report *doctorReport) {
+	blockTime := time.Unix(int64(head.Time), 0)
+	age := now.Sub(blockTime).Truncate(time.Second)
+
+	switch {
+	case age < -doctorMaxClockSkew:
+		report.add("clock_skew", "local", doctorFail,
+			"local clock is %v behind the latest L1 block", -age)
+	case age > doctorMaxHeadAge:
+		report.add("clock_skew", "local", doctorWarn,
+			"latest L1 block is %v old, the provider is lagging "+
+				"or the local clock is ahead", age)
+	default:
+		report.add("clock_skew", "local", doctorPass,
+			"latest L1 block is %v old", age)
+	}
+}
+
+// redactURL strips any credentials, path and query from a provider URL, as
+// these often embed API keys.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return "<invalid url>"
+	}
+	return u.Scheme + "://" + u.Host
+}
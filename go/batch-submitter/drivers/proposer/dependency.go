@@ -0,0 +1,85 @@
+package proposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/l2geth/log"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// ErrStateBatchAheadOfBatchData signals that a state batch would propose state
+// roots for L2 heights whose batch data has not confirmed in the CTC.
+var ErrStateBatchAheadOfBatchData = errors.New("state batch is ahead of " +
+	"confirmed batch data")
+
+// HeightSource reports the L2 height up to which batch data has confirmed on
+// L1, e.g. as tracked by a co-located sequencer.
+type HeightSource interface {
+	// ConfirmedHeight returns the exclusive end of the confirmed L2
+	// blocks, and false if the height is not yet known.
+	ConfirmedHeight() (uint64, bool)
+}
+
+// confirmedBatchHeight returns the exclusive end of the L2 blocks whose batch
+// data has reached NumConfirmations in the CTC, further bounded by the height
+// reported by SequencerHeight if configured.
+func (d *Driver) confirmedBatchHeight(ctx context.Context) (*big.Int, error) {
+	opts := &bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	}
+
+	// Read the CTC at the deepest block that has the required number of
+	// confirmations, counting the including block as the first.
+	if d.cfg.NumConfirmations > 1 {
+		head, err := d.cfg.L1Client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		depth := new(big.Int).SetUint64(d.cfg.NumConfirmations - 1)
+		if head.Number.Cmp(depth) >= 0 {
+			opts.BlockNumber = new(big.Int).Sub(head.Number, depth)
+		}
+	}
+
+	height, err := d.ctcContract.GetTotalElements(opts)
+	if err != nil {
+		return nil, err
+	}
+	height.Add(height, new(big.Int).SetUint64(d.cfg.BlockOffset))
+
+	if d.cfg.SequencerHeight != nil {
+		seqHeight, ok := d.cfg.SequencerHeight.ConfirmedHeight()
+		if ok && height.Uint64() > seqHeight {
+			height.SetUint64(seqHeight)
+		}
+	}
+
+	return height, nil
+}
+
+// checkBatchDependency asserts that the state roots in [start, end) only cover
+// L2 heights whose batch data has confirmed, recording a violation otherwise.
+func (d *Driver) checkBatchDependency(
+	ctx context.Context, start, end *big.Int) error {
+
+	confirmedHeight, err := d.confirmedBatchHeight(ctx)
+	if err != nil {
+		return err
+	}
+
+	if end.Cmp(confirmedHeight) > 0 {
+		d.metrics.StateBatchDependencyViolations.Inc()
+		log.Error(d.cfg.Name+" state batch ahead of confirmed batch data",
+			"start", start, "end", end,
+			"confirmed_height", confirmedHeight)
+		return fmt.Errorf("%w: end=%v confirmed_height=%v",
+			ErrStateBatchAheadOfBatchData, end, confirmedHeight)
+	}
+
+	return nil
+}
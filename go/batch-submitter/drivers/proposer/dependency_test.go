@@ -0,0 +1,255 @@
+package proposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// errRPCUnavailable is returned by ctcAPI when configured to fail.
+var errRPCUnavailable = errors.New("rpc unavailable")
+
+// ctcAPI serves the L1 head and the CTC's total elements over the eth
+// namespace, recording the block each call was made at.
+type ctcAPI struct {
+	mu            sync.Mutex
+	head          uint64
+	totalElements uint64
+	headerErr     error
+	callErr       error
+	callBlocks    []rpc.BlockNumber
+}
+
+// callArgs are the arguments of eth_call.
+type callArgs struct {
+	To   *common.Address `json:"to"`
+	Data hexutil.Bytes   `json:"data"`
+}
+
+func (a *ctcAPI) GetBlockByNumber(
+	rpc.BlockNumber, bool) (*types.Header, error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.headerErr != nil {
+		return nil, a.headerErr
+	}
+
+	return &types.Header{
+		Number:     new(big.Int).SetUint64(a.head),
+		Difficulty: new(big.Int),
+	}, nil
+}
+
+func (a *ctcAPI) Call(
+	_ callArgs, number rpc.BlockNumber) (hexutil.Bytes, error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.callBlocks = append(a.callBlocks, number)
+	if a.callErr != nil {
+		return nil, a.callErr
+	}
+
+	return common.BigToHash(
+		new(big.Int).SetUint64(a.totalElements),
+	).Bytes(), nil
+}
+
+// heightSource is a HeightSource reporting a fixed height.
+type heightSource struct {
+	height uint64
+	ok     bool
+}
+
+func (s heightSource) ConfirmedHeight() (uint64, bool) {
+	return s.height, s.ok
+}
+
+// newDependencyTestDriver initializes a Driver with the given name reading the
+// CTC served by api.
+func newDependencyTestDriver(
+	t *testing.T,
+	name string,
+	api *ctcAPI,
+	cfg Config,
+) *Driver {
+
+	server := rpc.NewServer()
+	require.Nil(t, server.RegisterName("eth", api))
+	t.Cleanup(server.Stop)
+
+	cfg.Name = name
+	cfg.L1Client = ethclient.NewClient(rpc.DialInProc(server))
+
+	ctcContract, err := ctc.NewCanonicalTransactionChain(
+		common.HexToAddress("0xc7c"), cfg.L1Client,
+	)
+	require.Nil(t, err)
+
+	return &Driver{
+		cfg:         cfg,
+		ctcContract: ctcContract,
+		metrics:     metrics.NewMetrics(cfg.Name),
+	}
+}
+
+// TestCheckBatchDependencyReady asserts that state roots are proposed for the
+// L2 heights whose batch data confirmed, offset by BlockOffset.
+func TestCheckBatchDependencyReady(t *testing.T) {
+	t.Parallel()
+
+	d := newDependencyTestDriver(t, "dependency_ready",
+		&ctcAPI{head: 20, totalElements: 10}, Config{BlockOffset: 1})
+
+	err := d.checkBatchDependency(
+		context.Background(), big.NewInt(5), big.NewInt(11),
+	)
+	require.Nil(t, err)
+	require.Equal(t, 0.0, testutil.ToFloat64(
+		d.metrics.StateBatchDependencyViolations,
+	))
+}
+
+// TestCheckBatchDependencyNotReady asserts that state roots ahead of the
+// confirmed batch data are rejected and recorded as a violation, whether
+// bounded by the CTC, its confirmations or a co-located sequencer.
+func TestCheckBatchDependencyNotReady(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		cfg          Config
+		end          int64
+		expCallBlock rpc.BlockNumber
+	}{
+		{
+			name:         "ahead of ctc",
+			cfg:          Config{BlockOffset: 1},
+			end:          12,
+			expCallBlock: rpc.LatestBlockNumber,
+		},
+		{
+			name: "ahead of sequencer",
+			cfg: Config{
+				BlockOffset:     1,
+				SequencerHeight: heightSource{height: 8, ok: true},
+			},
+			end:          9,
+			expCallBlock: rpc.LatestBlockNumber,
+		},
+		{
+			name: "ahead of confirmations",
+			cfg: Config{
+				BlockOffset:      1,
+				NumConfirmations: 3,
+			},
+			end:          12,
+			expCallBlock: 18,
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := &ctcAPI{head: 20, totalElements: 10}
+			d := newDependencyTestDriver(t,
+				fmt.Sprintf("dependency_not_ready_%d", i), api,
+				test.cfg)
+
+			err := d.checkBatchDependency(
+				context.Background(), big.NewInt(5),
+				big.NewInt(test.end),
+			)
+			require.True(t, errors.Is(
+				err, ErrStateBatchAheadOfBatchData,
+			))
+			require.Equal(t, 1.0, testutil.ToFloat64(
+				d.metrics.StateBatchDependencyViolations,
+			))
+			require.Equal(t, []rpc.BlockNumber{test.expCallBlock},
+				api.callBlocks)
+		})
+	}
+}
+
+// TestCheckBatchDependencyUnknownSequencerHeight asserts that a co-located
+// sequencer whose height is not yet known does not bound the state roots.
+func TestCheckBatchDependencyUnknownSequencerHeight(t *testing.T) {
+	t.Parallel()
+
+	d := newDependencyTestDriver(t, "dependency_unknown_sequencer",
+		&ctcAPI{head: 20, totalElements: 10}, Config{
+			BlockOffset:     1,
+			SequencerHeight: heightSource{height: 8},
+		})
+
+	err := d.checkBatchDependency(
+		context.Background(), big.NewInt(5), big.NewInt(11),
+	)
+	require.Nil(t, err)
+}
+
+// TestCheckBatchDependencyRPCErrors asserts that failing to read the L1 head
+// or the CTC is returned as is, rather than recorded as a violation.
+func TestCheckBatchDependencyRPCErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		api  *ctcAPI
+		cfg  Config
+	}{
+		{
+			name: "header",
+			api:  &ctcAPI{headerErr: errRPCUnavailable},
+			cfg:  Config{NumConfirmations: 3},
+		},
+		{
+			name: "total elements",
+			api:  &ctcAPI{callErr: errRPCUnavailable},
+		},
+	}
+
+	for i, test := range tests {
+		i, test := i, test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			d := newDependencyTestDriver(t,
+				fmt.Sprintf("dependency_rpc_error_%d", i), test.api,
+				test.cfg)
+
+			err := d.checkBatchDependency(
+				context.Background(), big.NewInt(5),
+				big.NewInt(11),
+			)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(),
+				errRPCUnavailable.Error())
+			require.False(t, errors.Is(
+				err, ErrStateBatchAheadOfBatchData,
+			))
+			require.Equal(t, 0.0, testutil.ToFloat64(
+				d.metrics.StateBatchDependencyViolations,
+			))
+		})
+	}
+}
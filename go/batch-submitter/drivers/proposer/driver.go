@@ -3,25 +3,32 @@ package proposer
 import (
 	"context"
 	"crypto/ecdsa"
-	"fmt"
 	"math/big"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/scc"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/quota"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/wallet"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
 	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
 	"github.com/ethereum-optimism/optimism/l2geth/log"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 // stateRootSize is the size in bytes of a state root.
 const stateRootSize = 32
 
+// proposerName is the name the SCC resolves the sender of state batches by.
+const proposerName = "OVM_Proposer"
+
 var bigOne = new(big.Int).SetUint64(1) //nolint:unused
 
 type Config struct {
@@ -34,17 +41,59 @@ type Config struct {
 	CTCAddr     common.Address
 	ChainID     *big.Int
 	PrivKey     *ecdsa.PrivateKey
+
+	// RotationKeys are the keys of the wallets batch txs may be submitted
+	// from once the wallet of PrivKey is rotated out, in rotation order.
+	RotationKeys []*ecdsa.PrivateKey
+
+	// DryRun, if true, builds and signs batch txs without publishing them.
+	DryRun bool
+
+	// Publisher, if non-nil, publishes signed batch txs in place of
+	// L1Client, e.g. to submit them to a private relay.
+	Publisher txmgr.Publisher
+
+	// NumConfirmations is the number of confirmations the batch data of an
+	// L2 block must reach in the CTC, counting the including block as the
+	// first, before its state root is proposed.
+	NumConfirmations uint64
+
+	// SequencerHeight, if non-nil, further bounds the proposed state roots
+	// by the height confirmed by a co-located sequencer.
+	SequencerHeight HeightSource
+
+	// MemoryQuota, if non-nil, bounds the bytes of state roots held by the
+	// batches being built at once. A batch is cut short once the quota is
+	// exhausted, and submitted early with the state roots fetched so far.
+	MemoryQuota *quota.Memory
+
+	// RPCQuota, if non-nil, bounds the rate of L2 block requests.
+	RPCQuota *quota.RPC
+
+	// RPCTimeout, if non-zero, bounds each L2 block request.
+	RPCTimeout time.Duration
 }
 
 type Driver struct {
 	cfg         Config
 	sccContract *scc.StateCommitmentChain
+	sccABI      *abi.ABI
 	ctcContract *ctc.CanonicalTransactionChain
-	walletAddr  common.Address
+	wallets     *wallet.Set
 	metrics     *metrics.Metrics
+
+	// maxTxSize is the max size of a batch tx, initialized from
+	// cfg.MaxTxSize and adjustable at runtime.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	maxTxSize uint64
 }
 
 func NewDriver(cfg Config) (*Driver, error) {
+	if cfg.Publisher == nil {
+		cfg.Publisher = cfg.L1Client
+	}
+
 	sccContract, err := scc.NewStateCommitmentChain(
 		cfg.SCCAddr, cfg.L1Client,
 	)
@@ -52,6 +101,11 @@ func NewDriver(cfg Config) (*Driver, error) {
 		return nil, err
 	}
 
+	sccABI, err := scc.StateCommitmentChainMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
 	ctcContract, err := ctc.NewCanonicalTransactionChain(
 		cfg.CTCAddr, cfg.L1Client,
 	)
@@ -59,14 +113,14 @@ func NewDriver(cfg Config) (*Driver, error) {
 		return nil, err
 	}
 
-	walletAddr := crypto.PubkeyToAddress(cfg.PrivKey.PublicKey)
-
 	return &Driver{
 		cfg:         cfg,
 		sccContract: sccContract,
+		sccABI:      sccABI,
 		ctcContract: ctcContract,
-		walletAddr:  walletAddr,
+		wallets:     wallet.NewSet(cfg.PrivKey, cfg.RotationKeys...),
 		metrics:     metrics.NewMetrics(cfg.Name),
+		maxTxSize:   cfg.MaxTxSize,
 	}, nil
 }
 
@@ -77,7 +131,37 @@ func (d *Driver) Name() string {
 
 // WalletAddr is the wallet address used to pay for batch transaction fees.
 func (d *Driver) WalletAddr() common.Address {
-	return d.walletAddr
+	return d.wallets.Addr()
+}
+
+// Wallets returns the wallets batch txs may be submitted from.
+func (d *Driver) Wallets() *wallet.Set {
+	return d.wallets
+}
+
+// AuthorizedSender returns the sender the SCC accepts batch txs from, i.e. the
+// address its AddressManager resolves as OVM_Proposer.
+func (d *Driver) AuthorizedSender(ctx context.Context) (common.Address, error) {
+	return d.sccContract.Resolve(&bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	}, proposerName)
+}
+
+// PingL2 returns an error if the L2 backend is unreachable.
+func (d *Driver) PingL2(ctx context.Context) error {
+	_, err := d.cfg.L2Client.HeaderByNumber(ctx, nil)
+	return err
+}
+
+// MaxTxSize returns the max size of a batch tx.
+func (d *Driver) MaxTxSize() uint64 {
+	return atomic.LoadUint64(&d.maxTxSize)
+}
+
+// SetMaxTxSize adjusts the max size of subsequently built batch txs.
+func (d *Driver) SetMaxTxSize(size uint64) {
+	atomic.StoreUint64(&d.maxTxSize, size)
 }
 
 // Metrics returns the subservice telemetry object.
@@ -85,6 +169,21 @@ func (d *Driver) Metrics() *metrics.Metrics {
 	return d.metrics
 }
 
+// DriverState is a snapshot of the internal state of the driver.
+type DriverState struct {
+	MaxTxSize        uint64 `json:"max_tx_size"`
+	MemoryQuotaInUse uint64 `json:"memory_quota_in_use"`
+}
+
+// DebugState returns a snapshot of the internal state of the driver, as
+// reported by the debug server.
+func (d *Driver) DebugState() interface{} {
+	return DriverState{
+		MaxTxSize:        d.MaxTxSize(),
+		MemoryQuotaInUse: d.cfg.MemoryQuota.InUse(),
+	}
+}
+
 // GetBatchBlockRange returns the start and end L2 block heights that need to be
 // processed. Note that the end value is *exclusive*, therefore if the returned
 // values are identical nothing needs to be processed.
@@ -102,18 +201,21 @@ func (d *Driver) GetBatchBlockRange(
 	}
 	start.Add(start, blockOffset)
 
-	end, err := d.ctcContract.GetTotalElements(&bind.CallOpts{
-		Pending: false,
-		Context: ctx,
-	})
+	// Only propose state roots for L2 heights whose batch data has
+	// confirmed, such that state batches never get ahead of the CTC.
+	end, err := d.confirmedBatchHeight(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
-	end.Add(end, blockOffset)
 
+	// State roots may already have been proposed beyond the confirmed
+	// batch data, e.g. if the CTC was reorged, in which case there is
+	// nothing to propose until the batch data confirms again.
 	if start.Cmp(end) > 0 {
-		return nil, nil, fmt.Errorf("invalid range, "+
-			"end(%v) < start(%v)", end, start)
+		d.metrics.StateBatchDependencyViolations.Inc()
+		log.Warn(d.cfg.Name+" proposed state ahead of confirmed batch data",
+			"start", start, "confirmed_height", end)
+		return start, start, nil
 	}
 
 	return start, end, nil
@@ -127,36 +229,77 @@ func (d *Driver) SubmitBatchTx(
 	start, end, nonce, gasPrice *big.Int) (*types.Transaction, error) {
 
 	name := d.cfg.Name
+	maxTxSize := d.MaxTxSize()
 
 	batchTxBuildStart := time.Now()
 
 	var (
 		stateRoots         [][stateRootSize]byte
 		totalStateRootSize uint64
+		exhausted          bool
 	)
+	defer func() {
+		d.cfg.MemoryQuota.Release(totalStateRootSize)
+		d.metrics.MemoryQuotaInUse.Set(float64(d.cfg.MemoryQuota.InUse()))
+	}()
 	for i := new(big.Int).Set(start); i.Cmp(end) < 0; i.Add(i, bigOne) {
 		// Consume state roots until reach our maximum tx size.
-		if totalStateRootSize+stateRootSize > d.cfg.MaxTxSize {
+		if totalStateRootSize+stateRootSize > maxTxSize {
+			break
+		}
+		if !d.cfg.MemoryQuota.TryAcquire(stateRootSize) {
+			d.metrics.MemoryQuotaExhausted.Inc()
+			exhausted = true
+			log.Info(name+" memory quota exhausted, submitting early",
+				"num_state_roots", len(stateRoots))
 			break
 		}
+		totalStateRootSize += stateRootSize
+		d.metrics.MemoryQuotaInUse.Set(float64(d.cfg.MemoryQuota.InUse()))
 
-		block, err := d.cfg.L2Client.BlockByNumber(ctx, i)
+		if err := d.waitRPCQuota(ctx); err != nil {
+			return nil, err
+		}
+		block, err := d.blockByNumber(ctx, i)
 		if err != nil {
 			return nil, err
 		}
 
-		totalStateRootSize += stateRootSize
 		stateRoots = append(stateRoots, block.Root())
 	}
 
+	// If the quota is held entirely by other batches, there is nothing to
+	// submit until they are released.
+	if exhausted && len(stateRoots) == 0 {
+		return nil, quota.ErrMemoryExhausted
+	}
+
 	batchTxBuildTime := float64(time.Since(batchTxBuildStart) / time.Millisecond)
 	d.metrics.BatchTxBuildTime.Set(batchTxBuildTime)
+	d.metrics.BatchBuildTime.Observe(batchTxBuildTime)
 	d.metrics.NumElementsPerBatch.Observe(float64(len(stateRoots)))
+	if maxTxSize > 0 {
+		d.metrics.BatchSizeHeadroom.Set(
+			float64(maxTxSize - totalStateRootSize),
+		)
+		d.metrics.BatchSizeUtilization.Observe(
+			float64(totalStateRootSize) / float64(maxTxSize),
+		)
+	}
 
 	log.Info(name+" batch constructed", "num_state_roots", len(stateRoots))
 
+	// Since this method is invoked on each fee bump, checking here ensures
+	// that state roots are never proposed for batch data that was reorged
+	// out while the state batch was pending.
+	batchEnd := new(big.Int).SetUint64(uint64(len(stateRoots)))
+	batchEnd.Add(batchEnd, start)
+	if err := d.checkBatchDependency(ctx, start, batchEnd); err != nil {
+		return nil, err
+	}
+
 	opts, err := bind.NewKeyedTransactorWithChainID(
-		d.cfg.PrivKey, d.cfg.ChainID,
+		d.wallets.Key(), d.cfg.ChainID,
 	)
 	if err != nil {
 		return nil, err
@@ -164,9 +307,78 @@ func (d *Driver) SubmitBatchTx(
 	opts.Nonce = nonce
 	opts.Context = ctx
 	opts.GasPrice = gasPrice
+	opts.NoSend = true
 
 	blockOffset := new(big.Int).SetUint64(d.cfg.BlockOffset)
 	offsetStartsAtIndex := new(big.Int).Sub(start, blockOffset)
 
-	return d.sccContract.AppendStateBatch(opts, stateRoots, offsetStartsAtIndex)
+	// Sign and publish separately, so that the duration of each can be
+	// measured independently.
+	batchTxSignStart := time.Now()
+	tx, err := d.sccContract.AppendStateBatch(
+		opts, stateRoots, offsetStartsAtIndex,
+	)
+	if err != nil {
+		return nil, err
+	}
+	d.metrics.BatchSignTime.Observe(
+		float64(time.Since(batchTxSignStart) / time.Millisecond),
+	)
+
+	if d.cfg.DryRun {
+		return tx, nil
+	}
+
+	if err := d.cfg.Publisher.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// blockByNumber returns the L2 block at the given height, bounded by the RPC
+// timeout.
+func (d *Driver) blockByNumber(
+	ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+	if d.cfg.RPCTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.cfg.RPCTimeout)
+		defer cancel()
+	}
+
+	return d.cfg.L2Client.BlockByNumber(ctx, number)
+}
+
+// L2BlockHash returns the hash of the L2 block at number.
+func (d *Driver) L2BlockHash(
+	ctx context.Context, number *big.Int) (common.Hash, error) {
+
+	if err := d.waitRPCQuota(ctx); err != nil {
+		return common.Hash{}, err
+	}
+	block, err := d.blockByNumber(ctx, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.Hash(block.Hash()), nil
+}
+
+// waitRPCQuota blocks until an L2 request may be made within the RPC quota.
+func (d *Driver) waitRPCQuota(ctx context.Context) error {
+	delay := d.cfg.RPCQuota.Reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	d.metrics.RPCQuotaThrottled.Inc()
+	d.metrics.RPCQuotaWaiting.Inc()
+	defer d.metrics.RPCQuotaWaiting.Dec()
+
+	if err := quota.Sleep(ctx, delay); err != nil {
+		return err
+	}
+	d.metrics.RPCQuotaWaitTime.Add(float64(delay / time.Millisecond))
+
+	return nil
 }
@@ -0,0 +1,106 @@
+package proposer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/scc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// appendStateBatchMethod is the name of the SCC method called by batch txs.
+const appendStateBatchMethod = "appendStateBatch"
+
+// ErrNoStateBatchAppended signals that a receipt contains no
+// StateBatchAppended event emitted by the SCC.
+var ErrNoStateBatchAppended = errors.New("receipt has no StateBatchAppended " +
+	"event")
+
+// InclusionProof proves the inclusion of each state root proposed by the
+// confirmed batch tx of receipt within the batch root committed by the SCC.
+// The batch header is taken from the emitted StateBatchAppended event, and the
+// state roots from the batch tx's calldata, such that the proof reflects
+// exactly what was committed on L1.
+func (d *Driver) InclusionProof(
+	ctx context.Context,
+	receipt *types.Receipt,
+) (*proofs.BatchProof, error) {
+
+	var event *scc.StateCommitmentChainStateBatchAppended
+	for _, l := range receipt.Logs {
+		if l.Address != d.cfg.SCCAddr {
+			continue
+		}
+		appended, err := d.sccContract.ParseStateBatchAppended(*l)
+		if err != nil {
+			continue
+		}
+		event = appended
+		break
+	}
+	if event == nil {
+		return nil, ErrNoStateBatchAppended
+	}
+
+	tx, _, err := d.cfg.L1Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	stateRoots, err := d.unpackStateRoots(tx.Data())
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := proofs.NewBatchProof(proofs.BatchHeader{
+		BatchIndex:        event.BatchIndex.Uint64(),
+		BatchRoot:         event.BatchRoot,
+		BatchSize:         event.BatchSize.Uint64(),
+		PrevTotalElements: event.PrevTotalElements.Uint64(),
+		ExtraData:         event.ExtraData,
+	}, stateRoots)
+	if err != nil {
+		return nil, err
+	}
+	proof.TxHash = receipt.TxHash
+	proof.BlockNumber = receipt.BlockNumber.Uint64()
+
+	return proof, nil
+}
+
+// unpackStateRoots decodes the state roots proposed by appendStateBatch
+// calldata.
+func (d *Driver) unpackStateRoots(data []byte) ([]common.Hash, error) {
+	if len(data) < 4 {
+		return nil, fmt.Errorf("batch tx calldata too short: %d bytes",
+			len(data))
+	}
+
+	method, err := d.sccABI.MethodById(data[:4])
+	if err != nil {
+		return nil, err
+	}
+	if method.Name != appendStateBatchMethod {
+		return nil, fmt.Errorf("batch tx calls %s, expected %s",
+			method.Name, appendStateBatchMethod)
+	}
+
+	args, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	batch, ok := args[0].([][stateRootSize]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s batch type %T",
+			appendStateBatchMethod, args[0])
+	}
+
+	stateRoots := make([]common.Hash, 0, len(batch))
+	for _, stateRoot := range batch {
+		stateRoots = append(stateRoots, stateRoot)
+	}
+
+	return stateRoots, nil
+}
@@ -0,0 +1,44 @@
+package proposer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// selfTestPayload is the dummy payload signed by the self-test.
+var selfTestPayload = crypto.Keccak256([]byte("batch-submitter self-test"))
+
+// SelfTest verifies the driver's dependencies before any batch is submitted.
+// A dummy payload is signed with the proposer key, the SCC is queried, and the
+// latest L2 block is fetched. Nothing is published.
+func (d *Driver) SelfTest(ctx context.Context) error {
+	privKey, walletAddr := d.wallets.Key(), d.wallets.Addr()
+	sig, err := crypto.Sign(selfTestPayload, privKey)
+	if err != nil {
+		return fmt.Errorf("unable to sign self-test payload: %w", err)
+	}
+	pubKey, err := crypto.SigToPub(selfTestPayload, sig)
+	if err != nil {
+		return fmt.Errorf("unable to recover self-test signer: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != walletAddr {
+		return fmt.Errorf("self-test signer %s does not match wallet %s",
+			signer, walletAddr)
+	}
+
+	_, err = d.sccContract.GetTotalElements(&bind.CallOpts{
+		Context: ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get total elements: %w", err)
+	}
+
+	if _, err := d.cfg.L2Client.BlockByNumber(ctx, nil); err != nil {
+		return fmt.Errorf("unable to fetch latest L2 block: %w", err)
+	}
+
+	return nil
+}
@@ -3,6 +3,7 @@ package sequencer
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
 )
@@ -12,8 +13,81 @@ var (
 	// BatchContext that specifies a total of zero txs.
 	ErrBlockWithInvalidContext = errors.New("attempted to generate batch " +
 		"context with 0 queued and 0 sequenced txs")
+
+	// ErrBatchContextNotMonotonic signals that a generated BatchContext has
+	// a timestamp that precedes the last timestamp recorded by the CTC, or
+	// the timestamp of the preceding context.
+	ErrBatchContextNotMonotonic = errors.New("batch context timestamp " +
+		"precedes prior timestamp")
+
+	// ErrBatchContextInFuture signals that a generated BatchContext has a
+	// timestamp ahead of the L1 timestamp at which the batch is expected
+	// to be included.
+	ErrBatchContextInFuture = errors.New("batch context timestamp is " +
+		"ahead of L1 timestamp")
+
+	// ErrBatchContextTooOld signals that a generated BatchContext would
+	// fall outside of the allowed drift from the L1 timestamp by the time
+	// the batch is expected to be included.
+	ErrBatchContextTooOld = errors.New("batch context timestamp exceeds " +
+		"maximum drift from L1 timestamp")
+
+	// ErrContextTooLarge signals that a run of consecutive queued txs
+	// exceeds the max number of txs of a single BatchContext. Unlike runs
+	// of sequencer txs, it cannot be split across contexts.
+	ErrContextTooLarge = errors.New("queued txs exceed max txs per " +
+		"batch context")
+
+	// ErrTooManyContexts signals that a batch exceeds the max number of
+	// contexts of a single batch.
+	ErrTooManyContexts = errors.New("batch exceeds max contexts per batch")
+
+	// ErrMalformedBlock signals an L2 block from which no BatchElement can
+	// be constructed, e.g. as it holds more than one tx or a tx of an
+	// unexpected type.
+	ErrMalformedBlock = errors.New("malformed l2 block")
+)
+
+const (
+	// MaxContextTxs is the max number of txs of a single BatchContext,
+	// bounded by the 3-byte tx counts of its encoding.
+	MaxContextTxs = 1<<24 - 1
+
+	// MaxBatchContexts is the max number of contexts of a batch, bounded
+	// by its 3-byte num_contexts, which also counts the version marker of
+	// versioned encodings.
+	MaxBatchContexts = 1<<24 - 2
 )
 
+// BatchLimits bounds the contexts of a batch. A zero limit defaults to the max
+// allowed by the encoding.
+type BatchLimits struct {
+	// MaxTxsPerContext is the max number of sequenced and queued txs of a
+	// single context. Runs of sequencer txs sharing a timestamp and block
+	// number are split across as many contexts as needed.
+	MaxTxsPerContext uint64
+
+	// MaxContextsPerBatch is the max number of contexts of a batch.
+	MaxContextsPerBatch uint64
+}
+
+// maxTxsPerContext returns MaxTxsPerContext, or MaxContextTxs if unset.
+func (l BatchLimits) maxTxsPerContext() uint64 {
+	if l.MaxTxsPerContext == 0 {
+		return MaxContextTxs
+	}
+	return l.MaxTxsPerContext
+}
+
+// maxContextsPerBatch returns MaxContextsPerBatch, or MaxBatchContexts if
+// unset.
+func (l BatchLimits) maxContextsPerBatch() uint64 {
+	if l.MaxContextsPerBatch == 0 {
+		return MaxBatchContexts
+	}
+	return l.MaxContextsPerBatch
+}
+
 // BatchElement reflects the contents of an atomic update to the L2 state.
 // Currently, each BatchElement is constructed from a single block containing
 // exactly one tx.
@@ -28,6 +102,12 @@ type BatchElement struct {
 	//
 	// NOTE: This field will only be populated for sequencer txs.
 	Tx *CachedTx
+
+	// QueueIndex is the index of the queue element executed by a queued
+	// tx, if reported by the L2 node.
+	//
+	// NOTE: This field will only be populated for queued txs.
+	QueueIndex *uint64
 }
 
 // IsSequencerTx returns true if this batch contains a tx that needs to be
@@ -39,31 +119,64 @@ func (b *BatchElement) IsSequencerTx() bool {
 // BatchElementFromBlock constructs a BatchElement from a single L2 block. This
 // method expects that there is exactly ONE tx per block. The returned
 // BatchElement will reflect whether or not the lone tx is a sequencer tx or a
-// queued tx.
+// queued tx. It panics if the block is malformed, see ParseBatchElement.
 func BatchElementFromBlock(block *l2types.Block) BatchElement {
+	batchElement, err := ParseBatchElement(block)
+	if err != nil {
+		panic(fmt.Sprintf("attempting to create batch element from "+
+			"block %d: %v", block.Number(), err))
+	}
+
+	return batchElement
+}
+
+// ParseBatchElement constructs a BatchElement from a single L2 block, returning
+// an error wrapping ErrMalformedBlock if the block does not hold exactly one tx
+// whose L1 metadata can be batched.
+func ParseBatchElement(block *l2types.Block) (BatchElement, error) {
 	txs := block.Transactions()
 	if len(txs) != 1 {
-		panic(fmt.Sprintf("attempting to create batch element from block %d, "+
-			"found %d txs instead of 1", block.Number(), len(txs)))
+		return BatchElement{}, fmt.Errorf("%w: found %d txs instead of 1",
+			ErrMalformedBlock, len(txs))
 	}
 
 	tx := txs[0]
 
 	// Extract L2 metadata.
+	if tx.L1BlockNumber() == nil {
+		return BatchElement{}, fmt.Errorf("%w: tx %s has no l1 block "+
+			"number", ErrMalformedBlock, tx.Hash().Hex())
+	}
 	l1BlockNumber := tx.L1BlockNumber().Uint64()
-	isSequencerTx := tx.QueueOrigin() == l2types.QueueOriginSequencer
 
-	// Only include sequencer txs in the returned BatchElement.
-	var cachedTx *CachedTx
+	var isSequencerTx bool
+	switch origin := tx.QueueOrigin(); origin {
+	case l2types.QueueOriginSequencer:
+		isSequencerTx = true
+	case l2types.QueueOriginL1ToL2:
+	default:
+		return BatchElement{}, fmt.Errorf("%w: tx %s has unknown queue "+
+			"origin %d", ErrMalformedBlock, tx.Hash().Hex(), origin)
+	}
+
+	// Only include sequencer txs in the returned BatchElement, and the
+	// queue index of queued txs.
+	var (
+		cachedTx   *CachedTx
+		queueIndex *uint64
+	)
 	if isSequencerTx {
 		cachedTx = NewCachedTx(tx)
+	} else {
+		queueIndex = tx.GetMeta().QueueIndex
 	}
 
 	return BatchElement{
 		Timestamp:   block.Time(),
 		BlockNumber: l1BlockNumber,
 		Tx:          cachedTx,
-	}
+		QueueIndex:  queueIndex,
+	}, nil
 }
 
 type groupedBlock struct {
@@ -72,14 +185,41 @@ type groupedBlock struct {
 }
 
 // GenSequencerBatchParams generates a valid AppendSequencerBatchParams from a
-// list of BatchElements. The BatchElements are assumed to be ordered in
-// ascending order by L2 block height.
+// list of BatchElements, within the given limits. The BatchElements are assumed
+// to be ordered in ascending order by L2 block height.
 func GenSequencerBatchParams(
 	shouldStartAtElement uint64,
 	blockOffset uint64,
 	batch []BatchElement,
+	limits BatchLimits,
 ) (*AppendSequencerBatchParams, error) {
 
+	contexts, txs, err := genBatchContexts(batch, limits.maxTxsPerContext())
+	if err != nil {
+		return nil, err
+	}
+
+	maxContexts := limits.maxContextsPerBatch()
+	if uint64(len(contexts)) > maxContexts {
+		return nil, fmt.Errorf("%w: num_contexts=%d max=%d",
+			ErrTooManyContexts, len(contexts), maxContexts)
+	}
+
+	return &AppendSequencerBatchParams{
+		ShouldStartAtElement:  shouldStartAtElement - blockOffset,
+		TotalElementsToAppend: uint64(len(batch)),
+		Contexts:              contexts,
+		Txs:                   txs,
+	}, nil
+}
+
+// genBatchContexts groups the BatchElements into contexts of at most maxTxs
+// txs each, returning the contexts along with the sequencer txs they cover.
+func genBatchContexts(
+	batch []BatchElement,
+	maxTxs uint64,
+) ([]BatchContext, []*CachedTx, error) {
+
 	var (
 		contexts               []BatchContext
 		groupedBlocks          []groupedBlock
@@ -147,46 +287,100 @@ func GenSequencerBatchParams(
 		lastBlockNumber = el.BlockNumber
 	}
 
-	// For each group, construct the resulting BatchContext.
+	// For each group, construct the resulting BatchContexts.
 	for _, block := range groupedBlocks {
-		numSequencedTxs := uint64(len(block.sequenced))
-		numSubsequentQueueTxs := uint64(len(block.queued))
-
 		// Ensure at least one tx was included in this group.
-		if numSequencedTxs == 0 && numSubsequentQueueTxs == 0 {
-			return nil, ErrBlockWithInvalidContext
+		if len(block.sequenced) == 0 && len(block.queued) == 0 {
+			return nil, nil, ErrBlockWithInvalidContext
 		}
 
-		// Compute the timestamp and block number from for the batch
-		// using either the earliest sequenced tx or the earliest queued
-		// tx. If a batch has a sequencer tx it is given preference,
-		// since it is guaranteed to be the earliest item in the group.
-		// Otherwise, we fallback to the earliest queued tx since it was
-		// the very first item.
-		var (
-			timestamp   uint64
-			blockNumber uint64
-		)
-		if numSequencedTxs > 0 {
-			timestamp = block.sequenced[0].Timestamp
-			blockNumber = block.sequenced[0].BlockNumber
-		} else {
-			timestamp = block.queued[0].Timestamp
-			blockNumber = block.queued[0].BlockNumber
+		// Since consecutive queued txs are never split, a group is
+		// split by peeling off contexts of sequencer txs until the
+		// rest fits within a single context. Only as many sequencer
+		// txs as necessary are peeled off, such that the queued txs
+		// remain preceded by sequencer txs where possible.
+		if uint64(len(block.queued)) > maxTxs {
+			return nil, nil, fmt.Errorf("%w: num_queued_txs=%d max=%d",
+				ErrContextTooLarge, len(block.queued), maxTxs)
 		}
+		sequenced := block.sequenced
+		for uint64(len(sequenced)+len(block.queued)) > maxTxs {
+			n := uint64(len(sequenced)+len(block.queued)) - maxTxs
+			if n > maxTxs {
+				n = maxTxs
+			}
+			contexts = append(contexts, newBatchContext(
+				sequenced[:n], nil,
+			))
+			sequenced = sequenced[n:]
+		}
+		contexts = append(contexts, newBatchContext(
+			sequenced, block.queued,
+		))
+	}
+
+	return contexts, txs, nil
+}
 
-		contexts = append(contexts, BatchContext{
-			NumSequencedTxs:       numSequencedTxs,
-			NumSubsequentQueueTxs: numSubsequentQueueTxs,
-			Timestamp:             timestamp,
-			BlockNumber:           blockNumber,
-		})
+// newBatchContext constructs the BatchContext of the given sequenced txs,
+// followed by the given queued txs, at least one of which must be non-empty.
+func newBatchContext(sequenced, queued []BatchElement) BatchContext {
+	// Compute the timestamp and block number from for the batch using
+	// either the earliest sequenced tx or the earliest queued tx. If a
+	// batch has a sequencer tx it is given preference, since it is
+	// guaranteed to be the earliest item in the group. Otherwise, we
+	// fallback to the earliest queued tx since it was the very first item.
+	first := queued
+	if len(sequenced) > 0 {
+		first = sequenced
 	}
 
-	return &AppendSequencerBatchParams{
-		ShouldStartAtElement:  shouldStartAtElement - blockOffset,
-		TotalElementsToAppend: uint64(len(batch)),
-		Contexts:              contexts,
-		Txs:                   txs,
-	}, nil
+	return BatchContext{
+		NumSequencedTxs:       uint64(len(sequenced)),
+		NumSubsequentQueueTxs: uint64(len(queued)),
+		Timestamp:             first[0].Timestamp,
+		BlockNumber:           first[0].BlockNumber,
+	}
+}
+
+// ValidateContextDrift asserts that the timestamps of the passed contexts are
+// non-decreasing, beginning from lastTimestamp, and that each lies at or
+// before, and within maxDrift of, the L1 timestamp at which the batch is
+// expected to be included. The expected inclusion time is computed as
+// l1Timestamp + inclusionDelay and is the only reference for both bounds, so
+// that batches which would only become invalid while waiting in the mempool
+// are rejected before being published.
+func ValidateContextDrift(
+	contexts []BatchContext,
+	lastTimestamp uint64,
+	l1Timestamp uint64,
+	maxDrift time.Duration,
+	inclusionDelay time.Duration,
+) error {
+
+	maxDriftSecs := uint64(maxDrift / time.Second)
+	inclusionTimestamp := l1Timestamp + uint64(inclusionDelay/time.Second)
+
+	prevTimestamp := lastTimestamp
+	for i, context := range contexts {
+		if context.Timestamp < prevTimestamp {
+			return fmt.Errorf("context %d: %w, timestamp=%d prev=%d",
+				i, ErrBatchContextNotMonotonic, context.Timestamp,
+				prevTimestamp)
+		}
+		if context.Timestamp > inclusionTimestamp {
+			return fmt.Errorf("context %d: %w, timestamp=%d "+
+				"inclusion=%d", i, ErrBatchContextInFuture,
+				context.Timestamp, inclusionTimestamp)
+		}
+		if context.Timestamp+maxDriftSecs < inclusionTimestamp {
+			return fmt.Errorf("context %d: %w, timestamp=%d "+
+				"inclusion=%d max_drift=%v", i,
+				ErrBatchContextTooOld, context.Timestamp,
+				inclusionTimestamp, maxDrift)
+		}
+		prevTimestamp = context.Timestamp
+	}
+
+	return nil
 }
@@ -1,8 +1,10 @@
 package sequencer_test
 
 import (
+	"errors"
 	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
 	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
@@ -47,3 +49,148 @@ func TestBatchElementFromBlock(t *testing.T) {
 	require.False(t, element.IsSequencerTx())
 	require.Nil(t, element.Tx)
 }
+
+// TestValidateContextDrift asserts that ValidateContextDrift enforces
+// monotonic timestamps, and that contexts are neither ahead of nor beyond the
+// maximum drift from the expected L1 inclusion time.
+func TestValidateContextDrift(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		timestamps    []uint64
+		lastTimestamp uint64
+		l1Timestamp   uint64
+		expErr        error
+	}{
+		{
+			name:          "valid contexts",
+			timestamps:    []uint64{100, 100, 110},
+			lastTimestamp: 90,
+			l1Timestamp:   120,
+			expErr:        nil,
+		},
+		{
+			name:          "precedes last timestamp",
+			timestamps:    []uint64{100},
+			lastTimestamp: 101,
+			l1Timestamp:   120,
+			expErr:        sequencer.ErrBatchContextNotMonotonic,
+		},
+		{
+			name:          "not monotonic",
+			timestamps:    []uint64{110, 100},
+			lastTimestamp: 90,
+			l1Timestamp:   120,
+			expErr:        sequencer.ErrBatchContextNotMonotonic,
+		},
+		{
+			name:          "ahead of l1 before inclusion",
+			timestamps:    []uint64{125},
+			lastTimestamp: 90,
+			l1Timestamp:   120,
+			expErr:        nil,
+		},
+		{
+			name:          "ahead of inclusion",
+			timestamps:    []uint64{131},
+			lastTimestamp: 90,
+			l1Timestamp:   120,
+			expErr:        sequencer.ErrBatchContextInFuture,
+		},
+		{
+			name:          "stale at inclusion",
+			timestamps:    []uint64{100},
+			lastTimestamp: 90,
+			l1Timestamp:   151,
+			expErr:        sequencer.ErrBatchContextTooOld,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var contexts []sequencer.BatchContext
+			for _, timestamp := range test.timestamps {
+				contexts = append(contexts, sequencer.BatchContext{
+					NumSequencedTxs: 1,
+					Timestamp:       timestamp,
+				})
+			}
+
+			// With a max drift of 60s and a 10s inclusion delay, a
+			// context at 100 is valid until the L1 timestamp
+			// exceeds 160 - 10 = 150.
+			err := sequencer.ValidateContextDrift(
+				contexts, test.lastTimestamp, test.l1Timestamp,
+				time.Minute, 10*time.Second,
+			)
+			if test.expErr == nil {
+				require.Nil(t, err)
+			} else {
+				require.True(t, errors.Is(err, test.expErr))
+			}
+		})
+	}
+}
+
+// TestGenSequencerBatchParamsLimits asserts that runs of sequencer txs too large
+// for a single context are split across contexts, and that batches which cannot
+// be split within the limits are rejected.
+func TestGenSequencerBatchParamsLimits(t *testing.T) {
+	t.Parallel()
+
+	elements := []sequencer.BatchElement{
+		sequencedElement(100, 10),
+		sequencedElement(100, 10),
+		sequencedElement(100, 10),
+		sequencedElement(100, 10),
+		sequencedElement(100, 10),
+		queuedElement(101, 11),
+		queuedElement(101, 11),
+		sequencedElement(102, 11),
+	}
+
+	params, err := sequencer.GenSequencerBatchParams(
+		10, 0, elements, sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	require.Equal(t, []sequencer.BatchContext{
+		{
+			NumSequencedTxs:       5,
+			NumSubsequentQueueTxs: 2,
+			Timestamp:             100,
+			BlockNumber:           10,
+		},
+		{NumSequencedTxs: 1, Timestamp: 102, BlockNumber: 11},
+	}, params.Contexts)
+
+	// The first group is split, keeping its queued txs together.
+	params, err = sequencer.GenSequencerBatchParams(
+		10, 0, elements, sequencer.BatchLimits{MaxTxsPerContext: 3},
+	)
+	require.Nil(t, err)
+	require.Equal(t, []sequencer.BatchContext{
+		{NumSequencedTxs: 3, Timestamp: 100, BlockNumber: 10},
+		{NumSequencedTxs: 1, Timestamp: 100, BlockNumber: 10},
+		{
+			NumSequencedTxs:       1,
+			NumSubsequentQueueTxs: 2,
+			Timestamp:             100,
+			BlockNumber:           10,
+		},
+		{NumSequencedTxs: 1, Timestamp: 102, BlockNumber: 11},
+	}, params.Contexts)
+	require.Len(t, params.Txs, 6)
+	require.Equal(t, uint64(len(elements)), params.TotalElementsToAppend)
+
+	// Consecutive queued txs are never split.
+	_, err = sequencer.GenSequencerBatchParams(
+		10, 0, elements, sequencer.BatchLimits{MaxTxsPerContext: 1},
+	)
+	require.ErrorIs(t, err, sequencer.ErrContextTooLarge)
+
+	_, err = sequencer.GenSequencerBatchParams(
+		10, 0, elements, sequencer.BatchLimits{MaxContextsPerBatch: 1},
+	)
+	require.ErrorIs(t, err, sequencer.ErrTooManyContexts)
+}
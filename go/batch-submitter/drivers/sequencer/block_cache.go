@@ -0,0 +1,131 @@
+package sequencer
+
+import (
+	"container/list"
+	"sync"
+
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+)
+
+// DefaultBlockCacheSize is the number of L2 blocks retained by the driver's
+// block cache when no explicit size is configured.
+const DefaultBlockCacheSize = 4096
+
+// blockCacheKey uniquely identifies an L2 block by both its height and hash.
+type blockCacheKey struct {
+	number uint64
+	hash   l2common.Hash
+}
+
+// blockCacheEntry is the value stored in each element of the LRU list.
+type blockCacheEntry struct {
+	key   blockCacheKey
+	block *l2types.Block
+}
+
+// BlockCache is a fixed-size, LRU cache of L2 blocks. Blocks are keyed by
+// number and hash, though at most one block is retained per height. Adding a
+// block whose hash differs from the cached block at the same height replaces
+// the stale entry, such that an L2 reorg can never cause both blocks to be
+// served.
+//
+// NOTE: BlockCache is safe for concurrent use.
+type BlockCache struct {
+	mu       sync.Mutex
+	size     int
+	lru      *list.List
+	entries  map[blockCacheKey]*list.Element
+	byNumber map[uint64]blockCacheKey
+}
+
+// NewBlockCache initializes a BlockCache holding at most size blocks. If size
+// is zero, DefaultBlockCacheSize is used.
+func NewBlockCache(size int) *BlockCache {
+	if size <= 0 {
+		size = DefaultBlockCacheSize
+	}
+
+	return &BlockCache{
+		size:     size,
+		lru:      list.New(),
+		entries:  make(map[blockCacheKey]*list.Element),
+		byNumber: make(map[uint64]blockCacheKey),
+	}
+}
+
+// Get returns the cached block with the given height and hash, if one exists,
+// and marks it as most recently used. A block cached at the same height with a
+// different hash, e.g. since reorged out, is not returned.
+func (c *BlockCache) Get(
+	number uint64, hash l2common.Hash) (*l2types.Block, bool) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[blockCacheKey{number: number, hash: hash}]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+
+	return elem.Value.(*blockCacheEntry).block, true
+}
+
+// Add inserts block into the cache, replacing any block previously cached at
+// the same height. If the cache is full, the least recently used block is
+// evicted.
+func (c *BlockCache) Add(block *l2types.Block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := blockCacheKey{
+		number: block.NumberU64(),
+		hash:   block.Hash(),
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+
+	// Drop any conflicting block at the same height.
+	if oldKey, ok := c.byNumber[key.number]; ok {
+		c.removeElement(c.entries[oldKey])
+	}
+
+	elem := c.lru.PushFront(&blockCacheEntry{key: key, block: block})
+	c.entries[key] = elem
+	c.byNumber[key.number] = key
+
+	for c.lru.Len() > c.size {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// Remove evicts the block cached at the given height, if any.
+func (c *BlockCache) Remove(number uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if key, ok := c.byNumber[number]; ok {
+		c.removeElement(c.entries[key])
+	}
+}
+
+// Len returns the number of blocks currently cached.
+func (c *BlockCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.Len()
+}
+
+// removeElement removes elem from the LRU list and both indexes.
+//
+// NOTE: This method MUST be called while holding c.mu.
+func (c *BlockCache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*blockCacheEntry)
+	delete(c.entries, entry.key)
+	delete(c.byNumber, entry.key.number)
+}
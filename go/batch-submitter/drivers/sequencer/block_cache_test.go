@@ -0,0 +1,100 @@
+package sequencer_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestBlock creates an empty L2 block at the given height. The timestamp is
+// used to produce distinct hashes for blocks sharing the same height.
+func newTestBlock(number, timestamp uint64) *l2types.Block {
+	header := &l2types.Header{
+		Number: new(big.Int).SetUint64(number),
+		Time:   timestamp,
+	}
+	return l2types.NewBlock(header, nil, nil, nil)
+}
+
+// TestBlockCacheGetAdd asserts that blocks added to the cache can be retrieved
+// by number and hash, and that missing blocks are reported as such.
+func TestBlockCacheGetAdd(t *testing.T) {
+	t.Parallel()
+
+	cache := sequencer.NewBlockCache(2)
+	block := newTestBlock(1, 0)
+
+	_, ok := cache.Get(1, block.Hash())
+	require.False(t, ok)
+
+	cache.Add(block)
+
+	cached, ok := cache.Get(1, block.Hash())
+	require.True(t, ok)
+	require.Equal(t, block.Hash(), cached.Hash())
+	require.Equal(t, 1, cache.Len())
+
+	// Adding the same block again should not create a duplicate entry.
+	cache.Add(block)
+	require.Equal(t, 1, cache.Len())
+}
+
+// TestBlockCacheEvictsLeastRecentlyUsed asserts that the cache never exceeds
+// its configured size, and that the least recently used block is the one that
+// gets evicted.
+func TestBlockCacheEvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	cache := sequencer.NewBlockCache(2)
+	block1 := newTestBlock(1, 0)
+	block2 := newTestBlock(2, 0)
+	block3 := newTestBlock(3, 0)
+	cache.Add(block1)
+	cache.Add(block2)
+
+	// Touch block 1 so that block 2 becomes the least recently used.
+	_, ok := cache.Get(1, block1.Hash())
+	require.True(t, ok)
+
+	cache.Add(block3)
+	require.Equal(t, 2, cache.Len())
+
+	_, ok = cache.Get(2, block2.Hash())
+	require.False(t, ok)
+	_, ok = cache.Get(1, block1.Hash())
+	require.True(t, ok)
+	_, ok = cache.Get(3, block3.Hash())
+	require.True(t, ok)
+}
+
+// TestBlockCacheReplacesConflictingHeight asserts that a block is never served
+// for the hash of another block at the same height, and that adding a block
+// with a different hash at an already cached height replaces the stale block.
+func TestBlockCacheReplacesConflictingHeight(t *testing.T) {
+	t.Parallel()
+
+	cache := sequencer.NewBlockCache(2)
+
+	oldBlock := newTestBlock(1, 0)
+	newBlock := newTestBlock(1, 1)
+	require.NotEqual(t, oldBlock.Hash(), newBlock.Hash())
+
+	cache.Add(oldBlock)
+	_, ok := cache.Get(1, newBlock.Hash())
+	require.False(t, ok)
+
+	cache.Add(newBlock)
+	require.Equal(t, 1, cache.Len())
+
+	_, ok = cache.Get(1, oldBlock.Hash())
+	require.False(t, ok)
+	cached, ok := cache.Get(1, newBlock.Hash())
+	require.True(t, ok)
+	require.Equal(t, newBlock.Hash(), cached.Hash())
+
+	cache.Remove(1)
+	require.Equal(t, 0, cache.Len())
+}
@@ -0,0 +1,276 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
+	l2ethereum "github.com/ethereum-optimism/optimism/l2geth"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// defaultBootstrapBlockRange is the number of L1 blocks whose CTC events are
+// fetched per query if no range is configured.
+const defaultBootstrapBlockRange = 10_000
+
+var (
+	// ErrL2BehindCTC signals that the L2 node has yet to sync every block
+	// already appended to the CTC.
+	ErrL2BehindCTC = errors.New("l2 node is behind the blocks appended " +
+		"to the ctc")
+
+	// ErrUnsubmittableGap signals that L2 blocks pending submission are
+	// unavailable from the L2 node, e.g. as it was started from a snapshot
+	// without an archive, such that no batch can be built until a node
+	// serving them is provided.
+	ErrUnsubmittableGap = errors.New("l2 blocks pending submission are " +
+		"unavailable")
+
+	// ErrBootstrapMismatch signals that the last L2 block appended to the
+	// CTC does not execute the last queue element appended by the CTC,
+	// such that the L2 node and the CTC disagree on the submitted chain.
+	ErrBootstrapMismatch = errors.New("last appended l2 block does not " +
+		"match the ctc queue")
+)
+
+// BootstrapConfig configures the derivation of the submitted L2 height from
+// the events of the CTC.
+type BootstrapConfig struct {
+	L1Client    *ethclient.Client
+	L2Client    l2client.Client
+	CTCAddr     common.Address
+	BlockOffset uint64
+
+	// FromL1Block is the first L1 block whose CTC events are scanned, e.g.
+	// the block deploying the CTC.
+	FromL1Block uint64
+
+	// L1BlockRange is the max number of L1 blocks whose CTC events are
+	// fetched per query, defaulting to 10,000.
+	L1BlockRange uint64
+}
+
+// BlockGap is an inclusive range of L2 block numbers.
+type BlockGap struct {
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+}
+
+// BootstrapReport describes the submitted L2 height derived from the events of
+// the CTC, validated against the L2 blocks available from the L2 node.
+type BootstrapReport struct {
+	// L1Head is the L1 block up to which CTC events were scanned.
+	L1Head uint64 `json:"l1Head"`
+
+	// LastBatchL1Block and LastBatchTxHash identify the tx appending the
+	// last sequencer batch, and are unset if none was found.
+	LastBatchL1Block *uint64     `json:"lastBatchL1Block,omitempty"`
+	LastBatchTxHash  common.Hash `json:"lastBatchTxHash"`
+
+	// TotalElements and NextQueueIndex are the total elements and next
+	// queue index of the CTC following the last sequencer batch.
+	TotalElements  uint64 `json:"totalElements"`
+	NextQueueIndex uint64 `json:"nextQueueIndex"`
+
+	// NextBlock is the first L2 block pending submission.
+	NextBlock uint64 `json:"nextBlock"`
+
+	// L2Head is the latest L2 block of the L2 node.
+	L2Head uint64 `json:"l2Head"`
+
+	// LastQueueIndex is the queue index executed by the last appended L2
+	// block, if it is available and executes a queue element.
+	LastQueueIndex *uint64 `json:"lastQueueIndex,omitempty"`
+
+	// Gap is the range of L2 blocks pending submission that are
+	// unavailable from the L2 node, if any.
+	Gap *BlockGap `json:"gap,omitempty"`
+}
+
+// Err returns an error describing why the L2 node is unable to submit the
+// blocks pending submission, or nil if it is able to.
+func (r *BootstrapReport) Err() error {
+	if r.L2Head+1 < r.NextBlock {
+		return fmt.Errorf("%w: l2 head %d, %d blocks behind next "+
+			"block %d", ErrL2BehindCTC, r.L2Head,
+			r.NextBlock-r.L2Head-1, r.NextBlock)
+	}
+	if r.LastQueueIndex != nil && *r.LastQueueIndex+1 != r.NextQueueIndex {
+		return fmt.Errorf("%w: block %d executes queue index %d, ctc "+
+			"next queue index is %d", ErrBootstrapMismatch,
+			r.NextBlock-1, *r.LastQueueIndex, r.NextQueueIndex)
+	}
+	if r.Gap != nil {
+		return fmt.Errorf("%w: blocks %d to %d (%d blocks)",
+			ErrUnsubmittableGap, r.Gap.Start, r.Gap.End,
+			r.Gap.End-r.Gap.Start+1)
+	}
+
+	return nil
+}
+
+// Bootstrap derives the submitted L2 height from the last
+// SequencerBatchAppended event of the CTC alone, without relying on the L2 node
+// having an archive of the submitted blocks, and validates it against the
+// blocks the L2 node is able to serve. The blocks available from the L2 node
+// are assumed to be contiguous up to its head, as they are for a node started
+// from a snapshot.
+func Bootstrap(
+	ctx context.Context,
+	cfg BootstrapConfig,
+) (*BootstrapReport, error) {
+
+	l1Head, err := cfg.L1Client.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get l1 head: %w", err)
+	}
+	report := &BootstrapReport{
+		L1Head: l1Head,
+	}
+
+	event, err := lastSequencerBatchAppended(ctx, cfg, l1Head)
+	if err != nil {
+		return nil, fmt.Errorf("unable to scan ctc events: %w", err)
+	}
+	if event != nil {
+		l1Block := event.Raw.BlockNumber
+		report.LastBatchL1Block = &l1Block
+		report.LastBatchTxHash = event.Raw.TxHash
+		report.TotalElements = event.TotalElements.Uint64()
+		report.NextQueueIndex = event.StartingQueueIndex.Uint64() +
+			event.NumQueueElements.Uint64()
+	}
+	report.NextBlock = report.TotalElements + cfg.BlockOffset
+
+	latestHeader, err := cfg.L2Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get l2 head: %w", err)
+	}
+	report.L2Head = latestHeader.Number.Uint64()
+	if report.L2Head+1 < report.NextBlock {
+		return report, nil
+	}
+
+	// Only a queued tx records an index against which the last appended
+	// block can be validated.
+	if report.TotalElements > 0 {
+		last := new(big.Int).SetUint64(report.NextBlock - 1)
+		block, err := cfg.L2Client.BlockByNumber(ctx, last)
+		switch {
+		case err == l2ethereum.NotFound:
+		case err != nil:
+			return nil, fmt.Errorf("unable to fetch l2 block %v: %w",
+				last, err)
+		case len(block.Transactions()) == 1:
+			meta := block.Transactions()[0].GetMeta()
+			report.LastQueueIndex = meta.QueueIndex
+		}
+	}
+
+	if report.NextBlock <= report.L2Head {
+		first, err := firstAvailableBlock(
+			ctx, cfg.L2Client, report.NextBlock, report.L2Head,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if first > report.NextBlock {
+			report.Gap = &BlockGap{
+				Start: report.NextBlock,
+				End:   first - 1,
+			}
+		}
+	}
+
+	return report, nil
+}
+
+// lastSequencerBatchAppended returns the last SequencerBatchAppended event of
+// the CTC emitted up to l1Head, or nil if there is none. Events are scanned
+// backwards from l1Head in windows of L1BlockRange blocks, such that only the
+// most recent window containing a batch is fetched in full.
+func lastSequencerBatchAppended(
+	ctx context.Context,
+	cfg BootstrapConfig,
+	l1Head uint64,
+) (*ctc.CanonicalTransactionChainSequencerBatchAppended, error) {
+
+	filterer, err := ctc.NewCanonicalTransactionChainFilterer(
+		cfg.CTCAddr, cfg.L1Client,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	blockRange := cfg.L1BlockRange
+	if blockRange == 0 {
+		blockRange = defaultBootstrapBlockRange
+	}
+
+	for end := l1Head; end >= cfg.FromL1Block; {
+		start := cfg.FromL1Block
+		if end-start >= blockRange {
+			start = end - blockRange + 1
+		}
+
+		windowEnd := end
+		iter, err := filterer.FilterSequencerBatchAppended(&bind.FilterOpts{
+			Start:   start,
+			End:     &windowEnd,
+			Context: ctx,
+		})
+		if err != nil {
+			return nil, err
+		}
+		var last *ctc.CanonicalTransactionChainSequencerBatchAppended
+		for iter.Next() {
+			last = iter.Event
+		}
+		err = iter.Error()
+		iter.Close()
+		if err != nil {
+			return nil, err
+		}
+		if last != nil {
+			return last, nil
+		}
+
+		if start == 0 || start == cfg.FromL1Block {
+			break
+		}
+		end = start - 1
+	}
+
+	return nil, nil
+}
+
+// firstAvailableBlock returns the first L2 block between lo and hi, inclusive,
+// available from client, assuming that hi is available and that so is every
+// block following an available block.
+func firstAvailableBlock(
+	ctx context.Context,
+	client l2client.Client,
+	lo, hi uint64,
+) (uint64, error) {
+
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, err := client.BlockByNumber(ctx, new(big.Int).SetUint64(mid))
+		switch {
+		case err == l2ethereum.NotFound:
+			lo = mid + 1
+		case err != nil:
+			return 0, fmt.Errorf("unable to fetch l2 block %d: %w",
+				mid, err)
+		default:
+			hi = mid
+		}
+	}
+
+	return lo, nil
+}
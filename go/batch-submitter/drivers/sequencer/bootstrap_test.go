@@ -0,0 +1,153 @@
+package sequencer_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newBootstrapTestChains returns an L1 chain whose CTC has appended the first
+// two blocks of the returned L2 chain, the second executing a queue element,
+// followed by empty L1 blocks. The L2 chain holds three further blocks pending
+// submission.
+func newBootstrapTestChains(t *testing.T) (*testutil.L1, *testutil.L2) {
+	l1, l2 := newTestChains(t)
+
+	element := testutil.QueueElement{Timestamp: 101, BlockNumber: 1}
+	l1.Enqueue(element)
+	l2.AddBlock(100, testutil.SequencerTx(0, 1))
+	l2.AddBlock(element.Timestamp, testutil.QueueTx(0, element))
+	for nonce := uint64(1); nonce < 4; nonce++ {
+		l2.AddBlock(101+nonce, testutil.SequencerTx(nonce, 1))
+	}
+
+	params, err := sequencer.GenSequencerBatchParams(
+		1, 1, []sequencer.BatchElement{
+			sequencer.BatchElementFromBlock(l2.Block(1)),
+			sequencer.BatchElementFromBlock(l2.Block(2)),
+		}, sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	callData, err := testutil.AppendSequencerBatchCallData(params)
+	require.Nil(t, err)
+
+	privKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	tx, err := types.SignTx(
+		types.NewTransaction(
+			0, l1.CTCAddr(), new(big.Int), 1_000_000,
+			testutil.SuggestedGasPrice, callData,
+		),
+		types.LatestSignerForChainID(l1.ChainID()), privKey,
+	)
+	require.Nil(t, err)
+	require.Nil(t, l1.Client().SendTransaction(context.Background(), tx))
+	require.Empty(t, l1.Reverted())
+
+	for i := 0; i < 5; i++ {
+		l1.Mine()
+	}
+
+	return l1, l2
+}
+
+// newBootstrapConfig returns a BootstrapConfig reading from l1 and l2, fetching
+// the events of two L1 blocks per query.
+func newBootstrapConfig(
+	l1 *testutil.L1,
+	l2 *testutil.L2,
+) sequencer.BootstrapConfig {
+
+	return sequencer.BootstrapConfig{
+		L1Client:     l1.Client(),
+		L2Client:     l2.Client(),
+		CTCAddr:      l1.CTCAddr(),
+		BlockOffset:  1,
+		L1BlockRange: 2,
+	}
+}
+
+// TestBootstrap asserts that the submitted L2 height and next queue index are
+// derived from the last batch appended to the CTC, and validated against the
+// last appended L2 block.
+func TestBootstrap(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newBootstrapTestChains(t)
+
+	report, err := sequencer.Bootstrap(
+		context.Background(), newBootstrapConfig(l1, l2),
+	)
+	require.Nil(t, err)
+	require.Nil(t, report.Err())
+
+	require.Equal(t, uint64(6), report.L1Head)
+	require.Equal(t, uint64(1), *report.LastBatchL1Block)
+	require.Equal(t, uint64(2), report.TotalElements)
+	require.Equal(t, uint64(1), report.NextQueueIndex)
+	require.Equal(t, uint64(3), report.NextBlock)
+	require.Equal(t, uint64(5), report.L2Head)
+	require.Equal(t, uint64(0), *report.LastQueueIndex)
+	require.Nil(t, report.Gap)
+}
+
+// TestBootstrapUnsubmittableGap asserts that the blocks pending submission that
+// are unavailable from a pruned L2 node are reported as a gap.
+func TestBootstrapUnsubmittableGap(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newBootstrapTestChains(t)
+	l2.Prune(5)
+
+	report, err := sequencer.Bootstrap(
+		context.Background(), newBootstrapConfig(l1, l2),
+	)
+	require.Nil(t, err)
+	require.Nil(t, report.LastQueueIndex)
+	require.Equal(t, &sequencer.BlockGap{Start: 3, End: 4}, report.Gap)
+	require.True(t, errors.Is(report.Err(), sequencer.ErrUnsubmittableGap))
+	require.Equal(t, "l2 blocks pending submission are unavailable: "+
+		"blocks 3 to 4 (2 blocks)", report.Err().Error())
+}
+
+// TestBootstrapL2Behind asserts that an L2 node yet to sync the blocks
+// appended to the CTC is reported as behind, rather than as a gap.
+func TestBootstrapL2Behind(t *testing.T) {
+	t.Parallel()
+
+	l1, _ := newBootstrapTestChains(t)
+	l2, err := testutil.NewL2()
+	require.Nil(t, err)
+	t.Cleanup(l2.Close)
+
+	report, err := sequencer.Bootstrap(
+		context.Background(), newBootstrapConfig(l1, l2),
+	)
+	require.Nil(t, err)
+	require.Nil(t, report.Gap)
+	require.True(t, errors.Is(report.Err(), sequencer.ErrL2BehindCTC))
+}
+
+// TestBootstrapNoBatches asserts that every L2 block is pending submission if
+// the CTC has yet to append a batch.
+func TestBootstrapNoBatches(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+	l2.AddBlock(100, testutil.SequencerTx(0, 1))
+
+	report, err := sequencer.Bootstrap(
+		context.Background(), newBootstrapConfig(l1, l2),
+	)
+	require.Nil(t, err)
+	require.Nil(t, report.Err())
+	require.Nil(t, report.LastBatchL1Block)
+	require.Equal(t, uint64(1), report.NextBlock)
+}
@@ -0,0 +1,89 @@
+package sequencer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownBatchBoundary signals that batches were configured to align with
+// an unsupported boundary.
+var ErrUnknownBatchBoundary = errors.New("batch boundary must be one of " +
+	"none, context or epoch")
+
+// BatchBoundary determines where a batch that is cut short of its block range,
+// e.g. by the max tx size, is allowed to end.
+type BatchBoundary string
+
+const (
+	// BatchBoundaryNone allows a batch to end after any block.
+	BatchBoundaryNone BatchBoundary = "none"
+
+	// BatchBoundaryContext only allows a batch to end between blocks that
+	// belong to different contexts, such that no context is split across
+	// batches.
+	BatchBoundaryContext BatchBoundary = "context"
+
+	// BatchBoundaryEpoch only allows a batch to end between blocks with
+	// different L1 block numbers, such that no L1 epoch is split across
+	// batches.
+	BatchBoundaryEpoch BatchBoundary = "epoch"
+)
+
+// ParseBatchBoundary parses a BatchBoundary from its name, defaulting to
+// BatchBoundaryNone if name is empty.
+func ParseBatchBoundary(name string) (BatchBoundary, error) {
+	switch boundary := BatchBoundary(name); boundary {
+	case "":
+		return BatchBoundaryNone, nil
+	case BatchBoundaryNone, BatchBoundaryContext, BatchBoundaryEpoch:
+		return boundary, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownBatchBoundary, name)
+	}
+}
+
+// splits returns true if ending a batch between last and next, the element
+// following it, would split a unit that the boundary keeps whole.
+func (b BatchBoundary) splits(last, next BatchElement) bool {
+	switch b {
+	case BatchBoundaryContext:
+		// Mirroring GenSequencerBatchParams, a queued tx always joins
+		// the context of the element preceding it, while a sequencer
+		// tx only joins that of a preceding sequencer tx with the same
+		// timestamp and block number.
+		if !next.IsSequencerTx() {
+			return true
+		}
+		return last.IsSequencerTx() &&
+			last.Timestamp == next.Timestamp &&
+			last.BlockNumber == next.BlockNumber
+
+	case BatchBoundaryEpoch:
+		return last.BlockNumber == next.BlockNumber
+
+	default:
+		return false
+	}
+}
+
+// AlignBatchBoundary trims elements, a batch cut short before next, such that
+// it ends at the given boundary. If no elements remain once trimmed, e.g. since
+// a single context exceeds the max tx size, elements is returned unaligned so
+// that submission can still make progress.
+func AlignBatchBoundary(
+	boundary BatchBoundary,
+	elements []BatchElement,
+	next BatchElement,
+) []BatchElement {
+
+	aligned := elements
+	for len(aligned) > 0 && boundary.splits(aligned[len(aligned)-1], next) {
+		next = aligned[len(aligned)-1]
+		aligned = aligned[:len(aligned)-1]
+	}
+	if len(aligned) == 0 {
+		return elements
+	}
+
+	return aligned
+}
@@ -0,0 +1,139 @@
+package sequencer_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// sequencedElement returns a BatchElement holding a sequencer tx with the given
+// timestamp and L1 block number.
+func sequencedElement(timestamp, blockNumber uint64) sequencer.BatchElement {
+	tx := l2types.NewTransaction(
+		0, l2common.Address{}, new(big.Int), 0, new(big.Int), nil,
+	)
+	return sequencer.BatchElement{
+		Timestamp:   timestamp,
+		BlockNumber: blockNumber,
+		Tx:          sequencer.NewCachedTx(tx),
+	}
+}
+
+// queuedElement returns a BatchElement holding a queued tx with the given
+// timestamp and L1 block number.
+func queuedElement(timestamp, blockNumber uint64) sequencer.BatchElement {
+	return sequencer.BatchElement{
+		Timestamp:   timestamp,
+		BlockNumber: blockNumber,
+	}
+}
+
+// TestAlignBatchBoundary asserts that a batch cut short is trimmed to end at
+// the configured boundary, and is left unaligned if nothing would remain.
+func TestAlignBatchBoundary(t *testing.T) {
+	t.Parallel()
+
+	elements := []sequencer.BatchElement{
+		sequencedElement(100, 10),
+		sequencedElement(101, 10),
+		queuedElement(101, 10),
+		sequencedElement(102, 11),
+		sequencedElement(102, 11),
+	}
+
+	tests := []struct {
+		name     string
+		boundary sequencer.BatchBoundary
+		elements []sequencer.BatchElement
+		next     sequencer.BatchElement
+		expLen   int
+	}{
+		{
+			name:     "none",
+			boundary: sequencer.BatchBoundaryNone,
+			elements: elements,
+			next:     sequencedElement(102, 11),
+			expLen:   5,
+		},
+		{
+			name:     "context continued by sequencer tx",
+			boundary: sequencer.BatchBoundaryContext,
+			elements: elements,
+			next:     sequencedElement(102, 11),
+			expLen:   3,
+		},
+		{
+			name:     "context continued by queued tx",
+			boundary: sequencer.BatchBoundaryContext,
+			elements: elements,
+			next:     queuedElement(102, 11),
+			expLen:   3,
+		},
+		{
+			name:     "context already ended",
+			boundary: sequencer.BatchBoundaryContext,
+			elements: elements,
+			next:     sequencedElement(103, 11),
+			expLen:   5,
+		},
+		{
+			name:     "queued txs kept with their context",
+			boundary: sequencer.BatchBoundaryContext,
+			elements: elements[:3],
+			next:     queuedElement(101, 10),
+			expLen:   1,
+		},
+		{
+			name:     "epoch continued",
+			boundary: sequencer.BatchBoundaryEpoch,
+			elements: elements,
+			next:     sequencedElement(103, 11),
+			expLen:   3,
+		},
+		{
+			name:     "epoch already ended",
+			boundary: sequencer.BatchBoundaryEpoch,
+			elements: elements,
+			next:     sequencedElement(103, 12),
+			expLen:   5,
+		},
+		{
+			name:     "single epoch left unaligned",
+			boundary: sequencer.BatchBoundaryEpoch,
+			elements: elements[:3],
+			next:     sequencedElement(102, 10),
+			expLen:   3,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			aligned := sequencer.AlignBatchBoundary(
+				test.boundary, test.elements, test.next,
+			)
+			require.Equal(t, test.elements[:test.expLen], aligned)
+		})
+	}
+}
+
+// TestParseBatchBoundary asserts that batch boundaries are parsed by name,
+// defaulting to none.
+func TestParseBatchBoundary(t *testing.T) {
+	t.Parallel()
+
+	boundary, err := sequencer.ParseBatchBoundary("")
+	require.Nil(t, err)
+	require.Equal(t, sequencer.BatchBoundaryNone, boundary)
+
+	boundary, err = sequencer.ParseBatchBoundary("epoch")
+	require.Nil(t, err)
+	require.Equal(t, sequencer.BatchBoundaryEpoch, boundary)
+
+	_, err = sequencer.ParseBatchBoundary("block")
+	require.ErrorIs(t, err, sequencer.ErrUnknownBatchBoundary)
+}
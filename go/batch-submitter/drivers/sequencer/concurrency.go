@@ -0,0 +1,69 @@
+package sequencer
+
+import (
+	"sync"
+	"time"
+)
+
+// ConcurrencyController tunes the number of concurrent L2 block requests using
+// an additive-increase/multiplicative-decrease (AIMD) policy. Each window of
+// requests that completes without error and with an average latency at or
+// under the target latency increases the limit by one. Otherwise, the limit is
+// halved. The limit is always kept within [min, max].
+//
+// NOTE: ConcurrencyController is safe for concurrent use.
+type ConcurrencyController struct {
+	mu            sync.Mutex
+	min           int
+	max           int
+	limit         int
+	targetLatency time.Duration
+}
+
+// NewConcurrencyController initializes a ConcurrencyController that starts at
+// min concurrent requests and never exceeds max.
+func NewConcurrencyController(
+	min, max int, targetLatency time.Duration) *ConcurrencyController {
+
+	if min < 1 {
+		min = 1
+	}
+	if max < min {
+		max = min
+	}
+
+	return &ConcurrencyController{
+		min:           min,
+		max:           max,
+		limit:         min,
+		targetLatency: targetLatency,
+	}
+}
+
+// Limit returns the current number of requests that may be in flight.
+func (c *ConcurrencyController) Limit() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.limit
+}
+
+// Observe updates the limit using the average latency of a window of requests,
+// and whether any request in the window failed. The updated limit is returned.
+func (c *ConcurrencyController) Observe(
+	avgLatency time.Duration, failed bool) int {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if failed || avgLatency > c.targetLatency {
+		c.limit /= 2
+		if c.limit < c.min {
+			c.limit = c.min
+		}
+	} else if c.limit < c.max {
+		c.limit++
+	}
+
+	return c.limit
+}
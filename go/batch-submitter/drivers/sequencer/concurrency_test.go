@@ -0,0 +1,43 @@
+package sequencer_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConcurrencyControllerAIMD asserts that the controller additively
+// increases the limit on healthy windows, multiplicatively decreases it on slow
+// or failed windows, and always respects the configured bounds.
+func TestConcurrencyControllerAIMD(t *testing.T) {
+	t.Parallel()
+
+	const target = 100 * time.Millisecond
+
+	c := sequencer.NewConcurrencyController(2, 8, target)
+	require.Equal(t, 2, c.Limit())
+
+	// Healthy windows increase the limit by one up to the max.
+	for i := 3; i <= 8; i++ {
+		require.Equal(t, i, c.Observe(target, false))
+	}
+	require.Equal(t, 8, c.Observe(target/2, false))
+
+	// Slow windows halve the limit.
+	require.Equal(t, 4, c.Observe(2*target, false))
+
+	// Failed windows halve the limit, but never below the min.
+	require.Equal(t, 2, c.Observe(0, true))
+	require.Equal(t, 2, c.Observe(0, true))
+}
+
+// TestConcurrencyControllerBounds asserts that invalid bounds are sanitized.
+func TestConcurrencyControllerBounds(t *testing.T) {
+	t.Parallel()
+
+	c := sequencer.NewConcurrencyController(0, 0, time.Second)
+	require.Equal(t, 1, c.Limit())
+	require.Equal(t, 1, c.Observe(0, false))
+}
@@ -0,0 +1,30 @@
+package sequencer
+
+import (
+	"errors"
+	"fmt"
+
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+)
+
+// ErrL2ChainDiscontinuity signals that the L2 blocks fetched for a batch do
+// not form a single chain, e.g. due to an L2 reorg or a lagging replica.
+var ErrL2ChainDiscontinuity = errors.New("L2 block parent hash does not " +
+	"match previous block")
+
+// ValidateChainContinuity checks that each of the passed blocks, ordered by
+// ascending height, is the child of the block preceding it. If prev is non-nil,
+// the first block must also be its child.
+func ValidateChainContinuity(prev *l2types.Block, blocks []*l2types.Block) error {
+	for _, block := range blocks {
+		if prev != nil && block.ParentHash() != prev.Hash() {
+			return fmt.Errorf("%w: block=%d parent_hash=%s "+
+				"prev_hash=%s", ErrL2ChainDiscontinuity,
+				block.NumberU64(), block.ParentHash().Hex(),
+				prev.Hash().Hex())
+		}
+		prev = block
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package sequencer_test
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChain creates numBlocks empty L2 blocks starting at the given height,
+// each the child of the block preceding it.
+func newTestChain(start uint64, numBlocks int) []*l2types.Block {
+	var (
+		blocks     []*l2types.Block
+		parentHash l2common.Hash
+	)
+	for i := 0; i < numBlocks; i++ {
+		block := l2types.NewBlock(&l2types.Header{
+			Number:     new(big.Int).SetUint64(start + uint64(i)),
+			ParentHash: parentHash,
+		}, nil, nil, nil)
+		blocks = append(blocks, block)
+		parentHash = block.Hash()
+	}
+
+	return blocks
+}
+
+// TestValidateChainContinuity asserts that ValidateChainContinuity accepts
+// blocks forming a single chain, including across fetch windows, and rejects
+// any block whose parent hash does not match the block preceding it.
+func TestValidateChainContinuity(t *testing.T) {
+	t.Parallel()
+
+	chain := newTestChain(10, 6)
+	fork := newTestBlock(13, 1)
+
+	tests := []struct {
+		name   string
+		prev   *l2types.Block
+		blocks []*l2types.Block
+		expErr bool
+	}{
+		{
+			name:   "empty",
+			blocks: nil,
+		},
+		{
+			name:   "continuous",
+			blocks: chain,
+		},
+		{
+			name:   "continuous with prev",
+			prev:   chain[2],
+			blocks: chain[3:],
+		},
+		{
+			name:   "discontinuous with prev",
+			prev:   fork,
+			blocks: chain[4:],
+			expErr: true,
+		},
+		{
+			name: "discontinuous within blocks",
+			blocks: []*l2types.Block{
+				chain[2], fork, chain[4],
+			},
+			expErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := sequencer.ValidateChainContinuity(
+				test.prev, test.blocks,
+			)
+			if test.expErr {
+				require.True(t, errors.Is(
+					err, sequencer.ErrL2ChainDiscontinuity,
+				))
+			} else {
+				require.Nil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrContextDriftDeferred signals that a built batch holds contexts outside of
+// the allowed drift from the L1 timestamp at which it is expected to be
+// included. It wraps txmgr.ErrSendDeferred, such that fee bumping stops and
+// the batch is rebuilt from its blocks, re-deriving its contexts, rather than
+// republished as is.
+var ErrContextDriftDeferred = fmt.Errorf("%w: batch contexts exceed max "+
+	"drift", txmgr.ErrSendDeferred)
+
+// validateContextDrift checks the passed contexts against the CTC's last
+// recorded timestamp and the expected inclusion timestamp. A violation is
+// returned wrapping ErrContextDriftDeferred.
+func (d *Driver) validateContextDrift(
+	ctx context.Context, contexts []BatchContext) error {
+
+	lastTimestamp, err := d.ctcContract.GetLastTimestamp(&bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	})
+	if err != nil {
+		return err
+	}
+
+	l1Header, err := d.cfg.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	err = ValidateContextDrift(
+		contexts, lastTimestamp.Uint64(), l1Header.Time,
+		d.cfg.MaxContextDrift, d.cfg.ExpectedInclusionDelay,
+	)
+	if err != nil {
+		d.metrics.ContextDriftViolations.Inc()
+		return fmt.Errorf("%w: %v", ErrContextDriftDeferred, err)
+	}
+
+	return nil
+}
+
+// trimFutureContexts cuts elements short before the first element whose
+// timestamp is ahead of the expected inclusion timestamp, aligned to the
+// configured BatchBoundary. An error wrapping ErrContextDriftDeferred is
+// returned if the first element is such an element. Elements are not checked
+// unless MaxContextDrift is set.
+func (d *Driver) trimFutureContexts(
+	ctx context.Context,
+	elements []BatchElement,
+) ([]BatchElement, error) {
+
+	if d.cfg.MaxContextDrift == 0 || len(elements) == 0 {
+		return elements, nil
+	}
+
+	l1Header, err := d.cfg.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	inclusionTimestamp := l1Header.Time +
+		uint64(d.cfg.ExpectedInclusionDelay/time.Second)
+
+	for i, element := range elements {
+		if element.Timestamp <= inclusionTimestamp {
+			continue
+		}
+
+		d.metrics.ContextDriftViolations.Inc()
+		if i == 0 {
+			return nil, fmt.Errorf("%w: %v, timestamp=%d "+
+				"inclusion=%d", ErrContextDriftDeferred,
+				ErrBatchContextInFuture, element.Timestamp,
+				inclusionTimestamp)
+		}
+
+		log.Warn(d.cfg.Name+" batch cut short before context ahead "+
+			"of inclusion", "timestamp", element.Timestamp,
+			"inclusion", inclusionTimestamp,
+			"old_num_txs", len(elements), "new_num_txs", i)
+
+		return d.alignBatchBoundary(elements[:i], element), nil
+	}
+
+	return elements, nil
+}
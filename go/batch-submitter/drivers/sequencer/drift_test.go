@@ -0,0 +1,90 @@
+package sequencer_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// driftTestL1Time is the timestamp of the genesis block of testutil.L1.
+const driftTestL1Time = 1_600_000_000
+
+// newDriftTestDriver returns a driver allowing a max context drift of a minute
+// and an inclusion delay of 10s, batching the L2 blocks with the given L1
+// timestamps.
+func newDriftTestDriver(
+	t *testing.T,
+	timestamps ...uint64,
+) (*sequencer.Driver, *testutil.L1) {
+
+	l1, l2 := newTestChains(t)
+
+	for i, timestamp := range timestamps {
+		l2.AddBlock(timestamp, testutil.SequencerTx(uint64(i), 1))
+	}
+
+	driver := newTestDriver(t, l1, l2, sequencer.Config{
+		Name:                   "drift_" + t.Name(),
+		MaxContextDrift:        time.Minute,
+		ExpectedInclusionDelay: 10 * time.Second,
+	})
+
+	return driver, l1
+}
+
+// TestBuildBatchContextAheadOfInclusion asserts that a batch is cut short
+// before a context ahead of its expected inclusion, and that no batch is built
+// while such a context heads the range.
+func TestBuildBatchContextAheadOfInclusion(t *testing.T) {
+	t.Parallel()
+
+	driver, _ := newDriftTestDriver(
+		t, driftTestL1Time-5, driftTestL1Time+10, driftTestL1Time+11,
+	)
+
+	batch, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(4),
+	)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), batch.End)
+
+	_, err = driver.BuildBatch(
+		context.Background(), big.NewInt(3), big.NewInt(4),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrContextDriftDeferred))
+	require.True(t, errors.Is(err, txmgr.ErrSendDeferred))
+}
+
+// TestSubmitBuiltBatchDefersStaleContexts asserts that a built batch whose
+// contexts drifted too far from L1 while awaiting publication is deferred
+// rather than published, such that it is rebuilt instead of failing every fee
+// bump.
+func TestSubmitBuiltBatchDefersStaleContexts(t *testing.T) {
+	t.Parallel()
+
+	driver, l1 := newDriftTestDriver(t, driftTestL1Time-49)
+
+	batch, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(2),
+	)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), batch.End)
+
+	// The context is valid until L1 reaches 1s past genesis, after which
+	// it would be more than a minute old at inclusion.
+	l1.Mine()
+	l1.Mine()
+
+	_, err = driver.SubmitBuiltBatch(
+		context.Background(), batch, big.NewInt(0), big.NewInt(1),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrContextDriftDeferred))
+	require.True(t, errors.Is(err, txmgr.ErrSendDeferred))
+}
@@ -1,52 +1,193 @@
 package sequencer
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"fmt"
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
-	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/quota"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/wallet"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/ethereum/go-ethereum/log"
 )
 
 const (
 	appendSequencerBatchMethodName = "appendSequencerBatch"
+
+	// sequencerName is the name the CTC resolves the sender of sequencer
+	// batches by.
+	sequencerName = "OVM_Sequencer"
 )
 
 var bigOne = new(big.Int).SetUint64(1)
 
 type Config struct {
-	Name        string
-	L1Client    *ethclient.Client
-	L2Client    *l2ethclient.Client
-	BlockOffset uint64
-	MaxTxSize   uint64
-	CTCAddr     common.Address
-	ChainID     *big.Int
-	PrivKey     *ecdsa.PrivateKey
+	Name           string
+	L1Client       *ethclient.Client
+	L2Client       l2client.Client
+	BlockOffset    uint64
+	MaxTxSize      uint64
+	BlockCacheSize int
+	CTCAddr        common.Address
+	ChainID        *big.Int
+	PrivKey        *ecdsa.PrivateKey
+
+	// RotationKeys are the keys of the wallets batch txs may be submitted
+	// from once the wallet of PrivKey is rotated out, in rotation order.
+	RotationKeys []*ecdsa.PrivateKey
+
+	// NumFetchWorkers is the maximum number of L2 blocks that will be
+	// fetched concurrently while building a batch.
+	NumFetchWorkers int
+
+	// FetchTargetLatency is the average L2 request latency above which the
+	// fetch concurrency is reduced. If zero, NumFetchWorkers requests are
+	// always made concurrently. Otherwise, the concurrency is tuned
+	// between one and NumFetchWorkers.
+	FetchTargetLatency time.Duration
+
+	// GasLimitMultiplier scales the node's gas estimate for each batch tx.
+	GasLimitMultiplier float64
+
+	// GasLimitBuffer is a fixed amount of gas added to each scaled gas
+	// estimate.
+	GasLimitBuffer uint64
+
+	// MaxGasLimit is a hard cap on the gas limit of each batch tx. If zero,
+	// the gas limit is not capped.
+	MaxGasLimit uint64
+
+	// DryRun, if true, builds, simulates and signs batch txs without
+	// publishing them.
+	DryRun bool
+
+	// Publisher, if non-nil, publishes signed batch txs in place of
+	// L1Client, e.g. to submit them to a private relay.
+	Publisher txmgr.Publisher
+
+	// MaxContextDrift is the maximum age of a batch context's timestamp
+	// relative to the L1 timestamp at inclusion. If zero, context drift
+	// is not validated.
+	MaxContextDrift time.Duration
+
+	// ExpectedInclusionDelay is the expected time a batch tx spends in the
+	// mempool before being included, which is added to the current L1
+	// timestamp when validating context drift.
+	ExpectedInclusionDelay time.Duration
+
+	// SecondaryL2Client, if non-nil, is used to refetch the L2 blocks of a
+	// batch when those returned by L2Client do not form a single chain.
+	SecondaryL2Client l2client.Client
+
+	// MinL2TxCount is the minimum number of L2 txs a pending range must
+	// hold before it is submitted. If zero, the tx count is not checked.
+	MinL2TxCount uint64
+
+	// MinBatchBytes is the minimum size in bytes of the sequencer txs of a
+	// pending range before it is submitted. If zero, the size is not
+	// checked.
+	MinBatchBytes uint64
+
+	// MaxBatchSubmissionTime is the age of the oldest pending block after
+	// which a range is submitted regardless of MinL2TxCount and
+	// MinBatchBytes. If zero, DefaultMaxBatchSubmissionTime is used.
+	MaxBatchSubmissionTime time.Duration
+
+	// MaxFeePerL2Tx, if non-nil and non-zero, is the maximum fee in wei
+	// worth paying per L2 tx in a batch. Batch txs whose fee would exceed
+	// this ceiling are not published, and their gas price is not bumped.
+	MaxFeePerL2Tx *big.Int
+
+	// MemoryQuota, if non-nil, bounds the bytes of L2 blocks held by the
+	// batches being built at once. A batch is cut short once the quota is
+	// exhausted, and submitted early with the blocks fetched so far.
+	MemoryQuota *quota.Memory
+
+	// RPCQuota, if non-nil, bounds the rate of L2 requests, such that
+	// catching up on a large range does not overwhelm the L2 replica.
+	RPCQuota *quota.RPC
+
+	// RPCTimeout, if non-zero, bounds each L2 block request.
+	RPCTimeout time.Duration
+
+	// BatchVersion determines the encoding of the contexts of each batch.
+	// Versions other than BatchVersionV0 are not accepted by the legacy
+	// CTC.
+	BatchVersion BatchVersion
+
+	// BatchBoundary determines where a batch cut short of its block range
+	// is allowed to end. If empty, batches may end after any block.
+	BatchBoundary BatchBoundary
+
+	// SystemTxPolicy determines how the sequencer txs identified as system
+	// txs by SystemTxs are handled. If empty, they are included like any
+	// other sequencer tx.
+	SystemTxPolicy SystemTxPolicy
+
+	// SystemTxs identifies the sequencer txs that are system txs.
+	SystemTxs SystemTxFilter
+
+	// BatchLimits bounds the contexts of each batch. A batch spanning too
+	// many contexts is cut short, leaving the rest for the next batch.
+	BatchLimits BatchLimits
+
+	// MalformedBlockPolicy determines how L2 blocks from which no batch
+	// element can be constructed are handled. If empty, they fail the
+	// batch holding them.
+	MalformedBlockPolicy MalformedBlockPolicy
+
+	// MalformedBlockBudget is the number of consecutive batches that may
+	// encounter a malformed block under MalformedBlockQuarantine, after
+	// which batches are no longer built until a batch is built without
+	// encountering one. If zero, batches are always built.
+	MalformedBlockBudget uint64
+
+	// OnMalformedBlock, if non-nil, is invoked with each block quarantined
+	// under MalformedBlockQuarantine.
+	OnMalformedBlock MalformedBlockHook
 }
 
 type Driver struct {
 	cfg            Config
 	ctcContract    *ctc.CanonicalTransactionChain
 	rawCtcContract *bind.BoundContract
-	walletAddr     common.Address
+	wallets        *wallet.Set
 	ctcABI         *abi.ABI
+	blockCache     *BlockCache
 	metrics        *metrics.Metrics
+
+	fetchConcurrency *ConcurrencyController
+	malformedBlocks  *malformedBlockBudget
+
+	// maxTxSize is the max size of a batch tx, initialized from
+	// cfg.MaxTxSize and adjustable at runtime.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	maxTxSize uint64
 }
 
 func NewDriver(cfg Config) (*Driver, error) {
+	if cfg.Publisher == nil {
+		cfg.Publisher = cfg.L1Client
+	}
+
 	ctcContract, err := ctc.NewCanonicalTransactionChain(
 		cfg.CTCAddr, cfg.L1Client,
 	)
@@ -71,15 +212,30 @@ func NewDriver(cfg Config) (*Driver, error) {
 		cfg.L1Client,
 	)
 
-	walletAddr := crypto.PubkeyToAddress(cfg.PrivKey.PublicKey)
+	// Without a target latency the concurrency controller is pinned to
+	// NumFetchWorkers.
+	minFetchWorkers := cfg.NumFetchWorkers
+	if cfg.FetchTargetLatency > 0 {
+		minFetchWorkers = 1
+	}
+	fetchConcurrency := NewConcurrencyController(
+		minFetchWorkers, cfg.NumFetchWorkers, cfg.FetchTargetLatency,
+	)
 
 	return &Driver{
 		cfg:            cfg,
 		ctcContract:    ctcContract,
 		rawCtcContract: rawCtcContract,
-		walletAddr:     walletAddr,
+		wallets:        wallet.NewSet(cfg.PrivKey, cfg.RotationKeys...),
 		ctcABI:         ctcABI,
+		blockCache:     NewBlockCache(cfg.BlockCacheSize),
 		metrics:        metrics.NewMetrics(cfg.Name),
+
+		fetchConcurrency: fetchConcurrency,
+		malformedBlocks: &malformedBlockBudget{
+			budget: cfg.MalformedBlockBudget,
+		},
+		maxTxSize: cfg.MaxTxSize,
 	}, nil
 }
 
@@ -90,7 +246,37 @@ func (d *Driver) Name() string {
 
 // WalletAddr is the wallet address used to pay for batch transaction fees.
 func (d *Driver) WalletAddr() common.Address {
-	return d.walletAddr
+	return d.wallets.Addr()
+}
+
+// Wallets returns the wallets batch txs may be submitted from.
+func (d *Driver) Wallets() *wallet.Set {
+	return d.wallets
+}
+
+// AuthorizedSender returns the sender the CTC accepts batch txs from, i.e. the
+// address its AddressManager resolves as OVM_Sequencer.
+func (d *Driver) AuthorizedSender(ctx context.Context) (common.Address, error) {
+	return d.ctcContract.Resolve(&bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	}, sequencerName)
+}
+
+// PingL2 returns an error if the L2 backend is unreachable.
+func (d *Driver) PingL2(ctx context.Context) error {
+	_, err := d.cfg.L2Client.HeaderByNumber(ctx, nil)
+	return err
+}
+
+// MaxTxSize returns the max size of a batch tx.
+func (d *Driver) MaxTxSize() uint64 {
+	return atomic.LoadUint64(&d.maxTxSize)
+}
+
+// SetMaxTxSize adjusts the max size of subsequently built batch txs.
+func (d *Driver) SetMaxTxSize(size uint64) {
+	atomic.StoreUint64(&d.maxTxSize, size)
 }
 
 // Metrics returns the subservice telemetry object.
@@ -98,12 +284,65 @@ func (d *Driver) Metrics() *metrics.Metrics {
 	return d.metrics
 }
 
+// DriverState is a snapshot of the internal state of the driver.
+type DriverState struct {
+	BlockCacheLen    int    `json:"block_cache_len"`
+	BlockCacheSize   int    `json:"block_cache_size"`
+	FetchWorkers     int    `json:"fetch_workers"`
+	MaxTxSize        uint64 `json:"max_tx_size"`
+	MemoryQuotaInUse uint64 `json:"memory_quota_in_use"`
+}
+
+// DebugState returns a snapshot of the internal state of the driver, as
+// reported by the debug server.
+func (d *Driver) DebugState() interface{} {
+	return DriverState{
+		BlockCacheLen:    d.blockCache.Len(),
+		BlockCacheSize:   d.cfg.BlockCacheSize,
+		FetchWorkers:     d.fetchConcurrency.Limit(),
+		MaxTxSize:        d.MaxTxSize(),
+		MemoryQuotaInUse: d.cfg.MemoryQuota.InUse(),
+	}
+}
+
 // GetBatchBlockRange returns the start and end L2 block heights that need to be
 // processed. Note that the end value is *exclusive*, therefore if the returned
 // values are identical nothing needs to be processed.
 func (d *Driver) GetBatchBlockRange(
 	ctx context.Context) (*big.Int, *big.Int, error) {
 
+	start, end, _, err := d.GetDeferrableBlockRange(ctx)
+	return start, end, err
+}
+
+// GetDeferrableBlockRange is GetBatchBlockRange, additionally reporting whether
+// a non-empty pending range was deferred as too small to be worth a batch tx.
+func (d *Driver) GetDeferrableBlockRange(
+	ctx context.Context) (*big.Int, *big.Int, bool, error) {
+
+	start, end, err := d.GetPendingBlockRange(ctx)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	// Report an empty range while the pending range is too small to be
+	// worth a batch tx.
+	shouldSubmit, err := d.shouldSubmitRange(ctx, start, end)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if !shouldSubmit {
+		return start, start, true, nil
+	}
+
+	return start, end, false, nil
+}
+
+// GetPendingBlockRange is GetBatchBlockRange, without deferring pending ranges
+// too small to be worth a batch tx.
+func (d *Driver) GetPendingBlockRange(
+	ctx context.Context) (*big.Int, *big.Int, error) {
+
 	blockOffset := new(big.Int).SetUint64(d.cfg.BlockOffset)
 
 	start, err := d.ctcContract.GetTotalElements(&bind.CallOpts{
@@ -115,6 +354,9 @@ func (d *Driver) GetBatchBlockRange(
 	}
 	start.Add(start, blockOffset)
 
+	if err := d.waitRPCQuota(ctx); err != nil {
+		return nil, nil, err
+	}
 	latestHeader, err := d.cfg.L2Client.HeaderByNumber(ctx, nil)
 	if err != nil {
 		return nil, nil, err
@@ -124,13 +366,43 @@ func (d *Driver) GetBatchBlockRange(
 	end := new(big.Int).Add(latestHeader.Number, bigOne)
 
 	if start.Cmp(end) > 0 {
-		return nil, nil, fmt.Errorf("invalid range, "+
-			"end(%v) < start(%v)", end, start)
+		return nil, nil, fmt.Errorf("%w: l2 head %v, next block %v",
+			ErrL2BehindCTC, latestHeader.Number, start)
 	}
 
 	return start, end, nil
 }
 
+// OldestPendingQueueElement returns the L1 timestamp of the oldest queue
+// element yet to be appended to the CTC, or false if none is pending.
+func (d *Driver) OldestPendingQueueElement(
+	ctx context.Context) (time.Time, bool, error) {
+
+	opts := &bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	}
+
+	nextIndex, err := d.ctcContract.GetNextQueueIndex(opts)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	length, err := d.ctcContract.GetQueueLength(opts)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if nextIndex.Cmp(length) >= 0 {
+		return time.Time{}, false, nil
+	}
+
+	element, err := d.ctcContract.GetQueueElement(opts, nextIndex)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	return time.Unix(element.Timestamp.Int64(), 0), true, nil
+}
+
 // SubmitBatchTx transforms the L2 blocks between start and end into a batch
 // transaction using the given nonce and gasPrice. The final transaction is
 // published and returned to the call.
@@ -138,50 +410,195 @@ func (d *Driver) SubmitBatchTx(
 	ctx context.Context,
 	start, end, nonce, gasPrice *big.Int) (*types.Transaction, error) {
 
-	name := d.cfg.Name
-
-	log.Info(name+" submitting batch tx", "start", start, "end", end,
+	log.Info(d.cfg.Name+" submitting batch tx", "start", start, "end", end,
 		"gasPrice", gasPrice)
 
+	batch, err := d.BuildBatch(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.SubmitBuiltBatch(ctx, batch, nonce, gasPrice)
+}
+
+// BuildBatch transforms the L2 blocks between start and end into the calldata
+// of a batch transaction, without signing or publishing it. The returned batch
+// may cover fewer blocks than requested if the full range would exceed the
+// maximum tx size.
+func (d *Driver) BuildBatch(
+	ctx context.Context, start, end *big.Int) (*queue.Batch, error) {
+
+	name := d.cfg.Name
+	maxTxSize := d.MaxTxSize()
+
 	batchTxBuildStart := time.Now()
 
 	var (
 		batchElements []BatchElement
+		blockHashes   []common.Hash
 		totalTxSize   uint64
+		numFetched    int
+		prevBlock     *l2types.Block
+		reserved      uint64
+		exhausted     bool
+
+		// malformedErr is the error of the malformed block before
+		// which a batch was cut short, if any.
+		malformedErr error
+
+		// next is the first element excluded from a batch cut short
+		// of its block range.
+		next *BatchElement
 	)
-	for i := new(big.Int).Set(start); i.Cmp(end) < 0; i.Add(i, bigOne) {
-		block, err := d.cfg.L2Client.BlockByNumber(ctx, i)
+	defer func() {
+		d.cfg.MemoryQuota.Release(reserved)
+		d.metrics.MemoryQuotaInUse.Set(float64(d.cfg.MemoryQuota.InUse()))
+	}()
+
+	// Blocks are fetched in windows, each of which is fetched
+	// concurrently. This allows us to stop fetching as soon as the size
+	// limit is reached, without serializing every request to the L2
+	// backend. The size of each window is determined by the fetch
+	// concurrency controller.
+	fetchStart := time.Now()
+
+fetchLoop:
+	for i := start.Uint64(); i < end.Uint64(); {
+		numWorkers := d.fetchConcurrency.Limit()
+		windowEnd := i + uint64(numWorkers)
+		if windowEnd > end.Uint64() {
+			windowEnd = end.Uint64()
+		}
+
+		blocks, err := d.fetchBlockWindow(
+			ctx, i, windowEnd, numWorkers, d.fetchBlock,
+		)
+		if err != nil {
+			return nil, err
+		}
+		blocks, err = d.ensureChainContinuity(
+			ctx, prevBlock, blocks, i, windowEnd, numWorkers,
+		)
 		if err != nil {
 			return nil, err
 		}
+		i = windowEnd
+		numFetched += len(blocks)
+		prevBlock = blocks[len(blocks)-1]
+
+		for _, block := range blocks {
+			batchElement, err := ParseBatchElement(block)
+			if err != nil {
+				if err := d.quarantineBlock(block, err); err != nil {
+					return nil, err
+				}
+				malformedErr = fmt.Errorf("block %d: %w",
+					block.NumberU64(), err)
+				log.Info(name+" batch cut short before "+
+					"malformed block", "num_blocks",
+					len(batchElements))
+				break fetchLoop
+			}
+			err = d.checkSystemTx(block.NumberU64(), batchElement)
+			if err != nil {
+				return nil, err
+			}
 
-		// For each sequencer transaction, update our running total with the
-		// size of the transaction.
-		batchElement := BatchElementFromBlock(block)
-		if batchElement.IsSequencerTx() {
-			// Abort once the total size estimate is greater than the maximum
-			// configured size. This is a conservative estimate, as the total
-			// calldata size will be greater when batch contexts are included.
-			// Below this set will be further whittled until the raw call data
-			// size also adheres to this constraint.
-			txLen := batchElement.Tx.Size()
-			if totalTxSize+uint64(TxLenSize+txLen) > d.cfg.MaxTxSize {
-				break
+			// Cut the batch short once the blocks it holds exhaust
+			// the memory quota.
+			blockSize := uint64(block.Size())
+			if !d.cfg.MemoryQuota.TryAcquire(blockSize) {
+				d.metrics.MemoryQuotaExhausted.Inc()
+				exhausted = true
+				next = &batchElement
+				log.Info(name+" memory quota exhausted, "+
+					"submitting early", "num_blocks",
+					len(batchElements))
+				break fetchLoop
 			}
-			totalTxSize += uint64(TxLenSize + txLen)
+			reserved += blockSize
+			d.metrics.MemoryQuotaInUse.Set(
+				float64(d.cfg.MemoryQuota.InUse()),
+			)
+
+			// For each sequencer transaction, update our running total
+			// with the size of the transaction.
+			if batchElement.IsSequencerTx() {
+				// Abort once the total size estimate is greater than
+				// the maximum configured size. This is a conservative
+				// estimate, as the total calldata size will be greater
+				// when batch contexts are included. Below this set
+				// will be further whittled until the raw call data
+				// size also adheres to this constraint.
+				txLen := batchElement.Tx.Size()
+				if totalTxSize+uint64(TxLenSize+txLen) > maxTxSize {
+					next = &batchElement
+					break fetchLoop
+				}
+				totalTxSize += uint64(TxLenSize + txLen)
+			}
+
+			batchElements = append(batchElements, batchElement)
+			blockHashes = append(blockHashes, common.Hash(block.Hash()))
 		}
+	}
+
+	// If the quota is held entirely by other batches, there is nothing to
+	// submit until they are released.
+	if exhausted && len(batchElements) == 0 {
+		return nil, quota.ErrMemoryExhausted
+	}
+
+	// Nothing can be submitted while a malformed block heads the range.
+	switch {
+	case malformedErr == nil:
+		d.malformedBlocks.reset()
+	case len(batchElements) == 0:
+		return nil, malformedErr
+	}
+
+	// A batch cut short of its block range is trimmed to end at the
+	// configured boundary, leaving the rest for the next batch.
+	if next != nil {
+		batchElements = d.alignBatchBoundary(batchElements, *next)
+	}
+
+	// A batch spanning too many contexts is cut short after its last
+	// allowed context.
+	batchElements, err := d.limitBatchContexts(batchElements)
+	if err != nil {
+		return nil, err
+	}
+
+	// A batch executing queue elements yet to be enqueued on L1 would
+	// revert, so it is cut short before the first of them.
+	batchElements, err = d.trimUnenqueued(ctx, batchElements)
+	if err != nil {
+		return nil, err
+	}
+
+	// A batch holding contexts ahead of its expected inclusion would
+	// revert, so it is cut short before the first of them.
+	batchElements, err = d.trimFutureContexts(ctx, batchElements)
+	if err != nil {
+		return nil, err
+	}
 
-		batchElements = append(batchElements, batchElement)
+	// Record the block fetch throughput.
+	if fetchTime := time.Since(fetchStart).Seconds(); fetchTime > 0 {
+		d.metrics.BlockFetchThroughput.Set(float64(numFetched) / fetchTime)
 	}
 
 	shouldStartAt := start.Uint64()
 	for {
 		batchParams, err := GenSequencerBatchParams(
 			shouldStartAt, d.cfg.BlockOffset, batchElements,
+			d.cfg.BatchLimits,
 		)
 		if err != nil {
 			return nil, err
 		}
+		batchParams.Version = d.cfg.BatchVersion
 
 		batchArguments, err := batchParams.Serialize()
 		if err != nil {
@@ -192,10 +609,17 @@ func (d *Driver) SubmitBatchTx(
 		batchCallData := append(appendSequencerBatchID, batchArguments...)
 
 		// Continue pruning until calldata size is less than configured max.
-		if uint64(len(batchCallData)) > d.cfg.MaxTxSize {
+		if uint64(len(batchCallData)) > maxTxSize {
 			oldLen := len(batchElements)
 			newBatchElementsLen := (oldLen * 9) / 10
-			batchElements = batchElements[:newBatchElementsLen]
+			pruned := batchElements[:newBatchElementsLen]
+			if newBatchElementsLen < oldLen {
+				pruned = d.alignBatchBoundary(
+					pruned, batchElements[newBatchElementsLen],
+				)
+			}
+			batchElements = pruned
+			newBatchElementsLen = len(batchElements)
 			log.Info(name+" pruned batch", "old_num_txs", oldLen, "new_num_txs", newBatchElementsLen)
 			continue
 		}
@@ -203,20 +627,464 @@ func (d *Driver) SubmitBatchTx(
 		// Record the batch_tx_build_time.
 		batchTxBuildTime := float64(time.Since(batchTxBuildStart) / time.Millisecond)
 		d.metrics.BatchTxBuildTime.Set(batchTxBuildTime)
+		d.metrics.BatchBuildTime.Observe(batchTxBuildTime)
 		d.metrics.NumElementsPerBatch.Observe(float64(len(batchElements)))
+		d.recordSizeHeadroom(uint64(len(batchCallData)))
 
 		log.Info(name+" batch constructed", "num_txs", len(batchElements), "length", len(batchCallData))
 
-		opts, err := bind.NewKeyedTransactorWithChainID(
-			d.cfg.PrivKey, d.cfg.ChainID,
-		)
+		// Each element is built from a single block, such that the
+		// last block covered is that of the last element.
+		var endBlockHash common.Hash
+		if len(batchElements) > 0 {
+			endBlockHash = blockHashes[len(batchElements)-1]
+		}
+
+		return &queue.Batch{
+			Start:        start.Uint64(),
+			End:          start.Uint64() + uint64(len(batchElements)),
+			CallData:     batchCallData,
+			EndBlockHash: endBlockHash,
+			CreatedAt:    time.Now(),
+		}, nil
+	}
+}
+
+// SubmitBuiltBatch signs and publishes a previously built batch using the
+// given nonce and gasPrice.
+func (d *Driver) SubmitBuiltBatch(
+	ctx context.Context,
+	batch *queue.Batch,
+	nonce, gasPrice *big.Int,
+) (*types.Transaction, error) {
+
+	// Since this method is invoked on each fee bump, validating here
+	// ensures that a batch which became stale while waiting in the mempool
+	// is checked against the latest L1 timestamp before being republished.
+	// A stale batch is deferred, such that fee bumping stops and the batch
+	// is rebuilt with contexts derived afresh.
+	if d.cfg.MaxContextDrift > 0 {
+		batchParams, err := decodeBatchCallData(batch.CallData)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.validateContextDrift(ctx, batchParams.Contexts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(
+		d.wallets.Key(), d.cfg.ChainID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	opts.Nonce = nonce
+	opts.Context = ctx
+	opts.GasPrice = gasPrice
+	opts.NoSend = true
+
+	// A batch building on batches still in flight reverts when simulated
+	// against the latest L1 state, so it can neither be preflighted nor
+	// have its gas estimated. Such batches are submitted at the max gas
+	// limit instead.
+	pipelined, err := d.buildsOnUnconfirmed(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	if pipelined {
+		if d.cfg.MaxGasLimit == 0 {
+			return nil, ErrPipelinedGasLimitNotSet
+		}
+		opts.GasLimit = d.cfg.MaxGasLimit
+	} else {
+		if err := d.preflightBatch(ctx, batch.CallData); err != nil {
+			return nil, err
+		}
+
+		gasLimit, err := d.estimateGasLimit(ctx, batch.CallData, gasPrice)
 		if err != nil {
 			return nil, err
 		}
-		opts.Nonce = nonce
-		opts.Context = ctx
-		opts.GasPrice = gasPrice
+		opts.GasLimit = gasLimit
+	}
+
+	// Never pay more for a batch than the L2 txs it carries are worth.
+	// The batch is instead deferred, and rebuilt once it covers more txs.
+	err = d.checkFeeCeiling(batch, gasPrice, opts.GasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sign and publish separately, so that the duration of each can be
+	// measured independently.
+	batchTxSignStart := time.Now()
+	tx, err := d.rawCtcContract.RawTransact(opts, batch.CallData)
+	if err != nil {
+		return nil, err
+	}
+	d.metrics.BatchSignTime.Observe(
+		float64(time.Since(batchTxSignStart) / time.Millisecond),
+	)
+
+	if d.cfg.DryRun {
+		return tx, nil
+	}
+
+	if err := d.cfg.Publisher.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
+
+// decodeBatchCallData parses the AppendSequencerBatchParams from the calldata
+// of an appendSequencerBatch call, including the 4-byte method selector.
+func decodeBatchCallData(callData []byte) (*AppendSequencerBatchParams, error) {
+	if len(callData) < 4 {
+		return nil, fmt.Errorf("batch calldata too short: %d bytes",
+			len(callData))
+	}
+
+	var params AppendSequencerBatchParams
+	if err := params.Read(bytes.NewReader(callData[4:])); err != nil {
+		return nil, err
+	}
+
+	return &params, nil
+}
+
+// recordSizeHeadroom records how close a batch of the given size came to the
+// maximum tx size, allowing operators to distinguish underfilled batches from
+// those clipped by the size limit.
+func (d *Driver) recordSizeHeadroom(size uint64) {
+	maxTxSize := d.MaxTxSize()
+	if maxTxSize == 0 {
+		return
+	}
+
+	var headroom uint64
+	if size < maxTxSize {
+		headroom = maxTxSize - size
+	}
+	d.metrics.BatchSizeHeadroom.Set(float64(headroom))
+	d.metrics.BatchSizeUtilization.Observe(
+		float64(size) / float64(maxTxSize),
+	)
+}
+
+// ensureChainContinuity validates that the blocks of the window [start, end)
+// extend prev as a single chain. On a discontinuity, the window and prev are
+// evicted from the block cache so that they are refetched by a later cycle,
+// and the window is refetched from the SecondaryL2Client, if configured.
+func (d *Driver) ensureChainContinuity(
+	ctx context.Context,
+	prev *l2types.Block,
+	blocks []*l2types.Block,
+	start, end uint64,
+	numWorkers int,
+) ([]*l2types.Block, error) {
+
+	name := d.cfg.Name
+
+	err := ValidateChainContinuity(prev, blocks)
+	if err == nil {
+		return blocks, nil
+	}
+	d.metrics.L2ChainDiscontinuities.Inc()
+	log.Warn(name+" L2 chain discontinuity detected", "err", err)
+
+	if prev != nil {
+		d.blockCache.Remove(prev.NumberU64())
+	}
+	for number := start; number < end; number++ {
+		d.blockCache.Remove(number)
+	}
+
+	if d.cfg.SecondaryL2Client == nil {
+		return nil, err
+	}
+
+	log.Info(name+" refetching blocks from secondary L2 backend",
+		"start", start, "end", end)
+	blocks, err = d.fetchBlockWindow(
+		ctx, start, end, numWorkers, d.fetchSecondaryBlock,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateChainContinuity(prev, blocks); err != nil {
+		d.metrics.L2ChainDiscontinuities.Inc()
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// fetchBlockWindow concurrently fetches the L2 blocks in [start, end) using at
+// most numWorkers invocations of fetch, and reports the average request
+// latency to the fetch concurrency controller.
+func (d *Driver) fetchBlockWindow(
+	ctx context.Context,
+	start, end uint64,
+	numWorkers int,
+	fetch BlockFetchFunc,
+) ([]*l2types.Block, error) {
+
+	var (
+		mu           sync.Mutex
+		totalLatency time.Duration
+		numRequests  int64
+	)
+	timedFetch := func(
+		ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+		requestStart := time.Now()
+		block, err := fetch(ctx, number)
+
+		mu.Lock()
+		totalLatency += time.Since(requestStart)
+		numRequests++
+		mu.Unlock()
+
+		return block, err
+	}
+
+	blocks, err := FetchBlocks(ctx, start, end, numWorkers, timedFetch)
+
+	var avgLatency time.Duration
+	if numRequests > 0 {
+		avgLatency = totalLatency / time.Duration(numRequests)
+	}
+	limit := d.fetchConcurrency.Observe(avgLatency, err != nil)
+	d.metrics.FetchWorkers.Set(float64(limit))
+
+	return blocks, err
+}
+
+// fetchBlock returns the L2 block at the given height, consulting the block
+// cache before querying the L2 backend. Blocks fetched from the backend are
+// added to the cache so that subsequent cycles, e.g. after a pruned batch or a
+// failed submission, avoid refetching them. The cache is only consulted for the
+// hash of the canonical header at the given height, such that a block since
+// reorged out is never served.
+func (d *Driver) fetchBlock(
+	ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+	if err := d.waitRPCQuota(ctx); err != nil {
+		return nil, err
+	}
+	header, err := d.headerByNumber(ctx, d.cfg.L2Client, number)
+	if err != nil {
+		return nil, err
+	}
+
+	block, ok := d.blockCache.Get(number.Uint64(), header.Hash())
+	if ok {
+		d.metrics.BlockCacheHits.Inc()
+		return block, nil
+	}
+	d.metrics.BlockCacheMisses.Inc()
 
-		return d.rawCtcContract.RawTransact(opts, batchCallData)
+	if err := d.waitRPCQuota(ctx); err != nil {
+		return nil, err
+	}
+	block, err = d.blockByNumber(ctx, d.cfg.L2Client, number)
+	if err != nil {
+		return nil, err
+	}
+	d.blockCache.Add(block)
+
+	return block, nil
+}
+
+// fetchSecondaryBlock returns the L2 block at the given height from the
+// SecondaryL2Client, bypassing the block cache. The block is added to the
+// cache, replacing any conflicting block fetched from the L2Client.
+func (d *Driver) fetchSecondaryBlock(
+	ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+	if err := d.waitRPCQuota(ctx); err != nil {
+		return nil, err
+	}
+	block, err := d.blockByNumber(ctx, d.cfg.SecondaryL2Client, number)
+	if err != nil {
+		return nil, err
+	}
+	d.blockCache.Add(block)
+
+	return block, nil
+}
+
+// alignBatchBoundary trims elements, a batch cut short before next, such that
+// it ends at the configured BatchBoundary.
+func (d *Driver) alignBatchBoundary(
+	elements []BatchElement, next BatchElement) []BatchElement {
+
+	aligned := AlignBatchBoundary(d.cfg.BatchBoundary, elements, next)
+	if len(aligned) < len(elements) {
+		log.Info(d.cfg.Name+" aligned batch to boundary", "boundary",
+			d.cfg.BatchBoundary, "old_num_txs", len(elements),
+			"new_num_txs", len(aligned))
+	}
+
+	return aligned
+}
+
+// limitBatchContexts trims elements such that their batch spans at most the
+// configured max contexts per batch, aligned to the configured BatchBoundary.
+func (d *Driver) limitBatchContexts(
+	elements []BatchElement) ([]BatchElement, error) {
+
+	limits := d.cfg.BatchLimits
+	contexts, _, err := genBatchContexts(
+		elements, limits.maxTxsPerContext(),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	maxContexts := limits.maxContextsPerBatch()
+	if uint64(len(contexts)) <= maxContexts {
+		return elements, nil
+	}
+
+	var numElements uint64
+	for _, context := range contexts[:maxContexts] {
+		numElements += context.NumSequencedTxs +
+			context.NumSubsequentQueueTxs
+	}
+	log.Info(d.cfg.Name+" batch exceeds max contexts, submitting early",
+		"num_contexts", len(contexts), "max_contexts", maxContexts,
+		"old_num_txs", len(elements), "new_num_txs", numElements)
+
+	return d.alignBatchBoundary(
+		elements[:numElements], elements[numElements],
+	), nil
+}
+
+// fetchUncachedBlock returns the L2 block at the given height from the
+// L2Client, bypassing the block cache.
+func (d *Driver) fetchUncachedBlock(
+	ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+	if err := d.waitRPCQuota(ctx); err != nil {
+		return nil, err
+	}
+	return d.blockByNumber(ctx, d.cfg.L2Client, number)
+}
+
+// blockByNumber returns the L2 block at the given height from client, bounded
+// by the RPC timeout.
+func (d *Driver) blockByNumber(
+	ctx context.Context,
+	client l2client.Client,
+	number *big.Int,
+) (*l2types.Block, error) {
+
+	if d.cfg.RPCTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.cfg.RPCTimeout)
+		defer cancel()
+	}
+
+	return client.BlockByNumber(ctx, number)
+}
+
+// headerByNumber returns the header of the L2 block at the given height from
+// client, bounded by the RPC timeout.
+func (d *Driver) headerByNumber(
+	ctx context.Context,
+	client l2client.Client,
+	number *big.Int,
+) (*l2types.Header, error) {
+
+	if d.cfg.RPCTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.cfg.RPCTimeout)
+		defer cancel()
+	}
+
+	return client.HeaderByNumber(ctx, number)
+}
+
+// L2BlockHash returns the hash of the L2 block at number from the L2Client,
+// bypassing the block cache such that a reorg is always observed.
+func (d *Driver) L2BlockHash(
+	ctx context.Context, number *big.Int) (common.Hash, error) {
+
+	block, err := d.fetchUncachedBlock(ctx, number)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.Hash(block.Hash()), nil
+}
+
+// waitRPCQuota blocks until an L2 request may be made within the RPC quota.
+func (d *Driver) waitRPCQuota(ctx context.Context) error {
+	delay := d.cfg.RPCQuota.Reserve()
+	if delay == 0 {
+		return nil
+	}
+
+	d.metrics.RPCQuotaThrottled.Inc()
+	d.metrics.RPCQuotaWaiting.Inc()
+	defer d.metrics.RPCQuotaWaiting.Dec()
+
+	if err := quota.Sleep(ctx, delay); err != nil {
+		return err
 	}
+	d.metrics.RPCQuotaWaitTime.Add(float64(delay / time.Millisecond))
+
+	return nil
+}
+
+// estimateGasLimit queries the L1 backend for the gas required to submit the
+// given calldata to the CTC, returning the estimate padded with the configured
+// safety margin.
+func (d *Driver) estimateGasLimit(
+	ctx context.Context,
+	callData []byte,
+	gasPrice *big.Int,
+) (uint64, error) {
+
+	name := d.cfg.Name
+
+	estimate, err := d.cfg.L1Client.EstimateGas(ctx, ethereum.CallMsg{
+		From:     d.wallets.Addr(),
+		To:       &d.cfg.CTCAddr,
+		GasPrice: gasPrice,
+		Data:     callData,
+	})
+	if err != nil {
+		log.Error(name+" unable to estimate gas", "length",
+			len(callData), "gasPrice", gasPrice, "err", err)
+		return 0, fmt.Errorf("unable to estimate gas: %w", err)
+	}
+
+	gasLimit, err := ApplyGasLimitMargin(
+		estimate, d.cfg.GasLimitMultiplier, d.cfg.GasLimitBuffer,
+		d.cfg.MaxGasLimit,
+	)
+	if err != nil {
+		log.Error(name+" gas estimate exceeds cap", "estimate",
+			estimate, "max_gas_limit", d.cfg.MaxGasLimit)
+		return 0, err
+	}
+
+	d.metrics.GasEstimate.Set(float64(estimate))
+	d.metrics.GasLimit.Set(float64(gasLimit))
+	if d.cfg.MaxGasLimit > 0 {
+		d.metrics.GasLimitHeadroom.Set(float64(d.cfg.MaxGasLimit - gasLimit))
+		d.metrics.GasLimitUtilization.Observe(
+			float64(gasLimit) / float64(d.cfg.MaxGasLimit),
+		)
+	}
+
+	log.Info(name+" estimated gas", "estimate", estimate,
+		"gas_limit", gasLimit)
+
+	return gasLimit, nil
 }
@@ -0,0 +1,54 @@
+package sequencer_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChains returns an L1 chain and an L2 chain holding only its genesis
+// block, both closed once the test completes.
+func newTestChains(t *testing.T) (*testutil.L1, *testutil.L2) {
+	l1, err := testutil.NewL1(big.NewInt(901), common.HexToAddress("0xc7c"))
+	require.Nil(t, err)
+	t.Cleanup(l1.Close)
+
+	l2, err := testutil.NewL2()
+	require.Nil(t, err)
+	t.Cleanup(l2.Close)
+
+	return l1, l2
+}
+
+// newTestDriver returns a Driver building batches of the blocks of l2 for the
+// CTC of l1 from a fresh key, with cfg supplying its name and any options
+// beyond those of a minimal driver.
+func newTestDriver(
+	t *testing.T,
+	l1 *testutil.L1,
+	l2 *testutil.L2,
+	cfg sequencer.Config,
+) *sequencer.Driver {
+
+	privKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	cfg.L1Client = l1.Client()
+	cfg.L2Client = l2.Client()
+	cfg.BlockOffset = 1
+	cfg.MaxTxSize = 128 * 1024
+	cfg.CTCAddr = l1.CTCAddr()
+	cfg.ChainID = l1.ChainID()
+	cfg.PrivKey = privKey
+	cfg.NumFetchWorkers = 2
+
+	driver, err := sequencer.NewDriver(cfg)
+	require.Nil(t, err)
+
+	return driver
+}
@@ -0,0 +1,51 @@
+package sequencer
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+)
+
+// checkFeeCeiling returns an error wrapping txmgr.ErrFeeCeilingReached if the
+// fee of publishing batch at gasPrice and gasLimit exceeds the batch's
+// economic value. No ceiling applies if MaxFeePerL2Tx is unset. Excluded system
+// txs carry no economic value.
+func (d *Driver) checkFeeCeiling(
+	batch *queue.Batch,
+	gasPrice *big.Int,
+	gasLimit uint64,
+) error {
+
+	if d.cfg.MaxFeePerL2Tx == nil || d.cfg.MaxFeePerL2Tx.Sign() == 0 {
+		return nil
+	}
+
+	// Each L2 block contains exactly one tx.
+	numTxs := batch.End - batch.Start
+	if d.cfg.SystemTxPolicy == SystemTxExclude {
+		params, err := decodeBatchCallData(batch.CallData)
+		if err != nil {
+			return err
+		}
+		for _, tx := range params.Txs {
+			if d.excludesSystemTx(BatchElement{Tx: tx}) {
+				numTxs--
+			}
+		}
+	}
+
+	ceiling := new(big.Int).Mul(
+		d.cfg.MaxFeePerL2Tx, new(big.Int).SetUint64(numTxs),
+	)
+	fee := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(gasLimit))
+	if fee.Cmp(ceiling) > 0 {
+		d.metrics.FeeCeilingExceeded.Inc()
+		return fmt.Errorf("%w: start=%d end=%d fee=%v ceiling=%v",
+			txmgr.ErrFeeCeilingReached, batch.Start, batch.End, fee,
+			ceiling)
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+package sequencer
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// TestCheckFeeCeiling asserts that a batch tx is rejected once its fee exceeds
+// the value of the L2 txs it carries, and that no ceiling applies if
+// MaxFeePerL2Tx is unset.
+func TestCheckFeeCeiling(t *testing.T) {
+	t.Parallel()
+
+	d := &Driver{
+		cfg: Config{
+			MaxFeePerL2Tx: big.NewInt(1000),
+		},
+		metrics: metrics.NewMetrics("fee_ceiling"),
+	}
+	batch := &queue.Batch{Start: 10, End: 20}
+
+	// The ceiling of 10 txs at 1000 wei each is 10000 wei.
+	require.Nil(t, d.checkFeeCeiling(batch, big.NewInt(100), 100))
+
+	err := d.checkFeeCeiling(batch, big.NewInt(101), 100)
+	require.True(t, errors.Is(err, txmgr.ErrFeeCeilingReached))
+
+	// A larger batch may be published at the same gas price.
+	larger := &queue.Batch{Start: 10, End: 21}
+	require.Nil(t, d.checkFeeCeiling(larger, big.NewInt(101), 100))
+
+	d.cfg.MaxFeePerL2Tx = nil
+	require.Nil(t, d.checkFeeCeiling(batch, big.NewInt(1e9), 1e6))
+}
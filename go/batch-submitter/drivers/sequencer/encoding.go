@@ -3,8 +3,10 @@ package sequencer
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 
 	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
@@ -17,6 +19,101 @@ const (
 	TxLenSize = 3
 )
 
+// BatchVersion identifies the encoding of the contexts of a batch.
+type BatchVersion uint64
+
+const (
+	// BatchVersionV0 encodes each context in full, and is the encoding
+	// expected by the CTC.
+	BatchVersionV0 BatchVersion = 0
+
+	// BatchVersionV1 delta encodes the timestamps and block numbers of the
+	// contexts, merging runs of adjacent contexts that share the same
+	// deltas and tx counts.
+	BatchVersionV1 BatchVersion = 1
+
+	// BatchVersionV2 prefixes the contexts with a BatchHeader, describing
+	// the encoder that produced the batch and how its contexts are
+	// encoded.
+	BatchVersionV2 BatchVersion = 2
+)
+
+// EncoderVersion identifies the revision of this encoder, and is recorded in
+// the BatchHeader of each BatchVersionV2 batch. It must be bumped whenever the
+// output of the encoder changes, such that historical batches can be matched
+// with the tooling able to decode them.
+const EncoderVersion = 1
+
+// BatchFlags are the options of a BatchVersionV2 encoding.
+type BatchFlags uint64
+
+const (
+	// BatchFlagDeltaContexts marks the contexts as delta encoded runs, as
+	// under BatchVersionV1, rather than encoded in full.
+	BatchFlagDeltaContexts BatchFlags = 1 << 0
+
+	// knownBatchFlags are the flags understood by this encoder.
+	knownBatchFlags = BatchFlagDeltaContexts
+)
+
+// BatchHeader describes the encoder of a BatchVersionV2 batch.
+type BatchHeader struct {
+	// EncoderVersion is the EncoderVersion of the encoder that produced
+	// the batch.
+	EncoderVersion uint64 `json:"encoder_version"`
+
+	// Flags are the options the batch was encoded with.
+	Flags BatchFlags `json:"flags"`
+}
+
+// DefaultBatchHeader returns the BatchHeader of batches produced by this
+// encoder, delta encoding their contexts.
+func DefaultBatchHeader() BatchHeader {
+	return BatchHeader{
+		EncoderVersion: EncoderVersion,
+		Flags:          BatchFlagDeltaContexts,
+	}
+}
+
+var (
+	// ErrUnknownBatchVersion signals an attempt to encode or decode a
+	// batch using an unsupported version.
+	ErrUnknownBatchVersion = errors.New("unknown batch version")
+
+	// ErrUnknownBatchFlags signals an attempt to encode or decode a batch
+	// using flags this encoder does not understand.
+	ErrUnknownBatchFlags = errors.New("unknown batch flags")
+
+	// ErrContextsNotMonotonic signals an attempt to delta encode contexts
+	// whose timestamps or block numbers decrease.
+	ErrContextsNotMonotonic = errors.New("batch context timestamps and " +
+		"block numbers must be non-decreasing")
+
+	// ErrContextsExceedCallData signals an attempt to decode a batch
+	// claiming more contexts than it has bytes of calldata left.
+	ErrContextsExceedCallData = errors.New("batch claims more contexts " +
+		"than its calldata could encode")
+)
+
+// ParseBatchVersion parses a BatchVersion from its name, either v0, v1 or v2.
+func ParseBatchVersion(name string) (BatchVersion, error) {
+	switch name {
+	case "v0":
+		return BatchVersionV0, nil
+	case "v1":
+		return BatchVersionV1, nil
+	case "v2":
+		return BatchVersionV2, nil
+	default:
+		return 0, fmt.Errorf("%w: %s", ErrUnknownBatchVersion, name)
+	}
+}
+
+// String returns the name of the BatchVersion.
+func (v BatchVersion) String() string {
+	return fmt.Sprintf("v%d", uint64(v))
+}
+
 var byteOrder = binary.BigEndian
 
 // BatchContext denotes a range of transactions that belong the same batch. It
@@ -71,12 +168,28 @@ func (c *BatchContext) Read(r io.Reader) error {
 	return readUint64(r, &c.BlockNumber, 5)
 }
 
+// isVersionMarker returns true if the BatchContext marks the remaining contexts
+// as following a versioned encoding, given by its BlockNumber. A marker spans
+// zero txs, which no context of a valid batch does.
+func (c *BatchContext) isVersionMarker() bool {
+	return c.NumSequencedTxs == 0 && c.NumSubsequentQueueTxs == 0 &&
+		c.Timestamp == 0 && c.BlockNumber != 0
+}
+
 // AppendSequencerBatchParams holds the raw data required to submit a batch of
 // L2 txs to L1 CTC contract. Rather than encoding the objects using the
 // standard ABI encoding, a custom encoding is and provided in the call data to
 // optimize for gas fees, since batch submission of L2 txs is a primary cost
 // driver.
 type AppendSequencerBatchParams struct {
+	// Version determines the encoding of Contexts. The zero value,
+	// BatchVersionV0, is the encoding expected by the CTC.
+	Version BatchVersion
+
+	// Header describes the encoder of a BatchVersionV2 batch. If nil when
+	// encoding, DefaultBatchHeader is used.
+	Header *BatchHeader
+
 	// ShouldStartAtElement specifies the intended starting sequence number
 	// of the provided transaction. Upon submission, this should match the
 	// CTC's expected value otherwise the transaction will revert.
@@ -97,7 +210,8 @@ type AppendSequencerBatchParams struct {
 	Txs []*CachedTx
 }
 
-// Write encodes the AppendSequencerBatchParams using the following format:
+// Write encodes the AppendSequencerBatchParams according to its Version. Under
+// BatchVersionV0, the following format is used:
 //  - should_start_at_element:        5 bytes
 //  - total_elements_to_append:       3 bytes
 //  - num_contexts:                   3 bytes
@@ -105,14 +219,67 @@ type AppendSequencerBatchParams struct {
 //  - [num txs ommitted]
 //    - tx_len:                       3 bytes
 //    - tx_bytes:                     tx_len bytes
+//
+// Later versions are prefixed by a marker context, whose block_number holds
+// the version and whose other fields are zero. Under BatchVersionV1, the marker
+// is followed by runs of contexts, each advancing the timestamp and block
+// number of the preceding context, or zero for the first, by the same deltas:
+//  - should_start_at_element:        5 bytes
+//  - total_elements_to_append:       3 bytes
+//  - num_contexts:                   3 bytes, including the marker
+//    - version_marker:               16 bytes
+//    - [num runs ommitted]
+//      - run_len:                    uvarint
+//      - num_sequenced_txs:          uvarint
+//      - num_subsequent_queue_txs:   uvarint
+//      - timestamp_delta:            uvarint
+//      - block_number_delta:         uvarint
+//  - [num txs ommitted]
+//    - tx_len:                       3 bytes
+//    - tx_bytes:                     tx_len bytes
+//
+// Under BatchVersionV2, the marker is followed by the BatchHeader, and then by
+// the contexts, either encoded as runs as under BatchVersionV1 or in full as
+// under BatchVersionV0, depending on its flags:
+//  - should_start_at_element:        5 bytes
+//  - total_elements_to_append:       3 bytes
+//  - num_contexts:                   3 bytes, including the marker
+//    - version_marker:               16 bytes
+//    - encoder_version:              uvarint
+//    - flags:                        uvarint
+//    - [contexts ommitted]
+//  - [num txs ommitted]
+//    - tx_len:                       3 bytes
+//    - tx_bytes:                     tx_len bytes
 func (p *AppendSequencerBatchParams) Write(w *bytes.Buffer) error {
 	writeUint64(w, p.ShouldStartAtElement, 5)
 	writeUint64(w, p.TotalElementsToAppend, 3)
 
-	// Write number of contexts followed by each fixed-size BatchContext.
-	writeUint64(w, uint64(len(p.Contexts)), 3)
-	for _, context := range p.Contexts {
-		context.Write(w)
+	switch p.Version {
+	case BatchVersionV0:
+		// Write number of contexts followed by each fixed-size
+		// BatchContext.
+		writeUint64(w, uint64(len(p.Contexts)), 3)
+		for _, context := range p.Contexts {
+			context.Write(w)
+		}
+
+	case BatchVersionV1:
+		if err := writeContextsV1(w, p.Contexts); err != nil {
+			return err
+		}
+
+	case BatchVersionV2:
+		header := DefaultBatchHeader()
+		if p.Header != nil {
+			header = *p.Header
+		}
+		if err := writeContextsV2(w, header, p.Contexts); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownBatchVersion, p.Version)
 	}
 
 	// Write each length-prefixed tx.
@@ -136,8 +303,12 @@ func (p *AppendSequencerBatchParams) Serialize() ([]byte, error) {
 
 // Read decodes the AppendSequencerBatchParams from a bytes stream. If the byte
 // stream does not terminate cleanly with an EOF while reading a tx_len, this
-// method will return an error. Otherwise, the stream will be parsed according
-// to the following format:
+// method will return an error. Since a run of delta encoded contexts may stand
+// for many contexts, a stream claiming more contexts than it has bytes left is
+// rejected with ErrContextsExceedCallData, bounding the memory of the decoded
+// contexts by the length of the stream. Otherwise, the stream will be parsed according
+// to the format of the version it is marked with, as described by Write, or
+// else the following BatchVersionV0 format:
 //  - should_start_at_element:        5 bytes
 //  - total_elements_to_append:       3 bytes
 //  - num_contexts:                   3 bytes
@@ -146,6 +317,18 @@ func (p *AppendSequencerBatchParams) Serialize() ([]byte, error) {
 //    - tx_len:                       3 bytes
 //    - tx_bytes:                     tx_len bytes
 func (p *AppendSequencerBatchParams) Read(r io.Reader) error {
+	// The remaining length bounds the number of contexts, so a stream that
+	// does not report it is read in full.
+	br, ok := r.(lenReader)
+	if !ok {
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		br = bytes.NewReader(data)
+	}
+	r = br
+
 	if err := readUint64(r, &p.ShouldStartAtElement, 5); err != nil {
 		return err
 	}
@@ -158,6 +341,10 @@ func (p *AppendSequencerBatchParams) Read(r io.Reader) error {
 	if err := readUint64(r, &numContexts, 3); err != nil {
 		return err
 	}
+	if numContexts > uint64(br.Len()) {
+		return fmt.Errorf("%w: num_contexts=%d remaining_len=%d",
+			ErrContextsExceedCallData, numContexts, br.Len())
+	}
 
 	for i := uint64(0); i < numContexts; i++ {
 		var batchContext BatchContext
@@ -165,6 +352,17 @@ func (p *AppendSequencerBatchParams) Read(r io.Reader) error {
 			return err
 		}
 
+		// A leading version marker is followed by the remaining
+		// contexts in the encoding of that version.
+		if i == 0 && batchContext.isVersionMarker() {
+			p.Version = BatchVersion(batchContext.BlockNumber)
+			err := p.readVersionedContexts(r, numContexts-1)
+			if err != nil {
+				return err
+			}
+			break
+		}
+
 		p.Contexts = append(p.Contexts, batchContext)
 	}
 
@@ -190,6 +388,287 @@ func (p *AppendSequencerBatchParams) Read(r io.Reader) error {
 	}
 }
 
+// readVersionedContexts decodes numContexts contexts following a version
+// marker, according to the Version of the AppendSequencerBatchParams.
+func (p *AppendSequencerBatchParams) readVersionedContexts(
+	r io.Reader, numContexts uint64) error {
+
+	switch p.Version {
+	case BatchVersionV1:
+		contexts, err := readContextsV1(r, numContexts)
+		if err != nil {
+			return err
+		}
+		p.Contexts = contexts
+		return nil
+
+	case BatchVersionV2:
+		header, contexts, err := readContextsV2(r, numContexts)
+		if err != nil {
+			return err
+		}
+		p.Header = header
+		p.Contexts = contexts
+		return nil
+
+	default:
+		return fmt.Errorf("%w: %v", ErrUnknownBatchVersion, p.Version)
+	}
+}
+
+// contextDelta is the difference between a BatchContext and the one preceding
+// it under BatchVersionV1.
+type contextDelta struct {
+	numSequencedTxs       uint64
+	numSubsequentQueueTxs uint64
+	timestamp             uint64
+	blockNumber           uint64
+}
+
+// contextRun is a run of adjacent contexts sharing the same contextDelta.
+type contextRun struct {
+	length uint64
+	delta  contextDelta
+}
+
+// writeContextsV1 writes the number of contexts and the version marker,
+// followed by the contexts encoded as runs of contextDeltas.
+func writeContextsV1(w *bytes.Buffer, contexts []BatchContext) error {
+	runs, err := contextRuns(contexts)
+	if err != nil {
+		return err
+	}
+
+	writeVersionMarker(w, BatchVersionV1, len(contexts))
+	writeContextRuns(w, runs)
+
+	return nil
+}
+
+// writeContextsV2 writes the number of contexts, the version marker and the
+// header, followed by the contexts encoded according to the header's flags.
+func writeContextsV2(
+	w *bytes.Buffer,
+	header BatchHeader,
+	contexts []BatchContext,
+) error {
+
+	if header.Flags&^knownBatchFlags != 0 {
+		return fmt.Errorf("%w: %#x", ErrUnknownBatchFlags, header.Flags)
+	}
+
+	var runs []contextRun
+	if header.Flags&BatchFlagDeltaContexts != 0 {
+		var err error
+		runs, err = contextRuns(contexts)
+		if err != nil {
+			return err
+		}
+	}
+
+	writeVersionMarker(w, BatchVersionV2, len(contexts))
+	writeUvarint(w, header.EncoderVersion)
+	writeUvarint(w, uint64(header.Flags))
+
+	if header.Flags&BatchFlagDeltaContexts != 0 {
+		writeContextRuns(w, runs)
+	} else {
+		for _, context := range contexts {
+			context.Write(w)
+		}
+	}
+
+	return nil
+}
+
+// writeVersionMarker writes the number of contexts, counting the marker,
+// followed by the marker of the given version.
+func writeVersionMarker(
+	w *bytes.Buffer,
+	version BatchVersion,
+	numContexts int,
+) {
+
+	writeUint64(w, uint64(numContexts)+1, 3)
+	marker := BatchContext{BlockNumber: uint64(version)}
+	marker.Write(w)
+}
+
+// contextRuns groups contexts into runs of adjacent contexts sharing the same
+// contextDelta.
+func contextRuns(contexts []BatchContext) ([]contextRun, error) {
+	var (
+		runs []contextRun
+		prev BatchContext
+	)
+	for i, context := range contexts {
+		if context.Timestamp < prev.Timestamp ||
+			context.BlockNumber < prev.BlockNumber {
+
+			return nil, fmt.Errorf("context %d: %w", i,
+				ErrContextsNotMonotonic)
+		}
+
+		delta := contextDelta{
+			numSequencedTxs:       context.NumSequencedTxs,
+			numSubsequentQueueTxs: context.NumSubsequentQueueTxs,
+			timestamp:             context.Timestamp - prev.Timestamp,
+			blockNumber:           context.BlockNumber - prev.BlockNumber,
+		}
+		if n := len(runs); n > 0 && runs[n-1].delta == delta {
+			runs[n-1].length++
+		} else {
+			runs = append(runs, contextRun{length: 1, delta: delta})
+		}
+		prev = context
+	}
+
+	return runs, nil
+}
+
+// writeContextRuns writes each run of contextDeltas.
+func writeContextRuns(w *bytes.Buffer, runs []contextRun) {
+	for _, run := range runs {
+		writeUvarint(w, run.length)
+		writeUvarint(w, run.delta.numSequencedTxs)
+		writeUvarint(w, run.delta.numSubsequentQueueTxs)
+		writeUvarint(w, run.delta.timestamp)
+		writeUvarint(w, run.delta.blockNumber)
+	}
+}
+
+// readContextsV1 decodes numContexts contexts encoded as runs of
+// contextDeltas.
+func readContextsV1(r io.Reader, numContexts uint64) ([]BatchContext, error) {
+	br := byteReader{r}
+
+	// Read bounds numContexts by the length of the calldata.
+	var (
+		contexts = make([]BatchContext, 0, numContexts)
+		prev     BatchContext
+	)
+	for uint64(len(contexts)) < numContexts {
+		var run contextRun
+		for _, val := range []*uint64{
+			&run.length,
+			&run.delta.numSequencedTxs,
+			&run.delta.numSubsequentQueueTxs,
+			&run.delta.timestamp,
+			&run.delta.blockNumber,
+		} {
+			if err := readUvarint(br, val); err != nil {
+				return nil, err
+			}
+		}
+
+		if run.length == 0 ||
+			run.length > numContexts-uint64(len(contexts)) {
+
+			return nil, fmt.Errorf("invalid batch context run "+
+				"length %d", run.length)
+		}
+
+		delta := run.delta
+		for i := uint64(0); i < run.length; i++ {
+			prev = BatchContext{
+				NumSequencedTxs:       delta.numSequencedTxs,
+				NumSubsequentQueueTxs: delta.numSubsequentQueueTxs,
+				Timestamp:             prev.Timestamp + delta.timestamp,
+				BlockNumber:           prev.BlockNumber + delta.blockNumber,
+			}
+			contexts = append(contexts, prev)
+		}
+	}
+
+	return contexts, nil
+}
+
+// readContextsV2 decodes the header following a BatchVersionV2 marker, and the
+// numContexts contexts encoded according to its flags.
+func readContextsV2(
+	r io.Reader,
+	numContexts uint64,
+) (*BatchHeader, []BatchContext, error) {
+
+	br := byteReader{r}
+
+	var header BatchHeader
+	if err := readUvarint(br, &header.EncoderVersion); err != nil {
+		return nil, nil, err
+	}
+	var flags uint64
+	if err := readUvarint(br, &flags); err != nil {
+		return nil, nil, err
+	}
+	header.Flags = BatchFlags(flags)
+	if header.Flags&^knownBatchFlags != 0 {
+		return nil, nil, fmt.Errorf("%w: %#x", ErrUnknownBatchFlags,
+			header.Flags)
+	}
+
+	if header.Flags&BatchFlagDeltaContexts != 0 {
+		contexts, err := readContextsV1(r, numContexts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return &header, contexts, nil
+	}
+
+	var contexts []BatchContext
+	for i := uint64(0); i < numContexts; i++ {
+		var batchContext BatchContext
+		if err := batchContext.Read(r); err != nil {
+			return nil, nil, err
+		}
+		contexts = append(contexts, batchContext)
+	}
+
+	return &header, contexts, nil
+}
+
+// lenReader is an io.Reader reporting the number of bytes left to read, such as
+// a bytes.Reader.
+type lenReader interface {
+	io.Reader
+	Len() int
+}
+
+// byteReader adapts an io.Reader to an io.ByteReader, without reading ahead of
+// the bytes requested.
+type byteReader struct {
+	io.Reader
+}
+
+// ReadByte reads a single byte from the underlying io.Reader.
+func (r byteReader) ReadByte() (byte, error) {
+	var buf [1]byte
+	if _, err := io.ReadFull(r.Reader, buf[:]); err != nil {
+		return 0, err
+	}
+	return buf[0], nil
+}
+
+// writeUvarint writes `val` to `w` as an unsigned varint.
+func writeUvarint(w *bytes.Buffer, val uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], val)
+	_, _ = w.Write(buf[:n]) // can't fail for bytes.Buffer
+}
+
+// readUvarint reads an unsigned varint from `r` into `val`. Since a varint is
+// only ever read where more of the stream is expected, an EOF is reported as
+// unexpected.
+func readUvarint(r io.ByteReader, val *uint64) error {
+	v, err := binary.ReadUvarint(r)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	} else if err != nil {
+		return err
+	}
+	*val = v
+	return nil
+}
+
 // writeUint64 writes a the bottom `n` bytes of `val` to `w`.
 func writeUint64(w *bytes.Buffer, val uint64, n uint) {
 	if n < 1 || n > 8 {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"encoding/json"
+	"io"
 	"os"
 	"testing"
 
@@ -303,3 +304,217 @@ func compareTxs(t *testing.T, a []*l2types.Transaction, b []*sequencer.CachedTx)
 		require.Equal(t, txA.Hash(), b[i].Tx().Hash())
 	}
 }
+
+// TestAppendSequencerBatchParamsV1EncodeDecode asserts the encoding and
+// decoding of a BatchVersionV1 batch against a test vector, whose contexts are
+// delta encoded as runs following the version marker:
+//  - [1 sequenced, 0 queued, +100 timestamp, +10 block number] x 1
+//  - [1 sequenced, 0 queued, +1 timestamp, +0 block number]    x 2
+//  - [2 sequenced, 1 queued, +0 timestamp, +1 block number]    x 1
+func TestAppendSequencerBatchParamsV1EncodeDecode(t *testing.T) {
+	t.Parallel()
+
+	hexEncoding := "0000000001000006" +
+		"000005" +
+		"00000000000000000000000000000001" +
+		"010100640a" +
+		"0201000100" +
+		"0102010001" +
+		"00000ac9808080808080808080"
+
+	expContexts := []sequencer.BatchContext{
+		{NumSequencedTxs: 1, Timestamp: 100, BlockNumber: 10},
+		{NumSequencedTxs: 1, Timestamp: 101, BlockNumber: 10},
+		{NumSequencedTxs: 1, Timestamp: 102, BlockNumber: 10},
+		{
+			NumSequencedTxs:       2,
+			NumSubsequentQueueTxs: 1,
+			Timestamp:             102,
+			BlockNumber:           11,
+		},
+	}
+
+	rawBytes, err := hex.DecodeString(hexEncoding)
+	require.Nil(t, err)
+
+	var params sequencer.AppendSequencerBatchParams
+	err = params.Read(bytes.NewReader(rawBytes))
+	require.Nil(t, err)
+	require.Equal(t, sequencer.BatchVersionV1, params.Version)
+	require.Equal(t, uint64(1), params.ShouldStartAtElement)
+	require.Equal(t, uint64(6), params.TotalElementsToAppend)
+	require.Equal(t, expContexts, params.Contexts)
+	require.Len(t, params.Txs, 1)
+	require.Equal(t, "c9808080808080808080",
+		hex.EncodeToString(params.Txs[0].RawTx()))
+
+	paramsBytes, err := params.Serialize()
+	require.Nil(t, err)
+	require.Equal(t, hexEncoding, hex.EncodeToString(paramsBytes))
+}
+
+// TestAppendSequencerBatchParamsV1RoundTrip asserts that a generated batch
+// survives a round trip through the BatchVersionV1 encoding, and that its
+// encoding is smaller than under BatchVersionV0.
+func TestAppendSequencerBatchParamsV1RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// Each tx is given its own timestamp, such that every context differs
+	// from its predecessor under BatchVersionV0.
+	var elements []sequencer.BatchElement
+	for i := uint64(0); i < 20; i++ {
+		elements = append(elements, newVerifyTestElements(1000+i, i)...)
+	}
+	params, err := sequencer.GenSequencerBatchParams(
+		11, 1, elements, sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	require.Len(t, params.Contexts, 20)
+
+	v0Bytes, err := params.Serialize()
+	require.Nil(t, err)
+
+	params.Version = sequencer.BatchVersionV1
+	v1Bytes, err := params.Serialize()
+	require.Nil(t, err)
+	require.Less(t, len(v1Bytes), len(v0Bytes))
+
+	var decoded sequencer.AppendSequencerBatchParams
+	err = decoded.Read(bytes.NewReader(v1Bytes))
+	require.Nil(t, err)
+	require.Equal(t, sequencer.BatchVersionV1, decoded.Version)
+	require.Empty(t, sequencer.CompareBatchParams(params, &decoded))
+
+	// Decoding the v0 encoding recovers the same batch.
+	var decodedV0 sequencer.AppendSequencerBatchParams
+	err = decodedV0.Read(bytes.NewReader(v0Bytes))
+	require.Nil(t, err)
+	require.Equal(t, sequencer.BatchVersionV0, decodedV0.Version)
+	require.Empty(t, sequencer.CompareBatchParams(params, &decodedV0))
+}
+
+// TestAppendSequencerBatchParamsV1Invalid asserts that contexts which cannot be
+// delta encoded, and unknown versions, are rejected.
+func TestAppendSequencerBatchParamsV1Invalid(t *testing.T) {
+	t.Parallel()
+
+	params := sequencer.AppendSequencerBatchParams{
+		Version: sequencer.BatchVersionV1,
+		Contexts: []sequencer.BatchContext{
+			{NumSequencedTxs: 1, Timestamp: 100, BlockNumber: 10},
+			{NumSequencedTxs: 1, Timestamp: 99, BlockNumber: 10},
+		},
+	}
+	_, err := params.Serialize()
+	require.ErrorIs(t, err, sequencer.ErrContextsNotMonotonic)
+
+	params.Version = 3
+	_, err = params.Serialize()
+	require.ErrorIs(t, err, sequencer.ErrUnknownBatchVersion)
+
+	// A marker for an unknown version is rejected on decoding.
+	rawBytes, err := hex.DecodeString("0000000001000000" +
+		"000001" +
+		"00000000000000000000000000000003")
+	require.Nil(t, err)
+
+	var decoded sequencer.AppendSequencerBatchParams
+	err = decoded.Read(bytes.NewReader(rawBytes))
+	require.ErrorIs(t, err, sequencer.ErrUnknownBatchVersion)
+}
+
+// TestAppendSequencerBatchParamsV1TooManyContexts asserts that a batch claiming
+// more contexts than its calldata could encode is rejected before any are
+// decoded, whether or not the stream reports its remaining length.
+func TestAppendSequencerBatchParamsV1TooManyContexts(t *testing.T) {
+	t.Parallel()
+
+	// A version marker followed by a single run of the max length.
+	rawBytes, err := hex.DecodeString("0000000001000000" +
+		"ffffff" +
+		"00000000000000000000000000000001" +
+		"feffff070000000000")
+	require.Nil(t, err)
+
+	var decoded sequencer.AppendSequencerBatchParams
+	err = decoded.Read(bytes.NewReader(rawBytes))
+	require.ErrorIs(t, err, sequencer.ErrContextsExceedCallData)
+	require.Empty(t, decoded.Contexts)
+
+	err = decoded.Read(io.MultiReader(bytes.NewReader(rawBytes)))
+	require.ErrorIs(t, err, sequencer.ErrContextsExceedCallData)
+}
+
+// TestAppendSequencerBatchParamsV2RoundTrip asserts that a generated batch
+// survives a round trip through the BatchVersionV2 encoding, with its contexts
+// either delta encoded or in full, and that the header is decoded alongside.
+func TestAppendSequencerBatchParamsV2RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	var elements []sequencer.BatchElement
+	for i := uint64(0); i < 20; i++ {
+		elements = append(elements, newVerifyTestElements(1000+i, i)...)
+	}
+	params, err := sequencer.GenSequencerBatchParams(
+		11, 1, elements, sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+
+	params.Version = sequencer.BatchVersionV1
+	v1Bytes, err := params.Serialize()
+	require.Nil(t, err)
+
+	// The default header delta encodes the contexts, adding only the
+	// encoder version and flags to the BatchVersionV1 encoding.
+	params.Version = sequencer.BatchVersionV2
+	v2Bytes, err := params.Serialize()
+	require.Nil(t, err)
+	require.Len(t, v2Bytes, len(v1Bytes)+2)
+
+	var decoded sequencer.AppendSequencerBatchParams
+	err = decoded.Read(bytes.NewReader(v2Bytes))
+	require.Nil(t, err)
+	require.Equal(t, sequencer.BatchVersionV2, decoded.Version)
+	require.Equal(t, sequencer.DefaultBatchHeader(), *decoded.Header)
+	require.Empty(t, sequencer.CompareBatchParams(params, &decoded))
+
+	// Without BatchFlagDeltaContexts, the contexts are encoded in full.
+	params.Header = &sequencer.BatchHeader{
+		EncoderVersion: sequencer.EncoderVersion,
+	}
+	fullBytes, err := params.Serialize()
+	require.Nil(t, err)
+	require.Greater(t, len(fullBytes), len(v2Bytes))
+
+	var decodedFull sequencer.AppendSequencerBatchParams
+	err = decodedFull.Read(bytes.NewReader(fullBytes))
+	require.Nil(t, err)
+	require.Equal(t, *params.Header, *decodedFull.Header)
+	require.Empty(t, sequencer.CompareBatchParams(params, &decodedFull))
+}
+
+// TestAppendSequencerBatchParamsV2UnknownFlags asserts that headers with flags
+// unknown to the encoder are rejected on both encoding and decoding.
+func TestAppendSequencerBatchParamsV2UnknownFlags(t *testing.T) {
+	t.Parallel()
+
+	params := sequencer.AppendSequencerBatchParams{
+		Version: sequencer.BatchVersionV2,
+		Header: &sequencer.BatchHeader{
+			EncoderVersion: sequencer.EncoderVersion,
+			Flags:          1 << 1,
+		},
+	}
+	_, err := params.Serialize()
+	require.ErrorIs(t, err, sequencer.ErrUnknownBatchFlags)
+
+	rawBytes, err := hex.DecodeString("0000000001000000" +
+		"000001" +
+		"00000000000000000000000000000002" +
+		"0102")
+	require.Nil(t, err)
+
+	var decoded sequencer.AppendSequencerBatchParams
+	err = decoded.Read(bytes.NewReader(rawBytes))
+	require.ErrorIs(t, err, sequencer.ErrUnknownBatchFlags)
+}
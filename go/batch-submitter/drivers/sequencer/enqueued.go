@@ -0,0 +1,71 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrQueueElementNotEnqueued signals that a batch would execute a queue element
+// yet to be enqueued in the CTC, such that it would revert on L1. It wraps
+// txmgr.ErrSendDeferred, such that submission is deferred rather than retried.
+var ErrQueueElementNotEnqueued = fmt.Errorf("%w: queue element not yet "+
+	"enqueued in the ctc", txmgr.ErrSendDeferred)
+
+// trimUnenqueued cuts elements short before the first queued tx executing a
+// queue element at or beyond the CTC's queue length, aligned to the configured
+// BatchBoundary. An error wrapping ErrQueueElementNotEnqueued is returned if
+// the first element is such a queued tx. Queued txs whose queue index is not
+// reported by the L2 node are not checked.
+func (d *Driver) trimUnenqueued(
+	ctx context.Context,
+	elements []BatchElement,
+) ([]BatchElement, error) {
+
+	var maxQueueIndex *uint64
+	for _, element := range elements {
+		if element.QueueIndex != nil {
+			maxQueueIndex = element.QueueIndex
+		}
+	}
+	if maxQueueIndex == nil {
+		return elements, nil
+	}
+
+	length, err := d.ctcContract.GetQueueLength(&bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get queue length: %w", err)
+	}
+	queueLength := length.Uint64()
+	if *maxQueueIndex < queueLength {
+		return elements, nil
+	}
+
+	for i, element := range elements {
+		if element.QueueIndex == nil || *element.QueueIndex < queueLength {
+			continue
+		}
+
+		d.metrics.QueueDeferrals.Inc()
+		if i == 0 {
+			return nil, fmt.Errorf("%w: queue index %d, queue length %d",
+				ErrQueueElementNotEnqueued, *element.QueueIndex,
+				queueLength)
+		}
+
+		log.Warn(d.cfg.Name+" batch cut short before queue element "+
+			"yet to be enqueued", "queue_index", *element.QueueIndex,
+			"queue_length", queueLength, "old_num_txs", len(elements),
+			"new_num_txs", i)
+
+		return d.alignBatchBoundary(elements[:i], element), nil
+	}
+
+	return elements, nil
+}
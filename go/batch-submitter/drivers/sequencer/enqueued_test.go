@@ -0,0 +1,52 @@
+package sequencer_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildBatchUnenqueuedQueueElement asserts that a batch is cut short before
+// a queued tx whose queue element is yet to be enqueued on L1, that no batch
+// is built while such a tx heads the range, and that the tx is batched once
+// its element is enqueued.
+func TestBuildBatchUnenqueuedQueueElement(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+
+	element := testutil.QueueElement{Timestamp: 102, BlockNumber: 1}
+	l2.AddBlock(100, testutil.SequencerTx(0, 1))
+	l2.AddBlock(101, testutil.SequencerTx(1, 1))
+	l2.AddBlock(element.Timestamp, testutil.QueueTx(0, element))
+	l2.AddBlock(103, testutil.SequencerTx(2, 1))
+
+	driver := newTestDriver(t, l1, l2, sequencer.Config{
+		Name: "enqueued_" + t.Name(),
+	})
+
+	batch, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(5),
+	)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), batch.End)
+
+	_, err = driver.BuildBatch(
+		context.Background(), big.NewInt(3), big.NewInt(5),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrQueueElementNotEnqueued))
+	require.True(t, errors.Is(err, txmgr.ErrSendDeferred))
+
+	l1.Enqueue(element)
+	batch, err = driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(5),
+	)
+	require.Nil(t, err)
+	require.Equal(t, uint64(5), batch.End)
+}
@@ -0,0 +1,72 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/ethereum/go-ethereum"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// blockClient is an l2client.Client serving its blocks by height.
+type blockClient map[uint64]*l2types.Block
+
+func (c blockClient) HeaderByNumber(
+	_ context.Context, number *big.Int) (*l2types.Header, error) {
+
+	block, ok := c[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return block.Header(), nil
+}
+
+func (c blockClient) BlockByNumber(
+	_ context.Context, number *big.Int) (*l2types.Block, error) {
+
+	block, ok := c[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return block, nil
+}
+
+// TestFetchBlockReorged asserts that a cached block is only served while it is
+// canonical, and that the canonical block is fetched once it was reorged out.
+func TestFetchBlockReorged(t *testing.T) {
+	t.Parallel()
+
+	oldBlock := l2types.NewBlock(&l2types.Header{
+		Number: big.NewInt(1),
+	}, nil, nil, nil)
+	newBlock := l2types.NewBlock(&l2types.Header{
+		Number: big.NewInt(1),
+		Time:   1,
+	}, nil, nil, nil)
+
+	l2Client := blockClient{1: oldBlock}
+	d := &Driver{
+		cfg: Config{
+			Name:     "fetch_block_reorged",
+			L2Client: l2Client,
+		},
+		blockCache: NewBlockCache(0),
+		metrics:    metrics.NewMetrics("fetch_block_reorged"),
+	}
+	d.blockCache.Add(oldBlock)
+
+	block, err := d.fetchBlock(context.Background(), big.NewInt(1))
+	require.Nil(t, err)
+	require.Equal(t, oldBlock.Hash(), block.Hash())
+	require.Equal(t, 1.0, testutil.ToFloat64(d.metrics.BlockCacheHits))
+
+	l2Client[1] = newBlock
+	block, err = d.fetchBlock(context.Background(), big.NewInt(1))
+	require.Nil(t, err)
+	require.Equal(t, newBlock.Hash(), block.Hash())
+	require.Equal(t, 1.0, testutil.ToFloat64(d.metrics.BlockCacheMisses))
+}
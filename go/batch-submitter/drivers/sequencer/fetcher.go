@@ -0,0 +1,96 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"sync"
+
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+)
+
+// BlockFetchFunc retrieves the L2 block at the given height.
+type BlockFetchFunc = func(
+	ctx context.Context, number *big.Int) (*l2types.Block, error)
+
+// FetchBlocks retrieves the L2 blocks in the range [start, end) using at most
+// numWorkers concurrent invocations of fetch. The returned blocks are ordered
+// by ascending height, regardless of the order in which the requests complete.
+// If any request fails, the remaining requests are canceled and the first
+// error encountered is returned.
+func FetchBlocks(
+	ctx context.Context,
+	start, end uint64,
+	numWorkers int,
+	fetch BlockFetchFunc,
+) ([]*l2types.Block, error) {
+
+	if end <= start {
+		return nil, nil
+	}
+
+	numBlocks := end - start
+	if numWorkers < 1 {
+		numWorkers = 1
+	}
+	if uint64(numWorkers) > numBlocks {
+		numWorkers = int(numBlocks)
+	}
+
+	ctxc, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		blocks   = make([]*l2types.Block, numBlocks)
+		indexes  = make(chan uint64)
+		wg       sync.WaitGroup
+		errMu    sync.Mutex
+		firstErr error
+	)
+
+	wg.Add(numWorkers)
+	for i := 0; i < numWorkers; i++ {
+		go func() {
+			defer wg.Done()
+
+			for idx := range indexes {
+				number := new(big.Int).SetUint64(start + idx)
+				block, err := fetch(ctxc, number)
+				if err != nil {
+					errMu.Lock()
+					if firstErr == nil {
+						firstErr = err
+					}
+					errMu.Unlock()
+					cancel()
+					continue
+				}
+
+				// Each worker writes to a distinct index, so no
+				// synchronization is required.
+				blocks[idx] = block
+			}
+		}()
+	}
+
+	// Dispatch each index to the worker pool, stopping early if a worker
+	// has failed or the parent context is canceled.
+dispatch:
+	for idx := uint64(0); idx < numBlocks; idx++ {
+		select {
+		case indexes <- idx:
+		case <-ctxc.Done():
+			break dispatch
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
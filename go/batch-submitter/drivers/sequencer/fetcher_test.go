@@ -0,0 +1,92 @@
+package sequencer_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"math/rand"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFetchBlocksPreservesOrder asserts that FetchBlocks returns blocks in
+// ascending order even when requests complete out of order, and that it never
+// exceeds the configured number of concurrent requests.
+func TestFetchBlocksPreservesOrder(t *testing.T) {
+	t.Parallel()
+
+	const numWorkers = 4
+
+	var inFlight, maxInFlight int32
+	fetch := func(
+		ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+		cur := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if cur <= max ||
+				atomic.CompareAndSwapInt32(&maxInFlight, max, cur) {
+				break
+			}
+		}
+
+		time.Sleep(time.Duration(rand.Intn(5)) * time.Millisecond)
+		return newTestBlock(number.Uint64(), 0), nil
+	}
+
+	blocks, err := sequencer.FetchBlocks(
+		context.Background(), 10, 30, numWorkers, fetch,
+	)
+	require.Nil(t, err)
+	require.Len(t, blocks, 20)
+	for i, block := range blocks {
+		require.Equal(t, uint64(10+i), block.NumberU64())
+	}
+	require.LessOrEqual(t, maxInFlight, int32(numWorkers))
+}
+
+// TestFetchBlocksError asserts that FetchBlocks returns the error of a failed
+// request.
+func TestFetchBlocksError(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("fetch failed")
+	fetch := func(
+		ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+		if number.Uint64() == 5 {
+			return nil, errFetch
+		}
+		return newTestBlock(number.Uint64(), 0), nil
+	}
+
+	blocks, err := sequencer.FetchBlocks(
+		context.Background(), 0, 10, 3, fetch,
+	)
+	require.Equal(t, errFetch, err)
+	require.Nil(t, blocks)
+}
+
+// TestFetchBlocksEmptyRange asserts that an empty range performs no requests.
+func TestFetchBlocksEmptyRange(t *testing.T) {
+	t.Parallel()
+
+	fetch := func(
+		ctx context.Context, number *big.Int) (*l2types.Block, error) {
+
+		t.Fatalf("unexpected fetch of block %v", number)
+		return nil, nil
+	}
+
+	blocks, err := sequencer.FetchBlocks(
+		context.Background(), 5, 5, 3, fetch,
+	)
+	require.Nil(t, err)
+	require.Empty(t, blocks)
+}
@@ -0,0 +1,50 @@
+package sequencer
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// ErrGasEstimateExceedsCap signals that the node's gas estimate for a batch
+// tx is greater than the configured hard gas cap.
+var ErrGasEstimateExceedsCap = errors.New("gas estimate exceeds max gas limit")
+
+// ApplyGasLimitMargin pads a raw gas estimate by first scaling it by
+// multiplier and then adding buffer. If maxGasLimit is non-zero, the result is
+// clamped to maxGasLimit, and an error is returned if the raw estimate alone
+// already exceeds it. A multiplier less than 1 is treated as 1, such that the
+// returned gas limit is never below the node's estimate.
+func ApplyGasLimitMargin(
+	estimate uint64,
+	multiplier float64,
+	buffer uint64,
+	maxGasLimit uint64,
+) (uint64, error) {
+
+	if maxGasLimit != 0 && estimate > maxGasLimit {
+		return 0, fmt.Errorf("%w: estimate=%d max=%d",
+			ErrGasEstimateExceedsCap, estimate, maxGasLimit)
+	}
+
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	scaled := math.Ceil(float64(estimate) * multiplier)
+	gasLimit := uint64(math.MaxUint64)
+	if scaled < float64(math.MaxUint64) {
+		gasLimit = uint64(scaled)
+	}
+	if gasLimit+buffer >= gasLimit {
+		gasLimit += buffer
+	} else {
+		gasLimit = math.MaxUint64
+	}
+
+	if maxGasLimit != 0 && gasLimit > maxGasLimit {
+		gasLimit = maxGasLimit
+	}
+
+	return gasLimit, nil
+}
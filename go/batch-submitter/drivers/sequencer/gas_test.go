@@ -0,0 +1,74 @@
+package sequencer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyGasLimitMargin asserts that gas estimates are padded by the
+// configured multiplier and buffer, and clamped to the max gas limit.
+func TestApplyGasLimitMargin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		estimate    uint64
+		multiplier  float64
+		buffer      uint64
+		maxGasLimit uint64
+		expGasLimit uint64
+		expErr      error
+	}{
+		{
+			name:        "no margin",
+			estimate:    100000,
+			multiplier:  1,
+			expGasLimit: 100000,
+		},
+		{
+			name:        "multiplier below one",
+			estimate:    100000,
+			multiplier:  0.5,
+			expGasLimit: 100000,
+		},
+		{
+			name:        "multiplier and buffer",
+			estimate:    100000,
+			multiplier:  1.5,
+			buffer:      1000,
+			expGasLimit: 151000,
+		},
+		{
+			name:        "clamped to max",
+			estimate:    100000,
+			multiplier:  2,
+			maxGasLimit: 150000,
+			expGasLimit: 150000,
+		},
+		{
+			name:        "estimate exceeds max",
+			estimate:    200000,
+			multiplier:  1,
+			maxGasLimit: 150000,
+			expErr:      sequencer.ErrGasEstimateExceedsCap,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gasLimit, err := sequencer.ApplyGasLimitMargin(
+				test.estimate, test.multiplier, test.buffer,
+				test.maxGasLimit,
+			)
+			if test.expErr != nil {
+				require.True(t, errors.Is(err, test.expErr))
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, test.expGasLimit, gasLimit)
+		})
+	}
+}
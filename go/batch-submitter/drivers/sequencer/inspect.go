@@ -0,0 +1,117 @@
+package sequencer
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+)
+
+// ErrNotAppendSequencerBatch signals that calldata does not call the CTC's
+// appendSequencerBatch method.
+var ErrNotAppendSequencerBatch = errors.New("calldata does not call " +
+	appendSequencerBatchMethodName)
+
+// ContextInspection describes a single BatchContext of a batch, along with the
+// range of L2 blocks it covers.
+type ContextInspection struct {
+	BatchContext
+
+	// StartBlock and EndBlock bound the L2 blocks covered by the context,
+	// [StartBlock, EndBlock).
+	StartBlock uint64 `json:"start_block"`
+	EndBlock   uint64 `json:"end_block"`
+}
+
+// TxInspection describes a single sequencer tx of a batch.
+type TxInspection struct {
+	Hash l2common.Hash `json:"hash"`
+	Size int           `json:"size"`
+}
+
+// BatchInspection is a human-readable breakdown of the calldata of an
+// appendSequencerBatch call.
+type BatchInspection struct {
+	// Version is the encoding of the batch's contexts.
+	Version BatchVersion `json:"version"`
+
+	// Header describes the encoder of a BatchVersionV2 batch.
+	Header *BatchHeader `json:"header,omitempty"`
+
+	ShouldStartAtElement  uint64 `json:"should_start_at_element"`
+	TotalElementsToAppend uint64 `json:"total_elements_to_append"`
+
+	// StartBlock and EndBlock bound the L2 blocks covered by the batch,
+	// [StartBlock, EndBlock).
+	StartBlock uint64 `json:"start_block"`
+	EndBlock   uint64 `json:"end_block"`
+
+	NumContexts     int    `json:"num_contexts"`
+	NumSequencedTxs uint64 `json:"num_sequenced_txs"`
+	NumQueuedTxs    uint64 `json:"num_queued_txs"`
+
+	// CallDataSize is the size of the calldata in bytes, and TxsSize the
+	// size of the length-prefixed sequencer txs within it.
+	CallDataSize int `json:"calldata_size"`
+	TxsSize      int `json:"txs_size"`
+
+	Contexts []ContextInspection `json:"contexts"`
+	Txs      []TxInspection      `json:"txs"`
+}
+
+// InspectBatch decodes the calldata of an appendSequencerBatch call, including
+// the 4-byte method selector, into a BatchInspection. L2 block heights are
+// derived from the CTC's element indexes using blockOffset.
+func InspectBatch(callData []byte, blockOffset uint64) (*BatchInspection, error) {
+	ctcABI, err := ctc.CanonicalTransactionChainMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	methodID := ctcABI.Methods[appendSequencerBatchMethodName].ID
+	if len(callData) < 4 || !bytes.Equal(callData[:4], methodID) {
+		return nil, ErrNotAppendSequencerBatch
+	}
+
+	params, err := decodeBatchCallData(callData)
+	if err != nil {
+		return nil, err
+	}
+
+	start := params.ShouldStartAtElement + blockOffset
+	inspection := &BatchInspection{
+		Version:               params.Version,
+		Header:                params.Header,
+		ShouldStartAtElement:  params.ShouldStartAtElement,
+		TotalElementsToAppend: params.TotalElementsToAppend,
+		StartBlock:            start,
+		EndBlock:              start + params.TotalElementsToAppend,
+		NumContexts:           len(params.Contexts),
+		CallDataSize:          len(callData),
+		Contexts:              make([]ContextInspection, 0, len(params.Contexts)),
+		Txs:                   make([]TxInspection, 0, len(params.Txs)),
+	}
+
+	next := start
+	for _, context := range params.Contexts {
+		numTxs := context.NumSequencedTxs + context.NumSubsequentQueueTxs
+		inspection.Contexts = append(inspection.Contexts, ContextInspection{
+			BatchContext: context,
+			StartBlock:   next,
+			EndBlock:     next + numTxs,
+		})
+		inspection.NumSequencedTxs += context.NumSequencedTxs
+		inspection.NumQueuedTxs += context.NumSubsequentQueueTxs
+		next += numTxs
+	}
+
+	for _, tx := range params.Txs {
+		inspection.Txs = append(inspection.Txs, TxInspection{
+			Hash: tx.Tx().Hash(),
+			Size: tx.Size(),
+		})
+		inspection.TxsSize += TxLenSize + tx.Size()
+	}
+
+	return inspection, nil
+}
@@ -0,0 +1,63 @@
+package sequencer_test
+
+import (
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/stretchr/testify/require"
+)
+
+// TestInspectBatch asserts that the block ranges, tx counts and sizes of a
+// batch are recovered from its calldata, and that other calldata is rejected.
+func TestInspectBatch(t *testing.T) {
+	t.Parallel()
+
+	// Two sequencer txs followed by a queued tx, then a sequencer tx with
+	// a later timestamp.
+	elements := newVerifyTestElements(100, 0, 1)
+	elements = append(elements, sequencer.BatchElement{
+		Timestamp:   100,
+		BlockNumber: 1,
+	})
+	elements = append(elements, newVerifyTestElements(101, 2)...)
+
+	params, err := sequencer.GenSequencerBatchParams(
+		11, 1, elements, sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	args, err := params.Serialize()
+	require.Nil(t, err)
+
+	ctcABI, err := ctc.CanonicalTransactionChainMetaData.GetAbi()
+	require.Nil(t, err)
+	callData := append(ctcABI.Methods["appendSequencerBatch"].ID, args...)
+
+	inspection, err := sequencer.InspectBatch(callData, 1)
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), inspection.ShouldStartAtElement)
+	require.Equal(t, uint64(4), inspection.TotalElementsToAppend)
+	require.Equal(t, uint64(11), inspection.StartBlock)
+	require.Equal(t, uint64(15), inspection.EndBlock)
+	require.Equal(t, 2, inspection.NumContexts)
+	require.Equal(t, uint64(3), inspection.NumSequencedTxs)
+	require.Equal(t, uint64(1), inspection.NumQueuedTxs)
+	require.Equal(t, len(callData), inspection.CallDataSize)
+
+	require.Len(t, inspection.Contexts, 2)
+	require.Equal(t, uint64(11), inspection.Contexts[0].StartBlock)
+	require.Equal(t, uint64(14), inspection.Contexts[0].EndBlock)
+	require.Equal(t, uint64(14), inspection.Contexts[1].StartBlock)
+	require.Equal(t, uint64(15), inspection.Contexts[1].EndBlock)
+
+	require.Len(t, inspection.Txs, 3)
+	txsSize := 0
+	for i, tx := range params.Txs {
+		require.Equal(t, tx.Tx().Hash(), inspection.Txs[i].Hash)
+		txsSize += sequencer.TxLenSize + tx.Size()
+	}
+	require.Equal(t, txsSize, inspection.TxsSize)
+
+	_, err = sequencer.InspectBatch(args, 1)
+	require.Equal(t, sequencer.ErrNotAppendSequencerBatch, err)
+}
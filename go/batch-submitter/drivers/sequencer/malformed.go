@@ -0,0 +1,126 @@
+package sequencer
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+var (
+	// ErrUnknownMalformedBlockPolicy signals that malformed L2 blocks were
+	// configured to be handled by an unsupported policy.
+	ErrUnknownMalformedBlockPolicy = errors.New("malformed block policy " +
+		"must be one of error or quarantine")
+
+	// ErrMalformedBlockBudget signals that malformed L2 blocks were
+	// quarantined more times in a row than allowed by the
+	// MalformedBlockBudget, halting the building of batches.
+	ErrMalformedBlockBudget = errors.New("malformed block budget exhausted")
+)
+
+// MalformedBlockPolicy determines how L2 blocks from which no BatchElement can
+// be constructed are handled. Since the CTC must hold every L2 block, no policy
+// omits a malformed block from the chain of batches; at most, the blocks
+// preceding it are submitted while it awaits review.
+type MalformedBlockPolicy string
+
+const (
+	// MalformedBlockError refuses to build a batch holding a malformed
+	// block, failing every submission of its range.
+	MalformedBlockError MalformedBlockPolicy = "error"
+
+	// MalformedBlockQuarantine cuts a batch short before a malformed
+	// block, such that the blocks preceding it are submitted, and reports
+	// the block to the OnMalformedBlock hook for manual review. The block
+	// is refetched on each encounter, such that a block fixed by the L2
+	// node is batched once it is served intact.
+	MalformedBlockQuarantine MalformedBlockPolicy = "quarantine"
+)
+
+// ParseMalformedBlockPolicy parses a MalformedBlockPolicy from its name,
+// defaulting to MalformedBlockError if name is empty.
+func ParseMalformedBlockPolicy(name string) (MalformedBlockPolicy, error) {
+	switch policy := MalformedBlockPolicy(name); policy {
+	case "":
+		return MalformedBlockError, nil
+	case MalformedBlockError, MalformedBlockQuarantine:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownMalformedBlockPolicy,
+			name)
+	}
+}
+
+// MalformedBlockHook is invoked with each L2 block quarantined by the
+// MalformedBlockQuarantine policy, e.g. to persist it for manual review.
+type MalformedBlockHook func(block *queue.QuarantinedBlock) error
+
+// malformedBlockBudget counts the consecutive batches that encountered a
+// malformed block, against a budget beyond which batches are no longer built.
+type malformedBlockBudget struct {
+	mu         sync.Mutex
+	budget     uint64
+	encounters uint64
+}
+
+// observe records an encounter of a malformed block, returning false once the
+// budget is exhausted. A zero budget is never exhausted.
+func (b *malformedBlockBudget) observe() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.encounters++
+	return b.budget == 0 || b.encounters <= b.budget
+}
+
+// reset clears the encounters recorded so far, once a batch is built without
+// encountering a malformed block.
+func (b *malformedBlockBudget) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.encounters = 0
+}
+
+// quarantineBlock handles block, which failed to be parsed with parseErr,
+// according to the MalformedBlockPolicy. A nil error is returned if the batch
+// being built may be cut short before the block.
+func (d *Driver) quarantineBlock(block *l2types.Block, parseErr error) error {
+	name := d.cfg.Name
+	number := block.NumberU64()
+	err := fmt.Errorf("block %d: %w", number, parseErr)
+	if d.cfg.MalformedBlockPolicy != MalformedBlockQuarantine {
+		return err
+	}
+
+	log.Error(name+" quarantining malformed block", "block", number,
+		"hash", block.Hash(), "err", parseErr)
+	d.metrics.MalformedBlocks.Inc()
+
+	// The block is refetched on the next encounter, in case the L2 node
+	// serves it intact by then.
+	d.blockCache.Remove(number)
+
+	if d.cfg.OnMalformedBlock != nil {
+		hookErr := d.cfg.OnMalformedBlock(&queue.QuarantinedBlock{
+			Number: number,
+			Hash:   common.Hash(block.Hash()),
+			Reason: parseErr.Error(),
+		})
+		if hookErr != nil {
+			log.Error(name+" unable to record malformed block",
+				"block", number, "err", hookErr)
+		}
+	}
+
+	if !d.malformedBlocks.observe() {
+		return fmt.Errorf("%w: %v", ErrMalformedBlockBudget, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,206 @@
+package sequencer_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// malformedTx returns a sequencer tx at nonce without the L1 block number from
+// which a batch context is built.
+func malformedTx(nonce uint64) *l2types.Transaction {
+	return l2types.NewTransaction(
+		nonce, l2common.Address{}, new(big.Int), 21000, new(big.Int), nil,
+	)
+}
+
+// newMalformedTestDriver returns a Driver building batches of the blocks of an
+// L2 chain whose third block is malformed, followed by a well-formed block.
+func newMalformedTestDriver(
+	t *testing.T,
+	cfg sequencer.Config,
+) *sequencer.Driver {
+
+	return newTxsTestDriver(t, cfg,
+		testutil.SequencerTx(0, 1),
+		testutil.SequencerTx(1, 1),
+		malformedTx(2),
+		testutil.SequencerTx(3, 1),
+	)
+}
+
+// newTxsTestDriver returns a Driver building batches of the blocks of an L2
+// chain holding one of txs each, timestamped a second apart.
+func newTxsTestDriver(
+	t *testing.T,
+	cfg sequencer.Config,
+	txs ...*l2types.Transaction,
+) *sequencer.Driver {
+
+	l1, l2 := newTestChains(t)
+
+	for i, tx := range txs {
+		l2.AddBlock(100+uint64(i), tx)
+	}
+
+	cfg.Name = "malformed_" + t.Name()
+	return newTestDriver(t, l1, l2, cfg)
+}
+
+// TestParseMalformedBlockPolicy asserts that malformed block policies are
+// parsed from their names, defaulting to error.
+func TestParseMalformedBlockPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := sequencer.ParseMalformedBlockPolicy("")
+	require.Nil(t, err)
+	require.Equal(t, sequencer.MalformedBlockError, policy)
+
+	policy, err = sequencer.ParseMalformedBlockPolicy("quarantine")
+	require.Nil(t, err)
+	require.Equal(t, sequencer.MalformedBlockQuarantine, policy)
+
+	_, err = sequencer.ParseMalformedBlockPolicy("skip")
+	require.True(t, errors.Is(err, sequencer.ErrUnknownMalformedBlockPolicy))
+}
+
+// TestParseBatchElement asserts that blocks that do not hold exactly one tx
+// with an L1 block number are malformed.
+func TestParseBatchElement(t *testing.T) {
+	t.Parallel()
+
+	header := &l2types.Header{Number: big.NewInt(1), Time: 100}
+
+	_, err := sequencer.ParseBatchElement(
+		l2types.NewBlock(header, nil, nil, nil),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlock))
+
+	_, err = sequencer.ParseBatchElement(l2types.NewBlock(
+		header, []*l2types.Transaction{
+			testutil.SequencerTx(0, 1), testutil.SequencerTx(1, 1),
+		}, nil, nil,
+	))
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlock))
+
+	_, err = sequencer.ParseBatchElement(l2types.NewBlock(
+		header, []*l2types.Transaction{malformedTx(0)}, nil, nil,
+	))
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlock))
+
+	element, err := sequencer.ParseBatchElement(l2types.NewBlock(
+		header, []*l2types.Transaction{testutil.SequencerTx(0, 7)},
+		nil, nil,
+	))
+	require.Nil(t, err)
+	require.Equal(t, uint64(100), element.Timestamp)
+	require.Equal(t, uint64(7), element.BlockNumber)
+	require.True(t, element.IsSequencerTx())
+}
+
+// TestBuildBatchMalformedBlockError asserts that a malformed block fails the
+// batch holding it under the default policy.
+func TestBuildBatchMalformedBlockError(t *testing.T) {
+	t.Parallel()
+
+	driver := newMalformedTestDriver(t, sequencer.Config{})
+
+	_, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(5),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlock))
+
+	batch, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(3),
+	)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), batch.End)
+}
+
+// TestBuildBatchQuarantinesMalformedBlock asserts that a batch is cut short
+// before a quarantined block, which is reported on each encounter, and that
+// batches are no longer built once the budget is exhausted.
+func TestBuildBatchQuarantinesMalformedBlock(t *testing.T) {
+	t.Parallel()
+
+	var quarantined []*queue.QuarantinedBlock
+	driver := newMalformedTestDriver(t, sequencer.Config{
+		MalformedBlockPolicy: sequencer.MalformedBlockQuarantine,
+		MalformedBlockBudget: 2,
+		OnMalformedBlock: func(block *queue.QuarantinedBlock) error {
+			quarantined = append(quarantined, block)
+			return nil
+		},
+	})
+
+	// The blocks preceding the malformed block are batched.
+	batch, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(5),
+	)
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), batch.Start)
+	require.Equal(t, uint64(3), batch.End)
+	require.Len(t, quarantined, 1)
+	require.Equal(t, uint64(3), quarantined[0].Number)
+	require.NotEmpty(t, quarantined[0].Reason)
+
+	// Nothing can be batched once the malformed block heads the range.
+	_, err = driver.BuildBatch(
+		context.Background(), big.NewInt(3), big.NewInt(5),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlock))
+	require.False(t, errors.Is(err, sequencer.ErrMalformedBlockBudget))
+	require.Len(t, quarantined, 2)
+
+	// The third consecutive encounter exhausts the budget.
+	_, err = driver.BuildBatch(
+		context.Background(), big.NewInt(3), big.NewInt(5),
+	)
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlockBudget))
+	require.Len(t, quarantined, 3)
+}
+
+// TestBuildBatchMalformedBudgetReset asserts that the budget is restored once a
+// batch is built without encountering a malformed block.
+func TestBuildBatchMalformedBudgetReset(t *testing.T) {
+	t.Parallel()
+
+	driver := newMalformedTestDriver(t, sequencer.Config{
+		MalformedBlockPolicy: sequencer.MalformedBlockQuarantine,
+		MalformedBlockBudget: 1,
+	})
+
+	_, err := driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(5),
+	)
+	require.Nil(t, err)
+
+	_, err = driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(3),
+	)
+	require.Nil(t, err)
+
+	_, err = driver.BuildBatch(
+		context.Background(), big.NewInt(1), big.NewInt(5),
+	)
+	require.Nil(t, err)
+}
+
+// TestSelfTestReportsMalformedBlock asserts that the self-test reports a
+// malformed pending block as an error rather than panicking.
+func TestSelfTestReportsMalformedBlock(t *testing.T) {
+	t.Parallel()
+
+	driver := newTxsTestDriver(t, sequencer.Config{}, malformedTx(0))
+
+	err := driver.SelfTest(context.Background())
+	require.True(t, errors.Is(err, sequencer.ErrMalformedBlock))
+}
@@ -0,0 +1,36 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+// ErrPipelinedGasLimitNotSet signals that a batch building on batches that are
+// still in flight cannot be submitted, as its gas limit cannot be estimated
+// and no MaxGasLimit is configured.
+var ErrPipelinedGasLimitNotSet = errors.New("max gas limit must be set to " +
+	"submit batches building on unconfirmed batches")
+
+// buildsOnUnconfirmed returns true if batch begins after the CTC's latest
+// confirmed element, i.e. it can only be appended once the batches in flight
+// ahead of it have confirmed.
+func (d *Driver) buildsOnUnconfirmed(
+	ctx context.Context, batch *queue.Batch) (bool, error) {
+
+	totalElements, err := d.ctcContract.GetTotalElements(&bind.CallOpts{
+		Pending: false,
+		Context: ctx,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	start := new(big.Int).SetUint64(d.cfg.BlockOffset)
+	start.Add(start, totalElements)
+
+	return new(big.Int).SetUint64(batch.Start).Cmp(start) > 0, nil
+}
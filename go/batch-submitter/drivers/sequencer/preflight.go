@@ -0,0 +1,59 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// ErrPreflightFailed signals that simulating a batch tx against the CTC
+// reverted, and that the batch would revert if published.
+var ErrPreflightFailed = errors.New("batch tx preflight failed")
+
+// preflightBatch simulates the batch calldata against the CTC at the latest L1
+// block using eth_call. Any revert is logged with its decoded revert reason,
+// e.g. "Actual batch start does not match expected", and returned as an error
+// so that no gas is spent publishing the batch.
+func (d *Driver) preflightBatch(ctx context.Context, callData []byte) error {
+	_, err := d.cfg.L1Client.CallContract(ctx, ethereum.CallMsg{
+		From: d.wallets.Addr(),
+		To:   &d.cfg.CTCAddr,
+		Data: callData,
+	}, nil)
+	if err == nil {
+		return nil
+	}
+
+	reason := RevertReason(err)
+	d.metrics.PreflightFailures.Inc()
+	log.Error(d.cfg.Name+" batch tx preflight failed", "reason", reason,
+		"length", len(callData))
+
+	return fmt.Errorf("%w: %s", ErrPreflightFailed, reason)
+}
+
+// RevertReason extracts the human-readable revert reason from an error
+// returned by eth_call or eth_estimateGas. If the error does not carry ABI
+// encoded revert data, the error's message is returned.
+func RevertReason(err error) string {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if hexData, ok := dataErr.ErrorData().(string); ok {
+			data, decodeErr := hexutil.Decode(hexData)
+			if decodeErr == nil {
+				reason, unpackErr := abi.UnpackRevert(data)
+				if unpackErr == nil {
+					return reason
+				}
+			}
+		}
+	}
+
+	return err.Error()
+}
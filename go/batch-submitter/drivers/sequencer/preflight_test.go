@@ -0,0 +1,41 @@
+package sequencer_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/stretchr/testify/require"
+)
+
+// revertError mimics the JSON-RPC error returned by a node for a reverted
+// eth_call, carrying the ABI encoded revert data.
+type revertError struct {
+	data interface{}
+}
+
+func (e revertError) Error() string          { return "execution reverted" }
+func (e revertError) ErrorCode() int         { return 3 }
+func (e revertError) ErrorData() interface{} { return e.data }
+
+// TestRevertReason asserts that RevertReason decodes ABI encoded revert data,
+// and otherwise falls back to the error message.
+func TestRevertReason(t *testing.T) {
+	t.Parallel()
+
+	// Error("Actual batch start does not match expected")
+	revertData := "0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"000000000000000000000000000000000000000000000000000000000000002a" +
+		"41637475616c20626174636820737461727420646f6573206e6f74206d617463" +
+		"6820657870656374656400000000000000000000000000000000000000000000"
+
+	reason := sequencer.RevertReason(revertError{data: revertData})
+	require.Equal(t, "Actual batch start does not match expected", reason)
+
+	reason = sequencer.RevertReason(revertError{data: "0xdeadbeef"})
+	require.Equal(t, "execution reverted", reason)
+
+	reason = sequencer.RevertReason(errors.New("connection refused"))
+	require.Equal(t, "connection refused", reason)
+}
@@ -0,0 +1,87 @@
+package sequencer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// selfTestPayload is the dummy payload signed by the self-test.
+var selfTestPayload = crypto.Keccak256([]byte("batch-submitter self-test"))
+
+// SelfTest verifies the driver's dependencies before any batch is submitted.
+// A dummy payload is signed with the sequencer key, one L2 block is fetched,
+// and a batch containing only the next pending block, if any, is simulated
+// against the CTC. Nothing is published.
+func (d *Driver) SelfTest(ctx context.Context) error {
+	name := d.cfg.Name
+
+	privKey, walletAddr := d.wallets.Key(), d.wallets.Addr()
+	sig, err := crypto.Sign(selfTestPayload, privKey)
+	if err != nil {
+		return fmt.Errorf("unable to sign self-test payload: %w", err)
+	}
+	pubKey, err := crypto.SigToPub(selfTestPayload, sig)
+	if err != nil {
+		return fmt.Errorf("unable to recover self-test signer: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != walletAddr {
+		return fmt.Errorf("self-test signer %s does not match wallet %s",
+			signer, walletAddr)
+	}
+
+	start, err := d.ctcContract.GetTotalElements(&bind.CallOpts{
+		Context: ctx,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get total elements: %w", err)
+	}
+	start.Add(start, new(big.Int).SetUint64(d.cfg.BlockOffset))
+
+	latestHeader, err := d.cfg.L2Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("unable to get latest L2 header: %w", err)
+	}
+
+	// Without a pending block there is no batch the CTC would accept, so
+	// only the fetch is exercised.
+	number := start
+	pending := start.Cmp(latestHeader.Number) <= 0
+	if !pending {
+		number = latestHeader.Number
+	}
+	block, err := d.cfg.L2Client.BlockByNumber(ctx, number)
+	if err != nil {
+		return fmt.Errorf("unable to fetch L2 block %v: %w", number, err)
+	}
+	if !pending {
+		log.Info(name+" no pending L2 blocks, skipping batch simulation",
+			"start", start)
+		return nil
+	}
+
+	element, err := ParseBatchElement(block)
+	if err != nil {
+		return fmt.Errorf("unable to parse L2 block %v: %w", number, err)
+	}
+	batchParams, err := GenSequencerBatchParams(
+		start.Uint64(), d.cfg.BlockOffset, []BatchElement{element},
+		d.cfg.BatchLimits,
+	)
+	if err != nil {
+		return err
+	}
+	batchParams.Version = d.cfg.BatchVersion
+	batchArguments, err := batchParams.Serialize()
+	if err != nil {
+		return err
+	}
+	appendSequencerBatchID := d.ctcABI.Methods[appendSequencerBatchMethodName].ID
+	callData := append(appendSequencerBatchID, batchArguments...)
+
+	return d.preflightBatch(ctx, callData)
+}
@@ -0,0 +1,140 @@
+package sequencer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+)
+
+var (
+	// ErrUnknownSystemTxPolicy signals that system txs were configured to
+	// be handled by an unsupported policy.
+	ErrUnknownSystemTxPolicy = errors.New("system tx policy must be one " +
+		"of include, exclude or error")
+
+	// ErrInvalidSystemTxSender signals a system tx sender that is not a
+	// hex address.
+	ErrInvalidSystemTxSender = errors.New("system tx sender must be a " +
+		"hex address")
+
+	// ErrSystemTx signals a system tx found in a pending range while system
+	// txs are handled by SystemTxError.
+	ErrSystemTx = errors.New("system tx in pending range")
+)
+
+// SystemTxPolicy determines how sequencer txs identified as system txs, e.g.
+// fee oracle updates sent with a zero gas price, are handled. Since the CTC
+// must hold every L2 block, no policy omits a system tx from its batch.
+type SystemTxPolicy string
+
+const (
+	// SystemTxInclude batches system txs like any other sequencer tx.
+	SystemTxInclude SystemTxPolicy = "include"
+
+	// SystemTxExclude batches system txs, but excludes them from the L2 tx
+	// counts and sizes that determine whether a pending range is worth a
+	// batch tx, i.e. MinL2TxCount, MinBatchBytes and MaxFeePerL2Tx. A
+	// range holding only system txs is thus deferred until user txs
+	// arrive or MaxBatchSubmissionTime elapses.
+	SystemTxExclude SystemTxPolicy = "exclude"
+
+	// SystemTxError refuses to build a batch holding a system tx, leaving
+	// the range for an operator to resolve.
+	SystemTxError SystemTxPolicy = "error"
+)
+
+// ParseSystemTxPolicy parses a SystemTxPolicy from its name, defaulting to
+// SystemTxInclude if name is empty.
+func ParseSystemTxPolicy(name string) (SystemTxPolicy, error) {
+	switch policy := SystemTxPolicy(name); policy {
+	case "":
+		return SystemTxInclude, nil
+	case SystemTxInclude, SystemTxExclude, SystemTxError:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownSystemTxPolicy, name)
+	}
+}
+
+// ParseSystemTxSenders parses a comma-separated list of system tx senders.
+func ParseSystemTxSenders(senders string) ([]l2common.Address, error) {
+	var addrs []l2common.Address
+	for _, sender := range strings.Split(senders, ",") {
+		sender = strings.TrimSpace(sender)
+		if sender == "" {
+			continue
+		}
+		if !l2common.IsHexAddress(sender) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidSystemTxSender,
+				sender)
+		}
+		addrs = append(addrs, l2common.HexToAddress(sender))
+	}
+
+	return addrs, nil
+}
+
+// SystemTxFilter identifies the sequencer txs that are system txs.
+type SystemTxFilter struct {
+	// ZeroGasPrice, if true, identifies every tx with a zero gas price as
+	// a system tx.
+	ZeroGasPrice bool
+
+	// Senders are the accounts whose txs are system txs.
+	Senders []l2common.Address
+}
+
+// IsSystemTx returns true if the sequencer tx of el is a system tx. Queued txs
+// are never system txs, as they are not posted by the sequencer.
+func (f *SystemTxFilter) IsSystemTx(el BatchElement) bool {
+	if !el.IsSequencerTx() {
+		return false
+	}
+
+	tx := el.Tx.Tx()
+	if f.ZeroGasPrice && tx.GasPrice().Sign() == 0 {
+		return true
+	}
+	if len(f.Senders) == 0 {
+		return false
+	}
+
+	var signer l2types.Signer = l2types.HomesteadSigner{}
+	if tx.Protected() {
+		signer = l2types.NewEIP155Signer(tx.ChainId())
+	}
+	sender, err := l2types.Sender(signer, tx)
+	if err != nil {
+		return false
+	}
+	for _, systemSender := range f.Senders {
+		if sender == systemSender {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkSystemTx returns an error wrapping ErrSystemTx if el holds a system tx
+// while system txs are handled by SystemTxError.
+func (d *Driver) checkSystemTx(height uint64, el BatchElement) error {
+	if d.cfg.SystemTxPolicy != SystemTxError ||
+		!d.cfg.SystemTxs.IsSystemTx(el) {
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: block=%d tx_hash=%s", ErrSystemTx, height,
+		el.Tx.Tx().Hash().Hex())
+}
+
+// excludesSystemTx returns true if el holds a system tx that is excluded from
+// the L2 tx counts and sizes of its range.
+func (d *Driver) excludesSystemTx(el BatchElement) bool {
+	return d.cfg.SystemTxPolicy == SystemTxExclude &&
+		d.cfg.SystemTxs.IsSystemTx(el)
+}
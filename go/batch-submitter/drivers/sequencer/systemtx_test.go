@@ -0,0 +1,151 @@
+package sequencer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	l2crypto "github.com/ethereum-optimism/optimism/l2geth/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// signedElement returns a BatchElement holding a sequencer tx signed by key
+// with the given gas price.
+func signedElement(t *testing.T, gasPrice int64) (BatchElement, l2common.Address) {
+	key, err := l2crypto.GenerateKey()
+	require.Nil(t, err)
+
+	tx, err := l2types.SignTx(l2types.NewTransaction(
+		0, l2common.Address{}, new(big.Int), 21000, big.NewInt(gasPrice),
+		nil,
+	), l2types.NewEIP155Signer(big.NewInt(420)), key)
+	require.Nil(t, err)
+
+	return BatchElement{Tx: NewCachedTx(tx)},
+		l2crypto.PubkeyToAddress(key.PublicKey)
+}
+
+// TestParseSystemTxPolicy asserts that system tx policies are parsed from their
+// names, defaulting to include.
+func TestParseSystemTxPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParseSystemTxPolicy("")
+	require.Nil(t, err)
+	require.Equal(t, SystemTxInclude, policy)
+
+	policy, err = ParseSystemTxPolicy("exclude")
+	require.Nil(t, err)
+	require.Equal(t, SystemTxExclude, policy)
+
+	_, err = ParseSystemTxPolicy("skip")
+	require.True(t, errors.Is(err, ErrUnknownSystemTxPolicy))
+}
+
+// TestSystemTxFilter asserts that system txs are identified by their gas price
+// or sender, and that queued txs are never system txs.
+func TestSystemTxFilter(t *testing.T) {
+	t.Parallel()
+
+	free, _ := signedElement(t, 0)
+	paid, sender := signedElement(t, 1)
+
+	filter := SystemTxFilter{ZeroGasPrice: true}
+	require.True(t, filter.IsSystemTx(free))
+	require.False(t, filter.IsSystemTx(paid))
+	require.False(t, filter.IsSystemTx(BatchElement{}))
+
+	senders, err := ParseSystemTxSenders(" " + sender.Hex() + ",")
+	require.Nil(t, err)
+	filter = SystemTxFilter{Senders: senders}
+	require.False(t, filter.IsSystemTx(free))
+	require.True(t, filter.IsSystemTx(paid))
+
+	_, err = ParseSystemTxSenders("oracle")
+	require.True(t, errors.Is(err, ErrInvalidSystemTxSender))
+}
+
+// TestCheckSystemTx asserts that system txs are only refused under the error
+// policy.
+func TestCheckSystemTx(t *testing.T) {
+	t.Parallel()
+
+	free, _ := signedElement(t, 0)
+	paid, _ := signedElement(t, 1)
+
+	d := &Driver{cfg: Config{
+		SystemTxPolicy: SystemTxError,
+		SystemTxs:      SystemTxFilter{ZeroGasPrice: true},
+	}}
+	require.True(t, errors.Is(d.checkSystemTx(7, free), ErrSystemTx))
+	require.Nil(t, d.checkSystemTx(7, paid))
+
+	d.cfg.SystemTxPolicy = SystemTxInclude
+	require.Nil(t, d.checkSystemTx(7, free))
+}
+
+// TestShouldSubmitRangeExcludesSystemTxs asserts that excluded system txs do
+// not count towards the minimum batch size.
+func TestShouldSubmitRangeExcludesSystemTxs(t *testing.T) {
+	t.Parallel()
+
+	// Every tx of the test driver has a zero gas price.
+	d := newThresholdsTestDriver(Config{
+		Name:           "thresholds_system_txs",
+		MinL2TxCount:   2,
+		MinBatchBytes:  1,
+		SystemTxs:      SystemTxFilter{ZeroGasPrice: true},
+		SystemTxPolicy: SystemTxInclude,
+	}, 0, 4, 10, time.Now())
+
+	start, end := big.NewInt(0), big.NewInt(4)
+	submit, err := d.shouldSubmitRange(context.Background(), start, end)
+	require.Nil(t, err)
+	require.True(t, submit)
+
+	d.cfg.SystemTxPolicy = SystemTxExclude
+	submit, err = d.shouldSubmitRange(context.Background(), start, end)
+	require.Nil(t, err)
+	require.False(t, submit)
+}
+
+// TestCheckFeeCeilingExcludesSystemTxs asserts that excluded system txs carry
+// no economic value.
+func TestCheckFeeCeilingExcludesSystemTxs(t *testing.T) {
+	t.Parallel()
+
+	free, _ := signedElement(t, 0)
+	paid, _ := signedElement(t, 1)
+	params, err := GenSequencerBatchParams(
+		10, 0, []BatchElement{free, paid}, BatchLimits{},
+	)
+	require.Nil(t, err)
+	args, err := params.Serialize()
+	require.Nil(t, err)
+
+	d := &Driver{
+		cfg: Config{
+			MaxFeePerL2Tx:  big.NewInt(1000),
+			SystemTxPolicy: SystemTxExclude,
+			SystemTxs:      SystemTxFilter{ZeroGasPrice: true},
+		},
+		metrics: metrics.NewMetrics("fee_ceiling_system_txs"),
+	}
+	batch := &queue.Batch{
+		Start:    10,
+		End:      12,
+		CallData: append(make([]byte, 4), args...),
+	}
+
+	// Only the paid tx is worth 1000 wei.
+	require.Nil(t, d.checkFeeCeiling(batch, big.NewInt(10), 100))
+	err = d.checkFeeCeiling(batch, big.NewInt(11), 100)
+	require.True(t, errors.Is(err, txmgr.ErrFeeCeilingReached))
+}
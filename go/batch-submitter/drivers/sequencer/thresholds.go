@@ -0,0 +1,144 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// DefaultMaxBatchSubmissionTime is the age of the oldest pending block after
+// which an under-sized range is submitted when no MaxBatchSubmissionTime is
+// configured, such that a quiet chain is never deferred indefinitely.
+const DefaultMaxBatchSubmissionTime = 10 * time.Minute
+
+// shouldSubmitRange returns true if the pending range [start, end) is worth a
+// batch tx. A range is deferred while it holds fewer than MinL2TxCount L2 txs
+// and fewer than MinBatchBytes bytes of sequencer txs, unless its oldest block
+// is older than MaxBatchSubmissionTime, or DefaultMaxBatchSubmissionTime if
+// unset. If neither minimum is configured,
+// every non-empty range is submitted. Excluded system txs count towards neither
+// minimum.
+func (d *Driver) shouldSubmitRange(
+	ctx context.Context, start, end *big.Int) (bool, error) {
+
+	name := d.cfg.Name
+
+	if d.cfg.MinL2TxCount == 0 && d.cfg.MinBatchBytes == 0 {
+		return true, nil
+	}
+	if start.Cmp(end) >= 0 {
+		return true, nil
+	}
+
+	// Each L2 block contains exactly one tx, though the excluded system
+	// txs are only known once the range is fetched.
+	numTxs := new(big.Int).Sub(end, start).Uint64()
+	if d.cfg.MinL2TxCount > 0 && d.cfg.SystemTxPolicy == SystemTxExclude {
+		var err error
+		numTxs, err = d.pendingL2TxCount(ctx, start, end)
+		if err != nil {
+			return false, err
+		}
+	}
+	if d.cfg.MinL2TxCount > 0 && numTxs >= d.cfg.MinL2TxCount {
+		return true, nil
+	}
+
+	var batchBytes uint64
+	if d.cfg.MinBatchBytes > 0 {
+		var err error
+		batchBytes, err = d.pendingBatchBytes(ctx, start, end)
+		if err != nil {
+			return false, err
+		}
+		if batchBytes >= d.cfg.MinBatchBytes {
+			return true, nil
+		}
+	}
+
+	// Force a submission once the oldest pending block is stale, such that
+	// a quiet chain is still batched within the deadline.
+	maxSubmissionTime := d.cfg.MaxBatchSubmissionTime
+	if maxSubmissionTime <= 0 {
+		maxSubmissionTime = DefaultMaxBatchSubmissionTime
+	}
+	oldest, err := d.fetchBlock(ctx, start)
+	if err != nil {
+		return false, err
+	}
+	age := time.Since(time.Unix(int64(oldest.Time()), 0))
+	if age >= maxSubmissionTime {
+		log.Info(name+" forcing submission of stale range",
+			"start", start, "end", end, "age", age)
+		return true, nil
+	}
+
+	log.Info(name+" pending range below minimum batch size, deferring",
+		"start", start, "end", end, "num_txs", numTxs,
+		"batch_bytes", batchBytes)
+
+	return false, nil
+}
+
+// pendingL2TxCount returns the number of L2 txs in the range [start, end) that
+// are not excluded system txs, stopping once MinL2TxCount is reached. Fetched
+// blocks are cached, such that building the batch does not refetch them.
+func (d *Driver) pendingL2TxCount(
+	ctx context.Context, start, end *big.Int) (uint64, error) {
+
+	var numTxs uint64
+	for i := start.Uint64(); i < end.Uint64(); i++ {
+		block, err := d.fetchBlock(ctx, new(big.Int).SetUint64(i))
+		if err != nil {
+			return 0, err
+		}
+
+		// A batch is cut short before a malformed block, so it
+		// bounds the txs counted.
+		batchElement, err := ParseBatchElement(block)
+		if err != nil {
+			break
+		}
+		if !d.excludesSystemTx(batchElement) {
+			numTxs++
+		}
+		if numTxs >= d.cfg.MinL2TxCount {
+			break
+		}
+	}
+
+	return numTxs, nil
+}
+
+// pendingBatchBytes returns the serialized size of the sequencer txs in the
+// range [start, end), excluding system txs if configured to do so, stopping
+// once MinBatchBytes is reached. Fetched blocks
+// are cached, such that building the batch does not refetch them.
+func (d *Driver) pendingBatchBytes(
+	ctx context.Context, start, end *big.Int) (uint64, error) {
+
+	var batchBytes uint64
+	for i := start.Uint64(); i < end.Uint64(); i++ {
+		block, err := d.fetchBlock(ctx, new(big.Int).SetUint64(i))
+		if err != nil {
+			return 0, err
+		}
+
+		batchElement, err := ParseBatchElement(block)
+		if err != nil {
+			break
+		}
+		if batchElement.IsSequencerTx() &&
+			!d.excludesSystemTx(batchElement) {
+
+			batchBytes += uint64(TxLenSize + batchElement.Tx.Size())
+		}
+		if batchBytes >= d.cfg.MinBatchBytes {
+			break
+		}
+	}
+
+	return batchBytes, nil
+}
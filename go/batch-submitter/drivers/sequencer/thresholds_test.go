@@ -0,0 +1,155 @@
+package sequencer
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newThresholdsTestDriver initializes a Driver whose block cache holds a
+// sequencer tx with dataSize bytes of calldata at each height in [start, end),
+// the first of which was produced at oldest.
+func newThresholdsTestDriver(
+	cfg Config,
+	start, end uint64,
+	dataSize int,
+	oldest time.Time,
+) *Driver {
+
+	l2Client := make(blockClient)
+	cfg.L2Client = l2Client
+	d := &Driver{
+		cfg:        cfg,
+		blockCache: NewBlockCache(0),
+		metrics:    metrics.NewMetrics(cfg.Name),
+	}
+	for i := start; i < end; i++ {
+		tx := l2types.NewTransaction(
+			i, l2common.Address{}, new(big.Int), 0, new(big.Int),
+			make([]byte, dataSize),
+		)
+		tx.SetL1BlockNumber(0)
+		block := l2types.NewBlock(&l2types.Header{
+			Number: new(big.Int).SetUint64(i),
+			Time:   uint64(oldest.Unix()) + (i - start),
+		}, []*l2types.Transaction{tx}, nil, nil)
+		l2Client[i] = block
+		d.blockCache.Add(block)
+	}
+
+	return d
+}
+
+// TestShouldSubmitRange asserts that under-sized ranges are deferred unless
+// their oldest block is stale, and that either minimum suffices to submit.
+func TestShouldSubmitRange(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cfg       Config
+		numBlocks uint64
+		age       time.Duration
+		expSubmit bool
+	}{
+		{
+			name:      "no minimums",
+			cfg:       Config{Name: "thresholds_none"},
+			numBlocks: 1,
+			expSubmit: true,
+		},
+		{
+			name: "tx count reached",
+			cfg: Config{
+				Name:         "thresholds_tx_count",
+				MinL2TxCount: 5,
+			},
+			numBlocks: 5,
+			expSubmit: true,
+		},
+		{
+			name: "tx count not reached",
+			cfg: Config{
+				Name:         "thresholds_tx_count_deferred",
+				MinL2TxCount: 5,
+			},
+			numBlocks: 4,
+			expSubmit: false,
+		},
+		{
+			name: "batch bytes reached",
+			cfg: Config{
+				Name:          "thresholds_bytes",
+				MinL2TxCount:  100,
+				MinBatchBytes: 500,
+			},
+			numBlocks: 4,
+			expSubmit: true,
+		},
+		{
+			name: "batch bytes not reached",
+			cfg: Config{
+				Name:          "thresholds_bytes_deferred",
+				MinBatchBytes: 5000,
+			},
+			numBlocks: 4,
+			expSubmit: false,
+		},
+		{
+			name: "stale range",
+			cfg: Config{
+				Name:                   "thresholds_stale",
+				MinL2TxCount:           100,
+				MaxBatchSubmissionTime: time.Minute,
+			},
+			numBlocks: 1,
+			age:       2 * time.Minute,
+			expSubmit: true,
+		},
+		{
+			name: "fresh range",
+			cfg: Config{
+				Name:                   "thresholds_fresh",
+				MinL2TxCount:           100,
+				MaxBatchSubmissionTime: time.Minute,
+			},
+			numBlocks: 1,
+			expSubmit: false,
+		},
+		{
+			name: "stale range without deadline",
+			cfg: Config{
+				Name:         "thresholds_stale_default",
+				MinL2TxCount: 100,
+			},
+			numBlocks: 1,
+			age:       DefaultMaxBatchSubmissionTime + time.Minute,
+			expSubmit: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const start = 10
+			end := start + test.numBlocks
+
+			d := newThresholdsTestDriver(
+				test.cfg, start, end, 200,
+				time.Now().Add(-test.age),
+			)
+
+			shouldSubmit, err := d.shouldSubmitRange(
+				context.Background(), big.NewInt(start),
+				new(big.Int).SetUint64(end),
+			)
+			require.Nil(t, err)
+			require.Equal(t, test.expSubmit, shouldSubmit)
+		})
+	}
+}
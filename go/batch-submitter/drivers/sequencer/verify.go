@@ -0,0 +1,108 @@
+package sequencer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// VerifyBatch re-derives the batch confirmed by receipt from L1, decoding the
+// appendSequencerBatch calldata of the batch tx and comparing it against the
+// batch generated afresh from the L2 blocks it covers. The blocks are refetched
+// from the L2Client, bypassing the block cache, such that the comparison does
+// not reuse the blocks from which the batch was built. A description of each
+// mismatch is returned, and the error is non-nil if the batch could not be
+// verified.
+func (d *Driver) VerifyBatch(
+	ctx context.Context,
+	receipt *types.Receipt,
+) ([]string, error) {
+
+	tx, _, err := d.cfg.L1Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	decoded, err := decodeBatchCallData(tx.Data())
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode batch calldata: %w", err)
+	}
+
+	start := decoded.ShouldStartAtElement + d.cfg.BlockOffset
+	end := start + decoded.TotalElementsToAppend
+	blocks, err := FetchBlocks(
+		ctx, start, end, d.cfg.NumFetchWorkers, d.fetchUncachedBlock,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]BatchElement, 0, len(blocks))
+	for _, block := range blocks {
+		element, err := ParseBatchElement(block)
+		if err != nil {
+			return nil, fmt.Errorf("unable to derive batch from L2 "+
+				"block %d: %w", block.NumberU64(), err)
+		}
+		elements = append(elements, element)
+	}
+	derived, err := GenSequencerBatchParams(
+		start, d.cfg.BlockOffset, elements, d.cfg.BatchLimits,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to derive batch from L2: %w", err)
+	}
+
+	mismatches := CompareBatchParams(derived, decoded)
+	log.Info(d.cfg.Name+" verified batch", "tx_hash", receipt.TxHash,
+		"start", start, "end", end, "num_mismatches", len(mismatches))
+
+	return mismatches, nil
+}
+
+// CompareBatchParams compares the batch decoded from the calldata of a batch tx
+// against the batch derived from the L2 blocks it covers, returning a
+// description of each field that differs. Txs are compared by their encoding,
+// as that is what the CTC commits to.
+func CompareBatchParams(derived, decoded *AppendSequencerBatchParams) []string {
+	var mismatches []string
+	mismatch := func(format string, args ...interface{}) {
+		mismatches = append(mismatches, fmt.Sprintf(format, args...))
+	}
+
+	if derived.ShouldStartAtElement != decoded.ShouldStartAtElement {
+		mismatch("should_start_at_element: derived %d, decoded %d",
+			derived.ShouldStartAtElement, decoded.ShouldStartAtElement)
+	}
+	if derived.TotalElementsToAppend != decoded.TotalElementsToAppend {
+		mismatch("total_elements_to_append: derived %d, decoded %d",
+			derived.TotalElementsToAppend, decoded.TotalElementsToAppend)
+	}
+
+	if len(derived.Contexts) != len(decoded.Contexts) {
+		mismatch("num_contexts: derived %d, decoded %d",
+			len(derived.Contexts), len(decoded.Contexts))
+	}
+	for i := 0; i < len(derived.Contexts) && i < len(decoded.Contexts); i++ {
+		if derived.Contexts[i] != decoded.Contexts[i] {
+			mismatch("context %d: derived %+v, decoded %+v", i,
+				derived.Contexts[i], decoded.Contexts[i])
+		}
+	}
+
+	if len(derived.Txs) != len(decoded.Txs) {
+		mismatch("num_txs: derived %d, decoded %d",
+			len(derived.Txs), len(decoded.Txs))
+	}
+	for i := 0; i < len(derived.Txs) && i < len(decoded.Txs); i++ {
+		if !bytes.Equal(derived.Txs[i].RawTx(), decoded.Txs[i].RawTx()) {
+			mismatch("tx %d: derived %s, decoded %s", i,
+				derived.Txs[i].Tx().Hash().Hex(),
+				decoded.Txs[i].Tx().Hash().Hex())
+		}
+	}
+
+	return mismatches
+}
@@ -0,0 +1,86 @@
+package sequencer_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newVerifyTestElements creates a batch element for each of the given nonces,
+// each holding a sequencer tx included at the given timestamp.
+func newVerifyTestElements(
+	timestamp uint64,
+	nonces ...uint64,
+) []sequencer.BatchElement {
+
+	var elements []sequencer.BatchElement
+	for _, nonce := range nonces {
+		tx := l2types.NewTransaction(
+			nonce, l2common.Address{}, new(big.Int), 0, new(big.Int),
+			[]byte{0x01},
+		)
+		tx.SetL1BlockNumber(1)
+		block := l2types.NewBlock(&l2types.Header{
+			Time: timestamp,
+		}, []*l2types.Transaction{tx}, nil, nil)
+		elements = append(elements, sequencer.BatchElementFromBlock(block))
+	}
+
+	return elements
+}
+
+// roundTripBatchParams encodes params as batch calldata and decodes the
+// result, as done when re-deriving a batch from L1.
+func roundTripBatchParams(
+	t *testing.T,
+	params *sequencer.AppendSequencerBatchParams,
+) *sequencer.AppendSequencerBatchParams {
+
+	data, err := params.Serialize()
+	require.Nil(t, err)
+
+	var decoded sequencer.AppendSequencerBatchParams
+	require.Nil(t, decoded.Read(bytes.NewReader(data)))
+
+	return &decoded
+}
+
+// TestCompareBatchParams asserts that a faithfully encoded batch matches the
+// batch it was derived from, and that each differing field is reported.
+func TestCompareBatchParams(t *testing.T) {
+	t.Parallel()
+
+	derived, err := sequencer.GenSequencerBatchParams(
+		10, 1, newVerifyTestElements(100, 0, 1, 2),
+		sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	require.Empty(t, sequencer.CompareBatchParams(
+		derived, roundTripBatchParams(t, derived),
+	))
+
+	// A batch whose last tx and context timestamp differ.
+	other, err := sequencer.GenSequencerBatchParams(
+		10, 1, newVerifyTestElements(101, 0, 1, 3),
+		sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	require.Len(t, sequencer.CompareBatchParams(
+		derived, roundTripBatchParams(t, other),
+	), 2)
+
+	// A batch covering fewer elements from a different start.
+	other, err = sequencer.GenSequencerBatchParams(
+		11, 1, newVerifyTestElements(100, 0, 1),
+		sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	require.Len(t, sequencer.CompareBatchParams(
+		derived, roundTripBatchParams(t, other),
+	), 4)
+}
@@ -0,0 +1,116 @@
+package e2e
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// batchTxGasLimit is the gas limit of each batch tx.
+const batchTxGasLimit = 100_000
+
+// Driver is a batchsubmitter.Driver appending the blocks of a simulated L2
+// chain to the batch contract of a SimulatedL1, at most maxBatchSize blocks
+// per batch tx.
+type Driver struct {
+	name         string
+	l1           *SimulatedL1
+	privKey      *ecdsa.PrivateKey
+	walletAddr   common.Address
+	metrics      *metrics.Metrics
+	l2Height     uint64
+	maxBatchSize uint64
+}
+
+// NewDriver initializes a Driver signing batch txs with privKey, which submits
+// the L2 blocks below l2Height. The name must be unique within the process, as
+// it prefixes the driver's metrics.
+func NewDriver(
+	name string,
+	l1 *SimulatedL1,
+	privKey *ecdsa.PrivateKey,
+	l2Height, maxBatchSize uint64,
+) *Driver {
+
+	return &Driver{
+		name:         name,
+		l1:           l1,
+		privKey:      privKey,
+		walletAddr:   crypto.PubkeyToAddress(privKey.PublicKey),
+		metrics:      metrics.NewMetrics(name),
+		l2Height:     l2Height,
+		maxBatchSize: maxBatchSize,
+	}
+}
+
+// Name is an identifier used to prefix logs for a particular service.
+func (d *Driver) Name() string {
+	return d.name
+}
+
+// WalletAddr is the wallet address used to pay for batch transaction fees.
+func (d *Driver) WalletAddr() common.Address {
+	return d.walletAddr
+}
+
+// Metrics returns the subservice telemetry object.
+func (d *Driver) Metrics() *metrics.Metrics {
+	return d.metrics
+}
+
+// GetBatchBlockRange returns the range between the batch contract's height and
+// the L2 height.
+func (d *Driver) GetBatchBlockRange(
+	ctx context.Context) (*big.Int, *big.Int, error) {
+
+	start := new(big.Int).SetUint64(d.l1.Height())
+	end := new(big.Int).SetUint64(d.l2Height)
+	if start.Cmp(end) > 0 {
+		end.Set(start)
+	}
+
+	return start, end, nil
+}
+
+// SubmitBatchTx signs and publishes a batch tx appending the blocks in
+// [start, end), truncated to the max batch size.
+func (d *Driver) SubmitBatchTx(
+	ctx context.Context,
+	start, end, nonce, gasPrice *big.Int,
+) (*types.Transaction, error) {
+
+	r := BatchRange{
+		Start: start.Uint64(),
+		End:   end.Uint64(),
+	}
+	if r.End-r.Start > d.maxBatchSize {
+		r.End = r.Start + d.maxBatchSize
+	}
+
+	contract := d.l1.BatchContract()
+	tx, err := types.SignNewTx(
+		d.privKey, types.LatestSignerForChainID(d.l1.ChainID()),
+		&types.LegacyTx{
+			Nonce:    nonce.Uint64(),
+			GasPrice: gasPrice,
+			Gas:      batchTxGasLimit,
+			To:       &contract,
+			Value:    new(big.Int),
+			Data:     EncodeBatchCallData(r),
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.l1.Client().SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+
+	return tx, nil
+}
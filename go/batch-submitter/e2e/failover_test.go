@@ -0,0 +1,243 @@
+package e2e_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+	"time"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/e2e"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/leader"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// testL2Height is the number of L2 blocks to submit.
+	testL2Height = 16
+
+	// testMaxBatchSize is the max number of L2 blocks per batch tx.
+	testMaxBatchSize = 2
+
+	// testBlockTime is the block time of the simulated L1.
+	testBlockTime = 20 * time.Millisecond
+
+	// testLeaseDuration is the duration of each replica's leadership
+	// lease.
+	testLeaseDuration = 300 * time.Millisecond
+)
+
+// replica is a sequencer service run in high availability mode, sharing its
+// wallet with the other replicas.
+type replica struct {
+	elector *leader.Elector
+	service *batchsubmitter.Service
+	stopped bool
+}
+
+// leading returns whether the replica holds leadership.
+func (r *replica) leading() bool {
+	_, leading := r.elector.Leadership()
+	return leading
+}
+
+// crash stops the replica's service, as if its process were killed.
+func (r *replica) crash() {
+	if !r.stopped {
+		_ = r.service.Stop()
+		r.stopped = true
+	}
+}
+
+// startReplica starts a replica identified by id, contending for lock and
+// submitting from the wallet of privKey. The name prefixes the replica's
+// metrics, and must be unique within the process.
+func startReplica(
+	t *testing.T,
+	l1 *e2e.SimulatedL1,
+	lock leader.Lock,
+	privKey *ecdsa.PrivateKey,
+	name, id string,
+) *replica {
+
+	var service *batchsubmitter.Service
+	elector, err := leader.NewElector(leader.Config{
+		Lock:          lock,
+		ID:            id,
+		LeaseDuration: testLeaseDuration,
+		RenewInterval: 50 * time.Millisecond,
+		OnChange: func(bool) {
+			service.TriggerCycle()
+		},
+	})
+	require.Nil(t, err)
+
+	service = batchsubmitter.NewService(batchsubmitter.ServiceConfig{
+		Context: context.Background(),
+		Driver: e2e.NewDriver(
+			name, l1, privKey, testL2Height, testMaxBatchSize,
+		),
+		PollInterval: 50 * time.Millisecond,
+		L1Client:     l1.Client(),
+		TxManagerConfig: txmgr.Config{
+			Name:                 name,
+			MinGasPrice:          big.NewInt(10 * params.GWei),
+			MaxGasPrice:          big.NewInt(1000 * params.GWei),
+			GasRetryIncrement:    big.NewInt(5 * params.GWei),
+			ResubmissionTimeout:  100 * time.Millisecond,
+			ReceiptQueryInterval: 10 * time.Millisecond,
+			NumConfirmations:     1,
+		},
+		SelfTxSender: noncemgr.NewSelfTxSender(
+			l1.Client(), privKey, l1.ChainID(),
+		),
+		PendingTxStrategy: batchsubmitter.PendingTxStrategyCancel,
+		Elector:           elector,
+	})
+	require.Nil(t, service.Start())
+	elector.Start()
+
+	r := &replica{
+		elector: elector,
+		service: service,
+	}
+	t.Cleanup(func() {
+		r.crash()
+		r.elector.Stop()
+	})
+
+	return r
+}
+
+// pendingHashes returns the hashes of the txs from account pending on l1.
+func pendingHashes(
+	l1 *e2e.SimulatedL1, account common.Address) map[common.Hash]bool {
+
+	hashes := make(map[common.Hash]bool)
+	for _, tx := range l1.PendingTxs(account) {
+		hashes[tx.Hash()] = true
+	}
+
+	return hashes
+}
+
+// TestLeaderFailover kills or partitions the leader while its batch tx awaits
+// confirmation, and asserts that the standby takes over and completes
+// submission without any range being submitted twice or skipped.
+func TestLeaderFailover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+
+		// crash, if true, stops the leader's service in addition to
+		// isolating it from the lock.
+		crash bool
+
+		// mineBeforeTakeover, if true, mines the leader's batch tx
+		// before the standby takes over.
+		mineBeforeTakeover bool
+
+		// awaitReplacement, if true, holds blocks until the standby has
+		// replaced the batch tx left pending by the leader.
+		awaitReplacement bool
+	}{
+		{
+			name:             "crash_pending",
+			crash:            true,
+			awaitReplacement: true,
+		},
+		{
+			name:               "crash_mined",
+			crash:              true,
+			mineBeforeTakeover: true,
+		},
+		{
+			name: "partition",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			l1, err := e2e.NewSimulatedL1(big.NewInt(901), testBlockTime)
+			require.Nil(t, err)
+			t.Cleanup(l1.Close)
+
+			privKey, err := crypto.GenerateKey()
+			require.Nil(t, err)
+			wallet := crypto.PubkeyToAddress(privKey.PublicKey)
+
+			lock := e2e.NewLock()
+			prefix := "e2e_failover_" + test.name
+			first := startReplica(
+				t, l1, lock, privKey, prefix+"_first", "first",
+			)
+			require.Eventually(t, first.leading, time.Second,
+				time.Millisecond)
+			second := startReplica(
+				t, l1, lock, privKey, prefix+"_second", "second",
+			)
+
+			// Hold blocks once the leader has made progress, such
+			// that its next batch tx remains pending.
+			require.Eventually(t, func() bool {
+				return l1.Height() >= testL2Height/4
+			}, 5*time.Second, time.Millisecond)
+			l1.HoldBlocks(true)
+			require.Eventually(t, func() bool {
+				return len(l1.PendingTxs(wallet)) > 0
+			}, 5*time.Second, time.Millisecond)
+			require.Less(t, l1.Height(), uint64(testL2Height))
+
+			// Inject the fault mid-confirmation.
+			leaderTxs := pendingHashes(l1, wallet)
+			lock.Isolate("first")
+			if test.crash {
+				first.crash()
+			}
+			if test.mineBeforeTakeover {
+				l1.Mine()
+			}
+
+			require.Eventually(t, second.leading, 2*time.Second,
+				time.Millisecond)
+			require.False(t, first.leading())
+
+			if test.awaitReplacement {
+				require.Eventually(t, func() bool {
+					for hash := range pendingHashes(l1, wallet) {
+						if !leaderTxs[hash] {
+							return true
+						}
+					}
+					return false
+				}, 5*time.Second, time.Millisecond)
+			}
+			l1.HoldBlocks(false)
+
+			require.Eventually(t, func() bool {
+				return l1.Height() == testL2Height
+			}, 10*time.Second, time.Millisecond)
+
+			// A range submitted twice reverts, as the contract only
+			// accepts batches beginning at its height.
+			require.Empty(t, l1.Reverted())
+
+			var next uint64
+			for _, batch := range l1.Batches() {
+				require.Equal(t, next, batch.Start, "skipped range")
+				next = batch.End
+			}
+			require.Equal(t, uint64(testL2Height), next)
+		})
+	}
+}
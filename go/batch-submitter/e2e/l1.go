@@ -0,0 +1,479 @@
+// Package e2e provides a harness for running batch submitter services end to
+// end against a simulated L1 chain, along with fault injection hooks to test
+// how they recover from crashed or partitioned replicas.
+package e2e
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// blockGasLimit is the gas limit of each simulated block.
+	blockGasLimit = 30_000_000
+
+	// batchCallDataSize is the size of the calldata of a batch tx, holding
+	// the start and end of its range as big-endian uint64s.
+	batchCallDataSize = 16
+)
+
+var (
+	// ErrReplacementUnderpriced signals a tx replacing another pending tx
+	// at the same nonce without bumping its gas price by at least 10%,
+	// mirroring the rule enforced by geth's tx pool.
+	ErrReplacementUnderpriced = errors.New("replacement transaction " +
+		"underpriced")
+
+	// ErrNonceTooLow signals a tx whose nonce has already been mined.
+	ErrNonceTooLow = errors.New("nonce too low")
+
+	// ErrInvalidBatchCallData signals a tx to the batch contract whose
+	// calldata does not encode a range.
+	ErrInvalidBatchCallData = errors.New("invalid batch calldata")
+
+	// walletBalance is the balance reported for every account.
+	walletBalance = new(big.Int).Mul(
+		big.NewInt(1000), big.NewInt(params.Ether),
+	)
+
+	// suggestedGasPrice is the gas price suggested by the simulated L1.
+	suggestedGasPrice = big.NewInt(params.GWei)
+)
+
+// BatchRange is the range of L2 blocks [Start, End) appended by a batch tx.
+type BatchRange struct {
+	Start uint64
+	End   uint64
+}
+
+// EncodeBatchCallData encodes the calldata of a batch tx appending r to the
+// batch contract.
+func EncodeBatchCallData(r BatchRange) []byte {
+	data := make([]byte, batchCallDataSize)
+	binary.BigEndian.PutUint64(data[:8], r.Start)
+	binary.BigEndian.PutUint64(data[8:], r.End)
+
+	return data
+}
+
+// DecodeBatchCallData decodes the range appended by a batch tx.
+func DecodeBatchCallData(data []byte) (BatchRange, error) {
+	if len(data) != batchCallDataSize {
+		return BatchRange{}, ErrInvalidBatchCallData
+	}
+
+	r := BatchRange{
+		Start: binary.BigEndian.Uint64(data[:8]),
+		End:   binary.BigEndian.Uint64(data[8:]),
+	}
+	if r.End <= r.Start {
+		return BatchRange{}, ErrInvalidBatchCallData
+	}
+
+	return r, nil
+}
+
+// SimulatedL1 is an in-process L1 chain served over JSON-RPC, such that
+// services use the same ethclient.Client they would against a real node. It
+// holds a tx pool applying geth's replacement rule, and a batch contract which,
+// like the CanonicalTransactionChain, reverts any batch tx not beginning at its
+// current height. Blocks are mined on an interval unless held back, letting
+// tests decide whether a published tx is mined before or after a fault.
+//
+// NOTE: For simplicity, batch txs are never reorged, gas is never charged, and
+// every account holds the same balance.
+type SimulatedL1 struct {
+	chainID  *big.Int
+	signer   types.Signer
+	contract common.Address
+
+	server *rpc.Server
+	client *ethclient.Client
+
+	mu       sync.Mutex
+	headers  []*types.Header
+	nonces   map[common.Address]uint64
+	pool     map[common.Address]map[uint64]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	batches  []BatchRange
+	reverted []common.Hash
+	held     bool
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+// NewSimulatedL1 starts a simulated L1 chain with the given chain ID, mining a
+// block every blockTime.
+func NewSimulatedL1(chainID *big.Int, blockTime time.Duration) (
+	*SimulatedL1, error) {
+
+	l1 := &SimulatedL1{
+		chainID:  chainID,
+		signer:   types.LatestSignerForChainID(chainID),
+		contract: common.HexToAddress("0xc7c"),
+		server:   rpc.NewServer(),
+		headers: []*types.Header{{
+			Number:     new(big.Int),
+			Difficulty: new(big.Int),
+			GasLimit:   blockGasLimit,
+			Time:       uint64(time.Now().Unix()),
+		}},
+		nonces:   make(map[common.Address]uint64),
+		pool:     make(map[common.Address]map[uint64]*types.Transaction),
+		receipts: make(map[common.Hash]*types.Receipt),
+		stop:     make(chan struct{}),
+	}
+
+	if err := l1.server.RegisterName("eth", &l1API{l1: l1}); err != nil {
+		return nil, err
+	}
+	l1.client = ethclient.NewClient(rpc.DialInProc(l1.server))
+
+	l1.wg.Add(1)
+	go l1.mineLoop(blockTime)
+
+	return l1, nil
+}
+
+// Client returns a client of the chain's JSON-RPC API.
+func (l *SimulatedL1) Client() *ethclient.Client {
+	return l.client
+}
+
+// ChainID returns the chain ID of the chain.
+func (l *SimulatedL1) ChainID() *big.Int {
+	return new(big.Int).Set(l.chainID)
+}
+
+// BatchContract returns the address of the batch contract.
+func (l *SimulatedL1) BatchContract() common.Address {
+	return l.contract
+}
+
+// Close stops mining and closes the JSON-RPC server.
+func (l *SimulatedL1) Close() {
+	close(l.stop)
+	l.wg.Wait()
+
+	l.client.Close()
+	l.server.Stop()
+}
+
+// HoldBlocks stops mining blocks on the interval while held is true, such that
+// published txs remain pending until Mine is called or mining resumes.
+func (l *SimulatedL1) HoldBlocks(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held = held
+}
+
+// Mine mines a block including every executable pending tx, whether or not
+// blocks are held.
+func (l *SimulatedL1) Mine() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.mineLocked()
+}
+
+// Height returns the exclusive end of the last range appended to the batch
+// contract.
+func (l *SimulatedL1) Height() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.heightLocked()
+}
+
+// Batches returns the ranges appended to the batch contract, in order.
+func (l *SimulatedL1) Batches() []BatchRange {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]BatchRange(nil), l.batches...)
+}
+
+// Reverted returns the hashes of the mined batch txs reverted by the batch
+// contract, as they did not begin at its height.
+func (l *SimulatedL1) Reverted() []common.Hash {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]common.Hash(nil), l.reverted...)
+}
+
+// PendingTxs returns the txs from account pending in the tx pool, by
+// ascending nonce.
+func (l *SimulatedL1) PendingTxs(account common.Address) []*types.Transaction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var txs []*types.Transaction
+	for _, tx := range l.pool[account] {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].Nonce() < txs[j].Nonce()
+	})
+
+	return txs
+}
+
+// mineLoop mines a block every blockTime unless blocks are held, until the
+// chain is closed.
+func (l *SimulatedL1) mineLoop(blockTime time.Duration) {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(blockTime)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			l.mu.Lock()
+			if !l.held {
+				l.mineLocked()
+			}
+			l.mu.Unlock()
+
+		case <-l.stop:
+			return
+		}
+	}
+}
+
+// mineLocked mines a block including, for each account, the pending txs at
+// consecutive nonces beginning at its next nonce.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *SimulatedL1) mineLocked() {
+	parent := l.headers[len(l.headers)-1]
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: new(big.Int),
+		GasLimit:   blockGasLimit,
+		Time:       parent.Time + 1,
+	}
+
+	senders := make([]common.Address, 0, len(l.pool))
+	for sender := range l.pool {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return bytes.Compare(senders[i][:], senders[j][:]) < 0
+	})
+
+	var included []*types.Transaction
+	for _, sender := range senders {
+		for {
+			tx, ok := l.pool[sender][l.nonces[sender]]
+			if !ok {
+				break
+			}
+			delete(l.pool[sender], tx.Nonce())
+			l.nonces[sender]++
+			included = append(included, tx)
+		}
+		if len(l.pool[sender]) == 0 {
+			delete(l.pool, sender)
+		}
+	}
+
+	var receipts []*types.Receipt
+	for i, tx := range included {
+		status := types.ReceiptStatusSuccessful
+		if to := tx.To(); to != nil && *to == l.contract {
+			if !l.appendBatchLocked(tx.Data()) {
+				status = types.ReceiptStatusFailed
+				l.reverted = append(l.reverted, tx.Hash())
+			}
+		}
+
+		header.GasUsed += tx.Gas()
+		receipts = append(receipts, &types.Receipt{
+			Type:              tx.Type(),
+			Status:            status,
+			CumulativeGasUsed: header.GasUsed,
+			Logs:              []*types.Log{},
+			TxHash:            tx.Hash(),
+			GasUsed:           tx.Gas(),
+			BlockNumber:       header.Number,
+			TransactionIndex:  uint(i),
+		})
+	}
+
+	// The block hash covers every header field, so it is only known once
+	// the gas used by the included txs has been set.
+	blockHash := header.Hash()
+	for _, receipt := range receipts {
+		receipt.BlockHash = blockHash
+		l.receipts[receipt.TxHash] = receipt
+	}
+	l.headers = append(l.headers, header)
+}
+
+// appendBatchLocked appends the range encoded by calldata to the batch
+// contract, returning false if the batch tx reverts.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *SimulatedL1) appendBatchLocked(calldata []byte) bool {
+	r, err := DecodeBatchCallData(calldata)
+	if err != nil || r.Start != l.heightLocked() {
+		return false
+	}
+	l.batches = append(l.batches, r)
+
+	return true
+}
+
+// heightLocked returns the exclusive end of the last range appended to the
+// batch contract.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *SimulatedL1) heightLocked() uint64 {
+	if len(l.batches) == 0 {
+		return 0
+	}
+
+	return l.batches[len(l.batches)-1].End
+}
+
+// sendTransaction adds tx to the tx pool, replacing any pending tx at the
+// same nonce that it outbids by at least 10%.
+func (l *SimulatedL1) sendTransaction(tx *types.Transaction) error {
+	sender, err := types.Sender(l.signer, tx)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tx.Nonce() < l.nonces[sender] {
+		return ErrNonceTooLow
+	}
+
+	if l.pool[sender] == nil {
+		l.pool[sender] = make(map[uint64]*types.Transaction)
+	}
+	if old, ok := l.pool[sender][tx.Nonce()]; ok {
+		minGasPrice := new(big.Int).Mul(old.GasPrice(), big.NewInt(110))
+		minGasPrice.Div(minGasPrice, big.NewInt(100))
+		if tx.GasPrice().Cmp(minGasPrice) < 0 {
+			return ErrReplacementUnderpriced
+		}
+	}
+	l.pool[sender][tx.Nonce()] = tx
+
+	return nil
+}
+
+// l1API serves the subset of the eth namespace used by the batch submitter.
+type l1API struct {
+	l1 *SimulatedL1
+}
+
+// ChainId returns the chain ID.
+func (api *l1API) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.l1.chainID)
+}
+
+// BlockNumber returns the number of the latest block.
+func (api *l1API) BlockNumber() hexutil.Uint64 {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	return hexutil.Uint64(len(api.l1.headers) - 1)
+}
+
+// GetBlockByNumber returns the header of the block at number, or nil if it
+// has not been mined. Txs are never included in the response.
+func (api *l1API) GetBlockByNumber(
+	number rpc.BlockNumber, _ bool) *types.Header {
+
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	headers := api.l1.headers
+	if number < 0 {
+		return headers[len(headers)-1]
+	}
+	if int(number) >= len(headers) {
+		return nil
+	}
+
+	return headers[number]
+}
+
+// GetBalance returns the balance of account, which is the same for every
+// account at every block.
+func (api *l1API) GetBalance(
+	_ common.Address, _ rpc.BlockNumber) *hexutil.Big {
+
+	return (*hexutil.Big)(walletBalance)
+}
+
+// GetTransactionCount returns the next nonce of account, including the txs
+// executable from the tx pool for the pending block.
+func (api *l1API) GetTransactionCount(
+	account common.Address, number rpc.BlockNumber) hexutil.Uint64 {
+
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	nonce := api.l1.nonces[account]
+	if number == rpc.PendingBlockNumber {
+		for {
+			if _, ok := api.l1.pool[account][nonce]; !ok {
+				break
+			}
+			nonce++
+		}
+	}
+
+	return hexutil.Uint64(nonce)
+}
+
+// GasPrice returns the suggested gas price.
+func (api *l1API) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(suggestedGasPrice)
+}
+
+// SendRawTransaction adds the encoded tx to the tx pool.
+func (api *l1API) SendRawTransaction(
+	input hexutil.Bytes) (common.Hash, error) {
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.l1.sendTransaction(tx); err != nil {
+		return common.Hash{}, err
+	}
+
+	return tx.Hash(), nil
+}
+
+// GetTransactionReceipt returns the receipt of the tx, or nil if it has not
+// been mined.
+func (api *l1API) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	return api.l1.receipts[hash]
+}
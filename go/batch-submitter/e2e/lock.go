@@ -0,0 +1,78 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrHolderIsolated signals a request to the lock from a holder that has been
+// isolated from it.
+var ErrHolderIsolated = errors.New("holder is isolated from the lock")
+
+// Lock is an in-memory leader.Lock from which holders can be isolated,
+// simulating replicas that crash or are partitioned from the lock backend
+// without releasing their lease.
+type Lock struct {
+	mu       sync.Mutex
+	holder   string
+	expiry   time.Time
+	isolated map[string]struct{}
+}
+
+// NewLock initializes a Lock that is free.
+func NewLock() *Lock {
+	return &Lock{
+		isolated: make(map[string]struct{}),
+	}
+}
+
+// Acquire takes the lock on behalf of holder for ttl if it is free or its
+// lease has expired, or extends the lease if holder already holds it.
+func (l *Lock) Acquire(
+	ctx context.Context,
+	holder string,
+	ttl time.Duration,
+) (bool, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.isolated[holder]; ok {
+		return false, ErrHolderIsolated
+	}
+
+	now := time.Now()
+	if l.holder != "" && l.holder != holder && now.Before(l.expiry) {
+		return false, nil
+	}
+	l.holder = holder
+	l.expiry = now.Add(ttl)
+
+	return true, nil
+}
+
+// Release releases the lock if it is held by holder.
+func (l *Lock) Release(ctx context.Context, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if _, ok := l.isolated[holder]; ok {
+		return ErrHolderIsolated
+	}
+	if l.holder == holder {
+		l.holder = ""
+	}
+
+	return nil
+}
+
+// Isolate fails every subsequent request from holder, such that any lease it
+// holds is only given up once it expires.
+func (l *Lock) Isolate(holder string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.isolated[holder] = struct{}{}
+}
@@ -0,0 +1,37 @@
+package failover
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The metrics of every Transport are shared by the process, and labeled by
+// the transport's backend and the redacted endpoint.
+var (
+	// rpcRequests counts the requests attempted against each endpoint.
+	rpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "batch_submitter_rpc_requests",
+		Help: "Count of RPC requests attempted against each endpoint",
+	}, []string{"backend", "endpoint"})
+
+	// rpcErrors counts the requests to each endpoint that failed over,
+	// i.e. that failed in transport, timed out or were refused.
+	rpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "batch_submitter_rpc_errors",
+		Help: "Count of failed RPC requests to each endpoint",
+	}, []string{"backend", "endpoint"})
+
+	// rpcEndpointHealthy is one while an endpoint is considered healthy,
+	// and zero otherwise.
+	rpcEndpointHealthy = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "batch_submitter_rpc_endpoint_healthy",
+		Help: "Whether each RPC endpoint is considered healthy",
+	}, []string{"backend", "endpoint"})
+
+	// rpcActiveEndpoint is one for the endpoint currently serving writes
+	// of each backend, and zero for the others.
+	rpcActiveEndpoint = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "batch_submitter_rpc_active_endpoint",
+		Help: "Whether each RPC endpoint is the active endpoint",
+	}, []string{"backend", "endpoint"})
+)
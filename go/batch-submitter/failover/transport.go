@@ -0,0 +1,552 @@
+package failover
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultHealthCheckTimeout bounds each health check if no RequestTimeout is
+// configured.
+const defaultHealthCheckTimeout = 5 * time.Second
+
+var (
+	// ErrNoEndpoints signals that a Transport was configured without any
+	// endpoints.
+	ErrNoEndpoints = errors.New("at least one rpc endpoint is required")
+
+	// ErrUnsupportedScheme signals that an endpoint is not served over
+	// HTTP, and therefore cannot be failed over per request.
+	ErrUnsupportedScheme = errors.New("rpc failover requires http or " +
+		"https endpoints")
+)
+
+// writeMethods are the RPC methods whose requests are never load balanced,
+// such that txs are always published through the active endpoint.
+var writeMethods = map[string]struct{}{
+	"eth_sendRawTransaction": {},
+	"eth_sendTransaction":    {},
+}
+
+// stickyMethods are the RPC methods querying the txs published by a write,
+// whose requests are sent to the endpoint that took the last write, as other
+// endpoints may not have seen the txs yet.
+var stickyMethods = map[string]struct{}{
+	"eth_getTransactionReceipt": {},
+	"eth_getTransactionByHash":  {},
+}
+
+// pendingBlockTag is the block parameter selecting the pending state, which
+// includes the txs in an endpoint's own tx pool.
+const pendingBlockTag = `"pending"`
+
+// requestKind classifies a JSON-RPC request, or batch of requests, by the
+// endpoints it may be sent to.
+type requestKind int
+
+const (
+	// readRequest does not depend on the txs published by a write, and
+	// may be load balanced.
+	readRequest requestKind = iota
+
+	// stickyRequest queries the pending state or published txs, and is
+	// sent to the endpoint that took the last write while healthy.
+	stickyRequest
+
+	// writeRequest publishes a tx through the active endpoint.
+	writeRequest
+)
+
+// Config configures a Transport.
+type Config struct {
+	// Backend identifies the endpoints in logs and metrics, e.g. "l1".
+	Backend string
+
+	// Endpoints are the HTTP URLs of the RPC providers, in order of
+	// priority. The first healthy endpoint is the active endpoint.
+	Endpoints []string
+
+	// HealthCheckInterval is the interval at which every endpoint is
+	// checked, allowing failed endpoints to recover. If zero, endpoints
+	// are only marked healthy again by a successful request.
+	HealthCheckInterval time.Duration
+
+	// RequestTimeout, if non-zero, bounds each attempt of a request
+	// against a single endpoint, after which the request fails over.
+	RequestTimeout time.Duration
+
+	// LoadBalanceReads, if true, spreads requests that neither publish
+	// txs nor query the pending state or published txs across every
+	// healthy endpoint.
+	LoadBalanceReads bool
+
+	// Base performs the requests to each endpoint. If nil,
+	// http.DefaultTransport is used.
+	Base http.RoundTripper
+}
+
+// endpoint is a single RPC provider of a Transport.
+type endpoint struct {
+	url   *url.URL
+	label string
+
+	// healthy is one while the endpoint is considered healthy.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	healthy uint32
+}
+
+func (e *endpoint) isHealthy() bool {
+	return atomic.LoadUint32(&e.healthy) == 1
+}
+
+// Transport is an http.RoundTripper that sends each JSON-RPC request to one of
+// several equivalent providers, failing over to the next on transport errors,
+// timeouts and server errors. It is intended to back an rpc.Client dialed
+// using rpc.DialHTTPWithClient, such that every call made through the client
+// fails over transparently. JSON-RPC errors are returned as is, since every
+// provider would return the same.
+//
+// NOTE: Transport is safe for concurrent use.
+type Transport struct {
+	cfg       Config
+	base      http.RoundTripper
+	endpoints []*endpoint
+
+	// next is the index at which the next load balanced read starts.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	next uint64
+
+	activeMu sync.Mutex
+	active   *endpoint
+
+	// pinned is the endpoint that took the last write, to which sticky
+	// requests are sent while it is healthy.
+	pinned *endpoint
+
+	quit      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewTransport initializes a Transport over the configured endpoints, each of
+// which is initially considered healthy, and begins health checking them.
+func NewTransport(cfg Config) (*Transport, error) {
+	if len(cfg.Endpoints) == 0 {
+		return nil, ErrNoEndpoints
+	}
+
+	base := cfg.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t := &Transport{
+		cfg:  cfg,
+		base: base,
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	labels := make(map[string]int)
+	for i, rawURL := range cfg.Endpoints {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedScheme,
+				u.Scheme)
+		}
+
+		// Only the host is retained, as providers commonly embed
+		// credentials in their URL's path.
+		label := u.Scheme + "://" + u.Host
+		if n := labels[label]; n > 0 {
+			label = fmt.Sprintf("%s#%d", label, i)
+		}
+		labels[label]++
+
+		ep := &endpoint{
+			url:     u,
+			label:   label,
+			healthy: 1,
+		}
+		rpcEndpointHealthy.WithLabelValues(cfg.Backend, label).Set(1)
+		t.endpoints = append(t.endpoints, ep)
+	}
+	t.updateActive()
+
+	if cfg.HealthCheckInterval > 0 {
+		go t.healthLoop()
+	} else {
+		close(t.done)
+	}
+
+	return t, nil
+}
+
+// Close stops health checking the endpoints.
+func (t *Transport) Close() {
+	t.closeOnce.Do(func() {
+		close(t.quit)
+		<-t.done
+	})
+}
+
+// Active returns the redacted URL of the active endpoint.
+func (t *Transport) Active() string {
+	t.activeMu.Lock()
+	defer t.activeMu.Unlock()
+
+	return t.active.label
+}
+
+// RoundTrip sends req to each candidate endpoint in turn until one responds
+// without failing.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	kind := classifyRequest(body)
+
+	var lastErr error
+	for _, ep := range t.candidates(kind) {
+		rpcRequests.WithLabelValues(t.cfg.Backend, ep.label).Inc()
+
+		resp, err := t.attempt(req, ep, body)
+		if err == nil {
+			t.setHealthy(ep, true)
+			if kind == writeRequest {
+				t.pin(ep)
+			}
+			return resp, nil
+		}
+		lastErr = err
+
+		// A request abandoned by the caller says nothing about the
+		// health of the endpoint.
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		rpcErrors.WithLabelValues(t.cfg.Backend, ep.label).Inc()
+		log.Warn("RPC request failed, failing over", "backend",
+			t.cfg.Backend, "endpoint", ep.label, "err", err)
+		t.setHealthy(ep, false)
+	}
+
+	return nil, lastErr
+}
+
+// candidates returns the endpoints to attempt a request of the given kind
+// against, in order. Healthy endpoints are tried first, in order of priority
+// or, for load balanced reads, starting from the next endpoint in rotation.
+// Sticky requests are tried against the pinned endpoint first while it is
+// healthy. Unhealthy endpoints are only tried as a last resort.
+func (t *Transport) candidates(kind requestKind) []*endpoint {
+	var healthy, unhealthy []*endpoint
+	for _, ep := range t.endpoints {
+		if ep.isHealthy() {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+
+	if pinned := t.pinnedEndpoint(); kind == stickyRequest &&
+		pinned != nil && pinned.isHealthy() {
+
+		sticky := make([]*endpoint, 0, len(t.endpoints))
+		sticky = append(sticky, pinned)
+		for _, ep := range healthy {
+			if ep != pinned {
+				sticky = append(sticky, ep)
+			}
+		}
+		return append(sticky, unhealthy...)
+	}
+
+	loadBalance := t.cfg.LoadBalanceReads && kind == readRequest
+	if loadBalance && len(healthy) > 1 {
+		start := int(atomic.AddUint64(&t.next, 1) % uint64(len(healthy)))
+		rotated := make([]*endpoint, 0, len(t.endpoints))
+		rotated = append(rotated, healthy[start:]...)
+		healthy = append(rotated, healthy[:start]...)
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+// pin records ep as the endpoint that took the last write.
+func (t *Transport) pin(ep *endpoint) {
+	t.activeMu.Lock()
+	defer t.activeMu.Unlock()
+
+	t.pinned = ep
+}
+
+// pinnedEndpoint returns the endpoint that took the last write, if any.
+func (t *Transport) pinnedEndpoint() *endpoint {
+	t.activeMu.Lock()
+	defer t.activeMu.Unlock()
+
+	return t.pinned
+}
+
+// attempt sends req with the given body to ep, returning an error if the
+// request fails, times out or is refused by the endpoint.
+func (t *Transport) attempt(
+	req *http.Request,
+	ep *endpoint,
+	body []byte,
+) (*http.Response, error) {
+
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.cfg.RequestTimeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.cfg.RequestTimeout)
+	}
+
+	r := req.Clone(ctx)
+	r.URL = ep.url
+	r.Host = ep.url.Host
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	r.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	r.ContentLength = int64(len(body))
+	if ep.url.User != nil {
+		password, _ := ep.url.User.Password()
+		r.SetBasicAuth(ep.url.User.Username(), password)
+	}
+
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Rate limiting and server errors are specific to the endpoint, and
+	// are therefore failed over.
+	if resp.StatusCode >= http.StatusInternalServerError ||
+		resp.StatusCode == http.StatusTooManyRequests {
+
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("endpoint returned status %d",
+			resp.StatusCode)
+	}
+
+	// The attempt's context must outlive RoundTrip, until the response
+	// has been read.
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+
+	return resp, nil
+}
+
+// setHealthy records whether ep is healthy, updating the active endpoint if
+// its health changed.
+func (t *Transport) setHealthy(ep *endpoint, healthy bool) {
+	var value uint32
+	if healthy {
+		value = 1
+	}
+	if atomic.SwapUint32(&ep.healthy, value) == value {
+		return
+	}
+
+	rpcEndpointHealthy.WithLabelValues(t.cfg.Backend, ep.label).
+		Set(float64(value))
+	t.updateActive()
+}
+
+// updateActive makes the first healthy endpoint the active endpoint, or the
+// first endpoint if none is healthy.
+func (t *Transport) updateActive() {
+	active := t.endpoints[0]
+	for _, ep := range t.endpoints {
+		if ep.isHealthy() {
+			active = ep
+			break
+		}
+	}
+
+	t.activeMu.Lock()
+	defer t.activeMu.Unlock()
+
+	if t.active == active {
+		return
+	}
+	if t.active != nil {
+		log.Warn("Active RPC endpoint changed", "backend",
+			t.cfg.Backend, "from", t.active.label, "to", active.label)
+		rpcActiveEndpoint.WithLabelValues(t.cfg.Backend, t.active.label).
+			Set(0)
+	}
+	rpcActiveEndpoint.WithLabelValues(t.cfg.Backend, active.label).Set(1)
+	t.active = active
+}
+
+func (t *Transport) healthLoop() {
+	defer close(t.done)
+
+	ticker := time.NewTicker(t.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			t.checkHealth()
+
+		case <-t.quit:
+			return
+		}
+	}
+}
+
+// checkHealth checks every endpoint concurrently.
+func (t *Transport) checkHealth() {
+	var wg sync.WaitGroup
+	for _, ep := range t.endpoints {
+		ep := ep
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := t.ping(ep)
+			if err != nil && ep.isHealthy() {
+				log.Warn("RPC endpoint failed health check",
+					"backend", t.cfg.Backend, "endpoint",
+					ep.label, "err", err)
+			}
+			t.setHealthy(ep, err == nil)
+		}()
+	}
+	wg.Wait()
+}
+
+// healthCheckRequest is the JSON-RPC request used to check each endpoint.
+var healthCheckRequest = []byte(
+	`{"jsonrpc":"2.0","id":1,"method":"eth_blockNumber","params":[]}`,
+)
+
+// ping returns an error unless ep answers a health check request.
+func (t *Transport) ping(ep *endpoint) error {
+	timeout := t.cfg.RequestTimeout
+	if timeout == 0 {
+		timeout = defaultHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, ep.url.String(),
+		bytes.NewReader(healthCheckRequest),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.attempt(req, ep, healthCheckRequest)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var msg struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&msg); err != nil {
+		return err
+	}
+	if msg.Error != nil {
+		return errors.New(msg.Error.Message)
+	}
+	if len(msg.Result) == 0 {
+		return errors.New("empty health check result")
+	}
+
+	return nil
+}
+
+// cancelOnClose cancels the context of a response once its body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
+// readBody reads and closes the body of req, such that it can be replayed
+// against each endpoint.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	defer req.Body.Close()
+
+	return ioutil.ReadAll(req.Body)
+}
+
+// classifyRequest classifies body, a JSON-RPC request or batch of requests, by
+// the most restrictive kind of any of its requests. A body that cannot be
+// decoded is treated as sticky, such that it is never load balanced.
+func classifyRequest(body []byte) requestKind {
+	type request struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+
+	var requests []request
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &requests); err != nil {
+			return stickyRequest
+		}
+	} else {
+		var single request
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return stickyRequest
+		}
+		requests = append(requests, single)
+	}
+
+	kind := readRequest
+	for _, r := range requests {
+		if _, ok := writeMethods[r.Method]; ok {
+			return writeRequest
+		}
+		if _, ok := stickyMethods[r.Method]; ok {
+			kind = stickyRequest
+		}
+		for _, param := range r.Params {
+			if string(bytes.TrimSpace(param)) == pendingBlockTag {
+				kind = stickyRequest
+			}
+		}
+	}
+	return kind
+}
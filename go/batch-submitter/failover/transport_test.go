@@ -0,0 +1,276 @@
+package failover_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/failover"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// testEndpoint is a JSON-RPC server answering every request with its result,
+// or with status if non-zero.
+type testEndpoint struct {
+	*httptest.Server
+
+	result   string
+	status   int32
+	requests int32
+}
+
+func newTestEndpoint(t *testing.T, result string) *testEndpoint {
+	e := &testEndpoint{result: result}
+	e.Server = httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			atomic.AddInt32(&e.requests, 1)
+			if status := atomic.LoadInt32(&e.status); status != 0 {
+				w.WriteHeader(int(status))
+				return
+			}
+
+			var msg struct {
+				ID json.RawMessage `json:"id"`
+			}
+			require.Nil(t, json.NewDecoder(req.Body).Decode(&msg))
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      msg.ID,
+				"result":  e.result,
+			})
+		},
+	))
+	t.Cleanup(e.Close)
+
+	return e
+}
+
+func (e *testEndpoint) setStatus(status int) {
+	atomic.StoreInt32(&e.status, int32(status))
+}
+
+func (e *testEndpoint) numRequests() int {
+	return int(atomic.LoadInt32(&e.requests))
+}
+
+// dialTestClient returns an RPC client failing over between endpoints.
+func dialTestClient(
+	t *testing.T,
+	cfg failover.Config,
+	endpoints ...*testEndpoint,
+) (*rpc.Client, *failover.Transport) {
+
+	for _, e := range endpoints {
+		cfg.Endpoints = append(cfg.Endpoints, e.URL)
+	}
+	transport, err := failover.NewTransport(cfg)
+	require.Nil(t, err)
+	t.Cleanup(transport.Close)
+
+	client, err := rpc.DialHTTPWithClient(
+		cfg.Endpoints[0], &http.Client{Transport: transport},
+	)
+	require.Nil(t, err)
+	t.Cleanup(client.Close)
+
+	return client, transport
+}
+
+// call returns the result of calling method.
+func call(t *testing.T, client *rpc.Client, method string) string {
+	var result string
+	require.Nil(t, client.CallContext(context.Background(), &result, method))
+	return result
+}
+
+// TestTransportFailsOver asserts that requests fail over to the next endpoint
+// when the active endpoint fails, and that the active endpoint is restored
+// once it passes a health check.
+func TestTransportFailsOver(t *testing.T) {
+	t.Parallel()
+
+	primary := newTestEndpoint(t, "primary")
+	backup := newTestEndpoint(t, "backup")
+	client, transport := dialTestClient(t, failover.Config{
+		Backend:             "TestTransportFailsOver",
+		HealthCheckInterval: 10 * time.Millisecond,
+	}, primary, backup)
+
+	require.Equal(t, "primary", call(t, client, "eth_blockNumber"))
+	require.Equal(t, 0, backup.numRequests())
+
+	primary.setStatus(http.StatusBadGateway)
+	require.Equal(t, "backup", call(t, client, "eth_blockNumber"))
+	require.Equal(t, "backup", call(t, client, "eth_sendRawTransaction"))
+
+	primary.setStatus(0)
+	require.Eventually(t, func() bool {
+		return transport.Active() == primary.URL
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, "primary", call(t, client, "eth_blockNumber"))
+}
+
+// TestTransportUnreachableEndpoint asserts that requests fail over from an
+// endpoint that cannot be reached, and fail once every endpoint has failed.
+func TestTransportUnreachableEndpoint(t *testing.T) {
+	t.Parallel()
+
+	unreachable := newTestEndpoint(t, "unreachable")
+	unreachable.Close()
+	backup := newTestEndpoint(t, "backup")
+	client, _ := dialTestClient(t, failover.Config{
+		Backend: "TestTransportUnreachableEndpoint",
+	}, unreachable, backup)
+
+	require.Equal(t, "backup", call(t, client, "eth_blockNumber"))
+
+	backup.setStatus(http.StatusServiceUnavailable)
+	var result string
+	err := client.CallContext(context.Background(), &result,
+		"eth_blockNumber")
+	require.Error(t, err)
+}
+
+// TestTransportLoadBalancesReads asserts that reads are spread across every
+// healthy endpoint, while txs are only published through the active one.
+func TestTransportLoadBalancesReads(t *testing.T) {
+	t.Parallel()
+
+	primary := newTestEndpoint(t, "primary")
+	secondary := newTestEndpoint(t, "secondary")
+	client, _ := dialTestClient(t, failover.Config{
+		Backend:          "TestTransportLoadBalancesReads",
+		LoadBalanceReads: true,
+	}, primary, secondary)
+
+	for i := 0; i < 4; i++ {
+		call(t, client, "eth_blockNumber")
+	}
+	require.Equal(t, 2, primary.numRequests())
+	require.Equal(t, 2, secondary.numRequests())
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, "primary",
+			call(t, client, "eth_sendRawTransaction"))
+	}
+	require.Equal(t, 6, primary.numRequests())
+	require.Equal(t, 2, secondary.numRequests())
+}
+
+// TestTransportRequestTimeout asserts that a request to an endpoint that does
+// not respond in time fails over.
+func TestTransportRequestTimeout(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	hung := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			<-release
+		},
+	))
+	t.Cleanup(hung.Close)
+	t.Cleanup(func() { close(release) })
+	backup := newTestEndpoint(t, "backup")
+
+	transport, err := failover.NewTransport(failover.Config{
+		Backend:        "TestTransportRequestTimeout",
+		Endpoints:      []string{hung.URL, backup.URL},
+		RequestTimeout: 50 * time.Millisecond,
+	})
+	require.Nil(t, err)
+	t.Cleanup(transport.Close)
+
+	client, err := rpc.DialHTTPWithClient(
+		hung.URL, &http.Client{Transport: transport},
+	)
+	require.Nil(t, err)
+	t.Cleanup(client.Close)
+
+	require.Equal(t, "backup", call(t, client, "eth_blockNumber"))
+	require.Equal(t, backup.URL, transport.Active())
+}
+
+// TestNewTransportRejectsWebsockets asserts that only HTTP endpoints can be
+// failed over.
+func TestNewTransportRejectsWebsockets(t *testing.T) {
+	t.Parallel()
+
+	_, err := failover.NewTransport(failover.Config{
+		Backend:   "TestNewTransportRejectsWebsockets",
+		Endpoints: []string{"http://localhost:8545", "ws://localhost:8546"},
+	})
+	require.ErrorIs(t, err, failover.ErrUnsupportedScheme)
+
+	_, err = failover.NewTransport(failover.Config{})
+	require.Equal(t, failover.ErrNoEndpoints, err)
+}
+
+// TestTransportPinsPendingQueries asserts that pending state and receipt
+// queries are sent to the endpoint that took the last tx, even once another
+// endpoint becomes active or reads are load balanced, until the next tx is
+// published elsewhere.
+func TestTransportPinsPendingQueries(t *testing.T) {
+	t.Parallel()
+
+	primary := newTestEndpoint(t, "primary")
+	secondary := newTestEndpoint(t, "secondary")
+	client, transport := dialTestClient(t, failover.Config{
+		Backend:             "TestTransportPinsPendingQueries",
+		HealthCheckInterval: 10 * time.Millisecond,
+		LoadBalanceReads:    true,
+	}, primary, secondary)
+
+	callPinned := func(method string, args ...interface{}) string {
+		var result string
+		err := client.CallContext(
+			context.Background(), &result, method, args...,
+		)
+		require.Nil(t, err)
+		return result
+	}
+
+	// Without a published tx, pending queries follow the active endpoint.
+	require.Equal(t, "primary", callPinned(
+		"eth_getTransactionCount", "0x00", "pending",
+	))
+
+	primary.setStatus(http.StatusBadGateway)
+	require.Equal(t, "secondary", call(t, client, "eth_sendRawTransaction"))
+
+	primary.setStatus(0)
+	require.Eventually(t, func() bool {
+		return transport.Active() == primary.URL
+	}, 5*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, "secondary", callPinned(
+			"eth_getTransactionCount", "0x00", "pending",
+		))
+		require.Equal(t, "secondary", callPinned(
+			"eth_getTransactionReceipt", "0x01",
+		))
+	}
+
+	// Latest state queries are still load balanced.
+	results := make(map[string]int)
+	for i := 0; i < 4; i++ {
+		results[callPinned(
+			"eth_getTransactionCount", "0x00", "latest",
+		)]++
+	}
+	require.Equal(t, map[string]int{"primary": 2, "secondary": 2}, results)
+
+	// The next tx is published through the active endpoint, to which the
+	// pending queries follow.
+	require.Equal(t, "primary", call(t, client, "eth_sendRawTransaction"))
+	require.Equal(t, "primary", callPinned(
+		"eth_getTransactionCount", "0x00", "pending",
+	))
+}
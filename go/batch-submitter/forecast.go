@@ -0,0 +1,338 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+)
+
+const (
+	// adminForecastPath is the path at which the forecast cost and latency
+	// of submitting the backlog are queried by the admin server.
+	adminForecastPath = "/admin/forecast"
+
+	// forecastBlockSample is the number of recent L1 blocks over which the
+	// L1 block time is averaged.
+	forecastBlockSample = 20
+)
+
+const (
+	// FeeStrategyActive publishes at the tx manager's initial gas price,
+	// bumping it as the tx manager does each ResubmissionTimeout until the
+	// market gas price or the max number of bumps is reached.
+	FeeStrategyActive = "active"
+
+	// FeeStrategyMarket publishes at the current market gas price without
+	// bumping.
+	FeeStrategyMarket = "market"
+
+	// FeeStrategyMax publishes at the tx manager's max gas price without
+	// bumping.
+	FeeStrategyMax = "max"
+)
+
+// ErrNoCostHistory signals a forecast requested before any batch was confirmed,
+// leaving the gas used per L2 tx unknown.
+var ErrNoCostHistory = errors.New("no confirmed batches to forecast from")
+
+// FeeForecast is the forecast cost and latency of submitting the backlog under
+// a single fee strategy.
+type FeeForecast struct {
+	Strategy string `json:"strategy"`
+
+	// Confirmable is false if the strategy never reaches the market gas
+	// price, in which case the backlog is not expected to be submitted
+	// until the market gas price falls.
+	Confirmable bool `json:"confirmable"`
+
+	// GasPrice is the gas price at which each batch tx is expected to
+	// confirm, in wei.
+	GasPrice *big.Int `json:"gas_price"`
+
+	// NumBumps is the number of fee bumps expected per batch tx.
+	NumBumps uint64 `json:"num_bumps"`
+
+	// Cost is the expected cost of submitting the backlog, in wei.
+	Cost *big.Int `json:"cost"`
+
+	// CostPerL2Tx is Cost divided by the number of L2 txs in the backlog,
+	// in wei.
+	CostPerL2Tx *big.Int `json:"cost_per_l2_tx"`
+
+	// Latency is the expected duration until the backlog is confirmed, or
+	// empty if the strategy is not confirmable.
+	Latency string `json:"latency,omitempty"`
+}
+
+// BacklogForecast is the forecast of submitting the backlog of a service, as
+// returned by the admin API.
+type BacklogForecast struct {
+	// Start and End bound the L2 blocks of the backlog, [Start, End).
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	// NumBatches is the expected number of batch txs, given the number of
+	// L2 txs held by recently confirmed batches.
+	NumBatches uint64 `json:"num_batches"`
+
+	// GasPerL2Tx is the gas used per L2 tx by recently confirmed batches.
+	GasPerL2Tx uint64 `json:"gas_per_l2_tx"`
+
+	// MarketGasPrice is the L1 backend's suggested gas price, in wei.
+	MarketGasPrice *big.Int `json:"market_gas_price"`
+
+	// L1BlockTime is the average time between recent L1 blocks.
+	L1BlockTime string `json:"l1_block_time"`
+
+	// Strategies are the forecasts under the active fee strategy, followed
+	// by the alternatives.
+	Strategies []FeeForecast `json:"strategies"`
+}
+
+// forecastParams are the inputs of a backlog forecast.
+type forecastParams struct {
+	start, end uint64
+
+	// usage is the gas used and L2 txs batched by recently confirmed
+	// batches.
+	usage batchUsage
+
+	marketGasPrice  *big.Int
+	initialGasPrice *big.Int
+	l1BlockTime     time.Duration
+	pollInterval    time.Duration
+
+	// maxInFlight is the number of batch txs that confirm concurrently.
+	maxInFlight uint64
+
+	txMgr txmgr.Config
+}
+
+// forecastBacklog forecasts the cost and latency of submitting the backlog
+// under the active fee strategy and its alternatives.
+func forecastBacklog(params forecastParams) *BacklogForecast {
+	numL2Txs := params.end - params.start
+	gasPerL2Tx := params.usage.GasUsed / params.usage.NumL2Txs
+	l2TxsPerBatch := params.usage.NumL2Txs / params.usage.NumBatches
+	numBatches := (numL2Txs + l2TxsPerBatch - 1) / l2TxsPerBatch
+
+	forecast := &BacklogForecast{
+		Start:          params.start,
+		End:            params.end,
+		NumBatches:     numBatches,
+		GasPerL2Tx:     gasPerL2Tx,
+		MarketGasPrice: params.marketGasPrice,
+		L1BlockTime:    params.l1BlockTime.String(),
+	}
+
+	maxGasPrice := params.txMgr.MaxGasPrice
+	for _, strategy := range []struct {
+		name     string
+		gasPrice *big.Int
+		bump     bool
+	}{
+		{FeeStrategyActive, params.initialGasPrice, true},
+		{FeeStrategyMarket, params.marketGasPrice, false},
+		{FeeStrategyMax, maxGasPrice, false},
+	} {
+		gasPrice, numBumps := bumpedGasPrice(
+			params, strategy.gasPrice, strategy.bump,
+		)
+
+		cost := new(big.Int).Mul(
+			gasPrice, new(big.Int).SetUint64(gasPerL2Tx*numL2Txs),
+		)
+		fee := FeeForecast{
+			Strategy:    strategy.name,
+			Confirmable: gasPrice.Cmp(params.marketGasPrice) >= 0,
+			GasPrice:    gasPrice,
+			NumBumps:    numBumps,
+			Cost:        cost,
+			CostPerL2Tx: spendPerL2Tx(cost, numL2Txs),
+		}
+		if fee.Confirmable {
+			fee.Latency = forecastLatency(
+				params, numBatches, numBumps,
+			).String()
+		}
+		forecast.Strategies = append(forecast.Strategies, fee)
+	}
+
+	return forecast
+}
+
+// bumpedGasPrice returns the gas price at which a batch tx first published at
+// gasPrice is expected to confirm, along with the number of fee bumps leading to
+// it. If bump is true, the tx manager's bumps are followed until the market gas
+// price is reached, or no further bump is possible.
+func bumpedGasPrice(
+	params forecastParams,
+	gasPrice *big.Int,
+	bump bool,
+) (*big.Int, uint64) {
+
+	maxGasPrice := params.txMgr.MaxGasPrice
+	bumpPolicy := params.txMgr.FeeBump
+
+	gasPrice = new(big.Int).Set(gasPrice)
+	var numBumps uint64
+	for bump &&
+		gasPrice.Cmp(params.marketGasPrice) < 0 &&
+		gasPrice.Cmp(maxGasPrice) < 0 &&
+		(params.txMgr.GasRetryIncrement.Sign() > 0 ||
+			bumpPolicy.GasPricePercent > 0) &&
+		(bumpPolicy.MaxBumps == 0 || numBumps < bumpPolicy.MaxBumps) {
+
+		gasPrice = txmgr.BumpGasPrice(
+			gasPrice, params.txMgr.GasRetryIncrement,
+			bumpPolicy.GasPricePercent, maxGasPrice,
+		)
+		numBumps++
+	}
+
+	return gasPrice, numBumps
+}
+
+// forecastLatency returns the expected duration until numBatches batch txs are
+// confirmed, each after numBumps fee bumps. Each batch tx waits for the next
+// cycle, its bumps and its confirmations, with up to maxInFlight batch txs
+// confirming concurrently.
+func forecastLatency(
+	params forecastParams,
+	numBatches, numBumps uint64,
+) time.Duration {
+
+	numConfirmations := params.txMgr.NumConfirmations
+	if numConfirmations == 0 {
+		numConfirmations = 1
+	}
+
+	perBatch := params.pollInterval +
+		time.Duration(numBumps)*params.txMgr.ResubmissionTimeout +
+		time.Duration(numConfirmations)*params.l1BlockTime
+
+	maxInFlight := params.maxInFlight
+	if maxInFlight == 0 {
+		maxInFlight = 1
+	}
+	numRounds := (numBatches + maxInFlight - 1) / maxInFlight
+
+	return time.Duration(numRounds) * perBatch
+}
+
+// Forecast returns the forecast cost and latency of submitting the current
+// backlog under the active fee strategy and its alternatives. The gas used per
+// L2 tx, and the number of L2 txs per batch, are those of the batches
+// confirmed within the longest spend window.
+func (s *Service) Forecast(ctx context.Context) (*BacklogForecast, error) {
+	usage := s.spend.Usage(time.Now())
+	if usage.NumBatches == 0 || usage.NumL2Txs == 0 {
+		return nil, ErrNoCostHistory
+	}
+
+	start, end, err := s.cfg.Driver.GetBatchBlockRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	params, err := s.forecastParams(ctx, start.Uint64(), end.Uint64())
+	if err != nil {
+		return nil, err
+	}
+	params.usage = usage
+
+	return forecastBacklog(params), nil
+}
+
+// forecastParams gathers the inputs of a forecast of submitting the L2 blocks
+// [start, end), from the batches confirmed within the longest spend window and
+// the current state of L1.
+func (s *Service) forecastParams(
+	ctx context.Context,
+	start, end uint64,
+) (forecastParams, error) {
+
+	marketGasPrice, err := s.cfg.L1Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return forecastParams{}, err
+	}
+
+	l1BlockTime, err := s.l1BlockTime(ctx)
+	if err != nil {
+		return forecastParams{}, err
+	}
+
+	var maxInFlight uint64 = 1
+	if s.pipeline != nil {
+		maxInFlight = uint64(s.pipeline.maxInFlight)
+	}
+
+	return forecastParams{
+		start:           start,
+		end:             end,
+		usage:           s.spend.Usage(time.Now()),
+		marketGasPrice:  marketGasPrice,
+		initialGasPrice: s.initialGasPrice(ctx),
+		l1BlockTime:     l1BlockTime,
+		pollInterval:    s.PollInterval(),
+		maxInFlight:     maxInFlight,
+		txMgr:           s.cfg.TxManagerConfig,
+	}, nil
+}
+
+// initialGasPrice returns the gas price of the tx manager's first publication
+// attempt, i.e. the GasPriceOracle's suggestion if it exceeds MinGasPrice,
+// clamped to MaxGasPrice.
+func (s *Service) initialGasPrice(ctx context.Context) *big.Int {
+	cfg := s.cfg.TxManagerConfig
+	gasPrice := new(big.Int).Set(cfg.MinGasPrice)
+
+	if cfg.GasPriceOracle != nil {
+		suggested, err := cfg.GasPriceOracle.SuggestGasPrice(ctx)
+		if err == nil && suggested.Cmp(gasPrice) > 0 {
+			gasPrice.Set(suggested)
+		}
+	}
+	if gasPrice.Cmp(cfg.MaxGasPrice) > 0 {
+		gasPrice.Set(cfg.MaxGasPrice)
+	}
+
+	return gasPrice
+}
+
+// l1BlockTime returns the average time between the last forecastBlockSample
+// L1 blocks.
+func (s *Service) l1BlockTime(ctx context.Context) (time.Duration, error) {
+	latest, err := s.cfg.L1Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	sample := uint64(forecastBlockSample)
+	if latest.Number.Uint64() < sample {
+		sample = latest.Number.Uint64()
+	}
+	if sample == 0 {
+		return 0, nil
+	}
+
+	earliest, err := s.cfg.L1Client.HeaderByNumber(
+		ctx, new(big.Int).Sub(latest.Number, new(big.Int).SetUint64(sample)),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	elapsed := time.Duration(latest.Time-earliest.Time) * time.Second
+	return elapsed / time.Duration(sample), nil
+}
+
+// serveAdminForecast returns the forecast of submitting the backlog of a
+// service.
+func serveAdminForecast(s *Service, req *http.Request) (interface{}, error) {
+	return s.Forecast(req.Context())
+}
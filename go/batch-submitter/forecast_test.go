@@ -0,0 +1,106 @@
+package batchsubmitter
+
+import (
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// TestForecastBacklog asserts that the backlog is forecast to confirm once each
+// strategy reaches the market gas price, with the active strategy following
+// the tx manager's fee bumps.
+func TestForecastBacklog(t *testing.T) {
+	t.Parallel()
+
+	params := forecastParams{
+		start: 100,
+		end:   350,
+		usage: batchUsage{
+			NumBatches: 2,
+			NumL2Txs:   200,
+			GasUsed:    2000000,
+		},
+		marketGasPrice:  big.NewInt(35),
+		initialGasPrice: big.NewInt(10),
+		l1BlockTime:     12 * time.Second,
+		pollInterval:    time.Minute,
+		maxInFlight:     1,
+		txMgr: txmgr.Config{
+			MaxGasPrice:         big.NewInt(100),
+			GasRetryIncrement:   big.NewInt(10),
+			ResubmissionTimeout: 2 * time.Minute,
+			NumConfirmations:    2,
+		},
+	}
+
+	forecast := forecastBacklog(params)
+	require.Equal(t, uint64(3), forecast.NumBatches)
+	require.Equal(t, uint64(10000), forecast.GasPerL2Tx)
+	require.Equal(t, []FeeForecast{
+		{
+			Strategy:    FeeStrategyActive,
+			Confirmable: true,
+			GasPrice:    big.NewInt(40),
+			NumBumps:    3,
+			Cost:        big.NewInt(100000000),
+			CostPerL2Tx: big.NewInt(400000),
+			Latency:     "22m12s",
+		},
+		{
+			Strategy:    FeeStrategyMarket,
+			Confirmable: true,
+			GasPrice:    big.NewInt(35),
+			Cost:        big.NewInt(87500000),
+			CostPerL2Tx: big.NewInt(350000),
+			Latency:     "4m12s",
+		},
+		{
+			Strategy:    FeeStrategyMax,
+			Confirmable: true,
+			GasPrice:    big.NewInt(100),
+			Cost:        big.NewInt(250000000),
+			CostPerL2Tx: big.NewInt(1000000),
+			Latency:     "4m12s",
+		},
+	}, forecast.Strategies)
+
+	// Once the market gas price exceeds the max gas price, only the
+	// market strategy is expected to confirm. Batches in flight confirm
+	// concurrently.
+	params.txMgr.MaxGasPrice = big.NewInt(30)
+	params.maxInFlight = 2
+	forecast = forecastBacklog(params)
+
+	active := forecast.Strategies[0]
+	require.False(t, active.Confirmable)
+	require.Equal(t, big.NewInt(30), active.GasPrice)
+	require.Equal(t, uint64(2), active.NumBumps)
+	require.Empty(t, active.Latency)
+
+	market := forecast.Strategies[1]
+	require.True(t, market.Confirmable)
+	require.Equal(t, "2m48s", market.Latency)
+
+	require.False(t, forecast.Strategies[2].Confirmable)
+}
+
+// TestAdminForecastNoHistory asserts that a forecast is refused until a batch
+// has been confirmed.
+func TestAdminForecastNoHistory(t *testing.T) {
+	t.Parallel()
+
+	server, services := newAdminTestServer(
+		t, namedDriver{name: "TestAdminForecastNoHistory"},
+	)
+	services[0].spend = newSpendTracker()
+
+	resp, err := http.Get(server.URL + adminForecastPath +
+		"?service=TestAdminForecastNoHistory")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusConflict, resp.StatusCode)
+}
@@ -0,0 +1,56 @@
+package batchsubmitter
+
+import (
+	"context"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// GasPriceOracleNode uses the L1 provider's eth_gasPrice suggestion.
+	GasPriceOracleNode = "node"
+
+	// GasPriceOracleFeeHistory estimates the gas price from the L1
+	// provider's eth_feeHistory.
+	GasPriceOracleFeeHistory = "fee-history"
+
+	// GasPriceOracleHTTP queries an external HTTP gas price oracle.
+	GasPriceOracleHTTP = "http"
+)
+
+// newGasPriceOracle initializes the gas price oracle of the given type, or
+// returns nil if oracle is empty. The fee-history oracle requires raw access
+// to the L1 provider, and is therefore given l1RPCClient, which backs
+// l1Client.
+func newGasPriceOracle(
+	ctx context.Context,
+	cfg Config,
+	oracle string,
+	l1Client *ethclient.Client,
+	l1RPCClient *rpc.Client,
+) (txmgr.GasPriceOracle, error) {
+
+	switch oracle {
+	case "":
+		return nil, nil
+
+	case GasPriceOracleNode:
+		return txmgr.NewNodeGasPriceOracle(l1Client), nil
+
+	case GasPriceOracleFeeHistory:
+		return txmgr.NewFeeHistoryGasPriceOracle(
+			l1RPCClient, cfg.FeeHistoryBlockCount,
+			cfg.FeeHistoryPercentile,
+		)
+
+	case GasPriceOracleHTTP:
+		return txmgr.NewHTTPGasPriceOracle(
+			cfg.GasPriceOracleURL, cfg.GasPriceOracleField,
+		), nil
+
+	default:
+		return nil, ErrUnknownGasPriceOracle
+	}
+}
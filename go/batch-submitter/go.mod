@@ -7,10 +7,15 @@ require (
 	github.com/ethereum-optimism/optimism/l2geth v1.0.0
 	github.com/ethereum/go-ethereum v1.10.12
 	github.com/getsentry/sentry-go v0.11.0
+	github.com/gorilla/websocket v1.4.2
+	github.com/lib/pq v1.10.4
+	github.com/mattn/go-sqlite3 v1.14.10
 	github.com/prometheus/client_golang v1.11.0
+	github.com/prometheus/client_model v0.2.0
 	github.com/stretchr/testify v1.7.0
 	github.com/tyler-smith/go-bip39 v1.0.1-0.20181017060643-dbb3b84ba2ef
 	github.com/urfave/cli v1.22.5
+	go.etcd.io/bbolt v1.3.6
 )
 
 replace github.com/ethereum-optimism/optimism/l2geth => ../../l2geth
@@ -0,0 +1,286 @@
+package batchsubmitter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// healthzPath is the path at which liveness is served by the metrics
+	// server.
+	healthzPath = "/healthz"
+
+	// readyzPath is the path at which readiness is served by the metrics
+	// server.
+	readyzPath = "/readyz"
+
+	// healthPingTimeout bounds each RPC made by a readiness check.
+	healthPingTimeout = 5 * time.Second
+)
+
+// L2Pinger is an optional interface that may be implemented by a Driver to
+// report whether its L2 backend is reachable.
+type L2Pinger interface {
+	PingL2(ctx context.Context) error
+}
+
+// HealthCheck is the result of a single health check.
+type HealthCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ServiceHealth is the result of every health check of a single service.
+type ServiceHealth struct {
+	Name    string        `json:"name"`
+	Healthy bool          `json:"healthy"`
+	Checks  []HealthCheck `json:"checks"`
+}
+
+// add records the result of a check, marking the service unhealthy if the
+// check failed.
+func (h *ServiceHealth) add(name string, ok bool, detail string) {
+	h.Checks = append(h.Checks, HealthCheck{
+		Name:   name,
+		OK:     ok,
+		Detail: detail,
+	})
+	h.Healthy = h.Healthy && ok
+}
+
+// HealthResponse is the body returned by the health endpoints.
+type HealthResponse struct {
+	Healthy  bool            `json:"healthy"`
+	Services []ServiceHealth `json:"services"`
+}
+
+// healthState tracks the progress of a service reported by its health checks.
+//
+// NOTE: healthState is safe for concurrent use.
+type healthState struct {
+	mu            sync.Mutex
+	startedAt     time.Time
+	lastCycleAt   time.Time
+	lastConfirmAt time.Time
+	balance       *big.Int
+
+	// blockRange is the last observed range of L2 blocks awaiting
+	// submission, if any.
+	blockRange *BlockRangeLag
+}
+
+// newHealthState initializes the health state of a service starting now.
+func newHealthState() *healthState {
+	return &healthState{
+		startedAt: time.Now(),
+	}
+}
+
+// CycleCompleted records the completion of a cycle.
+func (h *healthState) CycleCompleted() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastCycleAt = time.Now()
+}
+
+// BatchConfirmed records the confirmation of a batch tx.
+func (h *healthState) BatchConfirmed() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastConfirmAt = time.Now()
+}
+
+// LeadershipGained restarts the confirmation age upon gaining leadership in
+// high availability mode, as a standby has no batch txs to confirm.
+func (h *healthState) LeadershipGained() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastConfirmAt = time.Now()
+}
+
+// SetBalance records the last observed balance of the service's wallet.
+func (h *healthState) SetBalance(balance *big.Int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.balance = new(big.Int).Set(balance)
+}
+
+// SetBlockRange records the last observed range of L2 blocks awaiting
+// submission, [start, end).
+func (h *healthState) SetBlockRange(start, end *big.Int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lag := &BlockRangeLag{
+		Start:      start.Uint64(),
+		End:        end.Uint64(),
+		ObservedAt: time.Now(),
+	}
+	if lag.End > lag.Start {
+		lag.Blocks = lag.End - lag.Start
+	}
+	h.blockRange = lag
+}
+
+// lag returns the last observed range of L2 blocks awaiting submission, or nil
+// if none has been observed.
+func (h *healthState) lag() *BlockRangeLag {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.blockRange == nil {
+		return nil
+	}
+	lag := *h.blockRange
+	return &lag
+}
+
+// progress returns the time of the last completed cycle, or the time at which
+// the service started if none has, along with the time of the last confirmed
+// batch tx and the last observed balance.
+func (h *healthState) progress() (time.Time, time.Time, *big.Int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	lastCycleAt := h.lastCycleAt
+	if lastCycleAt.IsZero() {
+		lastCycleAt = h.startedAt
+	}
+
+	return lastCycleAt, h.lastConfirmAt, h.balance
+}
+
+// Liveness reports whether the service's event loop is making progress. A
+// service whose last cycle completed more than MaxCycleAge ago is wedged, and
+// should be restarted.
+func (s *Service) Liveness() ServiceHealth {
+	health := ServiceHealth{
+		Name:    s.cfg.Driver.Name(),
+		Healthy: true,
+	}
+
+	lastCycleAt, _, _ := s.health.progress()
+	age := time.Since(lastCycleAt).Truncate(time.Second)
+	maxAge := s.cfg.MaxCycleAge
+	health.add("event_loop", maxAge == 0 || age <= maxAge,
+		fmt.Sprintf("last_cycle_age=%v max=%v", age, maxAge))
+
+	return health
+}
+
+// Readiness reports whether the service is able to submit batches: its L1 and
+// L2 backends must be reachable, its RPC circuit breaker must not have
+// tripped, its wallet balance must be at least MinBalance, and unless it stands
+// by, its last batch tx must have confirmed within MaxConfirmationAge.
+func (s *Service) Readiness(ctx context.Context) ServiceHealth {
+	health := ServiceHealth{
+		Name:    s.cfg.Driver.Name(),
+		Healthy: true,
+	}
+
+	ping := func(name string, pingFn func(context.Context) error) {
+		ctxt, cancel := context.WithTimeout(ctx, healthPingTimeout)
+		defer cancel()
+
+		if err := pingFn(ctxt); err != nil {
+			health.add(name, false, err.Error())
+			return
+		}
+		health.add(name, true, "")
+	}
+	if s.pingL1 != nil {
+		ping("l1_rpc", s.pingL1)
+	}
+	if pinger, ok := s.cfg.Driver.(L2Pinger); ok {
+		ping("l2_rpc", pinger.PingL2)
+	}
+
+	if s.cfg.RPCRetryPolicy.BreakerThreshold > 0 {
+		open, failures := s.breaker.State()
+		health.add("rpc_circuit", !open,
+			fmt.Sprintf("consecutive_failures=%d threshold=%d",
+				failures, s.cfg.RPCRetryPolicy.BreakerThreshold))
+	}
+
+	_, lastConfirmAt, balance := s.health.progress()
+
+	if minBalance := s.cfg.MinBalance; minBalance != nil &&
+		minBalance.Sign() > 0 {
+
+		switch {
+		case balance == nil:
+			health.add("balance", false, "balance not yet observed")
+		default:
+			health.add("balance", balance.Cmp(minBalance) >= 0,
+				fmt.Sprintf("balance=%v min=%v", balance,
+					minBalance))
+		}
+	}
+
+	// Before any batch confirms, the confirmation age is measured from
+	// startup, allowing a freshly started service time to submit. A
+	// standby has nothing to confirm.
+	if maxAge := s.cfg.MaxConfirmationAge; maxAge > 0 && s.isLeader() {
+		if lastConfirmAt.IsZero() {
+			lastConfirmAt = s.health.startedAt
+		}
+		age := time.Since(lastConfirmAt).Truncate(time.Second)
+		health.add("last_confirmation", age <= maxAge,
+			fmt.Sprintf("age=%v max=%v", age, maxAge))
+	}
+
+	return health
+}
+
+// healthHandler serves the liveness or readiness of the services in a
+// registry.
+type healthHandler struct {
+	registry *statusRegistry
+	ready    bool
+}
+
+// ServeHTTP serves the health of all registered services as JSON, responding
+// with 503 Service Unavailable if any service is unhealthy. Readiness also
+// requires at least one service to be registered.
+func (h healthHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	services := h.registry.sorted()
+	resp := HealthResponse{
+		Healthy:  !h.ready || len(services) > 0,
+		Services: make([]ServiceHealth, 0, len(services)),
+	}
+	for _, s := range services {
+		var health ServiceHealth
+		if h.ready {
+			health = s.Readiness(req.Context())
+		} else {
+			health = s.Liveness()
+		}
+		resp.Healthy = resp.Healthy && health.Healthy
+		resp.Services = append(resp.Services, health)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !resp.Healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Error("Unable to write health response", "err", err)
+	}
+}
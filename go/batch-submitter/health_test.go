@@ -0,0 +1,121 @@
+package batchsubmitter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newHealthTestService returns a service whose L1 backend is reported by pingL1.
+func newHealthTestService(
+	name string, pingL1 func(context.Context) error) *Service {
+
+	return &Service{
+		cfg: ServiceConfig{
+			Driver:             namedDriver{name: name},
+			MinBalance:         big.NewInt(100),
+			MaxCycleAge:        time.Minute,
+			MaxConfirmationAge: time.Hour,
+		},
+		health: newHealthState(),
+		pingL1: pingL1,
+	}
+}
+
+// TestServiceLiveness asserts that a service is live until its last cycle is
+// older than MaxCycleAge.
+func TestServiceLiveness(t *testing.T) {
+	t.Parallel()
+
+	s := newHealthTestService("TestServiceLiveness", nil)
+	require.True(t, s.Liveness().Healthy)
+
+	s.health.lastCycleAt = time.Now().Add(-2 * time.Minute)
+	require.False(t, s.Liveness().Healthy)
+
+	s.health.CycleCompleted()
+	require.True(t, s.Liveness().Healthy)
+}
+
+// TestServiceReadiness asserts that a service is only ready once its L1
+// backend is reachable, its balance has been observed above the minimum, and
+// its last confirmation is recent.
+func TestServiceReadiness(t *testing.T) {
+	t.Parallel()
+
+	var l1Err error
+	s := newHealthTestService("TestServiceReadiness",
+		func(context.Context) error { return l1Err })
+
+	// The balance has yet to be observed.
+	require.False(t, s.Readiness(context.Background()).Healthy)
+
+	s.health.SetBalance(big.NewInt(100))
+	require.True(t, s.Readiness(context.Background()).Healthy)
+
+	s.health.SetBalance(big.NewInt(99))
+	require.False(t, s.Readiness(context.Background()).Healthy)
+
+	s.health.SetBalance(big.NewInt(100))
+	l1Err = errors.New("connection refused")
+	health := s.Readiness(context.Background())
+	require.False(t, health.Healthy)
+	require.Equal(t, "l1_rpc", health.Checks[0].Name)
+	require.Equal(t, l1Err.Error(), health.Checks[0].Detail)
+
+	l1Err = nil
+	s.health.startedAt = time.Now().Add(-2 * time.Hour)
+	require.False(t, s.Readiness(context.Background()).Healthy)
+
+	s.health.BatchConfirmed()
+	require.True(t, s.Readiness(context.Background()).Healthy)
+}
+
+// TestHealthHandler asserts that the health endpoints respond with 503 if any
+// service is unhealthy, and that readiness requires a registered service.
+func TestHealthHandler(t *testing.T) {
+	t.Parallel()
+
+	registry := &statusRegistry{
+		services: make(map[string]*Service),
+	}
+	get := func(ready bool) (int, HealthResponse) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, healthzPath, nil)
+		healthHandler{registry: registry, ready: ready}.ServeHTTP(rec, req)
+
+		var resp HealthResponse
+		require.Nil(t, json.NewDecoder(rec.Body).Decode(&resp))
+		return rec.Code, resp
+	}
+
+	code, _ := get(false)
+	require.Equal(t, http.StatusOK, code)
+	code, _ = get(true)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+
+	live := newHealthTestService("TestHealthHandlerLive", nil)
+	wedged := newHealthTestService("TestHealthHandlerWedged", nil)
+	wedged.health.lastCycleAt = time.Now().Add(-time.Hour)
+	registry.register(live)
+	registry.register(wedged)
+
+	code, resp := get(false)
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.False(t, resp.Healthy)
+	require.Len(t, resp.Services, 2)
+	require.True(t, resp.Services[0].Healthy)
+	require.False(t, resp.Services[1].Healthy)
+
+	registry.unregister(wedged)
+	code, resp = get(false)
+	require.Equal(t, http.StatusOK, code)
+	require.True(t, resp.Healthy)
+}
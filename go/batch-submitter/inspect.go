@@ -0,0 +1,148 @@
+package batchsubmitter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/failover"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+)
+
+// ErrInvalidTxHash signals that the inspect subcommand was not given a valid
+// tx hash.
+var ErrInvalidTxHash = errors.New("expected a 32-byte hex tx hash")
+
+// batchTxInspection is the output of the inspect subcommand.
+type batchTxInspection struct {
+	TxHash common.Hash     `json:"tx_hash"`
+	From   common.Address  `json:"from"`
+	To     *common.Address `json:"to"`
+
+	// BlockNumber and Status are only set once the tx is mined.
+	BlockNumber *uint64 `json:"block_number,omitempty"`
+	Status      *uint64 `json:"status,omitempty"`
+
+	// CTCTotalElements is the number of elements recorded by the CTC
+	// before the tx's block, or currently if it is pending, which is the
+	// start the batch was expected to have. If the CTC cannot be queried
+	// at that block, e.g. without an archive node, it is omitted and
+	// CTCQueryError is set instead.
+	CTCTotalElements *uint64 `json:"ctc_total_elements,omitempty"`
+	CTCQueryError    string  `json:"ctc_query_error,omitempty"`
+
+	Batch *sequencer.BatchInspection `json:"batch"`
+}
+
+// parseTxHash parses a 0x-prefixed, 32-byte hex tx hash.
+func parseTxHash(s string) (common.Hash, error) {
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("%w: %q", ErrInvalidTxHash, s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+// Inspect runs the inspect subcommand, fetching the L1 tx with the given hash
+// and printing its decoded sequencer batch as JSON, including its contexts,
+// block ranges, tx counts and sizes. Alongside the batch, the number of
+// elements the CTC recorded beforehand is printed, such that a batch reverted
+// for starting at an unexpected element can be diagnosed.
+func Inspect(ctx *cli.Context) error {
+	txHash, err := parseTxHash(ctx.Args().First())
+	if err != nil {
+		return err
+	}
+	cfg, err := NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+	ctcAddr, err := ParseAddress(cfg.CTCAddress)
+	if err != nil {
+		return err
+	}
+
+	endpoints := splitEndpoints(cfg.L1EthRpc)
+	if len(endpoints) == 0 {
+		return failover.ErrNoEndpoints
+	}
+	c := context.Background()
+	l1Client, err := ethclient.DialContext(c, endpoints[0])
+	if err != nil {
+		return err
+	}
+	defer l1Client.Close()
+
+	tx, pending, err := l1Client.TransactionByHash(c, txHash)
+	if err != nil {
+		return err
+	}
+	batch, err := sequencer.InspectBatch(tx.Data(), cfg.BlockOffset)
+	if err != nil {
+		return err
+	}
+
+	chainID, err := l1Client.ChainID(c)
+	if err != nil {
+		return err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return err
+	}
+
+	inspection := &batchTxInspection{
+		TxHash: txHash,
+		From:   from,
+		To:     tx.To(),
+		Batch:  batch,
+	}
+
+	// The CTC is queried at the parent of the including block, reflecting
+	// the state against which the batch was executed.
+	var blockNumber *big.Int
+	if !pending {
+		receipt, err := l1Client.TransactionReceipt(c, txHash)
+		if err != nil {
+			return err
+		}
+		number := receipt.BlockNumber.Uint64()
+		inspection.BlockNumber = &number
+		inspection.Status = &receipt.Status
+		blockNumber = new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1))
+	}
+
+	ctcContract, err := ctc.NewCanonicalTransactionChainCaller(
+		ctcAddr, l1Client,
+	)
+	if err != nil {
+		return err
+	}
+	totalElements, err := ctcContract.GetTotalElements(&bind.CallOpts{
+		BlockNumber: blockNumber,
+		Context:     c,
+	})
+	if err != nil {
+		inspection.CTCQueryError = err.Error()
+	} else {
+		total := totalElements.Uint64()
+		inspection.CTCTotalElements = &total
+	}
+
+	out, err := json.MarshalIndent(inspection, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(os.Stdout, string(out))
+	return err
+}
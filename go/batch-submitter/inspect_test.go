@@ -0,0 +1,24 @@
+package batchsubmitter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTxHash asserts that only 0x-prefixed, 32-byte hex hashes are
+// accepted.
+func TestParseTxHash(t *testing.T) {
+	hash := common.HexToHash("0x01")
+
+	parsed, err := parseTxHash(hash.Hex())
+	require.Nil(t, err)
+	require.Equal(t, hash, parsed)
+
+	for _, s := range []string{"", "0x01", hash.Hex()[2:], hash.Hex() + "00"} {
+		_, err := parseTxHash(s)
+		require.True(t, errors.Is(err, ErrInvalidTxHash), s)
+	}
+}
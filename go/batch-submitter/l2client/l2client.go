@@ -0,0 +1,113 @@
+package l2client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	l2ethclient "github.com/ethereum-optimism/optimism/l2geth/ethclient"
+	l2rpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
+)
+
+// ErrUnknownFlavor signals that the L2 node was configured to serve an
+// unsupported flavor of JSON-RPC.
+var ErrUnknownFlavor = errors.New("l2-rpc-flavor must be one of " +
+	"l2geth, op-geth or auto")
+
+// Client reads the L2 blocks to be batched, along with the L1 metadata of their
+// txs, from an L2 node.
+type Client interface {
+	// HeaderByNumber returns the header of the block at number, or of the
+	// latest block if number is nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*l2types.Header, error)
+
+	// BlockByNumber returns the block at number, or the latest block if
+	// number is nil. The L1 metadata of each tx is populated.
+	BlockByNumber(ctx context.Context, number *big.Int) (*l2types.Block, error)
+}
+
+// Flavor identifies the JSON-RPC representation of txs served by an L2 node,
+// which differ in how they report the queue origin and index of a tx.
+type Flavor string
+
+const (
+	// FlavorL2Geth is served by legacy l2geth nodes, which report the L1
+	// metadata of a tx in its queueOrigin, queueIndex and l1MessageSender
+	// fields.
+	FlavorL2Geth Flavor = "l2geth"
+
+	// FlavorOpGeth is served by op-geth style nodes, which report txs
+	// originating from the L1 queue as deposit txs. See OpGethClient.
+	FlavorOpGeth Flavor = "op-geth"
+
+	// FlavorAuto detects the flavor served by the node at startup.
+	FlavorAuto Flavor = "auto"
+)
+
+// ParseFlavor parses a Flavor from its name, defaulting to FlavorL2Geth if
+// name is empty.
+func ParseFlavor(name string) (Flavor, error) {
+	switch flavor := Flavor(name); flavor {
+	case "":
+		return FlavorL2Geth, nil
+	case FlavorL2Geth, FlavorOpGeth, FlavorAuto:
+		return flavor, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownFlavor, name)
+	}
+}
+
+// New returns a Client of the given flavor reading from rpcClient, detecting
+// the flavor served by the node if it is FlavorAuto. The resolved flavor is
+// returned alongside the Client.
+func New(
+	ctx context.Context,
+	rpcClient *l2rpc.Client,
+	flavor Flavor,
+) (Client, Flavor, error) {
+
+	if flavor == FlavorAuto {
+		var err error
+		flavor, err = Detect(ctx, rpcClient)
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	switch flavor {
+	case FlavorL2Geth:
+		return l2ethclient.NewClient(rpcClient), flavor, nil
+	case FlavorOpGeth:
+		return NewOpGethClient(rpcClient), flavor, nil
+	default:
+		return nil, "", fmt.Errorf("%w: %s", ErrUnknownFlavor, flavor)
+	}
+}
+
+// Detect returns the flavor served by the node behind rpcClient, judged by
+// whether the txs of its latest block carry the queueOrigin field that only
+// l2geth reports. If the latest block holds no txs, e.g. at genesis, the node
+// is assumed to be an l2geth node.
+func Detect(ctx context.Context, rpcClient *l2rpc.Client) (Flavor, error) {
+	var block *struct {
+		Transactions []map[string]json.RawMessage `json:"transactions"`
+	}
+	err := rpcClient.CallContext(
+		ctx, &block, "eth_getBlockByNumber", "latest", true,
+	)
+	if err != nil {
+		return "", err
+	}
+	if block == nil || len(block.Transactions) == 0 {
+		return FlavorL2Geth, nil
+	}
+
+	if _, ok := block.Transactions[0]["queueOrigin"]; ok {
+		return FlavorL2Geth, nil
+	}
+
+	return FlavorOpGeth, nil
+}
@@ -0,0 +1,121 @@
+package l2client_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	ethereum "github.com/ethereum-optimism/optimism/l2geth"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestL2 returns an L2 chain served by newL2, closed once the test
+// completes, holding a sequencer tx followed by a queue tx.
+func newTestL2(
+	t *testing.T,
+	newL2 func() (*testutil.L2, error),
+) *testutil.L2 {
+
+	l2, err := newL2()
+	require.Nil(t, err)
+	t.Cleanup(l2.Close)
+
+	l2.AddBlock(100, testutil.SequencerTx(0, 1))
+	l2.AddBlock(101, testutil.QueueTx(3, testutil.QueueElement{
+		Timestamp:   101,
+		BlockNumber: 2,
+	}))
+
+	return l2
+}
+
+// TestParseFlavor asserts that flavors are parsed from their names, defaulting
+// to l2geth.
+func TestParseFlavor(t *testing.T) {
+	t.Parallel()
+
+	flavor, err := l2client.ParseFlavor("")
+	require.Nil(t, err)
+	require.Equal(t, l2client.FlavorL2Geth, flavor)
+
+	flavor, err = l2client.ParseFlavor("op-geth")
+	require.Nil(t, err)
+	require.Equal(t, l2client.FlavorOpGeth, flavor)
+
+	_, err = l2client.ParseFlavor("erigon")
+	require.Equal(t, fmt.Errorf("%w: erigon", l2client.ErrUnknownFlavor), err)
+}
+
+// TestDetect asserts that the flavor served by a node is detected from the txs
+// of its latest block, assuming l2geth while it holds none.
+func TestDetect(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	genesis, err := testutil.NewOpGethL2()
+	require.Nil(t, err)
+	t.Cleanup(genesis.Close)
+	flavor, err := l2client.Detect(ctx, genesis.RPCClient())
+	require.Nil(t, err)
+	require.Equal(t, l2client.FlavorL2Geth, flavor)
+
+	l2geth := newTestL2(t, testutil.NewL2)
+	flavor, err = l2client.Detect(ctx, l2geth.RPCClient())
+	require.Nil(t, err)
+	require.Equal(t, l2client.FlavorL2Geth, flavor)
+
+	opGeth := newTestL2(t, testutil.NewOpGethL2)
+	client, flavor, err := l2client.New(
+		ctx, opGeth.RPCClient(), l2client.FlavorAuto,
+	)
+	require.Nil(t, err)
+	require.Equal(t, l2client.FlavorOpGeth, flavor)
+	require.IsType(t, &l2client.OpGethClient{}, client)
+}
+
+// TestOpGethClientBlockByNumber asserts that blocks read from an op-geth style
+// node carry the same hashes and L1 metadata as when read from l2geth.
+func TestOpGethClientBlockByNumber(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	l2geth := newTestL2(t, testutil.NewL2).Client()
+	opGeth := newTestL2(t, testutil.NewOpGethL2).Client()
+
+	for number := int64(0); number <= 2; number++ {
+		expected, err := l2geth.BlockByNumber(ctx, big.NewInt(number))
+		require.Nil(t, err)
+		block, err := opGeth.BlockByNumber(ctx, big.NewInt(number))
+		require.Nil(t, err)
+
+		require.Equal(t, expected.Hash(), block.Hash())
+		require.Equal(t, expected.ParentHash(), block.ParentHash())
+		require.Equal(t, expected.Time(), block.Time())
+		require.Len(t, block.Transactions(), len(expected.Transactions()))
+		for i, tx := range block.Transactions() {
+			expectedTx := expected.Transactions()[i]
+			require.Equal(t, expectedTx.QueueOrigin(), tx.QueueOrigin())
+			require.Equal(t, expectedTx.L1BlockNumber(), tx.L1BlockNumber())
+			require.Equal(t, expectedTx.GetMeta().QueueIndex,
+				tx.GetMeta().QueueIndex)
+			require.Equal(t, expectedTx.L1MessageSender(),
+				tx.L1MessageSender())
+			if tx.QueueOrigin() == l2types.QueueOriginSequencer {
+				require.Equal(t, expectedTx.Hash(), tx.Hash())
+			}
+		}
+	}
+
+	header, err := opGeth.HeaderByNumber(ctx, nil)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), header.Number.Uint64())
+
+	_, err = opGeth.BlockByNumber(ctx, big.NewInt(3))
+	require.True(t, errors.Is(err, ethereum.NotFound))
+}
@@ -0,0 +1,212 @@
+package l2client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethereum "github.com/ethereum-optimism/optimism/l2geth"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2hexutil "github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	l2rpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
+)
+
+// DepositTxType is the type reported by op-geth style nodes for txs
+// originating from the L1 queue.
+const DepositTxType = 0x7e
+
+// ErrHeaderHashMismatch signals that the hash reported for an L2 block differs
+// from the hash of its legacy header, e.g. as the block was produced after the
+// node adopted a header format that can no longer be batched.
+var ErrHeaderHashMismatch = errors.New("l2 block hash does not match " +
+	"its legacy header")
+
+// OpGethClient is a Client reading from an op-geth style node, which reports
+// the L1 metadata of txs differently from l2geth:
+//   - txs originating from the L1 queue are deposit txs of DepositTxType,
+//     whose from field is the L1 message sender and whose nonce field is the
+//     index of their queue element.
+//   - every other tx was sequenced, and is reported as a legacy tx.
+//   - every tx reports the L1 block number and timestamp of its context in
+//     its l1BlockNumber and l1Timestamp fields, as l2geth does.
+//
+// Headers are decoded from their legacy fields only, and are checked against
+// the hash reported by the node, such that the parent hashes of consecutive
+// blocks match as they do when read from l2geth.
+type OpGethClient struct {
+	c *l2rpc.Client
+}
+
+// NewOpGethClient returns an OpGethClient reading from rpcClient.
+func NewOpGethClient(rpcClient *l2rpc.Client) *OpGethClient {
+	return &OpGethClient{
+		c: rpcClient,
+	}
+}
+
+// opGethBlock is the body of an L2 block, as served by an op-geth style node.
+type opGethBlock struct {
+	Hash         l2common.Hash       `json:"hash"`
+	Transactions []opGethTransaction `json:"transactions"`
+}
+
+// opGethTransaction is an L2 tx, as served by an op-geth style node.
+type opGethTransaction struct {
+	Type          l2hexutil.Uint64  `json:"type"`
+	From          l2common.Address  `json:"from"`
+	Nonce         l2hexutil.Uint64  `json:"nonce"`
+	To            *l2common.Address `json:"to"`
+	Value         *l2hexutil.Big    `json:"value"`
+	Gas           l2hexutil.Uint64  `json:"gas"`
+	Input         l2hexutil.Bytes   `json:"input"`
+	L1BlockNumber *l2hexutil.Big    `json:"l1BlockNumber"`
+	L1Timestamp   l2hexutil.Uint64  `json:"l1Timestamp"`
+
+	// raw is the tx's full representation, from which sequenced txs are
+	// decoded.
+	raw json.RawMessage
+}
+
+// UnmarshalJSON decodes the fields of the tx, retaining its representation.
+func (tx *opGethTransaction) UnmarshalJSON(msg []byte) error {
+	type fields opGethTransaction
+	if err := json.Unmarshal(msg, (*fields)(tx)); err != nil {
+		return err
+	}
+	tx.raw = append(json.RawMessage(nil), msg...)
+
+	return nil
+}
+
+// HeaderByNumber returns the header of the block at number, or of the latest
+// block if number is nil.
+func (c *OpGethClient) HeaderByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*l2types.Header, error) {
+
+	head, _, err := c.getBlock(ctx, number, false)
+	return head, err
+}
+
+// BlockByNumber returns the block at number, or the latest block if number is
+// nil, translating the L1 metadata of its txs to that reported by l2geth.
+func (c *OpGethClient) BlockByNumber(
+	ctx context.Context,
+	number *big.Int,
+) (*l2types.Block, error) {
+
+	head, body, err := c.getBlock(ctx, number, true)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]*l2types.Transaction, 0, len(body.Transactions))
+	for i, rpcTx := range body.Transactions {
+		tx, err := rpcTx.toTransaction()
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode tx %d of l2 block "+
+				"%d: %w", i, head.Number, err)
+		}
+		txs = append(txs, tx)
+	}
+
+	return l2types.NewBlockWithHeader(head).WithBody(txs, nil), nil
+}
+
+// getBlock fetches the header and body of the block at number, or of the
+// latest block if number is nil, with txs in full if fullTx is true.
+func (c *OpGethClient) getBlock(
+	ctx context.Context,
+	number *big.Int,
+	fullTx bool,
+) (*l2types.Header, *opGethBlock, error) {
+
+	numberArg := "latest"
+	if number != nil {
+		numberArg = l2hexutil.EncodeBig(number)
+	}
+
+	var raw json.RawMessage
+	err := c.c.CallContext(
+		ctx, &raw, "eth_getBlockByNumber", numberArg, fullTx,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil, ethereum.NotFound
+	}
+
+	var head *l2types.Header
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return nil, nil, err
+	}
+	var body opGethBlock
+	if fullTx {
+		if err := json.Unmarshal(raw, &body); err != nil {
+			return nil, nil, err
+		}
+	} else if err := json.Unmarshal(raw, &struct {
+		Hash *l2common.Hash `json:"hash"`
+	}{&body.Hash}); err != nil {
+		return nil, nil, err
+	}
+
+	if head.Hash() != body.Hash {
+		return nil, nil, fmt.Errorf("%w: block %d reported as %s, "+
+			"computed %s", ErrHeaderHashMismatch, head.Number,
+			body.Hash.Hex(), head.Hash().Hex())
+	}
+
+	return head, &body, nil
+}
+
+// toTransaction returns the tx, with the L1 metadata l2geth would report.
+func (tx *opGethTransaction) toTransaction() (*l2types.Transaction, error) {
+	if tx.L1BlockNumber == nil {
+		return nil, errors.New("missing l1BlockNumber")
+	}
+	l1BlockNumber := (*big.Int)(tx.L1BlockNumber)
+	l1Timestamp := uint64(tx.L1Timestamp)
+
+	if tx.Type != DepositTxType {
+		var sequenced *l2types.Transaction
+		if err := json.Unmarshal(tx.raw, &sequenced); err != nil {
+			return nil, err
+		}
+		sequenced.SetTransactionMeta(l2types.NewTransactionMeta(
+			l1BlockNumber, l1Timestamp, nil,
+			l2types.QueueOriginSequencer, nil, nil, nil,
+		))
+		return sequenced, nil
+	}
+
+	value := new(big.Int)
+	if tx.Value != nil {
+		value.Set((*big.Int)(tx.Value))
+	}
+
+	var deposit *l2types.Transaction
+	if tx.To == nil {
+		deposit = l2types.NewContractCreation(
+			0, value, uint64(tx.Gas), new(big.Int), tx.Input,
+		)
+	} else {
+		deposit = l2types.NewTransaction(
+			0, *tx.To, value, uint64(tx.Gas), new(big.Int), tx.Input,
+		)
+	}
+
+	sender := tx.From
+	queueIndex := uint64(tx.Nonce)
+	deposit.SetTransactionMeta(l2types.NewTransactionMeta(
+		l1BlockNumber, l1Timestamp, &sender,
+		l2types.QueueOriginL1ToL2, nil, &queueIndex, nil,
+	))
+
+	return deposit, nil
+}
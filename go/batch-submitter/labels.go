@@ -0,0 +1,109 @@
+package batchsubmitter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ErrInvalidAddressLabel signals that an entry of the address labels is not of
+// the form address=label.
+var ErrInvalidAddressLabel = errors.New("address labels must be " +
+	"comma-separated address=label pairs")
+
+// ParseAddressLabels parses a comma-separated list of address=label pairs,
+// e.g. "0x4200...0005=mainnet-ctc,0x4200...0006=mainnet-scc".
+func ParseAddressLabels(labels string) (map[common.Address]string, error) {
+	parsed := make(map[common.Address]string)
+	if strings.TrimSpace(labels) == "" {
+		return parsed, nil
+	}
+
+	for _, entry := range strings.Split(labels, ",") {
+		parts := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidAddressLabel,
+				entry)
+		}
+
+		addr, err := ParseAddress(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidAddressLabel, err)
+		}
+
+		label := strings.TrimSpace(parts[1])
+		if label == "" {
+			return nil, fmt.Errorf("%w: empty label for %s",
+				ErrInvalidAddressLabel, addr)
+		}
+		parsed[addr] = label
+	}
+
+	return parsed, nil
+}
+
+// AddressBook maps addresses to human-readable labels, such that logs and the
+// status API identify the contracts and wallets involved by name.
+type AddressBook struct {
+	mu     sync.RWMutex
+	labels map[common.Address]string
+}
+
+// NewAddressBook initializes an AddressBook with the given labels.
+func NewAddressBook(labels map[common.Address]string) *AddressBook {
+	book := &AddressBook{
+		labels: make(map[common.Address]string, len(labels)),
+	}
+	for addr, label := range labels {
+		book.labels[addr] = label
+	}
+
+	return book
+}
+
+// SetDefault labels addr unless it has already been labeled, allowing
+// configured labels to take precedence over the built-in ones.
+func (b *AddressBook) SetDefault(addr common.Address, label string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.labels[addr]; !ok {
+		b.labels[addr] = label
+	}
+}
+
+// Label returns the label of addr, or the empty string if it is unlabeled.
+func (b *AddressBook) Label(addr common.Address) string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.labels[addr]
+}
+
+// Format returns addr alongside its label, e.g. "0x4200...0005 (mainnet-ctc)",
+// or just addr if it is unlabeled.
+func (b *AddressBook) Format(addr common.Address) string {
+	label := b.Label(addr)
+	if label == "" {
+		return addr.Hex()
+	}
+
+	return fmt.Sprintf("%s (%s)", addr.Hex(), label)
+}
+
+// LabeledAddress is an address and its label, as reported by the status API.
+type LabeledAddress struct {
+	Address common.Address `json:"address"`
+	Label   string         `json:"label,omitempty"`
+}
+
+// Labeled returns addr alongside its label.
+func (b *AddressBook) Labeled(addr common.Address) LabeledAddress {
+	return LabeledAddress{
+		Address: addr,
+		Label:   b.Label(addr),
+	}
+}
@@ -0,0 +1,92 @@
+package batchsubmitter_test
+
+import (
+	"errors"
+	"testing"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	ctcAddr = common.HexToAddress("0x4200000000000000000000000000000000000005")
+	sccAddr = common.HexToAddress("0x4200000000000000000000000000000000000006")
+)
+
+// TestParseAddressLabels asserts that ParseAddressLabels accepts well-formed
+// address=label pairs and rejects any malformed entry.
+func TestParseAddressLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		labels    string
+		expLabels map[common.Address]string
+		expErr    error
+	}{
+		{
+			name:      "empty",
+			labels:    "",
+			expLabels: map[common.Address]string{},
+		},
+		{
+			name: "multiple labels",
+			labels: " 0x4200000000000000000000000000000000000005=mainnet-ctc, " +
+				"0x4200000000000000000000000000000000000006 = mainnet-scc",
+			expLabels: map[common.Address]string{
+				ctcAddr: "mainnet-ctc",
+				sccAddr: "mainnet-scc",
+			},
+		},
+		{
+			name:   "missing separator",
+			labels: "0x4200000000000000000000000000000000000005",
+			expErr: batchsubmitter.ErrInvalidAddressLabel,
+		},
+		{
+			name:   "invalid address",
+			labels: "0x42=mainnet-ctc",
+			expErr: batchsubmitter.ErrInvalidAddressLabel,
+		},
+		{
+			name:   "empty label",
+			labels: "0x4200000000000000000000000000000000000005=",
+			expErr: batchsubmitter.ErrInvalidAddressLabel,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			labels, err := batchsubmitter.ParseAddressLabels(test.labels)
+			if test.expErr != nil {
+				require.True(t, errors.Is(err, test.expErr))
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, test.expLabels, labels)
+		})
+	}
+}
+
+// TestAddressBookConfiguredLabelsTakePrecedence asserts that default labels
+// never override configured ones, and that unlabeled addresses are formatted
+// without a label.
+func TestAddressBookConfiguredLabelsTakePrecedence(t *testing.T) {
+	t.Parallel()
+
+	book := batchsubmitter.NewAddressBook(map[common.Address]string{
+		ctcAddr: "mainnet-ctc",
+	})
+	book.SetDefault(ctcAddr, "CTC")
+	book.SetDefault(sccAddr, "SCC")
+
+	require.Equal(t, "mainnet-ctc", book.Label(ctcAddr))
+	require.Equal(t, "SCC", book.Label(sccAddr))
+	require.Equal(t, ctcAddr.Hex()+" (mainnet-ctc)", book.Format(ctcAddr))
+
+	unlabeled := common.HexToAddress("0x01")
+	require.Equal(t, "", book.Label(unlabeled))
+	require.Equal(t, unlabeled.Hex(), book.Format(unlabeled))
+}
@@ -0,0 +1,219 @@
+package leader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// DefaultLeaseDuration is the duration for which leadership is leased
+	// if none is configured.
+	DefaultLeaseDuration = 15 * time.Second
+
+	// releaseTimeout bounds the request releasing the lock upon Stop.
+	releaseTimeout = 5 * time.Second
+)
+
+// ErrReplicaIDNotSet signals that an Elector was configured without an ID
+// identifying its replica to the others.
+var ErrReplicaIDNotSet = errors.New("replica id must be set")
+
+// Lock is a lock with an expiring lease, shared by the replicas contending for
+// leadership.
+type Lock interface {
+	// Acquire takes the lock on behalf of holder for ttl, or extends its
+	// lease if holder already holds it. It returns true if holder holds
+	// the lock afterwards.
+	Acquire(ctx context.Context, holder string, ttl time.Duration) (bool,
+		error)
+
+	// Release releases the lock if it is held by holder.
+	Release(ctx context.Context, holder string) error
+}
+
+// Config houses parameters for altering the behavior of an Elector.
+type Config struct {
+	// Lock is the lock contended for by the replicas.
+	Lock Lock
+
+	// ID identifies this replica to the others, and must be unique among
+	// them.
+	ID string
+
+	// LeaseDuration is the duration for which the lock is leased upon each
+	// acquisition, and the time a standby may have to wait to take over
+	// from a leader that failed without releasing the lock. If zero,
+	// DefaultLeaseDuration is used.
+	LeaseDuration time.Duration
+
+	// RenewInterval is the interval at which the leader renews its lease
+	// and standbys attempt to acquire it. If zero, a third of the
+	// LeaseDuration is used.
+	RenewInterval time.Duration
+
+	// OnChange, if non-nil, is invoked from the campaign loop whenever this
+	// replica gains or loses leadership.
+	OnChange func(leading bool)
+}
+
+// Elector campaigns for leadership among replicas sharing a Lock, such that
+// only the leader submits batches while the others stand by.
+//
+// Leadership is only reported while the lease is known to be held with a margin
+// of one renew interval to spare, so that a leader unable to renew its lease,
+// e.g. if partitioned from the lock backend, steps down before a standby can
+// acquire the lock.
+type Elector struct {
+	cfg Config
+
+	mu         sync.Mutex
+	term       uint64
+	leading    bool
+	validUntil time.Time
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+// NewElector initializes an Elector using cfg. The Elector does not campaign
+// until Start is called.
+func NewElector(cfg Config) (*Elector, error) {
+	if cfg.ID == "" {
+		return nil, ErrReplicaIDNotSet
+	}
+	if cfg.LeaseDuration == 0 {
+		cfg.LeaseDuration = DefaultLeaseDuration
+	}
+	if cfg.RenewInterval == 0 {
+		cfg.RenewInterval = cfg.LeaseDuration / 3
+	}
+
+	return &Elector{
+		cfg:  cfg,
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}, nil
+}
+
+// Start campaigns for leadership in the background until Stop is called,
+// making the first attempt immediately.
+func (e *Elector) Start() {
+	go e.campaignLoop()
+}
+
+// Stop ends the campaign, releasing the lock if held so that a standby can take
+// over without waiting out the lease.
+func (e *Elector) Stop() {
+	close(e.quit)
+	<-e.done
+}
+
+// Leadership returns the current term along with whether this replica is the
+// leader. The term is incremented each time this replica gains leadership, such
+// that callers can tell whether leadership was lost in the meantime.
+func (e *Elector) Leadership() (uint64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.term, e.leading && time.Now().Before(e.validUntil)
+}
+
+// campaignLoop attempts to acquire or renew the lock every renew interval until
+// Stop is called.
+func (e *Elector) campaignLoop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.cfg.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		e.campaign()
+
+		select {
+		case <-ticker.C:
+		case <-e.quit:
+			e.resign()
+			return
+		}
+	}
+}
+
+// campaign makes a single attempt to acquire or renew the lock.
+func (e *Elector) campaign() {
+	ctx, cancel := context.WithTimeout(
+		context.Background(), e.cfg.RenewInterval,
+	)
+	defer cancel()
+
+	// The lease is measured from before the request, as the backend may
+	// have applied it at any point before replying.
+	attemptedAt := time.Now()
+	acquired, err := e.cfg.Lock.Acquire(
+		ctx, e.cfg.ID, e.cfg.LeaseDuration,
+	)
+	if err != nil {
+		log.Warn("Unable to campaign for leadership", "id", e.cfg.ID,
+			"err", err)
+	}
+
+	e.mu.Lock()
+	wasLeading := e.leading && time.Now().Before(e.validUntil)
+	switch {
+	case acquired:
+		if !wasLeading {
+			e.term++
+		}
+		e.leading = true
+		e.validUntil = attemptedAt.Add(
+			e.cfg.LeaseDuration - e.cfg.RenewInterval,
+		)
+
+	// A failed request leaves the lease as it was, which remains valid
+	// until validUntil.
+	case err == nil:
+		e.leading = false
+	}
+	isLeading := e.leading && time.Now().Before(e.validUntil)
+	term := e.term
+	e.mu.Unlock()
+
+	if isLeading == wasLeading {
+		return
+	}
+	if isLeading {
+		log.Info("Acquired leadership", "id", e.cfg.ID, "term", term)
+	} else {
+		log.Warn("Lost leadership", "id", e.cfg.ID, "term", term)
+	}
+	if e.cfg.OnChange != nil {
+		e.cfg.OnChange(isLeading)
+	}
+}
+
+// resign releases the lock if this replica holds it.
+func (e *Elector) resign() {
+	e.mu.Lock()
+	wasLeading := e.leading
+	e.leading = false
+	e.mu.Unlock()
+
+	if !wasLeading {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(
+		context.Background(), releaseTimeout,
+	)
+	defer cancel()
+
+	if err := e.cfg.Lock.Release(ctx, e.cfg.ID); err != nil {
+		log.Error("Unable to release leadership", "id", e.cfg.ID,
+			"err", err)
+		return
+	}
+	log.Info("Released leadership", "id", e.cfg.ID)
+}
@@ -0,0 +1,151 @@
+package leader_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/leader"
+	"github.com/stretchr/testify/require"
+)
+
+// memLock is an in-memory leader.Lock, whose backend can be made unreachable.
+type memLock struct {
+	mu          sync.Mutex
+	holder      string
+	expiry      time.Time
+	unreachable bool
+}
+
+func (l *memLock) Acquire(
+	ctx context.Context, holder string, ttl time.Duration) (bool, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.unreachable {
+		return false, errors.New("unreachable")
+	}
+	now := time.Now()
+	if l.holder != "" && l.holder != holder && now.Before(l.expiry) {
+		return false, nil
+	}
+	l.holder = holder
+	l.expiry = now.Add(ttl)
+
+	return true, nil
+}
+
+func (l *memLock) Release(ctx context.Context, holder string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.holder == holder {
+		l.holder = ""
+	}
+	return nil
+}
+
+// setUnreachable makes every subsequent request fail if unreachable is true.
+func (l *memLock) setUnreachable(unreachable bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.unreachable = unreachable
+}
+
+// newTestElector initializes an elector contending for lock as id.
+func newTestElector(
+	t *testing.T, lock leader.Lock, id string) *leader.Elector {
+
+	elector, err := leader.NewElector(leader.Config{
+		Lock:          lock,
+		ID:            id,
+		LeaseDuration: 300 * time.Millisecond,
+		RenewInterval: 20 * time.Millisecond,
+	})
+	require.Nil(t, err)
+
+	return elector
+}
+
+// isLeader returns whether elector currently holds leadership.
+func isLeader(elector *leader.Elector) bool {
+	_, leading := elector.Leadership()
+	return leading
+}
+
+// TestElectorFailover asserts that only one of two replicas leads at a time,
+// and that the standby takes over as soon as the leader stops.
+func TestElectorFailover(t *testing.T) {
+	t.Parallel()
+
+	lock := &memLock{}
+	first := newTestElector(t, lock, "first")
+	first.Start()
+	require.Eventually(t, func() bool {
+		return isLeader(first)
+	}, time.Second, time.Millisecond)
+
+	second := newTestElector(t, lock, "second")
+	second.Start()
+	defer second.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	require.True(t, isLeader(first))
+	require.False(t, isLeader(second))
+
+	// Stopping the leader releases the lock, such that the standby takes
+	// over well before the lease would have expired.
+	first.Stop()
+	require.False(t, isLeader(first))
+	require.Eventually(t, func() bool {
+		return isLeader(second)
+	}, 150*time.Millisecond, time.Millisecond)
+
+	term, _ := second.Leadership()
+	require.Equal(t, uint64(1), term)
+}
+
+// TestElectorStepsDown asserts that a leader unable to renew its lease steps
+// down before the lease expires, and begins a new term once it is able to
+// acquire the lock again.
+func TestElectorStepsDown(t *testing.T) {
+	t.Parallel()
+
+	lock := &memLock{}
+	elector := newTestElector(t, lock, "replica")
+	elector.Start()
+	defer elector.Stop()
+
+	require.Eventually(t, func() bool {
+		return isLeader(elector)
+	}, time.Second, time.Millisecond)
+
+	lock.setUnreachable(true)
+	unreachableAt := time.Now()
+	require.Eventually(t, func() bool {
+		return !isLeader(elector)
+	}, time.Second, time.Millisecond)
+	require.Less(t, int64(time.Since(unreachableAt)),
+		int64(300*time.Millisecond))
+
+	lock.setUnreachable(false)
+	require.Eventually(t, func() bool {
+		return isLeader(elector)
+	}, time.Second, time.Millisecond)
+
+	term, _ := elector.Leadership()
+	require.Equal(t, uint64(2), term)
+}
+
+// TestNewElectorRequiresID asserts that an elector cannot be initialized
+// without a replica ID.
+func TestNewElectorRequiresID(t *testing.T) {
+	t.Parallel()
+
+	_, err := leader.NewElector(leader.Config{Lock: &memLock{}})
+	require.Equal(t, leader.ErrReplicaIDNotSet, err)
+}
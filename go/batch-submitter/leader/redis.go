@@ -0,0 +1,343 @@
+package leader
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultRedisTimeout bounds each request made to Redis whose context
+	// carries no earlier deadline.
+	defaultRedisTimeout = 5 * time.Second
+
+	// acquireScript atomically takes the lock at KEYS[1] on behalf of the
+	// holder ARGV[1] for ARGV[2] milliseconds if it is free, or extends the
+	// lease if the holder already holds it.
+	acquireScript = `
+local holder = redis.call("GET", KEYS[1])
+if holder == ARGV[1] then
+	redis.call("PEXPIRE", KEYS[1], ARGV[2])
+	return 1
+end
+if not holder then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+	return 1
+end
+return 0`
+
+	// releaseScript atomically deletes the lock at KEYS[1] if it is held
+	// by the holder ARGV[1].
+	releaseScript = `
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`
+)
+
+// ErrUnsupportedLockURL signals that a lock backend was configured with a URL
+// whose scheme is not supported.
+var ErrUnsupportedLockURL = errors.New("lock url must use the redis or " +
+	"rediss scheme")
+
+// ErrUnexpectedRedisReply signals a reply of a type the issued command never
+// returns, e.g. from a proxy or an incompatible server.
+var ErrUnexpectedRedisReply = errors.New("unexpected redis reply")
+
+// redisError is an error reply returned by Redis.
+type redisError string
+
+// Error returns the error message sent by Redis.
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// RedisLock is a Lock stored under a single key in Redis. The lock is taken and
+// renewed atomically by a script, such that only the holder can extend or
+// release its lease.
+type RedisLock struct {
+	addr     string
+	useTLS   bool
+	username string
+	password string
+	db       int
+	key      string
+
+	mu   sync.Mutex
+	conn net.Conn
+	rd   *bufio.Reader
+}
+
+// NewRedisLock initializes a RedisLock stored under key in the Redis server at
+// rawURL, formatted as redis://[[user]:password@]host[:port][/db], or rediss://
+// to connect over TLS. The connection is only dialed upon the first request.
+func NewRedisLock(rawURL, key string) (*RedisLock, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "redis" && u.Scheme != "rediss" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedLockURL, u.Scheme)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "6379")
+	}
+
+	var db int
+	if path := strings.TrimPrefix(u.Path, "/"); path != "" {
+		db, err = strconv.Atoi(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis db: %w", err)
+		}
+	}
+
+	lock := &RedisLock{
+		addr:   addr,
+		useTLS: u.Scheme == "rediss",
+		db:     db,
+		key:    key,
+	}
+	if u.User != nil {
+		lock.username = u.User.Username()
+		lock.password, _ = u.User.Password()
+	}
+
+	return lock, nil
+}
+
+// Acquire takes the lock on behalf of holder for ttl, or extends its lease if
+// holder already holds it.
+func (l *RedisLock) Acquire(
+	ctx context.Context,
+	holder string,
+	ttl time.Duration,
+) (bool, error) {
+
+	reply, err := l.do(
+		ctx, "EVAL", acquireScript, "1", l.key, holder,
+		strconv.FormatInt(ttl.Milliseconds(), 10),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	acquired, ok := reply.(int64)
+	if !ok {
+		return false, fmt.Errorf("%w: %v", ErrUnexpectedRedisReply, reply)
+	}
+
+	return acquired == 1, nil
+}
+
+// Release releases the lock if it is held by holder.
+func (l *RedisLock) Release(ctx context.Context, holder string) error {
+	_, err := l.do(ctx, "EVAL", releaseScript, "1", l.key, holder)
+	return err
+}
+
+// Close closes the connection to Redis, if open.
+func (l *RedisLock) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.closeConn()
+}
+
+// do sends a command to Redis and returns its reply, dialing the connection if
+// needed. The connection is closed upon any failure other than an error reply,
+// and dialed again by the next request.
+func (l *RedisLock) do(ctx context.Context, args ...string) (interface{},
+	error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultRedisTimeout)
+	}
+
+	if l.conn == nil {
+		if err := l.dial(ctx, deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.conn.SetDeadline(deadline); err != nil {
+		_ = l.closeConn()
+		return nil, err
+	}
+	reply, err := l.roundTrip(args...)
+	if err != nil {
+		_ = l.closeConn()
+		return nil, err
+	}
+	if replyErr, ok := reply.(redisError); ok {
+		return nil, replyErr
+	}
+
+	return reply, nil
+}
+
+// dial connects to Redis, authenticating and selecting the configured db.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *RedisLock) dial(ctx context.Context, deadline time.Time) error {
+	dialer := &net.Dialer{Deadline: deadline}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if l.useTLS {
+		host, _, _ := net.SplitHostPort(l.addr)
+		conn, err = (&tls.Dialer{
+			NetDialer: dialer,
+			Config:    &tls.Config{ServerName: host},
+		}).DialContext(ctx, "tcp", l.addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", l.addr)
+	}
+	if err != nil {
+		return err
+	}
+	l.conn = conn
+	l.rd = bufio.NewReader(conn)
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = l.closeConn()
+		return err
+	}
+
+	var setup [][]string
+	switch {
+	case l.username != "":
+		setup = append(setup, []string{"AUTH", l.username, l.password})
+	case l.password != "":
+		setup = append(setup, []string{"AUTH", l.password})
+	}
+	if l.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(l.db)})
+	}
+	for _, args := range setup {
+		reply, err := l.roundTrip(args...)
+		if err == nil {
+			if replyErr, ok := reply.(redisError); ok {
+				err = replyErr
+			}
+		}
+		if err != nil {
+			_ = l.closeConn()
+			return err
+		}
+	}
+
+	return nil
+}
+
+// closeConn closes the connection to Redis, if open.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *RedisLock) closeConn() error {
+	if l.conn == nil {
+		return nil
+	}
+
+	err := l.conn.Close()
+	l.conn = nil
+	l.rd = nil
+
+	return err
+}
+
+// roundTrip writes a command to the connection and reads its reply.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *RedisLock) roundTrip(args ...string) (interface{}, error) {
+	if _, err := l.conn.Write(encodeCommand(args...)); err != nil {
+		return nil, err
+	}
+
+	return readReply(l.rd)
+}
+
+// encodeCommand encodes a command as a RESP array of bulk strings.
+func encodeCommand(args ...string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+
+	return []byte(b.String())
+}
+
+// readReply reads a single RESP reply, returned as a string for simple
+// strings, a redisError for errors, an int64 for integers, a []byte for bulk
+// strings, an []interface{} for arrays, and nil for null replies.
+func readReply(rd *bufio.Reader) (interface{}, error) {
+	line, err := rd.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed redis reply: %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+
+	case '-':
+		return redisError(payload), nil
+
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, data); err != nil {
+			return nil, err
+		}
+		return data[:size], nil
+
+	case '*':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, err
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		elems := make([]interface{}, size)
+		for i := range elems {
+			elems[i], err = readReply(rd)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return elems, nil
+
+	default:
+		return nil, fmt.Errorf("unknown redis reply type: %q", kind)
+	}
+}
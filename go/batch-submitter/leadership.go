@@ -0,0 +1,77 @@
+package batchsubmitter
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrNotLeader signals that a batch tx was not published as this replica no
+// longer holds leadership.
+var ErrNotLeader = errors.New("replica is not the leader")
+
+// LeaderElector determines whether this replica may submit batches in high
+// availability mode, in which replicas sharing a wallet stand by while another
+// holds leadership.
+type LeaderElector interface {
+	// Leadership returns the current term along with whether this
+	// replica is the leader. The term changes each time this replica
+	// gains leadership.
+	Leadership() (uint64, bool)
+}
+
+// isLeader returns true unless another replica holds leadership. Without a
+// configured Elector, the service is always the leader.
+func (s *Service) isLeader() bool {
+	if s.cfg.Elector == nil {
+		return true
+	}
+
+	_, leading := s.cfg.Elector.Leadership()
+	return leading
+}
+
+// checkLeadership returns true if the service may submit in the current cycle,
+// or records the cycle as skipped if it stands by. Each time leadership is
+// gained, the nonce and pending tx state are recovered first, as the previous
+// leader may have published txs this replica has never seen.
+//
+// NOTE: This method MUST only be called from the event loop.
+func (s *Service) checkLeadership(trace *CycleTrace) bool {
+	if s.cfg.Elector == nil {
+		return true
+	}
+
+	name := s.cfg.Driver.Name()
+
+	term, leading := s.cfg.Elector.Leadership()
+	if !leading {
+		s.metrics.IsLeader.Set(0)
+		if s.leaderTerm != 0 {
+			log.Warn(name+" lost leadership, standing by",
+				"term", s.leaderTerm)
+			s.leaderTerm = 0
+		}
+		trace.Skipped(SkipStandby,
+			"standing by, another replica holds leadership")
+		return false
+	}
+	s.metrics.IsLeader.Set(1)
+
+	if term != s.leaderTerm {
+		log.Info(name+" gained leadership, recovering submission "+
+			"state", "term", term)
+		s.metrics.LeadershipAcquired.Inc()
+		s.health.LeadershipGained()
+
+		// Discard the nonce tracked during any previous term, and
+		// resolve the txs left pending by the previous leader before
+		// building on top of them.
+		s.nonceMgr.Reset()
+		s.recoverSubmissionState(true)
+		s.leaderTerm = term
+	}
+	trace.Step("leadership", "term=%d", term)
+
+	return true
+}
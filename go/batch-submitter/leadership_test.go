@@ -0,0 +1,80 @@
+package batchsubmitter
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeElector is a LeaderElector whose leadership is set by the test.
+type fakeElector struct {
+	mu      sync.Mutex
+	term    uint64
+	leading bool
+}
+
+func (e *fakeElector) Leadership() (uint64, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.term, e.leading
+}
+
+// set updates the leadership reported by the elector.
+func (e *fakeElector) set(term uint64, leading bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.term, e.leading = term, leading
+}
+
+// TestServiceCheckLeadership asserts that cycles are skipped while standing by,
+// that the submission state is recovered once per term, and that a deposed
+// leader publishes no further batch txs.
+func TestServiceCheckLeadership(t *testing.T) {
+	t.Parallel()
+
+	name := "TestServiceCheckLeadership"
+	elector := &fakeElector{}
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver:  namedDriver{name: name},
+			Elector: elector,
+			DryRun:  true,
+		},
+		metrics: metrics.NewMetrics(name),
+		nonceMgr: noncemgr.NewManager(
+			name, common.Address{}, nil, nil,
+		),
+		health: newHealthState(),
+	}
+
+	trace := newCycleTrace()
+	require.False(t, s.checkLeadership(trace))
+	require.Equal(t, CycleSkipped, trace.Outcome)
+	require.False(t, s.isLeader())
+
+	sub := newBatchSubmission(big.NewInt(0), big.NewInt(1), 0, nil)
+	_, err := s.sendBatchTx(context.Background(), sub, big.NewInt(1))
+	require.Equal(t, ErrNotLeader, err)
+
+	elector.set(1, true)
+	require.True(t, s.checkLeadership(newCycleTrace()))
+	require.Equal(t, uint64(1), s.leaderTerm)
+	require.True(t, s.isLeader())
+
+	// Regaining leadership in a later term recovers the state again.
+	elector.set(1, false)
+	require.False(t, s.checkLeadership(newCycleTrace()))
+	require.Zero(t, s.leaderTerm)
+
+	elector.set(2, true)
+	require.True(t, s.checkLeadership(newCycleTrace()))
+	require.Equal(t, uint64(2), s.leaderTerm)
+}
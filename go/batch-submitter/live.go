@@ -0,0 +1,214 @@
+package batchsubmitter
+
+import (
+	// Imported for embedding the status page.
+	_ "embed"
+	"net/http"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// livePath is the path at which the live status page is served by the
+	// metrics server.
+	livePath = "/live"
+
+	// liveStreamPath is the path at which the live status is streamed to
+	// the status page over a WebSocket.
+	liveStreamPath = "/live/stream"
+
+	// liveStatusInterval is the interval at which the live status is
+	// pushed to each connected client.
+	liveStatusInterval = 2 * time.Second
+
+	// liveWriteTimeout bounds each write of the live status to a client.
+	liveWriteTimeout = 10 * time.Second
+)
+
+// livePage is the status page served at livePath, rendering the live status
+// streamed from liveStreamPath.
+//
+//go:embed live.html
+var livePage []byte
+
+// BlockRangeLag is the range of L2 blocks a service has yet to submit, as of
+// the last cycle to observe it.
+type BlockRangeLag struct {
+	// Start and End bound the blocks awaiting submission, [Start, End).
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	// Blocks is the number of blocks awaiting submission.
+	Blocks uint64 `json:"blocks"`
+
+	// ObservedAt is the time at which the range was observed.
+	ObservedAt time.Time `json:"observed_at"`
+}
+
+// ServiceLiveStatus is the live status of a single service, as pushed to the
+// status page. It is derived from the service's local state alone, such that
+// pushing it to every client never issues any RPCs.
+type ServiceLiveStatus struct {
+	// Name identifies the service.
+	Name string `json:"name"`
+
+	// Paused is true while submission is paused by an operator.
+	Paused bool `json:"paused"`
+
+	// Standby is true while another replica holds leadership in high
+	// availability mode.
+	Standby bool `json:"standby"`
+
+	// CurrentCycle is a snapshot of the trace of the cycle in progress, if
+	// any.
+	CurrentCycle *CycleTrace `json:"current_cycle,omitempty"`
+
+	// LastCycle is the trace of the most recently concluded cycle, if any.
+	LastCycle *CycleTrace `json:"last_cycle,omitempty"`
+
+	// Lag is the range of L2 blocks awaiting submission, if observed.
+	Lag *BlockRangeLag `json:"lag,omitempty"`
+
+	// InFlight are the batch txs awaiting confirmation in pipelined mode.
+	InFlight []PendingBatch `json:"in_flight"`
+
+	// PendingSubmissions and RecentSubmissions are the pending and most
+	// recently recorded batch txs of the configured StateStore, if any.
+	PendingSubmissions []*queue.SubmissionRecord `json:"pending_submissions,omitempty"`
+	RecentSubmissions  []*queue.SubmissionRecord `json:"recent_submissions,omitempty"`
+}
+
+// LiveStatus is the message pushed to the status page.
+type LiveStatus struct {
+	Time     time.Time           `json:"time"`
+	Services []ServiceLiveStatus `json:"services"`
+}
+
+// LiveStatus returns the live status of the service.
+func (s *Service) LiveStatus() ServiceLiveStatus {
+	status := ServiceLiveStatus{
+		Name:         s.cfg.Driver.Name(),
+		Paused:       s.Paused(),
+		Standby:      !s.isLeader(),
+		CurrentCycle: s.traces.Current(),
+		Lag:          s.health.lag(),
+		InFlight:     []PendingBatch{},
+
+		RecentSubmissions: s.recentSubmissions(),
+	}
+	if recent := s.traces.Recent(); len(recent) > 0 {
+		status.LastCycle = recent[0]
+	}
+	if s.pipeline != nil {
+		for _, batch := range s.pipeline.Batches() {
+			status.InFlight = append(status.InFlight, PendingBatch{
+				Start: batch.start,
+				End:   batch.end,
+				Nonce: batch.nonce,
+			})
+		}
+	}
+	if s.cfg.StateStore != nil {
+		pending, err := s.cfg.StateStore.Pending()
+		if err != nil {
+			log.Error(s.cfg.Driver.Name()+" unable to get pending "+
+				"submissions", "err", err)
+		}
+		status.PendingSubmissions = pending
+	}
+
+	return status
+}
+
+// LiveStatus returns the live status of all registered services, ordered by
+// name.
+func (r *statusRegistry) LiveStatus() LiveStatus {
+	services := r.sorted()
+
+	statuses := make([]ServiceLiveStatus, 0, len(services))
+	for _, s := range services {
+		statuses = append(statuses, s.LiveStatus())
+	}
+
+	return LiveStatus{
+		Time:     time.Now(),
+		Services: statuses,
+	}
+}
+
+// liveStreamHandler streams the live status of every registered service over
+// a WebSocket, pushing it upon connecting and every interval thereafter.
+type liveStreamHandler struct {
+	registry *statusRegistry
+	interval time.Duration
+	upgrader websocket.Upgrader
+}
+
+// ServeHTTP upgrades the request to a WebSocket and streams the live status
+// until the client disconnects. The upgrader's default origin check only
+// accepts connections made from the status page itself.
+func (h *liveStreamHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	conn, err := h.upgrader.Upgrade(w, req, nil)
+	if err != nil {
+		// The upgrader has already replied with an error.
+		return
+	}
+	defer conn.Close()
+
+	// The stream is one-way, but the connection must still be read from
+	// to process control messages and observe the client disconnecting.
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		err := conn.SetWriteDeadline(time.Now().Add(liveWriteTimeout))
+		if err != nil {
+			return
+		}
+		if err := conn.WriteJSON(h.registry.LiveStatus()); err != nil {
+			log.Debug("Unable to write live status", "err", err)
+			return
+		}
+
+		select {
+		case <-ticker.C:
+		case <-closed:
+			return
+		}
+	}
+}
+
+// liveHandlers returns the handlers of the live status page and its stream,
+// keyed by path.
+func liveHandlers(registry *statusRegistry) map[string]http.Handler {
+	page := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(livePage)
+	})
+
+	return map[string]http.Handler{
+		livePath: page,
+		liveStreamPath: &liveStreamHandler{
+			registry: registry,
+			interval: liveStatusInterval,
+		},
+	}
+}
@@ -0,0 +1,109 @@
+package batchsubmitter
+
+import (
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+// newLiveTestServer serves the live status page and stream for a single
+// service, pushing the live status every interval.
+func newLiveTestServer(
+	t *testing.T, name string, interval time.Duration) (*httptest.Server,
+	*Service) {
+
+	registry := &statusRegistry{
+		services: make(map[string]*Service),
+	}
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver: namedDriver{name: name},
+		},
+		traces: newTraceHistory(0),
+		health: newHealthState(),
+	}
+	registry.register(s)
+
+	mux := http.NewServeMux()
+	for path, handler := range liveHandlers(registry) {
+		if stream, ok := handler.(*liveStreamHandler); ok {
+			stream.interval = interval
+		}
+		mux.Handle(path, handler)
+	}
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	return server, s
+}
+
+// TestLivePage asserts that the embedded status page is served.
+func TestLivePage(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newLiveTestServer(t, "TestLivePage", time.Minute)
+
+	resp, err := http.Get(server.URL + livePath)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+	body, err := io.ReadAll(resp.Body)
+	require.Nil(t, err)
+	require.Contains(t, string(body), liveStreamPath)
+}
+
+// TestLiveStream asserts that the live status is pushed upon connecting and
+// again every interval, reflecting the cycle in progress and the observed lag.
+func TestLiveStream(t *testing.T) {
+	t.Parallel()
+
+	server, s := newLiveTestServer(t, "TestLiveStream", 10*time.Millisecond)
+
+	trace := newCycleTrace()
+	trace.Step("balance", "%d wei", 1)
+	s.traces.Begin(trace)
+	s.health.SetBlockRange(big.NewInt(10), big.NewInt(25))
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + liveStreamPath
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	var status LiveStatus
+	require.Nil(t, conn.ReadJSON(&status))
+	require.Len(t, status.Services, 1)
+
+	service := status.Services[0]
+	require.Equal(t, "TestLiveStream", service.Name)
+	require.Nil(t, service.LastCycle)
+	require.NotNil(t, service.CurrentCycle)
+	require.Equal(t, []TraceStep{{Name: "balance", Detail: "1 wei"}},
+		service.CurrentCycle.Steps)
+	require.Equal(t, uint64(10), service.Lag.Start)
+	require.Equal(t, uint64(25), service.Lag.End)
+	require.Equal(t, uint64(15), service.Lag.Blocks)
+	require.Empty(t, service.InFlight)
+
+	// Once the cycle concludes, it is pushed as the last cycle.
+	trace.Skipped(SkipNoUpdates, "no new L2 blocks to submit")
+	s.traces.Add(trace)
+
+	require.Eventually(t, func() bool {
+		var status LiveStatus
+		require.Nil(t, conn.ReadJSON(&status))
+
+		service := status.Services[0]
+		return service.CurrentCycle == nil && service.LastCycle != nil &&
+			service.LastCycle.Outcome == CycleSkipped
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,233 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// adminLogPath is the path at which the log levels are queried and adjusted by
+// the admin server.
+const adminLogPath = "/admin/log"
+
+const (
+	// LogFormatTerminal writes logs in a human-readable format suited to an
+	// interactive terminal.
+	LogFormatTerminal = "terminal"
+
+	// LogFormatJSON writes each log as a JSON object on its own line, to be
+	// shipped to a log aggregator.
+	LogFormatJSON = "json"
+)
+
+var (
+	// ErrUnknownLogFormat signals an unsupported log format.
+	ErrUnknownLogFormat = errors.New("log-format must be one of " +
+		"terminal or json")
+
+	// ErrInvalidLogModules signals a malformed list of module log levels.
+	ErrInvalidLogModules = errors.New("log-modules must be a " +
+		"comma-separated list of pattern=level")
+
+	// defaultLogFilter filters the records of the root logger by module,
+	// and is shared by all tenants within the process.
+	defaultLogFilter = newLogFilter()
+)
+
+// LogLevels are the log levels of the process, as reported and adjusted by the
+// admin API.
+type LogLevels struct {
+	// Level is the lowest level logged by modules not matched by Modules.
+	Level string `json:"level"`
+
+	// Modules is a comma-separated list of pattern=level, logging the
+	// packages or files matched by each pattern down to its level. The
+	// patterns follow geth's vmodule syntax, e.g. txmgr=debug logs package
+	// txmgr and service.go=trace logs the file service.go.
+	//
+	// NOTE: A module is always logged down to Level, so only levels below
+	// Level have an effect.
+	Modules string `json:"modules"`
+}
+
+// logFilter filters records by the log level of the module logging them,
+// allowing the levels to be adjusted at runtime.
+//
+// NOTE: logFilter is safe for concurrent use.
+type logFilter struct {
+	mu      sync.Mutex
+	glog    *log.GlogHandler
+	levels  LogLevels
+	handler atomic.Value
+}
+
+// logHandlerBox boxes a log.Handler, as an atomic.Value requires every value
+// stored to be of the same concrete type.
+type logHandlerBox struct {
+	log.Handler
+}
+
+// newLogFilter initializes a logFilter discarding every record until it is
+// given a handler.
+func newLogFilter() *logFilter {
+	f := &logFilter{}
+	f.handler.Store(logHandlerBox{log.DiscardHandler()})
+	f.glog = log.NewGlogHandler(log.FuncHandler(func(r *log.Record) error {
+		return f.handler.Load().(logHandlerBox).Log(r)
+	}))
+
+	return f
+}
+
+// SetHandler sets the handler to which the filtered records are written.
+func (f *logFilter) SetHandler(h log.Handler) {
+	f.handler.Store(logHandlerBox{h})
+}
+
+// Levels returns the current log levels.
+func (f *logFilter) Levels() LogLevels {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.levels
+}
+
+// SetLevels adjusts the log levels. The levels are validated before any is
+// applied, such that a rejected call leaves them unchanged.
+func (f *logFilter) SetLevels(levels LogLevels) error {
+	level, err := log.LvlFromString(levels.Level)
+	if err != nil {
+		return err
+	}
+	vmodule, err := parseLogModules(levels.Modules)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err := f.glog.Vmodule(vmodule); err != nil {
+		return err
+	}
+	f.glog.Verbosity(level)
+	f.levels = levels
+
+	return nil
+}
+
+// Log writes r to the handler if its module logs at its level.
+func (f *logFilter) Log(r *log.Record) error {
+	return f.glog.Log(r)
+}
+
+// parseLogModules converts a comma-separated list of pattern=level, where the
+// level is given by name or number, into geth's vmodule syntax.
+func parseLogModules(modules string) (string, error) {
+	var rules []string
+	for _, rule := range strings.Split(modules, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "" {
+			continue
+		}
+
+		parts := strings.Split(rule, "=")
+		if len(parts) != 2 {
+			return "", ErrInvalidLogModules
+		}
+		pattern := strings.TrimSpace(parts[0])
+		levelStr := strings.TrimSpace(parts[1])
+		if pattern == "" {
+			return "", ErrInvalidLogModules
+		}
+
+		level, err := log.LvlFromString(levelStr)
+		if err != nil {
+			n, err := strconv.Atoi(levelStr)
+			if err != nil || n < int(log.LvlCrit) ||
+				n > int(log.LvlTrace) {
+
+				return "", ErrInvalidLogModules
+			}
+			level = log.Lvl(n)
+		}
+
+		rules = append(rules, pattern+"="+strconv.Itoa(int(level)))
+	}
+
+	return strings.Join(rules, ","), nil
+}
+
+// newLogHandler returns a handler writing records to wr in the given format. If
+// sentryEnable is true, errors are also forwarded to Sentry.
+func newLogHandler(
+	wr io.Writer,
+	format string,
+	sentryEnable bool,
+) (log.Handler, error) {
+
+	var fmtr log.Format
+	switch format {
+	case LogFormatTerminal:
+		fmtr = log.TerminalFormat(true)
+	case LogFormatJSON:
+		fmtr = log.JSONFormat()
+	default:
+		return nil, ErrUnknownLogFormat
+	}
+
+	if sentryEnable {
+		return SentryStreamHandler(wr, fmtr), nil
+	}
+
+	return log.StreamHandler(wr, fmtr), nil
+}
+
+// logLevelsHandler serves the log levels of a logFilter.
+type logLevelsHandler struct {
+	filter *logFilter
+}
+
+// ServeHTTP returns the current log levels as JSON. For POST requests, the
+// levels given by the level and modules form values are applied beforehand,
+// leaving any that are absent unchanged.
+func (h logLevelsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if err := req.ParseForm(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		levels := h.filter.Levels()
+		if v := req.Form.Get("level"); v != "" {
+			levels.Level = v
+		}
+		if _, ok := req.Form["modules"]; ok {
+			levels.Modules = req.Form.Get("modules")
+		}
+
+		if err := h.filter.SetLevels(levels); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		log.Info("Log levels adjusted", "level", levels.Level,
+			"modules", levels.Modules)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(h.filter.Levels()); err != nil {
+		log.Error("Unable to write log levels response", "err", err)
+	}
+}
@@ -0,0 +1,124 @@
+package batchsubmitter
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseLogModules asserts that module levels are converted to geth's
+// vmodule syntax, accepting levels by name or number.
+func TestParseLogModules(t *testing.T) {
+	t.Parallel()
+
+	vmodule, err := parseLogModules(" txmgr=debug, service.go=5,")
+	require.Nil(t, err)
+	require.Equal(t, "txmgr=4,service.go=5", vmodule)
+
+	for _, modules := range []string{
+		"txmgr", "txmgr=verbose", "=debug", "txmgr=6", "a=b=c",
+	} {
+		_, err := parseLogModules(modules)
+		require.Equal(t, ErrInvalidLogModules, err, modules)
+	}
+}
+
+// TestLogFilterModules asserts that a module matched by a pattern is logged
+// below the level of the other modules, and that rejected levels leave the
+// filter unchanged.
+func TestLogFilterModules(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	filter := newLogFilter()
+	filter.SetHandler(log.StreamHandler(&buf, log.JSONFormat()))
+	logger := log.New()
+	logger.SetHandler(filter)
+
+	require.Nil(t, filter.SetLevels(LogLevels{Level: "info"}))
+	logger.Debug("hidden")
+	require.Empty(t, buf.String())
+
+	levels := LogLevels{
+		Level:   "info",
+		Modules: "logging_test.go=debug",
+	}
+	require.Nil(t, filter.SetLevels(levels))
+	logger.Debug("shown")
+	require.Contains(t, buf.String(), "shown")
+
+	require.NotNil(t, filter.SetLevels(LogLevels{Level: "verbose"}))
+	require.Equal(t, levels, filter.Levels())
+}
+
+// TestNewLogHandlerJSON asserts that the json format writes each record as a
+// JSON object.
+func TestNewLogHandlerJSON(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	handler, err := newLogHandler(&buf, LogFormatJSON, false)
+	require.Nil(t, err)
+	logger := log.New()
+	logger.SetHandler(handler)
+
+	logger.Info("batch tx published", "nonce", 7)
+
+	var record map[string]interface{}
+	require.Nil(t, json.Unmarshal(buf.Bytes(), &record))
+	require.Equal(t, "batch tx published", record["msg"])
+	require.Equal(t, float64(7), record["nonce"])
+
+	_, err = newLogHandler(&buf, "xml", false)
+	require.Equal(t, ErrUnknownLogFormat, err)
+}
+
+// TestAdminLogLevels asserts that the admin API reports and adjusts the log
+// levels, leaving absent levels unchanged.
+func TestAdminLogLevels(t *testing.T) {
+	t.Parallel()
+
+	filter := newLogFilter()
+	require.Nil(t, filter.SetLevels(LogLevels{Level: "info"}))
+	server := httptest.NewServer(logLevelsHandler{filter: filter})
+	t.Cleanup(server.Close)
+
+	post := func(form url.Values) (int, LogLevels) {
+		resp, err := http.Post(
+			server.URL, "application/x-www-form-urlencoded",
+			strings.NewReader(form.Encode()),
+		)
+		require.Nil(t, err)
+		defer resp.Body.Close()
+
+		var levels LogLevels
+		if resp.StatusCode == http.StatusOK {
+			require.Nil(t, json.NewDecoder(resp.Body).Decode(&levels))
+		}
+		return resp.StatusCode, levels
+	}
+
+	code, levels := post(url.Values{"modules": {"txmgr=debug"}})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, LogLevels{Level: "info", Modules: "txmgr=debug"}, levels)
+
+	code, levels = post(url.Values{"level": {"warn"}})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, LogLevels{Level: "warn", Modules: "txmgr=debug"}, levels)
+
+	code, _ = post(url.Values{"level": {"verbose"}})
+	require.Equal(t, http.StatusBadRequest, code)
+
+	resp, err := http.Get(server.URL)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&levels))
+	require.Equal(t, LogLevels{Level: "warn", Modules: "txmgr=debug"}, levels)
+}
@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var (
+	// serviceLabelMu guards serviceLabel.
+	serviceLabelMu sync.Mutex
+
+	// serviceLabel is the label identifying the service of the metrics
+	// registered by NewMetrics, or empty if services are identified by
+	// prefixing metric names.
+	serviceLabel string
+)
+
+// UseServiceLabel sets the label identifying the service of the metrics
+// registered by subsequent calls to NewMetrics, e.g. "service". Unlike the
+// default prefix, the label allows a single dashboard to chart a metric across
+// every service and tenant. An empty label restores prefixing.
+//
+// NOTE: This method MUST be called before any service registers its metrics,
+// since changing how a metric is identified midway would split its series.
+func UseServiceLabel(label string) {
+	serviceLabelMu.Lock()
+	defer serviceLabelMu.Unlock()
+
+	serviceLabel = label
+}
+
+// ServiceLabel returns the label set by UseServiceLabel, if any.
+func ServiceLabel() string {
+	serviceLabelMu.Lock()
+	defer serviceLabelMu.Unlock()
+
+	return serviceLabel
+}
+
+// labeledGatherer is a prometheus.Gatherer prefixing the name of every metric
+// of a gatherer with a namespace and attaching a set of constant labels.
+type labeledGatherer struct {
+	gatherer  prometheus.Gatherer
+	namespace string
+	labels    []*dto.LabelPair
+}
+
+// NewGatherer wraps gatherer such that the name of every gathered metric is
+// prefixed with namespace, if non-empty, and every series carries the constant
+// labels, e.g. the network, chain id and instance of the submitter. Labels
+// already set on a series take precedence. Unlike the namespace and constant
+// labels of individual metrics, these also apply to metrics registered by
+// dependencies, such that every exported series is identified consistently.
+func NewGatherer(
+	gatherer prometheus.Gatherer,
+	namespace string,
+	labels map[string]string,
+) prometheus.Gatherer {
+
+	if namespace == "" && len(labels) == 0 {
+		return gatherer
+	}
+
+	g := &labeledGatherer{
+		gatherer:  gatherer,
+		namespace: namespace,
+	}
+	for name, value := range labels {
+		name, value := name, value
+		g.labels = append(g.labels, &dto.LabelPair{
+			Name:  &name,
+			Value: &value,
+		})
+	}
+
+	return g
+}
+
+// Gather gathers the metrics of the wrapped gatherer, renamed and labeled.
+func (g *labeledGatherer) Gather() ([]*dto.MetricFamily, error) {
+	families, err := g.gatherer.Gather()
+	for _, family := range families {
+		if g.namespace != "" {
+			name := g.namespace + "_" + family.GetName()
+			family.Name = &name
+		}
+		for _, metric := range family.Metric {
+			metric.Label = g.addLabels(metric.Label)
+		}
+	}
+
+	return families, err
+}
+
+// addLabels returns pairs extended with the constant labels not already among
+// them, sorted by name as required by the exposition formats.
+func (g *labeledGatherer) addLabels(pairs []*dto.LabelPair) []*dto.LabelPair {
+	set := make(map[string]struct{}, len(pairs))
+	for _, pair := range pairs {
+		set[pair.GetName()] = struct{}{}
+	}
+	for _, pair := range g.labels {
+		if _, ok := set[pair.GetName()]; !ok {
+			pairs = append(pairs, pair)
+		}
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i].GetName() < pairs[j].GetName()
+	})
+
+	return pairs
+}
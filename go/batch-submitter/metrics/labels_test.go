@@ -0,0 +1,83 @@
+package metrics_test
+
+import (
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewGatherer asserts that gathered metrics are prefixed with the namespace
+// and carry the constant labels, without overriding their own labels.
+func TestNewGatherer(t *testing.T) {
+	t.Parallel()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "batches_submitted",
+	}, []string{"network"})
+	require.Nil(t, registry.Register(counter))
+	counter.WithLabelValues("goerli").Inc()
+
+	gatherer := metrics.NewGatherer(registry, "optimism", map[string]string{
+		"network":  "mainnet",
+		"chain_id": "10",
+	})
+	families, err := gatherer.Gather()
+	require.Nil(t, err)
+	require.Len(t, families, 1)
+	require.Equal(t, "optimism_batches_submitted", families[0].GetName())
+
+	labels := make(map[string]string)
+	var names []string
+	for _, pair := range families[0].GetMetric()[0].GetLabel() {
+		labels[pair.GetName()] = pair.GetValue()
+		names = append(names, pair.GetName())
+	}
+	require.Equal(t, map[string]string{
+		"network":  "goerli",
+		"chain_id": "10",
+	}, labels)
+	require.Equal(t, []string{"chain_id", "network"}, names)
+
+	require.Equal(t, prometheus.Gatherer(registry),
+		metrics.NewGatherer(registry, "", nil))
+}
+
+// TestUseServiceLabel asserts that services registered with a service label
+// share metric names, and are distinguished by the label instead.
+func TestUseServiceLabel(t *testing.T) {
+	metrics.UseServiceLabel("service")
+	defer metrics.UseServiceLabel("")
+
+	a := metrics.NewMetrics("TestUseServiceLabelA")
+	b := metrics.NewMetrics("TestUseServiceLabelB")
+	a.BatchesSubmitted.Add(2)
+	b.BatchesSubmitted.Inc()
+
+	require.Equal(t, 2.0, testutil.ToFloat64(a.BatchesSubmitted))
+	require.Equal(t, 1.0, testutil.ToFloat64(b.BatchesSubmitted))
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.Nil(t, err)
+	services := make(map[string]float64)
+	for _, family := range families {
+		if family.GetName() != "batches_submitted" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, pair := range metric.GetLabel() {
+				if pair.GetName() == "service" {
+					services[pair.GetValue()] =
+						metric.GetCounter().GetValue()
+				}
+			}
+		}
+	}
+	require.Equal(t, map[string]float64{
+		"TestUseServiceLabelA": 2,
+		"TestUseServiceLabelB": 1,
+	}, services)
+}
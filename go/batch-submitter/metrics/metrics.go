@@ -5,6 +5,16 @@ import (
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
+// utilizationBuckets are the histogram buckets used for metrics expressed as a
+// fraction of a configured limit.
+var utilizationBuckets = []float64{
+	0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 1.0,
+}
+
+// phaseDurationBuckets are the histogram buckets, in milliseconds, used for the
+// duration of each phase of a batch submission.
+var phaseDurationBuckets = prometheus.ExponentialBuckets(10, 2, 17)
+
 type Metrics struct {
 	// ETHBalance tracks the amount of ETH in the submitter's account.
 	ETHBalance prometheus.Gauge
@@ -32,25 +42,265 @@ type Metrics struct {
 	// transaction.
 	BatchTxBuildTime prometheus.Gauge
 
-	// BatchConfirmationTime tracks the duration it takes to confirm a batch
-	// transaction.
-	BatchConfirmationTime prometheus.Gauge
+	// BatchBuildTime tracks the duration of building each batch from L2
+	// blocks.
+	BatchBuildTime prometheus.Histogram
+
+	// BatchSignTime tracks the duration of constructing and signing each
+	// batch transaction.
+	BatchSignTime prometheus.Histogram
+
+	// BatchFirstBroadcastTime tracks the duration from the start of a
+	// submission until its first batch transaction is published.
+	BatchFirstBroadcastTime prometheus.Histogram
+
+	// BatchMempoolWaitTime tracks the duration from the first publication
+	// of a batch transaction until it is included in a block.
+	BatchMempoolWaitTime prometheus.Histogram
+
+	// BatchConfirmationDepthWaitTime tracks the duration from the inclusion
+	// of a batch transaction until it reaches the required number of
+	// confirmations.
+	BatchConfirmationDepthWaitTime prometheus.Histogram
+
+	// BlockCacheHits tracks the number of L2 blocks served from the block
+	// cache rather than fetched from the L2 backend.
+	BlockCacheHits prometheus.Counter
+
+	// BlockCacheMisses tracks the number of L2 blocks that were not found in
+	// the block cache and had to be fetched from the L2 backend.
+	BlockCacheMisses prometheus.Counter
+
+	// ContextDriftViolations tracks the number of batches rejected because
+	// a batch context exceeded the maximum drift from the L1 timestamp.
+	ContextDriftViolations prometheus.Counter
+
+	// BlockFetchThroughput tracks the number of L2 blocks fetched per second
+	// while building the most recent batch.
+	BlockFetchThroughput prometheus.Gauge
+
+	// FetchWorkers tracks the current number of concurrent L2 block
+	// requests permitted while building a batch.
+	FetchWorkers prometheus.Gauge
+
+	// MarketGasPrice tracks the L1 backend's suggested gas price in gwei,
+	// as observed when deciding whether to defer submission.
+	MarketGasPrice prometheus.Gauge
+
+	// DeferredSubmissions tracks the number of cycles in which submission
+	// was deferred because the market gas price exceeded the max.
+	DeferredSubmissions prometheus.Counter
+
+	// BatchSizeHeadroom tracks the number of bytes by which the most recent
+	// batch fell short of the maximum tx size.
+	BatchSizeHeadroom prometheus.Gauge
+
+	// BatchSizeUtilization tracks the fraction of the maximum tx size used
+	// by each batch.
+	BatchSizeUtilization prometheus.Histogram
+
+	// GasLimitHeadroom tracks the amount of gas by which the most recent
+	// batch's gas limit fell short of the max gas limit.
+	GasLimitHeadroom prometheus.Gauge
+
+	// GasLimitUtilization tracks the fraction of the max gas limit used by
+	// each batch's gas limit.
+	GasLimitUtilization prometheus.Histogram
+
+	// PreflightFailures tracks the number of batch txs that reverted when
+	// simulated prior to being published.
+	PreflightFailures prometheus.Counter
+
+	// GasEstimate tracks the node's raw gas estimate for the most recent
+	// batch transaction.
+	GasEstimate prometheus.Gauge
+
+	// GasLimit tracks the gas limit, after applying the safety margin, used
+	// for the most recent batch transaction.
+	GasLimit prometheus.Gauge
+
+	// StateBatchDependencyViolations tracks the number of times state roots
+	// were found proposed, or about to be proposed, for L2 heights whose
+	// batch data has not confirmed.
+	StateBatchDependencyViolations prometheus.Counter
+
+	// ReorgsDetected tracks the number of L1 reorgs that dropped the block
+	// including a batch tx before it reached the required confirmation
+	// depth.
+	ReorgsDetected prometheus.Counter
+
+	// BatchesResubmitted tracks the number of batches resubmitted after
+	// their batch tx was reorged out.
+	BatchesResubmitted prometheus.Counter
+
+	// L2ChainDiscontinuities tracks the number of times the L2 blocks
+	// fetched for a batch did not form a single chain.
+	L2ChainDiscontinuities prometheus.Counter
+
+	// RangesQuarantined tracks the number of ranges quarantined after
+	// exhausting their retry budget.
+	RangesQuarantined prometheus.Counter
+
+	// QuarantinedRangeStart is the start height of the quarantined range,
+	// or zero if none is quarantined.
+	QuarantinedRangeStart prometheus.Gauge
+
+	// FeeCeilingExceeded tracks the number of batch txs not published as
+	// their fee would exceed the batch's economic value.
+	FeeCeilingExceeded prometheus.Counter
+
+	// UneconomicBatchesDeferred tracks the number of batches deferred to
+	// be rebuilt larger after exceeding their fee ceiling.
+	UneconomicBatchesDeferred prometheus.Counter
+
+	// SelfTestFailed is one if the startup self-test failed, such that
+	// the service is running degraded, or zero otherwise.
+	SelfTestFailed prometheus.Gauge
+
+	// BalanceAlertLevel is zero while the wallet balance is above every
+	// threshold, one below the warning threshold, and two below the
+	// critical threshold at which submission halts.
+	BalanceAlertLevel prometheus.Gauge
+
+	// BalanceDrainRate is the rate at which the wallet balance drained
+	// over the trailing drain window, in ETH per hour.
+	BalanceDrainRate prometheus.Gauge
+
+	// BalanceDrainBaseline is the baseline drain rate of the wallet
+	// balance, in ETH per hour.
+	BalanceDrainBaseline prometheus.Gauge
+
+	// RPCRetries counts the retries of failed RPC queries within a cycle.
+	RPCRetries prometheus.Counter
+
+	// RPCCircuitOpen is one while sustained RPC failures have tripped the
+	// circuit breaker, or zero otherwise.
+	RPCCircuitOpen prometheus.Gauge
+
+	// BatchesVerified counts the confirmed batches re-derived from L1 and
+	// compared against L2.
+	BatchesVerified prometheus.Counter
+
+	// BatchVerificationMismatches counts the confirmed batches whose
+	// calldata does not match the L2 blocks they cover. Any increase is
+	// critical, as the batch committed to L1 is incorrect.
+	BatchVerificationMismatches prometheus.Counter
+
+	// BatchVerificationErrors counts the confirmed batches that could not
+	// be verified.
+	BatchVerificationErrors prometheus.Counter
+
+	// MemoryQuotaInUse is the number of bytes of batch data reserved
+	// against the driver's memory quota.
+	MemoryQuotaInUse prometheus.Gauge
+
+	// MemoryQuotaExhausted counts the batches cut short, or deferred, for
+	// exhausting the driver's memory quota.
+	MemoryQuotaExhausted prometheus.Counter
+
+	// RPCQuotaThrottled counts the L2 requests delayed by the driver's RPC
+	// quota.
+	RPCQuotaThrottled prometheus.Counter
+
+	// RPCQuotaWaitTime is the cumulative time in ms spent waiting for the
+	// driver's RPC quota.
+	RPCQuotaWaitTime prometheus.Counter
+
+	// RPCQuotaWaiting is the number of L2 requests currently delayed by the
+	// driver's RPC quota, non-zero while the driver is throttled.
+	RPCQuotaWaiting prometheus.Gauge
+
+	// IsLeader is one while the driver's replica holds leadership in high
+	// availability mode, and zero while it stands by.
+	IsLeader prometheus.Gauge
+
+	// LeadershipAcquired counts the times the driver's replica gained
+	// leadership in high availability mode.
+	LeadershipAcquired prometheus.Counter
+
+	// BatchCost tracks the cost in ETH of each confirmed batch, i.e. its
+	// gas used times its effective gas price.
+	BatchCost prometheus.Gauge
+
+	// BatchCostPerL2Tx tracks the cost in ETH of each confirmed batch
+	// divided by the number of L2 transactions it holds.
+	BatchCostPerL2Tx prometheus.Gauge
+
+	// TotalSpend tracks the cumulative cost in ETH of confirmed batches.
+	TotalSpend prometheus.Counter
+
+	// CyclesDeadlineExceeded counts the cycles aborted after exceeding the
+	// max cycle duration.
+	CyclesDeadlineExceeded prometheus.Counter
+
+	// WindowSpend tracks the cost in ETH of the batches confirmed within
+	// each trailing window, labeled by window.
+	WindowSpend *prometheus.GaugeVec
+
+	// CycleOutcomes counts the concluded cycles, labeled by outcome.
+	CycleOutcomes *prometheus.CounterVec
+
+	// CyclesSkipped counts the cycles that deliberately did not submit a
+	// batch, labeled by reason.
+	CyclesSkipped *prometheus.CounterVec
+
+	// L2ReorgsDetected counts the cycles halted because the L2 block at the
+	// end of the last confirmed batch was reorged out.
+	L2ReorgsDetected prometheus.Counter
+
+	// BacklogBlocks is the number of L2 blocks awaiting submission as of
+	// the last cycle.
+	BacklogBlocks prometheus.Gauge
+
+	// PriorityLaneActivations counts the cycles in which queue elements
+	// approaching their inclusion deadline preempted normal pacing.
+	PriorityLaneActivations prometheus.Counter
+
+	// FeeBumps counts the batch txs replaced at bumped fees.
+	FeeBumps prometheus.Counter
+
+	// WalletRotations counts the rotations of the submitting wallet.
+	WalletRotations prometheus.Counter
+
+	// ActiveWallet is the index of the submitting wallet among the
+	// configured wallets, where zero is the primary wallet.
+	ActiveWallet prometheus.Gauge
+
+	// MalformedBlocks counts the L2 blocks quarantined for failing to be
+	// parsed into batch elements.
+	MalformedBlocks prometheus.Counter
+
+	// QueueDeferrals counts the batches cut short or deferred for executing
+	// queue elements yet to be enqueued on L1.
+	QueueDeferrals prometheus.Counter
 }
 
+// NewMetrics registers the metrics of the service named subsystem. The service
+// is identified by prefixing each metric's name with subsystem or, if a service
+// label is configured with UseServiceLabel, by labeling each metric with it.
 func NewMetrics(subsystem string) *Metrics {
+	factory := promauto.With(prometheus.DefaultRegisterer)
+	if label := ServiceLabel(); label != "" {
+		factory = promauto.With(prometheus.WrapRegistererWith(
+			prometheus.Labels{label: subsystem},
+			prometheus.DefaultRegisterer,
+		))
+		subsystem = ""
+	}
+
 	return &Metrics{
-		ETHBalance: promauto.NewGauge(prometheus.GaugeOpts{
+		ETHBalance: factory.NewGauge(prometheus.GaugeOpts{
 			Name:      "batch_submitter_eth_balance",
 			Help:      "ETH balance of the batch submitter",
 			Subsystem: subsystem,
 		}),
-		BatchSizeInBytes: promauto.NewSummary(prometheus.SummaryOpts{
+		BatchSizeInBytes: factory.NewSummary(prometheus.SummaryOpts{
 			Name:       "batch_size_bytes",
 			Help:       "Size of batches in bytes",
 			Subsystem:  subsystem,
 			Objectives: map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001},
 		}),
-		NumElementsPerBatch: promauto.NewHistogram(prometheus.HistogramOpts{
+		NumElementsPerBatch: factory.NewHistogram(prometheus.HistogramOpts{
 			Name: "num_elements_per_batch",
 			Help: "Number of transaction in each batch",
 			Buckets: []float64{
@@ -69,34 +319,326 @@ func NewMetrics(subsystem string) *Metrics {
 			},
 			Subsystem: subsystem,
 		}),
-		SubmissionTimestamp: promauto.NewGauge(prometheus.GaugeOpts{
+		SubmissionTimestamp: factory.NewGauge(prometheus.GaugeOpts{
 			Name:      "submission_timestamp",
 			Help:      "Timestamp of last batch submitter submission",
 			Subsystem: subsystem,
 		}),
-		SubmissionGasUsed: promauto.NewGauge(prometheus.GaugeOpts{
+		SubmissionGasUsed: factory.NewGauge(prometheus.GaugeOpts{
 			Name:      "submission_gas_used",
 			Help:      "Gas used to submit each batch",
 			Subsystem: subsystem,
 		}),
-		BatchesSubmitted: promauto.NewCounter(prometheus.CounterOpts{
+		BatchesSubmitted: factory.NewCounter(prometheus.CounterOpts{
 			Name:      "batches_submitted",
 			Help:      "Count of batches submitted",
 			Subsystem: subsystem,
 		}),
-		FailedSubmissions: promauto.NewCounter(prometheus.CounterOpts{
+		FailedSubmissions: factory.NewCounter(prometheus.CounterOpts{
 			Name:      "failed_submissions",
 			Help:      "Count of failed batch submissions",
 			Subsystem: subsystem,
 		}),
-		BatchTxBuildTime: promauto.NewGauge(prometheus.GaugeOpts{
+		BatchTxBuildTime: factory.NewGauge(prometheus.GaugeOpts{
 			Name:      "batch_tx_build_time_ms",
 			Help:      "Time to construct batch transactions",
 			Subsystem: subsystem,
 		}),
-		BatchConfirmationTime: promauto.NewGauge(prometheus.GaugeOpts{
-			Name:      "batch_submitter_batch_confirmation_time_ms",
-			Help:      "Time to confirm batch transactions",
+		BatchBuildTime: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "batch_build_time_ms",
+			Help:      "Time to build batches from L2 blocks",
+			Buckets:   phaseDurationBuckets,
+			Subsystem: subsystem,
+		}),
+		BatchSignTime: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "batch_sign_time_ms",
+			Help:      "Time to construct and sign batch transactions",
+			Buckets:   phaseDurationBuckets,
+			Subsystem: subsystem,
+		}),
+		BatchFirstBroadcastTime: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "batch_first_broadcast_time_ms",
+			Help:      "Time until the first batch transaction is published",
+			Buckets:   phaseDurationBuckets,
+			Subsystem: subsystem,
+		}),
+		BatchMempoolWaitTime: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "batch_mempool_wait_time_ms",
+			Help:      "Time from first publication until batch transactions are mined",
+			Buckets:   phaseDurationBuckets,
+			Subsystem: subsystem,
+		}),
+		BatchConfirmationDepthWaitTime: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "batch_confirmation_depth_wait_time_ms",
+			Help:      "Time from inclusion until batch transactions reach the required confirmations",
+			Buckets:   phaseDurationBuckets,
+			Subsystem: subsystem,
+		}),
+		BlockCacheHits: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "block_cache_hits",
+			Help:      "Count of L2 blocks served from the block cache",
+			Subsystem: subsystem,
+		}),
+		BlockCacheMisses: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "block_cache_misses",
+			Help:      "Count of L2 blocks fetched after missing the block cache",
+			Subsystem: subsystem,
+		}),
+		ContextDriftViolations: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "context_drift_violations",
+			Help:      "Count of batches rejected for exceeding the max context drift",
+			Subsystem: subsystem,
+		}),
+		BlockFetchThroughput: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "block_fetch_blocks_per_second",
+			Help:      "L2 blocks fetched per second while building the last batch",
+			Subsystem: subsystem,
+		}),
+		FetchWorkers: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "fetch_workers",
+			Help:      "Number of concurrent L2 block requests permitted",
+			Subsystem: subsystem,
+		}),
+		MarketGasPrice: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "market_gas_price_gwei",
+			Help:      "Suggested L1 gas price in gwei",
+			Subsystem: subsystem,
+		}),
+		DeferredSubmissions: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "deferred_submissions",
+			Help:      "Count of cycles deferred due to the gas price ceiling",
+			Subsystem: subsystem,
+		}),
+		BatchSizeHeadroom: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "batch_size_headroom_bytes",
+			Help:      "Bytes remaining below the max tx size in the last batch",
+			Subsystem: subsystem,
+		}),
+		BatchSizeUtilization: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "batch_size_utilization",
+			Help:      "Fraction of the max tx size used by each batch",
+			Buckets:   utilizationBuckets,
+			Subsystem: subsystem,
+		}),
+		GasLimitHeadroom: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "gas_limit_headroom",
+			Help:      "Gas remaining below the max gas limit in the last batch",
+			Subsystem: subsystem,
+		}),
+		GasLimitUtilization: factory.NewHistogram(prometheus.HistogramOpts{
+			Name:      "gas_limit_utilization",
+			Help:      "Fraction of the max gas limit used by each batch",
+			Buckets:   utilizationBuckets,
+			Subsystem: subsystem,
+		}),
+		PreflightFailures: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "preflight_failures",
+			Help:      "Count of batch txs that reverted when simulated",
+			Subsystem: subsystem,
+		}),
+		GasEstimate: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "gas_estimate",
+			Help:      "Raw gas estimate of the last batch transaction",
+			Subsystem: subsystem,
+		}),
+		GasLimit: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "gas_limit",
+			Help:      "Padded gas limit of the last batch transaction",
+			Subsystem: subsystem,
+		}),
+		StateBatchDependencyViolations: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "state_batch_dependency_violations",
+			Help:      "Count of state batches ahead of confirmed batch data",
+			Subsystem: subsystem,
+		}),
+		ReorgsDetected: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "reorgs_detected",
+			Help:      "Count of reorgs that dropped a batch tx's block",
+			Subsystem: subsystem,
+		}),
+		BatchesResubmitted: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "batches_resubmitted",
+			Help:      "Count of batches resubmitted after being reorged out",
+			Subsystem: subsystem,
+		}),
+		L2ChainDiscontinuities: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "l2_chain_discontinuities",
+			Help:      "Count of L2 block parent hash mismatches while building batches",
+			Subsystem: subsystem,
+		}),
+		RangesQuarantined: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "ranges_quarantined",
+			Help:      "Count of ranges quarantined after repeated submission failures",
+			Subsystem: subsystem,
+		}),
+		QuarantinedRangeStart: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "quarantined_range_start",
+			Help:      "Start height of the quarantined range, or zero if none",
+			Subsystem: subsystem,
+		}),
+		FeeCeilingExceeded: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "fee_ceiling_exceeded",
+			Help:      "Count of batch txs whose fee exceeded the batch's fee ceiling",
+			Subsystem: subsystem,
+		}),
+		UneconomicBatchesDeferred: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "uneconomic_batches_deferred",
+			Help:      "Count of batches deferred after exceeding their fee ceiling",
+			Subsystem: subsystem,
+		}),
+		SelfTestFailed: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "self_test_failed",
+			Help:      "Whether the startup self-test failed",
+			Subsystem: subsystem,
+		}),
+		BalanceAlertLevel: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "balance_alert_level",
+			Help:      "Severity of the wallet balance alert, 0 ok, 1 warning, 2 critical",
+			Subsystem: subsystem,
+		}),
+		BalanceDrainRate: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "balance_drain_rate",
+			Help:      "ETH per hour drained from the wallet over the trailing drain window",
+			Subsystem: subsystem,
+		}),
+		BalanceDrainBaseline: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "balance_drain_baseline",
+			Help:      "Baseline ETH per hour drained from the wallet",
+			Subsystem: subsystem,
+		}),
+		RPCRetries: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "rpc_retries",
+			Help:      "Number of retries of failed RPC queries within a cycle",
+			Subsystem: subsystem,
+		}),
+		RPCCircuitOpen: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "rpc_circuit_open",
+			Help:      "Whether sustained RPC failures have tripped the circuit breaker",
+			Subsystem: subsystem,
+		}),
+		BatchesVerified: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "batches_verified",
+			Help:      "Count of confirmed batches verified against L2",
+			Subsystem: subsystem,
+		}),
+		BatchVerificationMismatches: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "batch_verification_mismatches",
+			Help:      "Count of confirmed batches not matching the L2 blocks they cover",
+			Subsystem: subsystem,
+		}),
+		BatchVerificationErrors: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "batch_verification_errors",
+			Help:      "Count of confirmed batches that could not be verified",
+			Subsystem: subsystem,
+		}),
+		MemoryQuotaInUse: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "memory_quota_in_use_bytes",
+			Help:      "Bytes of batch data reserved against the memory quota",
+			Subsystem: subsystem,
+		}),
+		MemoryQuotaExhausted: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "memory_quota_exhausted",
+			Help:      "Count of batches cut short or deferred by the memory quota",
+			Subsystem: subsystem,
+		}),
+		RPCQuotaThrottled: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "rpc_quota_throttled",
+			Help:      "Count of L2 requests delayed by the RPC quota",
+			Subsystem: subsystem,
+		}),
+		RPCQuotaWaitTime: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "rpc_quota_wait_time_ms",
+			Help:      "Cumulative time spent waiting for the RPC quota",
+			Subsystem: subsystem,
+		}),
+		RPCQuotaWaiting: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "rpc_quota_waiting",
+			Help:      "Number of L2 requests currently delayed by the RPC quota",
+			Subsystem: subsystem,
+		}),
+		IsLeader: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "is_leader",
+			Help:      "Whether the replica holds leadership in high availability mode",
+			Subsystem: subsystem,
+		}),
+		LeadershipAcquired: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "leadership_acquired",
+			Help:      "Count of times the replica gained leadership",
+			Subsystem: subsystem,
+		}),
+		BatchCost: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "batch_cost_eth",
+			Help:      "Cost in ETH of each confirmed batch",
+			Subsystem: subsystem,
+		}),
+		BatchCostPerL2Tx: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "batch_cost_per_l2_tx_eth",
+			Help:      "Cost in ETH of each confirmed batch per L2 transaction",
+			Subsystem: subsystem,
+		}),
+		TotalSpend: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "total_spend_eth",
+			Help:      "Cumulative cost in ETH of confirmed batches",
+			Subsystem: subsystem,
+		}),
+		CyclesDeadlineExceeded: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "cycles_deadline_exceeded",
+			Help:      "Count of cycles aborted after exceeding the max cycle duration",
+			Subsystem: subsystem,
+		}),
+		WindowSpend: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Name:      "window_spend_eth",
+			Help:      "Cost in ETH of the batches confirmed within each trailing window",
+			Subsystem: subsystem,
+		}, []string{"window"}),
+		CycleOutcomes: factory.NewCounterVec(prometheus.CounterOpts{
+			Name:      "cycle_outcomes",
+			Help:      "Count of concluded cycles by outcome",
+			Subsystem: subsystem,
+		}, []string{"outcome"}),
+		CyclesSkipped: factory.NewCounterVec(prometheus.CounterOpts{
+			Name:      "cycles_skipped",
+			Help:      "Count of cycles that did not submit a batch by reason",
+			Subsystem: subsystem,
+		}, []string{"reason"}),
+		L2ReorgsDetected: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "l2_reorgs_detected",
+			Help:      "Count of cycles halted by an L2 reorg beneath confirmed batches",
+			Subsystem: subsystem,
+		}),
+		BacklogBlocks: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "backlog_blocks",
+			Help:      "Number of L2 blocks awaiting submission",
+			Subsystem: subsystem,
+		}),
+		PriorityLaneActivations: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "priority_lane_activations",
+			Help:      "Count of cycles submitting through the priority lane",
+			Subsystem: subsystem,
+		}),
+		FeeBumps: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "fee_bumps",
+			Help:      "Count of batch txs replaced at bumped fees",
+			Subsystem: subsystem,
+		}),
+		WalletRotations: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "wallet_rotations",
+			Help:      "Count of rotations of the submitting wallet",
+			Subsystem: subsystem,
+		}),
+		ActiveWallet: factory.NewGauge(prometheus.GaugeOpts{
+			Name:      "active_wallet",
+			Help:      "Index of the submitting wallet among the configured wallets",
+			Subsystem: subsystem,
+		}),
+		MalformedBlocks: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "malformed_blocks",
+			Help:      "Count of L2 blocks quarantined for being malformed",
+			Subsystem: subsystem,
+		}),
+		QueueDeferrals: factory.NewCounter(prometheus.CounterOpts{
+			Name:      "queue_deferrals",
+			Help:      "Count of batches cut short or deferred for executing queue elements yet to be enqueued on L1",
 			Subsystem: subsystem,
 		}),
 	}
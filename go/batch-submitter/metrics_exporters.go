@@ -0,0 +1,200 @@
+package batchsubmitter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/telemetry"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// MetricsExporterPrometheus serves metrics to be scraped at /metrics.
+	MetricsExporterPrometheus = "prometheus"
+
+	// MetricsExporterStatsD pushes metrics to a StatsD or Datadog agent.
+	MetricsExporterStatsD = "statsd"
+
+	// MetricsExporterOTLP pushes metrics to an OpenTelemetry collector.
+	MetricsExporterOTLP = "otlp"
+
+	// MetricsExporterPushgateway pushes metrics to a Prometheus Pushgateway,
+	// for ephemeral deployments that cannot be scraped.
+	MetricsExporterPushgateway = "pushgateway"
+
+	// otlpServiceName identifies the batch submitter to OTLP collectors.
+	otlpServiceName = "batch-submitter"
+
+	// defaultMetricsExportInterval is the interval at which metrics are
+	// pushed if none is configured.
+	defaultMetricsExportInterval = 10 * time.Second
+
+	// defaultPushgatewayJob is the job under which metrics are pushed to
+	// the Pushgateway if none is configured.
+	defaultPushgatewayJob = "batch_submitter"
+)
+
+// metricsNameRegexp matches valid Prometheus metric namespaces and label names.
+var metricsNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// metricsExporters parses a comma-separated list of metrics exporters,
+// defaulting to prometheus if the list is empty.
+func metricsExporters(exporters string) []string {
+	var names []string
+	for _, name := range strings.Split(exporters, ",") {
+		name = strings.TrimSpace(name)
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return []string{MetricsExporterPrometheus}
+	}
+
+	return names
+}
+
+// hasMetricsExporter returns true if exporter is among the configured metrics
+// exporters.
+func hasMetricsExporter(cfg Config, exporter string) bool {
+	for _, name := range metricsExporters(cfg.MetricsExporters) {
+		if name == exporter {
+			return true
+		}
+	}
+	return false
+}
+
+// validateMetricsExporters ensures that each configured metrics exporter is
+// supported, and that any parameters it requires are set.
+func validateMetricsExporters(cfg *Config) error {
+	if cfg.MetricsExportInterval <= 0 {
+		cfg.MetricsExportInterval = defaultMetricsExportInterval
+	}
+	if cfg.PushgatewayJob == "" {
+		cfg.PushgatewayJob = defaultPushgatewayJob
+	}
+
+	for _, name := range metricsExporters(cfg.MetricsExporters) {
+		switch name {
+		case MetricsExporterPrometheus:
+
+		case MetricsExporterStatsD:
+			if cfg.StatsDAddress == "" {
+				return ErrStatsDAddressNotSet
+			}
+
+		case MetricsExporterOTLP:
+			if cfg.OTLPEndpoint == "" {
+				return ErrOTLPEndpointNotSet
+			}
+
+		case MetricsExporterPushgateway:
+			if cfg.PushgatewayURL == "" {
+				return ErrPushgatewayURLNotSet
+			}
+
+		default:
+			return fmt.Errorf("%w: %s", ErrUnknownMetricsExporter, name)
+		}
+	}
+
+	for _, name := range []string{
+		cfg.MetricsNamespace, cfg.MetricsServiceLabel,
+	} {
+		if name != "" && !metricsNameRegexp.MatchString(name) {
+			return fmt.Errorf("%w: %s", ErrInvalidMetricsNamespace, name)
+		}
+	}
+
+	_, err := parseMetricsLabels(cfg.MetricsLabels)
+	return err
+}
+
+// parseMetricsLabels parses a comma-separated list of name=value pairs into a
+// map of constant labels.
+func parseMetricsLabels(labels string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, pair := range strings.Split(labels, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+
+		parts := strings.SplitN(pair, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || !metricsNameRegexp.MatchString(name) ||
+			strings.HasPrefix(name, "__") {
+
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMetricsLabels,
+				pair)
+		}
+		if _, ok := parsed[name]; ok {
+			return nil, fmt.Errorf("%w: duplicate label %s",
+				ErrInvalidMetricsLabels, name)
+		}
+		parsed[name] = strings.TrimSpace(parts[1])
+	}
+
+	return parsed, nil
+}
+
+// metricsGatherer returns the gatherer of every registered metric, named and
+// labeled as configured by the MetricsNamespace and MetricsLabels.
+func metricsGatherer(cfg Config) prometheus.Gatherer {
+	// The labels were parsed by ValidateConfig.
+	labels, _ := parseMetricsLabels(cfg.MetricsLabels)
+
+	return metrics.NewGatherer(
+		prometheus.DefaultGatherer, cfg.MetricsNamespace, labels,
+	)
+}
+
+// startMetricsExporters begins pushing every registered metric to each of the
+// configured push-based exporters, returning nil if none are configured.
+// Prometheus is scraped by the metrics server instead.
+func startMetricsExporters(cfg Config) (*telemetry.Pusher, error) {
+	var sinks []telemetry.Sink
+	for _, name := range metricsExporters(cfg.MetricsExporters) {
+		switch name {
+		case MetricsExporterStatsD:
+			sink, err := telemetry.NewStatsDSink(cfg.StatsDAddress)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+
+		case MetricsExporterOTLP:
+			sinks = append(sinks, telemetry.NewOTLPSink(
+				cfg.OTLPEndpoint, otlpServiceName,
+			))
+
+		case MetricsExporterPushgateway:
+			// Grouping by the constant labels keeps the pushes of
+			// submitters on different networks from replacing one
+			// another.
+			labels, _ := parseMetricsLabels(cfg.MetricsLabels)
+			sinks = append(sinks, telemetry.NewPushgatewaySink(
+				cfg.PushgatewayURL, cfg.PushgatewayJob, labels,
+			))
+		}
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	pusher := telemetry.NewPusher(
+		metricsGatherer(cfg), cfg.MetricsExportInterval, sinks...,
+	)
+	pusher.Start()
+
+	log.Info("Started metrics exporters",
+		"exporters", cfg.MetricsExporters,
+		"interval", cfg.MetricsExportInterval)
+
+	return pusher, nil
+}
@@ -0,0 +1,343 @@
+package noncemgr
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// selfTxGasLimit is the gas limit of a zero-value self-transaction.
+const selfTxGasLimit = 21000
+
+// NonceSource is the subset of ethclient.Client used to query the nonces of an
+// account.
+type NonceSource interface {
+	// NonceAt returns the nonce of account at the given block height, or
+	// the latest block if blockNumber is nil.
+	NonceAt(ctx context.Context, account common.Address,
+		blockNumber *big.Int) (uint64, error)
+
+	// PendingNonceAt returns the nonce of account including any txs in the
+	// backend's tx pool.
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+}
+
+// GapFiller publishes a tx at the given nonce in order to repair a gap in the
+// account's nonces.
+type GapFiller = func(ctx context.Context, nonce uint64) error
+
+// Manager tracks the nonces assigned locally to an account, reconciling them
+// against the backend's view of the latest and pending nonces before each
+// assignment. This prevents nonces from being reused or skipped if a query to
+// the backend fails or races with an in-flight tx.
+//
+// On first use, nonces are assigned from the latest nonce, such that any txs
+// left pending by a previous run are replaced in turn rather than queued
+// behind. Likewise, the nonce of a published tx that is Abandoned, e.g. as it
+// never confirmed, is reassigned before any new nonce, such that the tx stuck
+// at it is replaced rather than holding up every later nonce.
+//
+// A gap is detected when the backend's pending nonce falls behind the next
+// locally-assigned nonce, i.e. a published tx was dropped from the tx pool. If
+// a GapFiller is configured, each missing nonce is filled so that any queued
+// txs with higher nonces can confirm. Otherwise, the manager rewinds and the
+// missing nonces are reassigned.
+type Manager struct {
+	name    string
+	account common.Address
+	backend NonceSource
+	fillGap GapFiller
+
+	mu     sync.Mutex
+	next   uint64
+	synced bool
+
+	// inherited is the pending nonce when the manager was synced. Nonces
+	// below it belong to txs published by a previous run, which are not
+	// mistaken for external advances while being replaced.
+	inherited uint64
+
+	// abandoned is the set of nonces below next to reassign, as their txs
+	// were abandoned or never published.
+	abandoned map[uint64]struct{}
+}
+
+// NewManager initializes a Manager for account. If fillGap is nil, nonce gaps
+// are repaired by reassigning the missing nonces.
+func NewManager(
+	name string,
+	account common.Address,
+	backend NonceSource,
+	fillGap GapFiller,
+) *Manager {
+
+	return &Manager{
+		name:      name,
+		account:   account,
+		backend:   backend,
+		fillGap:   fillGap,
+		abandoned: make(map[uint64]struct{}),
+	}
+}
+
+// Next reconciles the locally tracked nonce with the backend and reserves the
+// next nonce for a new tx, reassigning the lowest abandoned nonce if any.
+// Callers MUST Release the nonce if no tx using it was published, or Abandon it
+// if the published tx will not confirm.
+func (m *Manager) Next(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.reconcile(ctx); err != nil {
+		return 0, err
+	}
+
+	if nonce, ok := m.lowestAbandoned(); ok {
+		delete(m.abandoned, nonce)
+		log.Info(m.name+" reassigning abandoned nonce", "nonce", nonce)
+		return nonce, nil
+	}
+
+	nonce := m.next
+	m.next++
+
+	return nonce, nil
+}
+
+// Release returns a nonce reserved by Next that was never published, allowing
+// it to be reassigned. The most recently reserved nonce is rewound, any other
+// nonce is reassigned ahead of new nonces as if abandoned.
+func (m *Manager) Release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !m.synced || nonce >= m.next {
+		return
+	}
+	if nonce+1 == m.next {
+		m.next = nonce
+		return
+	}
+	m.abandoned[nonce] = struct{}{}
+}
+
+// Abandon marks the nonce of a published tx that will not confirm, e.g. as
+// the tx manager gave up on it, for reassignment ahead of new nonces. Unless
+// the abandoned tx is mined in the meantime, the next tx published at the
+// nonce replaces it.
+func (m *Manager) Abandon(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.synced && nonce < m.next {
+		m.abandoned[nonce] = struct{}{}
+	}
+}
+
+// Reset discards the locally tracked nonce, such that the next reservation is
+// initialized from the latest nonce as on first use. This is used when another
+// process may have published from the account in the meantime, whose pending
+// txs would otherwise be mistaken for gaps or external advances.
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.synced = false
+}
+
+// SetAccount switches the manager to assigning the nonces of account, e.g. once
+// the submitting wallet is rotated. The next reservation is initialized from
+// the latest nonce as on first use.
+func (m *Manager) SetAccount(account common.Address) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.account = account
+	m.synced = false
+}
+
+// lowestAbandoned returns the lowest nonce to reassign, if any.
+//
+// NOTE: This method MUST be called while holding m.mu.
+func (m *Manager) lowestAbandoned() (uint64, bool) {
+	var (
+		lowest uint64
+		found  bool
+	)
+	for nonce := range m.abandoned {
+		if !found || nonce < lowest {
+			lowest, found = nonce, true
+		}
+	}
+
+	return lowest, found
+}
+
+// reconcile updates the locally tracked nonce using the backend's latest and
+// pending nonces, repairing any gap that is detected.
+//
+// NOTE: This method MUST be called while holding m.mu.
+func (m *Manager) reconcile(ctx context.Context) error {
+	latest, err := m.backend.NonceAt(ctx, m.account, nil)
+	if err != nil {
+		return err
+	}
+	pending, err := m.backend.PendingNonceAt(ctx, m.account)
+	if err != nil {
+		return err
+	}
+
+	// The pending nonce can only trail the latest nonce if the backend's
+	// tx pool is lagging, in which case the latest nonce is authoritative.
+	if pending < latest {
+		pending = latest
+	}
+
+	// Abandoned nonces that were since consumed, by the abandoned tx or
+	// otherwise, can no longer be reassigned.
+	for nonce := range m.abandoned {
+		if nonce < latest {
+			delete(m.abandoned, nonce)
+		}
+	}
+
+	switch {
+	// Initialize from the latest nonce on first use, such that any txs
+	// left pending by a previous run are replaced rather than left stuck
+	// ahead of ours.
+	case !m.synced:
+		if pending > latest {
+			log.Warn(m.name+" replacing txs left pending",
+				"latest", latest, "pending", pending)
+		}
+		m.next = latest
+		m.inherited = pending
+		m.abandoned = make(map[uint64]struct{})
+		m.synced = true
+
+	// The account published txs we did not assign, e.g. from another
+	// process or a manual intervention.
+	case latest > m.next || (pending > m.next && m.next >= m.inherited):
+		log.Warn(m.name+" nonce advanced externally", "local", m.next,
+			"pending", pending)
+		m.next = pending
+
+	// Txs we published are missing from the tx pool.
+	case pending < m.next:
+		log.Warn(m.name+" detected nonce gap", "pending", pending,
+			"local", m.next, "latest", latest)
+
+		if m.fillGap == nil {
+			for nonce := range m.abandoned {
+				if nonce >= pending {
+					delete(m.abandoned, nonce)
+				}
+			}
+			m.next = pending
+			return nil
+		}
+
+		// Abandoned nonces are filled once reassigned.
+		for nonce := pending; nonce < m.next; nonce++ {
+			if _, ok := m.abandoned[nonce]; ok {
+				continue
+			}
+			if err := m.fillGap(ctx, nonce); err != nil {
+				return err
+			}
+			log.Info(m.name+" filled nonce gap", "nonce", nonce)
+		}
+	}
+
+	return nil
+}
+
+// SelfTxBackend is the subset of ethclient.Client used to publish zero-value
+// self-transactions.
+type SelfTxBackend interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// SelfTxSender signs and publishes a zero-value self-transaction at the given
+// nonce and gas price, which can be used to fill or cancel the nonce.
+type SelfTxSender = func(ctx context.Context, nonce uint64,
+	gasPrice *big.Int) (*types.Transaction, error)
+
+// NewSelfTxSender returns a SelfTxSender publishing txs from the account of
+// privKey to itself.
+func NewSelfTxSender(
+	backend SelfTxBackend,
+	privKey *ecdsa.PrivateKey,
+	chainID *big.Int,
+) SelfTxSender {
+
+	return NewRotatingSelfTxSender(backend, func() *ecdsa.PrivateKey {
+		return privKey
+	}, chainID)
+}
+
+// NewRotatingSelfTxSender returns a SelfTxSender publishing each tx from the
+// account of the key returned by activeKey at the time, to itself. This allows
+// the sender to follow the active wallet as it is rotated.
+func NewRotatingSelfTxSender(
+	backend SelfTxBackend,
+	activeKey func() *ecdsa.PrivateKey,
+	chainID *big.Int,
+) SelfTxSender {
+
+	signer := types.NewEIP155Signer(chainID)
+
+	return func(ctx context.Context, nonce uint64,
+		gasPrice *big.Int) (*types.Transaction, error) {
+
+		privKey := activeKey()
+		account := crypto.PubkeyToAddress(privKey.PublicKey)
+
+		tx, err := types.SignNewTx(privKey, signer, &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: gasPrice,
+			Gas:      selfTxGasLimit,
+			To:       &account,
+			Value:    new(big.Int),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := backend.SendTransaction(ctx, tx); err != nil {
+			return nil, err
+		}
+
+		return tx, nil
+	}
+}
+
+// NewSelfTxGapFiller returns a GapFiller that fills each missing nonce with a
+// zero-value self-transaction published by sendSelfTx, priced at the
+// backend's suggested gas price clamped to maxGasPrice.
+func NewSelfTxGapFiller(
+	backend SelfTxBackend,
+	sendSelfTx SelfTxSender,
+	maxGasPrice *big.Int,
+) GapFiller {
+
+	return func(ctx context.Context, nonce uint64) error {
+		gasPrice, err := backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return err
+		}
+		if gasPrice.Cmp(maxGasPrice) > 0 {
+			gasPrice = maxGasPrice
+		}
+
+		_, err = sendSelfTx(ctx, nonce, gasPrice)
+		return err
+	}
+}
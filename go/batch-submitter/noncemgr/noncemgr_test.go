@@ -0,0 +1,304 @@
+package noncemgr_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+var testAccount = common.HexToAddress("0x01")
+
+// mockNonceSource is a noncemgr.NonceSource returning configurable latest and
+// pending nonces.
+type mockNonceSource struct {
+	mu      sync.Mutex
+	latest  uint64
+	pending uint64
+	err     error
+}
+
+// setNonces updates the latest and pending nonces reported by the backend.
+func (b *mockNonceSource) setNonces(latest, pending uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.latest = latest
+	b.pending = pending
+}
+
+// NonceAt returns the configured latest nonce.
+func (b *mockNonceSource) NonceAt(ctx context.Context, account common.Address,
+	blockNumber *big.Int) (uint64, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.latest, b.err
+}
+
+// PendingNonceAt returns the configured pending nonce.
+func (b *mockNonceSource) PendingNonceAt(ctx context.Context,
+	account common.Address) (uint64, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.pending, b.err
+}
+
+// TestManagerAssignsSequentialNonces asserts that the manager initializes from
+// the latest nonce, replacing the txs left pending by a previous run, and
+// assigns sequential nonces while the backend lags behind in-flight txs.
+func TestManagerAssignsSequentialNonces(t *testing.T) {
+	t.Parallel()
+
+	backend := &mockNonceSource{latest: 3, pending: 5}
+	mgr := noncemgr.NewManager("TEST", testAccount, backend, nil)
+	ctx := context.Background()
+
+	nonce, err := mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), nonce)
+
+	// The txs left pending are replaced rather than skipped.
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(4), nonce)
+
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(5), nonce)
+
+	// The pool has seen the published tx, but latest hasn't advanced.
+	backend.setNonces(3, 6)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(6), nonce)
+
+	// An unpublished nonce is reassigned once released.
+	mgr.Release(nonce)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(6), nonce)
+
+	// Nonces used externally are skipped.
+	backend.setNonces(10, 10)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), nonce)
+}
+
+// TestManagerBackendError asserts that a failed query to the backend does not
+// consume a nonce.
+func TestManagerBackendError(t *testing.T) {
+	t.Parallel()
+
+	backend := &mockNonceSource{latest: 1, pending: 1}
+	mgr := noncemgr.NewManager("TEST", testAccount, backend, nil)
+	ctx := context.Background()
+
+	nonce, err := mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), nonce)
+
+	errBackend := errors.New("backend failed")
+	backend.err = errBackend
+	_, err = mgr.Next(ctx)
+	require.Equal(t, errBackend, err)
+
+	backend.err = nil
+	backend.setNonces(1, 2)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), nonce)
+}
+
+// TestManagerNonceGap asserts that a gap left by dropped txs is either rewound
+// or filled, depending on whether a GapFiller is configured.
+func TestManagerNonceGap(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// Without a gap filler, the missing nonces are reassigned.
+	backend := &mockNonceSource{}
+	mgr := noncemgr.NewManager("TEST", testAccount, backend, nil)
+	for i := uint64(0); i < 3; i++ {
+		backend.setNonces(0, i)
+		_, err := mgr.Next(ctx)
+		require.Nil(t, err)
+	}
+
+	backend.setNonces(1, 1)
+	nonce, err := mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), nonce)
+
+	// With a gap filler, each missing nonce is filled before assigning
+	// the next one.
+	var filled []uint64
+	fillGap := func(ctx context.Context, nonce uint64) error {
+		filled = append(filled, nonce)
+		return nil
+	}
+
+	backend = &mockNonceSource{}
+	mgr = noncemgr.NewManager("TEST", testAccount, backend, fillGap)
+	for i := uint64(0); i < 3; i++ {
+		backend.setNonces(0, i)
+		_, err := mgr.Next(ctx)
+		require.Nil(t, err)
+	}
+
+	backend.setNonces(1, 1)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), nonce)
+	require.Equal(t, []uint64{1, 2}, filled)
+}
+
+// TestManagerReassignsAbandonedNonce asserts that the nonce of an abandoned tx
+// is reassigned ahead of new nonces until it is consumed, and that released
+// nonces behind the latest reservation are likewise reassigned.
+func TestManagerReassignsAbandonedNonce(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var filled []uint64
+	fillGap := func(ctx context.Context, nonce uint64) error {
+		filled = append(filled, nonce)
+		return nil
+	}
+
+	backend := &mockNonceSource{}
+	mgr := noncemgr.NewManager("TEST", testAccount, backend, fillGap)
+	nonce, err := mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(0), nonce)
+
+	// The published tx is stuck in the pool, and is replaced once
+	// abandoned.
+	backend.setNonces(0, 1)
+	mgr.Abandon(nonce)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(0), nonce)
+
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), nonce)
+
+	// An abandoned tx that is mined regardless is not replaced.
+	backend.setNonces(0, 2)
+	mgr.Abandon(0)
+	backend.setNonces(1, 2)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), nonce)
+
+	// A dropped abandoned tx is reassigned rather than filled.
+	backend.setNonces(1, 3)
+	mgr.Abandon(1)
+	backend.setNonces(1, 1)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), nonce)
+	require.Equal(t, []uint64{2}, filled)
+
+	// A released nonce behind the latest reservation is reassigned.
+	backend.setNonces(1, 3)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(3), nonce)
+	mgr.Release(1)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), nonce)
+}
+
+// TestManagerSetAccount asserts that switching accounts initializes the next
+// nonce from the new account's tx pool, rather than treating it as a gap.
+func TestManagerSetAccount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var filled []uint64
+	fillGap := func(ctx context.Context, nonce uint64) error {
+		filled = append(filled, nonce)
+		return nil
+	}
+
+	backend := &mockNonceSource{}
+	mgr := noncemgr.NewManager("TEST", testAccount, backend, fillGap)
+	backend.setNonces(5, 5)
+	nonce, err := mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(5), nonce)
+
+	mgr.SetAccount(common.HexToAddress("0x02"))
+	backend.setNonces(2, 2)
+	nonce, err = mgr.Next(ctx)
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), nonce)
+	require.Empty(t, filled)
+}
+
+// mockSelfTxBackend is a noncemgr.SelfTxBackend recording published txs.
+type mockSelfTxBackend struct {
+	gasPrice *big.Int
+	txs      []*types.Transaction
+}
+
+// SuggestGasPrice returns the configured gas price.
+func (b *mockSelfTxBackend) SuggestGasPrice(
+	ctx context.Context) (*big.Int, error) {
+
+	return b.gasPrice, nil
+}
+
+// SendTransaction records tx as published.
+func (b *mockSelfTxBackend) SendTransaction(
+	ctx context.Context, tx *types.Transaction) error {
+
+	b.txs = append(b.txs, tx)
+	return nil
+}
+
+// TestSelfTxGapFiller asserts that the gap filler publishes a zero-value
+// self-transaction at the missing nonce, with its gas price clamped to the max.
+func TestSelfTxGapFiller(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	account := crypto.PubkeyToAddress(privKey.PublicKey)
+	chainID := big.NewInt(5)
+
+	backend := &mockSelfTxBackend{gasPrice: big.NewInt(200)}
+	fillGap := noncemgr.NewSelfTxGapFiller(
+		backend, noncemgr.NewSelfTxSender(backend, privKey, chainID),
+		big.NewInt(100),
+	)
+	require.Nil(t, fillGap(context.Background(), 7))
+	require.Len(t, backend.txs, 1)
+
+	tx := backend.txs[0]
+	require.Equal(t, uint64(7), tx.Nonce())
+	require.Equal(t, &account, tx.To())
+	require.Equal(t, int64(0), tx.Value().Int64())
+	require.Equal(t, big.NewInt(100), tx.GasPrice())
+
+	sender, err := types.Sender(types.NewEIP155Signer(chainID), tx)
+	require.Nil(t, err)
+	require.Equal(t, account, sender)
+}
@@ -0,0 +1,331 @@
+package batchsubmitter
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/flags"
+	"github.com/urfave/cli"
+)
+
+// ErrRequiredOptionNotSet signals that a Config was built from options without
+// one of the options required to run the batch submitter.
+var ErrRequiredOptionNotSet = errors.New("required option not set")
+
+// ErrInvalidOption signals that an option was passed an invalid value.
+var ErrInvalidOption = errors.New("invalid option")
+
+// The defaults of the settings whose flags are required, and therefore have
+// no default of their own.
+const (
+	defaultMaxL1TxSize         = 90_000
+	defaultPollInterval        = 500 * time.Millisecond
+	defaultNumConfirmations    = 1
+	defaultResubmissionTimeout = time.Minute
+)
+
+// Option configures a Config built by NewConfigFromOptions, returning an error
+// wrapping ErrInvalidOption if passed an invalid value.
+type Option func(cfg *Config) error
+
+// GasStrategy determines how the gas price of batch txs is chosen and bumped.
+type GasStrategy struct {
+	// Oracle selects the source of the initial gas price of batch txs of
+	// both services, one of GasPriceOracleNode, GasPriceOracleFeeHistory
+	// or GasPriceOracleHTTP. If empty, the initial gas price is the
+	// minimum gas price.
+	Oracle string
+
+	// OracleURL and OracleField locate the gas price in gwei, when using
+	// GasPriceOracleHTTP.
+	OracleURL   string
+	OracleField string
+
+	// MaxGasPriceInGwei is the max gas price at which batch txs are ever
+	// published.
+	MaxGasPriceInGwei uint64
+
+	// GasRetryIncrement is the step size in gwei by which the gas price is
+	// bumped after each ResubmissionTimeout.
+	GasRetryIncrement uint64
+
+	// ResubmissionTimeout is the duration after which a batch tx that has
+	// not been mined is republished at a bumped gas price.
+	ResubmissionTimeout time.Duration
+
+	// DeferAboveMaxGasPrice, if true, skips submission while the market
+	// gas price exceeds MaxGasPriceInGwei.
+	DeferAboveMaxGasPrice bool
+}
+
+// DA determines how sequencer batches are shaped for submission as calldata
+// to the CTC, which serves as the data availability layer of L2.
+type DA struct {
+	// CTCAddress is the address of the CTC.
+	CTCAddress string
+
+	// MinL2TxCount is the minimum number of L2 txs a pending range must
+	// hold before it is submitted, unless it is older than
+	// MaxBatchSubmissionTime. If zero, it is not enforced.
+	MinL2TxCount uint64
+
+	// MinBatchBytes is the minimum size in bytes of the L2 txs of a
+	// pending range before it is submitted, unless it is older than
+	// MaxBatchSubmissionTime. If zero, it is not enforced.
+	MinBatchBytes uint64
+
+	// MaxTxBatchCount is the max number of L2 txs in a single batch.
+	MaxTxBatchCount uint64
+
+	// MaxBatchSubmissionTime is the max duration a pending range waits to
+	// reach MinL2TxCount or MinBatchBytes before being submitted. If zero,
+	// sequencer.DefaultMaxBatchSubmissionTime is used.
+	MaxBatchSubmissionTime time.Duration
+}
+
+// DefaultConfig returns the Config used by the batch submitter when no flags
+// are set, ignoring any environment variables. Settings that are required on
+// the command line are given sane defaults, except the L1 and L2 providers,
+// the contract addresses and the signers.
+func DefaultConfig() Config {
+	set := flag.NewFlagSet("batch-submitter", flag.ContinueOnError)
+	for _, f := range flags.Flags {
+		withoutEnvVar(f).Apply(set)
+	}
+
+	cfg := configFromContext(cli.NewContext(nil, set, nil))
+	cfg.MaxL1TxSize = defaultMaxL1TxSize
+	cfg.PollInterval = defaultPollInterval
+	cfg.NumConfirmations = defaultNumConfirmations
+	cfg.ResubmissionTimeout = defaultResubmissionTimeout
+	cfg.RunTxBatchSubmitter = true
+	cfg.RunStateBatchSubmitter = true
+
+	return cfg
+}
+
+// withoutEnvVar returns a copy of f that is not read from the environment.
+func withoutEnvVar(f cli.Flag) cli.Flag {
+	switch f := f.(type) {
+	case cli.StringFlag:
+		f.EnvVar = ""
+		return f
+	case cli.BoolFlag:
+		f.EnvVar = ""
+		return f
+	case cli.Uint64Flag:
+		f.EnvVar = ""
+		return f
+	case cli.Float64Flag:
+		f.EnvVar = ""
+		return f
+	case cli.DurationFlag:
+		f.EnvVar = ""
+		return f
+	default:
+		return f
+	}
+}
+
+// NewConfigFromOptions builds a Config from DefaultConfig by applying opts in
+// order, allowing the batch submitter to be embedded without populating a
+// Config by hand. The result is validated as NewConfig does. WithL1EthRpc,
+// WithL2EthRpc, WithContracts and either WithSigner or WithMnemonic are
+// required.
+func NewConfigFromOptions(opts ...Option) (Config, error) {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	switch {
+	case cfg.L1EthRpc == "":
+		return Config{}, fmt.Errorf("%w: WithL1EthRpc",
+			ErrRequiredOptionNotSet)
+	case cfg.L2EthRpc == "":
+		return Config{}, fmt.Errorf("%w: WithL2EthRpc",
+			ErrRequiredOptionNotSet)
+	case cfg.CTCAddress == "" || cfg.SCCAddress == "":
+		return Config{}, fmt.Errorf("%w: WithContracts",
+			ErrRequiredOptionNotSet)
+	}
+
+	if err := ValidateConfig(&cfg); err != nil {
+		return Config{}, err
+	}
+
+	return cfg, nil
+}
+
+// NewBatchSubmitterFromOptions initializes a BatchSubmitter from a Config built
+// by NewConfigFromOptions.
+func NewBatchSubmitterFromOptions(
+	gitVersion string,
+	opts ...Option,
+) (*BatchSubmitter, error) {
+
+	cfg, err := NewConfigFromOptions(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBatchSubmitter(cfg, gitVersion)
+}
+
+// invalidOption returns an error wrapping ErrInvalidOption for option.
+func invalidOption(option, format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s: %s", ErrInvalidOption, option,
+		fmt.Sprintf(format, args...))
+}
+
+// WithL1EthRpc sets the L1 provider URLs, failing over between them in the
+// order given.
+func WithL1EthRpc(urls ...string) Option {
+	return func(cfg *Config) error {
+		if len(urls) == 0 {
+			return invalidOption("WithL1EthRpc", "no URLs")
+		}
+		cfg.L1EthRpc = strings.Join(urls, ",")
+		return nil
+	}
+}
+
+// WithL2EthRpc sets the L2 provider URLs, failing over between them in the
+// order given.
+func WithL2EthRpc(urls ...string) Option {
+	return func(cfg *Config) error {
+		if len(urls) == 0 {
+			return invalidOption("WithL2EthRpc", "no URLs")
+		}
+		cfg.L2EthRpc = strings.Join(urls, ",")
+		return nil
+	}
+}
+
+// WithContracts sets the addresses of the CTC and SCC.
+func WithContracts(ctcAddress, sccAddress string) Option {
+	return func(cfg *Config) error {
+		for _, addr := range []string{ctcAddress, sccAddress} {
+			if _, err := ParseAddress(addr); err != nil {
+				return invalidOption("WithContracts", "%v", err)
+			}
+		}
+		cfg.CTCAddress = ctcAddress
+		cfg.SCCAddress = sccAddress
+		return nil
+	}
+}
+
+// WithServices selects which of the sequencer and proposer are run.
+func WithServices(runTxBatchSubmitter, runStateBatchSubmitter bool) Option {
+	return func(cfg *Config) error {
+		cfg.RunTxBatchSubmitter = runTxBatchSubmitter
+		cfg.RunStateBatchSubmitter = runStateBatchSubmitter
+		return nil
+	}
+}
+
+// WithSigner sets the hex-encoded private keys of the sequencer and proposer
+// wallets, replacing any mnemonic.
+func WithSigner(sequencerPrivKey, proposerPrivKey string) Option {
+	return func(cfg *Config) error {
+		cfg.SequencerPrivateKey = sequencerPrivKey
+		cfg.ProposerPrivateKey = proposerPrivKey
+		cfg.Mnemonic = ""
+		cfg.SequencerHDPath = ""
+		cfg.ProposerHDPath = ""
+		return nil
+	}
+}
+
+// WithMnemonic derives the sequencer and proposer wallets from mnemonic at the
+// given HD paths, replacing any private keys.
+func WithMnemonic(
+	mnemonic, sequencerHDPath, proposerHDPath string,
+) Option {
+
+	return func(cfg *Config) error {
+		cfg.Mnemonic = mnemonic
+		cfg.SequencerHDPath = sequencerHDPath
+		cfg.ProposerHDPath = proposerHDPath
+		cfg.SequencerPrivateKey = ""
+		cfg.ProposerPrivateKey = ""
+		return nil
+	}
+}
+
+// WithMaxTxSize sets the max size in bytes of any L1 tx, which must exceed
+// the min size.
+func WithMaxTxSize(maxTxSize uint64) Option {
+	return func(cfg *Config) error {
+		if maxTxSize <= cfg.MinL1TxSize {
+			return invalidOption("WithMaxTxSize", "max tx size %d "+
+				"must exceed min tx size %d", maxTxSize,
+				cfg.MinL1TxSize)
+		}
+		cfg.MaxL1TxSize = maxTxSize
+		return nil
+	}
+}
+
+// WithGasStrategy sets how the gas price of batch txs is chosen and bumped.
+// Zero values of MaxGasPriceInGwei, GasRetryIncrement and ResubmissionTimeout
+// keep the defaults.
+func WithGasStrategy(strategy GasStrategy) Option {
+	return func(cfg *Config) error {
+		switch strategy.Oracle {
+		case "", GasPriceOracleNode, GasPriceOracleFeeHistory:
+		case GasPriceOracleHTTP:
+			if strategy.OracleURL == "" {
+				return invalidOption("WithGasStrategy",
+					"http oracle requires an oracle URL")
+			}
+		default:
+			return invalidOption("WithGasStrategy",
+				"unknown oracle %q", strategy.Oracle)
+		}
+
+		cfg.SequencerGasPriceOracle = strategy.Oracle
+		cfg.ProposerGasPriceOracle = strategy.Oracle
+		cfg.GasPriceOracleURL = strategy.OracleURL
+		if strategy.OracleField != "" {
+			cfg.GasPriceOracleField = strategy.OracleField
+		}
+		if strategy.MaxGasPriceInGwei != 0 {
+			cfg.MaxGasPriceInGwei = strategy.MaxGasPriceInGwei
+		}
+		if strategy.GasRetryIncrement != 0 {
+			cfg.GasRetryIncrement = strategy.GasRetryIncrement
+		}
+		if strategy.ResubmissionTimeout != 0 {
+			cfg.ResubmissionTimeout = strategy.ResubmissionTimeout
+		}
+		cfg.DeferAboveMaxGasPrice = strategy.DeferAboveMaxGasPrice
+		return nil
+	}
+}
+
+// WithDA sets how sequencer batches are shaped for submission to the CTC. A
+// zero MaxTxBatchCount keeps the default.
+func WithDA(da DA) Option {
+	return func(cfg *Config) error {
+		if da.CTCAddress != "" {
+			if _, err := ParseAddress(da.CTCAddress); err != nil {
+				return invalidOption("WithDA", "%v", err)
+			}
+			cfg.CTCAddress = da.CTCAddress
+		}
+		cfg.MinL2TxCount = da.MinL2TxCount
+		cfg.MinBatchBytes = da.MinBatchBytes
+		cfg.MaxBatchSubmissionTime = da.MaxBatchSubmissionTime
+		if da.MaxTxBatchCount != 0 {
+			cfg.MaxTxBatchCount = da.MaxTxBatchCount
+		}
+		return nil
+	}
+}
@@ -0,0 +1,104 @@
+package batchsubmitter_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testCTCAddress = "0x4BF681894abEc828B212C906082B444Ceb2f6cf6"
+	testSCCAddress = "0xE969C2724d2448F1d1A6189d3e2aA1F37d5998c1"
+)
+
+// requiredOptions returns the options required to build a Config.
+func requiredOptions() []batchsubmitter.Option {
+	return []batchsubmitter.Option{
+		batchsubmitter.WithL1EthRpc("http://l1-a:8545", "http://l1-b:8545"),
+		batchsubmitter.WithL2EthRpc("http://l2:8545"),
+		batchsubmitter.WithContracts(testCTCAddress, testSCCAddress),
+		batchsubmitter.WithSigner("sequencer-privkey", "proposer-privkey"),
+	}
+}
+
+// TestDefaultConfig asserts that the default Config holds the defaults of the
+// flags, ignoring the environment, along with defaults for required flags.
+func TestDefaultConfig(t *testing.T) {
+	os.Setenv("BATCH_SUBMITTER_MAX_GAS_PRICE_IN_GWEI", "1")
+	defer os.Unsetenv("BATCH_SUBMITTER_MAX_GAS_PRICE_IN_GWEI")
+
+	cfg := batchsubmitter.DefaultConfig()
+	require.Equal(t, uint64(100), cfg.MaxGasPriceInGwei)
+	require.Equal(t, uint64(5), cfg.GasRetryIncrement)
+	require.NotZero(t, cfg.MaxL1TxSize)
+	require.NotZero(t, cfg.PollInterval)
+	require.True(t, cfg.RunTxBatchSubmitter)
+	require.True(t, cfg.RunStateBatchSubmitter)
+}
+
+// TestNewConfigFromOptions asserts that options are applied over the default
+// Config.
+func TestNewConfigFromOptions(t *testing.T) {
+	t.Parallel()
+
+	opts := append(requiredOptions(),
+		batchsubmitter.WithMaxTxSize(120_000),
+		batchsubmitter.WithGasStrategy(batchsubmitter.GasStrategy{
+			Oracle:            batchsubmitter.GasPriceOracleFeeHistory,
+			MaxGasPriceInGwei: 300,
+		}),
+		batchsubmitter.WithDA(batchsubmitter.DA{
+			MinL2TxCount:           10,
+			MaxBatchSubmissionTime: time.Minute,
+		}),
+	)
+	cfg, err := batchsubmitter.NewConfigFromOptions(opts...)
+	require.Nil(t, err)
+
+	require.Equal(t, "http://l1-a:8545,http://l1-b:8545", cfg.L1EthRpc)
+	require.Equal(t, testCTCAddress, cfg.CTCAddress)
+	require.Equal(t, uint64(120_000), cfg.MaxL1TxSize)
+	require.Equal(t, batchsubmitter.GasPriceOracleFeeHistory,
+		cfg.SequencerGasPriceOracle)
+	require.Equal(t, uint64(300), cfg.MaxGasPriceInGwei)
+	require.Equal(t, uint64(5), cfg.GasRetryIncrement)
+	require.Equal(t, uint64(10), cfg.MinL2TxCount)
+	require.Equal(t, time.Minute, cfg.MaxBatchSubmissionTime)
+}
+
+// TestNewConfigFromOptionsErrors asserts that missing required options,
+// invalid options and invalid configurations are rejected.
+func TestNewConfigFromOptionsErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := batchsubmitter.NewConfigFromOptions(
+		batchsubmitter.WithL1EthRpc("http://l1:8545"),
+	)
+	require.True(t, errors.Is(err, batchsubmitter.ErrRequiredOptionNotSet))
+
+	for _, opt := range []batchsubmitter.Option{
+		batchsubmitter.WithContracts("0x1234", testSCCAddress),
+		batchsubmitter.WithMaxTxSize(0),
+		batchsubmitter.WithGasStrategy(batchsubmitter.GasStrategy{
+			Oracle: "magic",
+		}),
+		batchsubmitter.WithGasStrategy(batchsubmitter.GasStrategy{
+			Oracle: batchsubmitter.GasPriceOracleHTTP,
+		}),
+	} {
+		_, err = batchsubmitter.NewConfigFromOptions(
+			append(requiredOptions(), opt)...,
+		)
+		require.True(t, errors.Is(err, batchsubmitter.ErrInvalidOption),
+			"err: %v", err)
+	}
+
+	_, err = batchsubmitter.NewConfigFromOptions(append(requiredOptions(),
+		batchsubmitter.WithSigner("privkey", "privkey"))...,
+	)
+	require.Equal(t, batchsubmitter.ErrSameSequencerAndProposerPrivKey, err)
+}
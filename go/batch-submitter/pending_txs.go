@@ -0,0 +1,201 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// PendingTxStrategyCancel clears each pending tx by replacing it with a
+	// zero-value self-transaction.
+	PendingTxStrategyCancel = "cancel"
+
+	// PendingTxStrategyReplace clears each pending tx by replacing it with
+	// a freshly built batch tx at the same nonce.
+	PendingTxStrategyReplace = "replace"
+)
+
+// ErrNoPendingTxs signals a request to replace a pending tx of a service whose
+// wallet has none.
+var ErrNoPendingTxs = errors.New("no pending txs")
+
+// PendingTxReplacement is the outcome of replacing a pending tx, as returned by
+// the admin API.
+type PendingTxReplacement struct {
+	// Nonce is the nonce of the replaced tx.
+	Nonce uint64 `json:"nonce"`
+
+	// Strategy is the pending tx strategy used to build the replacement.
+	Strategy string `json:"strategy"`
+
+	// TxHash is the hash of the confirmed replacement, or nil if the
+	// original tx confirmed first.
+	TxHash *common.Hash `json:"tx_hash,omitempty"`
+}
+
+// clearPendingTxs replaces every tx from the submitter's wallet that is pending
+// in the L1 tx pool, one nonce at a time, using the configured pending tx
+// strategy. This prevents a batch tx that was stuck in the mempool before a
+// restart from blocking all subsequent submissions.
+func (s *Service) clearPendingTxs() error {
+	name := s.cfg.Driver.Name()
+
+	for {
+		_, err := s.ReplacePendingTx(s.ctx, s.cfg.PendingTxStrategy)
+		if errors.Is(err, ErrNoPendingTxs) {
+			log.Info(name + " no pending txs to clear")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ReplacePendingTx replaces the lowest-nonce tx from the submitter's wallet
+// that is pending in the L1 tx pool, using the given pending tx strategy. The
+// replacement is published through the tx manager, which bumps its gas price
+// until it confirms. This method blocks until then, or until the original tx
+// confirms first, in which case the replacement is abandoned.
+//
+// ErrNoPendingTxs is returned if the wallet has no pending txs.
+func (s *Service) ReplacePendingTx(
+	ctx context.Context,
+	strategy string,
+) (*PendingTxReplacement, error) {
+
+	name := s.cfg.Driver.Name()
+	walletAddr := s.cfg.Driver.WalletAddr()
+
+	latest, err := s.cfg.L1Client.NonceAt(ctx, walletAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.cfg.L1Client.PendingNonceAt(ctx, walletAddr)
+	if err != nil {
+		return nil, err
+	}
+	if pending <= latest {
+		return nil, ErrNoPendingTxs
+	}
+
+	log.Warn(name+" replacing pending tx", "nonce", latest,
+		"num_pending", pending-latest, "strategy", strategy)
+
+	sendReplacementTx, err := s.replacementTxSender(ctx, strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	// The context is canceled by sendTx once the nonce is confirmed by any
+	// tx, aborting any further fee bumps.
+	ctxc, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	nonce := latest
+	sendTx := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+
+		confirmed, err := s.cfg.L1Client.NonceAt(ctx, walletAddr, nil)
+		if err != nil {
+			return nil, err
+		}
+		if confirmed > nonce {
+			cancel()
+			return nil, context.Canceled
+		}
+
+		log.Info(name+" attempting pending tx replacement",
+			"nonce", nonce, "gas_price", gasPrice)
+
+		return sendReplacementTx(ctx, nonce, gasPrice)
+	}
+
+	replacement := &PendingTxReplacement{
+		Nonce:    nonce,
+		Strategy: strategy,
+	}
+
+	receipt, err := s.txMgr.Send(ctxc, sendTx)
+	switch {
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case err == context.Canceled:
+		log.Info(name+" pending tx confirmed before replacement",
+			"nonce", nonce)
+	case err != nil:
+		return nil, err
+	default:
+		log.Info(name+" pending tx replaced", "nonce", nonce,
+			"tx_hash", receipt.TxHash)
+		replacement.TxHash = &receipt.TxHash
+	}
+
+	return replacement, nil
+}
+
+// replacementTxSender returns a function publishing a replacement tx at the
+// given nonce and gas price using strategy. When replacing with a batch tx,
+// the batch covers the range currently expected by the contract and is built
+// once, such that each fee bump publishes the same batch. A self-transaction
+// is used if there is nothing to submit.
+func (s *Service) replacementTxSender(
+	ctx context.Context,
+	strategy string,
+) (noncemgr.SelfTxSender, error) {
+
+	switch strategy {
+	case PendingTxStrategyCancel:
+		return s.cfg.SelfTxSender, nil
+	case PendingTxStrategyReplace:
+	default:
+		return nil, ErrUnknownPendingTxStrategy
+	}
+
+	start, end, err := s.cfg.Driver.GetBatchBlockRange(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if start.Cmp(end) == 0 {
+		return s.cfg.SelfTxSender, nil
+	}
+
+	builder, ok := s.cfg.Driver.(BatchBuilder)
+	if !ok {
+		return func(
+			ctx context.Context,
+			nonce uint64,
+			gasPrice *big.Int,
+		) (*types.Transaction, error) {
+
+			return s.cfg.Driver.SubmitBatchTx(
+				ctx, start, end, new(big.Int).SetUint64(nonce),
+				gasPrice,
+			)
+		}, nil
+	}
+
+	batch, err := builder.BuildBatch(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(
+		ctx context.Context,
+		nonce uint64,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+
+		return builder.SubmitBuiltBatch(
+			ctx, batch, new(big.Int).SetUint64(nonce), gasPrice,
+		)
+	}, nil
+}
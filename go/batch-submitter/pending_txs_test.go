@@ -0,0 +1,381 @@
+package batchsubmitter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingTxWallet is the wallet of the pendingTxDriver.
+var pendingTxWallet = common.HexToAddress("0xba7c4")
+
+// bumpingTxManager is a TxManager publishing each tx at every one of its gas
+// prices in turn, confirming the last.
+type bumpingTxManager struct {
+	gasPrices []*big.Int
+}
+
+func (m *bumpingTxManager) Send(
+	ctx context.Context,
+	sendTx txmgr.SendTxFunc,
+) (*types.Receipt, error) {
+
+	var tx *types.Transaction
+	for _, gasPrice := range m.gasPrices {
+		var err error
+		tx, err = sendTx(ctx, gasPrice)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &types.Receipt{
+		TxHash: tx.Hash(),
+		Status: types.ReceiptStatusSuccessful,
+	}, nil
+}
+
+// pendingTxDriver is a Driver whose contract expects the range [start, end),
+// recording the batch txs and self-transactions it publishes.
+type pendingTxDriver struct {
+	namedDriver
+	start, end int64
+
+	mu       sync.Mutex
+	built    int
+	batchTxs []*types.Transaction
+	selfTxs  []*types.Transaction
+}
+
+func (d *pendingTxDriver) WalletAddr() common.Address {
+	return pendingTxWallet
+}
+
+func (d *pendingTxDriver) GetBatchBlockRange(
+	context.Context) (*big.Int, *big.Int, error) {
+
+	return big.NewInt(d.start), big.NewInt(d.end), nil
+}
+
+func (d *pendingTxDriver) BuildBatch(
+	_ context.Context, start, end *big.Int) (*queue.Batch, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.built++
+	return &queue.Batch{
+		Start:    start.Uint64(),
+		End:      end.Uint64(),
+		CallData: []byte{0x01},
+	}, nil
+}
+
+func (d *pendingTxDriver) SubmitBuiltBatch(
+	_ context.Context,
+	batch *queue.Batch,
+	nonce, gasPrice *big.Int,
+) (*types.Transaction, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx := types.NewTransaction(nonce.Uint64(), common.Address{}, nil,
+		1000000, gasPrice, batch.CallData)
+	d.batchTxs = append(d.batchTxs, tx)
+	return tx, nil
+}
+
+func (d *pendingTxDriver) sendSelfTx(
+	_ context.Context,
+	nonce uint64,
+	gasPrice *big.Int,
+) (*types.Transaction, error) {
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	tx := types.NewTransaction(nonce, pendingTxWallet, new(big.Int),
+		21000, gasPrice, nil)
+	d.selfTxs = append(d.selfTxs, tx)
+	return tx, nil
+}
+
+// failingNonceAPI serves an eth namespace whose nonce queries fail.
+type failingNonceAPI struct{}
+
+func (failingNonceAPI) GetTransactionCount(
+	common.Address, string) (hexutil.Uint64, error) {
+
+	return 0, errors.New("nonce unavailable")
+}
+
+// newPendingTxTestServer serves the admin API for a service run by driver,
+// whose L1 backend is served by api and whose txs are published at a gas price
+// of 1 and then bumped to 2.
+func newPendingTxTestServer(
+	t *testing.T,
+	driver *pendingTxDriver,
+	api interface{},
+) (*httptest.Server, *Service) {
+
+	server, services := newAdminTestServer(t, driver)
+
+	l1 := rpc.NewServer()
+	require.Nil(t, l1.RegisterName("eth", api))
+	t.Cleanup(l1.Stop)
+
+	s := services[0]
+	s.cfg.L1Client = ethclient.NewClient(rpc.DialInProc(l1))
+	s.cfg.SelfTxSender = driver.sendSelfTx
+	s.cfg.PendingTxStrategy = PendingTxStrategyCancel
+	s.txMgr = &bumpingTxManager{
+		gasPrices: []*big.Int{big.NewInt(1), big.NewInt(2)},
+	}
+
+	return server, s
+}
+
+// newPendingTxWalletAPI returns a walletAPI serving the given nonces of the
+// pendingTxDriver's wallet.
+func newPendingTxWalletAPI(latest, pending uint64) *walletAPI {
+	api := &walletAPI{
+		balances: make(map[common.Address]*big.Int),
+		latest:   make(map[common.Address]uint64),
+		pending:  make(map[common.Address]uint64),
+	}
+	api.setNonces(pendingTxWallet, latest, pending)
+
+	return api
+}
+
+// postPendingReplace posts form to the pending tx replacement path of the admin
+// API signed by adminTestKey, decoding any replacement returned.
+func postPendingReplace(
+	t *testing.T,
+	server *httptest.Server,
+	form url.Values,
+) (int, PendingTxReplacement) {
+
+	signed := signAdmin(t, adminPendingReplacePath, form)
+	resp, err := http.Post(
+		server.URL+adminPendingReplacePath,
+		"application/x-www-form-urlencoded",
+		strings.NewReader(signed.Encode()),
+	)
+	require.Nil(t, err)
+	defer resp.Body.Close()
+
+	var replacement PendingTxReplacement
+	if resp.StatusCode == http.StatusOK {
+		require.Nil(t, json.NewDecoder(resp.Body).Decode(&replacement))
+	}
+
+	return resp.StatusCode, replacement
+}
+
+// TestAdminPendingList asserts that the admin API lists the nonces held by the
+// wallet's pending txs.
+func TestAdminPendingList(t *testing.T) {
+	t.Parallel()
+
+	driver := &pendingTxDriver{
+		namedDriver: namedDriver{name: "TestAdminPendingList"},
+	}
+	server, _ := newPendingTxTestServer(
+		t, driver, newPendingTxWalletAPI(3, 5),
+	)
+
+	resp, err := http.Get(server.URL + adminPendingPath +
+		"?service=TestAdminPendingList")
+	require.Nil(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var state PendingTxState
+	require.Nil(t, json.NewDecoder(resp.Body).Decode(&state))
+	require.Equal(t, uint64(3), state.LatestNonce)
+	require.Equal(t, uint64(5), state.PendingNonce)
+	require.Empty(t, state.InFlight)
+}
+
+// TestAdminPendingReplaceCancel asserts that the admin API cancels the lowest
+// pending tx with a self-transaction published through the tx manager, each
+// fee bump being published at the same nonce.
+func TestAdminPendingReplaceCancel(t *testing.T) {
+	t.Parallel()
+
+	driver := &pendingTxDriver{
+		namedDriver: namedDriver{name: "TestAdminPendingReplaceCancel"},
+		start:       5,
+		end:         7,
+	}
+	server, _ := newPendingTxTestServer(
+		t, driver, newPendingTxWalletAPI(3, 5),
+	)
+
+	code, replacement := postPendingReplace(t, server, url.Values{
+		"service": {"TestAdminPendingReplaceCancel"},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, uint64(3), replacement.Nonce)
+	require.Equal(t, PendingTxStrategyCancel, replacement.Strategy)
+
+	require.Empty(t, driver.batchTxs)
+	require.Len(t, driver.selfTxs, 2)
+	for i, tx := range driver.selfTxs {
+		require.Equal(t, uint64(3), tx.Nonce())
+		require.Equal(t, int64(i+1), tx.GasPrice().Int64())
+	}
+	require.NotNil(t, replacement.TxHash)
+	require.Equal(t, driver.selfTxs[1].Hash(), *replacement.TxHash)
+}
+
+// TestAdminPendingReplaceBatch asserts that the admin API replaces the lowest
+// pending tx with a batch tx published through the tx manager, the batch being
+// built once and published again by each fee bump.
+func TestAdminPendingReplaceBatch(t *testing.T) {
+	t.Parallel()
+
+	driver := &pendingTxDriver{
+		namedDriver: namedDriver{name: "TestAdminPendingReplaceBatch"},
+		start:       5,
+		end:         7,
+	}
+	server, _ := newPendingTxTestServer(
+		t, driver, newPendingTxWalletAPI(3, 5),
+	)
+
+	code, replacement := postPendingReplace(t, server, url.Values{
+		"service":  {"TestAdminPendingReplaceBatch"},
+		"strategy": {PendingTxStrategyReplace},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, uint64(3), replacement.Nonce)
+	require.Equal(t, PendingTxStrategyReplace, replacement.Strategy)
+
+	require.Equal(t, 1, driver.built)
+	require.Empty(t, driver.selfTxs)
+	require.Len(t, driver.batchTxs, 2)
+	for i, tx := range driver.batchTxs {
+		require.Equal(t, uint64(3), tx.Nonce())
+		require.Equal(t, int64(i+1), tx.GasPrice().Int64())
+	}
+	require.NotNil(t, replacement.TxHash)
+	require.Equal(t, driver.batchTxs[1].Hash(), *replacement.TxHash)
+}
+
+// TestAdminPendingReplaceConfirmedFirst asserts that a replacement is abandoned
+// without further fee bumps once the original tx confirms.
+func TestAdminPendingReplaceConfirmedFirst(t *testing.T) {
+	t.Parallel()
+
+	driver := &pendingTxDriver{
+		namedDriver: namedDriver{
+			name: "TestAdminPendingReplaceConfirmedFirst",
+		},
+	}
+	api := newPendingTxWalletAPI(3, 5)
+	server, s := newPendingTxTestServer(t, driver, api)
+
+	// The original tx confirms once the first replacement is published.
+	s.cfg.SelfTxSender = func(
+		ctx context.Context,
+		nonce uint64,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+
+		api.setNonces(pendingTxWallet, 4, 5)
+		return driver.sendSelfTx(ctx, nonce, gasPrice)
+	}
+
+	code, replacement := postPendingReplace(t, server, url.Values{
+		"service": {"TestAdminPendingReplaceConfirmedFirst"},
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, uint64(3), replacement.Nonce)
+	require.Nil(t, replacement.TxHash)
+	require.Len(t, driver.selfTxs, 1)
+}
+
+// TestAdminPendingReplaceErrors asserts that the admin API rejects replacing a
+// pending tx with an unknown strategy, of a wallet without pending txs, or
+// while the L1 backend is unavailable, without publishing any tx.
+func TestAdminPendingReplaceErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		api     interface{}
+		form    url.Values
+		expCode int
+	}{
+		{
+			name:    "unknown strategy",
+			api:     newPendingTxWalletAPI(3, 5),
+			form:    url.Values{"strategy": {"rebroadcast"}},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "no pending txs",
+			api:     newPendingTxWalletAPI(3, 3),
+			form:    url.Values{},
+			expCode: http.StatusConflict,
+		},
+		{
+			name:    "rpc error",
+			api:     failingNonceAPI{},
+			form:    url.Values{},
+			expCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			name := t.Name()
+			driver := &pendingTxDriver{
+				namedDriver: namedDriver{name: name},
+				start:       5,
+				end:         7,
+			}
+			server, _ := newPendingTxTestServer(t, driver, test.api)
+
+			test.form.Set("service", name)
+			code, _ := postPendingReplace(t, server, test.form)
+			require.Equal(t, test.expCode, code)
+			require.Empty(t, driver.selfTxs)
+			require.Empty(t, driver.batchTxs)
+		})
+	}
+
+	// Replacing a pending tx is a mutation, which is not served over GET.
+	driver := &pendingTxDriver{
+		namedDriver: namedDriver{name: "TestAdminPendingReplaceGet"},
+	}
+	server, _ := newPendingTxTestServer(
+		t, driver, newPendingTxWalletAPI(3, 5),
+	)
+	resp, err := http.Get(server.URL + adminPendingReplacePath +
+		"?service=TestAdminPendingReplaceGet")
+	require.Nil(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	require.Empty(t, driver.selfTxs)
+}
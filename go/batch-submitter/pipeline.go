@@ -0,0 +1,195 @@
+package batchsubmitter
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+// inFlightBatch is a batch tx published in pipelined mode that has yet to reach
+// the required confirmation depth.
+type inFlightBatch struct {
+	start uint64
+	end   uint64
+	nonce uint64
+}
+
+// pipeline tracks the batch txs in flight when up to MaxInFlightBatches may be
+// pending confirmation at once. Batches are published at consecutive nonces,
+// each beginning where the previous batch ends, such that their block ranges
+// never overlap.
+type pipeline struct {
+	mu          sync.Mutex
+	maxInFlight int
+	inFlight    []*inFlightBatch
+
+	// failed are the batches whose submission failed, ordered by start,
+	// which are retried over the same range at their reassigned nonces.
+	failed []*inFlightBatch
+
+	// draining is set once a batch in flight fails, as every batch behind
+	// it remains stuck behind its nonce until it is retried. No new
+	// batches are added until the pipeline has drained, while the batches
+	// already in flight are still awaited at their nonces.
+	draining bool
+
+	// ctx is the context under which the batches in flight are published.
+	ctx context.Context
+}
+
+// newPipeline initializes a pipeline allowing up to maxInFlight batches in
+// flight, published under ctx.
+func newPipeline(ctx context.Context, maxInFlight uint64) *pipeline {
+	return &pipeline{
+		maxInFlight: int(maxInFlight),
+		ctx:         ctx,
+	}
+}
+
+// Status returns the number of batches in flight and the height at which the
+// next batch should begin, given the start of the unconfirmed range reported
+// by the contract.
+func (p *pipeline) Status(start uint64) (int, uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	next := start
+	for _, batch := range p.inFlight {
+		if batch.end > next {
+			next = batch.end
+		}
+	}
+
+	return len(p.inFlight), next
+}
+
+// Batches returns the batches in flight, in the order they were added.
+func (p *pipeline) Batches() []inFlightBatch {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	batches := make([]inFlightBatch, 0, len(p.inFlight))
+	for _, batch := range p.inFlight {
+		batches = append(batches, *batch)
+	}
+
+	return batches
+}
+
+// Full returns true if no batch can be added until a batch in flight
+// concludes.
+func (p *pipeline) Full() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return len(p.inFlight) >= p.maxInFlight
+}
+
+// Draining returns true if the pipeline is draining after a failed batch.
+func (p *pipeline) Draining() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.draining
+}
+
+// Retry returns the failed batch with the lowest start to resubmit, given the
+// start of the unconfirmed range reported by the contract, or nil if none
+// remains. Failed batches confirmed in the meantime, e.g. as their abandoned
+// tx was mined after all, are discarded.
+func (p *pipeline) Retry(start uint64) *inFlightBatch {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for len(p.failed) > 0 && p.failed[0].end <= start {
+		p.failed = p.failed[1:]
+	}
+	p.checkDrained()
+
+	if len(p.failed) == 0 {
+		return nil
+	}
+	retry := *p.failed[0]
+
+	return &retry
+}
+
+// Add records batch as in flight, returning the context under which it should
+// be published. A failed batch beginning at the same height is no longer
+// retried.
+func (p *pipeline) Add(batch *inFlightBatch) context.Context {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for i, failed := range p.failed {
+		if failed.start == batch.start {
+			p.failed = append(p.failed[:i], p.failed[i+1:]...)
+			break
+		}
+	}
+	p.inFlight = append(p.inFlight, batch)
+
+	return p.ctx
+}
+
+// Remove stops tracking batch once its submission concludes. If the submission
+// failed, the batch is kept for Retry and the pipeline begins draining, while
+// every other batch in flight continues to be awaited.
+func (p *pipeline) Remove(batch *inFlightBatch, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.remove(batch, failed)
+}
+
+// Skip stops tracking batch, whose tx was never published, e.g. as it was
+// deferred, such that its range is rebuilt by the next batch added. This is
+// only possible if no batch in flight begins after it. Otherwise, batch is
+// kept for Retry as if it failed, returning false.
+func (p *pipeline) Skip(batch *inFlightBatch) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, inFlight := range p.inFlight {
+		if inFlight.start > batch.start {
+			p.remove(batch, true)
+			return false
+		}
+	}
+	p.remove(batch, false)
+
+	return true
+}
+
+// remove implements Remove.
+//
+// NOTE: This method MUST be called while holding p.mu.
+func (p *pipeline) remove(batch *inFlightBatch, failed bool) {
+	for i, inFlight := range p.inFlight {
+		if inFlight == batch {
+			p.inFlight = append(p.inFlight[:i], p.inFlight[i+1:]...)
+			break
+		}
+	}
+
+	if failed {
+		i := sort.Search(len(p.failed), func(i int) bool {
+			return p.failed[i].start >= batch.start
+		})
+		p.failed = append(p.failed, nil)
+		copy(p.failed[i+1:], p.failed[i:])
+		p.failed[i] = batch
+		p.draining = true
+	}
+	p.checkDrained()
+}
+
+// checkDrained resumes admitting new batches once no batch remains in flight
+// or awaiting a retry.
+//
+// NOTE: This method MUST be called while holding p.mu.
+func (p *pipeline) checkDrained() {
+	if p.draining && len(p.inFlight) == 0 && len(p.failed) == 0 {
+		p.draining = false
+	}
+}
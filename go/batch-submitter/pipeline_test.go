@@ -0,0 +1,123 @@
+package batchsubmitter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestPipelineNextStart asserts that each batch added to the pipeline begins
+// where the last batch in flight ends, and that the pipeline reports being
+// full once the max number of batches is in flight.
+func TestPipelineNextStart(t *testing.T) {
+	t.Parallel()
+
+	p := newPipeline(context.Background(), 2)
+
+	numInFlight, next := p.Status(10)
+	require.Equal(t, 0, numInFlight)
+	require.Equal(t, uint64(10), next)
+	require.False(t, p.Full())
+
+	first := &inFlightBatch{start: 10, end: 15, nonce: 0}
+	p.Add(first)
+
+	numInFlight, next = p.Status(10)
+	require.Equal(t, 1, numInFlight)
+	require.Equal(t, uint64(15), next)
+	require.False(t, p.Full())
+
+	p.Add(&inFlightBatch{start: 15, end: 20, nonce: 1})
+
+	numInFlight, next = p.Status(10)
+	require.Equal(t, 2, numInFlight)
+	require.Equal(t, uint64(20), next)
+	require.True(t, p.Full())
+
+	// Confirming the first batch frees a slot without changing the start
+	// of the next batch.
+	p.Remove(first, false)
+
+	numInFlight, next = p.Status(15)
+	require.Equal(t, 1, numInFlight)
+	require.Equal(t, uint64(20), next)
+	require.False(t, p.Full())
+}
+
+// TestPipelineDrainsAfterFailure asserts that a failed batch leaves every other
+// batch in flight awaited under the same context, that the failed batch is
+// retried until re-added, and that new batches are only admitted once drained.
+func TestPipelineDrainsAfterFailure(t *testing.T) {
+	t.Parallel()
+
+	p := newPipeline(context.Background(), 3)
+
+	first := &inFlightBatch{start: 10, end: 15, nonce: 0}
+	second := &inFlightBatch{start: 15, end: 20, nonce: 1}
+	ctx := p.Add(first)
+	require.Equal(t, ctx, p.Add(second))
+
+	p.Remove(first, true)
+	require.True(t, p.Draining())
+	require.NoError(t, ctx.Err())
+
+	numInFlight, next := p.Status(10)
+	require.Equal(t, 1, numInFlight)
+	require.Equal(t, uint64(20), next)
+
+	retry := p.Retry(10)
+	require.NotNil(t, retry)
+	require.Equal(t, *first, *retry)
+
+	// The failed batch is retried until re-added, even once the batches
+	// behind it conclude.
+	p.Remove(second, false)
+	require.True(t, p.Draining())
+	require.Equal(t, retry, p.Retry(10))
+
+	retried := &inFlightBatch{start: 10, end: 15, nonce: 0}
+	require.NoError(t, p.Add(retried).Err())
+	require.Nil(t, p.Retry(10))
+	require.True(t, p.Draining())
+
+	p.Remove(retried, false)
+	require.False(t, p.Draining())
+}
+
+// TestPipelineDiscardsConfirmedRetry asserts that a failed batch is no longer
+// retried once the contract has moved past it.
+func TestPipelineDiscardsConfirmedRetry(t *testing.T) {
+	t.Parallel()
+
+	p := newPipeline(context.Background(), 2)
+
+	first := &inFlightBatch{start: 10, end: 15, nonce: 0}
+	p.Add(first)
+	p.Remove(first, true)
+	require.True(t, p.Draining())
+
+	require.Nil(t, p.Retry(15))
+	require.False(t, p.Draining())
+}
+
+// TestPipelineSkip asserts that a batch that was never published is only
+// skipped if no batch in flight begins after it, and retried otherwise.
+func TestPipelineSkip(t *testing.T) {
+	t.Parallel()
+
+	p := newPipeline(context.Background(), 2)
+
+	first := &inFlightBatch{start: 10, end: 15, nonce: 0}
+	second := &inFlightBatch{start: 15, end: 20, nonce: 1}
+	p.Add(first)
+	p.Add(second)
+
+	require.True(t, p.Skip(second))
+	require.False(t, p.Draining())
+
+	p.Add(second)
+	require.False(t, p.Skip(first))
+	require.True(t, p.Draining())
+	require.Equal(t, *first, *p.Retry(10))
+}
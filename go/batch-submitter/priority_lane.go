@@ -0,0 +1,96 @@
+package batchsubmitter
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// PriorityLaneDriver is an optional interface that may be implemented by a
+// Driver whose batches append L1 queue elements, which must be included before
+// their force-inclusion deadline. Once the oldest pending queue element reaches
+// PriorityLaneAge, the priority lane preempts the normal pacing of submissions.
+type PriorityLaneDriver interface {
+	// OldestPendingQueueElement returns the L1 timestamp of the oldest
+	// queue element yet to be appended, or false if none is pending.
+	OldestPendingQueueElement(ctx context.Context) (time.Time, bool, error)
+
+	// GetPendingBlockRange is GetBatchBlockRange, without deferring pending
+	// ranges too small to be worth a batch tx.
+	GetPendingBlockRange(ctx context.Context) (*big.Int, *big.Int, error)
+}
+
+// checkPriorityLane returns true if the oldest pending queue element has
+// reached PriorityLaneAge, in which case the cycle submits the pending range
+// immediately at the elevated fee tier, regardless of its size or the market
+// gas price. A failure to query the queue is logged, and the cycle proceeds at
+// its normal pace.
+func (s *Service) checkPriorityLane(
+	ctx context.Context,
+	trace *CycleTrace,
+) bool {
+
+	if s.cfg.PriorityLaneAge == 0 {
+		return false
+	}
+	laneDriver, ok := s.cfg.Driver.(PriorityLaneDriver)
+	if !ok {
+		return false
+	}
+
+	name := s.cfg.Driver.Name()
+
+	var (
+		oldest  time.Time
+		pending bool
+	)
+	err := s.queryWithRetry(ctx, "queue_element", func(
+		ctx context.Context) error {
+
+		var err error
+		oldest, pending, err = laneDriver.OldestPendingQueueElement(ctx)
+		return err
+	})
+	if err != nil {
+		log.Warn(name+" unable to get oldest pending queue element",
+			"err", err)
+		return false
+	}
+	if !pending {
+		return false
+	}
+
+	age := time.Since(oldest)
+	if age < s.cfg.PriorityLaneAge {
+		trace.Step("queue_element", "age=%v", age.Truncate(time.Second))
+		return false
+	}
+
+	log.Warn(name+" queue element approaching inclusion deadline, "+
+		"activating priority lane", "age", age.Truncate(time.Second))
+	s.metrics.PriorityLaneActivations.Inc()
+	trace.Step("priority_lane", "age=%v", age.Truncate(time.Second))
+
+	return true
+}
+
+// pendingBlockRange returns the pending range of a Driver implementing
+// PriorityLaneDriver, including a range it would otherwise defer.
+func (s *Service) pendingBlockRange(
+	ctx context.Context) (*big.Int, *big.Int, error) {
+
+	laneDriver := s.cfg.Driver.(PriorityLaneDriver)
+
+	var start, end *big.Int
+	err := s.queryWithRetry(ctx, "block_range", func(
+		ctx context.Context) error {
+
+		var err error
+		start, end, err = laneDriver.GetPendingBlockRange(ctx)
+		return err
+	})
+
+	return start, end, err
+}
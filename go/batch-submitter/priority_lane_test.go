@@ -0,0 +1,70 @@
+package batchsubmitter
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// queueDriver is a driver whose oldest pending queue element, if any, was
+// enqueued at oldest.
+type queueDriver struct {
+	namedDriver
+	oldest *time.Time
+}
+
+func (d *queueDriver) OldestPendingQueueElement(
+	context.Context) (time.Time, bool, error) {
+
+	if d.oldest == nil {
+		return time.Time{}, false, nil
+	}
+	return *d.oldest, true, nil
+}
+
+func (d *queueDriver) GetPendingBlockRange(
+	context.Context) (*big.Int, *big.Int, error) {
+
+	return big.NewInt(0), big.NewInt(1), nil
+}
+
+// TestServiceCheckPriorityLane asserts that the priority lane only activates
+// once the oldest pending queue element reaches PriorityLaneAge, counting each
+// activation.
+func TestServiceCheckPriorityLane(t *testing.T) {
+	t.Parallel()
+
+	driver := &queueDriver{
+		namedDriver: namedDriver{name: "TestServiceCheckPriorityLane"},
+	}
+	s := newRetryTestService(context.Background(), driver.name,
+		RetryPolicy{})
+	s.cfg.Driver = driver
+
+	// Disabled unless PriorityLaneAge is set.
+	old := time.Now().Add(-time.Hour)
+	driver.oldest = &old
+	require.False(t, s.checkPriorityLane(s.ctx, newCycleTrace()))
+
+	s.cfg.PriorityLaneAge = 10 * time.Minute
+
+	driver.oldest = nil
+	require.False(t, s.checkPriorityLane(s.ctx, newCycleTrace()))
+
+	recent := time.Now().Add(-time.Minute)
+	driver.oldest = &recent
+	require.False(t, s.checkPriorityLane(s.ctx, newCycleTrace()))
+	require.Equal(t, 0.0,
+		testutil.ToFloat64(s.metrics.PriorityLaneActivations))
+
+	driver.oldest = &old
+	trace := newCycleTrace()
+	require.True(t, s.checkPriorityLane(s.ctx, trace))
+	require.Equal(t, "priority_lane", trace.Steps[0].Name)
+	require.Equal(t, 1.0,
+		testutil.ToFloat64(s.metrics.PriorityLaneActivations))
+}
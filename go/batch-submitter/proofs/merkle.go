@@ -0,0 +1,149 @@
+package proofs
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// maxTreeDepth is the depth of the deepest tree supported by Lib_MerkleTree,
+// which pads odd rows using one default hash per level.
+const maxTreeDepth = 16
+
+var (
+	// ErrNoLeaves signals that a tree was requested over no leaves.
+	ErrNoLeaves = errors.New("merkle tree must have at least one leaf")
+
+	// ErrTooManyLeaves signals that a tree was requested over more leaves
+	// than Lib_MerkleTree supports.
+	ErrTooManyLeaves = errors.New("merkle tree has too many leaves")
+
+	// ErrIndexOutOfRange signals that a proof was requested for a leaf
+	// that is not in the tree.
+	ErrIndexOutOfRange = errors.New("merkle leaf index out of range")
+)
+
+// defaultHashes are the hashes Lib_MerkleTree uses in place of the missing
+// right sibling of an odd row at each depth: the root of an all-zero subtree
+// of that depth, whose leaves hash a zero word.
+var defaultHashes = func() [maxTreeDepth]common.Hash {
+	var defaults [maxTreeDepth]common.Hash
+	defaults[0] = crypto.Keccak256Hash(common.Hash{}.Bytes())
+	for i := 1; i < maxTreeDepth; i++ {
+		defaults[i] = hashPair(defaults[i-1], defaults[i-1])
+	}
+	return defaults
+}()
+
+// hashPair hashes two sibling nodes into their parent.
+func hashPair(left, right common.Hash) common.Hash {
+	return crypto.Keccak256Hash(left.Bytes(), right.Bytes())
+}
+
+// nextRow hashes each pair of nodes in row into their parent at the given
+// depth, pairing a trailing node with the default hash of that depth.
+func nextRow(row []common.Hash, depth int) []common.Hash {
+	next := make([]common.Hash, 0, (len(row)+1)/2)
+	for i := 0; i < len(row); i += 2 {
+		if i+1 < len(row) {
+			next = append(next, hashPair(row[i], row[i+1]))
+		} else {
+			next = append(next, hashPair(row[i], defaultHashes[depth]))
+		}
+	}
+	return next
+}
+
+// checkLeaves ensures that a tree can be built over numLeaves leaves.
+func checkLeaves(numLeaves int) error {
+	switch {
+	case numLeaves == 0:
+		return ErrNoLeaves
+	case numLeaves > 1<<maxTreeDepth:
+		return ErrTooManyLeaves
+	default:
+		return nil
+	}
+}
+
+// Root computes the root of the tree over leaves, matching
+// Lib_MerkleTree.getMerkleRoot.
+func Root(leaves []common.Hash) (common.Hash, error) {
+	if err := checkLeaves(len(leaves)); err != nil {
+		return common.Hash{}, err
+	}
+
+	row := leaves
+	for depth := 0; len(row) > 1; depth++ {
+		row = nextRow(row, depth)
+	}
+
+	return row[0], nil
+}
+
+// Proof returns the siblings proving the inclusion of the leaf at index within
+// the tree over leaves, ordered from the leaf to the root, as expected by
+// Lib_MerkleTree.verify.
+func Proof(leaves []common.Hash, index uint64) ([]common.Hash, error) {
+	if err := checkLeaves(len(leaves)); err != nil {
+		return nil, err
+	}
+	if index >= uint64(len(leaves)) {
+		return nil, ErrIndexOutOfRange
+	}
+
+	siblings := []common.Hash{}
+	row := leaves
+	for depth := 0; len(row) > 1; depth++ {
+		sibling := index ^ 1
+		if sibling < uint64(len(row)) {
+			siblings = append(siblings, row[sibling])
+		} else {
+			siblings = append(siblings, defaultHashes[depth])
+		}
+
+		row = nextRow(row, depth)
+		index >>= 1
+	}
+
+	return siblings, nil
+}
+
+// Verify returns true if siblings prove the inclusion of leaf at index within
+// the tree of totalLeaves leaves committed to by root, matching
+// Lib_MerkleTree.verify.
+func Verify(
+	root, leaf common.Hash,
+	index uint64,
+	siblings []common.Hash,
+	totalLeaves uint64,
+) bool {
+
+	if totalLeaves == 0 || index >= totalLeaves ||
+		len(siblings) != ceilLog2(totalLeaves) {
+
+		return false
+	}
+
+	computed := leaf
+	for _, sibling := range siblings {
+		if index&1 == 1 {
+			computed = hashPair(sibling, computed)
+		} else {
+			computed = hashPair(computed, sibling)
+		}
+		index >>= 1
+	}
+
+	return computed == root
+}
+
+// ceilLog2 returns the smallest k such that 2^k >= n, for n > 0.
+func ceilLog2(n uint64) int {
+	var k int
+	for uint64(1)<<k < n {
+		k++
+	}
+	return k
+}
@@ -0,0 +1,127 @@
+package proofs_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/proofs"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// testLeaves returns n distinct leaves.
+func testLeaves(n int) []common.Hash {
+	leaves := make([]common.Hash, 0, n)
+	for i := 0; i < n; i++ {
+		leaves = append(leaves, crypto.Keccak256Hash([]byte{byte(i)}))
+	}
+	return leaves
+}
+
+// TestRootMatchesContract asserts that roots are padded using the default
+// hashes hardcoded in Lib_MerkleTree.
+func TestRootMatchesContract(t *testing.T) {
+	t.Parallel()
+
+	leaves := testLeaves(3)
+
+	root, err := proofs.Root(leaves[:1])
+	require.Nil(t, err)
+	require.Equal(t, leaves[0], root)
+
+	// The trailing leaf of a three leaf tree is paired with the first
+	// default hash, and the resulting row is even.
+	defaultHash := common.HexToHash(
+		"0x290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563",
+	)
+	expRoot := crypto.Keccak256Hash(
+		crypto.Keccak256(leaves[0].Bytes(), leaves[1].Bytes()),
+		crypto.Keccak256(leaves[2].Bytes(), defaultHash.Bytes()),
+	)
+	root, err = proofs.Root(leaves)
+	require.Nil(t, err)
+	require.Equal(t, expRoot, root)
+
+	// A five leaf tree is also padded at the second level.
+	defaultHash1 := common.HexToHash(
+		"0x633dc4d7da7256660a892f8f1604a44b5432649cc8ec5cb3ced4c4e6ac94dd1d",
+	)
+	leaves = testLeaves(5)
+	expRoot = crypto.Keccak256Hash(
+		crypto.Keccak256(
+			crypto.Keccak256(leaves[0].Bytes(), leaves[1].Bytes()),
+			crypto.Keccak256(leaves[2].Bytes(), leaves[3].Bytes()),
+		),
+		crypto.Keccak256(
+			crypto.Keccak256(leaves[4].Bytes(), defaultHash.Bytes()),
+			defaultHash1.Bytes(),
+		),
+	)
+	root, err = proofs.Root(leaves)
+	require.Nil(t, err)
+	require.Equal(t, expRoot, root)
+
+	_, err = proofs.Root(nil)
+	require.Equal(t, proofs.ErrNoLeaves, err)
+}
+
+// TestProofVerifies asserts that the proof of every leaf verifies against the
+// root for trees of various sizes, and that tampered proofs do not.
+func TestProofVerifies(t *testing.T) {
+	t.Parallel()
+
+	for _, n := range []int{1, 2, 3, 4, 5, 7, 8, 9, 16, 17, 100} {
+		n := n
+		t.Run(fmt.Sprintf("%d leaves", n), func(t *testing.T) {
+			t.Parallel()
+
+			leaves := testLeaves(n)
+			root, err := proofs.Root(leaves)
+			require.Nil(t, err)
+
+			for i, leaf := range leaves {
+				siblings, err := proofs.Proof(leaves, uint64(i))
+				require.Nil(t, err)
+				require.True(t, proofs.Verify(
+					root, leaf, uint64(i), siblings, uint64(n),
+				))
+
+				if len(siblings) > 0 {
+					siblings[0][0] ^= 0xff
+					require.False(t, proofs.Verify(
+						root, leaf, uint64(i), siblings,
+						uint64(n),
+					))
+				}
+			}
+
+			_, err = proofs.Proof(leaves, uint64(n))
+			require.Equal(t, proofs.ErrIndexOutOfRange, err)
+		})
+	}
+}
+
+// TestNewBatchProof asserts that batch proofs are only generated for elements
+// matching the batch root.
+func TestNewBatchProof(t *testing.T) {
+	t.Parallel()
+
+	leaves := testLeaves(5)
+	root, err := proofs.Root(leaves)
+	require.Nil(t, err)
+
+	header := proofs.BatchHeader{
+		BatchIndex:        7,
+		BatchRoot:         root,
+		BatchSize:         5,
+		PrevTotalElements: 100,
+	}
+	proof, err := proofs.NewBatchProof(header, leaves)
+	require.Nil(t, err)
+	require.Len(t, proof.Elements, 5)
+	require.True(t, proof.Verify())
+
+	_, err = proofs.NewBatchProof(header, leaves[:4])
+	require.Equal(t, proofs.ErrRootMismatch, err)
+}
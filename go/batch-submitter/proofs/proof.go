@@ -0,0 +1,91 @@
+package proofs
+
+import (
+	"errors"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ErrRootMismatch signals that the elements of a batch do not hash to the
+// batch root committed on L1.
+var ErrRootMismatch = errors.New("batch elements do not match batch root")
+
+// BatchHeader is the header of a batch committed on L1, as emitted when the
+// batch was appended and passed to the contract's verification methods.
+type BatchHeader struct {
+	BatchIndex        uint64        `json:"batch_index"`
+	BatchRoot         common.Hash   `json:"batch_root"`
+	BatchSize         uint64        `json:"batch_size"`
+	PrevTotalElements uint64        `json:"prev_total_elements"`
+	ExtraData         hexutil.Bytes `json:"extra_data"`
+}
+
+// ElementProof proves the inclusion of a single element within its batch.
+type ElementProof struct {
+	// Index is the position of the element within its batch.
+	Index uint64 `json:"index"`
+
+	// Element is the leaf committed to by the batch root.
+	Element common.Hash `json:"element"`
+
+	// Siblings are the merkle siblings of the element, ordered from the
+	// leaf to the root.
+	Siblings []common.Hash `json:"siblings"`
+}
+
+// BatchProof proves the inclusion of every element of a confirmed batch.
+type BatchProof struct {
+	Header      BatchHeader    `json:"header"`
+	TxHash      common.Hash    `json:"tx_hash"`
+	BlockNumber uint64         `json:"block_number"`
+	Elements    []ElementProof `json:"elements"`
+}
+
+// NewBatchProof generates an inclusion proof for each of elements within the
+// batch described by header, ensuring that they hash to its batch root.
+func NewBatchProof(
+	header BatchHeader,
+	elements []common.Hash,
+) (*BatchProof, error) {
+
+	root, err := Root(elements)
+	if err != nil {
+		return nil, err
+	}
+	if root != header.BatchRoot ||
+		uint64(len(elements)) != header.BatchSize {
+
+		return nil, ErrRootMismatch
+	}
+
+	proof := &BatchProof{
+		Header:   header,
+		Elements: make([]ElementProof, 0, len(elements)),
+	}
+	for i, element := range elements {
+		siblings, err := Proof(elements, uint64(i))
+		if err != nil {
+			return nil, err
+		}
+		proof.Elements = append(proof.Elements, ElementProof{
+			Index:    uint64(i),
+			Element:  element,
+			Siblings: siblings,
+		})
+	}
+
+	return proof, nil
+}
+
+// Verify returns true if each element proof is valid against the batch root.
+func (p *BatchProof) Verify() bool {
+	for _, element := range p.Elements {
+		if !Verify(p.Header.BatchRoot, element.Element, element.Index,
+			element.Siblings, p.Header.BatchSize) {
+
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,193 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// adminQuarantinePath is the path at which quarantined ranges are released by
+// the admin server.
+const adminQuarantinePath = "/admin/quarantine"
+
+// ErrNoQuarantinedRange signals an attempt to release a quarantined range when
+// none is quarantined.
+var ErrNoQuarantinedRange = errors.New("no range is quarantined")
+
+// ErrUnknownQuarantineAction signals an attempt to release a quarantined range
+// with an unsupported action.
+var ErrUnknownQuarantineAction = errors.New("quarantine action must be " +
+	"force or skip")
+
+// QuarantineAction is an operator's decision on how to release a quarantined
+// range.
+type QuarantineAction string
+
+const (
+	// QuarantineForce retries the quarantined range as-is, with a fresh
+	// retry budget.
+	QuarantineForce QuarantineAction = "force"
+
+	// QuarantineSkip discards any batches queued for the quarantined range,
+	// such that it is rebuilt from L2 with a fresh retry budget.
+	//
+	// NOTE: The contracts only accept contiguous ranges, so the L2 blocks
+	// themselves can never be skipped.
+	QuarantineSkip QuarantineAction = "skip"
+)
+
+// QuarantinedRange describes an L2 range held back from submission after
+// exhausting its retry budget.
+type QuarantinedRange struct {
+	// Start and End bound the last attempted range, [Start, End).
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	// Attempts is the number of consecutive failed submissions of the
+	// range.
+	Attempts uint64 `json:"attempts"`
+
+	// LastError is the error of the last failed submission.
+	LastError string `json:"last_error"`
+
+	// QuarantinedAt is the time at which the range was quarantined.
+	QuarantinedAt time.Time `json:"quarantined_at"`
+}
+
+// retryBudget counts the consecutive failed submissions of the range beginning
+// at a given height, quarantining the range once maxAttempts is reached. A
+// maxAttempts of zero never quarantines.
+//
+// NOTE: retryBudget is safe for concurrent use.
+type retryBudget struct {
+	mu          sync.Mutex
+	maxAttempts uint64
+	start       uint64
+	attempts    uint64
+	quarantined *QuarantinedRange
+}
+
+// newRetryBudget initializes a retryBudget allowing maxAttempts consecutive
+// failures per range.
+func newRetryBudget(maxAttempts uint64) *retryBudget {
+	return &retryBudget{
+		maxAttempts: maxAttempts,
+	}
+}
+
+// Fail records a failed submission of [start, end), returning the quarantined
+// range if the failure exhausted its retry budget.
+func (b *retryBudget) Fail(start, end uint64, err error) *QuarantinedRange {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.quarantined != nil {
+		return nil
+	}
+
+	if start != b.start {
+		b.start = start
+		b.attempts = 0
+	}
+	b.attempts++
+
+	if b.maxAttempts == 0 || b.attempts < b.maxAttempts {
+		return nil
+	}
+
+	b.quarantined = &QuarantinedRange{
+		Start:         start,
+		End:           end,
+		Attempts:      b.attempts,
+		LastError:     err.Error(),
+		QuarantinedAt: time.Now(),
+	}
+	quarantined := *b.quarantined
+
+	return &quarantined
+}
+
+// Reset clears the failures recorded for the range beginning at start after it
+// was submitted.
+func (b *retryBudget) Reset(start uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if start == b.start && b.quarantined == nil {
+		b.attempts = 0
+	}
+}
+
+// Quarantined returns the quarantined range, or nil if none is quarantined.
+func (b *retryBudget) Quarantined() *QuarantinedRange {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.quarantined == nil {
+		return nil
+	}
+	quarantined := *b.quarantined
+
+	return &quarantined
+}
+
+// Release lifts the quarantine with a fresh retry budget, returning the
+// released range.
+func (b *retryBudget) Release() (*QuarantinedRange, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.quarantined == nil {
+		return nil, ErrNoQuarantinedRange
+	}
+	released := b.quarantined
+	b.quarantined = nil
+	b.attempts = 0
+
+	return released, nil
+}
+
+// quarantineHandler releases the quarantined ranges of the services in a
+// registry.
+type quarantineHandler struct {
+	registry *statusRegistry
+}
+
+// ServeHTTP releases the quarantined range of the service named by the service
+// form value, using the action form value. The released range is returned as
+// JSON.
+func (h quarantineHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	s, ok := h.registry.lookup(req.FormValue("service"))
+	if !ok {
+		http.Error(w, "unknown service", http.StatusNotFound)
+		return
+	}
+
+	action := QuarantineAction(req.FormValue("action"))
+	released, err := s.ReleaseQuarantine(action)
+	switch {
+	case errors.Is(err, ErrUnknownQuarantineAction):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, ErrNoQuarantinedRange):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(released); err != nil {
+		log.Error("Unable to write quarantine response", "err", err)
+	}
+}
@@ -0,0 +1,68 @@
+package batchsubmitter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestRetryBudgetQuarantine asserts that a range is quarantined once it fails
+// maxAttempts consecutive times, that failures of a different range reset the
+// count, and that releasing the quarantine restores a fresh budget.
+func TestRetryBudgetQuarantine(t *testing.T) {
+	t.Parallel()
+
+	errSubmit := errors.New("submit failed")
+	budget := newRetryBudget(3)
+
+	require.Nil(t, budget.Fail(10, 20, errSubmit))
+	require.Nil(t, budget.Fail(10, 20, errSubmit))
+
+	// A failure of another range starts a new count.
+	require.Nil(t, budget.Fail(15, 20, errSubmit))
+	require.Nil(t, budget.Fail(10, 20, errSubmit))
+	require.Nil(t, budget.Fail(10, 20, errSubmit))
+	require.Nil(t, budget.Quarantined())
+
+	quarantined := budget.Fail(10, 25, errSubmit)
+	require.NotNil(t, quarantined)
+	require.Equal(t, uint64(10), quarantined.Start)
+	require.Equal(t, uint64(25), quarantined.End)
+	require.Equal(t, uint64(3), quarantined.Attempts)
+	require.Equal(t, errSubmit.Error(), quarantined.LastError)
+	require.Equal(t, quarantined, budget.Quarantined())
+
+	// Further failures are not charged while quarantined.
+	require.Nil(t, budget.Fail(10, 25, errSubmit))
+
+	released, err := budget.Release()
+	require.Nil(t, err)
+	require.Equal(t, quarantined, released)
+	require.Nil(t, budget.Quarantined())
+
+	_, err = budget.Release()
+	require.Equal(t, ErrNoQuarantinedRange, err)
+
+	require.Nil(t, budget.Fail(10, 25, errSubmit))
+}
+
+// TestRetryBudgetReset asserts that a successful submission clears the
+// failures charged to its range, and that a zero budget never quarantines.
+func TestRetryBudgetReset(t *testing.T) {
+	t.Parallel()
+
+	errSubmit := errors.New("submit failed")
+
+	budget := newRetryBudget(2)
+	require.Nil(t, budget.Fail(10, 20, errSubmit))
+	budget.Reset(10)
+	require.Nil(t, budget.Fail(10, 20, errSubmit))
+	require.NotNil(t, budget.Fail(10, 20, errSubmit))
+
+	unlimited := newRetryBudget(0)
+	for i := 0; i < 100; i++ {
+		require.Nil(t, unlimited.Fail(10, 20, errSubmit))
+	}
+	require.Nil(t, unlimited.Quarantined())
+}
@@ -0,0 +1,167 @@
+package queue
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltOpenTimeout is the duration for which opening a bolt database waits on
+// another process holding its file lock.
+const boltOpenTimeout = 5 * time.Second
+
+// ErrBoltLocked signals that a bolt database is in use by another process.
+var ErrBoltLocked = errors.New("bolt database is locked")
+
+var (
+	// boltDBsMu guards boltDBs.
+	boltDBsMu sync.Mutex
+
+	// boltDBs tracks the bolt databases open within this process, keyed by
+	// absolute path. A database file may only be opened once per process,
+	// so every backend on the same file shares a single handle.
+	boltDBs = make(map[string]*sharedBoltDB)
+)
+
+// sharedBoltDB is a bolt database shared by the backends of every namespace
+// stored in its file, closed once the last backend is closed.
+type sharedBoltDB struct {
+	db   *bolt.DB
+	refs int
+}
+
+// boltBackend is a Backend storing the records of a namespace in a bucket of a
+// bolt database.
+type boltBackend struct {
+	path      string
+	db        *bolt.DB
+	bucket    []byte
+	closeOnce sync.Once
+}
+
+// openBoltBackend opens the bucket named namespace in the bolt database at
+// path, creating both if they do not already exist.
+func openBoltBackend(path, namespace string) (*boltBackend, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	boltDBsMu.Lock()
+	defer boltDBsMu.Unlock()
+
+	shared, ok := boltDBs[path]
+	if !ok {
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return nil, err
+		}
+		db, err := bolt.Open(path, 0600, &bolt.Options{
+			Timeout: boltOpenTimeout,
+		})
+		if errors.Is(err, bolt.ErrTimeout) {
+			return nil, fmt.Errorf("%w: %s", ErrBoltLocked, path)
+		} else if err != nil {
+			return nil, err
+		}
+		shared = &sharedBoltDB{db: db}
+		boltDBs[path] = shared
+	}
+
+	bucket := []byte(namespace)
+	err = shared.db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucket)
+		return err
+	})
+	if err != nil {
+		if shared.refs == 0 {
+			delete(boltDBs, path)
+			shared.db.Close()
+		}
+		return nil, err
+	}
+	shared.refs++
+
+	return &boltBackend{
+		path:   path,
+		db:     shared.db,
+		bucket: bucket,
+	}, nil
+}
+
+// Put writes value at key within a single transaction.
+func (b *boltBackend) Put(key string, value []byte) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Put([]byte(key), value)
+	})
+}
+
+// Get returns the value at key.
+func (b *boltBackend) Get(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		v := tx.Bucket(b.bucket).Get([]byte(key))
+		if v == nil {
+			return ErrNotFound
+		}
+		// Values are only valid for the life of the transaction.
+		value = append([]byte{}, v...)
+		return nil
+	})
+
+	return value, err
+}
+
+// Delete removes the value at key, if any.
+func (b *boltBackend) Delete(key string) error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).Delete([]byte(key))
+	})
+}
+
+// Keys returns the key of every stored value in ascending order, the order in
+// which bolt iterates a bucket.
+func (b *boltBackend) Keys() ([]string, error) {
+	var keys []string
+	err := b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(b.bucket).ForEach(func(k, _ []byte) error {
+			keys = append(keys, string(k))
+			return nil
+		})
+	})
+
+	return keys, err
+}
+
+// CheckWritable verifies that the database accepts writes.
+func (b *boltBackend) CheckWritable() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		if tx.Bucket(b.bucket) == nil {
+			return fmt.Errorf("bucket %s not found", b.bucket)
+		}
+		return nil
+	})
+}
+
+// Close releases the backend's reference to the database, closing it once no
+// backend refers to it.
+func (b *boltBackend) Close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		boltDBsMu.Lock()
+		defer boltDBsMu.Unlock()
+
+		shared := boltDBs[b.path]
+		shared.refs--
+		if shared.refs == 0 {
+			delete(boltDBs, b.path)
+			err = shared.db.Close()
+		}
+	})
+
+	return err
+}
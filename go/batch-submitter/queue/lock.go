@@ -0,0 +1,444 @@
+package queue
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+const (
+	// lockFileName is the name of the file within the queue directory that
+	// records the process currently using the queue.
+	lockFileName = "LOCK"
+
+	// DefaultStaleLockTimeout is the duration after which a lock whose
+	// heartbeat has not been refreshed is considered stale.
+	DefaultStaleLockTimeout = time.Minute
+)
+
+// ErrQueueLocked signals that the queue directory is in use by another live
+// process, or by another queue within this process.
+var ErrQueueLocked = errors.New("submission queue is locked")
+
+// ErrQueueLockLost signals that the lock over a queue directory was broken by
+// another process, e.g. as its heartbeat went stale, and is no longer held.
+var ErrQueueLockLost = errors.New("submission queue lock lost")
+
+var (
+	// heldLocksMu guards heldLocks.
+	heldLocksMu sync.Mutex
+
+	// heldLocks tracks the lock files held within this process, so that a
+	// lock file bearing our own PID can be distinguished from one left
+	// behind by a previous process that was assigned the same PID.
+	heldLocks = make(map[string]struct{})
+)
+
+// lockInfo is the content of the lock file.
+type lockInfo struct {
+	// PID is the process ID of the lock holder.
+	PID int `json:"pid"`
+
+	// Hostname is the host on which the lock holder is running. The PID is
+	// only meaningful on the same host.
+	Hostname string `json:"hostname"`
+
+	// Heartbeat is the last time the lock holder refreshed the lock.
+	Heartbeat time.Time `json:"heartbeat"`
+
+	// Token identifies the lock holder, such that it only ever refreshes
+	// or releases its own lock.
+	Token string `json:"token"`
+}
+
+// dirLock is an advisory lock over a queue directory. The holder periodically
+// refreshes a heartbeat in the lock file, such that a lock left behind by a
+// crashed process can be detected as stale and broken safely. Once the lock is
+// found broken by another process, lost is closed and lostErr is returned by
+// err.
+type dirLock struct {
+	path         string
+	staleTimeout time.Duration
+	info         lockInfo
+
+	lost    chan struct{}
+	lostErr error
+
+	quit        chan struct{}
+	done        chan struct{}
+	releaseOnce sync.Once
+}
+
+// acquireDirLock takes the lock over dir, breaking any existing lock that is
+// stale. A lock is stale if its holder ran on this host and is no longer
+// alive, or if its heartbeat is older than staleTimeout.
+func acquireDirLock(dir string, staleTimeout time.Duration) (*dirLock, error) {
+	if staleTimeout == 0 {
+		staleTimeout = DefaultStaleLockTimeout
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := filepath.Abs(filepath.Join(dir, lockFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	heldLocksMu.Lock()
+	defer heldLocksMu.Unlock()
+
+	if _, ok := heldLocks[path]; ok {
+		return nil, fmt.Errorf("%w: held by this process", ErrQueueLocked)
+	}
+
+	token, err := newLockToken()
+	if err != nil {
+		return nil, err
+	}
+
+	l := &dirLock{
+		path:         path,
+		staleTimeout: staleTimeout,
+		info: lockInfo{
+			PID:      os.Getpid(),
+			Hostname: hostname,
+			Token:    token,
+		},
+		lost: make(chan struct{}),
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	data, err := ioutil.ReadFile(l.path)
+	switch {
+	case os.IsNotExist(err):
+
+	case err != nil:
+		return nil, err
+
+	default:
+		// A corrupt lock file can only result from a crash mid-write,
+		// as the lock is always written atomically otherwise.
+		var existing lockInfo
+		if err := json.Unmarshal(data, &existing); err != nil {
+			log.Warn("Breaking unreadable submission queue lock",
+				"path", l.path, "err", err)
+		} else {
+			if !l.isStale(&existing) {
+				return nil, fmt.Errorf("%w: pid=%d hostname=%s "+
+					"heartbeat=%v", ErrQueueLocked, existing.PID,
+					existing.Hostname, existing.Heartbeat)
+			}
+			log.Warn("Breaking stale submission queue lock",
+				"path", l.path, "pid", existing.PID,
+				"hostname", existing.Hostname,
+				"heartbeat", existing.Heartbeat,
+				"new_pid", l.info.PID)
+		}
+
+		if err := l.breakLock(data); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := l.create(); err != nil {
+		return nil, err
+	}
+
+	heldLocks[path] = struct{}{}
+	go l.heartbeat()
+
+	return l, nil
+}
+
+// isStale returns true if the lock described by info can be broken.
+func (l *dirLock) isStale(info *lockInfo) bool {
+	if info.Hostname == l.info.Hostname {
+		// Since locks held within this process are rejected before
+		// reaching this point, a lock bearing our own PID must have
+		// been left behind by a previous process, e.g. a container
+		// that crashed and was restarted with the same PID.
+		if info.PID == l.info.PID || !processAlive(info.PID) {
+			return true
+		}
+	}
+
+	return time.Since(info.Heartbeat) > l.staleTimeout
+}
+
+// err returns an error wrapping ErrQueueLockLost if the lock was lost, or nil
+// otherwise.
+func (l *dirLock) err() error {
+	select {
+	case <-l.lost:
+		return l.lostErr
+	default:
+		return nil
+	}
+}
+
+// heartbeat refreshes the lock file until the lock is released or lost.
+func (l *dirLock) heartbeat() {
+	defer close(l.done)
+
+	ticker := time.NewTicker(l.staleTimeout / 4)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := l.refresh()
+			if errors.Is(err, ErrQueueLockLost) {
+				log.Error("Submission queue lock lost",
+					"path", l.path, "err", err)
+				l.lostErr = err
+				close(l.lost)
+				return
+			}
+			if err != nil {
+				log.Error("Unable to refresh submission queue lock",
+					"path", l.path, "err", err)
+			}
+		case <-l.quit:
+			return
+		}
+	}
+}
+
+// create atomically creates the lock file, failing with ErrQueueLocked if
+// another process created it first. The lock is written in full to a temporary
+// file that is then hard linked into place, such that it is never observed
+// partially written.
+func (l *dirLock) create() error {
+	l.info.Heartbeat = time.Now()
+
+	tmpName, err := l.writeTemp()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName)
+
+	err = os.Link(tmpName, l.path)
+	if os.IsExist(err) {
+		return fmt.Errorf("%w: acquired concurrently", ErrQueueLocked)
+	}
+
+	return err
+}
+
+// breakLock removes the stale lock file whose content is data. The lock file
+// is first moved aside and compared against data, such that a fresh lock
+// created by another process in the meantime is restored rather than broken.
+func (l *dirLock) breakLock(data []byte) error {
+	brokenName := l.path + ".broken-" + l.info.Token
+	err := os.Rename(l.path, brokenName)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer os.Remove(brokenName)
+
+	broken, err := ioutil.ReadFile(brokenName)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(broken, data) {
+		if err := l.restore(brokenName); err != nil {
+			return err
+		}
+		return fmt.Errorf("%w: acquired concurrently", ErrQueueLocked)
+	}
+
+	return nil
+}
+
+// restore links the lock file moved aside to name back into place, unless
+// another lock file was created in its place since.
+func (l *dirLock) restore(name string) error {
+	err := os.Link(name, l.path)
+	if os.IsExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// refresh rewrites the lock file with the current time, returning
+// ErrQueueLockLost if the lock file no longer bears our token. As in
+// breakLock, the lock file is first moved aside and compared against our
+// token, such that a lock created by another process after breaking ours is
+// restored rather than overwritten. The refreshed lock is then linked into
+// place as in create, such that a lock acquired by another process while ours
+// was moved aside is never overwritten either.
+func (l *dirLock) refresh() error {
+	l.info.Heartbeat = time.Now()
+
+	tmpName, err := l.writeTemp()
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpName)
+
+	asideName := l.path + ".refresh-" + l.info.Token
+	err = os.Rename(l.path, asideName)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: lock file removed", ErrQueueLockLost)
+	}
+	if err != nil {
+		return err
+	}
+	defer os.Remove(asideName)
+
+	current, err := readLockInfo(asideName)
+	if err == nil && current.Token != l.info.Token {
+		err = fmt.Errorf("%w: now held by pid=%d hostname=%s",
+			ErrQueueLockLost, current.PID, current.Hostname)
+	}
+	if err != nil {
+		if restoreErr := l.restore(asideName); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+
+	err = os.Link(tmpName, l.path)
+	if os.IsExist(err) {
+		return fmt.Errorf("%w: acquired concurrently", ErrQueueLockLost)
+	}
+	if err != nil {
+		// Our previous lock remains as valid as before.
+		if restoreErr := l.restore(asideName); restoreErr != nil {
+			return restoreErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkOwner returns ErrQueueLockLost unless the lock file bears our token.
+func (l *dirLock) checkOwner() error {
+	current, err := readLockInfo(l.path)
+	if os.IsNotExist(err) {
+		return fmt.Errorf("%w: lock file removed", ErrQueueLockLost)
+	}
+	if err != nil {
+		return err
+	}
+	if current.Token != l.info.Token {
+		return fmt.Errorf("%w: now held by pid=%d hostname=%s",
+			ErrQueueLockLost, current.PID, current.Hostname)
+	}
+
+	return nil
+}
+
+// writeTemp writes the lock's info to a temporary file next to the lock file,
+// returning its name.
+func (l *dirLock) writeTemp() (string, error) {
+	data, err := json.Marshal(l.info)
+	if err != nil {
+		return "", err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(l.path), "tmp-")
+	if err != nil {
+		return "", err
+	}
+	tmpName := tmpFile.Name()
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpName)
+		return "", err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpName)
+		return "", err
+	}
+
+	return tmpName, nil
+}
+
+// release stops the heartbeat and removes the lock file, unless it was since
+// broken by another process. Releasing the lock more than once is a no-op.
+func (l *dirLock) release() error {
+	var err error
+	l.releaseOnce.Do(func() {
+		close(l.quit)
+		<-l.done
+
+		err = l.checkOwner()
+		switch {
+		case errors.Is(err, ErrQueueLockLost):
+			log.Warn("Submission queue lock lost before release",
+				"path", l.path, "err", err)
+			err = nil
+		case err == nil:
+			err = os.Remove(l.path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		}
+
+		heldLocksMu.Lock()
+		delete(heldLocks, l.path)
+		heldLocksMu.Unlock()
+	})
+	return err
+}
+
+// readLockInfo reads the lock file at path.
+func readLockInfo(path string) (*lockInfo, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var info lockInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, err
+	}
+
+	return &info, nil
+}
+
+// newLockToken returns a random token identifying a lock holder.
+func newLockToken() (string, error) {
+	var token [16]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(token[:]), nil
+}
+
+// processAlive returns true if a process with the given PID is running on this
+// host.
+func processAlive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+
+	// Signal 0 performs error checking only. EPERM indicates the process
+	// exists but belongs to another user.
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
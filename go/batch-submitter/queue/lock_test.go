@@ -0,0 +1,162 @@
+package queue_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// writeLockFile writes a lock file to dir as if held by the given process.
+func writeLockFile(t *testing.T, dir string, pid int, hostname string,
+	heartbeat time.Time) {
+
+	data, err := json.Marshal(map[string]interface{}{
+		"pid":       pid,
+		"hostname":  hostname,
+		"heartbeat": heartbeat,
+	})
+	require.Nil(t, err)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "LOCK"), data, 0600)
+	require.Nil(t, err)
+}
+
+// TestSubmissionQueueLock asserts that a queue directory cannot be opened
+// while locked by a live holder, and that stale locks are broken.
+func TestSubmissionQueueLock(t *testing.T) {
+	t.Parallel()
+
+	hostname, err := os.Hostname()
+	require.Nil(t, err)
+
+	// Use our parent's PID as a live process other than our own, and a
+	// PID above the kernel's maximum as a dead process.
+	livePID := os.Getppid()
+	const deadPID = 1 << 30
+
+	tests := []struct {
+		name      string
+		pid       int
+		hostname  string
+		heartbeat time.Time
+		expLocked bool
+	}{
+		{
+			name:      "live process",
+			pid:       livePID,
+			hostname:  hostname,
+			heartbeat: time.Now(),
+			expLocked: true,
+		},
+		{
+			name:      "dead process",
+			pid:       deadPID,
+			hostname:  hostname,
+			heartbeat: time.Now(),
+		},
+		{
+			name:      "own pid from previous process",
+			pid:       os.Getpid(),
+			hostname:  hostname,
+			heartbeat: time.Now(),
+		},
+		{
+			name:      "other host with fresh heartbeat",
+			pid:       livePID,
+			hostname:  "other-host",
+			heartbeat: time.Now(),
+			expLocked: true,
+		},
+		{
+			name:      "other host with expired heartbeat",
+			pid:       livePID,
+			hostname:  "other-host",
+			heartbeat: time.Now().Add(-2 * time.Minute),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "submission-queue-lock")
+			require.Nil(t, err)
+			defer os.RemoveAll(dir)
+
+			writeLockFile(t, dir, test.pid, test.hostname,
+				test.heartbeat)
+
+			q, err := queue.NewSubmissionQueue(dir, time.Minute)
+			if test.expLocked {
+				require.True(t, errors.Is(err, queue.ErrQueueLocked))
+				return
+			}
+			require.Nil(t, err)
+			require.Nil(t, q.Close())
+
+			_, err = os.Stat(filepath.Join(dir, "LOCK"))
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+// TestSubmissionQueueLockWithinProcess asserts that a queue directory can only
+// be opened once within a process until it is closed.
+func TestSubmissionQueueLockWithinProcess(t *testing.T) {
+	t.Parallel()
+
+	q, dir := newTestQueue(t)
+
+	_, err := queue.NewSubmissionQueue(dir, 0)
+	require.True(t, errors.Is(err, queue.ErrQueueLocked))
+
+	require.Nil(t, q.Close())
+
+	reopened, err := queue.NewSubmissionQueue(dir, 0)
+	require.Nil(t, err)
+	require.Nil(t, reopened.Close())
+}
+
+// TestSubmissionQueueLockLost asserts that a lock broken by another process is
+// neither refreshed nor removed by its former holder, and that the queue fails
+// every operation from then on.
+func TestSubmissionQueueLockLost(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	q, err := queue.NewSubmissionQueue(dir, 40*time.Millisecond)
+	require.Nil(t, err)
+
+	// Another host breaks the lock once its heartbeat seems stale.
+	heartbeat := time.Now().Add(time.Hour).Truncate(time.Second)
+	writeLockFile(t, dir, os.Getppid(), "other-host", heartbeat)
+
+	// Wait out several heartbeats of the former holder.
+	time.Sleep(100 * time.Millisecond)
+	select {
+	case <-q.LockLost():
+	default:
+		t.Fatal("lock loss not signaled")
+	}
+	err = q.Push(&queue.Batch{Start: 0, End: 1})
+	require.True(t, errors.Is(err, queue.ErrQueueLockLost))
+	_, err = q.Len()
+	require.True(t, errors.Is(err, queue.ErrQueueLockLost))
+	require.Nil(t, q.Close())
+
+	data, err := ioutil.ReadFile(filepath.Join(dir, "LOCK"))
+	require.Nil(t, err)
+	var info struct {
+		Hostname  string    `json:"hostname"`
+		Heartbeat time.Time `json:"heartbeat"`
+	}
+	require.Nil(t, json.Unmarshal(data, &info))
+	require.Equal(t, "other-host", info.Hostname)
+	require.True(t, heartbeat.Equal(info.Heartbeat))
+}
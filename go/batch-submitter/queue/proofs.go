@@ -0,0 +1,83 @@
+package queue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/proofs"
+)
+
+// proofFileExt is the file extension used for each persisted batch proof.
+const proofFileExt = ".proof.json"
+
+// ProofStore persists the inclusion proofs of confirmed batches within a
+// directory, one file per batch index, for consumption by downstream
+// verifiers. Proofs are written atomically and never pruned.
+//
+// NOTE: ProofStore is safe for concurrent use.
+type ProofStore struct {
+	mu  sync.Mutex
+	dir string
+}
+
+// NewProofStore opens the proof store in dir, creating the directory if it
+// does not exist.
+func NewProofStore(dir string) (*ProofStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &ProofStore{
+		dir: dir,
+	}, nil
+}
+
+// Dir returns the directory in which proofs are stored.
+func (s *ProofStore) Dir() string {
+	return s.dir
+}
+
+// proofPath returns the path of the proof of the batch at batchIndex. Batch
+// indexes are zero-padded, such that files sort by batch index.
+func (s *ProofStore) proofPath(batchIndex uint64) string {
+	return filepath.Join(s.dir, fmt.Sprintf("%020d%s", batchIndex,
+		proofFileExt))
+}
+
+// Put writes proof, replacing any proof of the same batch index.
+func (s *ProofStore) Put(proof *proofs.BatchProof) error {
+	data, err := json.MarshalIndent(proof, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return writeFileAtomic(s.dir, s.proofPath(proof.Header.BatchIndex),
+		data)
+}
+
+// Get returns the proof of the batch at batchIndex, or nil if none is stored.
+func (s *ProofStore) Get(batchIndex uint64) (*proofs.BatchProof, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := ioutil.ReadFile(s.proofPath(batchIndex))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var proof proofs.BatchProof
+	if err := json.Unmarshal(data, &proof); err != nil {
+		return nil, err
+	}
+
+	return &proof, nil
+}
@@ -0,0 +1,51 @@
+package queue_test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/proofs"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// TestProofStore asserts that proofs are keyed by batch index and survive
+// reopening the store.
+func TestProofStore(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "proof-store")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	s, err := queue.NewProofStore(dir)
+	require.Nil(t, err)
+
+	proof, err := s.Get(3)
+	require.Nil(t, err)
+	require.Nil(t, proof)
+
+	leaves := []common.Hash{{0x01}, {0x02}, {0x03}}
+	root, err := proofs.Root(leaves)
+	require.Nil(t, err)
+	expProof, err := proofs.NewBatchProof(proofs.BatchHeader{
+		BatchIndex: 3,
+		BatchRoot:  root,
+		BatchSize:  3,
+		ExtraData:  []byte{0xde, 0xad},
+	}, leaves)
+	require.Nil(t, err)
+	expProof.TxHash = common.Hash{0xaa}
+	expProof.BlockNumber = 42
+	require.Nil(t, s.Put(expProof))
+
+	s, err = queue.NewProofStore(dir)
+	require.Nil(t, err)
+
+	proof, err = s.Get(3)
+	require.Nil(t, err)
+	require.Equal(t, expProof, proof)
+	require.True(t, proof.Verify())
+}
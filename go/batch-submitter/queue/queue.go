@@ -0,0 +1,354 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// batchFileExt is the file extension used for each persisted batch.
+	batchFileExt = ".json"
+
+	// probeFileName is the name of the file written to check that a
+	// directory is writable.
+	probeFileName = "PROBE"
+)
+
+// ErrNonContiguousBatch signals an attempt to push a batch whose start does not
+// match the end of the last queued batch.
+var ErrNonContiguousBatch = errors.New("batch does not extend queue tail")
+
+// Batch is a fully built batch payload that has not yet been confirmed on L1.
+type Batch struct {
+	// Start is the first L2 block height (inclusive) covered by the batch.
+	Start uint64 `json:"start"`
+
+	// End is the last L2 block height (exclusive) covered by the batch.
+	End uint64 `json:"end"`
+
+	// CallData is the serialized calldata of the batch tx.
+	CallData hexutil.Bytes `json:"call_data"`
+
+	// EndBlockHash is the hash of the last L2 block covered by the batch,
+	// at height End-1, pinning the chain the batch was built from.
+	EndBlockHash common.Hash `json:"end_block_hash,omitempty"`
+
+	// CreatedAt is the time at which the batch was built.
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// SubmissionQueue is a durable FIFO of built batches. Each batch is persisted
+// as an individual file within the queue's directory, named by its start
+// height, so that queued batches survive restarts and are always drained in
+// ascending order.
+//
+// NOTE: SubmissionQueue is safe for concurrent use. Only a single process may
+// use a given directory at a time, which is enforced by a lock file that is
+// held until Close is called.
+type SubmissionQueue struct {
+	mu   sync.Mutex
+	dir  string
+	lock *dirLock
+}
+
+// NewSubmissionQueue opens the queue stored in dir, creating the directory if
+// it does not already exist. If the directory is locked by a live process,
+// ErrQueueLocked is returned. A lock left behind by a dead process, or whose
+// heartbeat is older than staleLockTimeout, is broken. If staleLockTimeout is
+// zero, DefaultStaleLockTimeout is used.
+func NewSubmissionQueue(
+	dir string, staleLockTimeout time.Duration) (*SubmissionQueue, error) {
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	lock, err := acquireDirLock(dir, staleLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SubmissionQueue{
+		dir:  dir,
+		lock: lock,
+	}, nil
+}
+
+// Close releases the queue's lock over its directory. The queue must not be
+// used after calling Close.
+func (q *SubmissionQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.lock.release()
+}
+
+// LockLost returns a channel that is closed once the queue's lock over its
+// directory is found broken by another process, after which every operation
+// but Close fails with ErrQueueLockLost.
+func (q *SubmissionQueue) LockLost() <-chan struct{} {
+	return q.lock.lost
+}
+
+// CheckWritable verifies that batches can be persisted to the queue's
+// directory, without modifying the queue.
+func (q *SubmissionQueue) CheckWritable() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return err
+	}
+
+	return checkWritable(q.dir)
+}
+
+// Push appends batch to the tail of the queue. If the queue is non-empty, the
+// batch must begin where the last queued batch ends.
+func (q *SubmissionQueue) Push(batch *Batch) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return err
+	}
+
+	batches, err := q.load()
+	if err != nil {
+		return err
+	}
+
+	if len(batches) > 0 {
+		tail := batches[len(batches)-1]
+		if batch.Start != tail.End {
+			return fmt.Errorf("%w: tail_end=%d start=%d",
+				ErrNonContiguousBatch, tail.End, batch.Start)
+		}
+	}
+
+	data, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(q.dir, q.batchPath(batch.Start), data)
+}
+
+// Peek returns the batch at the head of the queue, or nil if the queue is
+// empty.
+func (q *SubmissionQueue) Peek() (*Batch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return nil, err
+	}
+
+	batches, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+	if len(batches) == 0 {
+		return nil, nil
+	}
+
+	return batches[0], nil
+}
+
+// Batches returns all queued batches in ascending order.
+func (q *SubmissionQueue) Batches() ([]*Batch, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return nil, err
+	}
+
+	return q.load()
+}
+
+// Len returns the number of queued batches.
+func (q *SubmissionQueue) Len() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return 0, err
+	}
+
+	names, err := q.batchFiles()
+	if err != nil {
+		return 0, err
+	}
+	return len(names), nil
+}
+
+// Remove deletes the queued batch starting at the given height. Removing a
+// batch that is not queued is a no-op.
+func (q *SubmissionQueue) Remove(start uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return err
+	}
+
+	err := os.Remove(q.batchPath(start))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// PruneBefore removes every queued batch that ends at or below height, i.e.
+// batches whose entire range has already been confirmed.
+func (q *SubmissionQueue) PruneBefore(height uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return err
+	}
+
+	batches, err := q.load()
+	if err != nil {
+		return err
+	}
+
+	for _, batch := range batches {
+		if batch.End > height {
+			break
+		}
+		if err := os.Remove(q.batchPath(batch.Start)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Clear removes all queued batches.
+func (q *SubmissionQueue) Clear() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if err := q.lock.err(); err != nil {
+		return err
+	}
+
+	names, err := q.batchFiles()
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if err := os.Remove(filepath.Join(q.dir, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// load reads all queued batches in ascending order of their start height.
+//
+// NOTE: This method MUST be called while holding q.mu.
+func (q *SubmissionQueue) load() ([]*Batch, error) {
+	names, err := q.batchFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	batches := make([]*Batch, 0, len(names))
+	for _, name := range names {
+		data, err := ioutil.ReadFile(filepath.Join(q.dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var batch Batch
+		if err := json.Unmarshal(data, &batch); err != nil {
+			return nil, fmt.Errorf("unable to decode batch %s: %w",
+				name, err)
+		}
+		batches = append(batches, &batch)
+	}
+
+	return batches, nil
+}
+
+// batchFiles returns the names of all batch files in ascending order. Since
+// file names are zero-padded start heights, lexicographic order matches the
+// order of the batches.
+//
+// NOTE: This method MUST be called while holding q.mu.
+func (q *SubmissionQueue) batchFiles() ([]string, error) {
+	entries, err := ioutil.ReadDir(q.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, "tmp-") ||
+			!strings.HasSuffix(name, batchFileExt) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// batchPath returns the path of the file storing the batch starting at start.
+func (q *SubmissionQueue) batchPath(start uint64) string {
+	return filepath.Join(q.dir, fmt.Sprintf("%020d%s", start, batchFileExt))
+}
+
+// writeFileAtomic writes data to path via a temporary file in dir, such that a
+// crash mid-write never leaves a partial file behind.
+func writeFileAtomic(dir, path string, data []byte) error {
+	tmpFile, err := ioutil.TempFile(dir, "tmp-")
+	if err != nil {
+		return err
+	}
+	tmpName := tmpFile.Name()
+	defer os.Remove(tmpName)
+
+	if _, err := tmpFile.Write(data); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Sync(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpName, path)
+}
+
+// checkWritable writes and removes a probe file in dir.
+func checkWritable(dir string) error {
+	path := filepath.Join(dir, probeFileName)
+	if err := writeFileAtomic(dir, path, []byte("probe")); err != nil {
+		return err
+	}
+
+	return os.Remove(path)
+}
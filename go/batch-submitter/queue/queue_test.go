@@ -0,0 +1,92 @@
+package queue_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestQueue creates a SubmissionQueue in a temporary directory that is
+// removed once the test completes.
+func newTestQueue(t *testing.T) (*queue.SubmissionQueue, string) {
+	dir, err := ioutil.TempDir("", "submission-queue")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	q, err := queue.NewSubmissionQueue(dir, 0)
+	require.Nil(t, err)
+	t.Cleanup(func() { q.Close() })
+
+	return q, dir
+}
+
+// TestSubmissionQueueOrdering asserts that batches are drained in ascending
+// order and that only contiguous batches may be pushed.
+func TestSubmissionQueueOrdering(t *testing.T) {
+	t.Parallel()
+
+	q, _ := newTestQueue(t)
+
+	head, err := q.Peek()
+	require.Nil(t, err)
+	require.Nil(t, head)
+
+	require.Nil(t, q.Push(&queue.Batch{Start: 9, End: 10}))
+	require.Nil(t, q.Push(&queue.Batch{Start: 10, End: 100}))
+	require.Nil(t, q.Push(&queue.Batch{Start: 100, End: 101}))
+
+	err = q.Push(&queue.Batch{Start: 102, End: 103})
+	require.True(t, errors.Is(err, queue.ErrNonContiguousBatch))
+
+	batches, err := q.Batches()
+	require.Nil(t, err)
+	require.Len(t, batches, 3)
+	require.Equal(t, uint64(9), batches[0].Start)
+	require.Equal(t, uint64(10), batches[1].Start)
+	require.Equal(t, uint64(100), batches[2].Start)
+
+	require.Nil(t, q.Remove(9))
+	head, err = q.Peek()
+	require.Nil(t, err)
+	require.Equal(t, uint64(10), head.Start)
+
+	require.Nil(t, q.PruneBefore(100))
+	n, err := q.Len()
+	require.Nil(t, err)
+	require.Equal(t, 1, n)
+
+	require.Nil(t, q.Clear())
+	n, err = q.Len()
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+}
+
+// TestSubmissionQueuePersistence asserts that queued batches survive
+// reopening the queue from the same directory.
+func TestSubmissionQueuePersistence(t *testing.T) {
+	t.Parallel()
+
+	q, dir := newTestQueue(t)
+
+	expBatch := &queue.Batch{
+		Start:     1,
+		End:       5,
+		CallData:  []byte{0x01, 0x02, 0x03},
+		CreatedAt: time.Unix(1000, 0).UTC(),
+	}
+	require.Nil(t, q.Push(expBatch))
+	require.Nil(t, q.Close())
+
+	reopened, err := queue.NewSubmissionQueue(dir, 0)
+	require.Nil(t, err)
+	defer reopened.Close()
+
+	head, err := reopened.Peek()
+	require.Nil(t, err)
+	require.Equal(t, expBatch, head)
+}
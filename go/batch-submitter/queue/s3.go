@@ -0,0 +1,389 @@
+package queue
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultS3Region is the region of an S3 backend if none is given by
+	// its URL or the environment.
+	defaultS3Region = "us-east-1"
+
+	// s3RequestTimeout is the maximum duration of a single S3 request.
+	s3RequestTimeout = 30 * time.Second
+
+	// s3CheckKey is the key of the object written, and deleted, to check
+	// that an S3 backend accepts writes.
+	s3CheckKey = "check-writable"
+)
+
+// ErrS3CredentialsNotSet signals an S3 backend opened without credentials.
+var ErrS3CredentialsNotSet = errors.New("AWS_ACCESS_KEY_ID and " +
+	"AWS_SECRET_ACCESS_KEY must be set to use the s3 backend")
+
+// s3Credentials are the credentials with which S3 requests are signed.
+type s3Credentials struct {
+	accessKeyID     string
+	secretAccessKey string
+	sessionToken    string
+}
+
+// s3Backend is a Backend storing each record of a namespace as an object
+// within an S3 bucket, under prefix/namespace/. Objects are written whole, so
+// each record is replaced atomically.
+type s3Backend struct {
+	client *http.Client
+	creds  s3Credentials
+	region string
+
+	// endpoint is the URL of the bucket, either virtual-hosted or, for a
+	// custom endpoint, path-style.
+	endpoint *url.URL
+
+	// prefix is the key prefix of the namespace's objects, ending in a
+	// slash.
+	prefix string
+
+	// now returns the time at which requests are signed.
+	now func() time.Time
+}
+
+// newS3Backend initializes the backend of namespace within the bucket of u,
+// i.e. s3://bucket/prefix?region=...&endpoint=..., using the credentials of
+// the AWS_* environment variables.
+func newS3Backend(u *url.URL, namespace string) (*s3Backend, error) {
+	creds := s3Credentials{
+		accessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		secretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		sessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+	if creds.accessKeyID == "" || creds.secretAccessKey == "" {
+		return nil, ErrS3CredentialsNotSet
+	}
+
+	query := u.Query()
+	region := query.Get("region")
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = defaultS3Region
+	}
+
+	var endpoint *url.URL
+	if rawEndpoint := query.Get("endpoint"); rawEndpoint != "" {
+		var err error
+		endpoint, err = url.Parse(rawEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		endpoint.Path = "/" + u.Host
+	} else {
+		endpoint = &url.URL{
+			Scheme: "https",
+			Host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", u.Host, region),
+		}
+	}
+
+	prefix := path.Join(strings.Trim(u.Path, "/"), namespace) + "/"
+
+	return &s3Backend{
+		client: &http.Client{
+			Timeout: s3RequestTimeout,
+		},
+		creds:    creds,
+		region:   region,
+		endpoint: endpoint,
+		prefix:   strings.TrimPrefix(prefix, "/"),
+		now:      time.Now,
+	}, nil
+}
+
+// Put writes value at key as a single object.
+func (b *s3Backend) Put(key string, value []byte) error {
+	resp, err := b.do(http.MethodPut, b.prefix+key, nil, value)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Get returns the value at key.
+func (b *s3Backend) Get(key string) ([]byte, error) {
+	resp, err := b.do(http.MethodGet, b.prefix+key, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return ioutil.ReadAll(resp.Body)
+}
+
+// Delete removes the value at key, if any. S3 does not fail deletions of
+// missing objects.
+func (b *s3Backend) Delete(key string) error {
+	resp, err := b.do(http.MethodDelete, b.prefix+key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// s3ListResult is the response of a ListObjectsV2 request.
+type s3ListResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// Keys returns the key of every stored value in ascending order, the order in
+// which S3 lists objects.
+func (b *s3Backend) Keys() ([]string, error) {
+	var (
+		keys  []string
+		token string
+	)
+	for {
+		query := url.Values{
+			"list-type": {"2"},
+			"prefix":    {b.prefix},
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := b.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, object := range result.Contents {
+			key := strings.TrimPrefix(object.Key, b.prefix)
+			if key == "" || strings.Contains(key, "/") {
+				continue
+			}
+			keys = append(keys, key)
+		}
+		if !result.IsTruncated {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// CheckWritable verifies that the bucket accepts writes by writing and then
+// deleting an object outside of the namespace's keys.
+func (b *s3Backend) CheckWritable() error {
+	key := b.prefix + "tmp-" + s3CheckKey
+	resp, err := b.do(http.MethodPut, key, nil, []byte{})
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	resp, err = b.do(http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	return nil
+}
+
+// Close is a no-op, as the backend holds no resources.
+func (b *s3Backend) Close() error {
+	return nil
+}
+
+// do sends a signed request for the object at key, or the bucket if key is
+// empty, returning ErrNotFound for missing objects and an error for any other
+// unsuccessful status.
+func (b *s3Backend) do(
+	method string,
+	key string,
+	query url.Values,
+	body []byte,
+) (*http.Response, error) {
+
+	u := *b.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + key
+	u.RawQuery = query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	signS3Request(req, body, b.creds, b.region, b.now())
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode/100 != 2:
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s returned status %d: %s",
+			method, key, resp.StatusCode, msg)
+	}
+
+	return resp, nil
+}
+
+// signS3Request signs req with AWS Signature Version 4, covering the host and
+// every header already set on req.
+func signS3Request(
+	req *http.Request,
+	body []byte,
+	creds s3Credentials,
+	region string,
+	now time.Time,
+) {
+
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if creds.sessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.sessionToken)
+	}
+
+	headers := map[string]string{
+		"host": req.URL.Host,
+	}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(
+			strings.Join(values, ","),
+		)
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s3URIEncode(req.URL.Path, false),
+		s3CanonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+creds.secretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, "+
+			"Signature=%s", creds.accessKeyID, scope, signedHeaders,
+		signature,
+	))
+}
+
+// s3CanonicalQuery encodes query sorted by name, as required by SigV4.
+func s3CanonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var params []string
+	for _, name := range names {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		for _, value := range values {
+			params = append(params, s3URIEncode(name, true)+"="+
+				s3URIEncode(value, true))
+		}
+	}
+
+	return strings.Join(params, "&")
+}
+
+// s3URIEncode percent-encodes every byte of s other than the unreserved
+// characters, and slashes unless encodeSlash is true.
+func s3URIEncode(s string, encodeSlash bool) string {
+	var encoded strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z',
+			'0' <= c && c <= '9', c == '-', c == '.', c == '_',
+			c == '~', c == '/' && !encodeSlash:
+			encoded.WriteByte(c)
+		default:
+			fmt.Fprintf(&encoded, "%%%02X", c)
+		}
+	}
+
+	return encoded.String()
+}
+
+// sha256Hex returns the hex-encoded SHA-256 digest of data.
+func sha256Hex(data []byte) string {
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:])
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of data under key.
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,183 @@
+package queue
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	// Register the database/sql drivers of the SQL backends.
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqlTable is the table in which the SQL backends store the records of every
+// namespace.
+const sqlTable = "submission_state"
+
+// sqlCheckKey is the key of the row written, and rolled back, to check that a
+// SQL backend accepts writes.
+const sqlCheckKey = "check-writable"
+
+// sqlDialect describes the differences between the databases supported by the
+// SQL backend.
+type sqlDialect struct {
+	// driver is the name of the registered database/sql driver.
+	driver string
+
+	// blobType is the column type of values.
+	blobType string
+
+	// placeholder returns the placeholder of the i-th query argument,
+	// starting at one.
+	placeholder func(i int) string
+
+	// maxOpenConns, if non-zero, bounds the open connections to the
+	// database.
+	maxOpenConns int
+}
+
+var (
+	// sqliteDialect uses a single connection, as SQLite serializes writers
+	// and would otherwise fail concurrent writes with SQLITE_BUSY.
+	sqliteDialect = sqlDialect{
+		driver:   "sqlite3",
+		blobType: "BLOB",
+		placeholder: func(int) string {
+			return "?"
+		},
+		maxOpenConns: 1,
+	}
+
+	postgresDialect = sqlDialect{
+		driver:   "postgres",
+		blobType: "BYTEA",
+		placeholder: func(i int) string {
+			return fmt.Sprintf("$%d", i)
+		},
+	}
+)
+
+// sqlBackend is a Backend storing the records of a namespace as rows of a table
+// shared by every namespace.
+type sqlBackend struct {
+	db        *sql.DB
+	namespace string
+
+	putQuery    string
+	getQuery    string
+	deleteQuery string
+	keysQuery   string
+}
+
+// openSQLBackend connects to the database at dsn using dialect, creating the
+// records table if it does not already exist. For SQLite, dsn is the path of
+// the database file, which is created along with its directory if necessary.
+func openSQLBackend(
+	dialect sqlDialect,
+	dsn string,
+	namespace string,
+) (*sqlBackend, error) {
+
+	if dialect.driver == sqliteDialect.driver {
+		if err := os.MkdirAll(filepath.Dir(dsn), 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := sql.Open(dialect.driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if dialect.maxOpenConns > 0 {
+		db.SetMaxOpenConns(dialect.maxOpenConns)
+	}
+
+	_, err = db.Exec(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		namespace TEXT NOT NULL,
+		key TEXT NOT NULL,
+		value %s NOT NULL,
+		PRIMARY KEY (namespace, key)
+	)`, sqlTable, dialect.blobType))
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	p := dialect.placeholder
+	return &sqlBackend{
+		db:        db,
+		namespace: namespace,
+		putQuery: fmt.Sprintf(`INSERT INTO %s (namespace, key, value)
+			VALUES (%s, %s, %s)
+			ON CONFLICT (namespace, key) DO UPDATE
+			SET value = excluded.value`, sqlTable, p(1), p(2), p(3)),
+		getQuery: fmt.Sprintf(`SELECT value FROM %s
+			WHERE namespace = %s AND key = %s`, sqlTable, p(1), p(2)),
+		deleteQuery: fmt.Sprintf(`DELETE FROM %s
+			WHERE namespace = %s AND key = %s`, sqlTable, p(1), p(2)),
+		keysQuery: fmt.Sprintf(`SELECT key FROM %s
+			WHERE namespace = %s ORDER BY key`, sqlTable, p(1)),
+	}, nil
+}
+
+// Put writes value at key, replacing any existing row.
+func (b *sqlBackend) Put(key string, value []byte) error {
+	_, err := b.db.Exec(b.putQuery, b.namespace, key, value)
+	return err
+}
+
+// Get returns the value at key.
+func (b *sqlBackend) Get(key string) ([]byte, error) {
+	var value []byte
+	err := b.db.QueryRow(b.getQuery, b.namespace, key).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return value, err
+}
+
+// Delete removes the value at key, if any.
+func (b *sqlBackend) Delete(key string) error {
+	_, err := b.db.Exec(b.deleteQuery, b.namespace, key)
+	return err
+}
+
+// Keys returns the key of every stored value in ascending order.
+func (b *sqlBackend) Keys() ([]string, error) {
+	rows, err := b.db.Query(b.keysQuery, b.namespace)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// CheckWritable verifies that the database accepts writes by writing a row
+// within a transaction that is rolled back.
+func (b *sqlBackend) CheckWritable() error {
+	tx, err := b.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(b.putQuery, b.namespace, sqlCheckKey, []byte{})
+	return err
+}
+
+// Close closes the connections to the database.
+func (b *sqlBackend) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,479 @@
+package queue
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// submissionFileExt is the file extension used for each persisted
+	// submission record.
+	submissionFileExt = ".submission.json"
+
+	// DefaultStateRetention is the number of concluded submissions retained
+	// by a StateStore. Pending submissions are always retained.
+	DefaultStateRetention = 1024
+
+	// quarantinedBlockKeyPrefix prefixes the key of each quarantined block.
+	quarantinedBlockKeyPrefix = "quarantined-block-"
+)
+
+// SubmissionStatus is the stage reached by a recorded submission.
+type SubmissionStatus string
+
+const (
+	// SubmissionPending marks a submission whose batch tx has been
+	// published but has yet to reach the confirmation depth.
+	SubmissionPending SubmissionStatus = "pending"
+
+	// SubmissionConfirmed marks a submission whose batch tx reached the
+	// confirmation depth.
+	SubmissionConfirmed SubmissionStatus = "confirmed"
+
+	// SubmissionAbandoned marks a submission that is no longer tracked,
+	// either after failing or after its nonce was consumed by another tx.
+	SubmissionAbandoned SubmissionStatus = "abandoned"
+)
+
+// SubmissionReceipt is the receipt of a confirmed batch tx.
+type SubmissionReceipt struct {
+	TxHash      common.Hash `json:"tx_hash"`
+	BlockNumber uint64      `json:"block_number"`
+	BlockHash   common.Hash `json:"block_hash"`
+	GasUsed     uint64      `json:"gas_used"`
+	Status      uint64      `json:"status"`
+}
+
+// SubmissionAttempt records a single tx published for a submission, forming an
+// audit log of the submission's fee escalation.
+type SubmissionAttempt struct {
+	// TxHash is the hash of the published tx.
+	TxHash common.Hash `json:"tx_hash"`
+
+	// GasPrice is the gas price in wei at which the tx was published.
+	GasPrice *big.Int `json:"gas_price"`
+
+	// PublishedAt is the time at which the tx was published.
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// BuildInfo identifies the build and configuration of the submitter that
+// published a batch, such that the batch can be decoded by version-matched
+// tooling long after the submitter has been upgraded.
+type BuildInfo struct {
+	// Version is the version of the submitter binary.
+	Version string `json:"version"`
+
+	// ConfigHash is a hash of the submitter's configuration, excluding
+	// secrets.
+	ConfigHash common.Hash `json:"config_hash"`
+
+	// Encoding and EncoderVersion identify the encoding of the batch, if
+	// the driver supports more than one.
+	Encoding       string `json:"encoding,omitempty"`
+	EncoderVersion uint64 `json:"encoder_version,omitempty"`
+}
+
+// SubmissionRecord records the publication of a batch tx at a single nonce.
+type SubmissionRecord struct {
+	// Nonce is the nonce at which the batch tx was published.
+	Nonce uint64 `json:"nonce"`
+
+	// Start and End bound the L2 blocks covered by the batch, [Start, End).
+	Start uint64 `json:"start"`
+	End   uint64 `json:"end"`
+
+	// EndBlockHash is the hash of the L2 block at height End-1 when the
+	// batch was published, if known. It is verified against the L2 chain
+	// before building on top of the batch, detecting L2 reorgs.
+	EndBlockHash common.Hash `json:"end_block_hash,omitempty"`
+
+	// Build identifies the submitter that built the batch, if known.
+	// Records written before builds were recorded omit it.
+	Build *BuildInfo `json:"build,omitempty"`
+
+	// TxHashes are the hashes of every tx published for the submission,
+	// in the order they were published. Each fee bump adds a tx.
+	TxHashes []common.Hash `json:"tx_hashes"`
+
+	// Attempts details every tx published for the submission, in the
+	// order they were published. Records written before attempts were
+	// recorded only list their TxHashes.
+	Attempts []SubmissionAttempt `json:"attempts,omitempty"`
+
+	// Status is the stage reached by the submission.
+	Status SubmissionStatus `json:"status"`
+
+	// Receipt is the receipt of the confirmed batch tx, if any.
+	Receipt *SubmissionReceipt `json:"receipt,omitempty"`
+
+	// SubmittedAt is the time at which the first tx was published.
+	SubmittedAt time.Time `json:"submitted_at"`
+
+	// UpdatedAt is the time at which the record was last written.
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LastTxHash returns the hash of the most recently published tx, or the zero
+// hash if none was published.
+func (r *SubmissionRecord) LastTxHash() common.Hash {
+	if len(r.TxHashes) == 0 {
+		return common.Hash{}
+	}
+	return r.TxHashes[len(r.TxHashes)-1]
+}
+
+// QuarantinedBlock records an L2 block that could not be batched, e.g. as it
+// holds an unexpected tx type, retained for manual review.
+type QuarantinedBlock struct {
+	// Number and Hash identify the block.
+	Number uint64      `json:"number"`
+	Hash   common.Hash `json:"hash"`
+
+	// Reason describes why the block could not be batched.
+	Reason string `json:"reason"`
+
+	// Encounters is the number of times the block was quarantined.
+	Encounters uint64 `json:"encounters"`
+
+	// FirstSeen and LastSeen are the times at which the block was first
+	// and last quarantined.
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// StateStore durably records the batch txs published by a submitter, keyed by
+// nonce, such that a restarted submitter can resume tracking the txs it left
+// pending rather than building their ranges again. Each record is persisted
+// individually within the store's Backend.
+//
+// NOTE: StateStore is safe for concurrent use. Only a single process may use a
+// given namespace of a backend at a time.
+type StateStore struct {
+	mu        sync.Mutex
+	backend   Backend
+	retention int
+}
+
+// NewStateStore opens the store in dir, creating the directory if it does not
+// already exist. Locking follows the semantics of NewSubmissionQueue.
+func NewStateStore(
+	dir string, staleLockTimeout time.Duration) (*StateStore, error) {
+
+	backend, err := NewDirBackend(dir, staleLockTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return OpenStateStore(backend), nil
+}
+
+// OpenStateStore returns a store persisting its records within backend. The
+// store takes ownership of backend, closing it along with the store.
+func OpenStateStore(backend Backend) *StateStore {
+	return &StateStore{
+		backend:   backend,
+		retention: DefaultStateRetention,
+	}
+}
+
+// Close releases the store's backend. The store must not be used after calling
+// Close.
+func (s *StateStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.backend.Close()
+}
+
+// LockLost returns a channel that is closed once the lock of the store's
+// backend is found broken by another process, after which every operation but
+// Close fails with ErrQueueLockLost. For a backend not implementing
+// LockedBackend, the channel is nil and thus never closed.
+func (s *StateStore) LockLost() <-chan struct{} {
+	if backend, ok := s.backend.(LockedBackend); ok {
+		return backend.LockLost()
+	}
+
+	return nil
+}
+
+// CheckWritable verifies that records can be persisted to the store's backend,
+// without modifying the store.
+func (s *StateStore) CheckWritable() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.backend.CheckWritable()
+}
+
+// Put writes record, replacing any record at the same nonce, then prunes the
+// oldest concluded records beyond the store's retention.
+func (s *StateStore) Put(record *SubmissionRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	record.UpdatedAt = time.Now()
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	err = s.backend.Put(recordKey(record.Nonce), data)
+	if err != nil {
+		return err
+	}
+
+	return s.prune()
+}
+
+// Get returns the record at nonce, or nil if none is recorded.
+func (s *StateStore) Get(nonce uint64) (*SubmissionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := recordKey(nonce)
+	data, err := s.backend.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSubmissionRecord(key, data)
+}
+
+// Pending returns every pending record in ascending order of nonce.
+func (s *StateStore) Pending() ([]*SubmissionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []*SubmissionRecord
+	for _, record := range records {
+		if record.Status == SubmissionPending {
+			pending = append(pending, record)
+		}
+	}
+
+	return pending, nil
+}
+
+// Recent returns up to n records, most recent nonce first.
+func (s *StateStore) Recent(n int) ([]*SubmissionRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	records, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	recent := make([]*SubmissionRecord, 0, n)
+	for i := len(records) - 1; i >= 0 && len(recent) < n; i-- {
+		recent = append(recent, records[i])
+	}
+
+	return recent, nil
+}
+
+// PutQuarantinedBlock records the quarantine of block, merging it with any
+// record of a block at the same height and hash such that repeated encounters
+// are counted rather than recorded individually.
+func (s *StateStore) PutQuarantinedBlock(block *QuarantinedBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	record := *block
+	record.Encounters = 1
+	record.FirstSeen = now
+	record.LastSeen = now
+
+	key := quarantinedBlockKey(block.Number)
+	data, err := s.backend.Get(key)
+	switch {
+	case errors.Is(err, ErrNotFound):
+	case err != nil:
+		return err
+	default:
+		prev, err := decodeQuarantinedBlock(key, data)
+		if err != nil {
+			return err
+		}
+		if prev.Hash == block.Hash {
+			record.Encounters = prev.Encounters + 1
+			record.FirstSeen = prev.FirstSeen
+		}
+	}
+
+	data, err = json.Marshal(&record)
+	if err != nil {
+		return err
+	}
+
+	return s.backend.Put(key, data)
+}
+
+// QuarantinedBlocks returns every quarantined block in ascending order of
+// height.
+func (s *StateStore) QuarantinedBlocks() ([]*QuarantinedBlock, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys, err := s.backend.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*QuarantinedBlock
+	for _, key := range keys {
+		if !strings.HasPrefix(key, quarantinedBlockKeyPrefix) {
+			continue
+		}
+		data, err := s.backend.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		block, err := decodeQuarantinedBlock(key, data)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+
+	return blocks, nil
+}
+
+// prune removes the oldest concluded records until at most s.retention remain.
+//
+// NOTE: This method MUST be called while holding s.mu.
+func (s *StateStore) prune() error {
+	records, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	excess := len(records) - s.retention
+	for _, record := range records {
+		if excess <= 0 {
+			break
+		}
+		if record.Status == SubmissionPending {
+			continue
+		}
+		if err := s.backend.Delete(recordKey(record.Nonce)); err != nil {
+			return err
+		}
+		excess--
+	}
+
+	return nil
+}
+
+// load reads all records in ascending order of nonce. Keys that do not name a
+// record, e.g. those of a backend's writability checks, are skipped.
+//
+// NOTE: This method MUST be called while holding s.mu.
+func (s *StateStore) load() ([]*SubmissionRecord, error) {
+	keys, err := s.backend.Keys()
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]*SubmissionRecord, 0, len(keys))
+	for _, key := range keys {
+		if !isRecordKey(key) {
+			continue
+		}
+		data, err := s.backend.Get(key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		record, err := decodeSubmissionRecord(key, data)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+
+	return records, nil
+}
+
+// ReadSubmissionRecord reads the record at nonce from the store in dir without
+// opening the store, such that records can be inspected while the submitter
+// holds its lock. This is safe, as records are written atomically.
+func ReadSubmissionRecord(dir string, nonce uint64) (*SubmissionRecord, error) {
+	path := filepath.Join(dir, recordKey(nonce)+submissionFileExt)
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeSubmissionRecord(recordKey(nonce), data)
+}
+
+// decodeSubmissionRecord decodes the record stored at key.
+func decodeSubmissionRecord(key string, data []byte) (*SubmissionRecord, error) {
+	var record SubmissionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("unable to decode submission %s: %w",
+			key, err)
+	}
+
+	return &record, nil
+}
+
+// decodeQuarantinedBlock decodes the quarantined block stored at key.
+func decodeQuarantinedBlock(key string, data []byte) (*QuarantinedBlock, error) {
+	var block QuarantinedBlock
+	if err := json.Unmarshal(data, &block); err != nil {
+		return nil, fmt.Errorf("unable to decode quarantined block %s: %w",
+			key, err)
+	}
+
+	return &block, nil
+}
+
+// quarantinedBlockKey returns the key of the quarantined block at number,
+// zero-padded such that keys sort in ascending order of height. Keys are
+// prefixed such that they are never mistaken for those of records.
+func quarantinedBlockKey(number uint64) string {
+	return fmt.Sprintf("%s%020d", quarantinedBlockKeyPrefix, number)
+}
+
+// recordKey returns the key of the record at nonce, zero-padded such that keys
+// sort in ascending order of nonce.
+func recordKey(nonce uint64) string {
+	return fmt.Sprintf("%020d", nonce)
+}
+
+// isRecordKey returns whether key was produced by recordKey.
+func isRecordKey(key string) bool {
+	if len(key) != 20 {
+		return false
+	}
+	_, err := strconv.ParseUint(key, 10, 64)
+	return err == nil
+}
@@ -0,0 +1,183 @@
+package queue_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestStateStore creates a StateStore in a temporary directory that is
+// removed once the test completes.
+func newTestStateStore(t *testing.T) (*queue.StateStore, string) {
+	dir, err := ioutil.TempDir("", "state-store")
+	require.Nil(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	s, err := queue.NewStateStore(dir, 0)
+	require.Nil(t, err)
+	t.Cleanup(func() { s.Close() })
+
+	return s, dir
+}
+
+// TestStateStoreRecords asserts that records are keyed by nonce, and that
+// pending and recent records are returned in the expected order.
+func TestStateStoreRecords(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newTestStateStore(t)
+
+	record, err := s.Get(0)
+	require.Nil(t, err)
+	require.Nil(t, record)
+
+	require.Nil(t, s.Put(&queue.SubmissionRecord{
+		Nonce: 2, Start: 20, End: 30, Status: queue.SubmissionPending,
+	}))
+	require.Nil(t, s.Put(&queue.SubmissionRecord{
+		Nonce: 1, Start: 10, End: 20, Status: queue.SubmissionPending,
+	}))
+	require.Nil(t, s.Put(&queue.SubmissionRecord{
+		Nonce: 0, Start: 0, End: 10, Status: queue.SubmissionConfirmed,
+		Receipt: &queue.SubmissionReceipt{BlockNumber: 5},
+	}))
+
+	pending, err := s.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 2)
+	require.Equal(t, uint64(1), pending[0].Nonce)
+	require.Equal(t, uint64(2), pending[1].Nonce)
+
+	recent, err := s.Recent(2)
+	require.Nil(t, err)
+	require.Len(t, recent, 2)
+	require.Equal(t, uint64(2), recent[0].Nonce)
+	require.Equal(t, uint64(1), recent[1].Nonce)
+
+	// Replacing a record updates it in place.
+	hash := common.HexToHash("0x01")
+	record, err = s.Get(1)
+	require.Nil(t, err)
+	record.TxHashes = append(record.TxHashes, hash)
+	record.Status = queue.SubmissionAbandoned
+	require.Nil(t, s.Put(record))
+
+	record, err = s.Get(1)
+	require.Nil(t, err)
+	require.Equal(t, queue.SubmissionAbandoned, record.Status)
+	require.Equal(t, hash, record.LastTxHash())
+
+	pending, err = s.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, uint64(2), pending[0].Nonce)
+}
+
+// TestStateStoreSurvivesReopen asserts that records persist across reopening
+// the store's directory, and that the directory is locked while open.
+func TestStateStoreSurvivesReopen(t *testing.T) {
+	t.Parallel()
+
+	s, dir := newTestStateStore(t)
+
+	require.Nil(t, s.Put(&queue.SubmissionRecord{
+		Nonce: 7, Start: 70, End: 80, Status: queue.SubmissionPending,
+		TxHashes: []common.Hash{common.HexToHash("0x07")},
+	}))
+
+	_, err := queue.NewStateStore(dir, 0)
+	require.True(t, errors.Is(err, queue.ErrQueueLocked))
+
+	require.Nil(t, s.Close())
+
+	reopened, err := queue.NewStateStore(dir, 0)
+	require.Nil(t, err)
+	defer reopened.Close()
+
+	pending, err := reopened.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, uint64(80), pending[0].End)
+	require.Equal(t, common.HexToHash("0x07"), pending[0].LastTxHash())
+}
+
+// TestStateStoreQuarantinedBlocks asserts that repeated quarantines of a block
+// are merged into a single record, and that quarantined blocks are kept apart
+// from submission records.
+func TestStateStoreQuarantinedBlocks(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newTestStateStore(t)
+
+	require.Nil(t, s.Put(&queue.SubmissionRecord{
+		Nonce: 0, Start: 0, End: 10, Status: queue.SubmissionPending,
+	}))
+
+	hash := common.HexToHash("0x0c")
+	require.Nil(t, s.PutQuarantinedBlock(&queue.QuarantinedBlock{
+		Number: 12, Hash: hash, Reason: "first",
+	}))
+	require.Nil(t, s.PutQuarantinedBlock(&queue.QuarantinedBlock{
+		Number: 11, Hash: common.HexToHash("0x0b"), Reason: "other",
+	}))
+	require.Nil(t, s.PutQuarantinedBlock(&queue.QuarantinedBlock{
+		Number: 12, Hash: hash, Reason: "second",
+	}))
+
+	blocks, err := s.QuarantinedBlocks()
+	require.Nil(t, err)
+	require.Len(t, blocks, 2)
+	require.Equal(t, uint64(11), blocks[0].Number)
+	require.Equal(t, uint64(1), blocks[0].Encounters)
+	require.Equal(t, uint64(12), blocks[1].Number)
+	require.Equal(t, uint64(2), blocks[1].Encounters)
+	require.Equal(t, "second", blocks[1].Reason)
+	require.False(t, blocks[1].LastSeen.Before(blocks[1].FirstSeen))
+
+	// A different block at the same height replaces the record.
+	require.Nil(t, s.PutQuarantinedBlock(&queue.QuarantinedBlock{
+		Number: 12, Hash: common.HexToHash("0x0d"), Reason: "reorged",
+	}))
+	blocks, err = s.QuarantinedBlocks()
+	require.Nil(t, err)
+	require.Equal(t, uint64(1), blocks[1].Encounters)
+
+	pending, err := s.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+}
+
+// TestReadSubmissionRecord asserts that records, including their attempts, can
+// be read while the store is open and locked.
+func TestReadSubmissionRecord(t *testing.T) {
+	t.Parallel()
+
+	s, dir := newTestStateStore(t)
+
+	publishedAt := time.Unix(1000, 0).UTC()
+	require.Nil(t, s.Put(&queue.SubmissionRecord{
+		Nonce: 3, Start: 30, End: 40, Status: queue.SubmissionPending,
+		TxHashes: []common.Hash{common.HexToHash("0x03")},
+		Attempts: []queue.SubmissionAttempt{{
+			TxHash:      common.HexToHash("0x03"),
+			GasPrice:    big.NewInt(42),
+			PublishedAt: publishedAt,
+		}},
+	}))
+
+	record, err := queue.ReadSubmissionRecord(dir, 3)
+	require.Nil(t, err)
+	require.Len(t, record.Attempts, 1)
+	require.Equal(t, big.NewInt(42), record.Attempts[0].GasPrice)
+	require.True(t, publishedAt.Equal(record.Attempts[0].PublishedAt))
+
+	_, err = queue.ReadSubmissionRecord(dir, 4)
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,179 @@
+package quota
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrMemoryExhausted signals that a batch could not be built, as the driver's
+// memory quota is held by other batches.
+var ErrMemoryExhausted = errors.New("memory quota exhausted")
+
+// Memory bounds the bytes of batch data a driver holds at once, shared by
+// every batch it builds concurrently, e.g. when a fee bump rebuilds a batch
+// while another is being built. A nil Memory is unlimited.
+//
+// NOTE: Memory is safe for concurrent use.
+type Memory struct {
+	mu    sync.Mutex
+	limit uint64
+	inUse uint64
+}
+
+// NewMemory initializes a Memory quota of limit bytes, or returns nil if limit
+// is zero.
+func NewMemory(limit uint64) *Memory {
+	if limit == 0 {
+		return nil
+	}
+
+	return &Memory{
+		limit: limit,
+	}
+}
+
+// TryAcquire reserves n bytes, returning false without reserving any if the
+// quota would be exceeded. A reservation that exceeds the limit on its own is
+// granted if nothing else is reserved, such that an oversized element can
+// still be submitted by itself.
+func (m *Memory) TryAcquire(n uint64) bool {
+	if m == nil {
+		return true
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.inUse > 0 && m.inUse+n > m.limit {
+		return false
+	}
+	m.inUse += n
+	return true
+}
+
+// Release returns n previously acquired bytes to the quota.
+func (m *Memory) Release(n uint64) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if n > m.inUse {
+		n = m.inUse
+	}
+	m.inUse -= n
+}
+
+// InUse returns the number of bytes currently reserved.
+func (m *Memory) InUse() uint64 {
+	if m == nil {
+		return 0
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.inUse
+}
+
+// RPC bounds the rate of requests a driver makes to its backend, as a token
+// bucket admitting bursts of a configured number of requests. A nil RPC is
+// unlimited.
+//
+// NOTE: RPC is safe for concurrent use.
+type RPC struct {
+	mu       sync.Mutex
+	interval time.Duration
+	burst    time.Duration
+
+	// next is the earliest time at which the next request may be made,
+	// trailing the current time while under the rate.
+	next time.Time
+
+	now func() time.Time
+}
+
+// NewRPC initializes an RPC quota of requestsPerSecond admitting bursts of up
+// to burst requests, or returns nil if requestsPerSecond is not positive. If
+// burst is zero, bursts of up to one second's worth of requests are admitted,
+// or of a single request at rates below one per second.
+func NewRPC(requestsPerSecond float64, burst uint64) *RPC {
+	if requestsPerSecond <= 0 {
+		return nil
+	}
+
+	interval := time.Duration(float64(time.Second) / requestsPerSecond)
+	burstDuration := time.Duration(burst) * interval
+	if burst == 0 {
+		burstDuration = time.Second
+	}
+
+	// The burst always covers at least one request, such that a rate
+	// below one per second does not delay the first request.
+	if interval > burstDuration {
+		burstDuration = interval
+	}
+
+	return &RPC{
+		interval: interval,
+		burst:    burstDuration,
+		now:      time.Now,
+	}
+}
+
+// Reserve reserves a request, returning the delay after which it may be made.
+func (r *RPC) Reserve() time.Duration {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Trailing the current time by less than the burst admits exactly a
+	// burst's worth of requests without delay.
+	now := r.now()
+	earliest := now.Add(r.interval - r.burst)
+	if r.next.Before(earliest) {
+		r.next = earliest
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(r.interval)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// Wait blocks until a request may be made within the quota, returning the time
+// spent waiting. An error is returned if ctx is canceled beforehand.
+func (r *RPC) Wait(ctx context.Context) (time.Duration, error) {
+	delay := r.Reserve()
+	if err := Sleep(ctx, delay); err != nil {
+		return 0, err
+	}
+	return delay, nil
+}
+
+// Sleep blocks for the delay returned by Reserve, such that callers may observe
+// being throttled while waiting. An error is returned if ctx is canceled
+// beforehand.
+func Sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
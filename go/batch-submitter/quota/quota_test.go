@@ -0,0 +1,105 @@
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestMemoryQuota asserts that reservations are refused once the limit would be
+// exceeded, except for an oversized reservation made while nothing else is
+// held, and that released bytes may be reacquired.
+func TestMemoryQuota(t *testing.T) {
+	t.Parallel()
+
+	m := NewMemory(100)
+	require.True(t, m.TryAcquire(60))
+	require.True(t, m.TryAcquire(40))
+	require.False(t, m.TryAcquire(1))
+	require.Equal(t, uint64(100), m.InUse())
+
+	m.Release(60)
+	require.Equal(t, uint64(40), m.InUse())
+	require.False(t, m.TryAcquire(61))
+	require.True(t, m.TryAcquire(60))
+
+	m.Release(100)
+	require.Equal(t, uint64(0), m.InUse())
+	require.True(t, m.TryAcquire(150))
+	require.False(t, m.TryAcquire(1))
+}
+
+// TestUnlimitedQuotas asserts that zero-valued quotas are nil and never refuse
+// or delay a request.
+func TestUnlimitedQuotas(t *testing.T) {
+	t.Parallel()
+
+	m := NewMemory(0)
+	require.Nil(t, m)
+	require.True(t, m.TryAcquire(1<<40))
+	m.Release(1 << 40)
+	require.Equal(t, uint64(0), m.InUse())
+
+	r := NewRPC(0, 0)
+	require.Nil(t, r)
+	require.Equal(t, time.Duration(0), r.Reserve())
+	waited, err := r.Wait(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, time.Duration(0), waited)
+}
+
+// TestRPCQuota asserts that requests are admitted immediately up to a burst of
+// one second's worth, and are then spaced at the configured rate.
+func TestRPCQuota(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+	r := NewRPC(4, 0)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 4; i++ {
+		require.Equal(t, time.Duration(0), r.Reserve())
+	}
+	require.Equal(t, 250*time.Millisecond, r.Reserve())
+	require.Equal(t, 500*time.Millisecond, r.Reserve())
+
+	// Once idle for longer than the burst, a full burst is admitted again.
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 4; i++ {
+		require.Equal(t, time.Duration(0), r.Reserve())
+	}
+	require.Equal(t, 250*time.Millisecond, r.Reserve())
+}
+
+// TestRPCQuotaBurst asserts that a configured burst is admitted immediately,
+// and that subsequent requests are spaced at the configured rate.
+func TestRPCQuotaBurst(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1000, 0)
+	r := NewRPC(10, 2)
+	r.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		require.Equal(t, time.Duration(0), r.Reserve())
+	}
+	require.Equal(t, 100*time.Millisecond, r.Reserve())
+	require.Equal(t, 200*time.Millisecond, r.Reserve())
+}
+
+// TestRPCQuotaWaitCanceled asserts that Wait returns the context's error if it
+// is canceled before the request is admitted.
+func TestRPCQuotaWaitCanceled(t *testing.T) {
+	t.Parallel()
+
+	r := NewRPC(0.001, 0)
+	_, err := r.Wait(context.Background())
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = r.Wait(ctx)
+	require.Equal(t, context.DeadlineExceeded, err)
+}
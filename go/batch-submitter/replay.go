@@ -0,0 +1,314 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/failover"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/flags"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/urfave/cli"
+)
+
+// replayMaxBlocks bounds the number of L1 blocks fetched when replaying a
+// single submission.
+const replayMaxBlocks = 10_000
+
+var (
+	// ErrSubmissionNotFound signals that no submission is recorded at the
+	// nonce to replay.
+	ErrSubmissionNotFound = errors.New("submission not found")
+
+	// ErrNoAttemptsRecorded signals that the submission to replay predates
+	// the recording of attempts, and cannot be replayed.
+	ErrNoAttemptsRecorded = errors.New("submission has no recorded attempts")
+
+	// ErrReplayViolations signals that a replayed submission deviated from
+	// the configured fee policy.
+	ErrReplayViolations = errors.New("submission deviated from fee policy")
+)
+
+// readSubmissionRecord reads the submission recorded by service at nonce from
+// the configured submission state. Directories are read without opening the
+// store, such that submissions can be replayed while the submitter holds its
+// lock.
+func readSubmissionRecord(
+	cfg Config,
+	service string,
+	nonce uint64,
+) (*queue.SubmissionRecord, error) {
+
+	dir := cfg.SubmissionStateDir
+	if cfg.SubmissionStateURL != "" {
+		backend, u, err := queue.ParseBackendURL(cfg.SubmissionStateURL)
+		if err != nil {
+			return nil, err
+		}
+		dir = u.Path
+
+		if backend != queue.BackendFile {
+			return readBackendSubmissionRecord(cfg, service, nonce)
+		}
+	}
+
+	record, err := queue.ReadSubmissionRecord(
+		filepath.Join(dir, service), nonce,
+	)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: %s nonce %d", ErrSubmissionNotFound,
+			service, nonce)
+	}
+
+	return record, err
+}
+
+// readBackendSubmissionRecord reads the submission recorded by service at nonce
+// from the backend of the configured SubmissionStateURL.
+func readBackendSubmissionRecord(
+	cfg Config,
+	service string,
+	nonce uint64,
+) (*queue.SubmissionRecord, error) {
+
+	backend, err := queue.OpenBackend(
+		cfg.SubmissionStateURL, service,
+		cfg.SubmissionQueueStaleLockTimeout,
+	)
+	if err != nil {
+		return nil, err
+	}
+	store := queue.OpenStateStore(backend)
+	defer store.Close()
+
+	record, err := store.Get(nonce)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, fmt.Errorf("%w: %s nonce %d", ErrSubmissionNotFound,
+			service, nonce)
+	}
+
+	return record, nil
+}
+
+// headerSource is the subset of an L1 client used to fetch historical base
+// fees.
+type headerSource interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// Replay runs the replay subcommand, reconstructing the fee escalation of the
+// submission recorded at the given nonce in the SubmissionStateDir against the
+// historical base fees of L1. The timeline is printed along with any deviation
+// from the configured gas price bounds, increment and resubmission timeout,
+// allowing bump parameters to be tuned after an incident. An error is returned
+// if the submission deviated from the policy.
+func Replay(ctx *cli.Context) error {
+	cfg, err := NewConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if cfg.SubmissionStateDir == "" && cfg.SubmissionStateURL == "" {
+		return fmt.Errorf("%w: %s", ErrRequiredOptionNotSet,
+			flags.SubmissionStateDirFlag.Name)
+	}
+	service := ctx.String(flags.ReplayServiceFlag.Name)
+	nonce := ctx.Uint64(flags.ReplayNonceFlag.Name)
+
+	record, err := readSubmissionRecord(cfg, service, nonce)
+	if err != nil {
+		return err
+	}
+	if len(record.Attempts) == 0 {
+		return ErrNoAttemptsRecorded
+	}
+
+	endpoints := splitEndpoints(cfg.L1EthRpc)
+	if len(endpoints) == 0 {
+		return failover.ErrNoEndpoints
+	}
+	l1Client, err := ethclient.DialContext(context.Background(), endpoints[0])
+	if err != nil {
+		return err
+	}
+	defer l1Client.Close()
+
+	report, err := replaySubmission(
+		context.Background(), cfg, service, record, l1Client,
+	)
+	if err != nil {
+		return err
+	}
+	printReplayReport(os.Stdout, record, report)
+
+	if !report.Conforms() {
+		return ErrReplayViolations
+	}
+	return nil
+}
+
+// replaySubmission replays record against the fee policy of the named service,
+// fetching the base fees of the L1 blocks mined from its first attempt until it
+// concluded.
+func replaySubmission(
+	ctx context.Context,
+	cfg Config,
+	service string,
+	record *queue.SubmissionRecord,
+	l1 headerSource,
+) (*txmgr.ReplayReport, error) {
+
+	// A confirmed submission concluded in its including block, and an
+	// abandoned one when it was last written.
+	end := time.Now()
+	switch {
+	case record.Receipt != nil:
+		header, err := l1.HeaderByNumber(
+			ctx, new(big.Int).SetUint64(record.Receipt.BlockNumber),
+		)
+		if err != nil {
+			return nil, err
+		}
+		end = time.Unix(int64(header.Time), 0)
+	case record.Status != queue.SubmissionPending:
+		end = record.UpdatedAt
+	}
+
+	attempts := make([]txmgr.Attempt, 0, len(record.Attempts))
+	for _, attempt := range record.Attempts {
+		attempts = append(attempts, txmgr.Attempt{
+			TxHash:      attempt.TxHash,
+			GasPrice:    attempt.GasPrice,
+			PublishedAt: attempt.PublishedAt,
+		})
+	}
+
+	fees, err := fetchBlockFees(ctx, l1, attempts[0].PublishedAt, end)
+	if err != nil {
+		return nil, err
+	}
+
+	oracle := cfg.SequencerGasPriceOracle
+	if strings.HasSuffix(service, "Proposer") {
+		oracle = cfg.ProposerGasPriceOracle
+	}
+	txMgrCfg := newTxManagerConfig(cfg)
+	policy := txmgr.ReplayPolicy{
+		MinGasPrice:         txMgrCfg.MinGasPrice,
+		MaxGasPrice:         txMgrCfg.MaxGasPrice,
+		GasRetryIncrement:   txMgrCfg.GasRetryIncrement,
+		GasPricePercent:     txMgrCfg.FeeBump.GasPricePercent,
+		ResubmissionTimeout: txMgrCfg.ResubmissionTimeout,
+		UsesOracle:          oracle != "",
+	}
+
+	return txmgr.Replay(policy, attempts, fees, end), nil
+}
+
+// fetchBlockFees returns the base fees of the L1 blocks timestamped within
+// [start, end], up to replayMaxBlocks. The first block is located by binary
+// search over the block timestamps.
+func fetchBlockFees(
+	ctx context.Context,
+	l1 headerSource,
+	start, end time.Time,
+) ([]txmgr.BlockFee, error) {
+
+	latest, err := l1.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Find the first block timestamped at or after start.
+	lo, hi := uint64(0), latest.Number.Uint64()+1
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		header, err := l1.HeaderByNumber(ctx, new(big.Int).SetUint64(mid))
+		if err != nil {
+			return nil, err
+		}
+		if time.Unix(int64(header.Time), 0).Before(start) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	var fees []txmgr.BlockFee
+	for number := lo; number <= latest.Number.Uint64() &&
+		len(fees) < replayMaxBlocks; number++ {
+
+		header, err := l1.HeaderByNumber(
+			ctx, new(big.Int).SetUint64(number),
+		)
+		if err != nil {
+			return nil, err
+		}
+		minedAt := time.Unix(int64(header.Time), 0)
+		if minedAt.After(end) {
+			break
+		}
+		fees = append(fees, txmgr.BlockFee{
+			Number:  number,
+			Time:    minedAt,
+			BaseFee: header.BaseFee,
+		})
+	}
+
+	return fees, nil
+}
+
+// printReplayReport writes the replayed timeline of record to w as a table,
+// followed by any deviation from the policy.
+func printReplayReport(
+	w io.Writer,
+	record *queue.SubmissionRecord,
+	report *txmgr.ReplayReport,
+) {
+
+	fmt.Fprintf(w, "submission nonce=%d start=%d end=%d status=%s\n\n",
+		record.Nonce, record.Start, record.End, record.Status)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "ATTEMPT\tPUBLISHED\tINTERVAL\tGAS_PRICE_GWEI\t"+
+		"BUMPS\tBLOCKS\tUNDERPRICED\tMAX_BASE_FEE_GWEI\tTX_HASH")
+	for i, step := range report.Steps {
+		maxBaseFee := "-"
+		if step.MaxBaseFee != nil {
+			maxBaseFee = fmt.Sprintf("%f", weiToGwei64(step.MaxBaseFee))
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%v\t%f\t%d\t%d\t%d\t%s\t%s\n", i,
+			step.PublishedAt.UTC().Format(time.RFC3339),
+			step.Interval.Truncate(time.Second),
+			weiToGwei64(step.GasPrice), step.Bumps, step.NumBlocks,
+			step.NumUnderpriced, maxBaseFee, step.TxHash.Hex())
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	for i, step := range report.Steps {
+		for _, violation := range step.Violations {
+			fmt.Fprintf(w, "VIOLATION attempt %d: %s\n", i, violation)
+		}
+	}
+	if report.FirstCompetitive < 0 {
+		fmt.Fprintln(w, "no attempt covered the base fee of every "+
+			"block mined while it was the latest")
+	} else {
+		fmt.Fprintf(w, "first competitive attempt: %d\n",
+			report.FirstCompetitive)
+	}
+	fmt.Fprintf(w, "conforms to policy: %t\n", report.Conforms())
+}
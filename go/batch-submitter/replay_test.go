@@ -0,0 +1,88 @@
+package batchsubmitter
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// testHeaderSource serves a chain of headers mined every 12s from the zero
+// time, each with a base fee of 10 gwei.
+type testHeaderSource struct {
+	numBlocks uint64
+}
+
+func (s testHeaderSource) HeaderByNumber(
+	_ context.Context, number *big.Int) (*types.Header, error) {
+
+	if number == nil {
+		number = new(big.Int).SetUint64(s.numBlocks - 1)
+	}
+	return &types.Header{
+		Number:  number,
+		Time:    12 * number.Uint64(),
+		BaseFee: gasPriceFromGwei(10),
+	}, nil
+}
+
+// TestFetchBlockFees asserts that exactly the blocks timestamped within the
+// given range are fetched.
+func TestFetchBlockFees(t *testing.T) {
+	fees, err := fetchBlockFees(
+		context.Background(), testHeaderSource{numBlocks: 1000},
+		time.Unix(100, 0), time.Unix(1200, 0),
+	)
+	require.Nil(t, err)
+	require.Len(t, fees, 92)
+	require.Equal(t, uint64(9), fees[0].Number)
+	require.Equal(t, uint64(100), fees[len(fees)-1].Number)
+}
+
+// TestReplaySubmission asserts that a confirmed submission is replayed up to
+// its including block, and that deviations from the policy are printed.
+func TestReplaySubmission(t *testing.T) {
+	cfg := Config{
+		MaxGasPriceInGwei:   100,
+		GasRetryIncrement:   5,
+		ResubmissionTimeout: time.Minute,
+	}
+	record := &queue.SubmissionRecord{
+		Nonce:  7,
+		Status: queue.SubmissionConfirmed,
+		Attempts: []queue.SubmissionAttempt{
+			{
+				GasPrice:    gasPriceFromGwei(1),
+				PublishedAt: time.Unix(0, 0),
+			},
+			{
+				GasPrice:    gasPriceFromGwei(6),
+				PublishedAt: time.Unix(60, 0),
+			},
+			{
+				GasPrice:    gasPriceFromGwei(11),
+				PublishedAt: time.Unix(90, 0),
+			},
+		},
+		Receipt: &queue.SubmissionReceipt{BlockNumber: 10},
+	}
+
+	report, err := replaySubmission(
+		context.Background(), cfg, "Sequencer", record,
+		testHeaderSource{numBlocks: 1000},
+	)
+	require.Nil(t, err)
+	require.False(t, report.Conforms())
+	require.Equal(t, 2, report.FirstCompetitive)
+	require.Equal(t, 3, report.Steps[2].NumBlocks)
+
+	var out bytes.Buffer
+	printReplayReport(&out, record, report)
+	require.Contains(t, out.String(), "VIOLATION attempt 2: bumped after")
+	require.Contains(t, out.String(), "conforms to policy: false")
+}
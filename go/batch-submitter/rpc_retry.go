@@ -0,0 +1,200 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// RetryPolicy determines how RPC queries failing within a cycle are retried,
+// and when sustained failures trip a service's circuit breaker.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a failed query is retried within
+	// a cycle before the cycle is abandoned. Zero disables retries, such
+	// that a failed query skips the cycle until the next PollInterval.
+	MaxRetries uint64
+
+	// InitialBackoff is the delay before the first retry, doubling with
+	// each subsequent retry up to MaxBackoff. Up to half of each delay is
+	// randomly shaved off, such that services sharing a provider do not
+	// retry in lockstep.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+
+	// BreakerThreshold, if non-zero, is the number of consecutive queries
+	// failing after exhausting their retries at which the circuit breaker
+	// trips, reporting the service not ready until a query succeeds.
+	BreakerThreshold uint64
+}
+
+// backoff returns the delay before the given retry, counting from zero,
+// including jitter.
+func (p RetryPolicy) backoff(retry uint64) time.Duration {
+	delay := p.InitialBackoff
+	for i := uint64(0); i < retry && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay - time.Duration(rand.Int63n(int64(delay)/2+1))
+}
+
+// circuitBreaker counts consecutive failed queries, tripping once threshold is
+// reached and resetting on the next success.
+//
+// NOTE: circuitBreaker is safe for concurrent use.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	threshold uint64
+	failures  uint64
+}
+
+// newCircuitBreaker initializes a circuitBreaker tripping after threshold
+// consecutive failures. A threshold of zero never trips.
+func newCircuitBreaker(threshold uint64) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+	}
+}
+
+// Success records a successful query, returning true if this closed a tripped
+// breaker.
+func (b *circuitBreaker) Success() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	wasOpen := b.isOpen()
+	b.failures = 0
+	return wasOpen
+}
+
+// Failure records a failed query, returning true if this tripped the breaker.
+func (b *circuitBreaker) Failure() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	return b.threshold > 0 && b.failures == b.threshold
+}
+
+// State returns whether the breaker is tripped, along with the number of
+// consecutive failures.
+func (b *circuitBreaker) State() (bool, uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.isOpen(), b.failures
+}
+
+// isOpen returns true if the breaker is tripped.
+//
+// NOTE: This method MUST be called while holding b.mu.
+func (b *circuitBreaker) isOpen() bool {
+	return b.threshold > 0 && b.failures >= b.threshold
+}
+
+// queryWithRetry runs query, retrying failures within the cycle as determined
+// by the service's RetryPolicy, and records the outcome with its circuit
+// breaker. Each attempt is passed a context bounded by RPCCallTimeout, if set.
+// The last error is returned if every attempt fails, or the context error if
+// ctx is done while backing off.
+func (s *Service) queryWithRetry(
+	ctx context.Context,
+	desc string,
+	query func(ctx context.Context) error,
+) error {
+
+	name := s.cfg.Driver.Name()
+	policy := s.cfg.RPCRetryPolicy
+
+	var err error
+	for retry := uint64(0); ; retry++ {
+		if err = s.callWithTimeout(ctx, query); err == nil {
+			if s.breaker.Success() {
+				log.Info(name+" RPC queries recovered, closing "+
+					"circuit breaker", "query", desc)
+				s.metrics.RPCCircuitOpen.Set(0)
+			}
+			return nil
+		}
+		if ctx.Err() != nil || retry >= policy.MaxRetries {
+			break
+		}
+
+		delay := policy.backoff(retry)
+		log.Warn(name+" RPC query failed, retrying", "query", desc,
+			"retry", retry+1, "max_retries", policy.MaxRetries,
+			"backoff", delay, "err", err)
+		s.metrics.RPCRetries.Inc()
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	if s.breaker.Failure() {
+		_, failures := s.breaker.State()
+		log.Error(name+" sustained RPC failures, tripping circuit "+
+			"breaker", "query", desc, "failures", failures, "err", err)
+		s.metrics.RPCCircuitOpen.Set(1)
+	}
+
+	return err
+}
+
+// callWithTimeout runs query with a context derived from ctx that is bounded
+// by RPCCallTimeout, if set.
+func (s *Service) callWithTimeout(
+	ctx context.Context,
+	query func(ctx context.Context) error,
+) error {
+
+	if s.cfg.RPCCallTimeout == 0 {
+		return query(ctx)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, s.cfg.RPCCallTimeout)
+	defer cancel()
+
+	return query(callCtx)
+}
+
+// cycleContext returns the context of a cycle, which is bounded by
+// MaxCycleDuration if set.
+func (s *Service) cycleContext() (context.Context, context.CancelFunc) {
+	if s.cfg.MaxCycleDuration == 0 {
+		return context.WithCancel(s.ctx)
+	}
+
+	return context.WithTimeout(s.ctx, s.cfg.MaxCycleDuration)
+}
+
+// checkCycleDeadline records a cycle that failed after exceeding the deadline
+// of its context, ctx.
+func (s *Service) checkCycleDeadline(ctx context.Context, trace *CycleTrace) {
+	if trace.Outcome != CycleFailed ||
+		!errors.Is(ctx.Err(), context.DeadlineExceeded) {
+
+		return
+	}
+
+	log.Warn(s.cfg.Driver.Name()+" cycle aborted after exceeding max "+
+		"cycle duration", "max_cycle_duration", s.cfg.MaxCycleDuration)
+	s.metrics.CyclesDeadlineExceeded.Inc()
+}
@@ -0,0 +1,185 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// newRetryTestService returns a service retrying queries using policy.
+func newRetryTestService(
+	ctx context.Context, name string, policy RetryPolicy) *Service {
+
+	return &Service{
+		cfg: ServiceConfig{
+			Driver:         namedDriver{name: name},
+			RPCRetryPolicy: policy,
+		},
+		ctx:     ctx,
+		metrics: metrics.NewMetrics(name),
+		health:  newHealthState(),
+		breaker: newCircuitBreaker(policy.BreakerThreshold),
+	}
+}
+
+// TestRetryPolicyBackoff asserts that the backoff doubles up to the max, with
+// at most half of each delay shaved off as jitter.
+func TestRetryPolicyBackoff(t *testing.T) {
+	t.Parallel()
+
+	policy := RetryPolicy{
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+	}
+	for retry, max := range []time.Duration{
+		100 * time.Millisecond,
+		200 * time.Millisecond,
+		400 * time.Millisecond,
+		800 * time.Millisecond,
+		time.Second,
+		time.Second,
+	} {
+		delay := policy.backoff(uint64(retry))
+		require.LessOrEqual(t, int64(delay), int64(max))
+		require.GreaterOrEqual(t, int64(delay), int64(max/2))
+	}
+}
+
+// TestServiceQueryWithRetry asserts that a failed query is retried until it
+// succeeds, and that the cycle is abandoned once the retries are exhausted.
+func TestServiceQueryWithRetry(t *testing.T) {
+	t.Parallel()
+
+	s := newRetryTestService(context.Background(),
+		"TestServiceQueryWithRetry", RetryPolicy{
+			MaxRetries:     2,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		})
+
+	errRPC := errors.New("connection refused")
+	var calls int
+	err := s.queryWithRetry(s.ctx, "balance", func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errRPC
+		}
+		return nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, 3, calls)
+
+	calls = 0
+	err = s.queryWithRetry(s.ctx, "balance", func(context.Context) error {
+		calls++
+		return errRPC
+	})
+	require.Equal(t, errRPC, err)
+	require.Equal(t, 3, calls)
+}
+
+// TestServiceQueryWithRetryCanceled asserts that backing off is interrupted
+// when the service stops.
+func TestServiceQueryWithRetryCanceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s := newRetryTestService(ctx, "TestServiceQueryWithRetryCanceled",
+		RetryPolicy{
+			MaxRetries:       5,
+			InitialBackoff:   time.Hour,
+			MaxBackoff:       time.Hour,
+			BreakerThreshold: 1,
+		})
+
+	go cancel()
+	err := s.queryWithRetry(s.ctx, "nonce", func(context.Context) error {
+		return errors.New("connection refused")
+	})
+	require.Equal(t, context.Canceled, err)
+
+	// Stopping is not counted as a failure.
+	open, _ := s.breaker.State()
+	require.False(t, open)
+}
+
+// TestServiceCircuitBreaker asserts that the service reports not ready after
+// BreakerThreshold consecutive failed queries, and ready again once a query
+// succeeds.
+func TestServiceCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	s := newRetryTestService(context.Background(),
+		"TestServiceCircuitBreaker", RetryPolicy{BreakerThreshold: 2})
+
+	errRPC := errors.New("connection refused")
+	failing := func(context.Context) error { return errRPC }
+
+	require.Equal(t, errRPC, s.queryWithRetry(s.ctx, "block_range", failing))
+	require.True(t, s.Readiness(context.Background()).Healthy)
+
+	require.Equal(t, errRPC, s.queryWithRetry(s.ctx, "block_range", failing))
+	health := s.Readiness(context.Background())
+	require.False(t, health.Healthy)
+	require.Equal(t, "rpc_circuit", health.Checks[0].Name)
+
+	require.Nil(t, s.queryWithRetry(s.ctx, "block_range", func(context.Context) error {
+		return nil
+	}))
+	require.True(t, s.Readiness(context.Background()).Healthy)
+}
+
+// TestServiceQueryWithRetryTimeout asserts that a hung query is abandoned once
+// RPCCallTimeout elapses, and retried with a fresh timeout.
+func TestServiceQueryWithRetryTimeout(t *testing.T) {
+	t.Parallel()
+
+	s := newRetryTestService(context.Background(),
+		"TestServiceQueryWithRetryTimeout", RetryPolicy{
+			MaxRetries:     1,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+		})
+	s.cfg.RPCCallTimeout = 10 * time.Millisecond
+
+	var calls int
+	err := s.queryWithRetry(s.ctx, "block_range",
+		func(ctx context.Context) error {
+			calls++
+			<-ctx.Done()
+			return ctx.Err()
+		})
+	require.Equal(t, context.DeadlineExceeded, err)
+	require.Equal(t, 2, calls)
+}
+
+// TestServiceCycleDeadline asserts that a cycle is bounded by
+// MaxCycleDuration, and that only failed cycles exceeding it are counted as
+// aborted.
+func TestServiceCycleDeadline(t *testing.T) {
+	t.Parallel()
+
+	s := newRetryTestService(context.Background(),
+		"TestServiceCycleDeadline", RetryPolicy{})
+	s.cfg.MaxCycleDuration = 10 * time.Millisecond
+
+	ctx, cancel := s.cycleContext()
+	defer cancel()
+
+	err := s.queryWithRetry(ctx, "balance", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	s.checkCycleDeadline(ctx, &CycleTrace{Outcome: CycleSkipped})
+	require.Equal(t, 0.0, testutil.ToFloat64(s.metrics.CyclesDeadlineExceeded))
+
+	s.checkCycleDeadline(ctx, &CycleTrace{Outcome: CycleFailed})
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.CyclesDeadlineExceeded))
+}
@@ -0,0 +1,32 @@
+package rpcmetrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// The metrics of every Transport are shared by the process, and labeled by
+// the transport's backend and the JSON-RPC method of each call.
+var (
+	// rpcCallDuration observes the latency of calls to each method.
+	rpcCallDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "batch_submitter_rpc_call_duration_seconds",
+		Help:    "Latency of RPC calls to each method",
+		Buckets: []float64{.01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"backend", "method"})
+
+	// rpcCallErrors counts the calls to each method that failed, labeled
+	// by whether the request failed in transport, was refused with an
+	// HTTP error status, or returned a JSON-RPC error.
+	rpcCallErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "batch_submitter_rpc_call_errors",
+		Help: "Count of failed RPC calls to each method",
+	}, []string{"backend", "method", "kind"})
+
+	// rpcCallsInFlight tracks the calls to each method awaiting a
+	// response.
+	rpcCallsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "batch_submitter_rpc_calls_in_flight",
+		Help: "Number of RPC calls to each method awaiting a response",
+	}, []string{"backend", "method"})
+)
@@ -0,0 +1,165 @@
+package rpcmetrics
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+// The kinds of errors recorded for failed calls.
+const (
+	errorKindTransport = "transport"
+	errorKindHTTP      = "http"
+	errorKindRPC       = "rpc"
+)
+
+// unknownMethod labels calls whose request could not be parsed.
+const unknownMethod = "unknown"
+
+// Transport is an http.RoundTripper recording the latency, errors and
+// in-flight count of each JSON-RPC call made through it, labeled by method,
+// e.g. eth_getBlockByNumber, eth_getTransactionCount or
+// eth_sendRawTransaction. This allows operators to tell slow RPCs apart from
+// slow batch building. Batched calls are recorded individually, each observing
+// the latency of the whole batch.
+//
+// NOTE: Subscriptions over WebSocket bypass HTTP, and are not instrumented.
+type Transport struct {
+	backend string
+	base    http.RoundTripper
+}
+
+// NewTransport initializes a Transport labeling its metrics with backend, and
+// forwarding requests to base, or http.DefaultTransport if base is nil.
+func NewTransport(backend string, base http.RoundTripper) *Transport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &Transport{
+		backend: backend,
+		base:    base,
+	}
+}
+
+// jsonrpcMessage is the subset of a JSON-RPC request or response used to
+// attribute errors to calls.
+type jsonrpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+// RoundTrip forwards req, recording the outcome of each call it contains.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	calls := parseMessages(body)
+	if len(calls) == 0 {
+		calls = []jsonrpcMessage{{}}
+	}
+	for i := range calls {
+		if calls[i].Method == "" {
+			calls[i].Method = unknownMethod
+		}
+	}
+
+	// Forward a copy of the request, as a RoundTripper must not modify
+	// the request it is given.
+	fwd := req.Clone(req.Context())
+	fwd.Body = ioutil.NopCloser(bytes.NewReader(body))
+	fwd.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	for _, call := range calls {
+		rpcCallsInFlight.WithLabelValues(t.backend, call.Method).Inc()
+	}
+	start := time.Now()
+
+	resp, respBody, err := t.roundTrip(fwd)
+
+	duration := time.Since(start).Seconds()
+	failed := make(map[string]string)
+	switch {
+	case err != nil:
+		failed[""] = errorKindTransport
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		failed[""] = errorKindHTTP
+	default:
+		for _, msg := range parseMessages(respBody) {
+			if len(msg.Error) > 0 && string(msg.Error) != "null" {
+				failed[string(msg.ID)] = errorKindRPC
+			}
+		}
+	}
+
+	for _, call := range calls {
+		rpcCallsInFlight.WithLabelValues(t.backend, call.Method).Dec()
+		rpcCallDuration.WithLabelValues(t.backend, call.Method).
+			Observe(duration)
+
+		kind, ok := failed[""]
+		if !ok {
+			kind, ok = failed[string(call.ID)]
+		}
+		if ok {
+			rpcCallErrors.WithLabelValues(
+				t.backend, call.Method, kind,
+			).Inc()
+		}
+	}
+
+	return resp, err
+}
+
+// roundTrip forwards req, buffering the body of the response such that it can
+// be inspected before being returned to the caller.
+func (t *Transport) roundTrip(req *http.Request) (*http.Response, []byte, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	return resp, body, nil
+}
+
+// parseMessages decodes a single or batched JSON-RPC payload, returning nil if
+// it is malformed.
+func parseMessages(data []byte) []jsonrpcMessage {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil
+	}
+
+	if data[0] == '[' {
+		var msgs []jsonrpcMessage
+		if err := json.Unmarshal(data, &msgs); err != nil {
+			return nil
+		}
+		return msgs
+	}
+
+	var msg jsonrpcMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil
+	}
+	return []jsonrpcMessage{msg}
+}
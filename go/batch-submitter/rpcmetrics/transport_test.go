@@ -0,0 +1,155 @@
+package rpcmetrics_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/rpcmetrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/telemetry"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestServer returns a JSON-RPC server failing calls to eth_chainId, and
+// answering any other with the block number 0x1.
+func newTestServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			var msg struct {
+				ID     json.RawMessage `json:"id"`
+				Method string          `json:"method"`
+			}
+			_ = json.NewDecoder(req.Body).Decode(&msg)
+
+			w.Header().Set("Content-Type", "application/json")
+			if msg.Method == "eth_chainId" {
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,`+
+					`"error":{"code":-32000,"message":"boom"}}`,
+					msg.ID)
+				return
+			}
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x1"}`,
+				msg.ID)
+		},
+	))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+// sampleValue returns the value of the series of name labeled with labels, or
+// the count of observations of a histogram.
+func sampleValue(
+	t *testing.T,
+	name string,
+	labels map[string]string,
+) float64 {
+
+	samples, err := telemetry.Gather(prometheus.DefaultGatherer)
+	require.Nil(t, err)
+
+	for _, sample := range samples {
+		if sample.Name != name {
+			continue
+		}
+		matches := true
+		for k, v := range labels {
+			matches = matches && sample.Labels[k] == v
+		}
+		if !matches {
+			continue
+		}
+		if sample.Kind == telemetry.KindHistogram {
+			return float64(sample.Count)
+		}
+		return sample.Value
+	}
+
+	return 0
+}
+
+// TestTransportRecordsCalls asserts that the latency of each call is observed
+// per method, that JSON-RPC errors are counted, and that responses reach the
+// caller intact.
+func TestTransportRecordsCalls(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	backend := "TestTransportRecordsCalls"
+	client, err := rpc.DialHTTPWithClient(server.URL, &http.Client{
+		Transport: rpcmetrics.NewTransport(backend, nil),
+	})
+	require.Nil(t, err)
+	defer client.Close()
+
+	var blockNumber string
+	for i := 0; i < 2; i++ {
+		err = client.CallContext(
+			context.Background(), &blockNumber, "eth_blockNumber",
+		)
+		require.Nil(t, err)
+		require.Equal(t, "0x1", blockNumber)
+	}
+
+	var chainID string
+	err = client.CallContext(context.Background(), &chainID, "eth_chainId")
+	require.NotNil(t, err)
+
+	require.Equal(t, 2.0, sampleValue(t,
+		"batch_submitter_rpc_call_duration_seconds",
+		map[string]string{"backend": backend, "method": "eth_blockNumber"},
+	))
+	require.Equal(t, 0.0, sampleValue(t,
+		"batch_submitter_rpc_call_errors",
+		map[string]string{"backend": backend, "method": "eth_blockNumber"},
+	))
+	require.Equal(t, 1.0, sampleValue(t,
+		"batch_submitter_rpc_call_errors",
+		map[string]string{
+			"backend": backend,
+			"method":  "eth_chainId",
+			"kind":    "rpc",
+		},
+	))
+	require.Equal(t, 0.0, sampleValue(t,
+		"batch_submitter_rpc_calls_in_flight",
+		map[string]string{"backend": backend, "method": "eth_chainId"},
+	))
+}
+
+// TestTransportRecordsTransportErrors asserts that calls failing in transport
+// are counted.
+func TestTransportRecordsTransportErrors(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	url := server.URL
+	server.Close()
+
+	backend := "TestTransportRecordsTransportErrors"
+	client, err := rpc.DialHTTPWithClient(url, &http.Client{
+		Transport: rpcmetrics.NewTransport(backend, nil),
+	})
+	require.Nil(t, err)
+	defer client.Close()
+
+	var blockNumber string
+	err = client.CallContext(
+		context.Background(), &blockNumber, "eth_blockNumber",
+	)
+	require.NotNil(t, err)
+
+	require.Equal(t, 1.0, sampleValue(t,
+		"batch_submitter_rpc_call_errors",
+		map[string]string{
+			"backend": backend,
+			"method":  "eth_blockNumber",
+			"kind":    "transport",
+		},
+	))
+}
@@ -0,0 +1,123 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	// SelfTestOff skips the startup self-test.
+	SelfTestOff = "off"
+
+	// SelfTestStrict refuses to start a service whose self-test fails.
+	SelfTestStrict = "strict"
+
+	// SelfTestDegraded starts a service whose self-test fails, raising
+	// the self_test_failed metric and logging the failures as errors.
+	SelfTestDegraded = "degraded"
+
+	// selfTestTimeout bounds the duration of the startup self-test.
+	selfTestTimeout = 30 * time.Second
+)
+
+// ErrSelfTestFailed signals that at least one check of the startup self-test
+// failed.
+var ErrSelfTestFailed = errors.New("self-test failed")
+
+// SelfTester is an optional interface that may be implemented by a Driver to
+// verify its dependencies, e.g. signing and the L1 and L2 backends, before the
+// service starts. No tx may be published.
+type SelfTester interface {
+	SelfTest(ctx context.Context) error
+}
+
+// selfTestCheck is a single named check of the startup self-test.
+type selfTestCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+// selfTest runs every check of the startup self-test, returning an error
+// describing each failed check.
+func (s *Service) selfTest() error {
+	name := s.cfg.Driver.Name()
+
+	var checks []selfTestCheck
+	if tester, ok := s.cfg.Driver.(SelfTester); ok {
+		checks = append(checks, selfTestCheck{"driver", tester.SelfTest})
+	}
+	checks = append(checks, selfTestCheck{"metrics",
+		func(context.Context) error {
+			_, err := prometheus.DefaultGatherer.Gather()
+			return err
+		},
+	})
+	if q := s.cfg.SubmissionQueue; q != nil {
+		checks = append(checks, selfTestCheck{"submission_queue",
+			func(context.Context) error {
+				return q.CheckWritable()
+			},
+		})
+	}
+	if store := s.cfg.StateStore; store != nil {
+		checks = append(checks, selfTestCheck{"state_store",
+			func(context.Context) error {
+				return store.CheckWritable()
+			},
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(s.ctx, selfTestTimeout)
+	defer cancel()
+
+	var failures []string
+	for _, c := range checks {
+		if err := c.check(ctx); err != nil {
+			log.Error(name+" self-test check failed", "check", c.name,
+				"err", err)
+			failures = append(failures,
+				fmt.Sprintf("%s: %v", c.name, err))
+			continue
+		}
+		log.Info(name+" self-test check passed", "check", c.name)
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%w: %s", ErrSelfTestFailed,
+			strings.Join(failures, "; "))
+	}
+
+	return nil
+}
+
+// runSelfTest runs the startup self-test in the configured mode, returning an
+// error only if the service must not start.
+func (s *Service) runSelfTest() error {
+	name := s.cfg.Driver.Name()
+
+	if s.cfg.SelfTestMode == "" || s.cfg.SelfTestMode == SelfTestOff {
+		return nil
+	}
+
+	err := s.selfTest()
+	if err == nil {
+		s.metrics.SelfTestFailed.Set(0)
+		log.Info(name + " self-test passed")
+		return nil
+	}
+
+	s.metrics.SelfTestFailed.Set(1)
+	if s.cfg.SelfTestMode == SelfTestStrict {
+		return err
+	}
+
+	log.Error(name+" self-test failed, starting degraded", "err", err)
+	s.selfTestErr = err.Error()
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/stretchr/testify/require"
+)
+
+// selfTestDriver is a Driver whose self-test returns err.
+type selfTestDriver struct {
+	namedDriver
+	err error
+}
+
+func (d selfTestDriver) SelfTest(context.Context) error {
+	return d.err
+}
+
+// TestServiceSelfTest asserts that a failed self-test prevents the service
+// from starting in strict mode, starts it degraded in degraded mode, and is
+// skipped entirely when off.
+func TestServiceSelfTest(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "self-test")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	q, err := queue.NewSubmissionQueue(dir, 0)
+	require.Nil(t, err)
+	defer q.Close()
+
+	m := metrics.NewMetrics("TestServiceSelfTest")
+	newService := func(mode string, driverErr error) *Service {
+		return &Service{
+			cfg: ServiceConfig{
+				Driver: selfTestDriver{
+					namedDriver: namedDriver{
+						name: "TestServiceSelfTest",
+					},
+					err: driverErr,
+				},
+				SubmissionQueue: q,
+				SelfTestMode:    mode,
+			},
+			ctx:     context.Background(),
+			metrics: m,
+		}
+	}
+
+	s := newService(SelfTestStrict, nil)
+	require.Nil(t, s.runSelfTest())
+	require.Empty(t, s.selfTestErr)
+
+	// The queue probe leaves no trace in the queue.
+	n, err := q.Len()
+	require.Nil(t, err)
+	require.Equal(t, 0, n)
+
+	driverErr := errors.New("unable to reach L1")
+
+	s = newService(SelfTestStrict, driverErr)
+	err = s.runSelfTest()
+	require.True(t, errors.Is(err, ErrSelfTestFailed))
+	require.Contains(t, err.Error(), driverErr.Error())
+
+	s = newService(SelfTestDegraded, driverErr)
+	require.Nil(t, s.runSelfTest())
+	require.Contains(t, s.selfTestErr, driverErr.Error())
+
+	s = newService(SelfTestOff, driverErr)
+	require.Nil(t, s.runSelfTest())
+	require.Empty(t, s.selfTestErr)
+}
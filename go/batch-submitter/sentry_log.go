@@ -2,6 +2,7 @@ package batchsubmitter
 
 import (
 	"errors"
+	"fmt"
 	"io"
 
 	"github.com/ethereum/go-ethereum/log"
@@ -14,7 +15,9 @@ var jsonFmt = log.JSONFormat()
 // log.StreamHandler, however it writes any log with severity greater than or
 // equal to log.LvlError to Sentry. In that case, the passed log.Record is
 // encoded using JSON rather than the default terminal output, so that it can be
-// captured for debugging in the Sentry dashboard.
+// captured for debugging in the Sentry dashboard. The record's key/value pairs
+// are also attached as tags, such that events can be searched by fields like
+// the range or nonce of a failed submission, and events are grouped by message.
 func SentryStreamHandler(wr io.Writer, fmtr log.Format) log.Handler {
 	h := log.FuncHandler(func(r *log.Record) error {
 		_, err := wr.Write(fmtr.Format(r))
@@ -29,6 +32,8 @@ func SentryStreamHandler(wr io.Writer, fmtr log.Format) log.Handler {
 		if r.Lvl <= log.LvlError {
 			sentry.WithScope(func(scope *sentry.Scope) {
 				scope.SetExtra("context", jsonFmt.Format(r))
+				scope.SetTags(sentryTags(r.Ctx))
+				scope.SetFingerprint([]string{r.Msg})
 				sentry.CaptureException(errors.New(r.Msg))
 			})
 		}
@@ -36,3 +41,18 @@ func SentryStreamHandler(wr io.Writer, fmtr log.Format) log.Handler {
 	})
 	return log.LazyHandler(log.SyncHandler(h))
 }
+
+// sentryTags formats the key/value pairs of a log record as Sentry tags. Pairs
+// whose key is not a string are skipped.
+func sentryTags(ctx []interface{}) map[string]string {
+	tags := make(map[string]string, len(ctx)/2)
+	for i := 0; i+1 < len(ctx); i += 2 {
+		key, ok := ctx[i].(string)
+		if !ok {
+			continue
+		}
+		tags[key] = fmt.Sprint(ctx[i+1])
+	}
+
+	return tags
+}
@@ -2,11 +2,20 @@ package batchsubmitter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"math/big"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/proofs"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -17,6 +26,9 @@ import (
 var (
 	// weiToEth is the conversion rate from wei to ether.
 	weiToEth = new(big.Float).SetFloat64(1e-18)
+
+	// weiToGwei is the conversion rate from wei to gwei.
+	weiToGwei = new(big.Float).SetFloat64(1e-9)
 )
 
 // Driver is an interface for creating and submitting batch transactions for a
@@ -47,12 +59,243 @@ type Driver interface {
 	) (*types.Transaction, error)
 }
 
+// BatchBuilder is an optional interface that may be implemented by a Driver
+// to separate building a batch from publishing it. This allows built batches
+// to be persisted in a SubmissionQueue, such that they survive restarts and
+// are published in order.
+type BatchBuilder interface {
+	// BuildBatch transforms the L2 blocks between start and end into a
+	// batch payload, without signing or publishing it. The returned batch
+	// may cover fewer blocks than requested.
+	BuildBatch(ctx context.Context, start, end *big.Int) (*queue.Batch, error)
+
+	// SubmitBuiltBatch signs and publishes a batch previously returned by
+	// BuildBatch using the given nonce and gasPrice.
+	SubmitBuiltBatch(
+		ctx context.Context,
+		batch *queue.Batch,
+		nonce, gasPrice *big.Int,
+	) (*types.Transaction, error)
+}
+
+// InclusionProver is an optional interface that may be implemented by a Driver
+// whose batch root is a merkle root of the batch's elements, allowing the
+// inclusion of each element to be proven to downstream verifiers.
+type InclusionProver interface {
+	// InclusionProof proves the inclusion of each element of the batch
+	// confirmed by receipt within the batch root committed on L1.
+	InclusionProof(
+		ctx context.Context,
+		receipt *types.Receipt,
+	) (*proofs.BatchProof, error)
+}
+
+// BatchVerifier is an optional interface that may be implemented by a Driver
+// whose batches can be re-derived from L1, verifying end-to-end that what was
+// committed matches the L2 blocks that were batched.
+type BatchVerifier interface {
+	// VerifyBatch decodes the batch tx confirmed by receipt and compares it
+	// against the batch derived from L2, returning a description of each
+	// mismatch. The error is non-nil if the batch could not be verified.
+	VerifyBatch(ctx context.Context, receipt *types.Receipt) ([]string, error)
+}
+
+// RangeDeferrer is an optional interface that may be implemented by a Driver
+// that defers pending ranges too small to be worth a batch tx, such that cycles
+// deferring a range can be told apart from those without new L2 blocks.
+type RangeDeferrer interface {
+	// GetDeferrableBlockRange is GetBatchBlockRange, additionally reporting
+	// whether a non-empty pending range was deferred, in which case the
+	// returned start and end are identical.
+	GetDeferrableBlockRange(
+		ctx context.Context) (*big.Int, *big.Int, bool, error)
+}
+
+// TxSizeLimiter is an optional interface that may be implemented by a Driver
+// whose max batch tx size can be adjusted at runtime.
+type TxSizeLimiter interface {
+	// MaxTxSize returns the max size of a batch tx.
+	MaxTxSize() uint64
+
+	// SetMaxTxSize adjusts the max size of subsequently built batch txs.
+	SetMaxTxSize(size uint64)
+}
+
 type ServiceConfig struct {
 	Context         context.Context
 	Driver          Driver
 	PollInterval    time.Duration
 	L1Client        *ethclient.Client
 	TxManagerConfig txmgr.Config
+
+	// SubmissionQueue, if non-nil and the Driver implements BatchBuilder,
+	// persists built batches until they are confirmed.
+	SubmissionQueue *queue.SubmissionQueue
+
+	// StateStore, if non-nil, records each published batch tx, such that
+	// any left pending are resumed after a restart.
+	StateStore *queue.StateStore
+
+	// ProofStore, if non-nil and the Driver implements InclusionProver,
+	// stores an inclusion proof of each confirmed batch.
+	ProofStore *queue.ProofStore
+
+	// Archiver, if non-nil, archives the sealed payload of each confirmed
+	// batch.
+	Archiver *archive.Archiver
+
+	// VerifyBatches, if true and the Driver implements BatchVerifier,
+	// verifies each confirmed batch against the L2 blocks it covers.
+	VerifyBatches bool
+
+	// DeferAboveMaxGasPrice, if true, skips any cycle in which the L1
+	// backend's suggested gas price exceeds TxManagerConfig.MaxGasPrice.
+	DeferAboveMaxGasPrice bool
+
+	// DryRun, if true, signs a single batch tx per cycle at the minimum gas
+	// price without waiting for confirmation. The Driver must also be
+	// configured not to publish the tx.
+	DryRun bool
+
+	// NonceGapFiller, if non-nil, is used to fill any gap detected in the
+	// submitter's nonces. Otherwise, missing nonces are reassigned.
+	NonceGapFiller noncemgr.GapFiller
+
+	// SelfTxSender publishes zero-value self-transactions from the
+	// submitter's wallet, used to cancel pending txs.
+	SelfTxSender noncemgr.SelfTxSender
+
+	// ClearPendingTxs, if true, replaces any txs pending from the
+	// submitter's wallet before the event loop starts.
+	ClearPendingTxs bool
+
+	// PendingTxStrategy determines how pending txs are replaced, either
+	// PendingTxStrategyCancel or PendingTxStrategyReplace.
+	PendingTxStrategy string
+
+	// MaxInFlightBatches, if greater than one and the Driver implements
+	// BatchBuilder, allows up to MaxInFlightBatches batch txs at
+	// consecutive nonces to await confirmation at once.
+	MaxInFlightBatches uint64
+
+	// CatchUpChunkSize, if non-zero, is the max number of L2 blocks covered
+	// by each cycle. While the backlog exceeds it, cycles submit chunks of
+	// the backlog back to back, without waiting PollInterval in between.
+	CatchUpChunkSize uint64
+
+	// CatchUpBudget bounds the spend and duration of catching up on a
+	// backlog larger than CatchUpChunkSize, if any of its bounds is set.
+	// Each catch-up is planned within the budget, and a plan estimated to
+	// cost more than its ApprovalThreshold awaits approval by an operator.
+	CatchUpBudget CatchUpBudget
+
+	// SpendBudget, if positive, is the max spend in wei of the batch txs
+	// confirmed within spendBudgetWindow, after which cycles are skipped
+	// until earlier spend leaves the window.
+	SpendBudget *big.Int
+
+	// PriorityLaneAge, if non-zero and the Driver implements
+	// PriorityLaneDriver, is the age of the oldest pending queue element at
+	// which submission preempts the deferral of small ranges and high gas
+	// prices. It should be set to the force-inclusion period less a margin
+	// for the batch tx to confirm.
+	PriorityLaneAge time.Duration
+
+	// PriorityMinGasPrice, if non-nil, is the gas price at which the batch
+	// txs of the priority lane are first published.
+	PriorityMinGasPrice *big.Int
+
+	// AddressBook, if non-nil, labels the submitter's wallet in logs and
+	// the status API.
+	AddressBook *AddressBook
+
+	// MaxSubmissionAttempts, if non-zero, is the number of consecutive
+	// failed submissions of a range after which it is quarantined until
+	// released by an operator.
+	MaxSubmissionAttempts uint64
+
+	// SelfTestMode determines how the startup self-test is run, either
+	// SelfTestOff, SelfTestStrict or SelfTestDegraded. The self-test is
+	// skipped if empty.
+	SelfTestMode string
+
+	// MinBalance, if positive, is the wallet balance in wei below which
+	// errors are logged, a warning is raised and the service reports not
+	// ready.
+	MinBalance *big.Int
+
+	// CriticalBalance, if positive, is the wallet balance in wei below
+	// which submission halts until the wallet is refunded, rather than
+	// publishing batch txs that are likely to fail.
+	CriticalBalance *big.Int
+
+	// BalanceNotifiers are alerted whenever the wallet balance crosses
+	// MinBalance or CriticalBalance, its drain rate becomes abnormal, or
+	// a confirmed batch fails verification.
+	BalanceNotifiers []alerts.Notifier
+
+	// BalanceDrainWindow, if non-zero, is the trailing window over which
+	// the wallet's drain rate is measured and compared to its baseline.
+	BalanceDrainWindow time.Duration
+
+	// BalanceDrainFactor is the multiple of the baseline drain rate above
+	// which the drain rate is abnormal.
+	BalanceDrainFactor float64
+
+	// MinBalanceDrainRate is the drain rate, in ETH per hour, below which
+	// the drain rate is never abnormal, no matter the baseline.
+	MinBalanceDrainRate float64
+
+	// StartupGracePeriod is the duration after startup during which
+	// cycles only observe chain state and pending txs, and no batch txs
+	// are published.
+	StartupGracePeriod time.Duration
+
+	// MaxCycleAge, if non-zero, is the duration after which a service
+	// that has not completed a cycle reports not live.
+	MaxCycleAge time.Duration
+
+	// MaxConfirmationAge, if non-zero, is the duration after which a
+	// service that has not confirmed a batch tx reports not ready.
+	MaxConfirmationAge time.Duration
+
+	// RPCRetryPolicy determines how failed balance, nonce and block range
+	// queries are retried within a cycle, and when sustained failures
+	// report the service not ready.
+	RPCRetryPolicy RetryPolicy
+
+	// RPCCallTimeout, if non-zero, bounds each balance, nonce and block
+	// range query made within a cycle, such that a hung call is retried
+	// rather than stalling the cycle.
+	RPCCallTimeout time.Duration
+
+	// MaxCycleDuration, if non-zero, bounds the queries and batch building
+	// of each cycle, after which the cycle is aborted.
+	MaxCycleDuration time.Duration
+
+	// Elector, if non-nil, determines whether this replica is the leader
+	// in high availability mode. Cycles are skipped while another replica
+	// holds leadership, and the submission state is recovered each time
+	// leadership is gained.
+	Elector LeaderElector
+
+	// Build, if non-nil, is recorded alongside each submission in the
+	// StateStore, identifying the submitter that built its batch.
+	Build *queue.BuildInfo
+
+	// WalletRotationBalance, if non-nil and non-zero, is the balance below
+	// which the authorized sender of a Driver implementing WalletRotator is
+	// alerted on, and which a wallet must hold to take over submission.
+	WalletRotationBalance *big.Int
+
+	// WalletWedgedAfter, if non-zero, is the duration for which txs may
+	// remain pending behind an unchanged latest nonce of the authorized
+	// sender of a Driver implementing WalletRotator before it is alerted on.
+	WalletWedgedAfter time.Duration
+
+	// OnCycle, if non-nil, is called from the event loop with the trace of
+	// each completed cycle, before the next cycle is scheduled.
+	OnCycle func(trace *CycleTrace)
 }
 
 type Service struct {
@@ -60,8 +303,120 @@ type Service struct {
 	ctx    context.Context
 	cancel func()
 
-	txMgr   txmgr.TxManager
-	metrics *metrics.Metrics
+	txMgr    txmgr.TxManager
+	nonceMgr *noncemgr.Manager
+	metrics  *metrics.Metrics
+
+	// priorityMgr publishes the batch txs of the priority lane, at the
+	// elevated fee tier if one is configured.
+	priorityMgr txmgr.TxManager
+
+	// wedge tracks whether the nonce of the active wallet is wedged, and
+	// is only accessed from the event loop.
+	wedge nonceWedge
+
+	// rotationRefused is set once an alert was raised for a wallet rotation
+	// refused for lack of an authorized wallet, and is only accessed from
+	// the event loop.
+	rotationRefused bool
+
+	// batchBuilder is set when built batches are routed through the
+	// configured SubmissionQueue, or published in pipelined mode.
+	batchBuilder BatchBuilder
+
+	// pipeline tracks the batch txs in flight, and is only set in
+	// pipelined mode.
+	pipeline *pipeline
+
+	// traces retains the decision traces of the most recent cycles.
+	traces *traceHistory
+
+	// retries counts the failed submissions of the next range, and holds
+	// it in quarantine once MaxSubmissionAttempts is reached.
+	retries *retryBudget
+
+	// confirmedHeight is the exclusive end of the highest batch to reach
+	// the required confirmation depth since startup, or zero if none has.
+	confirmedHeight uint64
+
+	// paused is set to one while submission is paused by an operator.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	paused uint32
+
+	// pollInterval is the delay between cycles, initialized from
+	// cfg.PollInterval and adjustable at runtime.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	pollInterval int64
+
+	// trigger wakes the event loop to run a cycle immediately.
+	trigger chan struct{}
+
+	// draining is closed once the service begins draining, after which no
+	// new cycles are started.
+	draining  chan struct{}
+	drainOnce sync.Once
+
+	// catchingUp is set while the last cycle submitted a chunk of a
+	// backlog larger than CatchUpChunkSize, such that the next cycle runs
+	// immediately.
+	//
+	// NOTE: This field MUST only be accessed from the event loop.
+	catchingUp bool
+
+	// catchUp holds the plan of the current catch-up, and is only set if
+	// cfg.CatchUpBudget is enabled.
+	catchUp *catchUpTracker
+
+	// selfTestErr describes the failed checks of the startup self-test,
+	// if the service started degraded. It is set before the service is
+	// registered, and is never modified afterwards.
+	selfTestErr string
+
+	// health tracks the progress reported by the health endpoints.
+	health *healthState
+
+	// pingL1 reports whether the L1 backend is reachable.
+	pingL1 func(ctx context.Context) error
+
+	// graceUntil is the end of the startup grace period.
+	//
+	// NOTE: This field MUST only be accessed from the event loop.
+	graceUntil time.Time
+
+	// balanceLevel is the level of the last observed wallet balance.
+	//
+	// NOTE: This field MUST only be accessed from the event loop.
+	balanceLevel balanceLevel
+
+	// drain tracks the wallet's drain rate, and is only set if
+	// cfg.BalanceDrainWindow is non-zero.
+	//
+	// NOTE: This field MUST only be accessed from the event loop.
+	drain *drainTracker
+
+	// drainAlerting is true while the wallet's drain rate is abnormal.
+	//
+	// NOTE: This field MUST only be accessed from the event loop.
+	drainAlerting bool
+
+	// breaker trips after sustained RPC failures, as determined by
+	// cfg.RPCRetryPolicy.
+	breaker *circuitBreaker
+
+	// spend accumulates the costs of the batches confirmed since startup.
+	spend *spendTracker
+
+	// endBlockPin holds the L2 block at the end of the highest confirmed
+	// batch, verified before building on top of it.
+	endBlockPin blockPinState
+
+	// leaderTerm is the term of the elector in which the submission state
+	// was last recovered, or zero while standing by.
+	//
+	// NOTE: This field MUST only be accessed from the event loop.
+	leaderTerm uint64
 
 	wg sync.WaitGroup
 }
@@ -69,20 +424,116 @@ type Service struct {
 func NewService(cfg ServiceConfig) *Service {
 	ctx, cancel := context.WithCancel(cfg.Context)
 
+	if cfg.AddressBook == nil {
+		cfg.AddressBook = NewAddressBook(nil)
+	}
+
+	// Count the fee bumps of batch txs, and their reorgs before they reach
+	// the confirmation depth, along with the batches resubmitted as a
+	// result.
+	txMgrCfg := cfg.TxManagerConfig
+	if txMgrCfg.OnFeeBump == nil {
+		txMgrCfg.OnFeeBump = cfg.Driver.Metrics().FeeBumps.Inc
+	}
+	if txMgrCfg.OnReorg == nil {
+		m := cfg.Driver.Metrics()
+		txMgrCfg.OnReorg = func(reincluded bool) {
+			m.ReorgsDetected.Inc()
+			if !reincluded {
+				m.BatchesResubmitted.Inc()
+			}
+		}
+	}
+
 	txMgr := txmgr.NewSimpleTxManager(
-		cfg.Driver.Name(), cfg.TxManagerConfig, cfg.L1Client,
+		cfg.Driver.Name(), txMgrCfg, cfg.L1Client,
 	)
 
+	// Batch txs of the priority lane are published at an elevated fee
+	// tier.
+	priorityTxMgr := txMgr
+	if cfg.PriorityMinGasPrice != nil {
+		priorityCfg := txMgrCfg
+		priorityCfg.MinGasPrice = cfg.PriorityMinGasPrice
+		priorityTxMgr = txmgr.NewSimpleTxManager(
+			cfg.Driver.Name(), priorityCfg, cfg.L1Client,
+		)
+	}
+
+	nonceMgr := noncemgr.NewManager(
+		cfg.Driver.Name(), cfg.Driver.WalletAddr(), cfg.L1Client,
+		cfg.NonceGapFiller,
+	)
+
+	builder, isBuilder := cfg.Driver.(BatchBuilder)
+	if cfg.SubmissionQueue != nil && !isBuilder {
+		log.Warn(cfg.Driver.Name() + " does not support " +
+			"submission queue, ignoring")
+		cfg.SubmissionQueue = nil
+	}
+
+	// Pipelining requires batches to be built ahead of publishing them,
+	// such that the block range covered by each batch in flight is known.
+	var batchPipeline *pipeline
+	if cfg.MaxInFlightBatches > 1 {
+		if isBuilder {
+			batchPipeline = newPipeline(ctx, cfg.MaxInFlightBatches)
+		} else {
+			log.Warn(cfg.Driver.Name() + " does not support " +
+				"pipelining, ignoring")
+		}
+	}
+
+	var batchBuilder BatchBuilder
+	if cfg.SubmissionQueue != nil || batchPipeline != nil {
+		batchBuilder = builder
+	}
+
+	var drain *drainTracker
+	if cfg.BalanceDrainWindow > 0 {
+		drain = newDrainTracker(cfg.BalanceDrainWindow)
+	}
+
+	var catchUp *catchUpTracker
+	if cfg.CatchUpChunkSize > 0 && cfg.CatchUpBudget.enabled() {
+		catchUp = newCatchUpTracker(cfg.CatchUpBudget)
+	}
+
 	return &Service{
-		cfg:     cfg,
-		ctx:     ctx,
-		cancel:  cancel,
-		txMgr:   txMgr,
-		metrics: cfg.Driver.Metrics(),
+		cfg:          cfg,
+		ctx:          ctx,
+		cancel:       cancel,
+		txMgr:        txMgr,
+		priorityMgr:  priorityTxMgr,
+		nonceMgr:     nonceMgr,
+		metrics:      cfg.Driver.Metrics(),
+		batchBuilder: batchBuilder,
+		pipeline:     batchPipeline,
+		traces:       newTraceHistory(defaultTraceHistorySize),
+		retries:      newRetryBudget(cfg.MaxSubmissionAttempts),
+		pollInterval: int64(cfg.PollInterval),
+		trigger:      make(chan struct{}, 1),
+		draining:     make(chan struct{}),
+		health:       newHealthState(),
+		breaker:      newCircuitBreaker(cfg.RPCRetryPolicy.BreakerThreshold),
+		drain:        drain,
+		catchUp:      catchUp,
+		spend:        newSpendTracker(),
+		pingL1: func(ctx context.Context) error {
+			_, err := cfg.L1Client.BlockNumber(ctx)
+			return err
+		},
 	}
 }
 
 func (s *Service) Start() error {
+	if err := s.runSelfTest(); err != nil {
+		return err
+	}
+
+	defaultStatusRegistry.register(s)
+	s.initCycleMetrics()
+
 	s.wg.Add(1)
 	go s.eventLoop()
 	return nil
@@ -91,113 +542,1370 @@ func (s *Service) Start() error {
 func (s *Service) Stop() error {
 	s.cancel()
 	s.wg.Wait()
+
+	defaultStatusRegistry.unregister(s)
+	return nil
+}
+
+// Drain gracefully winds down the service ahead of Stop: no new cycles are
+// started, and no new batch txs are published, while the batch txs in flight
+// are awaited until they reach the confirmation depth or the tx manager gives
+// up on them. The outcome of each is recorded in the StateStore as usual.
+//
+// Drain returns nil once nothing remains in flight, or the error of ctx if it
+// expires first, e.g. as an operator forces shutdown. Either way, Stop must be
+// called afterwards, which abandons anything still in flight. Submissions
+// interrupted this way remain pending in the StateStore, and are resumed after
+// a restart.
+func (s *Service) Drain(ctx context.Context) error {
+	name := s.cfg.Driver.Name()
+
+	s.drainOnce.Do(func() {
+		log.Info(name + " draining service")
+		close(s.draining)
+	})
+
+	drained := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		log.Info(name + " service drained")
+		return nil
+
+	case <-ctx.Done():
+		log.Warn(name+" service drain interrupted, abandoning batch "+
+			"txs in flight", "err", ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// Draining returns true once the service has begun draining.
+func (s *Service) Draining() bool {
+	select {
+	case <-s.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// ConfirmedHeight returns the exclusive end of the highest batch to reach the
+// required confirmation depth, and false if no batch has since startup.
+func (s *Service) ConfirmedHeight() (uint64, bool) {
+	height := atomic.LoadUint64(&s.confirmedHeight)
+	return height, height != 0
+}
+
+// recordConfirmedHeight advances the confirmed height to end, if higher.
+func (s *Service) recordConfirmedHeight(end uint64) {
+	for {
+		height := atomic.LoadUint64(&s.confirmedHeight)
+		if end <= height ||
+			atomic.CompareAndSwapUint64(&s.confirmedHeight, height, end) {
+			return
+		}
+	}
+}
+
+// Status returns the current status of the service.
+func (s *Service) Status() ServiceStatus {
+	return ServiceStatus{
+		Name:         s.cfg.Driver.Name(),
+		Wallet:       s.cfg.AddressBook.Labeled(s.cfg.Driver.WalletAddr()),
+		Paused:       s.Paused(),
+		Standby:      !s.isLeader(),
+		Draining:     s.Draining(),
+		SelfTestErr:  s.selfTestErr,
+		Quarantined:  s.retries.Quarantined(),
+		CatchUp:      s.CatchUpPlan(),
+		RecentCycles: s.traces.Recent(),
+
+		RecentSubmissions: s.recentSubmissions(),
+	}
+}
+
+// ReleaseQuarantine lifts the quarantine of the range held back after
+// exhausting its retry budget, returning the released range. With
+// QuarantineSkip, any queued batches are discarded such that the range is
+// rebuilt.
+func (s *Service) ReleaseQuarantine(
+	action QuarantineAction) (*QuarantinedRange, error) {
+
+	name := s.cfg.Driver.Name()
+
+	switch action {
+	case QuarantineForce, QuarantineSkip:
+	default:
+		return nil, ErrUnknownQuarantineAction
+	}
+
+	if s.retries.Quarantined() == nil {
+		return nil, ErrNoQuarantinedRange
+	}
+
+	if action == QuarantineSkip && s.cfg.SubmissionQueue != nil {
+		if err := s.cfg.SubmissionQueue.Clear(); err != nil {
+			return nil, err
+		}
+	}
+
+	released, err := s.retries.Release()
+	if err != nil {
+		return nil, err
+	}
+	s.metrics.QuarantinedRangeStart.Set(0)
+	log.Info(name+" released quarantined range", "action", action,
+		"start", released.Start, "end", released.End)
+
+	return released, nil
+}
+
+// Pause stops the service from submitting new batches until Resume is called.
+// Batch txs already published continue to be tracked until confirmed.
+func (s *Service) Pause() {
+	if atomic.CompareAndSwapUint32(&s.paused, 0, 1) {
+		log.Info(s.cfg.Driver.Name() + " submission paused")
+	}
+}
+
+// Resume lifts a pause, such that submission resumes on the next cycle.
+func (s *Service) Resume() {
+	if atomic.CompareAndSwapUint32(&s.paused, 1, 0) {
+		log.Info(s.cfg.Driver.Name() + " submission resumed")
+	}
+}
+
+// Paused returns true while submission is paused.
+func (s *Service) Paused() bool {
+	return atomic.LoadUint32(&s.paused) == 1
+}
+
+// TriggerCycle wakes the event loop to run a cycle immediately, rather than
+// after the poll interval elapses. Triggers received while a cycle is pending
+// are coalesced.
+func (s *Service) TriggerCycle() {
+	select {
+	case s.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// PollInterval returns the delay between cycles.
+func (s *Service) PollInterval() time.Duration {
+	return time.Duration(atomic.LoadInt64(&s.pollInterval))
+}
+
+// SetPollInterval adjusts the delay between cycles, taking effect after the
+// current delay elapses.
+func (s *Service) SetPollInterval(interval time.Duration) error {
+	if interval <= 0 {
+		return ErrInvalidAdminParam
+	}
+
+	atomic.StoreInt64(&s.pollInterval, int64(interval))
+	log.Info(s.cfg.Driver.Name()+" poll interval adjusted",
+		"poll_interval", interval)
+
+	return nil
+}
+
+// SetMaxTxSize adjusts the max size of subsequently built batch txs. Batches
+// already built, e.g. those held in the SubmissionQueue, are not rebuilt.
+func (s *Service) SetMaxTxSize(size uint64) error {
+	if err := validateMaxTxSize(s, size); err != nil {
+		return err
+	}
+
+	s.cfg.Driver.(TxSizeLimiter).SetMaxTxSize(size)
+	log.Info(s.cfg.Driver.Name()+" max tx size adjusted",
+		"max_tx_size", size)
+
+	return nil
+}
+
+// validateMaxTxSize ensures the max tx size of the service's Driver can be
+// adjusted to size, which must be non-zero and accepted by the L1 tx pool.
+func validateMaxTxSize(s *Service, size uint64) error {
+	if _, ok := s.cfg.Driver.(TxSizeLimiter); !ok {
+		return ErrMaxTxSizeUnsupported
+	}
+	if size == 0 {
+		return ErrInvalidAdminParam
+	}
+	if size > MaxL1TxPoolTxSize {
+		return fmt.Errorf("%w: max_tx_size exceeds %d bytes",
+			ErrInvalidAdminParam, MaxL1TxPoolTxSize)
+	}
+
 	return nil
 }
 
+// RuntimeSettings returns the current runtime settings of the service.
+func (s *Service) RuntimeSettings() RuntimeSettings {
+	settings := RuntimeSettings{
+		Paused:       s.Paused(),
+		PollInterval: s.PollInterval().String(),
+	}
+	if limiter, ok := s.cfg.Driver.(TxSizeLimiter); ok {
+		settings.MaxTxSize = limiter.MaxTxSize()
+	}
+
+	return settings
+}
+
+// PendingTxState returns the state of the service's txs that have yet to
+// confirm.
+func (s *Service) PendingTxState(ctx context.Context) (*PendingTxState, error) {
+	walletAddr := s.cfg.Driver.WalletAddr()
+
+	latest, err := s.cfg.L1Client.NonceAt(ctx, walletAddr, nil)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.cfg.L1Client.PendingNonceAt(ctx, walletAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &PendingTxState{
+		LatestNonce:  latest,
+		PendingNonce: pending,
+		InFlight:     []PendingBatch{},
+	}
+	if s.pipeline != nil {
+		for _, batch := range s.pipeline.Batches() {
+			state.InFlight = append(state.InFlight, PendingBatch{
+				Start: batch.start,
+				End:   batch.end,
+				Nonce: batch.nonce,
+			})
+		}
+	}
+	if s.cfg.StateStore != nil {
+		state.Submissions, err = s.cfg.StateStore.Pending()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return state, nil
+}
+
+// recordSubmissionFailure charges a failed submission of [start, end) to its
+// retry budget, quarantining the range if the budget is exhausted. Failures
+// caused by shutdown or a draining pipeline are not charged.
+func (s *Service) recordSubmissionFailure(start, end *big.Int, err error) {
+	if errors.Is(err, context.Canceled) || s.ctx.Err() != nil {
+		return
+	}
+
+	quarantined := s.retries.Fail(start.Uint64(), end.Uint64(), err)
+	if quarantined == nil {
+		return
+	}
+
+	s.metrics.RangesQuarantined.Inc()
+	s.metrics.QuarantinedRangeStart.Set(float64(quarantined.Start))
+	log.Error(s.cfg.Driver.Name()+" range quarantined after repeated "+
+		"submission failures, awaiting operator action",
+		"start", quarantined.Start, "end", quarantined.End,
+		"attempts", quarantined.Attempts,
+		"last_err", quarantined.LastError,
+		"wallet", s.cfg.AddressBook.Format(s.cfg.Driver.WalletAddr()))
+}
+
 func (s *Service) eventLoop() {
 	defer s.wg.Done()
 
 	name := s.cfg.Driver.Name()
 
+	// In high availability mode, the submission state is only recovered
+	// once leadership is gained, as the leader may still be publishing.
+	if s.cfg.Elector == nil {
+		s.recoverSubmissionState(false)
+	}
+
+	// Once the lock over the submission queue or state is broken by
+	// another process, that process may be submitting the same batches, so
+	// the service stops rather than race it.
+	var queueLockLost, stateLockLost <-chan struct{}
+	if s.cfg.SubmissionQueue != nil {
+		queueLockLost = s.cfg.SubmissionQueue.LockLost()
+	}
+	if s.cfg.StateStore != nil {
+		stateLockLost = s.cfg.StateStore.LockLost()
+	}
+
 	for {
 		select {
-		case <-time.After(s.cfg.PollInterval):
-			// Record the submitter's current ETH balance. This is done first in
-			// case any of the remaining steps fail, we can at least have an
-			// accurate view of the submitter's balance.
-			balance, err := s.cfg.L1Client.BalanceAt(
-				s.ctx, s.cfg.Driver.WalletAddr(), nil,
-			)
-			if err != nil {
-				log.Error(name+" unable to get current balance", "err", err)
-				continue
-			}
-			s.metrics.ETHBalance.Set(weiToEth64(balance))
-
-			// Determine the range of L2 blocks that the batch submitter has not
-			// processed, and needs to take action on.
-			log.Info(name + " fetching current block range")
-			start, end, err := s.cfg.Driver.GetBatchBlockRange(s.ctx)
-			if err != nil {
-				log.Error(name+" unable to get block range", "err", err)
-				continue
+		case <-time.After(s.nextCycleDelay()):
+		case <-s.trigger:
+			log.Info(name + " running triggered cycle")
+
+		case err := <-s.ctx.Done():
+			log.Error(name+" service shutting down", "err", err)
+			return
+
+		case <-queueLockLost:
+			log.Error(name+" submission queue lock lost, stopping",
+				"err", queue.ErrQueueLockLost)
+			s.cancel()
+			return
+
+		case <-stateLockLost:
+			log.Error(name+" submission state lock lost, stopping",
+				"err", queue.ErrQueueLockLost)
+			s.cancel()
+			return
+
+		case <-s.draining:
+			log.Info(name + " service draining, no longer " +
+				"starting cycles")
+			return
+		}
+
+		trace := newCycleTrace()
+		s.traces.Begin(trace)
+		s.catchingUp = false
+		s.runCycle(trace)
+		s.traces.Add(trace)
+		s.recordCycleOutcome(trace)
+		s.health.CycleCompleted()
+		if s.cfg.OnCycle != nil {
+			s.cfg.OnCycle(trace.Snapshot())
+		}
+
+		// A cycle that did not submit its chunk of the backlog, e.g. as
+		// it failed, waits PollInterval as usual.
+		if trace.Snapshot().Outcome != CycleSubmitted {
+			s.catchingUp = false
+		}
+	}
+}
+
+// nextCycleDelay returns the delay before the next cycle, which is run
+// immediately while catching up on a backlog.
+//
+// NOTE: This method MUST only be called from the event loop.
+func (s *Service) nextCycleDelay() time.Duration {
+	if s.catchingUp {
+		return 0
+	}
+
+	return s.PollInterval()
+}
+
+// initCycleMetrics reports every cycle outcome and skip reason as zero, such
+// that dashboards can tell a count that has yet to increase from a missing
+// series.
+func (s *Service) initCycleMetrics() {
+	for _, outcome := range []CycleOutcome{
+		CycleSubmitted, CycleSkipped, CycleFailed,
+	} {
+		s.metrics.CycleOutcomes.WithLabelValues(string(outcome))
+	}
+	for _, reason := range skipReasons {
+		s.metrics.CyclesSkipped.WithLabelValues(string(reason))
+	}
+}
+
+// recordCycleOutcome counts the outcome of a concluded cycle, classifying
+// skipped cycles by reason such that an idle service can be told apart from a
+// failing one.
+func (s *Service) recordCycleOutcome(trace *CycleTrace) {
+	snapshot := trace.Snapshot()
+	if snapshot.Outcome == "" {
+		return
+	}
+
+	s.metrics.CycleOutcomes.WithLabelValues(
+		string(snapshot.Outcome),
+	).Inc()
+	if snapshot.Outcome == CycleSkipped {
+		s.metrics.CyclesSkipped.WithLabelValues(
+			string(snapshot.SkipReason),
+		).Inc()
+	}
+}
+
+// recoverSubmissionState resumes or clears the batch txs left pending by a
+// previous run, and then begins the startup grace period. If failover is true,
+// the txs were left pending by the previous leader, and are cleared regardless
+// of ClearPendingTxs as this replica never recorded them.
+//
+// NOTE: This method MUST only be called from the event loop.
+func (s *Service) recoverSubmissionState(failover bool) {
+	name := s.cfg.Driver.Name()
+
+	// Verify the chain left off on by a previous run before building on
+	// top of it.
+	s.loadEndBlockPin()
+
+	// Resume tracking the batch txs recorded as pending by a previous run,
+	// rather than building their ranges again. No txs are published in
+	// dry-run mode, so there is nothing to resume.
+	if s.cfg.StateStore != nil && !s.cfg.DryRun {
+		if err := s.resumeSubmissions(); err != nil {
+			log.Error(name+" unable to resume submissions", "err", err)
+		}
+	}
+
+	// Clear any txs left pending by a previous run, which would otherwise
+	// block submission at their nonces. No txs are published in dry-run
+	// mode, so there is nothing to clear.
+	if (s.cfg.ClearPendingTxs || failover) && !s.cfg.DryRun {
+		if err := s.clearPendingTxs(); err != nil {
+			log.Error(name+" unable to clear pending txs", "err", err)
+		}
+	}
+
+	// The grace period begins once any previous submissions have been
+	// resumed or cleared.
+	if s.cfg.StartupGracePeriod > 0 {
+		s.graceUntil = time.Now().Add(s.cfg.StartupGracePeriod)
+		log.Info(name+" observing chain state before first submission",
+			"grace_period", s.cfg.StartupGracePeriod)
+	}
+}
+
+// runCycle performs a single submission cycle, recording each decision taken
+// and the final outcome in trace. The queries and batch building leading up to
+// publication are bounded by MaxCycleDuration, if set, while publication and
+// confirmation are bounded by the tx manager.
+func (s *Service) runCycle(trace *CycleTrace) {
+	name := s.cfg.Driver.Name()
+
+	if s.Paused() {
+		log.Info(name + " submission paused, skipping cycle")
+		trace.Skipped(SkipPaused, "submission paused by operator")
+		return
+	}
+
+	if !s.checkLeadership(trace) {
+		return
+	}
+
+	ctx, cancel := s.cycleContext()
+	defer cancel()
+	defer s.checkCycleDeadline(ctx, trace)
+
+	// Record the submitter's current ETH balance. This is done first in
+	// case any of the remaining steps fail, we can at least have an
+	// accurate view of the submitter's balance.
+	var balance *big.Int
+	err := s.queryWithRetry(ctx, "balance", func(
+		ctx context.Context) error {
+
+		var err error
+		balance, err = s.cfg.L1Client.BalanceAt(
+			ctx, s.cfg.Driver.WalletAddr(), nil,
+		)
+		return err
+	})
+	if err != nil {
+		log.Error(name+" unable to get current balance", "wallet",
+			s.cfg.AddressBook.Format(s.cfg.Driver.WalletAddr()),
+			"err", err)
+		trace.Failed("unable to get current balance", err)
+		return
+	}
+	balance = s.checkWalletRotation(ctx, trace, balance)
+	s.metrics.ETHBalance.Set(weiToEth64(balance))
+	s.health.SetBalance(balance)
+	trace.Step("balance", "%v wei", balance)
+
+	s.checkBalanceDrain(time.Now(), balance)
+	s.updateSpendMetrics(time.Now())
+	if s.checkBalance(balance) == balanceCritical {
+		trace.Skipped(SkipLowBalance,
+			"balance below critical minimum")
+		return
+	}
+	if s.spendBudgetExhausted(time.Now()) {
+		trace.Skipped(SkipSpendBudget, "spend budget exhausted")
+		return
+	}
+
+	// Determine the range of L2 blocks that the batch submitter has not
+	// processed, and needs to take action on.
+	log.Info(name + " fetching current block range")
+	var (
+		start, end *big.Int
+		deferred   bool
+	)
+	err = s.queryWithRetry(ctx, "block_range", func(
+		ctx context.Context) error {
+
+		var err error
+		if deferrer, ok := s.cfg.Driver.(RangeDeferrer); ok {
+			start, end, deferred, err =
+				deferrer.GetDeferrableBlockRange(ctx)
+			return err
+		}
+		start, end, err = s.cfg.Driver.GetBatchBlockRange(ctx)
+		return err
+	})
+	if err != nil {
+		log.Error(name+" unable to get block range", "err", err)
+		trace.Failed("unable to get block range from contract state",
+			err)
+		return
+	}
+	trace.Step("block_range", "start=%v end=%v", start, end)
+	s.health.SetBlockRange(start, end)
+
+	// Ensure the L2 chain still contains the last confirmed batch before
+	// building on top of it.
+	if !s.checkEndBlockPin(ctx, trace) {
+		return
+	}
+
+	// Submit a deferred range regardless of its size once L1 queue
+	// elements approach their inclusion deadline.
+	priority := s.checkPriorityLane(ctx, trace)
+	if priority && deferred {
+		start, end, err = s.pendingBlockRange(ctx)
+		if err != nil {
+			log.Error(name+" unable to get block range", "err", err)
+			trace.Failed("unable to get block range from "+
+				"contract state", err)
+			return
+		}
+		deferred = false
+		trace.Step("block_range", "start=%v end=%v", start, end)
+		s.health.SetBlockRange(start, end)
+	}
+
+	// In pipelined mode, the next batch begins where the last batch in
+	// flight ends, rather than at the contract's confirmed height. While
+	// draining after a failed batch, only the failed range is resubmitted,
+	// such that the batches in flight behind it can still confirm.
+	next := start
+	var retry *inFlightBatch
+	if s.pipeline != nil {
+		numInFlight, nextStart := s.pipeline.Status(start.Uint64())
+		trace.Step("pipeline", "in_flight=%d next_start=%d",
+			numInFlight, nextStart)
+		next = new(big.Int).SetUint64(nextStart)
+
+		switch {
+		case s.pipeline.Draining():
+			retry = s.pipeline.Retry(start.Uint64())
+			if retry == nil {
+				log.Info(name + " waiting for pipeline to drain")
+				trace.Skipped(SkipPipelineFull,
+					"draining pipeline after failed batch tx")
+				return
 			}
+			log.Info(name+" retrying failed pipelined batch",
+				"start", retry.start, "end", retry.end,
+				"nonce", retry.nonce)
+			trace.Step("pipeline_retry", "start=%d end=%d nonce=%d",
+				retry.start, retry.end, retry.nonce)
+			next = new(big.Int).SetUint64(retry.start)
 
-			// No new updates.
-			if start.Cmp(end) == 0 {
-				log.Info(name+" no updates", "start", start, "end", end)
-				continue
+		case s.pipeline.Full():
+			log.Info(name+" max in-flight batches reached",
+				"in_flight", numInFlight)
+			trace.Skipped(SkipPipelineFull,
+				"max in-flight batches reached")
+			return
+		}
+	}
+
+	// Hold back a quarantined range until an operator releases it. If the
+	// contract has since moved past it, e.g. after a manual submission,
+	// the quarantine no longer applies.
+	if quarantined := s.retries.Quarantined(); quarantined != nil {
+		switch {
+		case next.Uint64() == quarantined.Start:
+			trace.Step("quarantine", "start=%d end=%d attempts=%d",
+				quarantined.Start, quarantined.End,
+				quarantined.Attempts)
+			trace.Skipped(SkipQuarantined,
+				"range quarantined, awaiting operator action")
+			return
+
+		case next.Uint64() > quarantined.Start:
+			log.Info(name+" quarantined range submitted, "+
+				"lifting quarantine", "start", quarantined.Start)
+			if _, err := s.retries.Release(); err == nil {
+				s.metrics.QuarantinedRangeStart.Set(0)
 			}
-			log.Info(name+" block range", "start", start, "end", end)
+		}
+	}
+
+	var backlog uint64
+	if next.Cmp(end) < 0 {
+		backlog = new(big.Int).Sub(end, next).Uint64()
+	}
+	s.metrics.BacklogBlocks.Set(float64(backlog))
+
+	// A catch-up concludes once the backlog fits within a single chunk.
+	if s.catchUp != nil && backlog <= s.cfg.CatchUpChunkSize {
+		s.catchUp.Finish()
+	}
+
+	// A failed batch is retried over its original range, such that the
+	// batches in flight behind it remain aligned.
+	if retry != nil {
+		end = new(big.Int).SetUint64(retry.end)
+	}
+
+	// No new updates.
+	if next.Cmp(end) >= 0 {
+		log.Info(name+" no updates", "start", next, "end", end)
+		if deferred {
+			trace.Skipped(SkipBelowMinSize,
+				"pending range below minimum batch size")
+			return
+		}
+		trace.Skipped(SkipNoUpdates, "no new L2 blocks to submit")
+		return
+	}
+
+	// Catch up on a large backlog in bounded chunks submitted back to
+	// back, rather than building the entire range in a single pass. Once
+	// the catch-up budget stops the plan, or while it awaits approval, the
+	// chunks are submitted a poll interval apart instead.
+	if chunk := s.cfg.CatchUpChunkSize; retry == nil && chunk > 0 &&
+		backlog > chunk {
+
+		s.catchingUp = s.continueCatchUp(
+			ctx, trace, next.Uint64(), end.Uint64(),
+		)
+		end = new(big.Int).SetUint64(next.Uint64() + chunk)
+		log.Info(name+" catching up on backlog", "backlog", backlog,
+			"chunk_size", chunk)
+		trace.Step("catch_up", "backlog=%d chunk_end=%v", backlog, end)
+	}
+	log.Info(name+" block range", "start", next, "end", end)
 
-			// Query for the submitter's current nonce.
-			nonce64, err := s.cfg.L1Client.NonceAt(
-				s.ctx, s.cfg.Driver.WalletAddr(), nil,
+	// Only observe during the startup grace period, such that a service
+	// restarted by a crash-loop does not immediately resubmit a range
+	// whose batch tx from the previous run has yet to be mined.
+	if remaining := time.Until(s.graceUntil); remaining > 0 {
+		s.observePendingTxs(ctx, trace)
+		log.Info(name+" in startup grace period, deferring submission",
+			"remaining", remaining.Truncate(time.Second))
+		trace.Skipped(SkipGracePeriod, "startup grace period")
+		return
+	}
+
+	// Defer submission while the market gas price exceeds our ceiling,
+	// rather than publishing a tx that is unlikely to confirm at the max
+	// gas price.
+	if s.cfg.DeferAboveMaxGasPrice && !priority {
+		shouldDefer, err := s.shouldDeferSubmission(ctx, trace)
+		if err != nil {
+			log.Error(name+" unable to get gas price", "err", err)
+			trace.Failed("unable to get gas price", err)
+			return
+		}
+		if shouldDefer {
+			trace.Skipped(SkipGasPriceCapped,
+				"gas price above max gas price")
+			return
+		}
+	}
+
+	// When using the submission queue, publish the queued batch beginning
+	// at the next height, building a new one only if none is queued. In
+	// pipelined mode, the batch must be built ahead of publishing it, such
+	// that the range it covers is known.
+	var batch *queue.Batch
+	switch {
+	case s.cfg.SubmissionQueue != nil:
+		batch, err = s.nextQueuedBatch(ctx, start, next, end)
+		if errors.Is(err, txmgr.ErrSendDeferred) {
+			log.Info(name+" deferring batch", "start", next,
+				"end", end, "err", err)
+			trace.Skipped(SkipSendDeferred, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error(name+" unable to get queued batch", "err", err)
+			s.recordSubmissionFailure(next, end, err)
+			trace.Failed("unable to get queued batch", err)
+			return
+		}
+		trace.Step("queued_batch", "start=%d end=%d size=%d",
+			batch.Start, batch.End, len(batch.CallData))
+
+	case s.pipeline != nil:
+		batch, err = s.batchBuilder.BuildBatch(ctx, next, end)
+		if errors.Is(err, txmgr.ErrSendDeferred) {
+			log.Info(name+" deferring batch", "start", next,
+				"end", end, "err", err)
+			trace.Skipped(SkipSendDeferred, err.Error())
+			return
+		}
+		if err != nil {
+			log.Error(name+" unable to build batch", "err", err)
+			s.recordSubmissionFailure(next, end, err)
+			trace.Failed("unable to build batch", err)
+			return
+		}
+		trace.Step("built_batch", "start=%d end=%d size=%d",
+			batch.Start, batch.End, len(batch.CallData))
+	}
+	if batch != nil {
+		start = new(big.Int).SetUint64(batch.Start)
+		end = new(big.Int).SetUint64(batch.End)
+	}
+
+	// A cycle begun before draining publishes nothing new, such that
+	// only the batch txs already in flight are awaited.
+	if s.Draining() {
+		trace.Skipped(SkipDraining, "service draining ahead of shutdown")
+		return
+	}
+
+	// Reserve the submitter's next nonce. The nonce is released below if
+	// no tx using it is ever published.
+	var nonce uint64
+	err = s.queryWithRetry(ctx, "nonce", func(ctx context.Context) error {
+		var err error
+		nonce, err = s.nonceMgr.Next(ctx)
+		return err
+	})
+	if err != nil {
+		log.Error(name+" unable to get current nonce", "err", err)
+		trace.Failed("unable to get current nonce", err)
+		return
+	}
+	trace.Step("nonce", "%d", nonce)
+
+	// The cycle deadline no longer applies once the batch tx is handed to
+	// the tx manager, as aborting publication would strand the nonce.
+	cancel()
+
+	sub := newBatchSubmission(start, end, nonce, batch)
+	sub.priority = priority
+
+	// In dry-run mode the tx is never published, so there is no receipt
+	// to wait for.
+	if s.cfg.DryRun {
+		tx, err := s.sendBatchTx(
+			s.ctx, sub, s.cfg.TxManagerConfig.MinGasPrice,
+		)
+		s.nonceMgr.Release(nonce)
+		if err != nil {
+			log.Error(name+" dry run batch tx failed", "err", err)
+			trace.Failed("dry run batch tx failed", err)
+			return
+		}
+		log.Info(name+" dry run batch tx built", "tx_hash",
+			tx.Hash(), "gas", tx.Gas(), "size", tx.Size())
+		trace.Step("dry_run_tx", "hash=%s gas=%d size=%v",
+			tx.Hash(), tx.Gas(), tx.Size())
+		trace.Skipped(SkipDryRun,
+			"dry run mode, batch tx not published")
+		return
+	}
+
+	if s.pipeline != nil {
+		s.submitPipelined(sub, trace)
+		return
+	}
+
+	// Wait until one of our submitted transactions confirms. If no
+	// receipt is received it's likely our gas price was too low.
+	receipt, err := s.confirmBatchTx(s.ctx, sub)
+	if s.isUneconomic(sub, err) {
+		trace.Skipped(SkipFeeCapped, "batch fee above ceiling, "+
+			"deferring until a larger batch can be built")
+		return
+	}
+	if s.isDeferred(sub, err) {
+		trace.Skipped(SkipSendDeferred, err.Error())
+		return
+	}
+	if err != nil {
+		s.recordSubmissionFailure(start, end, err)
+		trace.Failed("unable to publish batch tx", err)
+		return
+	}
+	s.retries.Reset(start.Uint64())
+	trace.Step("receipt", "hash=%s block=%v gas_used=%d",
+		receipt.TxHash, receipt.BlockNumber, receipt.GasUsed)
+
+	trace.Submitted("batch tx confirmed")
+}
+
+// batchSubmission tracks the publication of a single batch tx at a reserved
+// nonce.
+type batchSubmission struct {
+	start *big.Int
+	end   *big.Int
+	nonce uint64
+
+	// batch is the prebuilt batch to publish, if any. Otherwise, the batch
+	// is built by the Driver on each publication attempt.
+	batch *queue.Batch
+
+	// createdAt is the time at which the submission began.
+	createdAt time.Time
+
+	// published is closed once the first tx is broadcast, at which point
+	// firstBroadcast is set.
+	published      chan struct{}
+	publishOnce    sync.Once
+	firstBroadcast time.Time
+
+	// resumed is the tx left pending by a previous run, if any, which is
+	// tracked in place of publishing a new tx on the first attempt.
+	resumedMu sync.Mutex
+	resumed   *types.Transaction
+
+	// priority is set if the batch was submitted by the priority lane, in
+	// which case its txs are published at the elevated fee tier.
+	priority bool
+
+	// endBlockHash is the hash of the L2 block at end-1 when the first tx
+	// was published, or the zero hash if unknown. It is set along with
+	// firstBroadcast.
+	endBlockHash common.Hash
+}
+
+// newBatchSubmission begins the submission of the L2 blocks in [start, end) at
+// the given nonce.
+func newBatchSubmission(
+	start, end *big.Int,
+	nonce uint64,
+	batch *queue.Batch,
+) *batchSubmission {
+
+	return &batchSubmission{
+		start:     start,
+		end:       end,
+		nonce:     nonce,
+		batch:     batch,
+		createdAt: time.Now(),
+		published: make(chan struct{}),
+	}
+}
+
+// takeResumedTx returns the tx left pending by a previous run, if it has not
+// already been taken.
+func (b *batchSubmission) takeResumedTx() *types.Transaction {
+	b.resumedMu.Lock()
+	defer b.resumedMu.Unlock()
+
+	tx := b.resumed
+	b.resumed = nil
+
+	return tx
+}
+
+// isPublished returns true if any tx of the submission has been broadcast.
+func (b *batchSubmission) isPublished() bool {
+	select {
+	case <-b.published:
+		return true
+	default:
+		return false
+	}
+}
+
+// sendBatchTx attempts to send the batch tx of sub at the given gas price. It is
+// invoked by the tx manager on each publication attempt.
+func (s *Service) sendBatchTx(
+	ctx context.Context,
+	sub *batchSubmission,
+	gasPrice *big.Int,
+) (*types.Transaction, error) {
+
+	name := s.cfg.Driver.Name()
+	nonce := new(big.Int).SetUint64(sub.nonce)
+
+	// Fence off a deposed leader, such that it stops bumping the fees of
+	// its batch txs once a standby may have taken over.
+	if !s.isLeader() {
+		return nil, ErrNotLeader
+	}
+
+	if tx := sub.takeResumedTx(); tx != nil {
+		log.Info(name+" resuming pending batch tx", "start", sub.start,
+			"end", sub.end, "nonce", nonce, "tx_hash", tx.Hash())
+		return tx, nil
+	}
+
+	log.Info(name+" attempting batch tx", "start", sub.start,
+		"end", sub.end, "nonce", nonce,
+		"gasPrice", gasPrice)
+
+	var (
+		tx  *types.Transaction
+		err error
+	)
+	if sub.batch != nil {
+		tx, err = s.batchBuilder.SubmitBuiltBatch(
+			ctx, sub.batch, nonce, gasPrice,
+		)
+	} else {
+		tx, err = s.cfg.Driver.SubmitBatchTx(
+			ctx, sub.start, sub.end, nonce, gasPrice,
+		)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// No tx is broadcast in dry-run mode.
+	if !s.cfg.DryRun {
+		sub.publishOnce.Do(func() {
+			sub.endBlockHash = s.endBlockHash(ctx, sub)
+			sub.firstBroadcast = time.Now()
+			s.metrics.BatchFirstBroadcastTime.Observe(
+				msSince(sub.createdAt, sub.firstBroadcast),
 			)
-			if err != nil {
-				log.Error(name+" unable to get current nonce",
+			close(sub.published)
+		})
+		s.recordPublishedTx(sub, tx)
+	}
+
+	log.Info(
+		name+" submitted batch tx",
+		"start", sub.start,
+		"end", sub.end,
+		"nonce", nonce,
+		"tx_hash", tx.Hash(),
+		"gasPrice", gasPrice,
+	)
+
+	s.metrics.BatchSizeInBytes.Observe(float64(tx.Size()))
+
+	return tx, nil
+}
+
+// confirmBatchTx publishes the batch tx of sub, blocking until it has reached
+// the configured confirmation depth. The tx manager resumes fee bumping if the
+// batch tx is reorged out beforehand.
+//
+// NOTE: Batches are only dequeued from the SubmissionQueue once final, such
+// that a reorged batch is resubmitted even across restarts.
+func (s *Service) confirmBatchTx(
+	ctx context.Context,
+	sub *batchSubmission,
+) (*types.Receipt, error) {
+
+	name := s.cfg.Driver.Name()
+
+	sendTx := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		return s.sendBatchTx(ctx, sub, gasPrice)
+	}
+
+	txMgr := s.txMgr
+	if sub.priority {
+		txMgr = s.priorityMgr
+	}
+	receipt, err := txMgr.Send(ctx, sendTx)
+	if s.isUneconomic(sub, err) {
+		log.Info(name+" batch fee above ceiling, deferring until "+
+			"a larger batch can be built", "start", sub.start,
+			"end", sub.end, "err", err)
+		s.metrics.UneconomicBatchesDeferred.Inc()
+		s.nonceMgr.Release(sub.nonce)
+
+		// Discard the queued batch, such that it is rebuilt to cover
+		// any blocks produced in the meantime.
+		if sub.batch != nil && s.cfg.SubmissionQueue != nil {
+			if err := s.cfg.SubmissionQueue.Clear(); err != nil {
+				log.Error(name+" unable to clear submission queue",
 					"err", err)
-				continue
-			}
-			nonce := new(big.Int).SetUint64(nonce64)
-
-			// Construct the transaction submission clousure that will attempt
-			// to send the next transaction at the given nonce and gas price.
-			sendTx := func(
-				ctx context.Context,
-				gasPrice *big.Int,
-			) (*types.Transaction, error) {
-				log.Info(name+" attempting batch tx", "start", start,
-					"end", end, "nonce", nonce,
-					"gasPrice", gasPrice)
-
-				tx, err := s.cfg.Driver.SubmitBatchTx(
-					ctx, start, end, nonce, gasPrice,
-				)
-				if err != nil {
-					return nil, err
-				}
-
-				log.Info(
-					name+" submitted batch tx",
-					"start", start,
-					"end", end,
-					"nonce", nonce,
-					"tx_hash", tx.Hash(),
-					"gasPrice", gasPrice,
-				)
-
-				s.metrics.BatchSizeInBytes.Observe(float64(tx.Size()))
-
-				return tx, nil
 			}
+		}
+		return nil, err
+	}
+	if s.isDeferred(sub, err) {
+		log.Info(name+" batch tx deferred", "start", sub.start,
+			"end", sub.end, "err", err)
+		s.nonceMgr.Release(sub.nonce)
 
-			// Wait until one of our submitted transactions confirms. If no
-			// receipt is received it's likely our gas price was too low.
-			batchConfirmationStart := time.Now()
-			receipt, err := s.txMgr.Send(s.ctx, sendTx)
-			if err != nil {
-				log.Error(name+" unable to publish batch tx",
+		// Discard the queued batch, such that it is rebuilt from its
+		// blocks rather than republished as is, e.g. once its contexts
+		// drifted too far from L1.
+		if sub.batch != nil && s.cfg.SubmissionQueue != nil {
+			if err := s.cfg.SubmissionQueue.Clear(); err != nil {
+				log.Error(name+" unable to clear submission queue",
 					"err", err)
-				s.metrics.FailedSubmissions.Inc()
-				continue
 			}
+		}
+		return nil, err
+	}
+	if err != nil {
+		log.Error(name+" unable to publish batch tx", "wallet",
+			s.cfg.AddressBook.Format(s.cfg.Driver.WalletAddr()),
+			"start", sub.start, "end", sub.end, "nonce", sub.nonce,
+			"err", err)
+		s.metrics.FailedSubmissions.Inc()
+		if !sub.isPublished() {
+			s.nonceMgr.Release(sub.nonce)
+		}
+
+		// Submissions interrupted by shutdown remain pending, such that
+		// they are resumed after a restart. Otherwise, the nonce of the
+		// abandoned tx is reassigned, such that the tx stuck at it is
+		// replaced unless mined in the meantime.
+		if sub.isPublished() && s.ctx.Err() == nil {
+			s.nonceMgr.Abandon(sub.nonce)
+			s.concludeSubmission(sub, queue.SubmissionAbandoned, nil)
+		}
+		return nil, err
+	}
+	confirmedAt := time.Now()
 
-			// The transaction was successfully submitted.
-			log.Info(name+" batch tx successfully published",
-				"tx_hash", receipt.TxHash)
-			batchConfirmationTime := time.Since(batchConfirmationStart) /
-				time.Millisecond
-			s.metrics.BatchConfirmationTime.Set(float64(batchConfirmationTime))
-			s.metrics.BatchesSubmitted.Inc()
-			s.metrics.SubmissionGasUsed.Set(float64(receipt.GasUsed))
-			s.metrics.SubmissionTimestamp.Set(float64(time.Now().UnixNano() / 1e6))
+	// The transaction was successfully submitted.
+	log.Info(name+" batch tx successfully published",
+		"tx_hash", receipt.TxHash)
 
-		case err := <-s.ctx.Done():
-			log.Error(name+" service shutting down", "err", err)
+	s.metrics.BatchesSubmitted.Inc()
+	s.metrics.SubmissionGasUsed.Set(float64(receipt.GasUsed))
+	s.metrics.SubmissionTimestamp.Set(
+		float64(confirmedAt.UnixNano() / 1e6),
+	)
+
+	// Split the time to confirmation at the timestamp of the including
+	// block, as Send only returns once the confirmation depth is reached.
+	header, err := s.cfg.L1Client.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		log.Warn(name+" unable to get batch tx block", "tx_hash",
+			receipt.TxHash, "err", err)
+	} else {
+		minedAt := time.Unix(int64(header.Time), 0)
+		s.metrics.BatchMempoolWaitTime.Observe(
+			msSince(sub.firstBroadcast, minedAt),
+		)
+		s.metrics.BatchConfirmationDepthWaitTime.Observe(
+			msSince(minedAt, confirmedAt),
+		)
+		s.recordBatchCost(ctx, sub, receipt, header, confirmedAt)
+	}
+	s.recordConfirmedHeight(sub.end.Uint64())
+	s.pinEndBlock(sub.end.Uint64(), sub.endBlockHash, receipt)
+	s.health.BatchConfirmed()
+	s.concludeSubmission(sub, queue.SubmissionConfirmed, receipt)
+	s.storeInclusionProof(ctx, receipt)
+	s.archiveBatch(ctx, sub, receipt)
+	s.verifyBatch(ctx, receipt)
+
+	if sub.batch != nil && s.cfg.SubmissionQueue != nil {
+		err := s.cfg.SubmissionQueue.Remove(sub.batch.Start)
+		if err != nil {
+			log.Error(name+" unable to dequeue batch",
+				"start", sub.batch.Start, "err", err)
+		}
+	}
+
+	return receipt, nil
+}
+
+// storeInclusionProof generates and stores the inclusion proof of the batch
+// confirmed by receipt, if the service is configured to. Failures are logged
+// rather than returned, as the batch is confirmed regardless.
+func (s *Service) storeInclusionProof(
+	ctx context.Context,
+	receipt *types.Receipt,
+) {
+
+	prover, ok := s.cfg.Driver.(InclusionProver)
+	if !ok || s.cfg.ProofStore == nil {
+		return
+	}
+	name := s.cfg.Driver.Name()
+
+	proof, err := prover.InclusionProof(ctx, receipt)
+	if err != nil {
+		log.Error(name+" unable to generate inclusion proof",
+			"tx_hash", receipt.TxHash, "err", err)
+		return
+	}
+	if err := s.cfg.ProofStore.Put(proof); err != nil {
+		log.Error(name+" unable to store inclusion proof",
+			"batch_index", proof.Header.BatchIndex, "err", err)
+		return
+	}
+
+	log.Info(name+" stored inclusion proof", "batch_index",
+		proof.Header.BatchIndex, "num_elements", len(proof.Elements))
+}
+
+// archiveBatch seals and archives the calldata of the batch tx confirmed by
+// receipt, if the service is configured to. The calldata is read back from L1,
+// such that the archived payload is exactly what was included. Failures are
+// logged rather than returned, as the batch is confirmed regardless.
+func (s *Service) archiveBatch(
+	ctx context.Context,
+	sub *batchSubmission,
+	receipt *types.Receipt,
+) {
+
+	if s.cfg.Archiver == nil {
+		return
+	}
+	name := s.cfg.Driver.Name()
+
+	tx, _, err := s.cfg.L1Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		log.Error(name+" unable to get batch tx to archive",
+			"tx_hash", receipt.TxHash, "err", err)
+		return
+	}
+
+	manifest, err := s.cfg.Archiver.Archive(&queue.Batch{
+		Start:    sub.start.Uint64(),
+		End:      sub.end.Uint64(),
+		CallData: tx.Data(),
+	})
+	if err != nil {
+		log.Error(name+" unable to archive batch", "start", sub.start,
+			"end", sub.end, "err", err)
+		return
+	}
+
+	log.Info(name+" archived batch", "start", manifest.Start,
+		"end", manifest.End, "key_id", manifest.KeyID,
+		"sealed_hash", manifest.SealedHash)
+}
+
+// verifyBatch re-derives the batch confirmed by receipt from L1, if the service
+// is configured to, raising a critical alert on any mismatch. Failures are
+// logged rather than returned, as the batch is confirmed regardless.
+func (s *Service) verifyBatch(ctx context.Context, receipt *types.Receipt) {
+	verifier, ok := s.cfg.Driver.(BatchVerifier)
+	if !ok || !s.cfg.VerifyBatches {
+		return
+	}
+	name := s.cfg.Driver.Name()
+
+	mismatches, err := verifier.VerifyBatch(ctx, receipt)
+	if err != nil {
+		log.Error(name+" unable to verify batch", "tx_hash",
+			receipt.TxHash, "err", err)
+		s.metrics.BatchVerificationErrors.Inc()
+		return
+	}
+	s.metrics.BatchesVerified.Inc()
+	if len(mismatches) == 0 {
+		return
+	}
+
+	log.Error(name+" confirmed batch does not match L2", "tx_hash",
+		receipt.TxHash, "block_number", receipt.BlockNumber,
+		"mismatches", mismatches)
+	s.metrics.BatchVerificationMismatches.Inc()
+
+	s.notify(alerts.Alert{
+		Key:      name + "-batch-mismatch-" + receipt.TxHash.Hex(),
+		Severity: alerts.SeverityCritical,
+		Service:  name,
+		Summary: fmt.Sprintf("confirmed batch tx %s does not match "+
+			"the L2 blocks it covers", receipt.TxHash.Hex()),
+		Details: map[string]string{
+			"tx_hash":      receipt.TxHash.Hex(),
+			"block_number": receipt.BlockNumber.String(),
+			"mismatches":   strings.Join(mismatches, "; "),
+		},
+		Time: time.Now(),
+	})
+}
+
+// observePendingTxs records the wallet's latest and pending nonces in trace,
+// warning if txs from a previous run are still pending.
+func (s *Service) observePendingTxs(ctx context.Context, trace *CycleTrace) {
+	name := s.cfg.Driver.Name()
+	walletAddr := s.cfg.Driver.WalletAddr()
+
+	latest, err := s.cfg.L1Client.NonceAt(ctx, walletAddr, nil)
+	if err != nil {
+		log.Error(name+" unable to get latest nonce", "err", err)
+		return
+	}
+	pending, err := s.cfg.L1Client.PendingNonceAt(ctx, walletAddr)
+	if err != nil {
+		log.Error(name+" unable to get pending nonce", "err", err)
+		return
+	}
+	trace.Step("pending_txs", "latest_nonce=%d pending_nonce=%d", latest,
+		pending)
+
+	if pending > latest {
+		log.Warn(name+" txs still pending from a previous run",
+			"wallet", s.cfg.AddressBook.Format(walletAddr),
+			"latest_nonce", latest, "pending_nonce", pending)
+	}
+}
+
+// isUneconomic returns true if the batch tx of sub was never published as its
+// fee exceeded the batch's ceiling, as reported by err.
+func (s *Service) isUneconomic(sub *batchSubmission, err error) bool {
+	return errors.Is(err, txmgr.ErrFeeCeilingReached) && !sub.isPublished()
+}
+
+// isDeferred returns true if the batch tx of sub was never published as it
+// would revert until the L1 state it depends on exists, as reported by err.
+func (s *Service) isDeferred(sub *batchSubmission, err error) bool {
+	return errors.Is(err, txmgr.ErrSendDeferred) && !sub.isPublished()
+}
+
+// submitPipelined publishes the batch tx of sub in the background, returning
+// once its first tx has been broadcast. This ensures the backend's pending
+// nonce accounts for the tx before the next batch reserves the following
+// nonce.
+func (s *Service) submitPipelined(sub *batchSubmission, trace *CycleTrace) {
+	name := s.cfg.Driver.Name()
+
+	inFlight := &inFlightBatch{
+		start: sub.start.Uint64(),
+		end:   sub.end.Uint64(),
+		nonce: sub.nonce,
+	}
+	ctx := s.pipeline.Add(inFlight)
+
+	done := make(chan error, 1)
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		// A failed batch is retried over the same range, while the
+		// batches in flight behind it continue to be awaited.
+		_, err := s.confirmBatchTx(ctx, sub)
+		switch {
+		case err == nil:
+			log.Info(name+" pipelined batch tx confirmed",
+				"start", inFlight.start, "end", inFlight.end,
+				"nonce", inFlight.nonce)
+			s.retries.Reset(inFlight.start)
+			s.pipeline.Remove(inFlight, false)
+
+		case s.isUneconomic(sub, err) || s.isDeferred(sub, err):
+			s.pipeline.Skip(inFlight)
+
+		default:
+			s.recordSubmissionFailure(sub.start, sub.end, err)
+			s.pipeline.Remove(inFlight, true)
+		}
+		done <- err
+	}()
+
+	select {
+	case <-sub.published:
+		trace.Submitted("batch tx published, awaiting confirmation " +
+			"in pipeline")
+
+	case err := <-done:
+		if s.isUneconomic(sub, err) {
+			trace.Skipped(SkipFeeCapped, "batch fee above "+
+				"ceiling, deferring until a larger batch can "+
+				"be built")
 			return
 		}
+		if s.isDeferred(sub, err) {
+			trace.Skipped(SkipSendDeferred, err.Error())
+			return
+		}
+		if err != nil {
+			trace.Failed("unable to publish batch tx", err)
+			return
+		}
+		trace.Submitted("batch tx confirmed")
+
+	case <-s.ctx.Done():
+		trace.Failed("service shutting down", s.ctx.Err())
+	}
+}
+
+// shouldDeferSubmission returns true if the L1 backend's suggested gas price is
+// above the tx manager's max gas price.
+func (s *Service) shouldDeferSubmission(
+	ctx context.Context, trace *CycleTrace) (bool, error) {
+
+	name := s.cfg.Driver.Name()
+
+	gasPrice, err := s.cfg.L1Client.SuggestGasPrice(ctx)
+	if err != nil {
+		return false, err
+	}
+	s.metrics.MarketGasPrice.Set(weiToGwei64(gasPrice))
+
+	maxGasPrice := s.cfg.TxManagerConfig.MaxGasPrice
+	trace.Step("gas_price", "suggested=%v max=%v", gasPrice, maxGasPrice)
+	if gasPrice.Cmp(maxGasPrice) <= 0 {
+		return false, nil
+	}
+
+	log.Warn(name+" deferring submission, gas price above max",
+		"gas_price", gasPrice, "max_gas_price", maxGasPrice)
+	s.metrics.DeferredSubmissions.Inc()
+
+	return true, nil
+}
+
+// nextQueuedBatch returns the queued batch beginning at next, after discarding
+// any batches that are already confirmed or that no longer begin at the
+// contract's expected start. If no such batch is queued, a new batch is built
+// for the range [next, end) and enqueued. Outside of pipelined mode, next is
+// always start, i.e. the batch at the head of the queue.
+func (s *Service) nextQueuedBatch(
+	ctx context.Context,
+	start, next, end *big.Int,
+) (*queue.Batch, error) {
+
+	name := s.cfg.Driver.Name()
+	q := s.cfg.SubmissionQueue
+
+	// Drop any batches that were confirmed before we could dequeue them,
+	// e.g. if the process was restarted after confirmation.
+	if err := q.PruneBefore(start.Uint64()); err != nil {
+		return nil, err
+	}
+
+	batches, err := q.Batches()
+	if err != nil {
+		return nil, err
+	}
+
+	// If the head of the queue doesn't begin where the contract expects,
+	// the queued batches would revert and must be rebuilt.
+	if len(batches) > 0 && batches[0].Start != start.Uint64() {
+		log.Warn(name+" discarding stale submission queue",
+			"queue_start", batches[0].Start, "expected_start", start)
+		if err := q.Clear(); err != nil {
+			return nil, err
+		}
+		batches = nil
 	}
+
+	for _, batch := range batches {
+		if batch.Start == next.Uint64() {
+			log.Info(name+" resuming queued batch",
+				"start", batch.Start, "end", batch.End)
+			return batch, nil
+		}
+	}
+
+	batch, err := s.batchBuilder.BuildBatch(ctx, next, end)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.Push(batch); err != nil {
+		return nil, err
+	}
+
+	return batch, nil
+}
+
+// msSince returns the number of milliseconds elapsed between start and end.
+func msSince(start, end time.Time) float64 {
+	return float64(end.Sub(start) / time.Millisecond)
 }
 
 func weiToEth64(wei *big.Int) float64 {
@@ -206,3 +1914,10 @@ func weiToEth64(wei *big.Int) float64 {
 	eth64, _ := eth.Float64()
 	return eth64
 }
+
+func weiToGwei64(wei *big.Int) float64 {
+	gwei := new(big.Float).SetInt(wei)
+	gwei.Mul(gwei, weiToGwei)
+	gwei64, _ := gwei.Float64()
+	return gwei64
+}
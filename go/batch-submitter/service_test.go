@@ -0,0 +1,302 @@
+package batchsubmitter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/archive"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/metrics"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+)
+
+// namedDriver is a Driver that only implements Name.
+type namedDriver struct {
+	Driver
+	name string
+}
+
+func (d namedDriver) Name() string {
+	return d.name
+}
+
+// TestServiceConfirmedHeight asserts that the confirmed height is unknown until
+// a batch confirms, and only ever advances, e.g. if pipelined batches reach the
+// confirmation depth out of order.
+func TestServiceConfirmedHeight(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{}
+
+	_, ok := s.ConfirmedHeight()
+	require.False(t, ok)
+
+	s.recordConfirmedHeight(20)
+	s.recordConfirmedHeight(15)
+
+	height, ok := s.ConfirmedHeight()
+	require.True(t, ok)
+	require.Equal(t, uint64(20), height)
+}
+
+// TestServiceNextCycleDelay asserts that cycles run back to back only while
+// catching up on a backlog.
+func TestServiceNextCycleDelay(t *testing.T) {
+	t.Parallel()
+
+	s := &Service{pollInterval: int64(time.Minute)}
+	require.Equal(t, time.Minute, s.nextCycleDelay())
+
+	s.catchingUp = true
+	require.Equal(t, time.Duration(0), s.nextCycleDelay())
+}
+
+// TestServiceRecordsSubmissions asserts that each tx published for a submission
+// is appended to its pending record, that the record is concluded with the
+// receipt of the confirmed tx, and that a later use of the nonce begins a new
+// record.
+func TestServiceRecordsSubmissions(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "service-state")
+	require.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	store, err := queue.NewStateStore(dir, 0)
+	require.Nil(t, err)
+	defer store.Close()
+
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver:     namedDriver{name: "TestServiceRecordsSubmissions"},
+			StateStore: store,
+			Build:      &queue.BuildInfo{Version: "v1.2.3"},
+		},
+	}
+
+	newTx := func(gasPrice int64) *types.Transaction {
+		return types.NewTransaction(
+			3, common.Address{}, nil, 0, big.NewInt(gasPrice), nil,
+		)
+	}
+
+	sub := newBatchSubmission(big.NewInt(10), big.NewInt(20), 3, nil)
+	first, bumped := newTx(1), newTx(2)
+	s.recordPublishedTx(sub, first)
+	s.recordPublishedTx(sub, bumped)
+	s.recordPublishedTx(sub, bumped)
+
+	pending, err := store.Pending()
+	require.Nil(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, uint64(10), pending[0].Start)
+	require.Equal(t, uint64(20), pending[0].End)
+	require.Equal(t, []common.Hash{first.Hash(), bumped.Hash()},
+		pending[0].TxHashes)
+	require.Equal(t, "v1.2.3", pending[0].Build.Version)
+
+	s.concludeSubmission(sub, queue.SubmissionConfirmed, &types.Receipt{
+		TxHash:      bumped.Hash(),
+		BlockNumber: big.NewInt(100),
+		GasUsed:     21000,
+		Status:      types.ReceiptStatusSuccessful,
+	})
+
+	record, err := store.Get(3)
+	require.Nil(t, err)
+	require.Equal(t, queue.SubmissionConfirmed, record.Status)
+	require.Equal(t, uint64(100), record.Receipt.BlockNumber)
+	require.Equal(t, bumped.Hash(), record.Receipt.TxHash)
+
+	// A concluded record is never reopened by a later use of the nonce.
+	sub = newBatchSubmission(big.NewInt(20), big.NewInt(30), 3, nil)
+	s.recordPublishedTx(sub, first)
+
+	record, err = store.Get(3)
+	require.Nil(t, err)
+	require.Equal(t, queue.SubmissionPending, record.Status)
+	require.Equal(t, uint64(20), record.Start)
+	require.Equal(t, []common.Hash{first.Hash()}, record.TxHashes)
+	require.Nil(t, record.Receipt)
+}
+
+// verifyingDriver is a Driver implementing BatchVerifier, reporting the given
+// mismatches or error for every batch.
+type verifyingDriver struct {
+	namedDriver
+	mismatches []string
+	err        error
+}
+
+func (d verifyingDriver) VerifyBatch(
+	context.Context, *types.Receipt) ([]string, error) {
+
+	return d.mismatches, d.err
+}
+
+// TestServiceVerifyBatch asserts that a critical alert is raised only when a
+// confirmed batch is verified and found not to match L2.
+func TestServiceVerifyBatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		verifyBatches bool
+		mismatches    []string
+		err           error
+		expSeverities []alerts.Severity
+	}{
+		{
+			name:          "disabled",
+			verifyBatches: false,
+			mismatches:    []string{"tx 0"},
+		},
+		{
+			name:          "match",
+			verifyBatches: true,
+		},
+		{
+			name:          "unable to verify",
+			verifyBatches: true,
+			err:           errors.New("not found"),
+		},
+		{
+			name:          "mismatch",
+			verifyBatches: true,
+			mismatches:    []string{"tx 0"},
+			expSeverities: []alerts.Severity{alerts.SeverityCritical},
+		},
+	}
+
+	for i, test := range tests {
+		name := fmt.Sprintf("TestServiceVerifyBatch%d", i)
+		notifier := &recordingNotifier{}
+		s := &Service{
+			cfg: ServiceConfig{
+				Driver: verifyingDriver{
+					namedDriver: namedDriver{name: name},
+					mismatches:  test.mismatches,
+					err:         test.err,
+				},
+				VerifyBatches:    test.verifyBatches,
+				BalanceNotifiers: []alerts.Notifier{notifier},
+			},
+			metrics: metrics.NewMetrics(name),
+		}
+
+		s.verifyBatch(context.Background(), &types.Receipt{
+			BlockNumber: big.NewInt(100),
+		})
+		s.wg.Wait()
+		require.Equal(t, test.expSeverities, notifier.severities,
+			test.name)
+	}
+}
+
+// txAPI serves txs by hash over the eth namespace.
+type txAPI struct {
+	txs map[common.Hash]*types.Transaction
+}
+
+func (a *txAPI) GetTransactionByHash(hash common.Hash) *types.Transaction {
+	return a.txs[hash]
+}
+
+// TestServiceArchiveBatch asserts that the calldata of a confirmed batch tx is
+// archived sealed under the configured key, and that nothing is archived
+// unless an archiver is configured.
+func TestServiceArchiveBatch(t *testing.T) {
+	t.Parallel()
+
+	callData := []byte("appendSequencerBatch calldata")
+	tx := types.NewTransaction(
+		3, common.Address{}, nil, 0, big.NewInt(1), callData,
+	)
+
+	server := rpc.NewServer()
+	require.Nil(t, server.RegisterName("eth", &txAPI{
+		txs: map[common.Hash]*types.Transaction{tx.Hash(): tx},
+	}))
+	defer server.Stop()
+
+	backend, err := queue.NewDirBackend(t.TempDir(), time.Minute)
+	require.Nil(t, err)
+
+	sealer, err := archive.NewSealer(
+		"key-1", bytes.Repeat([]byte{0x01}, archive.KeySize),
+	)
+	require.Nil(t, err)
+	archiver := archive.NewArchiver(sealer, backend)
+	defer archiver.Close()
+
+	s := &Service{
+		cfg: ServiceConfig{
+			Driver:   namedDriver{name: "TestServiceArchiveBatch"},
+			L1Client: ethclient.NewClient(rpc.DialInProc(server)),
+		},
+	}
+	sub := newBatchSubmission(big.NewInt(10), big.NewInt(20), 3, nil)
+	receipt := &types.Receipt{TxHash: tx.Hash()}
+
+	s.archiveBatch(context.Background(), sub, receipt)
+	_, err = archiver.Get(10)
+	require.True(t, errors.Is(err, queue.ErrNotFound))
+
+	s.cfg.Archiver = archiver
+	s.archiveBatch(context.Background(), sub, receipt)
+
+	record, err := archiver.Get(10)
+	require.Nil(t, err)
+	require.Equal(t, uint64(20), record.Manifest.End)
+	require.Equal(t, "key-1", record.Manifest.KeyID)
+	require.False(t, bytes.Contains(record.Sealed, callData))
+
+	opened, err := sealer.Open(record.Sealed, record.Manifest)
+	require.Nil(t, err)
+	require.Equal(t, callData, opened)
+}
+
+// TestServiceStopsOnLockLost asserts that a service whose lock over its
+// submission state is broken by another process stops, rather than racing the
+// new holder.
+func TestServiceStopsOnLockLost(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	stateStore, err := queue.NewStateStore(dir, 40*time.Millisecond)
+	require.Nil(t, err)
+	defer stateStore.Close()
+
+	s := newStartTestService("TestServiceStopsOnLockLost", nil)
+	s.cfg.StateStore = stateStore
+	require.Nil(t, s.Start())
+	defer s.Stop()
+
+	// Another host breaks the lock once its heartbeat seems stale.
+	err = ioutil.WriteFile(filepath.Join(dir, "LOCK"), []byte(fmt.Sprintf(
+		`{"pid":%d,"hostname":"other-host","heartbeat":%q}`,
+		os.Getppid(), time.Now().Add(time.Hour).Format(time.RFC3339),
+	)), 0600)
+	require.Nil(t, err)
+
+	select {
+	case <-s.ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("service not stopped after losing its lock")
+	}
+	_, err = stateStore.Pending()
+	require.True(t, errors.Is(err, queue.ErrQueueLockLost))
+}
@@ -0,0 +1,97 @@
+package batchsubmitter
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrL1ChainIDMismatch signals that the L1 provider reports a chain ID
+	// other than the one the batch submitter was configured for.
+	ErrL1ChainIDMismatch = errors.New("L1 chain ID does not match " +
+		"l1-chain-id")
+
+	// ErrInvalidChainID signals that the chain ID used for signing cannot
+	// produce replay-protected transactions.
+	ErrInvalidChainID = errors.New("chain ID must be positive")
+
+	// ErrTxNotReplayProtected signals that a self-check tx was signed
+	// without EIP-155 replay protection.
+	ErrTxNotReplayProtected = errors.New("signed tx is not replay protected")
+
+	// ErrUnexpectedTxChainID signals that a self-check tx was signed for a
+	// chain ID other than the configured one.
+	ErrUnexpectedTxChainID = errors.New("signed tx has unexpected chain ID")
+
+	// ErrUnexpectedTxType signals that a self-check tx was signed with a tx
+	// type other than the one used for batch submission.
+	ErrUnexpectedTxType = errors.New("signed tx has unexpected type")
+
+	// ErrUnexpectedTxSender signals that the sender recovered from a
+	// self-check tx does not match the configured wallet.
+	ErrUnexpectedTxSender = errors.New("signed tx has unexpected sender")
+)
+
+// VerifySigner performs a self-check signing round using the same transactor
+// as the drivers, asserting that the resulting transaction is a legacy,
+// EIP-155 replay-protected transaction for chainID whose sender recovers to
+// the address of privKey. Running this at startup surfaces a misconfigured
+// settlement chain immediately, rather than as an "invalid sender" rejection
+// when the first batch is broadcast.
+//
+// NOTE: The self-check transaction is never published.
+func VerifySigner(privKey *ecdsa.PrivateKey, chainID *big.Int) error {
+	if chainID == nil || chainID.Sign() <= 0 {
+		return ErrInvalidChainID
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(privKey, chainID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := opts.Signer(opts.From, types.NewTx(&types.LegacyTx{
+		Nonce:    0,
+		GasPrice: big.NewInt(1),
+		Gas:      21000,
+		To:       &common.Address{},
+		Value:    big.NewInt(0),
+	}))
+	if err != nil {
+		return err
+	}
+
+	if tx.Type() != types.LegacyTxType {
+		return fmt.Errorf("%w: want=%d got=%d",
+			ErrUnexpectedTxType, types.LegacyTxType, tx.Type())
+	}
+	if !tx.Protected() {
+		return ErrTxNotReplayProtected
+	}
+	if tx.ChainId().Cmp(chainID) != 0 {
+		return fmt.Errorf("%w: want=%v got=%v",
+			ErrUnexpectedTxChainID, chainID, tx.ChainId())
+	}
+
+	// Recover the sender independently of the transactor, as a node on the
+	// settlement chain would.
+	sender, err := types.Sender(types.NewEIP155Signer(chainID), tx)
+	if err != nil {
+		return err
+	}
+
+	expSender := crypto.PubkeyToAddress(privKey.PublicKey)
+	if sender != expSender {
+		return fmt.Errorf("%w: want=%s got=%s",
+			ErrUnexpectedTxSender, expSender, sender)
+	}
+
+	return nil
+}
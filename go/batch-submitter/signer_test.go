@@ -0,0 +1,41 @@
+package batchsubmitter_test
+
+import (
+	"math/big"
+	"testing"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVerifySigner asserts that the signer self-check accepts any positive
+// chain ID, including those of non-mainnet settlement chains, and rejects
+// chain IDs that cannot be replay protected.
+func TestVerifySigner(t *testing.T) {
+	t.Parallel()
+
+	privKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		chainID *big.Int
+		expErr  error
+	}{
+		{"mainnet", big.NewInt(1), nil},
+		{"goerli", big.NewInt(5), nil},
+		{"large chain id", new(big.Int).SetUint64(1<<40 + 7), nil},
+		{"nil chain id", nil, batchsubmitter.ErrInvalidChainID},
+		{"zero chain id", big.NewInt(0), batchsubmitter.ErrInvalidChainID},
+		{"negative chain id", big.NewInt(-1), batchsubmitter.ErrInvalidChainID},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			err := batchsubmitter.VerifySigner(privKey, test.chainID)
+			require.Equal(t, test.expErr, err)
+		})
+	}
+}
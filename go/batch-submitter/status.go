@@ -0,0 +1,142 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// statusPath is the path at which the status API is served by the metrics
+// server.
+const statusPath = "/status"
+
+// ServiceStatus is the status of a single service as reported by the status
+// API.
+type ServiceStatus struct {
+	// Name identifies the service.
+	Name string `json:"name"`
+
+	// Wallet is the labeled address of the service's wallet.
+	Wallet LabeledAddress `json:"wallet"`
+
+	// Paused is true while submission is paused by an operator.
+	Paused bool `json:"paused"`
+
+	// Standby is true while another replica holds leadership in high
+	// availability mode.
+	Standby bool `json:"standby"`
+
+	// Draining is true once the service has begun draining ahead of
+	// shutdown.
+	Draining bool `json:"draining"`
+
+	// SelfTestErr describes the failed checks of the startup self-test,
+	// if the service started degraded.
+	SelfTestErr string `json:"self_test_error,omitempty"`
+
+	// Quarantined is the range held back after exhausting its retry
+	// budget, if any.
+	Quarantined *QuarantinedRange `json:"quarantined,omitempty"`
+
+	// CatchUp is the plan of the current catch-up, or of the last one to
+	// conclude, if a catch-up budget is configured.
+	CatchUp *CatchUpPlan `json:"catch_up,omitempty"`
+
+	// RecentCycles are the decision traces of the service's most recent
+	// cycles, most recent first.
+	RecentCycles []*CycleTrace `json:"recent_cycles"`
+
+	// RecentSubmissions are the service's most recently recorded batch
+	// txs, most recent nonce first, if a StateStore is configured.
+	RecentSubmissions []*queue.SubmissionRecord `json:"recent_submissions,omitempty"`
+}
+
+// StatusResponse is the body returned by the status API.
+type StatusResponse struct {
+	Services []ServiceStatus `json:"services"`
+}
+
+// statusRegistry tracks the running services of every tenant in the process,
+// and serves their status.
+type statusRegistry struct {
+	mu       sync.RWMutex
+	services map[string]*Service
+}
+
+// defaultStatusRegistry is the registry served by the metrics server.
+var defaultStatusRegistry = &statusRegistry{
+	services: make(map[string]*Service),
+}
+
+// register adds s to the registry.
+func (r *statusRegistry) register(s *Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.services[s.cfg.Driver.Name()] = s
+}
+
+// unregister removes s from the registry.
+func (r *statusRegistry) unregister(s *Service) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.services, s.cfg.Driver.Name())
+}
+
+// lookup returns the registered service with the given name.
+func (r *statusRegistry) lookup(name string) (*Service, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.services[name]
+	return s, ok
+}
+
+// sorted returns all registered services, ordered by name.
+func (r *statusRegistry) sorted() []*Service {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	services := make([]*Service, 0, len(r.services))
+	for _, s := range r.services {
+		services = append(services, s)
+	}
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].cfg.Driver.Name() <
+			services[j].cfg.Driver.Name()
+	})
+
+	return services
+}
+
+// Status returns the status of all registered services, ordered by name.
+func (r *statusRegistry) Status() StatusResponse {
+	services := r.sorted()
+
+	statuses := make([]ServiceStatus, 0, len(services))
+	for _, s := range services {
+		statuses = append(statuses, s.Status())
+	}
+
+	return StatusResponse{
+		Services: statuses,
+	}
+}
+
+// ServeHTTP serves the status of all registered services as JSON.
+func (r *statusRegistry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(r.Status()); err != nil {
+		log.Error("Unable to write status response", "err", err)
+	}
+}
@@ -0,0 +1,262 @@
+package batchsubmitter
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// defaultRecentSubmissions is the number of recorded batch txs reported by the
+// status API.
+const defaultRecentSubmissions = 10
+
+// recordPublishedTx records tx as published for sub in the configured
+// StateStore, if any. The first tx published at a nonce begins a new pending
+// record, and each fee bump appends to it.
+func (s *Service) recordPublishedTx(sub *batchSubmission, tx *types.Transaction) {
+	store := s.cfg.StateStore
+	if store == nil {
+		return
+	}
+
+	record, err := store.Get(sub.nonce)
+	if err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to get submission record",
+			"nonce", sub.nonce, "err", err)
+		return
+	}
+
+	// A concluded record, or one for another range, belongs to an earlier
+	// use of the nonce.
+	if record == nil || record.Status != queue.SubmissionPending ||
+		record.Start != sub.start.Uint64() {
+
+		record = &queue.SubmissionRecord{
+			Nonce:        sub.nonce,
+			Start:        sub.start.Uint64(),
+			End:          sub.end.Uint64(),
+			EndBlockHash: sub.endBlockHash,
+			Build:        s.cfg.Build,
+			Status:       queue.SubmissionPending,
+			SubmittedAt:  sub.firstBroadcast,
+		}
+	}
+	if record.LastTxHash() == tx.Hash() {
+		return
+	}
+	record.TxHashes = append(record.TxHashes, tx.Hash())
+	record.Attempts = append(record.Attempts, queue.SubmissionAttempt{
+		TxHash:      tx.Hash(),
+		GasPrice:    tx.GasPrice(),
+		PublishedAt: time.Now(),
+	})
+
+	if err := store.Put(record); err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to record submission",
+			"nonce", sub.nonce, "tx_hash", tx.Hash(), "err", err)
+	}
+}
+
+// concludeSubmission marks the pending record of sub with the given status in
+// the configured StateStore, if any, along with the receipt of its confirmed
+// batch tx.
+func (s *Service) concludeSubmission(
+	sub *batchSubmission,
+	status queue.SubmissionStatus,
+	receipt *types.Receipt,
+) {
+
+	store := s.cfg.StateStore
+	if store == nil {
+		return
+	}
+
+	record, err := store.Get(sub.nonce)
+	if err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to get submission record",
+			"nonce", sub.nonce, "err", err)
+		return
+	}
+	if record == nil || record.Status != queue.SubmissionPending {
+		return
+	}
+
+	s.concludeRecord(record, status, receipt)
+}
+
+// concludeRecord marks record with the given status, along with the receipt of
+// its confirmed batch tx.
+func (s *Service) concludeRecord(
+	record *queue.SubmissionRecord,
+	status queue.SubmissionStatus,
+	receipt *types.Receipt,
+) {
+
+	record.Status = status
+	if receipt != nil {
+		record.Receipt = &queue.SubmissionReceipt{
+			TxHash:      receipt.TxHash,
+			BlockNumber: receipt.BlockNumber.Uint64(),
+			BlockHash:   receipt.BlockHash,
+			GasUsed:     receipt.GasUsed,
+			Status:      receipt.Status,
+		}
+	}
+
+	if err := s.cfg.StateStore.Put(record); err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to record submission",
+			"nonce", record.Nonce, "status", status, "err", err)
+	}
+}
+
+// resumeSubmissions reconciles the records left pending by a previous run, in
+// order of nonce. Records whose nonce has since been consumed are concluded
+// from the receipts of their txs, while those whose last tx is still known to
+// the L1 backend are tracked until confirmed, bumping fees as usual. Records
+// whose txs were dropped are abandoned, leaving their ranges to be rebuilt.
+func (s *Service) resumeSubmissions() error {
+	name := s.cfg.Driver.Name()
+
+	pending, err := s.cfg.StateStore.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	latest, err := s.cfg.L1Client.NonceAt(
+		s.ctx, s.cfg.Driver.WalletAddr(), nil,
+	)
+	if err != nil {
+		return err
+	}
+
+	for _, record := range pending {
+		if record.Nonce < latest {
+			if err := s.reconcileSubmission(record); err != nil {
+				return err
+			}
+			continue
+		}
+
+		tx, _, err := s.cfg.L1Client.TransactionByHash(
+			s.ctx, record.LastTxHash(),
+		)
+		if errors.Is(err, ethereum.NotFound) {
+			log.Warn(name+" pending batch tx dropped, abandoning",
+				"nonce", record.Nonce, "tx_hash", record.LastTxHash())
+			s.concludeRecord(record, queue.SubmissionAbandoned, nil)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		sub, err := s.resumedSubmission(record, tx)
+		if err != nil {
+			return err
+		}
+
+		log.Info(name+" resuming pending batch submission",
+			"start", record.Start, "end", record.End,
+			"nonce", record.Nonce, "tx_hash", tx.Hash())
+		if _, err := s.confirmBatchTx(s.ctx, sub); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// reconcileSubmission concludes a pending record whose nonce has since been
+// consumed, confirming it if any of its txs was mined and abandoning it
+// otherwise, e.g. if the nonce was consumed by a cancellation.
+func (s *Service) reconcileSubmission(record *queue.SubmissionRecord) error {
+	for i := len(record.TxHashes) - 1; i >= 0; i-- {
+		receipt, err := s.cfg.L1Client.TransactionReceipt(
+			s.ctx, record.TxHashes[i],
+		)
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		log.Info(s.cfg.Driver.Name()+" pending batch tx mined while "+
+			"stopped", "nonce", record.Nonce,
+			"tx_hash", receipt.TxHash)
+		s.concludeRecord(record, queue.SubmissionConfirmed, receipt)
+		s.pinEndBlock(record.End, record.EndBlockHash, receipt)
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			s.storeInclusionProof(s.ctx, receipt)
+		}
+		return nil
+	}
+
+	s.concludeRecord(record, queue.SubmissionAbandoned, nil)
+	return nil
+}
+
+// resumedSubmission recreates the submission of record, whose last tx was
+// published as tx. The queued batch covering the same range is used for any
+// fee bumps, if available.
+func (s *Service) resumedSubmission(
+	record *queue.SubmissionRecord,
+	tx *types.Transaction,
+) (*batchSubmission, error) {
+
+	var batch *queue.Batch
+	if s.cfg.SubmissionQueue != nil {
+		batches, err := s.cfg.SubmissionQueue.Batches()
+		if err != nil {
+			return nil, err
+		}
+		for _, queued := range batches {
+			if queued.Start == record.Start &&
+				queued.End == record.End {
+				batch = queued
+				break
+			}
+		}
+	}
+
+	sub := newBatchSubmission(
+		new(big.Int).SetUint64(record.Start),
+		new(big.Int).SetUint64(record.End),
+		record.Nonce,
+		batch,
+	)
+	sub.resumed = tx
+	sub.endBlockHash = record.EndBlockHash
+	sub.firstBroadcast = record.SubmittedAt
+	if sub.firstBroadcast.IsZero() {
+		sub.firstBroadcast = time.Now()
+	}
+	sub.publishOnce.Do(func() { close(sub.published) })
+
+	return sub, nil
+}
+
+// recentSubmissions returns the most recently recorded batch txs, or nil if no
+// StateStore is configured.
+func (s *Service) recentSubmissions() []*queue.SubmissionRecord {
+	if s.cfg.StateStore == nil {
+		return nil
+	}
+
+	recent, err := s.cfg.StateStore.Recent(defaultRecentSubmissions)
+	if err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to get recent "+
+			"submissions", "err", err)
+		return nil
+	}
+
+	return recent
+}
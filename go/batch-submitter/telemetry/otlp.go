@@ -0,0 +1,267 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// otlpAggregationCumulative is the OTLP aggregation temporality of values
+// accumulated since the process started, matching Prometheus semantics.
+const otlpAggregationCumulative = 2
+
+// OTLPSink publishes samples to an OpenTelemetry collector using OTLP/HTTP
+// with JSON encoding, e.g. to "http://localhost:4318/v1/metrics".
+type OTLPSink struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+	startedAt   time.Time
+}
+
+// NewOTLPSink initializes an OTLPSink publishing to endpoint, identifying the
+// process as serviceName.
+func NewOTLPSink(endpoint, serviceName string) *OTLPSink {
+	return &OTLPSink{
+		endpoint:    endpoint,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		startedAt:   time.Now(),
+	}
+}
+
+// Name identifies the sink in logs.
+func (s *OTLPSink) Name() string {
+	return "otlp"
+}
+
+// Push publishes samples to the collector.
+func (s *OTLPSink) Push(ctx context.Context, samples []Sample) error {
+	body, err := json.Marshal(s.request(samples, time.Now()))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, s.endpoint, bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp collector returned status %d",
+			resp.StatusCode)
+	}
+
+	return nil
+}
+
+// The following types encode the subset of the OTLP metrics request used by
+// the sink, following the protobuf JSON mapping.
+
+type otlpRequest struct {
+	ResourceMetrics []otlpResourceMetrics `json:"resourceMetrics"`
+}
+
+type otlpResourceMetrics struct {
+	Resource     otlpResource       `json:"resource"`
+	ScopeMetrics []otlpScopeMetrics `json:"scopeMetrics"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpScopeMetrics struct {
+	Scope   otlpScope    `json:"scope"`
+	Metrics []otlpMetric `json:"metrics"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpMetric struct {
+	Name      string         `json:"name"`
+	Sum       *otlpSum       `json:"sum,omitempty"`
+	Gauge     *otlpGauge     `json:"gauge,omitempty"`
+	Histogram *otlpHistogram `json:"histogram,omitempty"`
+}
+
+type otlpSum struct {
+	DataPoints             []otlpNumberDataPoint `json:"dataPoints"`
+	AggregationTemporality int                   `json:"aggregationTemporality"`
+	IsMonotonic            bool                  `json:"isMonotonic"`
+}
+
+type otlpGauge struct {
+	DataPoints []otlpNumberDataPoint `json:"dataPoints"`
+}
+
+type otlpHistogram struct {
+	DataPoints             []otlpHistogramDataPoint `json:"dataPoints"`
+	AggregationTemporality int                      `json:"aggregationTemporality"`
+}
+
+type otlpNumberDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	AsDouble          float64         `json:"asDouble"`
+}
+
+type otlpHistogramDataPoint struct {
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	TimeUnixNano      string          `json:"timeUnixNano"`
+	Count             string          `json:"count"`
+	Sum               float64         `json:"sum"`
+	BucketCounts      []string        `json:"bucketCounts,omitempty"`
+	ExplicitBounds    []float64       `json:"explicitBounds,omitempty"`
+}
+
+// request encodes samples gathered at now as an OTLP metrics request, with one
+// metric per name holding a data point per series.
+func (s *OTLPSink) request(samples []Sample, now time.Time) otlpRequest {
+	startTime := strconv.FormatInt(s.startedAt.UnixNano(), 10)
+	timestamp := strconv.FormatInt(now.UnixNano(), 10)
+
+	var (
+		names   []string
+		metrics = make(map[string]*otlpMetric)
+	)
+	for _, sample := range samples {
+		metric, ok := metrics[sample.Name]
+		if !ok {
+			metric = &otlpMetric{Name: sample.Name}
+			metrics[sample.Name] = metric
+			names = append(names, sample.Name)
+		}
+		attrs := otlpAttributes(sample.Labels)
+
+		switch sample.Kind {
+		case KindCounter:
+			if metric.Sum == nil {
+				metric.Sum = &otlpSum{
+					AggregationTemporality: otlpAggregationCumulative,
+					IsMonotonic:            true,
+				}
+			}
+			metric.Sum.DataPoints = append(metric.Sum.DataPoints,
+				otlpNumberDataPoint{
+					Attributes:        attrs,
+					StartTimeUnixNano: startTime,
+					TimeUnixNano:      timestamp,
+					AsDouble:          sample.Value,
+				})
+
+		case KindGauge:
+			if metric.Gauge == nil {
+				metric.Gauge = &otlpGauge{}
+			}
+			metric.Gauge.DataPoints = append(metric.Gauge.DataPoints,
+				otlpNumberDataPoint{
+					Attributes:        attrs,
+					StartTimeUnixNano: startTime,
+					TimeUnixNano:      timestamp,
+					AsDouble:          sample.Value,
+				})
+
+		case KindHistogram:
+			if metric.Histogram == nil {
+				metric.Histogram = &otlpHistogram{
+					AggregationTemporality: otlpAggregationCumulative,
+				}
+			}
+			point := otlpHistogramDataPoint{
+				Attributes:        attrs,
+				StartTimeUnixNano: startTime,
+				TimeUnixNano:      timestamp,
+				Count:             strconv.FormatUint(sample.Count, 10),
+				Sum:               sample.Sum,
+			}
+
+			// OTLP buckets hold non-cumulative counts, with a final
+			// bucket above the last explicit bound.
+			if len(sample.Buckets) > 0 {
+				var prev uint64
+				for _, bucket := range sample.Buckets {
+					point.ExplicitBounds = append(
+						point.ExplicitBounds, bucket.UpperBound,
+					)
+					point.BucketCounts = append(point.BucketCounts,
+						strconv.FormatUint(
+							bucket.CumulativeCount-prev, 10,
+						))
+					prev = bucket.CumulativeCount
+				}
+				point.BucketCounts = append(point.BucketCounts,
+					strconv.FormatUint(sample.Count-prev, 10))
+			}
+			metric.Histogram.DataPoints = append(
+				metric.Histogram.DataPoints, point,
+			)
+		}
+	}
+
+	otlpMetrics := make([]otlpMetric, 0, len(names))
+	for _, name := range names {
+		otlpMetrics = append(otlpMetrics, *metrics[name])
+	}
+
+	return otlpRequest{
+		ResourceMetrics: []otlpResourceMetrics{{
+			Resource: otlpResource{
+				Attributes: []otlpAttribute{{
+					Key:   "service.name",
+					Value: otlpValue{StringValue: s.serviceName},
+				}},
+			},
+			ScopeMetrics: []otlpScopeMetrics{{
+				Scope:   otlpScope{Name: s.serviceName},
+				Metrics: otlpMetrics,
+			}},
+		}},
+	}
+}
+
+// otlpAttributes encodes labels as OTLP attributes, ordered by name.
+func otlpAttributes(labels map[string]string) []otlpAttribute {
+	attrs := make([]otlpAttribute, 0, len(labels))
+	for name, value := range labels {
+		attrs = append(attrs, otlpAttribute{
+			Key:   name,
+			Value: otlpValue{StringValue: value},
+		})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		return attrs[i].Key < attrs[j].Key
+	})
+
+	return attrs
+}
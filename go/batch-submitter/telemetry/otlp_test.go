@@ -0,0 +1,84 @@
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOTLPSink asserts that samples are published to the collector as
+// cumulative OTLP metrics, with non-cumulative histogram buckets.
+func TestOTLPSink(t *testing.T) {
+	t.Parallel()
+
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			require.Equal(t, "application/json",
+				req.Header.Get("Content-Type"))
+
+			var body map[string]interface{}
+			require.Nil(t, json.NewDecoder(req.Body).Decode(&body))
+			received <- body
+		},
+	))
+	defer server.Close()
+
+	registry, counter, gauge, histogram := newTestRegistry(t)
+	counter.WithLabelValues("mainnet").Add(3)
+	gauge.Set(1.5)
+	histogram.Observe(5)
+	histogram.Observe(50)
+	histogram.Observe(500)
+
+	samples, err := telemetry.Gather(registry)
+	require.Nil(t, err)
+
+	sink := telemetry.NewOTLPSink(server.URL, "batch-submitter")
+	require.Nil(t, sink.Push(context.Background(), samples))
+
+	body := <-received
+	scope := body["resourceMetrics"].([]interface{})[0].(map[string]interface{})["scopeMetrics"].([]interface{})[0].(map[string]interface{})
+
+	metrics := make(map[string]map[string]interface{})
+	for _, metric := range scope["metrics"].([]interface{}) {
+		m := metric.(map[string]interface{})
+		metrics[m["name"].(string)] = m
+	}
+	require.Len(t, metrics, 3)
+
+	sum := metrics["batches_submitted"]["sum"].(map[string]interface{})
+	require.Equal(t, true, sum["isMonotonic"])
+	point := sum["dataPoints"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, 3.0, point["asDouble"])
+	attr := point["attributes"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "tenant", attr["key"])
+
+	gaugePoint := metrics["balance_eth"]["gauge"].(map[string]interface{})["dataPoints"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, 1.5, gaugePoint["asDouble"])
+
+	histPoint := metrics["batch_size"]["histogram"].(map[string]interface{})["dataPoints"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, "3", histPoint["count"])
+	require.Equal(t, []interface{}{"1", "1", "1"}, histPoint["bucketCounts"])
+	require.Equal(t, []interface{}{10.0, 100.0}, histPoint["explicitBounds"])
+}
+
+// TestOTLPSinkRejected asserts that a collector error is reported.
+func TestOTLPSinkRejected(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		},
+	))
+	defer server.Close()
+
+	sink := telemetry.NewOTLPSink(server.URL, "batch-submitter")
+	require.Error(t, sink.Push(context.Background(), nil))
+}
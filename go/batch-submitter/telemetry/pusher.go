@@ -0,0 +1,100 @@
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Sink is a telemetry backend to which gathered samples are pushed, used in
+// place of, or alongside, scraping the Prometheus registry.
+type Sink interface {
+	// Name identifies the sink in logs.
+	Name() string
+
+	// Push publishes a snapshot of every series.
+	Push(ctx context.Context, samples []Sample) error
+}
+
+// Pusher periodically gathers every series registered with a Prometheus
+// gatherer and pushes them to each of its sinks. Instrumentation is left
+// untouched, such that every metric is available to every backend.
+type Pusher struct {
+	gatherer prometheus.Gatherer
+	interval time.Duration
+	sinks    []Sink
+
+	quit     chan struct{}
+	done     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewPusher initializes a Pusher pushing the series of gatherer to sinks once
+// per interval.
+func NewPusher(
+	gatherer prometheus.Gatherer,
+	interval time.Duration,
+	sinks ...Sink,
+) *Pusher {
+
+	return &Pusher{
+		gatherer: gatherer,
+		interval: interval,
+		sinks:    sinks,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Start begins pushing in the background until Stop is called.
+func (p *Pusher) Start() {
+	go p.loop()
+}
+
+// Stop pushes a final snapshot, then stops pushing.
+func (p *Pusher) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.quit)
+		<-p.done
+	})
+}
+
+func (p *Pusher) loop() {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			p.Push()
+
+		case <-p.quit:
+			p.Push()
+			return
+		}
+	}
+}
+
+// Push gathers every series and pushes them to each sink, logging any sink
+// that fails. Each sink is allowed up to the push interval.
+func (p *Pusher) Push() {
+	samples, err := Gather(p.gatherer)
+	if err != nil {
+		log.Error("Unable to gather metrics", "err", err)
+		return
+	}
+
+	for _, sink := range p.sinks {
+		ctx, cancel := context.WithTimeout(context.Background(), p.interval)
+		if err := sink.Push(ctx, samples); err != nil {
+			log.Error("Unable to push metrics", "sink", sink.Name(),
+				"err", err)
+		}
+		cancel()
+	}
+}
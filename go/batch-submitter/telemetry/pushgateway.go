@@ -0,0 +1,222 @@
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// pushgatewayContentType is the content type of the Prometheus text exposition
+// format pushed to the Pushgateway.
+const pushgatewayContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PushgatewaySink publishes samples to a Prometheus Pushgateway, such that
+// ephemeral deployments that cannot be scraped are still monitored. Each push
+// replaces every series of the sink's group, identified by its job and
+// grouping labels.
+type PushgatewaySink struct {
+	url    string
+	client *http.Client
+}
+
+// NewPushgatewaySink initializes a PushgatewaySink publishing to the
+// Pushgateway at endpoint, e.g. "http://localhost:9091", under the group
+// identified by job and grouping.
+func NewPushgatewaySink(
+	endpoint string,
+	job string,
+	grouping map[string]string,
+) *PushgatewaySink {
+
+	endpoint = strings.TrimSuffix(endpoint, "/")
+
+	return &PushgatewaySink{
+		url:    endpoint + pushgatewayPath(job, grouping),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name identifies the sink in logs.
+func (s *PushgatewaySink) Name() string {
+	return "pushgateway"
+}
+
+// Push replaces the series of the sink's group with samples.
+func (s *PushgatewaySink) Push(ctx context.Context, samples []Sample) error {
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPut, s.url, bytes.NewReader(encodeText(samples)),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", pushgatewayContentType)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(ioutil.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("pushgateway returned status %d",
+			resp.StatusCode)
+	}
+
+	return nil
+}
+
+// pushgatewayPath returns the path of the group identified by job and
+// grouping. Values that cannot be expressed as a path segment are base64
+// encoded, as supported by the Pushgateway.
+func pushgatewayPath(job string, grouping map[string]string) string {
+	names := make([]string, 0, len(grouping))
+	for name := range grouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("/metrics")
+	writeSegment := func(name, value string) {
+		if value == "" || strings.Contains(value, "/") {
+			b.WriteString("/" + name + "@base64/")
+			b.WriteString(base64.RawURLEncoding.EncodeToString(
+				[]byte(value),
+			))
+			if value == "" {
+				b.WriteString("=")
+			}
+			return
+		}
+		b.WriteString("/" + name + "/" + url.PathEscape(value))
+	}
+
+	writeSegment("job", job)
+	for _, name := range names {
+		writeSegment(name, grouping[name])
+	}
+
+	return b.String()
+}
+
+// encodeText encodes samples in the Prometheus text exposition format.
+// Histograms, including summaries gathered as histograms, are encoded with a
+// final +Inf bucket.
+func encodeText(samples []Sample) []byte {
+	// Series of the same metric must be grouped under a single TYPE line.
+	sorted := append([]Sample{}, samples...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Name < sorted[j].Name
+	})
+
+	var b bytes.Buffer
+	for i, sample := range sorted {
+		if i == 0 || sorted[i-1].Name != sample.Name {
+			fmt.Fprintf(&b, "# TYPE %s %s\n", sample.Name,
+				textType(sample.Kind))
+		}
+
+		switch sample.Kind {
+		case KindCounter, KindGauge:
+			writeTextLine(&b, sample.Name, sample.Labels, "",
+				sample.Value)
+
+		case KindHistogram:
+			for _, bucket := range sample.Buckets {
+				writeTextLine(&b, sample.Name+"_bucket",
+					sample.Labels,
+					formatTextValue(bucket.UpperBound),
+					float64(bucket.CumulativeCount))
+			}
+			writeTextLine(&b, sample.Name+"_bucket", sample.Labels,
+				"+Inf", float64(sample.Count))
+			writeTextLine(&b, sample.Name+"_sum", sample.Labels, "",
+				sample.Sum)
+			writeTextLine(&b, sample.Name+"_count", sample.Labels, "",
+				float64(sample.Count))
+		}
+	}
+
+	return b.Bytes()
+}
+
+// textType returns the type of kind in the text exposition format.
+func textType(kind Kind) string {
+	switch kind {
+	case KindCounter:
+		return "counter"
+	case KindHistogram:
+		return "histogram"
+	default:
+		return "gauge"
+	}
+}
+
+// writeTextLine writes a single series to b, with an le label if le is
+// non-empty.
+func writeTextLine(
+	b *bytes.Buffer,
+	name string,
+	labels map[string]string,
+	le string,
+	value float64,
+) {
+
+	names := make([]string, 0, len(labels))
+	for label := range labels {
+		names = append(names, label)
+	}
+	sort.Strings(names)
+
+	b.WriteString(name)
+	if len(names) > 0 || le != "" {
+		b.WriteString("{")
+		for i, label := range names {
+			if i > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(b, `%s="%s"`, label,
+				labelValueEscaper.Replace(labels[label]))
+		}
+		if le != "" {
+			if len(names) > 0 {
+				b.WriteString(",")
+			}
+			fmt.Fprintf(b, `le="%s"`, le)
+		}
+		b.WriteString("}")
+	}
+	b.WriteString(" ")
+	b.WriteString(formatTextValue(value))
+	b.WriteString("\n")
+}
+
+// labelValueEscaper escapes label values as in the text exposition format.
+var labelValueEscaper = strings.NewReplacer(
+	`\`, `\\`, "\n", `\n`, `"`, `\"`,
+)
+
+// formatTextValue formats value as in the text exposition format.
+func formatTextValue(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(value, 'g', -1, 64)
+	}
+}
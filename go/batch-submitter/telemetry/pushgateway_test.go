@@ -0,0 +1,72 @@
+package telemetry_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPushgatewaySink asserts that samples replace the sink's group in the
+// text exposition format, with histograms ending in a +Inf bucket.
+func TestPushgatewaySink(t *testing.T) {
+	t.Parallel()
+
+	type push struct {
+		method string
+		path   string
+		body   string
+	}
+	received := make(chan push, 1)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			body, err := ioutil.ReadAll(req.Body)
+			require.Nil(t, err)
+			received <- push{
+				method: req.Method,
+				path:   req.URL.EscapedPath(),
+				body:   string(body),
+			}
+		},
+	))
+	defer server.Close()
+
+	registry, counter, gauge, histogram := newTestRegistry(t)
+	counter.WithLabelValues(`main"net`).Add(3)
+	gauge.Set(1.5)
+	histogram.Observe(5)
+	histogram.Observe(500)
+
+	samples, err := telemetry.Gather(registry)
+	require.Nil(t, err)
+
+	sink := telemetry.NewPushgatewaySink(server.URL, "batch_submitter",
+		map[string]string{
+			"network":  "mainnet",
+			"instance": "host/1",
+		})
+	require.Nil(t, sink.Push(context.Background(), samples))
+
+	p := <-received
+	require.Equal(t, http.MethodPut, p.method)
+	require.Equal(t, "/metrics/job/batch_submitter/instance@base64/"+
+		"aG9zdC8x/network/mainnet", p.path)
+	require.Equal(t, []string{
+		"# TYPE balance_eth gauge",
+		"balance_eth 1.5",
+		"# TYPE batch_size histogram",
+		`batch_size_bucket{le="10"} 1`,
+		`batch_size_bucket{le="100"} 1`,
+		`batch_size_bucket{le="+Inf"} 2`,
+		"batch_size_sum 505",
+		"batch_size_count 2",
+		"# TYPE batches_submitted counter",
+		`batches_submitted{tenant="main\"net"} 3`,
+		"",
+	}, strings.Split(p.body, "\n"))
+}
@@ -0,0 +1,153 @@
+package telemetry
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// Kind is the type of a gathered metric.
+type Kind int
+
+const (
+	// KindCounter is a monotonically increasing total.
+	KindCounter Kind = iota
+
+	// KindGauge is a value that may rise and fall.
+	KindGauge
+
+	// KindHistogram is a distribution of observations.
+	KindHistogram
+)
+
+// Bucket is a single cumulative bucket of a histogram.
+type Bucket struct {
+	// UpperBound is the inclusive upper bound of the bucket.
+	UpperBound float64
+
+	// CumulativeCount is the number of observations at or below
+	// UpperBound.
+	CumulativeCount uint64
+}
+
+// Sample is the value of a single metric series at the time it was gathered.
+type Sample struct {
+	// Name is the fully qualified name of the metric.
+	Name string
+
+	// Labels are the labels of the series.
+	Labels map[string]string
+
+	// Kind is the type of the metric.
+	Kind Kind
+
+	// Value is the cumulative total of a counter, or the current value of
+	// a gauge.
+	Value float64
+
+	// Count and Sum are the number and sum of a histogram's
+	// observations.
+	Count uint64
+	Sum   float64
+
+	// Buckets are the cumulative buckets of a histogram, in ascending
+	// order of their upper bound, excluding the implicit +Inf bucket.
+	Buckets []Bucket
+}
+
+// Key identifies the series of the sample, combining its name and labels.
+func (s Sample) Key() string {
+	names := make([]string, 0, len(s.Labels))
+	for name := range s.Labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(s.Name)
+	for _, name := range names {
+		b.WriteString(",")
+		b.WriteString(name)
+		b.WriteString("=")
+		b.WriteString(s.Labels[name])
+	}
+
+	return b.String()
+}
+
+// Gather collects a sample of every series registered with gatherer.
+// Summaries are reported as histograms without buckets, and untyped metrics
+// as gauges.
+func Gather(gatherer prometheus.Gatherer) ([]Sample, error) {
+	families, err := gatherer.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	var samples []Sample
+	for _, family := range families {
+		for _, metric := range family.GetMetric() {
+			sample := Sample{
+				Name:   family.GetName(),
+				Labels: labels(metric),
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				sample.Kind = KindCounter
+				sample.Value = metric.GetCounter().GetValue()
+
+			case dto.MetricType_GAUGE:
+				sample.Kind = KindGauge
+				sample.Value = metric.GetGauge().GetValue()
+
+			case dto.MetricType_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				sample.Kind = KindHistogram
+				sample.Count = histogram.GetSampleCount()
+				sample.Sum = histogram.GetSampleSum()
+				for _, bucket := range histogram.GetBucket() {
+					if math.IsInf(bucket.GetUpperBound(), 1) {
+						continue
+					}
+					sample.Buckets = append(sample.Buckets, Bucket{
+						UpperBound:      bucket.GetUpperBound(),
+						CumulativeCount: bucket.GetCumulativeCount(),
+					})
+				}
+
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				sample.Kind = KindHistogram
+				sample.Count = summary.GetSampleCount()
+				sample.Sum = summary.GetSampleSum()
+
+			default:
+				sample.Kind = KindGauge
+				sample.Value = metric.GetUntyped().GetValue()
+			}
+
+			samples = append(samples, sample)
+		}
+	}
+
+	return samples, nil
+}
+
+// labels returns the labels of metric as a map.
+func labels(metric *dto.Metric) map[string]string {
+	pairs := metric.GetLabel()
+	if len(pairs) == 0 {
+		return nil
+	}
+
+	labels := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		labels[pair.GetName()] = pair.GetValue()
+	}
+
+	return labels
+}
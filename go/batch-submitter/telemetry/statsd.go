@@ -0,0 +1,151 @@
+package telemetry
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxStatsDPacketSize is the max size of a single StatsD datagram, chosen to
+// avoid fragmentation on common network MTUs.
+const maxStatsDPacketSize = 1432
+
+// StatsDSink publishes samples to a StatsD agent over UDP, using the DogStatsD
+// extension for labels, such that it is also compatible with the Datadog
+// agent. Since StatsD counters are deltas, counters and histogram counts are
+// published as the increase since the previous push.
+//
+// NOTE: StatsDSink is safe for concurrent use.
+type StatsDSink struct {
+	mu   sync.Mutex
+	conn net.Conn
+
+	// prev tracks the last pushed cumulative value of each counter series,
+	// and the last pushed count and sum of each histogram series.
+	prev map[string]float64
+}
+
+// NewStatsDSink initializes a StatsDSink publishing to the agent at addr, e.g.
+// "localhost:8125".
+func NewStatsDSink(addr string) (*StatsDSink, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StatsDSink{
+		conn: conn,
+		prev: make(map[string]float64),
+	}, nil
+}
+
+// Name identifies the sink in logs.
+func (s *StatsDSink) Name() string {
+	return "statsd"
+}
+
+// Push publishes samples to the StatsD agent.
+func (s *StatsDSink) Push(_ context.Context, samples []Sample) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines []string
+	for _, sample := range samples {
+		tags := statsDTags(sample.Labels)
+		key := sample.Key()
+
+		switch sample.Kind {
+		case KindCounter:
+			delta := s.delta(key, sample.Value)
+			lines = append(lines, statsDLine(sample.Name, delta, "c", tags))
+
+		case KindGauge:
+			lines = append(lines,
+				statsDLine(sample.Name, sample.Value, "g", tags))
+
+		case KindHistogram:
+			count := s.delta(key+"#count", float64(sample.Count))
+			sum := s.delta(key+"#sum", sample.Sum)
+			lines = append(lines,
+				statsDLine(sample.Name+"_count", count, "c", tags),
+				statsDLine(sample.Name+"_sum", sum, "c", tags),
+			)
+		}
+	}
+
+	return s.write(lines)
+}
+
+// Close closes the sink's connection.
+func (s *StatsDSink) Close() error {
+	return s.conn.Close()
+}
+
+// delta returns the increase of the cumulative value of the series key since
+// the previous push. A decrease, i.e. a counter reset, reports the full value.
+//
+// NOTE: This method MUST be called while holding s.mu.
+func (s *StatsDSink) delta(key string, value float64) float64 {
+	prev := s.prev[key]
+	s.prev[key] = value
+
+	if value < prev {
+		return value
+	}
+	return value - prev
+}
+
+// write sends lines to the agent, packing as many lines as fit into each
+// datagram.
+//
+// NOTE: This method MUST be called while holding s.mu.
+func (s *StatsDSink) write(lines []string) error {
+	var packet strings.Builder
+	flush := func() error {
+		if packet.Len() == 0 {
+			return nil
+		}
+		_, err := s.conn.Write([]byte(packet.String()))
+		packet.Reset()
+		return err
+	}
+
+	for _, line := range lines {
+		if packet.Len() > 0 &&
+			packet.Len()+1+len(line) > maxStatsDPacketSize {
+
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if packet.Len() > 0 {
+			packet.WriteString("\n")
+		}
+		packet.WriteString(line)
+	}
+
+	return flush()
+}
+
+// statsDLine formats a single StatsD metric line.
+func statsDLine(name string, value float64, kind, tags string) string {
+	line := name + ":" + strconv.FormatFloat(value, 'f', -1, 64) + "|" + kind
+	if tags != "" {
+		line += "|#" + tags
+	}
+	return line
+}
+
+// statsDTags formats labels as DogStatsD tags, ordered by name.
+func statsDTags(labels map[string]string) string {
+	tags := make([]string, 0, len(labels))
+	for name, value := range labels {
+		tags = append(tags, name+":"+value)
+	}
+	sort.Strings(tags)
+
+	return strings.Join(tags, ",")
+}
@@ -0,0 +1,94 @@
+package telemetry_test
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/telemetry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRegistry returns a registry holding a labeled counter, a gauge and a
+// histogram.
+func newTestRegistry(t *testing.T) (
+	*prometheus.Registry,
+	*prometheus.CounterVec,
+	prometheus.Gauge,
+	prometheus.Histogram,
+) {
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "batches_submitted",
+	}, []string{"tenant"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "balance_eth",
+	})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "batch_size",
+		Buckets: []float64{10, 100},
+	})
+	require.Nil(t, registry.Register(counter))
+	require.Nil(t, registry.Register(gauge))
+	require.Nil(t, registry.Register(histogram))
+
+	return registry, counter, gauge, histogram
+}
+
+// readPacket reads a single datagram from conn.
+func readPacket(t *testing.T, conn net.PacketConn) []string {
+	require.Nil(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+
+	buf := make([]byte, 2048)
+	n, _, err := conn.ReadFrom(buf)
+	require.Nil(t, err)
+
+	return strings.Split(string(buf[:n]), "\n")
+}
+
+// TestStatsDSink asserts that counters and histogram counts are published as
+// the increase since the previous push, with labels as DogStatsD tags.
+func TestStatsDSink(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer conn.Close()
+
+	sink, err := telemetry.NewStatsDSink(conn.LocalAddr().String())
+	require.Nil(t, err)
+	defer sink.Close()
+
+	registry, counter, gauge, histogram := newTestRegistry(t)
+	counter.WithLabelValues("mainnet").Add(3)
+	gauge.Set(1.5)
+	histogram.Observe(50)
+
+	push := func() []string {
+		samples, err := telemetry.Gather(registry)
+		require.Nil(t, err)
+		require.Nil(t, sink.Push(context.Background(), samples))
+		return readPacket(t, conn)
+	}
+
+	require.ElementsMatch(t, []string{
+		"balance_eth:1.5|g",
+		"batch_size_count:1|c",
+		"batch_size_sum:50|c",
+		"batches_submitted:3|c|#tenant:mainnet",
+	}, push())
+
+	counter.WithLabelValues("mainnet").Add(2)
+	histogram.Observe(5)
+
+	require.ElementsMatch(t, []string{
+		"balance_eth:1.5|g",
+		"batch_size_count:1|c",
+		"batch_size_sum:5|c",
+		"batches_submitted:2|c|#tenant:mainnet",
+	}, push())
+}
@@ -0,0 +1,236 @@
+package batchsubmitter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// ErrNoTenants signals that a tenants file was provided but did not
+	// contain any tenant definitions.
+	ErrNoTenants = errors.New("tenants file must define at least one tenant")
+
+	// ErrDuplicateTenantSender signals that two tenants submit from the
+	// same wallet, whose nonces they would contend for.
+	ErrDuplicateTenantSender = errors.New("sender address used by more " +
+		"than one tenant")
+
+	// tenantNameRegex restricts tenant names to characters that are valid
+	// within a Prometheus metric subsystem.
+	tenantNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// TenantsFile is the root-level object parsed from the file referenced by
+// the tenants-file flag.
+type TenantsFile struct {
+	Tenants []TenantConfig `json:"tenants"`
+}
+
+// TenantConfig describes a single rollup tenant. Any field left empty inherits
+// the value configured via flags or environment variables, allowing tenants to
+// only override what differs between them.
+type TenantConfig struct {
+	// Name uniquely identifies the tenant, and is used to namespace logs
+	// and metrics.
+	Name string `json:"name"`
+
+	L1EthRpc            string `json:"l1_eth_rpc"`
+	L2EthRpc            string `json:"l2_eth_rpc"`
+	SecondaryL2EthRpc   string `json:"secondary_l2_eth_rpc"`
+	CTCAddress          string `json:"ctc_address"`
+	SCCAddress          string `json:"scc_address"`
+	SequencerPrivateKey string `json:"sequencer_private_key"`
+	ProposerPrivateKey  string `json:"proposer_private_key"`
+	Mnemonic            string `json:"mnemonic"`
+	SequencerHDPath     string `json:"sequencer_hd_path"`
+	ProposerHDPath      string `json:"proposer_hd_path"`
+
+	// PollInterval and MaxGasPriceInGwei bound the rate at which a tenant
+	// submits batches and the fees it may spend doing so.
+	PollInterval      string `json:"poll_interval"`
+	MaxGasPriceInGwei uint64 `json:"max_gas_price_in_gwei"`
+
+	// The RPC quotas and bursts bound the rate of L2 requests made by the
+	// tenant's drivers, such that one tenant cannot starve the others of
+	// a shared L2 backend.
+	SequencerRPCQuota float64 `json:"sequencer_rpc_quota"`
+	ProposerRPCQuota  float64 `json:"proposer_rpc_quota"`
+	SequencerRPCBurst uint64  `json:"sequencer_rpc_burst"`
+	ProposerRPCBurst  uint64  `json:"proposer_rpc_burst"`
+
+	// MaxFeePerL2TxInGwei and SpendBudgetEther bound what the tenant
+	// spends per L2 tx and in total within a trailing day.
+	MaxFeePerL2TxInGwei uint64  `json:"max_fee_per_l2_tx_in_gwei"`
+	SpendBudgetEther    float64 `json:"spend_budget_ether"`
+
+	// AdminOperators is a comma-separated list of the addresses whose
+	// signatures authorize admin API mutations of this tenant's services
+	// only, in addition to the operators of every tenant.
+	AdminOperators string `json:"admin_operators"`
+}
+
+// LoadTenantConfigs parses the tenants file at path, returning one Config per
+// tenant derived from base. Each returned Config is validated independently.
+func LoadTenantConfigs(base Config, path string) ([]Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file TenantsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	return TenantConfigs(base, file.Tenants)
+}
+
+// TenantConfigs returns one Config per tenant, overlaying each tenant's
+// overrides on top of base.
+func TenantConfigs(base Config, tenants []TenantConfig) ([]Config, error) {
+	if len(tenants) == 0 {
+		return nil, ErrNoTenants
+	}
+
+	seen := make(map[string]struct{})
+	senders := make(map[common.Address]string)
+	cfgs := make([]Config, 0, len(tenants))
+	for _, tenant := range tenants {
+		if !tenantNameRegex.MatchString(tenant.Name) {
+			return nil, fmt.Errorf("invalid tenant name: %q",
+				tenant.Name)
+		}
+		if _, ok := seen[tenant.Name]; ok {
+			return nil, fmt.Errorf("duplicate tenant name: %q",
+				tenant.Name)
+		}
+		seen[tenant.Name] = struct{}{}
+
+		cfg, err := tenant.apply(base)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.Name, err)
+		}
+
+		if err := ValidateConfig(&cfg); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenant.Name, err)
+		}
+
+		for _, sender := range tenantSenders(cfg) {
+			other, ok := senders[sender]
+			if ok && other != tenant.Name {
+				return nil, fmt.Errorf("tenant %s: %w: %s is "+
+					"also used by tenant %s", tenant.Name,
+					ErrDuplicateTenantSender, sender.Hex(),
+					other)
+			}
+			senders[sender] = tenant.Name
+		}
+
+		cfgs = append(cfgs, cfg)
+	}
+
+	return cfgs, nil
+}
+
+// apply returns a copy of base with the tenant's overrides applied.
+func (t TenantConfig) apply(base Config) (Config, error) {
+	cfg := base
+	cfg.TenantName = t.Name
+	cfg.TenantsFile = ""
+
+	overrideString(&cfg.L1EthRpc, t.L1EthRpc)
+	overrideString(&cfg.CTCAddress, t.CTCAddress)
+	overrideString(&cfg.SCCAddress, t.SCCAddress)
+
+	// The L2 providers are overridden as a unit, otherwise a tenant's L2
+	// provider could be paired with a secondary provider of another chain.
+	if t.L2EthRpc != "" || t.SecondaryL2EthRpc != "" {
+		overrideString(&cfg.L2EthRpc, t.L2EthRpc)
+		cfg.SecondaryL2EthRpc = t.SecondaryL2EthRpc
+	}
+
+	// Wallet credentials are overridden as a unit, otherwise a tenant's
+	// private key could be combined with the base mnemonic, which would
+	// fail validation.
+	if t.SequencerPrivateKey != "" || t.ProposerPrivateKey != "" ||
+		t.Mnemonic != "" {
+
+		cfg.SequencerPrivateKey = t.SequencerPrivateKey
+		cfg.ProposerPrivateKey = t.ProposerPrivateKey
+		cfg.Mnemonic = t.Mnemonic
+		cfg.SequencerHDPath = t.SequencerHDPath
+		cfg.ProposerHDPath = t.ProposerHDPath
+	}
+
+	if t.PollInterval != "" {
+		pollInterval, err := time.ParseDuration(t.PollInterval)
+		if err != nil {
+			return Config{}, err
+		}
+		cfg.PollInterval = pollInterval
+	}
+	if t.MaxGasPriceInGwei != 0 {
+		cfg.MaxGasPriceInGwei = t.MaxGasPriceInGwei
+	}
+
+	overrideFloat64(&cfg.SequencerRPCQuota, t.SequencerRPCQuota)
+	overrideFloat64(&cfg.ProposerRPCQuota, t.ProposerRPCQuota)
+	overrideUint64(&cfg.SequencerRPCBurst, t.SequencerRPCBurst)
+	overrideUint64(&cfg.ProposerRPCBurst, t.ProposerRPCBurst)
+	overrideUint64(&cfg.MaxFeePerL2TxInGwei, t.MaxFeePerL2TxInGwei)
+	overrideFloat64(&cfg.SpendBudgetEther, t.SpendBudgetEther)
+	cfg.TenantAdminOperators = t.AdminOperators
+
+	return cfg, nil
+}
+
+// tenantSenders returns the addresses of the wallets from which the tenant
+// described by cfg submits. Wallets whose keys cannot be parsed are omitted,
+// as the tenant fails to start regardless.
+func tenantSenders(cfg Config) []common.Address {
+	var senders []common.Address
+	for _, wallet := range []struct {
+		hdPath, privKey string
+	}{
+		{cfg.SequencerHDPath, cfg.SequencerPrivateKey},
+		{cfg.ProposerHDPath, cfg.ProposerPrivateKey},
+	} {
+		privKey, err := GetConfiguredPrivateKey(
+			cfg.Mnemonic, wallet.hdPath, wallet.privKey,
+		)
+		if err != nil {
+			continue
+		}
+		senders = append(senders, crypto.PubkeyToAddress(privKey.PublicKey))
+	}
+
+	return senders
+}
+
+// overrideString sets *dst to src if src is non-empty.
+func overrideString(dst *string, src string) {
+	if src != "" {
+		*dst = src
+	}
+}
+
+// overrideUint64 sets *dst to src if src is non-zero.
+func overrideUint64(dst *uint64, src uint64) {
+	if src != 0 {
+		*dst = src
+	}
+}
+
+// overrideFloat64 sets *dst to src if src is non-zero.
+func overrideFloat64(dst *float64, src float64) {
+	if src != 0 {
+		*dst = src
+	}
+}
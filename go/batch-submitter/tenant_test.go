@@ -0,0 +1,151 @@
+package batchsubmitter_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTenantConfigs asserts that tenant overrides are layered on top of the
+// base configuration, and that each tenant is validated independently.
+func TestTenantConfigs(t *testing.T) {
+	base := batchsubmitter.Config{
+		LogLevel:          "info",
+		L1EthRpc:          "http://l1",
+		L2EthRpc:          "http://l2",
+		SecondaryL2EthRpc: "http://l2-secondary",
+		PollInterval:      time.Second,
+		MaxGasPriceInGwei: 100,
+		Mnemonic:          "mnemonic",
+		SequencerHDPath:   "sequencer-path",
+		ProposerHDPath:    "proposer-path",
+		TenantsFile:       "tenants.json",
+	}
+
+	cfgs, err := batchsubmitter.TenantConfigs(base, []batchsubmitter.TenantConfig{
+		{
+			Name:     "alpha",
+			L2EthRpc: "http://alpha-l2",
+		},
+		{
+			Name:                "beta",
+			SequencerPrivateKey: "sequencer-privkey",
+			ProposerPrivateKey:  "proposer-privkey",
+			PollInterval:        "5s",
+			MaxGasPriceInGwei:   20,
+			SequencerRPCQuota:   50,
+			ProposerRPCBurst:    10,
+			MaxFeePerL2TxInGwei: 1000,
+			SpendBudgetEther:    0.5,
+			AdminOperators:      "0x000000000000000000000000000000000000bE7a",
+		},
+	})
+	require.Nil(t, err)
+	require.Len(t, cfgs, 2)
+
+	alpha := cfgs[0]
+	require.Equal(t, "alpha", alpha.TenantName)
+	require.Equal(t, "", alpha.TenantsFile)
+	require.Equal(t, "http://l1", alpha.L1EthRpc)
+	require.Equal(t, "http://alpha-l2", alpha.L2EthRpc)
+	require.Equal(t, "", alpha.SecondaryL2EthRpc)
+	require.Equal(t, "mnemonic", alpha.Mnemonic)
+
+	// Overriding the L2 provider or any wallet credential replaces the
+	// inherited ones.
+	beta := cfgs[1]
+	require.Equal(t, "beta", beta.TenantName)
+	require.Equal(t, "http://l2", beta.L2EthRpc)
+	require.Equal(t, "http://l2-secondary", beta.SecondaryL2EthRpc)
+	require.Equal(t, "", beta.Mnemonic)
+	require.Equal(t, "sequencer-privkey", beta.SequencerPrivateKey)
+	require.Equal(t, 5*time.Second, beta.PollInterval)
+	require.Equal(t, uint64(20), beta.MaxGasPriceInGwei)
+
+	// Rate and spend budgets are overridden per tenant, and admin
+	// operators are scoped to the tenant.
+	require.Equal(t, float64(50), beta.SequencerRPCQuota)
+	require.Equal(t, uint64(10), beta.ProposerRPCBurst)
+	require.Equal(t, uint64(1000), beta.MaxFeePerL2TxInGwei)
+	require.Equal(t, 0.5, beta.SpendBudgetEther)
+	require.Equal(t, "0x000000000000000000000000000000000000bE7a",
+		beta.TenantAdminOperators)
+	require.Equal(t, float64(0), alpha.SequencerRPCQuota)
+	require.Equal(t, 0.0, alpha.SpendBudgetEther)
+	require.Equal(t, "", alpha.TenantAdminOperators)
+}
+
+// TestTenantConfigsDuplicateSender asserts that tenants submitting from the
+// same wallet are rejected.
+func TestTenantConfigsDuplicateSender(t *testing.T) {
+	const (
+		keyA = "4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+		keyB = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+		keyC = "59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d"
+		keyD = "5de4111afa1a4b94908f83103eb1f1706367c2e68ca870fc3fb9a804cdab365a"
+	)
+	base := batchsubmitter.Config{
+		LogLevel: "info",
+	}
+
+	_, err := batchsubmitter.TenantConfigs(base, []batchsubmitter.TenantConfig{
+		{
+			Name:                "alpha",
+			SequencerPrivateKey: keyA,
+			ProposerPrivateKey:  keyB,
+		},
+		{
+			Name:                "beta",
+			SequencerPrivateKey: keyC,
+			ProposerPrivateKey:  keyD,
+		},
+	})
+	require.Nil(t, err)
+
+	_, err = batchsubmitter.TenantConfigs(base, []batchsubmitter.TenantConfig{
+		{
+			Name:                "alpha",
+			SequencerPrivateKey: keyA,
+			ProposerPrivateKey:  keyB,
+		},
+		{
+			Name:                "beta",
+			SequencerPrivateKey: keyC,
+			ProposerPrivateKey:  keyA,
+		},
+	})
+	require.True(t, errors.Is(err, batchsubmitter.ErrDuplicateTenantSender))
+}
+
+// TestTenantConfigsInvalid asserts that malformed tenant definitions are
+// rejected.
+func TestTenantConfigsInvalid(t *testing.T) {
+	base := batchsubmitter.Config{
+		LogLevel:            "info",
+		SequencerPrivateKey: "sequencer-privkey",
+		ProposerPrivateKey:  "proposer-privkey",
+	}
+
+	_, err := batchsubmitter.TenantConfigs(base, nil)
+	require.Equal(t, batchsubmitter.ErrNoTenants, err)
+
+	_, err = batchsubmitter.TenantConfigs(base, []batchsubmitter.TenantConfig{
+		{Name: "bad-name"},
+	})
+	require.NotNil(t, err)
+
+	_, err = batchsubmitter.TenantConfigs(base, []batchsubmitter.TenantConfig{
+		{Name: "alpha"},
+		{Name: "alpha"},
+	})
+	require.NotNil(t, err)
+
+	_, err = batchsubmitter.TenantConfigs(base, []batchsubmitter.TenantConfig{
+		{Name: "alpha", Mnemonic: "mnemonic"},
+	})
+	require.Equal(t, batchsubmitter.ErrSequencerPrivKeyOrMnemonic,
+		errors.Unwrap(err))
+}
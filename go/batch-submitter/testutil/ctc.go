@@ -0,0 +1,257 @@
+package testutil
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/bindings/ctc"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// appendSequencerBatchMethodName is the name of the CTC method
+	// appending a sequencer batch, which is called with custom-encoded
+	// calldata rather than ABI-encoded arguments.
+	appendSequencerBatchMethodName = "appendSequencerBatch"
+
+	// sequencerBatchAppendedEventName is the name of the CTC event emitted
+	// for each appended sequencer batch.
+	sequencerBatchAppendedEventName = "SequencerBatchAppended"
+)
+
+// revertSelector is the selector of the Error(string) revert reason.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// QueueElement is an L1 to L2 tx enqueued in the CTC, awaiting inclusion in a
+// sequencer batch.
+type QueueElement struct {
+	TransactionHash common.Hash
+	Timestamp       uint64
+	BlockNumber     uint64
+}
+
+// AppendSequencerBatchCallData returns the calldata of an appendSequencerBatch
+// call appending params, including the 4-byte method selector.
+func AppendSequencerBatchCallData(
+	params *sequencer.AppendSequencerBatchParams) ([]byte, error) {
+
+	ctcABI, err := ctc.CanonicalTransactionChainMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	batchArguments, err := params.Serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	methodID := ctcABI.Methods[appendSequencerBatchMethodName].ID
+	return append(append([]byte{}, methodID...), batchArguments...), nil
+}
+
+// revertError is returned for calls and txs reverted by the fake CTC, carrying
+// the ABI-encoded revert reason as its data like a geth node would.
+type revertError struct {
+	reason string
+}
+
+func (e *revertError) Error() string {
+	return "execution reverted: " + e.reason
+}
+
+// ErrorCode returns the JSON-RPC error code geth returns for reverts.
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex-encoded Error(string) revert reason.
+func (e *revertError) ErrorData() interface{} {
+	stringTy, _ := abi.NewType("string", "", nil)
+	data, _ := abi.Arguments{{Type: stringTy}}.Pack(e.reason)
+	return hexutil.Encode(append(append([]byte{}, revertSelector...), data...))
+}
+
+// ctcState is the state of the fake CTC.
+type ctcState struct {
+	abi *abi.ABI
+
+	totalElements   uint64
+	nextQueueIndex  uint64
+	lastTimestamp   uint64
+	lastBlockNumber uint64
+	queue           []QueueElement
+	batches         []*sequencer.BatchInspection
+}
+
+// newCTCState returns the state of a freshly deployed CTC.
+func newCTCState() (*ctcState, error) {
+	ctcABI, err := ctc.CanonicalTransactionChainMetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ctcState{abi: ctcABI}, nil
+}
+
+// call executes a call of the CTC with callData, returning the ABI-encoded
+// result of a view method. A call of appendSequencerBatch only validates the
+// batch, returning no result unless it reverts.
+func (c *ctcState) call(callData []byte) ([]byte, error) {
+	if len(callData) < 4 {
+		return nil, &revertError{reason: "missing method selector"}
+	}
+	method, err := c.abi.MethodById(callData[:4])
+	if err != nil {
+		return nil, &revertError{reason: "unknown method selector"}
+	}
+
+	switch method.Name {
+	case appendSequencerBatchMethodName:
+		_, err := c.validateBatch(callData)
+		return nil, err
+
+	case "getQueueElement":
+		args, err := method.Inputs.Unpack(callData[4:])
+		if err != nil {
+			return nil, &revertError{reason: err.Error()}
+		}
+		index := args[0].(*big.Int)
+		if !index.IsUint64() || index.Uint64() >= uint64(len(c.queue)) {
+			return nil, &revertError{reason: "Index out of bounds."}
+		}
+		element := c.queue[index.Uint64()]
+		return method.Outputs.Pack(ctc.Lib_OVMCodecQueueElement{
+			TransactionHash: element.TransactionHash,
+			Timestamp:       new(big.Int).SetUint64(element.Timestamp),
+			BlockNumber:     new(big.Int).SetUint64(element.BlockNumber),
+		})
+	}
+
+	var result uint64
+	switch method.Name {
+	case "getTotalElements":
+		result = c.totalElements
+	case "getTotalBatches":
+		result = uint64(len(c.batches))
+	case "getNextQueueIndex":
+		result = c.nextQueueIndex
+	case "getQueueLength":
+		result = uint64(len(c.queue))
+	case "getNumPendingQueueElements":
+		result = uint64(len(c.queue)) - c.nextQueueIndex
+	case "getLastTimestamp":
+		result = c.lastTimestamp
+	case "getLastBlockNumber":
+		result = c.lastBlockNumber
+	default:
+		return nil, fmt.Errorf("fake CTC does not implement %s",
+			method.Name)
+	}
+
+	return method.Outputs.Pack(new(big.Int).SetUint64(result))
+}
+
+// transact executes a tx calling the CTC with callData, returning the events
+// it emits, or an error if it reverts. Only appendSequencerBatch changes the
+// state of the CTC.
+func (c *ctcState) transact(callData []byte) ([]*types.Log, error) {
+	methodID := c.abi.Methods[appendSequencerBatchMethodName].ID
+	if len(callData) < 4 || !bytes.Equal(callData[:4], methodID) {
+		_, err := c.call(callData)
+		return nil, err
+	}
+
+	batch, err := c.validateBatch(callData)
+	if err != nil {
+		return nil, err
+	}
+	event, err := c.appendBatch(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	return []*types.Log{event}, nil
+}
+
+// validateBatch decodes the calldata of an appendSequencerBatch call, and
+// checks that the batch begins at the CTC's height, only appends queue
+// elements that have been enqueued, and that its contexts do not go back in
+// time, mirroring the checks of the CanonicalTransactionChain.
+func (c *ctcState) validateBatch(
+	callData []byte) (*sequencer.BatchInspection, error) {
+
+	batch, err := sequencer.InspectBatch(callData, 0)
+	if err != nil {
+		return nil, &revertError{reason: err.Error()}
+	}
+
+	switch {
+	case batch.ShouldStartAtElement != c.totalElements:
+		return nil, &revertError{reason: "Actual batch start index " +
+			"does not match expected start index."}
+
+	case batch.TotalElementsToAppend == 0:
+		return nil, &revertError{reason: "Must append at least " +
+			"one element."}
+
+	case batch.NumSequencedTxs+batch.NumQueuedTxs !=
+		batch.TotalElementsToAppend:
+		return nil, &revertError{reason: "Actual transaction index " +
+			"does not match expected total elements to append."}
+
+	case c.nextQueueIndex+batch.NumQueuedTxs > uint64(len(c.queue)):
+		return nil, &revertError{reason: "Attempted to append more " +
+			"elements than are available in the queue."}
+	}
+
+	timestamp, blockNumber := c.lastTimestamp, c.lastBlockNumber
+	for _, context := range batch.Contexts {
+		if context.Timestamp < timestamp {
+			return nil, &revertError{reason: "Context timestamp " +
+				"values must monotonically increase."}
+		}
+		if context.BlockNumber < blockNumber {
+			return nil, &revertError{reason: "Context blockNumber " +
+				"values must monotonically increase."}
+		}
+		timestamp, blockNumber = context.Timestamp, context.BlockNumber
+	}
+
+	return batch, nil
+}
+
+// appendBatch appends a batch validated by validateBatch, returning the
+// SequencerBatchAppended event it emits.
+func (c *ctcState) appendBatch(
+	batch *sequencer.BatchInspection) (*types.Log, error) {
+
+	totalElements := c.totalElements + batch.TotalElementsToAppend
+	event := c.abi.Events[sequencerBatchAppendedEventName]
+	data, err := event.Inputs.NonIndexed().Pack(
+		new(big.Int).SetUint64(c.nextQueueIndex),
+		new(big.Int).SetUint64(batch.NumQueuedTxs),
+		new(big.Int).SetUint64(totalElements),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	c.totalElements = totalElements
+	c.nextQueueIndex += batch.NumQueuedTxs
+	if len(batch.Contexts) > 0 {
+		last := batch.Contexts[len(batch.Contexts)-1]
+		c.lastTimestamp = last.Timestamp
+		c.lastBlockNumber = last.BlockNumber
+	}
+	c.batches = append(c.batches, batch)
+
+	return &types.Log{
+		Topics: []common.Hash{event.ID},
+		Data:   data,
+	}, nil
+}
@@ -0,0 +1,626 @@
+// Package testutil provides in-process fakes of the L1 and L2 chains the batch
+// submitter talks to, and helpers to drive a Service one cycle at a time, such
+// that custom drivers can be integration tested without a full devnet.
+package testutil
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// blockGasLimit is the gas limit of each block.
+	blockGasLimit = 30_000_000
+
+	// genesisTime is the timestamp of the genesis block, each subsequent
+	// block being a second later than its parent.
+	genesisTime = 1_600_000_000
+
+	// txGas and txDataGas are the intrinsic gas of a tx, and of each byte
+	// of its calldata, returned as gas estimates.
+	txGas     = params.TxGas
+	txDataGas = params.TxDataNonZeroGasEIP2028
+)
+
+var (
+	// ErrReplacementUnderpriced signals a tx replacing another pending tx
+	// at the same nonce without bumping its gas price by at least 10%,
+	// mirroring the rule enforced by geth's tx pool.
+	ErrReplacementUnderpriced = errors.New("replacement transaction " +
+		"underpriced")
+
+	// ErrNonceTooLow signals a tx whose nonce has already been mined.
+	ErrNonceTooLow = errors.New("nonce too low")
+
+	// ErrNotCTCCall signals a call to an address other than the CTC.
+	ErrNotCTCCall = errors.New("fake L1 only serves calls to the CTC")
+
+	// DefaultBalance is the balance of every account until set otherwise.
+	DefaultBalance = new(big.Int).Mul(
+		big.NewInt(1000), big.NewInt(params.Ether),
+	)
+
+	// SuggestedGasPrice is the gas price suggested by the L1.
+	SuggestedGasPrice = big.NewInt(params.GWei)
+
+	// ctcCode is the code reported at the address of the CTC, such that
+	// bindings find a contract deployed there.
+	ctcCode = []byte{0x00}
+)
+
+// L1 is an in-process L1 chain served over JSON-RPC, such that services use
+// the same ethclient.Client they would against a real node, with a fake
+// CanonicalTransactionChain deployed at CTCAddr. The CTC decodes each
+// appendSequencerBatch tx and reverts those a real CTC would reject, e.g. a
+// batch not beginning at its total elements, emitting a SequencerBatchAppended
+// event for each batch it appends.
+//
+// Every published tx is mined immediately in a block of its own, such that a
+// Service progresses deterministically. Blocks may be held to leave txs
+// pending until Mine is called.
+//
+// NOTE: For simplicity, gas is never charged, and contexts are not checked
+// against the L1 block including their batch.
+type L1 struct {
+	chainID *big.Int
+	signer  types.Signer
+	ctcAddr common.Address
+
+	server *rpc.Server
+	client *ethclient.Client
+
+	mu       sync.Mutex
+	headers  []*types.Header
+	balances map[common.Address]*big.Int
+	nonces   map[common.Address]uint64
+	pool     map[common.Address]map[uint64]*types.Transaction
+	txs      map[common.Hash]*types.Transaction
+	receipts map[common.Hash]*types.Receipt
+	logs     []*types.Log
+	reverted []common.Hash
+	ctc      *ctcState
+	held     bool
+}
+
+// NewL1 starts an L1 chain with the given chain ID, with the CTC deployed at
+// ctcAddr.
+func NewL1(chainID *big.Int, ctcAddr common.Address) (*L1, error) {
+	ctc, err := newCTCState()
+	if err != nil {
+		return nil, err
+	}
+
+	l1 := &L1{
+		chainID: chainID,
+		signer:  types.LatestSignerForChainID(chainID),
+		ctcAddr: ctcAddr,
+		server:  rpc.NewServer(),
+		headers: []*types.Header{{
+			Number:     new(big.Int),
+			Difficulty: new(big.Int),
+			GasLimit:   blockGasLimit,
+			Time:       genesisTime,
+		}},
+		balances: make(map[common.Address]*big.Int),
+		nonces:   make(map[common.Address]uint64),
+		pool:     make(map[common.Address]map[uint64]*types.Transaction),
+		txs:      make(map[common.Hash]*types.Transaction),
+		receipts: make(map[common.Hash]*types.Receipt),
+		ctc:      ctc,
+	}
+
+	if err := l1.server.RegisterName("eth", &l1API{l1: l1}); err != nil {
+		return nil, err
+	}
+	l1.client = ethclient.NewClient(rpc.DialInProc(l1.server))
+
+	return l1, nil
+}
+
+// Client returns a client of the chain's JSON-RPC API.
+func (l *L1) Client() *ethclient.Client {
+	return l.client
+}
+
+// ChainID returns the chain ID of the chain.
+func (l *L1) ChainID() *big.Int {
+	return new(big.Int).Set(l.chainID)
+}
+
+// CTCAddr returns the address of the CTC.
+func (l *L1) CTCAddr() common.Address {
+	return l.ctcAddr
+}
+
+// Close closes the JSON-RPC server.
+func (l *L1) Close() {
+	l.client.Close()
+	l.server.Stop()
+}
+
+// SetBalance sets the balance of account.
+func (l *L1) SetBalance(account common.Address, balance *big.Int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.balances[account] = new(big.Int).Set(balance)
+}
+
+// HoldBlocks stops mining published txs while held is true, such that they
+// remain pending until Mine is called or blocks are released.
+func (l *L1) HoldBlocks(held bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.held = held
+}
+
+// Mine mines a block including every executable pending tx, whether or not
+// blocks are held. If no tx is pending, an empty block is mined, e.g. to
+// confirm the txs mined before it.
+func (l *L1) Mine() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.mineLocked()
+}
+
+// Enqueue appends an L1 to L2 tx to the CTC's queue.
+func (l *L1) Enqueue(element QueueElement) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.ctc.queue = append(l.ctc.queue, element)
+}
+
+// TotalElements returns the number of elements appended to the CTC.
+func (l *L1) TotalElements() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.ctc.totalElements
+}
+
+// NextQueueIndex returns the index of the next queue element to be appended
+// to the CTC.
+func (l *L1) NextQueueIndex() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.ctc.nextQueueIndex
+}
+
+// Batches returns the sequencer batches appended to the CTC, in order.
+func (l *L1) Batches() []*sequencer.BatchInspection {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]*sequencer.BatchInspection(nil), l.ctc.batches...)
+}
+
+// Reverted returns the hashes of the mined txs reverted by the CTC.
+func (l *L1) Reverted() []common.Hash {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]common.Hash(nil), l.reverted...)
+}
+
+// PendingTxs returns the txs from account pending in the tx pool, by
+// ascending nonce.
+func (l *L1) PendingTxs(account common.Address) []*types.Transaction {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var txs []*types.Transaction
+	for _, tx := range l.pool[account] {
+		txs = append(txs, tx)
+	}
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].Nonce() < txs[j].Nonce()
+	})
+
+	return txs
+}
+
+// mineLocked mines a block including, for each account, the pending txs at
+// consecutive nonces beginning at its next nonce.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *L1) mineLocked() {
+	parent := l.headers[len(l.headers)-1]
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: new(big.Int),
+		GasLimit:   blockGasLimit,
+		Time:       parent.Time + 1,
+	}
+
+	senders := make([]common.Address, 0, len(l.pool))
+	for sender := range l.pool {
+		senders = append(senders, sender)
+	}
+	sort.Slice(senders, func(i, j int) bool {
+		return bytes.Compare(senders[i][:], senders[j][:]) < 0
+	})
+
+	var included []*types.Transaction
+	for _, sender := range senders {
+		for {
+			tx, ok := l.pool[sender][l.nonces[sender]]
+			if !ok {
+				break
+			}
+			delete(l.pool[sender], tx.Nonce())
+			l.nonces[sender]++
+			included = append(included, tx)
+		}
+		if len(l.pool[sender]) == 0 {
+			delete(l.pool, sender)
+		}
+	}
+
+	var (
+		receipts []*types.Receipt
+		logs     []*types.Log
+	)
+	for i, tx := range included {
+		status := types.ReceiptStatusSuccessful
+		txLogs, ok := l.executeLocked(tx)
+		if !ok {
+			status = types.ReceiptStatusFailed
+			l.reverted = append(l.reverted, tx.Hash())
+		}
+		for _, log := range txLogs {
+			log.Address = l.ctcAddr
+			log.BlockNumber = header.Number.Uint64()
+			log.TxHash = tx.Hash()
+			log.TxIndex = uint(i)
+			log.Index = uint(len(logs))
+			logs = append(logs, log)
+		}
+
+		header.GasUsed += tx.Gas()
+		receipts = append(receipts, &types.Receipt{
+			Type:              tx.Type(),
+			Status:            status,
+			CumulativeGasUsed: header.GasUsed,
+			Logs:              append([]*types.Log{}, txLogs...),
+			TxHash:            tx.Hash(),
+			GasUsed:           tx.Gas(),
+			BlockNumber:       header.Number,
+			TransactionIndex:  uint(i),
+		})
+		l.txs[tx.Hash()] = tx
+	}
+
+	// The block hash covers every header field, so it is only known once
+	// the gas used by the included txs has been set.
+	blockHash := header.Hash()
+	for _, receipt := range receipts {
+		receipt.BlockHash = blockHash
+		l.receipts[receipt.TxHash] = receipt
+	}
+	for _, log := range logs {
+		log.BlockHash = blockHash
+	}
+	l.logs = append(l.logs, logs...)
+	l.headers = append(l.headers, header)
+}
+
+// executeLocked executes tx against the CTC, returning the events it emits,
+// or false if it reverts. Txs to any other address always succeed.
+//
+// NOTE: This method MUST be called while holding l.mu.
+func (l *L1) executeLocked(tx *types.Transaction) ([]*types.Log, bool) {
+	if to := tx.To(); to == nil || *to != l.ctcAddr {
+		return nil, true
+	}
+
+	logs, err := l.ctc.transact(tx.Data())
+	return logs, err == nil
+}
+
+// sendTransaction adds tx to the tx pool, replacing any pending tx at the
+// same nonce that it outbids by at least 10%, and mines it unless blocks are
+// held.
+func (l *L1) sendTransaction(tx *types.Transaction) error {
+	sender, err := types.Sender(l.signer, tx)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if tx.Nonce() < l.nonces[sender] {
+		return ErrNonceTooLow
+	}
+
+	if l.pool[sender] == nil {
+		l.pool[sender] = make(map[uint64]*types.Transaction)
+	}
+	if old, ok := l.pool[sender][tx.Nonce()]; ok {
+		minGasPrice := new(big.Int).Mul(old.GasPrice(), big.NewInt(110))
+		minGasPrice.Div(minGasPrice, big.NewInt(100))
+		if tx.GasPrice().Cmp(minGasPrice) < 0 {
+			return ErrReplacementUnderpriced
+		}
+	}
+	l.pool[sender][tx.Nonce()] = tx
+
+	if !l.held {
+		l.mineLocked()
+	}
+
+	return nil
+}
+
+// call executes a call against the latest state of the CTC.
+func (l *L1) call(to *common.Address, data []byte) ([]byte, error) {
+	if to == nil || *to != l.ctcAddr {
+		return nil, ErrNotCTCCall
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.ctc.call(data)
+}
+
+// callArgs are the arguments of eth_call and eth_estimateGas.
+type callArgs struct {
+	From *common.Address `json:"from"`
+	To   *common.Address `json:"to"`
+	Data hexutil.Bytes   `json:"data"`
+}
+
+// filterArgs are the arguments of eth_getLogs.
+type filterArgs struct {
+	FromBlock *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock   *rpc.BlockNumber `json:"toBlock"`
+	Addresses []common.Address `json:"address"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+// matches returns true if log was emitted within the block range by one of
+// the addresses, with topics matching each position of the filter.
+func (args *filterArgs) matches(log *types.Log, from, to uint64) bool {
+	if log.BlockNumber < from || log.BlockNumber > to {
+		return false
+	}
+	if len(args.Addresses) > 0 &&
+		!containsAddress(args.Addresses, log.Address) {
+
+		return false
+	}
+	for i, topics := range args.Topics {
+		if len(topics) == 0 {
+			continue
+		}
+		if i >= len(log.Topics) || !containsHash(topics, log.Topics[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsAddress returns true if addrs contains addr.
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// containsHash returns true if hashes contains hash.
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
+
+// l1API serves the subset of the eth namespace used by the batch submitter.
+type l1API struct {
+	l1 *L1
+}
+
+// ChainId returns the chain ID.
+func (api *l1API) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(api.l1.chainID)
+}
+
+// BlockNumber returns the number of the latest block.
+func (api *l1API) BlockNumber() hexutil.Uint64 {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	return hexutil.Uint64(len(api.l1.headers) - 1)
+}
+
+// GetBlockByNumber returns the header of the block at number, or nil if it
+// has not been mined. Txs are never included in the response.
+func (api *l1API) GetBlockByNumber(
+	number rpc.BlockNumber, _ bool) *types.Header {
+
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	headers := api.l1.headers
+	if number < 0 {
+		return headers[len(headers)-1]
+	}
+	if int(number) >= len(headers) {
+		return nil
+	}
+
+	return headers[number]
+}
+
+// GetBalance returns the balance of account, which is the same at every
+// block.
+func (api *l1API) GetBalance(
+	account common.Address, _ rpc.BlockNumber) *hexutil.Big {
+
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	if balance, ok := api.l1.balances[account]; ok {
+		return (*hexutil.Big)(new(big.Int).Set(balance))
+	}
+
+	return (*hexutil.Big)(DefaultBalance)
+}
+
+// GetTransactionCount returns the next nonce of account, including the txs
+// executable from the tx pool for the pending block.
+func (api *l1API) GetTransactionCount(
+	account common.Address, number rpc.BlockNumber) hexutil.Uint64 {
+
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	nonce := api.l1.nonces[account]
+	if number == rpc.PendingBlockNumber {
+		for {
+			if _, ok := api.l1.pool[account][nonce]; !ok {
+				break
+			}
+			nonce++
+		}
+	}
+
+	return hexutil.Uint64(nonce)
+}
+
+// GetCode returns the code deployed at account, which is only non-empty for
+// the CTC.
+func (api *l1API) GetCode(
+	account common.Address, _ rpc.BlockNumber) hexutil.Bytes {
+
+	if account == api.l1.ctcAddr {
+		return ctcCode
+	}
+
+	return nil
+}
+
+// GasPrice returns the suggested gas price.
+func (api *l1API) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(SuggestedGasPrice)
+}
+
+// Call executes a call against the CTC.
+func (api *l1API) Call(
+	args callArgs, _ rpc.BlockNumber) (hexutil.Bytes, error) {
+
+	return api.l1.call(args.To, args.Data)
+}
+
+// EstimateGas returns the intrinsic gas of a tx with the given calldata, or
+// an error if the tx would revert.
+func (api *l1API) EstimateGas(args callArgs) (hexutil.Uint64, error) {
+	if args.To != nil && *args.To == api.l1.ctcAddr {
+		if _, err := api.l1.call(args.To, args.Data); err != nil {
+			return 0, err
+		}
+	}
+
+	return hexutil.Uint64(txGas + txDataGas*uint64(len(args.Data))), nil
+}
+
+// SendRawTransaction adds the encoded tx to the tx pool.
+func (api *l1API) SendRawTransaction(
+	input hexutil.Bytes) (common.Hash, error) {
+
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(input); err != nil {
+		return common.Hash{}, err
+	}
+	if err := api.l1.sendTransaction(tx); err != nil {
+		return common.Hash{}, err
+	}
+
+	return tx.Hash(), nil
+}
+
+// GetTransactionByHash returns the mined tx, along with the block including
+// it, or nil if it has not been mined.
+func (api *l1API) GetTransactionByHash(
+	hash common.Hash) (map[string]interface{}, error) {
+
+	api.l1.mu.Lock()
+	tx, ok := api.l1.txs[hash]
+	receipt := api.l1.receipts[hash]
+	api.l1.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+
+	fields, err := marshalFields(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err := types.Sender(api.l1.signer, tx)
+	if err != nil {
+		return nil, err
+	}
+	fields["from"] = sender
+	fields["blockHash"] = receipt.BlockHash
+	fields["blockNumber"] = (*hexutil.Big)(receipt.BlockNumber)
+	fields["transactionIndex"] = hexutil.Uint64(receipt.TransactionIndex)
+
+	return fields, nil
+}
+
+// GetTransactionReceipt returns the receipt of the tx, or nil if it has not
+// been mined.
+func (api *l1API) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	return api.l1.receipts[hash]
+}
+
+// GetLogs returns the events emitted by the CTC matching the filter.
+func (api *l1API) GetLogs(args filterArgs) []*types.Log {
+	api.l1.mu.Lock()
+	defer api.l1.mu.Unlock()
+
+	head := uint64(len(api.l1.headers) - 1)
+	from, to := uint64(0), head
+	if args.FromBlock != nil && *args.FromBlock >= 0 {
+		from = uint64(*args.FromBlock)
+	}
+	if args.ToBlock != nil && *args.ToBlock >= 0 {
+		to = uint64(*args.ToBlock)
+	}
+
+	logs := []*types.Log{}
+	for _, log := range api.l1.logs {
+		if args.matches(log, from, to) {
+			logs = append(logs, log)
+		}
+	}
+
+	return logs
+}
@@ -0,0 +1,317 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/l2client"
+	l2common "github.com/ethereum-optimism/optimism/l2geth/common"
+	l2hexutil "github.com/ethereum-optimism/optimism/l2geth/common/hexutil"
+	l2types "github.com/ethereum-optimism/optimism/l2geth/core/types"
+	l2rpc "github.com/ethereum-optimism/optimism/l2geth/rpc"
+)
+
+// l2TxGasLimit is the gas limit of the txs built by SequencerTx.
+const l2TxGasLimit = 21_000
+
+// L2 is an in-process L2 chain served over JSON-RPC, such that drivers use the
+// same l2client.Client they would against a real node. It serves canned
+// blocks holding a single tx each, as produced by the sequencer, on top of an
+// empty genesis block, in the flavor of RPC of either l2geth or op-geth.
+type L2 struct {
+	flavor    l2client.Flavor
+	server    *l2rpc.Server
+	rpcClient *l2rpc.Client
+	client    l2client.Client
+
+	mu     sync.Mutex
+	blocks []*l2types.Block
+	pruned uint64
+}
+
+// NewL2 starts an L2 chain holding only its genesis block, served as by an
+// l2geth node.
+func NewL2() (*L2, error) {
+	return newL2(l2client.FlavorL2Geth)
+}
+
+// NewOpGethL2 starts an L2 chain holding only its genesis block, served as by
+// an op-geth style node.
+func NewOpGethL2() (*L2, error) {
+	return newL2(l2client.FlavorOpGeth)
+}
+
+// newL2 starts an L2 chain holding only its genesis block, served in the given
+// flavor of RPC.
+func newL2(flavor l2client.Flavor) (*L2, error) {
+	l2 := &L2{
+		flavor: flavor,
+		server: l2rpc.NewServer(),
+		blocks: []*l2types.Block{
+			l2types.NewBlock(&l2types.Header{
+				Number:     new(big.Int),
+				Difficulty: new(big.Int),
+			}, nil, nil, nil),
+		},
+	}
+
+	if err := l2.server.RegisterName("eth", &l2API{l2: l2}); err != nil {
+		return nil, err
+	}
+	l2.rpcClient = l2rpc.DialInProc(l2.server)
+
+	client, _, err := l2client.New(
+		context.Background(), l2.rpcClient, flavor,
+	)
+	if err != nil {
+		return nil, err
+	}
+	l2.client = client
+
+	return l2, nil
+}
+
+// Client returns a client of the chain's JSON-RPC API, in the flavor served.
+func (l *L2) Client() l2client.Client {
+	return l.client
+}
+
+// RPCClient returns the raw JSON-RPC client of the chain.
+func (l *L2) RPCClient() *l2rpc.Client {
+	return l.rpcClient
+}
+
+// Close closes the JSON-RPC server.
+func (l *L2) Close() {
+	l.rpcClient.Close()
+	l.server.Stop()
+}
+
+// Height returns the number of the latest block.
+func (l *L2) Height() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return uint64(len(l.blocks) - 1)
+}
+
+// Block returns the block at number, or nil if there is none.
+func (l *L2) Block(number uint64) *l2types.Block {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if number >= uint64(len(l.blocks)) {
+		return nil
+	}
+
+	return l.blocks[number]
+}
+
+// AddBlock appends a block holding tx at the given timestamp, returning the
+// new block.
+func (l *L2) AddBlock(timestamp uint64, tx *l2types.Transaction) *l2types.Block {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	parent := l.blocks[len(l.blocks)-1]
+	block := l2types.NewBlock(&l2types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), l2common.Big1),
+		Difficulty: new(big.Int),
+		Time:       timestamp,
+	}, []*l2types.Transaction{tx}, nil, nil)
+	l.blocks = append(l.blocks, block)
+
+	return block
+}
+
+// Rewind drops every block from number onwards, such that different blocks
+// may be added in their place, as if the chain were reorged.
+func (l *L2) Rewind(number uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if number == 0 {
+		number = 1
+	}
+	if number < uint64(len(l.blocks)) {
+		l.blocks = l.blocks[:number]
+	}
+}
+
+// Prune makes every block before number unavailable, as if the node were
+// started from a snapshot without an archive of earlier blocks. The latest
+// block always remains available.
+func (l *L2) Prune(number uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.pruned = number
+}
+
+// SequencerTx returns an unsigned sequencer tx at nonce, sequenced at the L1
+// block l1BlockNumber.
+func SequencerTx(nonce, l1BlockNumber uint64) *l2types.Transaction {
+	tx := l2types.NewTransaction(
+		nonce, l2common.Address{}, new(big.Int), l2TxGasLimit,
+		new(big.Int), []byte{0x01},
+	)
+	tx.SetL1BlockNumber(l1BlockNumber)
+
+	return tx
+}
+
+// QueueTx returns the L2 tx executing the queue element at queueIndex.
+func QueueTx(queueIndex uint64, element QueueElement) *l2types.Transaction {
+	tx := l2types.NewTransaction(
+		0, l2common.Address{}, new(big.Int), l2TxGasLimit,
+		new(big.Int), nil,
+	)
+	tx.SetTransactionMeta(l2types.NewTransactionMeta(
+		new(big.Int).SetUint64(element.BlockNumber), element.Timestamp,
+		&l2common.Address{}, l2types.QueueOriginL1ToL2, nil,
+		&queueIndex, nil,
+	))
+
+	return tx
+}
+
+// l2API serves the subset of the eth namespace used by the batch submitter.
+type l2API struct {
+	l2 *L2
+}
+
+// GetBlockByNumber returns the block at number, or nil if there is none.
+func (api *l2API) GetBlockByNumber(
+	number l2rpc.BlockNumber,
+	fullTx bool,
+) (map[string]interface{}, error) {
+
+	api.l2.mu.Lock()
+	blocks, pruned := api.l2.blocks, api.l2.pruned
+	api.l2.mu.Unlock()
+
+	var block *l2types.Block
+	switch {
+	case number < 0:
+		block = blocks[len(blocks)-1]
+	case uint64(number) < pruned:
+		return nil, nil
+	case int(number) < len(blocks):
+		block = blocks[number]
+	default:
+		return nil, nil
+	}
+
+	if api.l2.flavor == l2client.FlavorOpGeth {
+		return marshalOpGethBlock(block, fullTx)
+	}
+	return marshalL2Block(block, fullTx)
+}
+
+// marshalL2Block returns the JSON-RPC representation of block, including the
+// L1 metadata of each tx if fullTx is true, or only their hashes otherwise.
+func marshalL2Block(
+	block *l2types.Block,
+	fullTx bool,
+) (map[string]interface{}, error) {
+
+	fields, err := marshalFields(block.Header())
+	if err != nil {
+		return nil, err
+	}
+	fields["hash"] = block.Hash()
+	fields["uncles"] = []l2common.Hash{}
+
+	txs := make([]interface{}, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		if !fullTx {
+			txs = append(txs, tx.Hash())
+			continue
+		}
+
+		txFields, err := marshalFields(tx)
+		if err != nil {
+			return nil, err
+		}
+		meta := tx.GetMeta()
+		txFields["blockHash"] = block.Hash()
+		txFields["blockNumber"] = (*l2hexutil.Big)(block.Number())
+		txFields["transactionIndex"] = l2hexutil.Uint64(i)
+		txFields["l1BlockNumber"] = (*l2hexutil.Big)(meta.L1BlockNumber)
+		txFields["l1Timestamp"] = l2hexutil.Uint64(meta.L1Timestamp)
+		txFields["l1MessageSender"] = meta.L1MessageSender
+		txFields["queueOrigin"] = meta.QueueOrigin.String()
+		txFields["index"] = (*l2hexutil.Uint64)(meta.Index)
+		txFields["queueIndex"] = (*l2hexutil.Uint64)(meta.QueueIndex)
+		txFields["rawTransaction"] = l2hexutil.Bytes(meta.RawTransaction)
+		txs = append(txs, txFields)
+	}
+	fields["transactions"] = txs
+
+	return fields, nil
+}
+
+// marshalOpGethBlock returns the op-geth style JSON-RPC representation of
+// block, reporting queue txs as deposit txs if fullTx is true.
+func marshalOpGethBlock(
+	block *l2types.Block,
+	fullTx bool,
+) (map[string]interface{}, error) {
+
+	fields, err := marshalL2Block(block, false)
+	if err != nil || !fullTx {
+		return fields, err
+	}
+
+	txs := make([]interface{}, 0, len(block.Transactions()))
+	for i, tx := range block.Transactions() {
+		meta := tx.GetMeta()
+
+		var txFields map[string]interface{}
+		if meta.QueueOrigin == l2types.QueueOriginSequencer {
+			txFields, err = marshalFields(tx)
+			if err != nil {
+				return nil, err
+			}
+			txFields["type"] = l2hexutil.Uint64(0)
+		} else {
+			txFields = map[string]interface{}{
+				"type":  l2hexutil.Uint64(l2client.DepositTxType),
+				"from":  meta.L1MessageSender,
+				"nonce": (*l2hexutil.Uint64)(meta.QueueIndex),
+				"to":    tx.To(),
+				"value": (*l2hexutil.Big)(tx.Value()),
+				"gas":   l2hexutil.Uint64(tx.Gas()),
+				"input": l2hexutil.Bytes(tx.Data()),
+				"hash":  tx.Hash(),
+			}
+		}
+		txFields["blockHash"] = block.Hash()
+		txFields["blockNumber"] = (*l2hexutil.Big)(block.Number())
+		txFields["transactionIndex"] = l2hexutil.Uint64(i)
+		txFields["l1BlockNumber"] = (*l2hexutil.Big)(meta.L1BlockNumber)
+		txFields["l1Timestamp"] = l2hexutil.Uint64(meta.L1Timestamp)
+		txs = append(txs, txFields)
+	}
+	fields["transactions"] = txs
+
+	return fields, nil
+}
+
+// marshalFields returns the fields of the JSON encoding of v.
+func marshalFields(v interface{}) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(encoded, &fields); err != nil {
+		return nil, err
+	}
+
+	return fields, nil
+}
@@ -0,0 +1,97 @@
+package testutil
+
+import (
+	"context"
+	"time"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+)
+
+// idlePollInterval is the poll interval of a stepped Service, long enough that
+// cycles only run when stepped.
+const idlePollInterval = 24 * time.Hour
+
+// Stepper runs a Service one cycle at a time. Between steps, the event loop is
+// parked, such that a test observes the state left by each cycle before the
+// next one begins.
+type Stepper struct {
+	service *batchsubmitter.Service
+	cycles  chan *batchsubmitter.CycleTrace
+	done    chan struct{}
+}
+
+// NewStepper initializes a Service from cfg whose cycles only run when
+// stepped. Any OnCycle callback of cfg is still called, before the step
+// returns.
+func NewStepper(cfg batchsubmitter.ServiceConfig) *Stepper {
+	s := &Stepper{
+		cycles: make(chan *batchsubmitter.CycleTrace),
+		done:   make(chan struct{}),
+	}
+
+	onCycle := cfg.OnCycle
+	cfg.PollInterval = idlePollInterval
+	cfg.OnCycle = func(trace *batchsubmitter.CycleTrace) {
+		if onCycle != nil {
+			onCycle(trace)
+		}
+		select {
+		case s.cycles <- trace:
+		case <-s.done:
+		}
+	}
+	s.service = batchsubmitter.NewService(cfg)
+
+	return s
+}
+
+// Service returns the stepped Service.
+func (s *Stepper) Service() *batchsubmitter.Service {
+	return s.service
+}
+
+// Start starts the Service, without running a cycle.
+func (s *Stepper) Start() error {
+	return s.service.Start()
+}
+
+// Stop stops the Service, releasing a cycle awaiting the next step.
+func (s *Stepper) Stop() error {
+	close(s.done)
+	return s.service.Stop()
+}
+
+// Step triggers a cycle and returns its trace once it completes. If a cycle
+// was already run without being stepped, e.g. as the Service is catching up
+// on a backlog, its trace is returned instead, and the trigger runs the next
+// cycle.
+func (s *Stepper) Step(
+	ctx context.Context) (*batchsubmitter.CycleTrace, error) {
+
+	s.service.TriggerCycle()
+
+	select {
+	case trace := <-s.cycles:
+		return trace, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// StepUntil steps the Service until cond holds after a cycle, returning the
+// trace of the last cycle, or ctx's error if it expires first.
+func (s *Stepper) StepUntil(
+	ctx context.Context,
+	cond func() bool,
+) (*batchsubmitter.CycleTrace, error) {
+
+	for {
+		trace, err := s.Step(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if cond() {
+			return trace, nil
+		}
+	}
+}
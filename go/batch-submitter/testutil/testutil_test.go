@@ -0,0 +1,484 @@
+package testutil_test
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	batchsubmitter "github.com/ethereum-optimism/optimism/go/batch-submitter"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/drivers/sequencer"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/testutil"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestChains returns an L1 and L2 chain closed once the test completes.
+func newTestChains(t *testing.T) (*testutil.L1, *testutil.L2) {
+	l1, err := testutil.NewL1(big.NewInt(901), common.HexToAddress("0xc7c"))
+	require.Nil(t, err)
+	t.Cleanup(l1.Close)
+
+	l2, err := testutil.NewL2()
+	require.Nil(t, err)
+	t.Cleanup(l2.Close)
+
+	return l1, l2
+}
+
+// newTestStepper returns a stepped sequencer service appending the blocks of
+// l2 to the CTC of l1, stopped once the test completes. Each opt may alter the
+// service's config before it is started.
+func newTestStepper(
+	t *testing.T,
+	l1 *testutil.L1,
+	l2 *testutil.L2,
+	opts ...func(*batchsubmitter.ServiceConfig),
+) *testutil.Stepper {
+
+	privKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	name := "testutil_" + t.Name()
+	driver, err := sequencer.NewDriver(sequencer.Config{
+		Name:            name,
+		L1Client:        l1.Client(),
+		L2Client:        l2.Client(),
+		BlockOffset:     1,
+		MaxTxSize:       128 * 1024,
+		MaxGasLimit:     1_000_000,
+		CTCAddr:         l1.CTCAddr(),
+		ChainID:         l1.ChainID(),
+		PrivKey:         privKey,
+		NumFetchWorkers: 1,
+	})
+	require.Nil(t, err)
+
+	cfg := batchsubmitter.ServiceConfig{
+		Context:  context.Background(),
+		Driver:   driver,
+		L1Client: l1.Client(),
+		TxManagerConfig: txmgr.Config{
+			Name:                 name,
+			MinGasPrice:          big.NewInt(params.GWei),
+			MaxGasPrice:          big.NewInt(100 * params.GWei),
+			GasRetryIncrement:    big.NewInt(params.GWei),
+			ResubmissionTimeout:  time.Second,
+			ReceiptQueryInterval: 10 * time.Millisecond,
+			NumConfirmations:     1,
+		},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	stepper := testutil.NewStepper(cfg)
+	require.Nil(t, stepper.Start())
+	t.Cleanup(func() {
+		_ = stepper.Stop()
+	})
+
+	return stepper
+}
+
+// TestStepperAppendsL2Blocks asserts that a stepped sequencer appends the
+// canned L2 blocks to the fake CTC, including the queue element enqueued on
+// L1, without any batch reverting.
+func TestStepperAppendsL2Blocks(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+	testStepperAppendsL2Blocks(t, l1, l2)
+}
+
+// TestStepperAppendsOpGethBlocks asserts that a stepped sequencer appends the
+// same batch when the canned L2 blocks are served as by an op-geth style node.
+func TestStepperAppendsOpGethBlocks(t *testing.T) {
+	t.Parallel()
+
+	l1, _ := newTestChains(t)
+	l2, err := testutil.NewOpGethL2()
+	require.Nil(t, err)
+	t.Cleanup(l2.Close)
+
+	testStepperAppendsL2Blocks(t, l1, l2)
+}
+
+// testStepperAppendsL2Blocks adds canned blocks to l2, including a queue
+// element enqueued on l1, and asserts that a stepped sequencer appends them in
+// a single batch.
+func testStepperAppendsL2Blocks(
+	t *testing.T,
+	l1 *testutil.L1,
+	l2 *testutil.L2,
+) {
+
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		l2.AddBlock(100+nonce, testutil.SequencerTx(nonce, 1))
+	}
+	element := testutil.QueueElement{Timestamp: 104, BlockNumber: 2}
+	l1.Enqueue(element)
+	l2.AddBlock(element.Timestamp, testutil.QueueTx(0, element))
+	l2.AddBlock(105, testutil.SequencerTx(4, 2))
+
+	stepper := newTestStepper(t, l1, l2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	trace, err := stepper.StepUntil(ctx, func() bool {
+		return l1.TotalElements() == l2.Height()
+	})
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSubmitted, trace.Outcome)
+	require.Empty(t, l1.Reverted())
+	require.Equal(t, uint64(1), l1.NextQueueIndex())
+
+	batches := l1.Batches()
+	require.Len(t, batches, 1)
+	require.Equal(t, uint64(5), batches[0].NumSequencedTxs)
+	require.Equal(t, uint64(1), batches[0].NumQueuedTxs)
+
+	// With nothing left to append, the next cycle is skipped.
+	trace, err = stepper.Step(ctx)
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSkipped, trace.Outcome)
+}
+
+// TestStepperDefersUnenqueuedQueueElement asserts that a batch executing a
+// queue element yet to be enqueued on l1 is deferred rather than published,
+// and appended once the element is enqueued.
+func TestStepperDefersUnenqueuedQueueElement(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+	element := testutil.QueueElement{Timestamp: 100, BlockNumber: 1}
+	l2.AddBlock(element.Timestamp, testutil.QueueTx(0, element))
+	l2.AddBlock(101, testutil.SequencerTx(0, 1))
+
+	stepper := newTestStepper(t, l1, l2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	trace, err := stepper.Step(ctx)
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSkipped, trace.Outcome)
+	require.Equal(t, batchsubmitter.SkipSendDeferred, trace.SkipReason)
+	require.Empty(t, l1.Batches())
+	require.Empty(t, l1.Reverted())
+
+	l1.Enqueue(element)
+	trace, err = stepper.StepUntil(ctx, func() bool {
+		return l1.TotalElements() == l2.Height()
+	})
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSubmitted, trace.Outcome)
+	require.Empty(t, l1.Reverted())
+	require.Equal(t, uint64(1), l1.NextQueueIndex())
+}
+
+// testGasPriceOracle is a txmgr.GasPriceOracle suggesting the gas price last
+// set by a test.
+type testGasPriceOracle struct {
+	gasPrice int64
+}
+
+// set updates the suggested gas price.
+func (o *testGasPriceOracle) set(gasPrice int64) {
+	atomic.StoreInt64(&o.gasPrice, gasPrice)
+}
+
+// SuggestGasPrice returns the suggested gas price.
+func (o *testGasPriceOracle) SuggestGasPrice(
+	ctx context.Context) (*big.Int, error) {
+
+	return big.NewInt(atomic.LoadInt64(&o.gasPrice)), nil
+}
+
+// TestStepperReplacesAbandonedBatchTx asserts that once the tx manager gives up
+// on a published batch tx, the next cycle re-signs the batch at the same nonce,
+// replacing the stuck tx rather than queueing a new tx behind it.
+func TestStepperReplacesAbandonedBatchTx(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		l2.AddBlock(100+nonce, testutil.SequencerTx(nonce, 1))
+	}
+	l1.HoldBlocks(true)
+
+	oracle := &testGasPriceOracle{gasPrice: params.GWei}
+	stepper := newTestStepper(t, l1, l2,
+		func(cfg *batchsubmitter.ServiceConfig) {
+			cfg.TxManagerConfig.GasPriceOracle = oracle
+			cfg.TxManagerConfig.ResubmissionTimeout =
+				100 * time.Millisecond
+			cfg.TxManagerConfig.FeeBump.MaxBumps = 1
+		},
+	)
+	wallet := stepper.Service().Status().Wallet.Address
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	trace, err := stepper.Step(ctx)
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleFailed, trace.Outcome)
+
+	stuck := l1.PendingTxs(wallet)
+	require.Len(t, stuck, 1)
+	require.Equal(t, uint64(0), stuck[0].Nonce())
+
+	oracle.set(5 * params.GWei)
+	traces := stepInBackground(ctx, stepper)
+	require.Eventually(t, func() bool {
+		pending := l1.PendingTxs(wallet)
+		return len(pending) == 1 &&
+			pending[0].Hash() != stuck[0].Hash()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	replacement := l1.PendingTxs(wallet)[0]
+	require.Equal(t, uint64(0), replacement.Nonce())
+	require.Equal(t, big.NewInt(5*params.GWei), replacement.GasPrice())
+
+	l1.Mine()
+	require.Equal(t, batchsubmitter.CycleSubmitted, (<-traces).Outcome)
+	require.Len(t, l1.Batches(), 1)
+	require.Empty(t, l1.Reverted())
+	require.Equal(t, l2.Height(), l1.TotalElements())
+}
+
+// TestStepperRetriesFailedPipelinedBatch asserts that once the tx manager gives
+// up on a pipelined batch tx, the batch tx broadcast behind it is still awaited
+// at its nonce, while the failed batch is replaced at its own nonce, such that
+// both batches are appended without reverting.
+func TestStepperRetriesFailedPipelinedBatch(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+	l2.AddBlock(100, testutil.SequencerTx(0, 1))
+	l1.HoldBlocks(true)
+
+	oracle := &testGasPriceOracle{gasPrice: params.GWei}
+	stepper := newTestStepper(t, l1, l2,
+		func(cfg *batchsubmitter.ServiceConfig) {
+			cfg.MaxInFlightBatches = 2
+			cfg.TxManagerConfig.GasPriceOracle = oracle
+			cfg.TxManagerConfig.ResubmissionTimeout =
+				500 * time.Millisecond
+			cfg.TxManagerConfig.FeeBump.MaxBumps = 1
+		},
+	)
+	wallet := stepper.Service().Status().Wallet.Address
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	trace, err := stepper.Step(ctx)
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSubmitted, trace.Outcome)
+	first := l1.PendingTxs(wallet)
+	require.Len(t, first, 1)
+
+	// Publish the second batch once the first has been bumped, such that
+	// the first is given up on while the second is still awaited.
+	require.Eventually(t, func() bool {
+		pending := l1.PendingTxs(wallet)
+		return len(pending) == 1 && pending[0].Hash() != first[0].Hash()
+	}, 5*time.Second, 10*time.Millisecond)
+
+	l2.AddBlock(101, testutil.SequencerTx(1, 1))
+	trace, err = stepper.Step(ctx)
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSubmitted, trace.Outcome)
+	pending := l1.PendingTxs(wallet)
+	require.Len(t, pending, 2)
+	second := pending[1]
+	require.Equal(t, uint64(1), second.Nonce())
+
+	oracle.set(5 * params.GWei)
+	_, err = stepper.StepUntil(ctx, func() bool {
+		pending := l1.PendingTxs(wallet)
+		return len(pending) == 2 &&
+			pending[0].GasPrice().Cmp(big.NewInt(5*params.GWei)) == 0
+	})
+	require.Nil(t, err)
+	require.Equal(t, second.Hash(), l1.PendingTxs(wallet)[1].Hash())
+
+	l1.Mine()
+	_, err = stepper.StepUntil(ctx, func() bool {
+		return l1.TotalElements() == l2.Height() &&
+			stepper.Service().DebugState().InFlight != nil &&
+			len(stepper.Service().DebugState().InFlight) == 0
+	})
+	require.Nil(t, err)
+	require.Len(t, l1.Batches(), 2)
+	require.Empty(t, l1.Reverted())
+
+	receipt, err := l1.Client().TransactionReceipt(ctx, second.Hash())
+	require.Nil(t, err)
+	require.Equal(t, types.ReceiptStatusSuccessful, receipt.Status)
+
+	// Once drained, new batches are admitted again.
+	l2.AddBlock(102, testutil.SequencerTx(2, 1))
+	trace, err = stepper.Step(ctx)
+	require.Nil(t, err)
+	require.Equal(t, batchsubmitter.CycleSubmitted, trace.Outcome)
+}
+
+// stepInBackground steps stepper without blocking, delivering the trace of the
+// cycle once it completes.
+func stepInBackground(
+	ctx context.Context,
+	stepper *testutil.Stepper,
+) <-chan *batchsubmitter.CycleTrace {
+
+	traces := make(chan *batchsubmitter.CycleTrace, 1)
+	go func() {
+		trace, _ := stepper.Step(ctx)
+		traces <- trace
+	}()
+
+	return traces
+}
+
+// startHeldBatch adds canned blocks to l2 and steps a sequencer in the
+// background until its batch tx is pending on l1, whose blocks are held.
+func startHeldBatch(
+	ctx context.Context,
+	t *testing.T,
+	l1 *testutil.L1,
+	l2 *testutil.L2,
+) (*testutil.Stepper, <-chan *batchsubmitter.CycleTrace) {
+
+	for nonce := uint64(0); nonce < 3; nonce++ {
+		l2.AddBlock(100+nonce, testutil.SequencerTx(nonce, 1))
+	}
+	l1.HoldBlocks(true)
+
+	stepper := newTestStepper(t, l1, l2)
+	traces := stepInBackground(ctx, stepper)
+
+	wallet := stepper.Service().Status().Wallet.Address
+	require.Eventually(t, func() bool {
+		return len(l1.PendingTxs(wallet)) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	return stepper, traces
+}
+
+// TestServiceDrainAwaitsBatchInFlight asserts that a draining service awaits
+// its batch tx in flight until it confirms, and starts no further cycles.
+func TestServiceDrainAwaitsBatchInFlight(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stepper, traces := startHeldBatch(ctx, t, l1, l2)
+	service := stepper.Service()
+
+	drained := make(chan error, 1)
+	go func() {
+		drained <- service.Drain(ctx)
+	}()
+	require.Eventually(t, service.Draining, time.Second, time.Millisecond)
+
+	select {
+	case err := <-drained:
+		t.Fatalf("drained with batch tx in flight: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	l1.Mine()
+	require.Nil(t, <-drained)
+	require.Equal(t, batchsubmitter.CycleSubmitted, (<-traces).Outcome)
+	require.Equal(t, l2.Height(), l1.TotalElements())
+
+	// No further cycles are started once drained.
+	stepCtx, stepCancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer stepCancel()
+	_, err := stepper.Step(stepCtx)
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+// TestServiceDrainForced asserts that a drain is abandoned once its context
+// expires, leaving the batch tx in flight to be abandoned by Stop.
+func TestServiceDrainForced(t *testing.T) {
+	t.Parallel()
+
+	l1, l2 := newTestChains(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	stepper, traces := startHeldBatch(ctx, t, l1, l2)
+
+	drainCtx, drainCancel := context.WithTimeout(
+		ctx, 100*time.Millisecond,
+	)
+	defer drainCancel()
+	err := stepper.Service().Drain(drainCtx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	require.Nil(t, stepper.Service().Stop())
+	require.Equal(t, batchsubmitter.CycleFailed, (<-traces).Outcome)
+	require.Zero(t, l1.TotalElements())
+}
+
+// TestL1RevertsMisalignedBatch asserts that the fake CTC reverts a batch that
+// does not begin at its total elements, both when called and when mined.
+func TestL1RevertsMisalignedBatch(t *testing.T) {
+	t.Parallel()
+
+	l1, _ := newTestChains(t)
+	ctx := context.Background()
+
+	batchParams, err := sequencer.GenSequencerBatchParams(
+		1, 0, []sequencer.BatchElement{{
+			Timestamp:   1,
+			BlockNumber: 1,
+		}}, sequencer.BatchLimits{},
+	)
+	require.Nil(t, err)
+	callData, err := testutil.AppendSequencerBatchCallData(batchParams)
+	require.Nil(t, err)
+
+	ctcAddr := l1.CTCAddr()
+	_, err = l1.Client().CallContract(ctx, ethereum.CallMsg{
+		To:   &ctcAddr,
+		Data: callData,
+	}, nil)
+	require.NotNil(t, err)
+	require.Equal(t, "Actual batch start index does not match expected "+
+		"start index.", sequencer.RevertReason(err))
+
+	privKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	tx, err := types.SignNewTx(
+		privKey, types.LatestSignerForChainID(l1.ChainID()),
+		&types.LegacyTx{
+			GasPrice: big.NewInt(params.GWei),
+			Gas:      100_000,
+			To:       &ctcAddr,
+			Data:     callData,
+		},
+	)
+	require.Nil(t, err)
+	require.Nil(t, l1.Client().SendTransaction(ctx, tx))
+
+	receipt, err := l1.Client().TransactionReceipt(ctx, tx.Hash())
+	require.Nil(t, err)
+	require.Equal(t, types.ReceiptStatusFailed, receipt.Status)
+	require.Equal(t, []common.Hash{tx.Hash()}, l1.Reverted())
+	require.Zero(t, l1.TotalElements())
+}
@@ -0,0 +1,277 @@
+package batchsubmitter
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// defaultTraceHistorySize is the number of cycle traces retained per service
+// if none is configured.
+const defaultTraceHistorySize = 64
+
+// CycleOutcome summarizes the result of a single submission cycle.
+type CycleOutcome string
+
+const (
+	// CycleSubmitted indicates that a batch tx was confirmed.
+	CycleSubmitted CycleOutcome = "submitted"
+
+	// CycleSkipped indicates that the cycle deliberately did not submit a
+	// batch, e.g. because there were no updates or the gas price was too
+	// high.
+	CycleSkipped CycleOutcome = "skipped"
+
+	// CycleFailed indicates that the cycle was aborted by an error.
+	CycleFailed CycleOutcome = "failed"
+)
+
+// SkipReason classifies why a cycle deliberately did not submit a batch, such
+// that idle cycles can be told apart on dashboards.
+type SkipReason string
+
+const (
+	// SkipNoUpdates indicates that there were no new L2 blocks to submit.
+	SkipNoUpdates SkipReason = "no_updates"
+
+	// SkipBelowMinSize indicates that the pending range was deferred as too
+	// small to be worth a batch tx.
+	SkipBelowMinSize SkipReason = "below_min_size"
+
+	// SkipPaused indicates that submission was paused by an operator.
+	SkipPaused SkipReason = "paused"
+
+	// SkipStandby indicates that another replica holds leadership.
+	SkipStandby SkipReason = "standby"
+
+	// SkipLowBalance indicates that the balance was below the critical
+	// minimum.
+	SkipLowBalance SkipReason = "low_balance"
+
+	// SkipSpendBudget indicates that the spend of the batches confirmed
+	// within the spend budget's window reached the budget.
+	SkipSpendBudget SkipReason = "spend_budget"
+
+	// SkipPipelineFull indicates that the pipeline was full or draining.
+	SkipPipelineFull SkipReason = "pipeline_full"
+
+	// SkipQuarantined indicates that the next range awaits an operator.
+	SkipQuarantined SkipReason = "quarantined"
+
+	// SkipGracePeriod indicates that the startup grace period had yet to
+	// elapse.
+	SkipGracePeriod SkipReason = "grace_period"
+
+	// SkipGasPriceCapped indicates that the gas price was above the max
+	// gas price.
+	SkipGasPriceCapped SkipReason = "gas_price_capped"
+
+	// SkipFeeCapped indicates that the batch fee was above its ceiling.
+	SkipFeeCapped SkipReason = "fee_capped"
+
+	// SkipDryRun indicates that the batch tx was not published in dry-run
+	// mode.
+	SkipDryRun SkipReason = "dry_run"
+
+	// SkipDraining indicates that the service was draining ahead of
+	// shutdown, such that no new batch tx was published.
+	SkipDraining SkipReason = "draining"
+
+	// SkipSendDeferred indicates that the batch would revert until the L1
+	// state it depends on exists, e.g. as it executes queue elements yet
+	// to be enqueued.
+	SkipSendDeferred SkipReason = "send_deferred"
+)
+
+// skipReasons lists every SkipReason, such that each is reported before it
+// first occurs.
+var skipReasons = []SkipReason{
+	SkipNoUpdates, SkipBelowMinSize, SkipPaused, SkipStandby,
+	SkipLowBalance, SkipSpendBudget, SkipPipelineFull, SkipQuarantined, SkipGracePeriod,
+	SkipGasPriceCapped, SkipFeeCapped, SkipDryRun, SkipDraining,
+	SkipSendDeferred,
+}
+
+// TraceStep records a single decision taken during a cycle.
+type TraceStep struct {
+	// Name identifies the decision point, e.g. block_range.
+	Name string `json:"name"`
+
+	// Detail describes the inputs or result of the decision.
+	Detail string `json:"detail"`
+}
+
+// CycleTrace is the decision trail of a single submission cycle, explaining
+// why a batch was or wasn't submitted.
+//
+// NOTE: A CycleTrace is safe for concurrent use through its methods. Its fields
+// MUST only be read directly once the cycle has concluded.
+type CycleTrace struct {
+	mu sync.Mutex
+
+	// StartedAt is the time at which the cycle began.
+	StartedAt time.Time `json:"started_at"`
+
+	// DurationMs is the duration of the cycle in milliseconds.
+	DurationMs int64 `json:"duration_ms"`
+
+	// Outcome summarizes the result of the cycle.
+	Outcome CycleOutcome `json:"outcome"`
+
+	// Reason explains the outcome.
+	Reason string `json:"reason"`
+
+	// SkipReason classifies the outcome of a skipped cycle.
+	SkipReason SkipReason `json:"skip_reason,omitempty"`
+
+	// Error is the error that aborted the cycle, if any.
+	Error string `json:"error,omitempty"`
+
+	// Steps are the decisions taken during the cycle, in order.
+	Steps []TraceStep `json:"steps"`
+}
+
+// newCycleTrace begins the trace of a new cycle.
+func newCycleTrace() *CycleTrace {
+	return &CycleTrace{
+		StartedAt: time.Now(),
+	}
+}
+
+// Step appends a decision to the trace.
+func (t *CycleTrace) Step(name, format string, args ...interface{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Steps = append(t.Steps, TraceStep{
+		Name:   name,
+		Detail: fmt.Sprintf(format, args...),
+	})
+}
+
+// Submitted concludes the trace with a successful submission.
+func (t *CycleTrace) Submitted(reason string) {
+	t.finish(CycleSubmitted, reason, nil)
+}
+
+// Skipped concludes the trace with a deliberately skipped submission,
+// classified by kind.
+func (t *CycleTrace) Skipped(kind SkipReason, reason string) {
+	t.mu.Lock()
+	t.SkipReason = kind
+	t.mu.Unlock()
+
+	t.finish(CycleSkipped, reason, nil)
+}
+
+// Failed concludes the trace with an error.
+func (t *CycleTrace) Failed(reason string, err error) {
+	t.finish(CycleFailed, reason, err)
+}
+
+// finish records the outcome and duration of the cycle.
+func (t *CycleTrace) finish(outcome CycleOutcome, reason string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.Outcome = outcome
+	t.Reason = reason
+	if err != nil {
+		t.Error = err.Error()
+	}
+	t.DurationMs = int64(time.Since(t.StartedAt) / time.Millisecond)
+}
+
+// Snapshot returns a copy of the trace, which may still be in progress.
+func (t *CycleTrace) Snapshot() *CycleTrace {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return &CycleTrace{
+		StartedAt:  t.StartedAt,
+		DurationMs: t.DurationMs,
+		Outcome:    t.Outcome,
+		Reason:     t.Reason,
+		SkipReason: t.SkipReason,
+		Error:      t.Error,
+		Steps:      append([]TraceStep{}, t.Steps...),
+	}
+}
+
+// traceHistory is a fixed-size ring buffer of the most recent cycle traces.
+type traceHistory struct {
+	mu     sync.Mutex
+	traces []*CycleTrace
+	next   int
+	full   bool
+
+	// current is the trace of the cycle in progress, if any.
+	current *CycleTrace
+}
+
+// newTraceHistory initializes a traceHistory retaining up to size traces.
+func newTraceHistory(size int) *traceHistory {
+	if size <= 0 {
+		size = defaultTraceHistorySize
+	}
+
+	return &traceHistory{
+		traces: make([]*CycleTrace, size),
+	}
+}
+
+// Begin records trace as the trace of the cycle in progress, until it is added
+// to the history once the cycle concludes.
+func (h *traceHistory) Begin(trace *CycleTrace) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.current = trace
+}
+
+// Current returns a snapshot of the trace of the cycle in progress, or nil if
+// no cycle is running.
+func (h *traceHistory) Current() *CycleTrace {
+	h.mu.Lock()
+	current := h.current
+	h.mu.Unlock()
+
+	if current == nil {
+		return nil
+	}
+	return current.Snapshot()
+}
+
+// Add records trace, evicting the oldest trace if the history is full.
+func (h *traceHistory) Add(trace *CycleTrace) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.current == trace {
+		h.current = nil
+	}
+	h.traces[h.next] = trace
+	h.next = (h.next + 1) % len(h.traces)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// Recent returns the retained traces, most recent first.
+func (h *traceHistory) Recent() []*CycleTrace {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	n := h.next
+	if h.full {
+		n = len(h.traces)
+	}
+
+	recent := make([]*CycleTrace, 0, n)
+	for i := 1; i <= n; i++ {
+		idx := (h.next - i + len(h.traces)) % len(h.traces)
+		recent = append(recent, h.traces[idx])
+	}
+
+	return recent
+}
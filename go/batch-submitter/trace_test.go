@@ -0,0 +1,136 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTraceHistoryRecent asserts that Recent returns the retained traces most
+// recent first, evicting the oldest traces once the history is full.
+func TestTraceHistoryRecent(t *testing.T) {
+	t.Parallel()
+
+	history := newTraceHistory(3)
+	require.Empty(t, history.Recent())
+
+	var traces []*CycleTrace
+	for i := 0; i < 5; i++ {
+		trace := newCycleTrace()
+		history.Add(trace)
+		traces = append(traces, trace)
+
+		switch i {
+		case 0:
+			require.Equal(t, []*CycleTrace{traces[0]}, history.Recent())
+		case 1:
+			require.Equal(t, []*CycleTrace{
+				traces[1], traces[0],
+			}, history.Recent())
+		}
+	}
+
+	require.Equal(t, []*CycleTrace{
+		traces[4], traces[3], traces[2],
+	}, history.Recent())
+}
+
+// TestCycleTraceOutcome asserts that concluding a trace records its outcome,
+// reason and error alongside the steps taken.
+func TestCycleTraceOutcome(t *testing.T) {
+	t.Parallel()
+
+	trace := newCycleTrace()
+	trace.Step("block_range", "start=%d end=%d", 1, 1)
+	trace.Skipped(SkipNoUpdates, "no new L2 blocks to submit")
+
+	require.Equal(t, CycleSkipped, trace.Outcome)
+	require.Equal(t, "no new L2 blocks to submit", trace.Reason)
+	require.Equal(t, SkipNoUpdates, trace.SkipReason)
+	require.Empty(t, trace.Error)
+	require.Equal(t, []TraceStep{
+		{Name: "block_range", Detail: "start=1 end=1"},
+	}, trace.Steps)
+
+	trace = newCycleTrace()
+	trace.Failed("unable to get current nonce", errors.New("boom"))
+
+	require.Equal(t, CycleFailed, trace.Outcome)
+	require.Equal(t, "unable to get current nonce", trace.Reason)
+	require.Equal(t, "boom", trace.Error)
+}
+
+// TestTraceHistoryCurrent asserts that the trace of the cycle in progress is
+// returned as a snapshot until it is added to the history.
+func TestTraceHistoryCurrent(t *testing.T) {
+	t.Parallel()
+
+	history := newTraceHistory(3)
+	require.Nil(t, history.Current())
+
+	trace := newCycleTrace()
+	history.Begin(trace)
+	trace.Step("balance", "%d wei", 1)
+
+	current := history.Current()
+	require.Equal(t, []TraceStep{{Name: "balance", Detail: "1 wei"}},
+		current.Steps)
+
+	// Later steps are not reflected in an earlier snapshot.
+	trace.Step("block_range", "start=%d end=%d", 1, 1)
+	require.Len(t, current.Steps, 1)
+	require.Len(t, history.Current().Steps, 2)
+
+	trace.Skipped(SkipNoUpdates, "no new L2 blocks to submit")
+	history.Add(trace)
+	require.Nil(t, history.Current())
+	require.Equal(t, []*CycleTrace{trace}, history.Recent())
+}
+
+// TestServiceRecordCycleOutcome asserts that concluded cycles are counted by
+// outcome, and skipped cycles by reason.
+func TestServiceRecordCycleOutcome(t *testing.T) {
+	t.Parallel()
+
+	s := newRetryTestService(context.Background(),
+		"TestServiceRecordCycleOutcome", RetryPolicy{})
+	s.initCycleMetrics()
+
+	skipped := func(reason SkipReason) float64 {
+		return testutil.ToFloat64(
+			s.metrics.CyclesSkipped.WithLabelValues(string(reason)),
+		)
+	}
+	outcomes := func(outcome CycleOutcome) float64 {
+		return testutil.ToFloat64(
+			s.metrics.CycleOutcomes.WithLabelValues(string(outcome)),
+		)
+	}
+	require.Equal(t, 3, testutil.CollectAndCount(s.metrics.CycleOutcomes))
+	require.Equal(t, len(skipReasons),
+		testutil.CollectAndCount(s.metrics.CyclesSkipped))
+
+	for _, reason := range []SkipReason{
+		SkipNoUpdates, SkipNoUpdates, SkipBelowMinSize,
+	} {
+		trace := newCycleTrace()
+		trace.Skipped(reason, string(reason))
+		s.recordCycleOutcome(trace)
+	}
+	trace := newCycleTrace()
+	trace.Failed("unable to get current nonce", errors.New("boom"))
+	s.recordCycleOutcome(trace)
+
+	// A trace that never concluded is not counted.
+	s.recordCycleOutcome(newCycleTrace())
+
+	require.Equal(t, 3.0, outcomes(CycleSkipped))
+	require.Equal(t, 1.0, outcomes(CycleFailed))
+	require.Equal(t, 0.0, outcomes(CycleSubmitted))
+	require.Equal(t, 2.0, skipped(SkipNoUpdates))
+	require.Equal(t, 1.0, skipped(SkipBelowMinSize))
+	require.Equal(t, 0.0, skipped(SkipPaused))
+}
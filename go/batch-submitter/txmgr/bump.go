@@ -0,0 +1,196 @@
+package txmgr
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// minDynamicFeeBumpPercent is the smallest bump of the tip cap and fee cap of a
+// dynamic fee tx accepted by nodes as a replacement, used if no percentage is
+// configured.
+const minDynamicFeeBumpPercent = 10
+
+// FeeBumpPolicy determines how the fees of a tx are bumped each time it is
+// replaced after a ResubmissionTimeout, and how long Send keeps replacing it.
+type FeeBumpPolicy struct {
+	// GasPricePercent is the percentage by which the gas price of a legacy
+	// tx is bumped on each replacement, before adding GasRetryIncrement.
+	GasPricePercent uint64
+
+	// TipCapPercent and FeeCapPercent are the percentages by which the tip
+	// cap and fee cap of a dynamic fee tx are bumped on each replacement.
+	// If zero, the minimum of 10% accepted by nodes is used.
+	TipCapPercent uint64
+	FeeCapPercent uint64
+
+	// MaxBumps, if non-zero, is the max number of replacements of a tx,
+	// after which the last replacement is given one final
+	// ResubmissionTimeout to confirm.
+	MaxBumps uint64
+
+	// AbortAfter, if non-zero, is the duration after which Send gives up
+	// on a tx, regardless of its fees. A tx mined but yet to reach
+	// NumConfirmations is waited for, in case it confirms.
+	AbortAfter time.Duration
+}
+
+// DynamicFees are the fees of an EIP-1559 dynamic fee tx.
+type DynamicFees struct {
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// SendDynamicTxFunc defines a function signature for publishing a desired
+// dynamic fee tx with specific fees. It is otherwise identical to SendTxFunc.
+type SendDynamicTxFunc = func(
+	ctx context.Context, fees DynamicFees) (*types.Transaction, error)
+
+// BumpGasPrice bumps the current gas price by percent, and then by an additive
+// gasRetryIncrement, clamping the resulting value to maxGasPrice.
+//
+// NOTE: This method does not mutate curGasPrice, but instead returns a copy.
+func BumpGasPrice(
+	curGasPrice, gasRetryIncrement *big.Int,
+	percent uint64,
+	maxGasPrice *big.Int,
+) *big.Int {
+
+	return NextGasPrice(
+		bumpPercent(curGasPrice, percent), gasRetryIncrement,
+		maxGasPrice,
+	)
+}
+
+// BumpDynamicFees bumps the tip cap and fee cap of fees by the percentages of
+// policy, clamping the fee cap to maxFeeCap and the tip cap to the fee cap.
+//
+// NOTE: This method does not mutate fees, but instead returns a copy.
+func BumpDynamicFees(
+	fees DynamicFees,
+	policy FeeBumpPolicy,
+	maxFeeCap *big.Int,
+) DynamicFees {
+
+	tipCapPercent := policy.TipCapPercent
+	if tipCapPercent == 0 {
+		tipCapPercent = minDynamicFeeBumpPercent
+	}
+	feeCapPercent := policy.FeeCapPercent
+	if feeCapPercent == 0 {
+		feeCapPercent = minDynamicFeeBumpPercent
+	}
+
+	return clampDynamicFees(DynamicFees{
+		GasTipCap: bumpPercent(fees.GasTipCap, tipCapPercent),
+		GasFeeCap: bumpPercent(fees.GasFeeCap, feeCapPercent),
+	}, maxFeeCap)
+}
+
+// clampDynamicFees returns a copy of fees with the fee cap clamped to maxFeeCap
+// and the tip cap clamped to the fee cap.
+func clampDynamicFees(fees DynamicFees, maxFeeCap *big.Int) DynamicFees {
+	feeCap := new(big.Int).Set(fees.GasFeeCap)
+	if feeCap.Cmp(maxFeeCap) > 0 {
+		feeCap.Set(maxFeeCap)
+	}
+	tipCap := new(big.Int).Set(fees.GasTipCap)
+	if tipCap.Cmp(feeCap) > 0 {
+		tipCap.Set(feeCap)
+	}
+
+	return DynamicFees{
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+	}
+}
+
+// bumpPercent returns a copy of value increased by percent, rounded up such
+// that any non-zero percentage increases a non-zero value.
+func bumpPercent(value *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(
+		value, new(big.Int).SetUint64(100+percent),
+	)
+	bumped.Add(bumped, big.NewInt(99))
+	return bumped.Div(bumped, big.NewInt(100))
+}
+
+// feeSchedule escalates the fees at which the txs of a single Send are
+// published.
+type feeSchedule interface {
+	// publisher returns a function publishing the tx at the current fees.
+	publisher() func(ctx context.Context) (*types.Transaction, error)
+
+	// bump escalates the fees, returning false if they are already at the
+	// max.
+	bump() bool
+
+	// logCtx returns the current fees as log context.
+	logCtx() []interface{}
+}
+
+// legacySchedule escalates the gas price of legacy txs.
+type legacySchedule struct {
+	cfg      *Config
+	gasPrice *big.Int
+	sendTx   SendTxFunc
+}
+
+func (s *legacySchedule) publisher() func(
+	ctx context.Context) (*types.Transaction, error) {
+
+	gasPrice := s.gasPrice
+	return func(ctx context.Context) (*types.Transaction, error) {
+		return s.sendTx(ctx, gasPrice)
+	}
+}
+
+func (s *legacySchedule) bump() bool {
+	if s.gasPrice.Cmp(s.cfg.MaxGasPrice) >= 0 {
+		return false
+	}
+
+	s.gasPrice = BumpGasPrice(
+		s.gasPrice, s.cfg.GasRetryIncrement,
+		s.cfg.FeeBump.GasPricePercent, s.cfg.MaxGasPrice,
+	)
+	return true
+}
+
+func (s *legacySchedule) logCtx() []interface{} {
+	return []interface{}{"gas_price", s.gasPrice}
+}
+
+// dynamicSchedule escalates the tip cap and fee cap of dynamic fee txs.
+type dynamicSchedule struct {
+	cfg    *Config
+	fees   DynamicFees
+	sendTx SendDynamicTxFunc
+}
+
+func (s *dynamicSchedule) publisher() func(
+	ctx context.Context) (*types.Transaction, error) {
+
+	fees := s.fees
+	return func(ctx context.Context) (*types.Transaction, error) {
+		return s.sendTx(ctx, fees)
+	}
+}
+
+func (s *dynamicSchedule) bump() bool {
+	if s.fees.GasFeeCap.Cmp(s.cfg.MaxGasPrice) >= 0 {
+		return false
+	}
+
+	s.fees = BumpDynamicFees(s.fees, s.cfg.FeeBump, s.cfg.MaxGasPrice)
+	return true
+}
+
+func (s *dynamicSchedule) logCtx() []interface{} {
+	return []interface{}{
+		"gas_tip_cap", s.fees.GasTipCap,
+		"gas_fee_cap", s.fees.GasFeeCap,
+	}
+}
@@ -0,0 +1,168 @@
+package txmgr_test
+
+import (
+	"context"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBumpGasPrice asserts that the gas price is bumped by the percentage
+// before adding the increment, rounding up, and is clamped to the max.
+func TestBumpGasPrice(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, big.NewInt(115), txmgr.BumpGasPrice(
+		big.NewInt(100), big.NewInt(5), 10, big.NewInt(200),
+	))
+	require.Equal(t, big.NewInt(12), txmgr.BumpGasPrice(
+		big.NewInt(11), big.NewInt(0), 1, big.NewInt(200),
+	))
+	require.Equal(t, big.NewInt(200), txmgr.BumpGasPrice(
+		big.NewInt(190), big.NewInt(5), 10, big.NewInt(200),
+	))
+}
+
+// TestBumpDynamicFees asserts that the tip cap and fee cap are bumped by their
+// own percentages, defaulting to the 10% accepted by nodes, with the fee cap
+// clamped to the max and the tip cap to the fee cap.
+func TestBumpDynamicFees(t *testing.T) {
+	t.Parallel()
+
+	fees := txmgr.DynamicFees{
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(100),
+	}
+
+	bumped := txmgr.BumpDynamicFees(fees, txmgr.FeeBumpPolicy{
+		FeeCapPercent: 50,
+	}, big.NewInt(1000))
+	require.Equal(t, big.NewInt(11), bumped.GasTipCap)
+	require.Equal(t, big.NewInt(150), bumped.GasFeeCap)
+
+	// The passed fees are not mutated.
+	require.Equal(t, big.NewInt(10), fees.GasTipCap)
+	require.Equal(t, big.NewInt(100), fees.GasFeeCap)
+
+	bumped = txmgr.BumpDynamicFees(fees, txmgr.FeeBumpPolicy{
+		TipCapPercent: 1000,
+	}, big.NewInt(105))
+	require.Equal(t, big.NewInt(105), bumped.GasTipCap)
+	require.Equal(t, big.NewInt(105), bumped.GasFeeCap)
+}
+
+// TestTxMgrMaxFeeBumps asserts that Send gives up once a tx has been replaced
+// the max number of times, counting each bump.
+func TestTxMgrMaxFeeBumps(t *testing.T) {
+	t.Parallel()
+
+	var numBumps int32
+	h := newTestHarnessWithConfig(txmgr.Config{
+		MinGasPrice:          big.NewInt(5),
+		MaxGasPrice:          big.NewInt(50),
+		GasRetryIncrement:    big.NewInt(5),
+		ResubmissionTimeout:  50 * time.Millisecond,
+		ReceiptQueryInterval: 10 * time.Millisecond,
+		FeeBump: txmgr.FeeBumpPolicy{
+			MaxBumps: 2,
+		},
+		OnFeeBump: func() {
+			atomic.AddInt32(&numBumps, 1)
+		},
+	})
+
+	var maxGasPrice uint64
+	sendTxFunc := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		atomic.StoreUint64(&maxGasPrice, gasPrice.Uint64())
+		return types.NewTx(&types.LegacyTx{
+			GasPrice: gasPrice,
+		}), nil
+	}
+
+	_, err := h.mgr.Send(context.Background(), sendTxFunc)
+	require.Equal(t, txmgr.ErrMaxFeeBumps, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&numBumps))
+	require.Equal(t, uint64(15), atomic.LoadUint64(&maxGasPrice))
+}
+
+// TestTxMgrAbortAfter asserts that Send gives up on a tx that is not mined
+// before the abort deadline, even if its fees could still be bumped.
+func TestTxMgrAbortAfter(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarnessWithConfig(txmgr.Config{
+		MinGasPrice:          big.NewInt(5),
+		MaxGasPrice:          big.NewInt(50),
+		GasRetryIncrement:    big.NewInt(5),
+		ResubmissionTimeout:  time.Minute,
+		ReceiptQueryInterval: 10 * time.Millisecond,
+		FeeBump: txmgr.FeeBumpPolicy{
+			AbortAfter: 50 * time.Millisecond,
+		},
+	})
+
+	sendTxFunc := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		return types.NewTx(&types.LegacyTx{
+			GasPrice: gasPrice,
+		}), nil
+	}
+
+	_, err := h.mgr.Send(context.Background(), sendTxFunc)
+	require.Equal(t, txmgr.ErrSendAborted, err)
+}
+
+// TestTxMgrSendDynamic asserts that SendDynamic bumps the tip cap and fee cap of
+// dynamic fee txs until one confirms.
+func TestTxMgrSendDynamic(t *testing.T) {
+	t.Parallel()
+
+	cfg := txmgr.Config{
+		MinGasPrice:          big.NewInt(5),
+		MaxGasPrice:          big.NewInt(500),
+		GasRetryIncrement:    big.NewInt(5),
+		ResubmissionTimeout:  50 * time.Millisecond,
+		ReceiptQueryInterval: 10 * time.Millisecond,
+		FeeBump: txmgr.FeeBumpPolicy{
+			TipCapPercent: 100,
+			FeeCapPercent: 50,
+		},
+	}
+	backend := newMockBackend()
+	mgr := txmgr.NewSimpleTxManager("TEST", cfg, backend)
+
+	sendTxFunc := func(
+		ctx context.Context,
+		fees txmgr.DynamicFees,
+	) (*types.Transaction, error) {
+		tx := types.NewTx(&types.DynamicFeeTx{
+			GasTipCap: fees.GasTipCap,
+			GasFeeCap: fees.GasFeeCap,
+		})
+		if fees.GasTipCap.Cmp(big.NewInt(40)) >= 0 {
+			backend.mine(tx.Hash(), fees.GasFeeCap)
+		}
+		return tx, nil
+	}
+
+	receipt, err := mgr.SendDynamic(context.Background(), txmgr.DynamicFees{
+		GasTipCap: big.NewInt(10),
+		GasFeeCap: big.NewInt(100),
+	}, sendTxFunc)
+	require.Nil(t, err)
+	require.NotNil(t, receipt)
+
+	// The tip cap doubles from 10 to 40 in two bumps, while the fee cap
+	// rises by half each time.
+	require.Equal(t, uint64(225), receipt.GasUsed)
+}
@@ -0,0 +1,160 @@
+package txmgr
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrTxReorged signals that the block including a tx was reorged out, and that
+// the tx is no longer included in the canonical chain.
+var ErrTxReorged = errors.New("tx reorged out of canonical chain")
+
+// ConfirmationSource extends ReceiptSource with canonical header lookups, used
+// to track the confirmation depth of mined txs.
+//
+// NOTE: This is a subset of ethclient.Client.
+type ConfirmationSource interface {
+	ReceiptSource
+
+	// HeaderByNumber returns the canonical header at number, or the latest
+	// header if number is nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header,
+		error)
+}
+
+// WaitConfirmations blocks until the block including receipt has
+// numConfirmations confirmations, counting the including block as the first,
+// and then re-verifies that the block is still canonical. If a reorg dropped
+// the block but the tx was re-included, the depth of the new receipt is awaited
+// instead. If the tx is no longer included at all, ErrTxReorged is returned.
+// The final receipt is returned on success.
+//
+// As with WaitMined, queries are made on each new head if the backend is a
+// HeadSubscriber, and otherwise every queryInterval.
+//
+// onReorg, if non-nil, is invoked for each reorg detected, indicating whether
+// the tx was re-included.
+func WaitConfirmations(
+	ctx context.Context,
+	backend ConfirmationSource,
+	receipt *types.Receipt,
+	numConfirmations uint64,
+	queryInterval time.Duration,
+	onReorg func(reincluded bool),
+) (*types.Receipt, error) {
+
+	if numConfirmations <= 1 || receipt.BlockNumber == nil {
+		return receipt, nil
+	}
+
+	trigger := newQueryTrigger(ctx, backend, queryInterval)
+	defer trigger.Stop()
+
+	// wait blocks until the next query may be made.
+	wait := func() error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-trigger.C:
+			return nil
+		}
+	}
+
+	depth := new(big.Int).SetUint64(numConfirmations - 1)
+	for {
+		targetHeight := new(big.Int).Add(receipt.BlockNumber, depth)
+
+		header, err := backend.HeaderByNumber(ctx, nil)
+		if err != nil {
+			log.Trace("Unable to get L1 head", "err", err)
+		} else if header.Number.Cmp(targetHeight) >= 0 {
+			canonical, err := isCanonical(ctx, backend, receipt)
+			if err != nil {
+				log.Trace("Unable to verify receipt is canonical",
+					"hash", receipt.TxHash, "err", err)
+			} else if canonical {
+				return receipt, nil
+			} else {
+				log.Warn("Reorg detected, tx block no longer "+
+					"canonical", "hash", receipt.TxHash,
+					"block_number", receipt.BlockNumber,
+					"block_hash", receipt.BlockHash)
+
+				receipt, err = reincludedReceipt(
+					ctx, backend, receipt, wait,
+				)
+				if onReorg != nil && ctx.Err() == nil {
+					onReorg(err == nil)
+				}
+				if err != nil {
+					return nil, err
+				}
+				continue
+			}
+		}
+
+		if err := wait(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// isCanonical returns true if the block including receipt is the canonical
+// block at its height.
+func isCanonical(
+	ctx context.Context,
+	backend ConfirmationSource,
+	receipt *types.Receipt,
+) (bool, error) {
+
+	header, err := backend.HeaderByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		return false, err
+	}
+
+	return header.Hash() == receipt.BlockHash, nil
+}
+
+// reincludedReceipt returns the receipt of the tx of a reorged receipt in the
+// new canonical chain, or ErrTxReorged if the tx is no longer included.
+// Queries are retried after calling wait until the backend's answer reflects
+// the reorg.
+func reincludedReceipt(
+	ctx context.Context,
+	backend ConfirmationSource,
+	reorged *types.Receipt,
+	wait func() error,
+) (*types.Receipt, error) {
+
+	for {
+		receipt, err := backend.TransactionReceipt(ctx, reorged.TxHash)
+		switch {
+		case errors.Is(err, ethereum.NotFound),
+			err == nil && receipt == nil:
+			return nil, ErrTxReorged
+
+		case err != nil:
+			log.Trace("Unable to get receipt of reorged tx",
+				"hash", reorged.TxHash, "err", err)
+
+		// The backend may still be indexing the reorged block.
+		case receipt.BlockHash == reorged.BlockHash:
+
+		default:
+			log.Info("Reorged tx re-included", "hash",
+				receipt.TxHash, "block_number", receipt.BlockNumber,
+				"block_hash", receipt.BlockHash)
+			return receipt, nil
+		}
+
+		if err := wait(); err != nil {
+			return nil, err
+		}
+	}
+}
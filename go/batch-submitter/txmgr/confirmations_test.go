@@ -0,0 +1,273 @@
+package txmgr_test
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChain implements txmgr.ConfirmationSource over a chain of
+// headers that can be reorged.
+type mockChain struct {
+	mu       sync.Mutex
+	headers  []*types.Header
+	receipts map[common.Hash]*types.Receipt
+}
+
+// newMockChain initializes a mockChain with numBlocks headers.
+func newMockChain(numBlocks int) *mockChain {
+	c := &mockChain{
+		receipts: make(map[common.Hash]*types.Receipt),
+	}
+	c.extend(numBlocks, 0)
+
+	return c
+}
+
+// extend appends numBlocks headers to the chain, tagging them with fork to
+// distinguish their hashes from those of other forks.
+func (c *mockChain) extend(numBlocks int, fork byte) {
+	for i := 0; i < numBlocks; i++ {
+		c.headers = append(c.headers, &types.Header{
+			Number: big.NewInt(int64(len(c.headers))),
+			Extra:  []byte{fork},
+		})
+	}
+}
+
+// include records a receipt for txHash in the block at height.
+func (c *mockChain) include(txHash common.Hash, height int) *types.Receipt {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	receipt := &types.Receipt{
+		TxHash:      txHash,
+		BlockNumber: big.NewInt(int64(height)),
+		BlockHash:   c.headers[height].Hash(),
+	}
+	c.receipts[txHash] = receipt
+
+	return receipt
+}
+
+// reorg replaces every header from height onwards with numBlocks headers of a
+// new fork, dropping any receipts included in the replaced headers.
+func (c *mockChain) reorg(height, numBlocks int, fork byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.headers = c.headers[:height]
+	c.extend(numBlocks, fork)
+
+	for txHash, receipt := range c.receipts {
+		if receipt.BlockNumber.Int64() >= int64(height) {
+			delete(c.receipts, txHash)
+		}
+	}
+}
+
+func (c *mockChain) HeaderByNumber(
+	ctx context.Context, number *big.Int) (*types.Header, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if number == nil {
+		return c.headers[len(c.headers)-1], nil
+	}
+	if number.Int64() >= int64(len(c.headers)) {
+		return nil, ethereum.NotFound
+	}
+
+	return c.headers[number.Int64()], nil
+}
+
+func (c *mockChain) TransactionReceipt(
+	ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	receipt, ok := c.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+
+	return receipt, nil
+}
+
+// waitConfirmations awaits 3 confirmations of receipt in the background,
+// counting the reorgs detected.
+func waitConfirmations(
+	chain *mockChain,
+	receipt *types.Receipt,
+) (<-chan *types.Receipt, <-chan error, *int32) {
+
+	var (
+		receiptChan = make(chan *types.Receipt, 1)
+		errChan     = make(chan error, 1)
+		reorgs      int32
+	)
+	go func() {
+		receipt, err := txmgr.WaitConfirmations(
+			context.Background(), chain, receipt, 3,
+			10*time.Millisecond, func(bool) { reorgs++ },
+		)
+		receiptChan <- receipt
+		errChan <- err
+	}()
+
+	return receiptChan, errChan, &reorgs
+}
+
+// TestWaitConfirmationsCanonical asserts that the receipt is returned
+// once its block has the required number of confirmations.
+func TestWaitConfirmationsCanonical(t *testing.T) {
+	t.Parallel()
+
+	chain := newMockChain(10)
+	receipt := chain.include(common.Hash{0x01}, 9)
+
+	receiptChan, errChan, _ := waitConfirmations(chain, receipt)
+
+	// The receipt only has one confirmation.
+	select {
+	case <-receiptChan:
+		t.Fatalf("receipt returned before confirmation depth")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	chain.mu.Lock()
+	chain.extend(2, 0)
+	chain.mu.Unlock()
+
+	require.Equal(t, receipt, <-receiptChan)
+	require.Nil(t, <-errChan)
+}
+
+// TestWaitConfirmationsReorgedOut asserts that ErrTxReorged is
+// returned if the tx's block is reorged out and the tx is not re-included.
+func TestWaitConfirmationsReorgedOut(t *testing.T) {
+	t.Parallel()
+
+	chain := newMockChain(10)
+	receipt := chain.include(common.Hash{0x01}, 9)
+	chain.reorg(9, 3, 1)
+
+	receiptChan, errChan, reorgs := waitConfirmations(chain, receipt)
+
+	require.Nil(t, <-receiptChan)
+	require.Equal(t, txmgr.ErrTxReorged, <-errChan)
+	require.Equal(t, int32(1), *reorgs)
+}
+
+// TestWaitConfirmationsReincluded asserts that the confirmation depth
+// of the new receipt is awaited if the tx is re-included after a reorg.
+func TestWaitConfirmationsReincluded(t *testing.T) {
+	t.Parallel()
+
+	chain := newMockChain(10)
+	txHash := common.Hash{0x01}
+	receipt := chain.include(txHash, 9)
+	chain.reorg(9, 3, 1)
+	reincluded := chain.include(txHash, 10)
+
+	receiptChan, errChan, reorgs := waitConfirmations(chain, receipt)
+
+	// The re-included receipt only has two confirmations.
+	select {
+	case <-receiptChan:
+		t.Fatalf("receipt returned before confirmation depth")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	chain.mu.Lock()
+	chain.extend(1, 1)
+	chain.mu.Unlock()
+
+	require.Equal(t, reincluded, <-receiptChan)
+	require.Nil(t, <-errChan)
+	require.Equal(t, int32(1), *reorgs)
+}
+
+// TestTxMgrResumesBumpingAfterReorg asserts that Send holds off bumping while a
+// mined tx awaits its confirmation depth, and resumes bumping if the tx is
+// reorged out beforehand.
+func TestTxMgrResumesBumpingAfterReorg(t *testing.T) {
+	t.Parallel()
+
+	var (
+		chain     = newMockChain(10)
+		mu        sync.Mutex
+		published []*types.Transaction
+		reorgs    []bool
+	)
+	mgr := txmgr.NewSimpleTxManager("TEST", txmgr.Config{
+		MinGasPrice:          big.NewInt(5),
+		MaxGasPrice:          big.NewInt(50),
+		GasRetryIncrement:    big.NewInt(5),
+		ResubmissionTimeout:  100 * time.Millisecond,
+		ReceiptQueryInterval: 10 * time.Millisecond,
+		NumConfirmations:     3,
+		OnReorg: func(reincluded bool) {
+			mu.Lock()
+			defer mu.Unlock()
+			reorgs = append(reorgs, reincluded)
+		},
+	}, chain)
+
+	numPublished := func() int {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(published)
+	}
+
+	sendTx := func(
+		ctx context.Context, gasPrice *big.Int) (*types.Transaction, error) {
+
+		tx := types.NewTx(&types.LegacyTx{GasPrice: gasPrice})
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Mine the first tx with a single confirmation, and bury any
+		// subsequent tx under the required confirmation depth.
+		if len(published) == 0 {
+			chain.include(tx.Hash(), 9)
+		} else {
+			chain.mu.Lock()
+			chain.extend(3, 2)
+			chain.mu.Unlock()
+			chain.include(tx.Hash(), len(chain.headers)-3)
+		}
+		published = append(published, tx)
+
+		return tx, nil
+	}
+
+	receiptChan := make(chan *types.Receipt, 1)
+	go func() {
+		receipt, err := mgr.Send(context.Background(), sendTx)
+		require.Nil(t, err)
+		receiptChan <- receipt
+	}()
+
+	// No bump is published while the mined tx awaits its confirmations.
+	time.Sleep(300 * time.Millisecond)
+	require.Equal(t, 1, numPublished())
+
+	chain.reorg(9, 3, 1)
+
+	receipt := <-receiptChan
+	require.Equal(t, 2, numPublished())
+	require.Equal(t, published[1].Hash(), receipt.TxHash)
+	require.Equal(t, []bool{false}, reorgs)
+}
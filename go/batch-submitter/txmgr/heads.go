@@ -0,0 +1,134 @@
+package txmgr
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// HeadSubscriber is implemented by backends able to push new L1 heads, such as
+// an ethclient.Client dialed over WebSocket or IPC. If a ReceiptSource also
+// implements HeadSubscriber, receipts are checked on each new head rather than
+// once per query interval.
+//
+// NOTE: This is a subset of ethclient.Client.
+type HeadSubscriber interface {
+	// SubscribeNewHead subscribes to notifications about each new head
+	// of the canonical chain.
+	SubscribeNewHead(ctx context.Context,
+		ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// queryTrigger fires whenever a backend should be queried for receipts. If the
+// backend is a HeadSubscriber, it fires on each new head, and otherwise once
+// per query interval. Should the subscription be refused, e.g. by an HTTP-only
+// endpoint, or later fail, the trigger falls back to polling.
+type queryTrigger struct {
+	// C receives a value whenever a query should be made. Heads arriving
+	// faster than they are consumed are coalesced.
+	C <-chan struct{}
+
+	cancel func()
+	wg     sync.WaitGroup
+}
+
+// newQueryTrigger starts a queryTrigger for backend, which runs until Stop is
+// called or ctx is canceled.
+func newQueryTrigger(
+	ctx context.Context,
+	backend interface{},
+	queryInterval time.Duration,
+) *queryTrigger {
+
+	c := make(chan struct{}, 1)
+	ctxc, cancel := context.WithCancel(ctx)
+	t := &queryTrigger{
+		C:      c,
+		cancel: cancel,
+	}
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		if subscriber, ok := backend.(HeadSubscriber); ok {
+			if !watchHeads(ctxc, subscriber, c) {
+				return
+			}
+		}
+		poll(ctxc, queryInterval, c)
+	}()
+
+	return t
+}
+
+// Stop stops the trigger, releasing its subscription or ticker.
+func (t *queryTrigger) Stop() {
+	t.cancel()
+	t.wg.Wait()
+}
+
+// watchHeads fires c on each new head pushed by subscriber until ctx is
+// canceled, in which case false is returned. If the subscription is refused or
+// fails, true is returned so that the caller can fall back to polling.
+func watchHeads(
+	ctx context.Context,
+	subscriber HeadSubscriber,
+	c chan<- struct{},
+) bool {
+
+	heads := make(chan *types.Header, 1)
+	sub, err := subscriber.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		if ctx.Err() != nil {
+			return false
+		}
+		log.Trace("Head subscription unavailable, polling for "+
+			"receipts", "err", err)
+		return true
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case <-heads:
+			fire(c)
+
+		case err := <-sub.Err():
+			log.Warn("Head subscription failed, polling for receipts",
+				"err", err)
+			return true
+
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// poll fires c once per queryInterval until ctx is canceled.
+func poll(ctx context.Context, queryInterval time.Duration, c chan<- struct{}) {
+	ticker := time.NewTicker(queryInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			fire(c)
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// fire signals c without blocking, coalescing with any pending signal.
+func fire(c chan<- struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
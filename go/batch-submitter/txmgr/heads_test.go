@@ -0,0 +1,149 @@
+package txmgr_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/stretchr/testify/require"
+)
+
+// errNotificationsUnsupported mimics the error returned by an HTTP-only
+// endpoint when subscribing.
+var errNotificationsUnsupported = errors.New("notifications not supported")
+
+// subscribingBackend implements txmgr.ReceiptSource and txmgr.HeadSubscriber,
+// returning a receipt once the tx is mined and pushing heads on demand.
+type subscribingBackend struct {
+	mu      sync.Mutex
+	mined   bool
+	queries int
+
+	refuse bool
+	heads  chan *types.Header
+}
+
+func newSubscribingBackend(refuse bool) *subscribingBackend {
+	return &subscribingBackend{
+		refuse: refuse,
+		heads:  make(chan *types.Header),
+	}
+}
+
+// mine causes subsequent receipt queries to return a receipt.
+func (b *subscribingBackend) mine() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.mined = true
+}
+
+// numQueries returns the number of receipt queries made.
+func (b *subscribingBackend) numQueries() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.queries
+}
+
+func (b *subscribingBackend) TransactionReceipt(
+	ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.queries++
+	if !b.mined {
+		return nil, nil
+	}
+	return &types.Receipt{TxHash: txHash}, nil
+}
+
+func (b *subscribingBackend) SubscribeNewHead(ctx context.Context,
+	ch chan<- *types.Header) (ethereum.Subscription, error) {
+
+	if b.refuse {
+		return nil, errNotificationsUnsupported
+	}
+
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		for {
+			select {
+			case head := <-b.heads:
+				select {
+				case ch <- head:
+				case <-quit:
+					return nil
+				}
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// TestWaitMinedQueriesOnNewHeads asserts that WaitMined queries for the receipt
+// on each new head when the backend supports subscriptions, rather than once
+// per query interval.
+func TestWaitMinedQueriesOnNewHeads(t *testing.T) {
+	t.Parallel()
+
+	backend := newSubscribingBackend(false)
+	tx := types.NewTx(&types.LegacyTx{})
+
+	type result struct {
+		receipt *types.Receipt
+		err     error
+	}
+	resultChan := make(chan result, 1)
+	go func() {
+		receipt, err := txmgr.WaitMined(
+			context.Background(), backend, tx, time.Hour,
+		)
+		resultChan <- result{receipt, err}
+	}()
+
+	// The receipt is queried once up front, and again on each head.
+	backend.heads <- &types.Header{}
+	require.Eventually(t, func() bool {
+		return backend.numQueries() == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	backend.mine()
+	backend.heads <- &types.Header{}
+
+	select {
+	case res := <-resultChan:
+		require.Nil(t, res.err)
+		require.Equal(t, tx.Hash(), res.receipt.TxHash)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("receipt not returned after new head")
+	}
+}
+
+// TestWaitMinedFallsBackToPolling asserts that WaitMined polls for the receipt
+// when the backend refuses the head subscription.
+func TestWaitMinedFallsBackToPolling(t *testing.T) {
+	t.Parallel()
+
+	backend := newSubscribingBackend(true)
+	tx := types.NewTx(&types.LegacyTx{})
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		backend.mine()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	receipt, err := txmgr.WaitMined(ctx, backend, tx, 10*time.Millisecond)
+	require.Nil(t, err)
+	require.Equal(t, tx.Hash(), receipt.TxHash)
+	require.Greater(t, backend.numQueries(), 2)
+}
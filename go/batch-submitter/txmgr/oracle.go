@@ -0,0 +1,234 @@
+package txmgr
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+const (
+	// defaultFeeHistoryBlockCount is the number of blocks sampled by a
+	// FeeHistoryGasPriceOracle if none is configured.
+	defaultFeeHistoryBlockCount = 10
+
+	// defaultHTTPOracleTimeout is the maximum duration of a single request
+	// made by an HTTPGasPriceOracle.
+	defaultHTTPOracleTimeout = 5 * time.Second
+)
+
+var (
+	// ErrInvalidFeeHistoryPercentile signals that the configured reward
+	// percentile does not lie within [0, 100].
+	ErrInvalidFeeHistoryPercentile = errors.New("fee history percentile " +
+		"must be between 0 and 100")
+
+	// ErrEmptyFeeHistory signals that the backend returned no base fee for
+	// the pending block.
+	ErrEmptyFeeHistory = errors.New("fee history contains no base fee")
+
+	// ErrOracleFieldNotFound signals that the response of an external
+	// oracle did not contain the configured field.
+	ErrOracleFieldNotFound = errors.New("gas price field not found in " +
+		"oracle response")
+)
+
+// GasPriceOracle provides the initial gas price used by the tx manager before
+// any fee bumping takes place.
+type GasPriceOracle interface {
+	// SuggestGasPrice returns the suggested gas price (in wei) for a tx
+	// that is about to be published.
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// GasPricer is the subset of ethclient.Client used to query the node's
+// eth_gasPrice suggestion.
+type GasPricer interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// NodeGasPriceOracle is a GasPriceOracle that defers to the eth_gasPrice
+// suggestion of the backend.
+type NodeGasPriceOracle struct {
+	backend GasPricer
+}
+
+// NewNodeGasPriceOracle initializes a NodeGasPriceOracle using backend.
+func NewNodeGasPriceOracle(backend GasPricer) *NodeGasPriceOracle {
+	return &NodeGasPriceOracle{
+		backend: backend,
+	}
+}
+
+// SuggestGasPrice returns the backend's eth_gasPrice suggestion.
+func (o *NodeGasPriceOracle) SuggestGasPrice(
+	ctx context.Context) (*big.Int, error) {
+
+	return o.backend.SuggestGasPrice(ctx)
+}
+
+// RPCCaller is the subset of rpc.Client used to issue raw JSON-RPC requests.
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string,
+		args ...interface{}) error
+}
+
+// feeHistory is the response of an eth_feeHistory request.
+type feeHistory struct {
+	Reward        [][]*hexutil.Big `json:"reward"`
+	BaseFeePerGas []*hexutil.Big   `json:"baseFeePerGas"`
+}
+
+// FeeHistoryGasPriceOracle is a GasPriceOracle that estimates the gas price
+// from eth_feeHistory, as the base fee of the pending block plus the median of
+// the priority fees paid at a given percentile over recent blocks.
+type FeeHistoryGasPriceOracle struct {
+	backend    RPCCaller
+	blockCount uint64
+	percentile float64
+}
+
+// NewFeeHistoryGasPriceOracle initializes a FeeHistoryGasPriceOracle sampling
+// the last blockCount blocks at the given reward percentile. If blockCount is
+// zero, defaultFeeHistoryBlockCount is used.
+func NewFeeHistoryGasPriceOracle(
+	backend RPCCaller,
+	blockCount uint64,
+	percentile float64,
+) (*FeeHistoryGasPriceOracle, error) {
+
+	if percentile < 0 || percentile > 100 {
+		return nil, ErrInvalidFeeHistoryPercentile
+	}
+	if blockCount == 0 {
+		blockCount = defaultFeeHistoryBlockCount
+	}
+
+	return &FeeHistoryGasPriceOracle{
+		backend:    backend,
+		blockCount: blockCount,
+		percentile: percentile,
+	}, nil
+}
+
+// SuggestGasPrice returns the estimated gas price derived from the backend's
+// fee history.
+func (o *FeeHistoryGasPriceOracle) SuggestGasPrice(
+	ctx context.Context) (*big.Int, error) {
+
+	var history feeHistory
+	err := o.backend.CallContext(
+		ctx, &history, "eth_feeHistory",
+		hexutil.Uint64(o.blockCount), "latest",
+		[]float64{o.percentile},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return FeeHistoryGasPrice(history.BaseFeePerGas, history.Reward)
+}
+
+// FeeHistoryGasPrice computes a gas price from the results of eth_feeHistory,
+// as the base fee of the pending block, i.e. the last entry of baseFees, plus
+// the median of the first reward percentile of each block. Blocks without any
+// rewards are ignored.
+func FeeHistoryGasPrice(
+	baseFees []*hexutil.Big, rewards [][]*hexutil.Big) (*big.Int, error) {
+
+	if len(baseFees) == 0 || baseFees[len(baseFees)-1] == nil {
+		return nil, ErrEmptyFeeHistory
+	}
+
+	gasPrice := new(big.Int).Set(baseFees[len(baseFees)-1].ToInt())
+
+	var tips []*big.Int
+	for _, blockRewards := range rewards {
+		if len(blockRewards) == 0 || blockRewards[0] == nil {
+			continue
+		}
+		tips = append(tips, blockRewards[0].ToInt())
+	}
+	if len(tips) == 0 {
+		return gasPrice, nil
+	}
+
+	sort.Slice(tips, func(i, j int) bool {
+		return tips[i].Cmp(tips[j]) < 0
+	})
+
+	return gasPrice.Add(gasPrice, tips[len(tips)/2]), nil
+}
+
+// HTTPGasPriceOracle is a GasPriceOracle that queries an external HTTP
+// endpoint returning a JSON object, reading the gas price (in gwei) from a
+// top-level numeric field.
+type HTTPGasPriceOracle struct {
+	client *http.Client
+	url    string
+	field  string
+}
+
+// NewHTTPGasPriceOracle initializes an HTTPGasPriceOracle reading field from
+// the JSON response of url.
+func NewHTTPGasPriceOracle(url, field string) *HTTPGasPriceOracle {
+	return &HTTPGasPriceOracle{
+		client: &http.Client{
+			Timeout: defaultHTTPOracleTimeout,
+		},
+		url:   url,
+		field: field,
+	}
+}
+
+// SuggestGasPrice returns the gas price reported by the external oracle.
+func (o *HTTPGasPriceOracle) SuggestGasPrice(
+	ctx context.Context) (*big.Int, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, o.url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("gas price oracle returned status %d",
+			resp.StatusCode)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+
+	rawValue, ok := body[o.field]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOracleFieldNotFound, o.field)
+	}
+
+	// Decoding into a json.Number accepts both JSON numbers and numeric
+	// strings, as oracles differ in how they encode prices.
+	var value json.Number
+	if err := json.Unmarshal(rawValue, &value); err != nil {
+		return nil, fmt.Errorf("invalid gas price %s: %w", rawValue, err)
+	}
+
+	gwei, ok := new(big.Float).SetString(value.String())
+	if !ok || gwei.Sign() < 0 {
+		return nil, fmt.Errorf("invalid gas price %s", value)
+	}
+
+	wei, _ := gwei.Mul(gwei, big.NewFloat(1e9)).Int(nil)
+	return wei, nil
+}
@@ -0,0 +1,144 @@
+package txmgr_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/require"
+)
+
+// hexBig is a helper that converts v into a *hexutil.Big.
+func hexBig(v int64) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(v))
+}
+
+// TestFeeHistoryGasPrice asserts that the fee history gas price is the pending
+// base fee plus the median priority fee across the sampled blocks.
+func TestFeeHistoryGasPrice(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		baseFees    []*hexutil.Big
+		rewards     [][]*hexutil.Big
+		expGasPrice *big.Int
+		expErr      error
+	}{
+		{
+			name:     "no base fees",
+			baseFees: nil,
+			expErr:   txmgr.ErrEmptyFeeHistory,
+		},
+		{
+			name:        "no rewards",
+			baseFees:    []*hexutil.Big{hexBig(10), hexBig(12)},
+			expGasPrice: big.NewInt(12),
+		},
+		{
+			name:     "median reward",
+			baseFees: []*hexutil.Big{hexBig(10), hexBig(11), hexBig(12), hexBig(13)},
+			rewards: [][]*hexutil.Big{
+				{hexBig(5)}, {hexBig(1)}, {}, {hexBig(3)},
+			},
+			expGasPrice: big.NewInt(16),
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gasPrice, err := txmgr.FeeHistoryGasPrice(
+				test.baseFees, test.rewards,
+			)
+			require.Equal(t, test.expErr, err)
+			require.Equal(t, test.expGasPrice, gasPrice)
+		})
+	}
+}
+
+// mockRPCCaller is a txmgr.RPCCaller that answers eth_feeHistory requests with
+// a canned response.
+type mockRPCCaller struct {
+	method   string
+	args     []interface{}
+	response string
+}
+
+// CallContext records the request and decodes the canned response into result.
+func (c *mockRPCCaller) CallContext(ctx context.Context, result interface{},
+	method string, args ...interface{}) error {
+
+	c.method = method
+	c.args = args
+	return json.Unmarshal([]byte(c.response), result)
+}
+
+// TestFeeHistoryGasPriceOracle asserts that the oracle requests the configured
+// number of blocks and percentile, and decodes the response.
+func TestFeeHistoryGasPriceOracle(t *testing.T) {
+	t.Parallel()
+
+	_, err := txmgr.NewFeeHistoryGasPriceOracle(&mockRPCCaller{}, 0, 101)
+	require.Equal(t, txmgr.ErrInvalidFeeHistoryPercentile, err)
+
+	caller := &mockRPCCaller{
+		response: `{"baseFeePerGas":["0x64","0xc8"],"reward":[["0xa"]]}`,
+	}
+	oracle, err := txmgr.NewFeeHistoryGasPriceOracle(caller, 4, 60)
+	require.Nil(t, err)
+
+	gasPrice, err := oracle.SuggestGasPrice(context.Background())
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(210), gasPrice)
+	require.Equal(t, "eth_feeHistory", caller.method)
+	require.Equal(t, hexutil.Uint64(4), caller.args[0])
+	require.Equal(t, []float64{60}, caller.args[2])
+}
+
+// TestHTTPGasPriceOracle asserts that the oracle reads the configured field in
+// gwei, whether encoded as a JSON number or a string.
+func TestHTTPGasPriceOracle(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/fail" {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(`{"fast":42.5,"slow":"7","bad":"abc"}`))
+		},
+	))
+	defer server.Close()
+
+	ctx := context.Background()
+
+	gasPrice, err := txmgr.NewHTTPGasPriceOracle(server.URL, "fast").
+		SuggestGasPrice(ctx)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(42_500_000_000), gasPrice)
+
+	gasPrice, err = txmgr.NewHTTPGasPriceOracle(server.URL, "slow").
+		SuggestGasPrice(ctx)
+	require.Nil(t, err)
+	require.Equal(t, big.NewInt(7_000_000_000), gasPrice)
+
+	_, err = txmgr.NewHTTPGasPriceOracle(server.URL, "missing").
+		SuggestGasPrice(ctx)
+	require.True(t, errors.Is(err, txmgr.ErrOracleFieldNotFound))
+
+	_, err = txmgr.NewHTTPGasPriceOracle(server.URL, "bad").
+		SuggestGasPrice(ctx)
+	require.NotNil(t, err)
+
+	_, err = txmgr.NewHTTPGasPriceOracle(server.URL+"/fail", "fast").
+		SuggestGasPrice(ctx)
+	require.NotNil(t, err)
+}
@@ -0,0 +1,328 @@
+package txmgr
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// DefaultPrivateTxMaxBlocks is the number of blocks within which a
+	// relay may include a private tx if none is configured, matching the
+	// default of the Flashbots relay.
+	DefaultPrivateTxMaxBlocks = 25
+
+	// defaultRelayTimeout is the maximum duration of a single request made
+	// to a relay.
+	defaultRelayTimeout = 10 * time.Second
+
+	// flashbotsSignatureHeader is the header carrying the signature of a
+	// relay request, which relays use to attribute and rate limit
+	// submissions.
+	flashbotsSignatureHeader = "X-Flashbots-Signature"
+)
+
+var (
+	// ErrUnknownSubmissionMode signals that txs were configured to be
+	// submitted in an unsupported mode.
+	ErrUnknownSubmissionMode = errors.New("tx submission mode must be one " +
+		"of public, private or bundle")
+
+	// ErrRelaySigningKeyRequired signals that bundles were configured to
+	// be submitted without a key to sign them with.
+	ErrRelaySigningKeyRequired = errors.New("bundle submission requires a " +
+		"relay signing key")
+)
+
+// SubmissionMode determines how signed txs are published to L1.
+type SubmissionMode string
+
+const (
+	// SubmissionModePublic broadcasts txs to the public mempool of the L1
+	// provider.
+	SubmissionModePublic SubmissionMode = "public"
+
+	// SubmissionModePrivate submits txs to a relay using
+	// eth_sendPrivateTransaction, which keeps them out of the public
+	// mempool until included.
+	SubmissionModePrivate SubmissionMode = "private"
+
+	// SubmissionModeBundle submits each tx to a relay as a single-tx bundle
+	// using eth_sendBundle, targeting the next block only.
+	SubmissionModeBundle SubmissionMode = "bundle"
+)
+
+// ParseSubmissionMode parses a SubmissionMode from its name, defaulting to
+// SubmissionModePublic if name is empty.
+func ParseSubmissionMode(name string) (SubmissionMode, error) {
+	switch mode := SubmissionMode(name); mode {
+	case "":
+		return SubmissionModePublic, nil
+	case SubmissionModePublic, SubmissionModePrivate, SubmissionModeBundle:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnknownSubmissionMode, name)
+	}
+}
+
+// Publisher publishes signed txs to L1.
+//
+// NOTE: This is a subset of ethclient.Client.
+type Publisher interface {
+	// SendTransaction publishes tx.
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+}
+
+// Rebroadcaster is implemented by Publishers whose submissions only target a
+// limited range of blocks, such as bundles. If the Config of a SimpleTxManager
+// has a Rebroadcaster, each published tx is resubmitted on every new L1 head
+// until it is mined.
+type Rebroadcaster interface {
+	// Rebroadcast resubmits tx, targeting the blocks following head.
+	Rebroadcast(ctx context.Context, tx *types.Transaction,
+		head *types.Header) error
+}
+
+// HeaderSource is used by a RelayPublisher to determine the blocks targeted by
+// a submission.
+//
+// NOTE: This is a subset of ethclient.Client.
+type HeaderSource interface {
+	// HeaderByNumber returns the canonical header at number, or the latest
+	// header if number is nil.
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header,
+		error)
+}
+
+// RelayConfig houses parameters for altering the behavior of a RelayPublisher.
+type RelayConfig struct {
+	// Mode is either SubmissionModePrivate or SubmissionModeBundle.
+	Mode SubmissionMode
+
+	// URL is the JSON-RPC endpoint of the relay.
+	URL string
+
+	// SigningKey, if non-nil, signs each request in the
+	// X-Flashbots-Signature header. It identifies the submitter to the
+	// relay, and need not, and should not, hold any funds. It is required
+	// to submit bundles.
+	SigningKey *ecdsa.PrivateKey
+
+	// MaxBlocks is the number of blocks within which a private tx may be
+	// included, after which the relay drops it. If zero,
+	// DefaultPrivateTxMaxBlocks is used.
+	MaxBlocks uint64
+
+	// Timeout bounds each request made to the relay. If zero,
+	// defaultRelayTimeout is used.
+	Timeout time.Duration
+}
+
+// RelayPublisher is a Publisher and Rebroadcaster that submits txs to a private
+// relay rather than the public mempool, such that they cannot be observed and
+// front-run, or outbid to grief their inclusion, before being mined.
+type RelayPublisher struct {
+	cfg     RelayConfig
+	backend HeaderSource
+	client  *http.Client
+
+	// nextID is the ID of the next JSON-RPC request.
+	//
+	// NOTE: This field MUST be accessed atomically.
+	nextID uint64
+}
+
+// NewRelayPublisher initializes a RelayPublisher using cfg, querying backend for
+// the latest L1 head upon each initial submission.
+func NewRelayPublisher(
+	cfg RelayConfig,
+	backend HeaderSource,
+) (*RelayPublisher, error) {
+
+	switch cfg.Mode {
+	case SubmissionModePrivate:
+	case SubmissionModeBundle:
+		if cfg.SigningKey == nil {
+			return nil, ErrRelaySigningKeyRequired
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSubmissionMode,
+			cfg.Mode)
+	}
+	if cfg.MaxBlocks == 0 {
+		cfg.MaxBlocks = DefaultPrivateTxMaxBlocks
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = defaultRelayTimeout
+	}
+
+	return &RelayPublisher{
+		cfg:     cfg,
+		backend: backend,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}, nil
+}
+
+// SendTransaction submits tx to the relay, targeting the blocks following the
+// latest L1 head.
+func (p *RelayPublisher) SendTransaction(
+	ctx context.Context, tx *types.Transaction) error {
+
+	head, err := p.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	return p.Rebroadcast(ctx, tx, head)
+}
+
+// Rebroadcast resubmits tx to the relay, targeting the blocks following head. A
+// private tx already known to the relay has its max block number extended,
+// while a bundle targets the next block in its place.
+func (p *RelayPublisher) Rebroadcast(
+	ctx context.Context,
+	tx *types.Transaction,
+	head *types.Header,
+) error {
+
+	rawTx, err := tx.MarshalBinary()
+	if err != nil {
+		return err
+	}
+	number := head.Number.Uint64()
+
+	switch p.cfg.Mode {
+	case SubmissionModeBundle:
+		return p.call(ctx, "eth_sendBundle", sendBundleArgs{
+			Txs:         []hexutil.Bytes{rawTx},
+			BlockNumber: hexutil.Uint64(number + 1),
+		})
+
+	default:
+		return p.call(ctx, "eth_sendPrivateTransaction",
+			sendPrivateTransactionArgs{
+				Tx:             rawTx,
+				MaxBlockNumber: hexutil.Uint64(number + p.cfg.MaxBlocks),
+			},
+		)
+	}
+}
+
+// sendBundleArgs are the parameters of an eth_sendBundle request.
+type sendBundleArgs struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}
+
+// sendPrivateTransactionArgs are the parameters of an
+// eth_sendPrivateTransaction request.
+type sendPrivateTransactionArgs struct {
+	Tx             hexutil.Bytes  `json:"tx"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber"`
+}
+
+// relayRequest is a JSON-RPC request made to a relay.
+type relayRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+// relayResponse is the JSON-RPC response of a relay, whose result is ignored.
+type relayResponse struct {
+	Error *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// call issues a JSON-RPC request for method to the relay, signing it if a
+// SigningKey is configured.
+func (p *RelayPublisher) call(
+	ctx context.Context,
+	method string,
+	params interface{},
+) error {
+
+	body, err := json.Marshal(relayRequest{
+		JSONRPC: "2.0",
+		ID:      atomic.AddUint64(&p.nextID, 1),
+		Method:  method,
+		Params:  []interface{}{params},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx, http.MethodPost, p.cfg.URL, bytes.NewReader(body),
+	)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.cfg.SigningKey != nil {
+		signature, err := SignRelayRequest(p.cfg.SigningKey, body)
+		if err != nil {
+			return err
+		}
+		req.Header.Set(flashbotsSignatureHeader, signature)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("relay %s request failed: %s", method,
+			resp.Status)
+	}
+
+	var result relayResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+	if result.Error != nil {
+		return fmt.Errorf("relay %s request failed: %s (code %d)",
+			method, result.Error.Message, result.Error.Code)
+	}
+
+	return nil
+}
+
+// SignRelayRequest returns the X-Flashbots-Signature header of a relay request
+// with the given body, formatted as the address of key followed by its
+// signature of the hex-encoded keccak256 hash of body, as an EIP-191 message.
+func SignRelayRequest(key *ecdsa.PrivateKey, body []byte) (string, error) {
+	hash := crypto.Keccak256Hash(body).Hex()
+	signature, err := crypto.Sign(accounts.TextHash([]byte(hash)), key)
+	if err != nil {
+		return "", err
+	}
+
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	return address.Hex() + ":" + hexutil.Encode(signature), nil
+}
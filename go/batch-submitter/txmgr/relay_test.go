@@ -0,0 +1,295 @@
+package txmgr_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// relayCall is a request received by a test relay.
+type relayCall struct {
+	Method string
+	Params []map[string]interface{}
+
+	// Signer is the address recovered from the request's signature, or
+	// the zero address if the request was unsigned.
+	Signer common.Address
+}
+
+// newTestRelay serves a relay recording each request, and replying with
+// errMessage as a JSON-RPC error if it is non-empty.
+func newTestRelay(
+	t *testing.T, errMessage string) (*httptest.Server, <-chan relayCall) {
+
+	calls := make(chan relayCall, 16)
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, req *http.Request) {
+			body, err := io.ReadAll(req.Body)
+			require.Nil(t, err)
+
+			var call relayCall
+			require.Nil(t, json.Unmarshal(body, &call))
+			call.Signer = recoverRelaySigner(t, req, body)
+			calls <- call
+
+			if errMessage != "" {
+				_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,` +
+					`"error":{"code":-32000,"message":"` +
+					errMessage + `"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,` +
+				`"result":null}`))
+		},
+	))
+	t.Cleanup(server.Close)
+
+	return server, calls
+}
+
+// recoverRelaySigner returns the address that signed the X-Flashbots-Signature
+// header of req, asserting that it matches the claimed address.
+func recoverRelaySigner(
+	t *testing.T, req *http.Request, body []byte) common.Address {
+
+	header := req.Header.Get("X-Flashbots-Signature")
+	if header == "" {
+		return common.Address{}
+	}
+
+	parts := strings.Split(header, ":")
+	require.Len(t, parts, 2)
+	signature, err := hexutil.Decode(parts[1])
+	require.Nil(t, err)
+
+	hash := crypto.Keccak256Hash(body).Hex()
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(hash)), signature)
+	require.Nil(t, err)
+
+	signer := crypto.PubkeyToAddress(*pubKey)
+	require.Equal(t, common.HexToAddress(parts[0]), signer)
+
+	return signer
+}
+
+// headBackend is a txmgr.HeaderSource whose head advances by one block on each
+// query.
+type headBackend struct {
+	*mockBackend
+
+	mu   sync.Mutex
+	head uint64
+}
+
+// HeaderByNumber returns the latest header, advancing the head.
+func (b *headBackend) HeaderByNumber(
+	ctx context.Context, number *big.Int) (*types.Header, error) {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.head++
+	return &types.Header{
+		Number: new(big.Int).SetUint64(b.head),
+	}, nil
+}
+
+// newRelayTestTx returns a legacy tx along with its binary encoding.
+func newRelayTestTx(t *testing.T) (*types.Transaction, string) {
+	tx := types.NewTx(&types.LegacyTx{
+		Nonce:    1,
+		GasPrice: big.NewInt(5),
+	})
+	rawTx, err := tx.MarshalBinary()
+	require.Nil(t, err)
+
+	return tx, hexutil.Encode(rawTx)
+}
+
+// TestRelayPublisherPrivateTx asserts that a private tx is submitted with
+// eth_sendPrivateTransaction, allowing the relay MaxBlocks blocks past the
+// latest head to include it.
+func TestRelayPublisherPrivateTx(t *testing.T) {
+	t.Parallel()
+
+	server, calls := newTestRelay(t, "")
+	publisher, err := txmgr.NewRelayPublisher(txmgr.RelayConfig{
+		Mode:      txmgr.SubmissionModePrivate,
+		URL:       server.URL,
+		MaxBlocks: 10,
+	}, &headBackend{head: 99})
+	require.Nil(t, err)
+
+	tx, rawTx := newRelayTestTx(t)
+	require.Nil(t, publisher.SendTransaction(context.Background(), tx))
+
+	call := <-calls
+	require.Equal(t, "eth_sendPrivateTransaction", call.Method)
+	require.Equal(t, []map[string]interface{}{{
+		"tx":             rawTx,
+		"maxBlockNumber": "0x6e",
+	}}, call.Params)
+	require.Equal(t, common.Address{}, call.Signer)
+}
+
+// TestRelayPublisherBundle asserts that a bundle is submitted with
+// eth_sendBundle targeting the block following the head, and is signed with
+// the configured signing key.
+func TestRelayPublisherBundle(t *testing.T) {
+	t.Parallel()
+
+	signingKey, err := crypto.GenerateKey()
+	require.Nil(t, err)
+
+	server, calls := newTestRelay(t, "")
+	publisher, err := txmgr.NewRelayPublisher(txmgr.RelayConfig{
+		Mode:       txmgr.SubmissionModeBundle,
+		URL:        server.URL,
+		SigningKey: signingKey,
+	}, &headBackend{})
+	require.Nil(t, err)
+
+	tx, rawTx := newRelayTestTx(t)
+	head := &types.Header{Number: big.NewInt(41)}
+	require.Nil(t, publisher.Rebroadcast(context.Background(), tx, head))
+
+	call := <-calls
+	require.Equal(t, "eth_sendBundle", call.Method)
+	require.Equal(t, []map[string]interface{}{{
+		"txs":         []interface{}{rawTx},
+		"blockNumber": "0x2a",
+	}}, call.Params)
+	require.Equal(t, crypto.PubkeyToAddress(signingKey.PublicKey),
+		call.Signer)
+}
+
+// TestRelayPublisherError asserts that a submission rejected by the relay
+// returns the relay's error.
+func TestRelayPublisherError(t *testing.T) {
+	t.Parallel()
+
+	server, _ := newTestRelay(t, "bundle simulation failed")
+	publisher, err := txmgr.NewRelayPublisher(txmgr.RelayConfig{
+		Mode: txmgr.SubmissionModePrivate,
+		URL:  server.URL,
+	}, &headBackend{})
+	require.Nil(t, err)
+
+	tx, _ := newRelayTestTx(t)
+	err = publisher.SendTransaction(context.Background(), tx)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "bundle simulation failed")
+}
+
+// TestNewRelayPublisherRequiresSigningKey asserts that bundles cannot be
+// submitted without a signing key.
+func TestNewRelayPublisherRequiresSigningKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := txmgr.NewRelayPublisher(txmgr.RelayConfig{
+		Mode: txmgr.SubmissionModeBundle,
+		URL:  "http://localhost",
+	}, &headBackend{})
+	require.Equal(t, txmgr.ErrRelaySigningKeyRequired, err)
+
+	_, err = txmgr.NewRelayPublisher(txmgr.RelayConfig{
+		Mode: txmgr.SubmissionModePublic,
+	}, &headBackend{})
+	require.ErrorIs(t, err, txmgr.ErrUnknownSubmissionMode)
+}
+
+// mockRebroadcaster records the heads at which txs are rebroadcast.
+type mockRebroadcaster struct {
+	mu    sync.Mutex
+	heads []uint64
+}
+
+// Rebroadcast records head.
+func (r *mockRebroadcaster) Rebroadcast(
+	ctx context.Context, tx *types.Transaction, head *types.Header) error {
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.heads = append(r.heads, head.Number.Uint64())
+	return nil
+}
+
+// Heads returns the heads rebroadcast at so far.
+func (r *mockRebroadcaster) Heads() []uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]uint64(nil), r.heads...)
+}
+
+// TestTxMgrRebroadcastsEachHead asserts that a published tx is rebroadcast on
+// each new head until it is mined, and no longer once Send returns.
+func TestTxMgrRebroadcastsEachHead(t *testing.T) {
+	t.Parallel()
+
+	rebroadcaster := &mockRebroadcaster{}
+	backend := &headBackend{mockBackend: newMockBackend()}
+	mgr := txmgr.NewSimpleTxManager("TEST", txmgr.Config{
+		MinGasPrice:          new(big.Int).SetUint64(5),
+		MaxGasPrice:          new(big.Int).SetUint64(50),
+		GasRetryIncrement:    new(big.Int).SetUint64(5),
+		ResubmissionTimeout:  time.Minute,
+		ReceiptQueryInterval: 10 * time.Millisecond,
+		Rebroadcaster:        rebroadcaster,
+	}, backend)
+
+	var (
+		txMu sync.Mutex
+		tx   *types.Transaction
+	)
+	sendTx := func(
+		ctx context.Context, gasPrice *big.Int) (*types.Transaction, error) {
+
+		txMu.Lock()
+		defer txMu.Unlock()
+
+		tx = types.NewTx(&types.LegacyTx{
+			GasPrice: gasPrice,
+		})
+		return tx, nil
+	}
+
+	// Mine the tx once it has been rebroadcast at a few heads.
+	go func() {
+		for len(rebroadcaster.Heads()) < 3 {
+			time.Sleep(time.Millisecond)
+		}
+		txMu.Lock()
+		defer txMu.Unlock()
+		backend.mine(tx.Hash(), tx.GasPrice())
+	}()
+
+	receipt, err := mgr.Send(context.Background(), sendTx)
+	require.Nil(t, err)
+	require.NotNil(t, receipt)
+
+	heads := rebroadcaster.Heads()
+	require.GreaterOrEqual(t, len(heads), 3)
+	for i := 1; i < len(heads); i++ {
+		require.Greater(t, heads[i], heads[i-1])
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	require.Equal(t, heads, rebroadcaster.Heads())
+}
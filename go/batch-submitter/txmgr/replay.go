@@ -0,0 +1,281 @@
+package txmgr
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// replayIntervalTolerance is the slack allowed when checking the interval
+// between attempts against the ResubmissionTimeout, absorbing the delay
+// between the send loop bumping the gas price and the tx being published.
+const replayIntervalTolerance = time.Second
+
+// ReplayPolicy is the fee escalation policy against which a send loop is
+// replayed, mirroring the corresponding fields of Config.
+type ReplayPolicy struct {
+	MinGasPrice         *big.Int
+	MaxGasPrice         *big.Int
+	GasRetryIncrement   *big.Int
+	GasPricePercent     uint64
+	ResubmissionTimeout time.Duration
+
+	// UsesOracle is true if the initial gas price was suggested by a
+	// GasPriceOracle, in which case it may exceed MinGasPrice.
+	UsesOracle bool
+}
+
+// Attempt is a single tx published by the send loop.
+type Attempt struct {
+	TxHash      common.Hash
+	GasPrice    *big.Int
+	PublishedAt time.Time
+}
+
+// BlockFee is the base fee of a single L1 block. BaseFee is nil for blocks
+// preceding London.
+type BlockFee struct {
+	Number  uint64
+	Time    time.Time
+	BaseFee *big.Int
+}
+
+// ReplayStep is the replayed outcome of a single attempt, covering the L1
+// blocks mined while it was the latest attempt.
+type ReplayStep struct {
+	Attempt
+
+	// Interval is the time elapsed since the previous attempt.
+	Interval time.Duration
+
+	// Bumps is the number of gas price increments separating the attempt
+	// from the previous attempt.
+	Bumps uint64
+
+	// NumBlocks is the number of L1 blocks mined while the attempt was the
+	// latest.
+	NumBlocks int
+
+	// NumUnderpriced is the number of those blocks whose base fee exceeded
+	// the attempt's gas price, such that it could not have been included.
+	NumUnderpriced int
+
+	// MaxBaseFee is the highest base fee of those blocks, or nil if none
+	// had a base fee.
+	MaxBaseFee *big.Int
+
+	// Violations describes each way in which the attempt deviates from
+	// the policy.
+	Violations []string
+}
+
+// ReplayReport is the fee escalation timeline of a send loop reconstructed by
+// Replay.
+type ReplayReport struct {
+	Steps []ReplayStep
+
+	// FirstCompetitive is the index of the first attempt whose gas price
+	// covered the base fee of every block mined while it was the latest,
+	// or -1 if there is none.
+	FirstCompetitive int
+}
+
+// Conforms returns true if every attempt followed the policy.
+func (r *ReplayReport) Conforms() bool {
+	for _, step := range r.Steps {
+		if len(step.Violations) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Replay deterministically replays the send loop that published attempts
+// against policy, reconstructing its fee escalation timeline against the
+// historical base fees of the L1 blocks in fees, up to and including end. Each
+// attempt is checked to have been published within the gas price bounds, no
+// sooner than a ResubmissionTimeout after the previous one, and bumped by a
+// whole number of increments that the elapsed time allows. More than one
+// increment may separate consecutive attempts, as publications that failed
+// before reaching the network are not recorded. The attempts and fees must be
+// ordered by time.
+func Replay(
+	policy ReplayPolicy,
+	attempts []Attempt,
+	fees []BlockFee,
+	end time.Time,
+) *ReplayReport {
+
+	report := &ReplayReport{
+		Steps:            make([]ReplayStep, 0, len(attempts)),
+		FirstCompetitive: -1,
+	}
+	for i, attempt := range attempts {
+		step := ReplayStep{Attempt: attempt}
+		violation := func(format string, args ...interface{}) {
+			step.Violations = append(step.Violations,
+				fmt.Sprintf(format, args...))
+		}
+
+		gasPrice := attempt.GasPrice
+		if gasPrice.Cmp(policy.MaxGasPrice) > 0 {
+			violation("gas price %v exceeds the max gas price %v",
+				gasPrice, policy.MaxGasPrice)
+		}
+		if i == 0 {
+			switch {
+			case gasPrice.Cmp(policy.MinGasPrice) < 0:
+				violation("initial gas price %v is below the min "+
+					"gas price %v", gasPrice, policy.MinGasPrice)
+			case !policy.UsesOracle &&
+				gasPrice.Cmp(policy.MinGasPrice) != 0:
+				violation("initial gas price %v differs from the "+
+					"min gas price %v without an oracle",
+					gasPrice, policy.MinGasPrice)
+			}
+		} else {
+			prev := attempts[i-1]
+			step.Interval = attempt.PublishedAt.Sub(prev.PublishedAt)
+			replayBump(policy, prev.GasPrice, &step, violation)
+		}
+
+		windowEnd := end
+		if i+1 < len(attempts) {
+			windowEnd = attempts[i+1].PublishedAt
+		}
+		for _, fee := range fees {
+			if fee.Time.Before(attempt.PublishedAt) ||
+				fee.Time.After(windowEnd) ||
+				(i+1 < len(attempts) && fee.Time.Equal(windowEnd)) {
+				continue
+			}
+			step.NumBlocks++
+			if fee.BaseFee == nil {
+				continue
+			}
+			if fee.BaseFee.Cmp(gasPrice) > 0 {
+				step.NumUnderpriced++
+			}
+			if step.MaxBaseFee == nil || fee.BaseFee.Cmp(step.MaxBaseFee) > 0 {
+				step.MaxBaseFee = fee.BaseFee
+			}
+		}
+		if report.FirstCompetitive < 0 && step.NumBlocks > 0 &&
+			step.NumUnderpriced == 0 {
+			report.FirstCompetitive = i
+		}
+
+		report.Steps = append(report.Steps, step)
+	}
+
+	return report
+}
+
+// replayBump checks the bump from prevGasPrice to the gas price of step against
+// policy, recording the number of increments in step.
+func replayBump(
+	policy ReplayPolicy,
+	prevGasPrice *big.Int,
+	step *ReplayStep,
+	violation func(format string, args ...interface{}),
+) {
+
+	gasPrice := step.GasPrice
+	timeout := policy.ResubmissionTimeout
+	if step.Interval+replayIntervalTolerance < timeout {
+		violation("bumped after %v, before the resubmission timeout "+
+			"of %v", step.Interval, timeout)
+	}
+	if prevGasPrice.Cmp(policy.MaxGasPrice) >= 0 {
+		violation("republished after reaching the max gas price %v",
+			policy.MaxGasPrice)
+		return
+	}
+
+	diff := new(big.Int).Sub(gasPrice, prevGasPrice)
+	incr := policy.GasRetryIncrement
+	if diff.Sign() <= 0 ||
+		(incr.Sign() <= 0 && policy.GasPricePercent == 0) {
+
+		violation("gas price %v is not bumped from %v", gasPrice,
+			prevGasPrice)
+		return
+	}
+
+	// Percentage bumps compound, and are therefore counted by replaying
+	// them.
+	if policy.GasPricePercent > 0 {
+		bumps, ok := countPercentBumps(policy, prevGasPrice, gasPrice)
+		if !ok {
+			violation("gas price %v is not reachable from %v in "+
+				"bumps of %d%% plus %v", gasPrice, prevGasPrice,
+				policy.GasPricePercent, incr)
+			return
+		}
+		step.Bumps = bumps
+		checkBumpRate(policy, step, violation)
+		return
+	}
+
+	// The final bump may be clamped to the max gas price, and is
+	// therefore rounded up.
+	bumps, rem := new(big.Int).QuoRem(diff, incr, new(big.Int))
+	if rem.Sign() != 0 {
+		if gasPrice.Cmp(policy.MaxGasPrice) != 0 {
+			violation("gas price %v is not reachable from %v in "+
+				"increments of %v", gasPrice, prevGasPrice, incr)
+			return
+		}
+		bumps.Add(bumps, big.NewInt(1))
+	}
+	step.Bumps = bumps.Uint64()
+	checkBumpRate(policy, step, violation)
+}
+
+// countPercentBumps returns the number of bumps of policy taking prevGasPrice
+// to gasPrice, or false if gasPrice is not reached exactly.
+func countPercentBumps(
+	policy ReplayPolicy,
+	prevGasPrice, gasPrice *big.Int,
+) (uint64, bool) {
+
+	var bumps uint64
+	for cur := prevGasPrice; cur.Cmp(gasPrice) < 0; bumps++ {
+		if cur.Cmp(policy.MaxGasPrice) >= 0 {
+			return 0, false
+		}
+		cur = BumpGasPrice(
+			cur, policy.GasRetryIncrement, policy.GasPricePercent,
+			policy.MaxGasPrice,
+		)
+		if cur.Cmp(gasPrice) > 0 {
+			return 0, false
+		}
+	}
+
+	return bumps, true
+}
+
+// checkBumpRate checks that the bumps of step are no more than the elapsed
+// resubmission timeouts of policy allow.
+func checkBumpRate(
+	policy ReplayPolicy,
+	step *ReplayStep,
+	violation func(format string, args ...interface{}),
+) {
+
+	timeout := policy.ResubmissionTimeout
+	allowed := uint64(1)
+	if timeout > 0 {
+		n := uint64((step.Interval + replayIntervalTolerance) / timeout)
+		if n > allowed {
+			allowed = n
+		}
+	}
+	if step.Bumps > allowed {
+		violation("bumped %d times in %v, more than the resubmission "+
+			"timeout of %v allows", step.Bumps, step.Interval, timeout)
+	}
+}
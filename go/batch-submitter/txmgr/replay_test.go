@@ -0,0 +1,164 @@
+package txmgr_test
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/stretchr/testify/require"
+)
+
+// testReplayPolicy starts at 10 wei, bumping by 10 wei every minute up to a max
+// of 45 wei.
+var testReplayPolicy = txmgr.ReplayPolicy{
+	MinGasPrice:         big.NewInt(10),
+	MaxGasPrice:         big.NewInt(45),
+	GasRetryIncrement:   big.NewInt(10),
+	ResubmissionTimeout: time.Minute,
+}
+
+// newReplayAttempts creates an attempt at each of the given gas prices,
+// published at the given offsets from the zero time.
+func newReplayAttempts(
+	gasPrices []int64,
+	offsets []time.Duration,
+) []txmgr.Attempt {
+
+	attempts := make([]txmgr.Attempt, 0, len(gasPrices))
+	for i, gasPrice := range gasPrices {
+		attempts = append(attempts, txmgr.Attempt{
+			GasPrice:    big.NewInt(gasPrice),
+			PublishedAt: time.Unix(0, 0).Add(offsets[i]),
+		})
+	}
+	return attempts
+}
+
+// TestReplayViolations asserts that Replay accepts a send loop following the
+// policy, including bumps clamped to the max gas price or spanning failed
+// publications, and flags each deviation.
+func TestReplayViolations(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		gasPrices     []int64
+		offsets       []time.Duration
+		expBumps      []uint64
+		expViolations []int
+	}{
+		{
+			name:      "conforming",
+			gasPrices: []int64{10, 20, 30, 40, 45},
+			offsets: []time.Duration{
+				0, time.Minute, 2 * time.Minute,
+				3 * time.Minute, 4 * time.Minute,
+			},
+			expBumps:      []uint64{0, 1, 1, 1, 1},
+			expViolations: []int{0, 0, 0, 0, 0},
+		},
+		{
+			name:          "failed publication",
+			gasPrices:     []int64{10, 30},
+			offsets:       []time.Duration{0, 2 * time.Minute},
+			expBumps:      []uint64{0, 2},
+			expViolations: []int{0, 0},
+		},
+		{
+			name:          "bumped early",
+			gasPrices:     []int64{10, 20},
+			offsets:       []time.Duration{0, 30 * time.Second},
+			expBumps:      []uint64{0, 1},
+			expViolations: []int{0, 1},
+		},
+		{
+			name:          "bumped too far",
+			gasPrices:     []int64{10, 40},
+			offsets:       []time.Duration{0, time.Minute},
+			expBumps:      []uint64{0, 3},
+			expViolations: []int{0, 1},
+		},
+		{
+			name:          "unreachable gas price",
+			gasPrices:     []int64{10, 25},
+			offsets:       []time.Duration{0, time.Minute},
+			expBumps:      []uint64{0, 0},
+			expViolations: []int{0, 1},
+		},
+		{
+			name:          "initial gas price without oracle",
+			gasPrices:     []int64{15, 25},
+			offsets:       []time.Duration{0, time.Minute},
+			expBumps:      []uint64{0, 1},
+			expViolations: []int{1, 0},
+		},
+		{
+			name:          "republished at max",
+			gasPrices:     []int64{40, 45, 50},
+			offsets:       []time.Duration{0, time.Minute, 2 * time.Minute},
+			expBumps:      []uint64{0, 1, 0},
+			expViolations: []int{1, 0, 2},
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			attempts := newReplayAttempts(test.gasPrices, test.offsets)
+			report := txmgr.Replay(
+				testReplayPolicy, attempts, nil, time.Unix(0, 0),
+			)
+
+			require.Len(t, report.Steps, len(attempts))
+			conforms := true
+			for i, step := range report.Steps {
+				require.Equal(t, test.expBumps[i], step.Bumps)
+				require.Len(t, step.Violations,
+					test.expViolations[i], step.Violations)
+				if test.expViolations[i] > 0 {
+					conforms = false
+				}
+			}
+			require.Equal(t, conforms, report.Conforms())
+		})
+	}
+}
+
+// TestReplayBaseFees asserts that the blocks mined while each attempt was the
+// latest are attributed to it, and that the first attempt covering the base
+// fee of each of its blocks is reported as competitive.
+func TestReplayBaseFees(t *testing.T) {
+	t.Parallel()
+
+	attempts := newReplayAttempts(
+		[]int64{10, 20, 30},
+		[]time.Duration{0, time.Minute, 2 * time.Minute},
+	)
+
+	// A block every 20s from the first attempt, with a base fee of 25 wei.
+	var fees []txmgr.BlockFee
+	for i := 0; i < 9; i++ {
+		fees = append(fees, txmgr.BlockFee{
+			Number:  uint64(i),
+			Time:    time.Unix(int64(20*i), 0),
+			BaseFee: big.NewInt(25),
+		})
+	}
+
+	report := txmgr.Replay(
+		testReplayPolicy, attempts, fees, time.Unix(160, 0),
+	)
+	require.True(t, report.Conforms())
+	require.Equal(t, 2, report.FirstCompetitive)
+
+	for i, step := range report.Steps {
+		require.Equal(t, 3, step.NumBlocks)
+		require.Equal(t, big.NewInt(25), step.MaxBaseFee)
+		if i < 2 {
+			require.Equal(t, 3, step.NumUnderpriced)
+		} else {
+			require.Equal(t, 0, step.NumUnderpriced)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"math/big"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -18,9 +19,30 @@ import (
 // resubmission timeout.
 var ErrPublishTimeout = errors.New("failed to publish tx with max gas price")
 
+// ErrFeeCeilingReached signals that a SendTxFunc declined to publish a tx at
+// the requested gas price, as its fee would exceed the caller's ceiling. Once
+// returned, Send no longer bumps the gas price of the tx.
+var ErrFeeCeilingReached = errors.New("tx fee exceeds fee ceiling")
+
+// ErrSendDeferred signals that a SendTxFunc declined to publish a tx that would
+// revert until the L1 state it depends on exists, e.g. as it references queue
+// elements yet to be enqueued. Like ErrFeeCeilingReached, once returned Send no
+// longer bumps the gas price of the tx.
+var ErrSendDeferred = errors.New("tx deferred until its l1 state exists")
+
+// ErrMaxFeeBumps signals that the tx manager did not receive a confirmation for
+// a given tx after replacing it FeeBump.MaxBumps times and waiting out a
+// resubmission timeout.
+var ErrMaxFeeBumps = errors.New("failed to publish tx within max fee bumps")
+
+// ErrSendAborted signals that the tx manager gave up on a given tx after
+// FeeBump.AbortAfter elapsed.
+var ErrSendAborted = errors.New("tx not mined before abort deadline")
+
 // SendTxFunc defines a function signature for publishing a desired tx with a
 // specific gas price. Implementations of this signature should also return
-// promptly when the context is canceled.
+// promptly when the context is canceled, and may return an error wrapping
+// ErrFeeCeilingReached or ErrSendDeferred to stop further fee bumping.
 type SendTxFunc = func(
 	ctx context.Context, gasPrice *big.Int) (*types.Transaction, error)
 
@@ -29,7 +51,8 @@ type Config struct {
 	Name string
 
 	// MinGasPrice is the minimum gas price (in gwei). This is used as the
-	// initial publication attempt.
+	// initial publication attempt, unless GasPriceOracle suggests a higher
+	// gas price.
 	MinGasPrice *big.Int
 
 	// MaxGasPrice is the maximum gas price (in gwei). This is used to clamp
@@ -50,8 +73,40 @@ type Config struct {
 
 	// RequireQueryInterval is the interval at which the tx manager will
 	// query the backend to check for confirmations after a tx at a
-	// specific gas price has been published.
+	// specific gas price has been published. If the backend is a
+	// HeadSubscriber, e.g. dialed over WebSocket, the backend is instead
+	// queried on each new head, and this interval is only used if the
+	// subscription is refused or fails.
 	ReceiptQueryInterval time.Duration
+
+	// GasPriceOracle, if non-nil, is queried for the initial gas price of
+	// each tx. The suggestion is clamped to [MinGasPrice, MaxGasPrice], and
+	// MinGasPrice is used if the oracle fails.
+	GasPriceOracle GasPriceOracle
+
+	// NumConfirmations is the number of L1 blocks, counting the including
+	// block as the first, that must be mined on top of a tx before Send
+	// returns its receipt. A value of zero or one returns the receipt as
+	// soon as the tx is included.
+	NumConfirmations uint64
+
+	// OnReorg, if non-nil, is invoked whenever a mined tx is reorged out
+	// before reaching NumConfirmations, indicating whether the tx was
+	// re-included. If not, fee bumping resumes until a tx is mined again.
+	OnReorg func(reincluded bool)
+
+	// Rebroadcaster, if non-nil, resubmits each published tx on every new
+	// L1 head until it is mined, as required by publishers whose
+	// submissions only target the next blocks, e.g. a RelayPublisher.
+	Rebroadcaster Rebroadcaster
+
+	// FeeBump determines how the fees of each replacement tx are bumped,
+	// in addition to GasRetryIncrement, and when Send gives up.
+	FeeBump FeeBumpPolicy
+
+	// OnFeeBump, if non-nil, is invoked each time a tx is replaced at
+	// bumped fees.
+	OnFeeBump func()
 }
 
 // TxManager is an interface that allows callers to reliably publish txs,
@@ -62,7 +117,8 @@ type TxManager interface {
 	// until an invocation of sendTx returns (called with differing gas
 	// prices). The method may be canceled using the passed context.
 	//
-	// NOTE: Send should be called by AT MOST one caller at a time.
+	// NOTE: Send may be called concurrently, e.g. to publish txs at
+	// consecutive nonces, in which case each tx is tracked independently.
 	Send(ctx context.Context, sendTx SendTxFunc) (*types.Receipt, error)
 }
 
@@ -83,12 +139,12 @@ type ReceiptSource interface {
 type SimpleTxManager struct {
 	name    string
 	cfg     Config
-	backend ReceiptSource
+	backend ConfirmationSource
 }
 
 // NewSimpleTxManager initializes a new SimpleTxManager with the passed Config.
 func NewSimpleTxManager(
-	name string, cfg Config, backend ReceiptSource) *SimpleTxManager {
+	name string, cfg Config, backend ConfirmationSource) *SimpleTxManager {
 
 	return &SimpleTxManager{
 		name:    name,
@@ -98,14 +154,45 @@ func NewSimpleTxManager(
 }
 
 // Send is used to publish a transaction with incrementally higher gas prices
-// until the transaction eventually confirms. This method blocks until an
-// invocation of sendTx returns (called with differing gas prices). The method
-// may be canceled using the passed context.
+// until the transaction eventually confirms under NumConfirmations blocks. If
+// the mined tx is reorged out beforehand, fee bumping resumes. This method
+// blocks until an invocation of sendTx returns (called with differing gas
+// prices). The method may be canceled using the passed context.
 //
-// NOTE: Send should be called by AT MOST one caller at a time.
+// NOTE: Send may be called concurrently, e.g. to publish txs at consecutive
+// nonces, in which case each tx is tracked independently.
 func (m *SimpleTxManager) Send(
 	ctx context.Context, sendTx SendTxFunc) (*types.Receipt, error) {
 
+	// Initialize our initial gas price from the oracle or the configured
+	// minimum, never exceeding the configured maximum.
+	return m.send(ctx, &legacySchedule{
+		cfg:      &m.cfg,
+		gasPrice: m.initialGasPrice(ctx),
+		sendTx:   sendTx,
+	})
+}
+
+// SendDynamic is Send for dynamic fee txs, published beginning at fees with the
+// fee cap clamped to MaxGasPrice. The tip cap and fee cap are bumped by the
+// percentages of the FeeBump policy on each replacement.
+func (m *SimpleTxManager) SendDynamic(
+	ctx context.Context,
+	fees DynamicFees,
+	sendTx SendDynamicTxFunc,
+) (*types.Receipt, error) {
+
+	return m.send(ctx, &dynamicSchedule{
+		cfg:    &m.cfg,
+		fees:   clampDynamicFees(fees, m.cfg.MaxGasPrice),
+		sendTx: sendTx,
+	})
+}
+
+// send publishes a tx at the escalating fees of schedule until it confirms.
+func (m *SimpleTxManager) send(
+	ctx context.Context, schedule feeSchedule) (*types.Receipt, error) {
+
 	name := m.name
 
 	// Initialize a wait group to track any spawned goroutines, and ensure
@@ -120,59 +207,136 @@ func (m *SimpleTxManager) Send(
 	ctxc, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	// numPublished counts the txs published successfully, such that Send
+	// can tell whether any tx may still confirm once the fee ceiling is
+	// reached.
+	var numPublished int32
+
+	// ceilingChan receives the error of the first publication declined
+	// for exceeding the caller's fee ceiling, or deferred by the caller.
+	ceilingChan := make(chan error, 1)
+
+	// numMined counts the published txs that have been mined but have yet
+	// to reach NumConfirmations. Fee bumping is suspended while any are
+	// pending, and resumes if they are all reorged out.
+	var numMined int32
+
 	// Create a closure that will block on passed sendTx function in the
-	// background, returning the first successfully mined receipt back to
-	// the main event loop via receiptChan.
+	// background, returning the first successfully confirmed receipt back
+	// to the main event loop via receiptChan.
 	receiptChan := make(chan *types.Receipt, 1)
-	sendTxAsync := func(gasPrice *big.Int) {
+	sendTxAsync := func(
+		publish func(context.Context) (*types.Transaction, error),
+		fees []interface{},
+	) {
+
 		defer wg.Done()
 
-		// Sign and publish transaction with current gas price.
-		tx, err := sendTx(ctxc, gasPrice)
+		// Sign and publish transaction with current fees.
+		tx, err := publish(ctxc)
 		if err != nil {
 			if err == context.Canceled ||
 				strings.Contains(err.Error(), "context canceled") {
 				return
 			}
+			if errors.Is(err, ErrFeeCeilingReached) ||
+				errors.Is(err, ErrSendDeferred) {
+
+				log.Warn(name+" publication declined, no longer "+
+					"bumping gas price",
+					append(fees, "err", err)...)
+				select {
+				case ceilingChan <- err:
+				default:
+				}
+				return
+			}
 			log.Error(name+" unable to publish transaction",
-				"gas_price", gasPrice, "err", err)
+				append(fees, "err", err)...)
 			// TODO(conner): add retry?
 			return
 		}
 
+		atomic.AddInt32(&numPublished, 1)
+
 		txHash := tx.Hash()
-		log.Info(name+" transaction published successfully", "hash", txHash,
-			"gas_price", gasPrice)
+		log.Info(name+" transaction published successfully",
+			append([]interface{}{"hash", txHash}, fees...)...)
+
+		for {
+			// Wait for the transaction to be mined and buried under
+			// the required number of confirmations, reporting the
+			// receipt back to the main event loop if found.
+			stopRebroadcast := m.startRebroadcast(ctxc, tx)
+			receipt, err := WaitMined(
+				ctxc, m.backend, tx, m.cfg.ReceiptQueryInterval,
+			)
+			stopRebroadcast()
+			if err != nil {
+				log.Debug(name+" send tx failed", "hash", txHash,
+					"err", err)
+			}
+			if receipt == nil {
+				return
+			}
+
+			atomic.AddInt32(&numMined, 1)
+			receipt, err = WaitConfirmations(
+				ctxc, m.backend, receipt, m.cfg.NumConfirmations,
+				m.cfg.ReceiptQueryInterval, m.cfg.OnReorg,
+			)
+			switch {
+			case err == nil:
+				// Use non-blocking select to ensure function
+				// can exit if more than one receipt is
+				// discovered.
+				select {
+				case receiptChan <- receipt:
+					log.Trace(name+" send tx succeeded",
+						"hash", txHash)
+				default:
+				}
+				return
+
+			// The tx is no longer included, resume fee bumping
+			// while continuing to watch for the tx in case it is
+			// mined again.
+			case err == ErrTxReorged:
+				atomic.AddInt32(&numMined, -1)
+				log.Warn(name+" transaction reorged out before "+
+					"confirmation depth, resuming fee bumping",
+					"hash", txHash)
 
-		// Wait for the transaction to be mined, reporting the receipt
-		// back to the main event loop if found.
-		receipt, err := WaitMined(
-			ctxc, m.backend, tx, m.cfg.ReceiptQueryInterval,
-		)
-		if err != nil {
-			log.Debug(name+" send tx failed", "hash", txHash,
-				"gas_price", gasPrice, "err", err)
-		}
-		if receipt != nil {
-			// Use non-blocking select to ensure function can exit
-			// if more than one receipt is discovered.
-			select {
-			case receiptChan <- receipt:
-				log.Trace(name+" send tx succeeded", "hash", txHash,
-					"gas_price", gasPrice)
 			default:
+				log.Debug(name+" send tx failed", "hash", txHash,
+					"err", err)
+				return
 			}
 		}
 	}
 
-	// Initialize our initial gas price to the configured minimum.
-	curGasPrice := new(big.Int).Set(m.cfg.MinGasPrice)
-
-	// Submit and wait for the receipt at our first gas price in the
-	// background, before entering the event loop and waiting out the
-	// resubmission timeout.
+	// Submit and wait for the receipt at our first fees in the background,
+	// before entering the event loop and waiting out the resubmission
+	// timeout.
 	wg.Add(1)
-	go sendTxAsync(curGasPrice)
+	go sendTxAsync(schedule.publisher(), schedule.logCtx())
+
+	// ceilingErr is set once the fee ceiling is reached, or publication is
+	// deferred, after which no further bumps are attempted.
+	var ceilingErr error
+
+	// abortChan fires once the abort deadline elapses, if any, after which
+	// aborted is set and Send returns as soon as no tx is mined.
+	var (
+		abortChan <-chan time.Time
+		aborted   bool
+		numBumps  uint64
+	)
+	if m.cfg.FeeBump.AbortAfter > 0 {
+		abortTimer := time.NewTimer(m.cfg.FeeBump.AbortAfter)
+		defer abortTimer.Stop()
+		abortChan = abortTimer.C
+	}
 
 	for {
 		select {
@@ -180,40 +344,164 @@ func (m *SimpleTxManager) Send(
 		// Whenever a resubmission timeout has elapsed, bump the gas
 		// price and publish a new transaction.
 		case <-time.After(m.cfg.ResubmissionTimeout):
+			// Hold off while a mined tx awaits its confirmation
+			// depth, as any replacement would be rejected.
+			if atomic.LoadInt32(&numMined) > 0 {
+				continue
+			}
+
+			if aborted {
+				return nil, ErrSendAborted
+			}
+
+			// If the fee ceiling was reached, give the txs already
+			// published one last resubmission timeout to confirm.
+			if ceilingErr != nil {
+				return nil, ceilingErr
+			}
+
+			// Likewise once the max number of bumps is reached.
+			maxBumps := m.cfg.FeeBump.MaxBumps
+			if maxBumps > 0 && numBumps >= maxBumps {
+				return nil, ErrMaxFeeBumps
+			}
+
 			// If our last attempt published at the max gas price,
 			// return an error as we are unlikely to succeed in
 			// publishing. This also indicates that the max gas
 			// price should likely be adjusted higher for the
 			// daemon.
-			if curGasPrice.Cmp(m.cfg.MaxGasPrice) >= 0 {
+			if !schedule.bump() {
 				return nil, ErrPublishTimeout
 			}
-
-			// Bump the gas price using linear gas price increments.
-			curGasPrice = NextGasPrice(
-				curGasPrice, m.cfg.GasRetryIncrement,
-				m.cfg.MaxGasPrice,
-			)
+			numBumps++
+			if m.cfg.OnFeeBump != nil {
+				m.cfg.OnFeeBump()
+			}
 
 			// Submit and wait for the bumped traction to confirm.
 			wg.Add(1)
-			go sendTxAsync(curGasPrice)
+			go sendTxAsync(schedule.publisher(), schedule.logCtx())
+
+		// The abort deadline has elapsed. A mined tx is still given
+		// the chance to reach the confirmation depth.
+		case <-abortChan:
+			if atomic.LoadInt32(&numMined) == 0 {
+				return nil, ErrSendAborted
+			}
+			aborted = true
+
+		// The caller declined to publish at the last bumped gas price,
+		// or deferred publication. If no tx was ever published, there
+		// is nothing to wait for.
+		case err := <-ceilingChan:
+			if atomic.LoadInt32(&numPublished) == 0 {
+				return nil, err
+			}
+			ceilingErr = err
 
 		// The passed context has been canceled, i.e. in the event of a
 		// shutdown.
 		case <-ctxc.Done():
 			return nil, ctxc.Err()
 
-		// The transaction has confirmed.
+		// The transaction has reached the required confirmation
+		// depth.
 		case receipt := <-receiptChan:
 			return receipt, nil
 		}
 	}
 }
 
+// initialGasPrice returns the gas price of the first publication attempt. This
+// is the suggestion of the configured GasPriceOracle if it exceeds
+// MinGasPrice, clamped to MaxGasPrice.
+func (m *SimpleTxManager) initialGasPrice(ctx context.Context) *big.Int {
+	gasPrice := new(big.Int).Set(m.cfg.MinGasPrice)
+
+	if m.cfg.GasPriceOracle != nil {
+		suggested, err := m.cfg.GasPriceOracle.SuggestGasPrice(ctx)
+		switch {
+		case err != nil:
+			log.Warn(m.name+" unable to query gas price oracle, "+
+				"using min gas price", "err", err)
+		case suggested.Cmp(gasPrice) > 0:
+			gasPrice.Set(suggested)
+		}
+	}
+
+	if gasPrice.Cmp(m.cfg.MaxGasPrice) > 0 {
+		gasPrice.Set(m.cfg.MaxGasPrice)
+	}
+
+	return gasPrice
+}
+
+// startRebroadcast resubmits tx through the configured Rebroadcaster on each new
+// L1 head, until the returned function is called or ctx is canceled. If no
+// Rebroadcaster is configured, this is a no-op.
+func (m *SimpleTxManager) startRebroadcast(
+	ctx context.Context, tx *types.Transaction) func() {
+
+	if m.cfg.Rebroadcaster == nil {
+		return func() {}
+	}
+
+	ctxc, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		trigger := newQueryTrigger(
+			ctxc, m.backend, m.cfg.ReceiptQueryInterval,
+		)
+		defer trigger.Stop()
+
+		// The tx was just published targeting the blocks following the
+		// current head, so only later heads warrant a resubmission.
+		var lastHead *big.Int
+		if head, err := m.backend.HeaderByNumber(ctxc, nil); err == nil {
+			lastHead = head.Number
+		}
+
+		for {
+			select {
+			case <-ctxc.Done():
+				return
+			case <-trigger.C:
+			}
+
+			head, err := m.backend.HeaderByNumber(ctxc, nil)
+			if err != nil {
+				log.Trace(m.name+" unable to get L1 head for "+
+					"rebroadcast", "err", err)
+				continue
+			}
+			if lastHead != nil && head.Number.Cmp(lastHead) <= 0 {
+				continue
+			}
+			lastHead = head.Number
+
+			err = m.cfg.Rebroadcaster.Rebroadcast(ctxc, tx, head)
+			if err != nil && ctxc.Err() == nil {
+				log.Warn(m.name+" unable to rebroadcast "+
+					"transaction", "hash", tx.Hash(),
+					"head", head.Number, "err", err)
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		wg.Wait()
+	}
+}
+
 // WaitMined blocks until the backend indicates confirmation of tx and returns
-// the tx receipt. Queries are made every queryInterval, regardless of whether
-// the backend returns an error. This method can be canceled using the passed
+// the tx receipt. Queries are made on each new head if the backend is a
+// HeadSubscriber, and otherwise every queryInterval, regardless of whether the
+// backend returns an error. This method can be canceled using the passed
 // context.
 func WaitMined(
 	ctx context.Context,
@@ -222,8 +510,8 @@ func WaitMined(
 	queryInterval time.Duration,
 ) (*types.Receipt, error) {
 
-	queryTicker := time.NewTicker(queryInterval)
-	defer queryTicker.Stop()
+	trigger := newQueryTrigger(ctx, backend, queryInterval)
+	defer trigger.Stop()
 
 	txHash := tx.Hash()
 
@@ -243,7 +531,7 @@ func WaitMined(
 		select {
 		case <-ctx.Done():
 			return nil, ctx.Err()
-		case <-queryTicker.C:
+		case <-trigger.C:
 		}
 	}
 }
@@ -3,12 +3,14 @@ package txmgr_test
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ethereum-optimism/optimism/go/batch-submitter/txmgr"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/stretchr/testify/require"
@@ -104,7 +106,7 @@ func newTestHarness() *testHarness {
 	})
 }
 
-// mockBackend implements txmgr.ReceiptSource that tracks mined transactions
+// mockBackend implements txmgr.ConfirmationSource that tracks mined transactions
 // along with the gas price used.
 type mockBackend struct {
 	mu sync.RWMutex
@@ -155,6 +157,14 @@ func (b *mockBackend) TransactionReceipt(
 	}, nil
 }
 
+// HeaderByNumber is never queried, as the receipts returned by the mockBackend
+// carry no block number and are thus considered final once mined.
+func (b *mockBackend) HeaderByNumber(
+	ctx context.Context, number *big.Int) (*types.Header, error) {
+
+	return nil, ethereum.NotFound
+}
+
 // TestTxMgrConfirmAtMinGasPrice asserts that Send returns the min gas price tx
 // if the tx is mined instantly.
 func TestTxMgrConfirmAtMinGasPrice(t *testing.T) {
@@ -179,6 +189,116 @@ func TestTxMgrConfirmAtMinGasPrice(t *testing.T) {
 	require.Equal(t, receipt.GasUsed, h.cfg.MinGasPrice.Uint64())
 }
 
+// TestTxMgrMinGasPriceClampedToMax asserts that Send never publishes a tx above
+// the max gas price, even if the configured min gas price is higher.
+func TestTxMgrMinGasPriceClampedToMax(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarnessWithConfig(txmgr.Config{
+		MinGasPrice:          new(big.Int).SetUint64(20),
+		MaxGasPrice:          new(big.Int).SetUint64(10),
+		GasRetryIncrement:    new(big.Int).SetUint64(5),
+		ResubmissionTimeout:  time.Second,
+		ReceiptQueryInterval: 50 * time.Millisecond,
+	})
+
+	sendTxFunc := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		tx := types.NewTx(&types.LegacyTx{
+			GasPrice: gasPrice,
+		})
+		h.backend.mine(tx.Hash(), gasPrice)
+		return tx, nil
+	}
+
+	ctx := context.Background()
+	receipt, err := h.mgr.Send(ctx, sendTxFunc)
+	require.Nil(t, err)
+	require.NotNil(t, receipt)
+	require.Equal(t, receipt.GasUsed, h.cfg.MaxGasPrice.Uint64())
+}
+
+// mockGasPriceOracle is a txmgr.GasPriceOracle returning a fixed suggestion.
+type mockGasPriceOracle struct {
+	gasPrice *big.Int
+	err      error
+}
+
+// SuggestGasPrice returns the configured gas price or error.
+func (o *mockGasPriceOracle) SuggestGasPrice(
+	ctx context.Context) (*big.Int, error) {
+
+	return o.gasPrice, o.err
+}
+
+// TestTxMgrInitialGasPriceFromOracle asserts that the first publication attempt
+// uses the oracle's suggestion, clamped to the configured gas price range, and
+// falls back to the min gas price if the oracle fails.
+func TestTxMgrInitialGasPriceFromOracle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		oracle      *mockGasPriceOracle
+		expGasPrice uint64
+	}{
+		{
+			name:        "suggestion within range",
+			oracle:      &mockGasPriceOracle{gasPrice: big.NewInt(20)},
+			expGasPrice: 20,
+		},
+		{
+			name:        "suggestion below min",
+			oracle:      &mockGasPriceOracle{gasPrice: big.NewInt(1)},
+			expGasPrice: 5,
+		},
+		{
+			name:        "suggestion above max",
+			oracle:      &mockGasPriceOracle{gasPrice: big.NewInt(500)},
+			expGasPrice: 50,
+		},
+		{
+			name: "oracle failure",
+			oracle: &mockGasPriceOracle{
+				err: errors.New("oracle failed"),
+			},
+			expGasPrice: 5,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			h := newTestHarnessWithConfig(txmgr.Config{
+				MinGasPrice:          new(big.Int).SetUint64(5),
+				MaxGasPrice:          new(big.Int).SetUint64(50),
+				GasRetryIncrement:    new(big.Int).SetUint64(5),
+				ResubmissionTimeout:  time.Second,
+				ReceiptQueryInterval: 50 * time.Millisecond,
+				GasPriceOracle:       test.oracle,
+			})
+
+			sendTxFunc := func(
+				ctx context.Context,
+				gasPrice *big.Int,
+			) (*types.Transaction, error) {
+				tx := types.NewTx(&types.LegacyTx{
+					GasPrice: gasPrice,
+				})
+				h.backend.mine(tx.Hash(), gasPrice)
+				return tx, nil
+			}
+
+			receipt, err := h.mgr.Send(context.Background(), sendTxFunc)
+			require.Nil(t, err)
+			require.NotNil(t, receipt)
+			require.Equal(t, test.expGasPrice, receipt.GasUsed)
+		})
+	}
+}
+
 // TestTxMgrNeverConfirmCancel asserts that a Send can be canceled even if no
 // transaction is mined. This is done to ensure the the tx mgr can properly
 // abort on shutdown, even if a txn is in the process of being published.
@@ -351,6 +471,67 @@ func TestTxMgrConfirmsMinGasPriceAfterBumping(t *testing.T) {
 	require.Equal(t, receipt.GasUsed, h.cfg.MinGasPrice.Uint64())
 }
 
+// TestTxMgrConcurrentSendsTrackedIndependently asserts that concurrent calls to
+// Send for txs at different nonces each bump and confirm their own tx.
+func TestTxMgrConcurrentSendsTrackedIndependently(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness()
+
+	// The tx at the first nonce confirms immediately, while the tx at the
+	// second nonce only confirms once its gas price has been bumped.
+	bumpedGasPrice := new(big.Int).Add(
+		h.cfg.MinGasPrice, h.cfg.GasRetryIncrement,
+	)
+	newSendTxFunc := func(
+		nonce uint64,
+		minedGasPrice *big.Int,
+	) txmgr.SendTxFunc {
+
+		return func(
+			ctx context.Context,
+			gasPrice *big.Int,
+		) (*types.Transaction, error) {
+			tx := types.NewTx(&types.LegacyTx{
+				Nonce:    nonce,
+				GasPrice: gasPrice,
+			})
+			if gasPrice.Cmp(minedGasPrice) == 0 {
+				h.backend.mine(tx.Hash(), gasPrice)
+			}
+			return tx, nil
+		}
+	}
+
+	var (
+		wg       sync.WaitGroup
+		receipts [2]*types.Receipt
+		errs     [2]error
+	)
+	for i, minedGasPrice := range []*big.Int{
+		h.cfg.MinGasPrice, bumpedGasPrice,
+	} {
+		wg.Add(1)
+		go func(i int, minedGasPrice *big.Int) {
+			defer wg.Done()
+
+			receipts[i], errs[i] = h.mgr.Send(
+				context.Background(),
+				newSendTxFunc(uint64(i), minedGasPrice),
+			)
+		}(i, minedGasPrice)
+	}
+	wg.Wait()
+
+	require.Nil(t, errs[0])
+	require.NotNil(t, receipts[0])
+	require.Equal(t, receipts[0].GasUsed, h.cfg.MinGasPrice.Uint64())
+
+	require.Nil(t, errs[1])
+	require.NotNil(t, receipts[1])
+	require.Equal(t, receipts[1].GasUsed, bumpedGasPrice.Uint64())
+}
+
 // TestWaitMinedReturnsReceiptOnFirstSuccess insta-mines a transaction and
 // asserts that WaitMined returns the appropriate receipt.
 func TestWaitMinedReturnsReceiptOnFirstSuccess(t *testing.T) {
@@ -429,3 +610,89 @@ func TestWaitMinedReturnsReceiptAfterFailure(t *testing.T) {
 	require.NotNil(t, receipt)
 	require.Equal(t, receipt.TxHash, txHash)
 }
+
+// TestTxMgrFeeCeilingBeforePublish asserts that Send returns immediately if the
+// first publication is declined for exceeding the fee ceiling.
+func TestTxMgrFeeCeilingBeforePublish(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness()
+
+	sendTxFunc := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		return nil, fmt.Errorf("%w: gas_price=%v",
+			txmgr.ErrFeeCeilingReached, gasPrice)
+	}
+
+	start := time.Now()
+	receipt, err := h.mgr.Send(context.Background(), sendTxFunc)
+	require.True(t, errors.Is(err, txmgr.ErrFeeCeilingReached))
+	require.Nil(t, receipt)
+	require.Less(t, time.Since(start), h.cfg.ResubmissionTimeout)
+}
+
+// TestTxMgrSendDeferredBeforePublish asserts that Send returns immediately if
+// the first publication is deferred by the caller.
+func TestTxMgrSendDeferredBeforePublish(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarness()
+
+	sendTxFunc := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		return nil, fmt.Errorf("%w: queue index 3", txmgr.ErrSendDeferred)
+	}
+
+	start := time.Now()
+	receipt, err := h.mgr.Send(context.Background(), sendTxFunc)
+	require.True(t, errors.Is(err, txmgr.ErrSendDeferred))
+	require.Nil(t, receipt)
+	require.Less(t, time.Since(start), h.cfg.ResubmissionTimeout)
+}
+
+// TestTxMgrFeeCeilingStopsBumping asserts that Send stops bumping the gas price
+// once a publication is declined for exceeding the fee ceiling, and gives up
+// after waiting one more resubmission timeout for the txs already published.
+func TestTxMgrFeeCeilingStopsBumping(t *testing.T) {
+	t.Parallel()
+
+	h := newTestHarnessWithConfig(txmgr.Config{
+		MinGasPrice:          new(big.Int).SetUint64(5),
+		MaxGasPrice:          new(big.Int).SetUint64(50),
+		GasRetryIncrement:    new(big.Int).SetUint64(5),
+		ResubmissionTimeout:  100 * time.Millisecond,
+		ReceiptQueryInterval: 10 * time.Millisecond,
+	})
+
+	var (
+		mu        sync.Mutex
+		gasPrices []uint64
+	)
+	sendTxFunc := func(
+		ctx context.Context,
+		gasPrice *big.Int,
+	) (*types.Transaction, error) {
+		mu.Lock()
+		gasPrices = append(gasPrices, gasPrice.Uint64())
+		mu.Unlock()
+
+		if gasPrice.Uint64() > 5 {
+			return nil, txmgr.ErrFeeCeilingReached
+		}
+		return types.NewTx(&types.LegacyTx{
+			GasPrice: gasPrice,
+		}), nil
+	}
+
+	receipt, err := h.mgr.Send(context.Background(), sendTxFunc)
+	require.Equal(t, txmgr.ErrFeeCeilingReached, err)
+	require.Nil(t, receipt)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []uint64{5, 10}, gasPrices)
+}
@@ -0,0 +1,90 @@
+// Package wallet tracks the keys a driver signs batch txs with, allowing
+// submission to rotate between several wallets, e.g. while one is being
+// funded or after it was compromised.
+package wallet
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrUnknownWallet signals an attempt to activate a wallet outside of a Set.
+var ErrUnknownWallet = errors.New("unknown wallet")
+
+// Set is an ordered set of wallets, exactly one of which is active at a time.
+// The first wallet is active initially.
+//
+// NOTE: Set is safe for concurrent use.
+type Set struct {
+	keys  []*ecdsa.PrivateKey
+	addrs []common.Address
+
+	mu     sync.RWMutex
+	active int
+}
+
+// NewSet initializes a Set activating primary, followed by the rotation keys in
+// the order they are rotated to.
+func NewSet(primary *ecdsa.PrivateKey, rotation ...*ecdsa.PrivateKey) *Set {
+	keys := append([]*ecdsa.PrivateKey{primary}, rotation...)
+	addrs := make([]common.Address, 0, len(keys))
+	for _, key := range keys {
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+
+	return &Set{
+		keys:  keys,
+		addrs: addrs,
+	}
+}
+
+// Len returns the number of wallets in the Set.
+func (s *Set) Len() int {
+	return len(s.keys)
+}
+
+// Addrs returns the addresses of the wallets in the Set, in rotation order.
+func (s *Set) Addrs() []common.Address {
+	return append([]common.Address(nil), s.addrs...)
+}
+
+// Active returns the index of the active wallet.
+func (s *Set) Active() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.active
+}
+
+// Key returns the private key of the active wallet.
+func (s *Set) Key() *ecdsa.PrivateKey {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.keys[s.active]
+}
+
+// Addr returns the address of the active wallet.
+func (s *Set) Addr() common.Address {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.addrs[s.active]
+}
+
+// Use activates the wallet at index i.
+func (s *Set) Use(i int) error {
+	if i < 0 || i >= len(s.keys) {
+		return ErrUnknownWallet
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active = i
+	return nil
+}
@@ -0,0 +1,38 @@
+package wallet_test
+
+import (
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/wallet"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestKey generates a random private key.
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	return key
+}
+
+// TestSetUse asserts that the primary wallet is active initially, and that
+// only wallets within the set can be activated.
+func TestSetUse(t *testing.T) {
+	t.Parallel()
+
+	primary, backup := newTestKey(t), newTestKey(t)
+	set := wallet.NewSet(primary, backup)
+	require.Equal(t, 2, set.Len())
+	require.Equal(t, 0, set.Active())
+	require.Equal(t, primary, set.Key())
+	require.Equal(t, crypto.PubkeyToAddress(primary.PublicKey), set.Addr())
+
+	require.Nil(t, set.Use(1))
+	require.Equal(t, 1, set.Active())
+	require.Equal(t, backup, set.Key())
+	require.Equal(t, set.Addrs()[1], set.Addr())
+
+	require.Equal(t, wallet.ErrUnknownWallet, set.Use(2))
+	require.Equal(t, 1, set.Active())
+}
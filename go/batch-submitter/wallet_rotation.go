@@ -0,0 +1,333 @@
+package batchsubmitter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/queue"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+)
+
+// ErrUnauthorizedWallets signals that none of the wallets a Driver may submit
+// from is the sender its contract accepts batch txs from, such that every
+// batch tx would revert.
+var ErrUnauthorizedWallets = errors.New("no configured wallet is the " +
+	"sender resolved by the address manager")
+
+// WalletRotator is an optional interface that may be implemented by a Driver
+// able to submit batch txs from more than one wallet. Since its contract only
+// accepts batch txs from the sender resolved by its AddressManager, submission
+// rotates to another wallet only once the sender is updated to it. A sender
+// whose balance falls below WalletRotationBalance, or whose nonce has been
+// wedged for WalletWedgedAfter, is instead alerted on, naming an eligible
+// wallet the sender may be updated to.
+type WalletRotator interface {
+	// Wallets returns the wallets batch txs may be submitted from, whose
+	// active wallet is reported by WalletAddr.
+	Wallets() *wallet.Set
+
+	// AuthorizedSender returns the sender the Driver's contract accepts
+	// batch txs from, as resolved by its AddressManager.
+	AuthorizedSender(ctx context.Context) (common.Address, error)
+}
+
+// UseAuthorizedWallet activates the wallet of rotator that is the sender its
+// contract accepts batch txs from, returning ErrUnauthorizedWallets if none of
+// its wallets is. Drivers with a single wallet are not checked.
+func UseAuthorizedWallet(ctx context.Context, rotator WalletRotator) error {
+	wallets := rotator.Wallets()
+	if wallets.Len() < 2 {
+		return nil
+	}
+
+	sender, err := rotator.AuthorizedSender(ctx)
+	if err != nil {
+		return err
+	}
+	for i, addr := range wallets.Addrs() {
+		if addr == sender {
+			return wallets.Use(i)
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnauthorizedWallets, sender.Hex())
+}
+
+// nonceWedge tracks how long the active wallet has had txs pending behind an
+// unchanged latest nonce.
+type nonceWedge struct {
+	latest uint64
+	since  time.Time
+}
+
+// checkWalletRotation rotates submission to the authorized sender once it is
+// updated to an eligible rotation wallet, returning the balance of the wallet
+// that is active afterwards. Since the batch txs in flight are bound to the
+// nonces of the active wallet, rotation waits for them to conclude. If the
+// updated sender is not eligible, or the active wallet is still the sender but
+// its balance is below WalletRotationBalance or its nonce is wedged, rotation
+// is refused with an alert, as only updating the sender can rotate submission.
+//
+// NOTE: This method MUST only be called from the event loop.
+func (s *Service) checkWalletRotation(
+	ctx context.Context,
+	trace *CycleTrace,
+	balance *big.Int,
+) *big.Int {
+
+	rotator, ok := s.cfg.Driver.(WalletRotator)
+	if !ok {
+		return balance
+	}
+	wallets := rotator.Wallets()
+	if wallets.Len() < 2 {
+		return balance
+	}
+
+	// Batch txs from any wallet but the sender resolved by the contract's
+	// AddressManager revert, so submission only ever follows the sender.
+	name := s.cfg.Driver.Name()
+	sender, err := rotator.AuthorizedSender(ctx)
+	if err != nil {
+		log.Warn(name+" unable to resolve authorized sender", "err", err)
+		return balance
+	}
+
+	active := wallets.Addr()
+	if sender == active {
+		var reason string
+		switch {
+		case belowThreshold(balance, s.cfg.WalletRotationBalance):
+			reason = "low balance"
+		case s.nonceWedged(ctx, active):
+			reason = "wedged nonce"
+		default:
+			s.rotationRefused = false
+			return balance
+		}
+
+		s.refuseWalletRotation(ctx, wallets, sender, reason)
+		return balance
+	}
+
+	const reason = "authorized sender changed"
+	if s.pipeline != nil && len(s.pipeline.Batches()) > 0 {
+		log.Warn(name+" wallet rotation awaiting batch txs in flight",
+			"reason", reason)
+		return balance
+	}
+
+	for next, addr := range wallets.Addrs() {
+		if addr != sender {
+			continue
+		}
+		nextBalance, ok := s.walletEligible(ctx, addr)
+		if !ok {
+			break
+		}
+
+		s.rotateWallet(wallets, next, reason)
+		trace.Step("wallet_rotation", "reason=%s wallet=%s", reason,
+			addr.Hex())
+		return nextBalance
+	}
+
+	s.refuseWalletRotation(ctx, wallets, sender, reason)
+	return balance
+}
+
+// refuseWalletRotation alerts that the active wallet needs rotating out, but
+// the authorized sender is not an eligible rotation wallet, such that the
+// active wallet must be funded or unwedged, or the sender updated to the
+// eligible rotation wallet named by the alert, if any. The alert is only raised
+// once until the rotation is no longer needed.
+func (s *Service) refuseWalletRotation(
+	ctx context.Context,
+	wallets *wallet.Set,
+	sender common.Address,
+	reason string,
+) {
+
+	name := s.cfg.Driver.Name()
+	active := wallets.Addr()
+
+	eligible := "none"
+	for _, addr := range wallets.Addrs() {
+		if addr == active || addr == sender {
+			continue
+		}
+		if _, ok := s.walletEligible(ctx, addr); ok {
+			eligible = addr.Hex()
+			break
+		}
+	}
+
+	log.Error(name+" no eligible authorized wallet to rotate to",
+		"reason", reason,
+		"wallet", s.cfg.AddressBook.Format(active),
+		"authorized_sender", s.cfg.AddressBook.Format(sender),
+		"eligible_wallet", eligible)
+
+	if s.rotationRefused {
+		return
+	}
+	s.rotationRefused = true
+
+	s.notify(alerts.Alert{
+		Key:      name + "-wallet-rotation",
+		Severity: alerts.SeverityCritical,
+		Service:  name,
+		Summary: fmt.Sprintf("wallet %s needs rotating out after %s, "+
+			"but the authorized sender %s is not an eligible "+
+			"rotation wallet (eligible: %s)", active.Hex(), reason,
+			sender.Hex(), eligible),
+		Details: map[string]string{
+			"wallet":            active.Hex(),
+			"authorized_sender": sender.Hex(),
+			"eligible_wallet":   eligible,
+			"reason":            reason,
+		},
+		Time: time.Now(),
+	})
+}
+
+// nonceWedged returns true if the wallet has had txs pending behind an
+// unchanged latest nonce for at least WalletWedgedAfter, i.e. a tx that is
+// no longer being bumped is holding back every later nonce.
+func (s *Service) nonceWedged(
+	ctx context.Context,
+	walletAddr common.Address,
+) bool {
+
+	if s.cfg.WalletWedgedAfter == 0 {
+		return false
+	}
+
+	latest, pending, err := s.walletNonces(ctx, walletAddr)
+	if err != nil {
+		log.Warn(s.cfg.Driver.Name()+" unable to get wallet nonces",
+			"err", err)
+		return false
+	}
+
+	now := time.Now()
+	switch {
+	case pending <= latest:
+		s.wedge = nonceWedge{}
+		return false
+	case s.wedge.since.IsZero() || s.wedge.latest != latest:
+		s.wedge = nonceWedge{latest: latest, since: now}
+		return false
+	default:
+		return now.Sub(s.wedge.since) >= s.cfg.WalletWedgedAfter
+	}
+}
+
+// walletEligible returns the balance of the wallet, and whether it can take
+// over submission, i.e. it holds at least WalletRotationBalance and has no
+// txs pending.
+func (s *Service) walletEligible(
+	ctx context.Context,
+	walletAddr common.Address,
+) (*big.Int, bool) {
+
+	name := s.cfg.Driver.Name()
+
+	balance, err := s.cfg.L1Client.BalanceAt(ctx, walletAddr, nil)
+	if err != nil {
+		log.Warn(name+" unable to get wallet balance", "wallet",
+			s.cfg.AddressBook.Format(walletAddr), "err", err)
+		return nil, false
+	}
+	if belowThreshold(balance, s.cfg.WalletRotationBalance) {
+		return nil, false
+	}
+
+	latest, pending, err := s.walletNonces(ctx, walletAddr)
+	if err != nil {
+		log.Warn(name+" unable to get wallet nonces", "wallet",
+			s.cfg.AddressBook.Format(walletAddr), "err", err)
+		return nil, false
+	}
+
+	return balance, pending <= latest
+}
+
+// walletNonces returns the latest and pending nonces of the wallet.
+func (s *Service) walletNonces(
+	ctx context.Context,
+	walletAddr common.Address,
+) (uint64, uint64, error) {
+
+	latest, err := s.cfg.L1Client.NonceAt(ctx, walletAddr, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	pending, err := s.cfg.L1Client.PendingNonceAt(ctx, walletAddr)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return latest, pending, nil
+}
+
+// rotateWallet activates the wallet at index next, resetting the state bound
+// to the previous wallet. Submissions left pending by the previous wallet are
+// abandoned, as their nonces are meaningless to the next one.
+func (s *Service) rotateWallet(wallets *wallet.Set, next int, reason string) {
+	name := s.cfg.Driver.Name()
+	prev := wallets.Addr()
+
+	_ = wallets.Use(next) // can't fail for an index within Addrs
+	s.nonceMgr.SetAccount(wallets.Addr())
+	s.wedge = nonceWedge{}
+	s.rotationRefused = false
+	if s.drain != nil {
+		s.drain = newDrainTracker(s.cfg.BalanceDrainWindow)
+	}
+	s.abandonPendingSubmissions()
+
+	s.metrics.WalletRotations.Inc()
+	s.metrics.ActiveWallet.Set(float64(next))
+	log.Warn(name+" rotated submission wallet", "reason", reason,
+		"from", s.cfg.AddressBook.Format(prev),
+		"to", s.cfg.AddressBook.Format(wallets.Addr()))
+
+	s.notify(alerts.Alert{
+		Key:      name + "-wallet-rotation",
+		Severity: alerts.SeverityWarning,
+		Service:  name,
+		Summary: fmt.Sprintf("submission rotated from wallet %s to %s "+
+			"after %s", prev.Hex(), wallets.Addr().Hex(), reason),
+		Details: map[string]string{
+			"from":   prev.Hex(),
+			"to":     wallets.Addr().Hex(),
+			"reason": reason,
+		},
+		Time: time.Now(),
+	})
+}
+
+// abandonPendingSubmissions abandons the records left pending in the
+// configured StateStore, if any.
+func (s *Service) abandonPendingSubmissions() {
+	if s.cfg.StateStore == nil {
+		return
+	}
+
+	pending, err := s.cfg.StateStore.Pending()
+	if err != nil {
+		log.Error(s.cfg.Driver.Name()+" unable to get pending "+
+			"submissions", "err", err)
+		return
+	}
+	for _, record := range pending {
+		s.concludeRecord(record, queue.SubmissionAbandoned, nil)
+	}
+}
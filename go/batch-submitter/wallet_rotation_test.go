@@ -0,0 +1,271 @@
+package batchsubmitter
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/alerts"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/noncemgr"
+	"github.com/ethereum-optimism/optimism/go/batch-submitter/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// walletAPI serves the balances and nonces of wallets over the eth namespace.
+type walletAPI struct {
+	mu       sync.Mutex
+	balances map[common.Address]*big.Int
+	latest   map[common.Address]uint64
+	pending  map[common.Address]uint64
+}
+
+func (a *walletAPI) GetBalance(
+	addr common.Address, _ string) (*hexutil.Big, error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	balance := a.balances[addr]
+	if balance == nil {
+		balance = new(big.Int)
+	}
+	return (*hexutil.Big)(balance), nil
+}
+
+func (a *walletAPI) GetTransactionCount(
+	addr common.Address, block string) (hexutil.Uint64, error) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if block == "pending" {
+		return hexutil.Uint64(a.pending[addr]), nil
+	}
+	return hexutil.Uint64(a.latest[addr]), nil
+}
+
+func (a *walletAPI) setBalance(addr common.Address, balance int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.balances[addr] = big.NewInt(balance)
+}
+
+func (a *walletAPI) setNonces(addr common.Address, latest, pending uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.latest[addr] = latest
+	a.pending[addr] = pending
+}
+
+// rotatingDriver is a driver submitting from its set of wallets, whose
+// contract accepts batch txs from sender.
+type rotatingDriver struct {
+	namedDriver
+	wallets *wallet.Set
+	sender  *common.Address
+}
+
+func (d rotatingDriver) WalletAddr() common.Address {
+	return d.wallets.Addr()
+}
+
+func (d rotatingDriver) Wallets() *wallet.Set {
+	return d.wallets
+}
+
+func (d rotatingDriver) AuthorizedSender(
+	context.Context) (common.Address, error) {
+
+	return *d.sender, nil
+}
+
+// newRotationTestService returns a service whose driver rotates between
+// numWallets wallets, rotating below a balance of 100 wei. The balances and
+// nonces of the wallets are served by the returned walletAPI, and the sender
+// authorized by the driver's contract is the returned address, initially the
+// active wallet.
+func newRotationTestService(
+	t *testing.T,
+	numWallets int,
+) (*Service, *wallet.Set, *walletAPI, *common.Address) {
+
+	key, err := crypto.GenerateKey()
+	require.Nil(t, err)
+	var rotationKeys []*ecdsa.PrivateKey
+	for i := 1; i < numWallets; i++ {
+		rotationKey, err := crypto.GenerateKey()
+		require.Nil(t, err)
+		rotationKeys = append(rotationKeys, rotationKey)
+	}
+	wallets := wallet.NewSet(key, rotationKeys...)
+
+	api := &walletAPI{
+		balances: make(map[common.Address]*big.Int),
+		latest:   make(map[common.Address]uint64),
+		pending:  make(map[common.Address]uint64),
+	}
+	server := rpc.NewServer()
+	require.Nil(t, server.RegisterName("eth", api))
+	t.Cleanup(server.Stop)
+	l1Client := ethclient.NewClient(rpc.DialInProc(server))
+
+	name := t.Name()
+	s := newRetryTestService(context.Background(), name, RetryPolicy{})
+	sender := wallets.Addr()
+	s.cfg.Driver = rotatingDriver{
+		namedDriver: namedDriver{name: name},
+		wallets:     wallets,
+		sender:      &sender,
+	}
+	s.cfg.L1Client = l1Client
+	s.cfg.AddressBook = NewAddressBook(nil)
+	s.cfg.WalletRotationBalance = big.NewInt(100)
+	s.nonceMgr = noncemgr.NewManager(name, wallets.Addr(), l1Client, nil)
+
+	return s, wallets, api, &sender
+}
+
+// TestServiceWalletRotationLowBalance asserts that submission only rotates to
+// the authorized sender, once it is an eligible wallet, and that rotation is
+// otherwise refused with a single alert naming an eligible wallet.
+func TestServiceWalletRotationLowBalance(t *testing.T) {
+	t.Parallel()
+
+	s, wallets, api, sender := newRotationTestService(t, 3)
+	notifier := &recordingNotifier{}
+	s.cfg.BalanceNotifiers = []alerts.Notifier{notifier}
+	addrs := wallets.Addrs()
+	api.setBalance(addrs[1], 500)
+	api.setBalance(addrs[2], 200)
+
+	// The active wallet holds enough to keep submitting.
+	balance := s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(100))
+	require.Equal(t, big.NewInt(100), balance)
+	require.Equal(t, 0, wallets.Active())
+
+	// While the active wallet is the authorized sender, rotating to any
+	// other wallet is refused, alerting once.
+	for i := 0; i < 2; i++ {
+		balance = s.checkWalletRotation(
+			s.ctx, newCycleTrace(), big.NewInt(99),
+		)
+		require.Equal(t, big.NewInt(99), balance)
+		require.Equal(t, 0, wallets.Active())
+	}
+	s.wg.Wait()
+	require.Equal(t,
+		[]alerts.Severity{alerts.SeverityCritical}, notifier.severities,
+	)
+	require.Equal(t, "low balance", notifier.details[0]["reason"])
+	require.Equal(t, addrs[1].Hex(), notifier.details[0]["eligible_wallet"])
+
+	// Likewise while the authorized sender has txs pending.
+	*sender = addrs[1]
+	api.setNonces(addrs[1], 1, 2)
+	balance = s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(99))
+	require.Equal(t, big.NewInt(99), balance)
+	require.Equal(t, 0, wallets.Active())
+
+	*sender = addrs[2]
+	trace := newCycleTrace()
+	balance = s.checkWalletRotation(s.ctx, trace, big.NewInt(99))
+	require.Equal(t, big.NewInt(200), balance)
+	require.Equal(t, 2, wallets.Active())
+	require.Equal(t, "wallet_rotation", trace.Steps[0].Name)
+	require.Equal(t, 1.0, testutil.ToFloat64(s.metrics.WalletRotations))
+	require.Equal(t, 2.0, testutil.ToFloat64(s.metrics.ActiveWallet))
+
+	// With no eligible wallet left, the active wallet is kept.
+	balance = s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(50))
+	require.Equal(t, big.NewInt(50), balance)
+	require.Equal(t, 2, wallets.Active())
+}
+
+// TestServiceWalletRotationSenderChanged asserts that submission follows the
+// authorized sender to another wallet regardless of the active wallet's
+// balance.
+func TestServiceWalletRotationSenderChanged(t *testing.T) {
+	t.Parallel()
+
+	s, wallets, api, sender := newRotationTestService(t, 2)
+	addrs := wallets.Addrs()
+	api.setBalance(addrs[1], 100)
+
+	*sender = addrs[1]
+	balance := s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(1000))
+	require.Equal(t, big.NewInt(100), balance)
+	require.Equal(t, 1, wallets.Active())
+}
+
+// TestUseAuthorizedWallet asserts that submission begins from the wallet that
+// is the authorized sender, and that startup fails if none is.
+func TestUseAuthorizedWallet(t *testing.T) {
+	t.Parallel()
+
+	s, wallets, _, sender := newRotationTestService(t, 3)
+	rotator := s.cfg.Driver.(WalletRotator)
+
+	*sender = wallets.Addrs()[2]
+	require.Nil(t, UseAuthorizedWallet(s.ctx, rotator))
+	require.Equal(t, 2, wallets.Active())
+
+	*sender = common.HexToAddress("0x01")
+	err := UseAuthorizedWallet(s.ctx, rotator)
+	require.True(t, errors.Is(err, ErrUnauthorizedWallets))
+	require.Equal(t, 2, wallets.Active())
+}
+
+// TestServiceWalletRotationWedgedNonce asserts that once txs have been pending
+// behind an unchanged nonce of the authorized sender for WalletWedgedAfter, an
+// eligible wallet to update the sender to is alerted on, that the wedge
+// restarts whenever the nonce advances, and that submission rotates once the
+// sender is updated.
+func TestServiceWalletRotationWedgedNonce(t *testing.T) {
+	t.Parallel()
+
+	s, wallets, api, sender := newRotationTestService(t, 2)
+	notifier := &recordingNotifier{}
+	s.cfg.BalanceNotifiers = []alerts.Notifier{notifier}
+	s.cfg.WalletWedgedAfter = 50 * time.Millisecond
+	addrs := wallets.Addrs()
+	api.setBalance(addrs[1], 100)
+
+	api.setNonces(addrs[0], 1, 3)
+	s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(1000))
+	time.Sleep(60 * time.Millisecond)
+
+	// The nonce advanced, restarting the wedge.
+	api.setNonces(addrs[0], 2, 3)
+	s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(1000))
+	s.wg.Wait()
+	require.Empty(t, notifier.severities)
+	time.Sleep(60 * time.Millisecond)
+
+	balance := s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(1000))
+	require.Equal(t, big.NewInt(1000), balance)
+	require.Equal(t, 0, wallets.Active())
+	s.wg.Wait()
+	require.Equal(t,
+		[]alerts.Severity{alerts.SeverityCritical}, notifier.severities,
+	)
+	require.Equal(t, "wedged nonce", notifier.details[0]["reason"])
+	require.Equal(t, addrs[1].Hex(), notifier.details[0]["eligible_wallet"])
+
+	*sender = addrs[1]
+	balance = s.checkWalletRotation(s.ctx, newCycleTrace(), big.NewInt(1000))
+	require.Equal(t, big.NewInt(100), balance)
+	require.Equal(t, 1, wallets.Active())
+	require.Equal(t, nonceWedge{}, s.wedge)
+}